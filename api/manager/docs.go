@@ -418,6 +418,243 @@ const docTemplate = `{
                 }
             }
         },
+        "/blocklists": {
+            "get": {
+                "description": "Get Blocklists",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blocklist"
+                ],
+                "summary": "Get Blocklists",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "regex",
+                            "glob"
+                        ],
+                        "type": "string",
+                        "description": "pattern type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "scheduler cluster id",
+                        "name": "scheduler_cluster_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Blocklist"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blocklist"
+                ],
+                "summary": "Create Blocklist",
+                "parameters": [
+                    {
+                        "description": "Blocklist",
+                        "name": "Blocklist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateBlocklistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Blocklist"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/blocklists/{id}": {
+            "get": {
+                "description": "Get Blocklist by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blocklist"
+                ],
+                "summary": "Get Blocklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Blocklist"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blocklist"
+                ],
+                "summary": "Destroy Blocklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Blocklist"
+                ],
+                "summary": "Update Blocklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Blocklist",
+                        "name": "Blocklist",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateBlocklistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Blocklist"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
         "/buckets": {
             "get": {
                 "description": "Get Buckets",
@@ -849,6 +1086,12 @@ const docTemplate = `{
                         "name": "per_page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tenant id",
+                        "name": "tenant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1408,9 +1651,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/roles": {
+        "/queued-jobs": {
             "get": {
-                "description": "Get roles",
+                "description": "Get jobs in queue, newer jobs come first",
                 "consumes": [
                     "application/json"
                 ],
@@ -1418,23 +1661,64 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Queued Job"
+                ],
+                "summary": "Get Queued Jobs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "job state",
+                        "name": "state",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get Roles",
                 "responses": {
                     "200": {
-                        "description": ""
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/job.JobInfo"
+                            }
+                        }
                     },
                     "400": {
                         "description": ""
                     },
+                    "404": {
+                        "description": ""
+                    },
                     "500": {
                         "description": ""
                     }
                 }
-            },
-            "post": {
-                "description": "Create Role by json config",
+            }
+        },
+        "/queued-jobs/{uuid}": {
+            "get": {
+                "description": "Get payload and state of job in queue by uuid",
                 "consumes": [
                     "application/json"
                 ],
@@ -1442,14 +1726,167 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Role"
+                    "Queued Job"
                 ],
-                "summary": "Create Role",
+                "summary": "Get Queued Job",
                 "parameters": [
                     {
-                        "description": "Role",
-                        "name": "Role",
-                        "in": "body",
+                        "type": "string",
+                        "description": "uuid",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/job.JobInfo"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/queued-jobs/{uuid}/cancel": {
+            "post": {
+                "description": "Remove unfinished job from its queue and mark it failed by uuid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queued Job"
+                ],
+                "summary": "Cancel Queued Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "uuid",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/job.JobInfo"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/queued-jobs/{uuid}/retry": {
+            "post": {
+                "description": "Send failed job to its queue again by uuid",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Queued Job"
+                ],
+                "summary": "Retry Queued Job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "uuid",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/job.JobInfo"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "description": "Get roles",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Get Roles",
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "post": {
+                "description": "Create Role by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "Create Role",
+                "parameters": [
+                    {
+                        "description": "Role",
+                        "name": "Role",
+                        "in": "body",
                         "required": true,
                         "schema": {
                             "$ref": "#/definitions/types.CreateRoleRequest"
@@ -1654,6 +2091,12 @@ const docTemplate = `{
                         "name": "per_page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tenant id",
+                        "name": "tenant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1844,6 +2287,88 @@ const docTemplate = `{
                 }
             }
         },
+        "/scheduler-clusters/{id}/purge": {
+            "delete": {
+                "description": "Permanently delete soft deleted scheduler cluster by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SchedulerCluster"
+                ],
+                "summary": "Purge SchedulerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/scheduler-clusters/{id}/restore": {
+            "post": {
+                "description": "Restore soft deleted scheduler cluster by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SchedulerCluster"
+                ],
+                "summary": "Restore SchedulerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SchedulerCluster"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
         "/scheduler-clusters/{id}/schedulers/{scheduler_id}": {
             "put": {
                 "description": "Add Scheduler to schedulerCluster",
@@ -2504,9 +3029,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/schedulers/{scheduler_id}/models/{model_id}": {
-            "patch": {
-                "description": "Update by json config",
+        "/schedulers/{id}/purge": {
+            "delete": {
+                "description": "Permanently delete soft deleted scheduler by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2514,9 +3039,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "Scheduler"
                 ],
-                "summary": "Update Model",
+                "summary": "Purge Scheduler",
                 "parameters": [
                     {
                         "type": "string",
@@ -2524,30 +3049,11 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "model_id",
-                        "name": "model_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Model",
-                        "name": "Model",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.UpdateModelRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/types.Model"
-                        }
+                        "description": ""
                     },
                     "400": {
                         "description": ""
@@ -2555,15 +3061,18 @@ const docTemplate = `{
                     "404": {
                         "description": ""
                     },
+                    "409": {
+                        "description": ""
+                    },
                     "500": {
                         "description": ""
                     }
                 }
             }
         },
-        "/schedulers/{scheduler_id}/models/{model_id}/versions/{version_id}": {
-            "patch": {
-                "description": "Update by json config",
+        "/schedulers/{id}/restore": {
+            "post": {
+                "description": "Restore soft deleted scheduler by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2571,9 +3080,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Model"
+                    "Scheduler"
                 ],
-                "summary": "Update Model Version",
+                "summary": "Restore Scheduler",
                 "parameters": [
                     {
                         "type": "string",
@@ -2581,10 +3090,108 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "model_id",
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Scheduler"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/schedulers/{scheduler_id}/models/{model_id}": {
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Model"
+                ],
+                "summary": "Update Model",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "model_id",
+                        "name": "model_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Model",
+                        "name": "Model",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateModelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Model"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/schedulers/{scheduler_id}/models/{model_id}/versions/{version_id}": {
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Model"
+                ],
+                "summary": "Update Model Version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "model_id",
                         "name": "model_id",
                         "in": "path",
                         "required": true
@@ -2656,6 +3263,12 @@ const docTemplate = `{
                         "name": "per_page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tenant id",
+                        "name": "tenant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3280,6 +3893,12 @@ const docTemplate = `{
                         "name": "per_page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tenant id",
+                        "name": "tenant_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3470,6 +4089,88 @@ const docTemplate = `{
                 }
             }
         },
+        "/seed-peer-clusters/{id}/purge": {
+            "delete": {
+                "description": "Permanently delete soft deleted seed peer cluster by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Purge SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/restore": {
+            "post": {
+                "description": "Restore soft deleted seed peer cluster by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Restore SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SeedPeerCluster"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
         "/seed-peer-clusters/{id}/scheduler-clusters/{scheduler_cluster_id}": {
             "put": {
                 "description": "Add SchedulerCluster to SeedPeerCluster",
@@ -3741,38 +4442,494 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeer"
+                    "SeedPeer"
+                ],
+                "summary": "Update SeedPeer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SeedPeer",
+                        "name": "SeedPeer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateSeedPeerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SeedPeer"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/seed-peers/{id}/purge": {
+            "delete": {
+                "description": "Permanently delete soft deleted seed peer by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Purge SeedPeer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "409": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/seed-peers/{id}/restore": {
+            "post": {
+                "description": "Restore soft deleted seed peer by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Restore SeedPeer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.SeedPeer"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/tenants": {
+            "get": {
+                "description": "Get Tenants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Get Tenants",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Tenant"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Create Tenant",
+                "parameters": [
+                    {
+                        "description": "Tenant",
+                        "name": "Tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateTenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/tenants/{id}": {
+            "get": {
+                "description": "Get Tenant by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Get Tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Destroy Tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Update Tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tenant",
+                        "name": "Tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateTenantRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/tenants/{id}/users/{user_id}": {
+            "put": {
+                "description": "Add User to Tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Add User to Tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete User to Tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Delete User to Tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "404": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/user/signin/oidc": {
+            "get": {
+                "description": "oidc signin by json config",
+                "tags": [
+                    "User"
+                ],
+                "summary": "OIDC Signin",
+                "responses": {
+                    "302": {
+                        "description": ""
+                    },
+                    "400": {
+                        "description": ""
+                    },
+                    "500": {
+                        "description": ""
+                    }
+                }
+            }
+        },
+        "/user/signin/oidc/callback": {
+            "get": {
+                "description": "oidc signin callback by json config",
+                "tags": [
+                    "User"
                 ],
-                "summary": "Update SeedPeer",
+                "summary": "OIDC Signin Callback",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "path",
+                        "description": "code",
+                        "name": "code",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "SeedPeer",
-                        "name": "SeedPeer",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/types.UpdateSeedPeerRequest"
-                        }
+                        "type": "string",
+                        "description": "state",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.SeedPeer"
-                        }
+                        "description": ""
                     },
                     "400": {
                         "description": ""
                     },
-                    "404": {
+                    "401": {
                         "description": ""
                     },
                     "500": {
@@ -4201,6 +5358,38 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "job.JobInfo": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/tasks.Arg"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "group_uuid": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "queue": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "uuid": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Application": {
             "type": "object",
             "properties": {
@@ -4248,6 +5437,32 @@ const docTemplate = `{
                 }
             }
         },
+        "model.Blocklist": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "scheduler_cluster_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Config": {
             "type": "object",
             "properties": {
@@ -4316,6 +5531,9 @@ const docTemplate = `{
                 "task_id": {
                     "type": "string"
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
                 "type": {
                     "type": "string"
                 },
@@ -4412,6 +5630,9 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "features": {
+                    "$ref": "#/definitions/model.JSONMap"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -4439,6 +5660,9 @@ const docTemplate = `{
                         "$ref": "#/definitions/model.SeedPeerCluster"
                     }
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -4465,6 +5689,9 @@ const docTemplate = `{
                         "$ref": "#/definitions/model.SecurityRule"
                     }
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -4591,11 +5818,40 @@ const docTemplate = `{
                 "security_group_id": {
                     "type": "integer"
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "model.Tenant": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.User"
+                    }
+                }
+            }
+        },
         "model.User": {
             "type": "object",
             "properties": {
@@ -4663,6 +5919,18 @@ const docTemplate = `{
                 }
             }
         },
+        "tasks.Arg": {
+            "type": "object",
+            "properties": {
+                "Name": {
+                    "type": "string"
+                },
+                "Type": {
+                    "type": "string"
+                },
+                "Value": {}
+            }
+        },
         "types.AddPermissionForRoleRequest": {
             "type": "object",
             "required": [
@@ -4713,6 +5981,32 @@ const docTemplate = `{
                 }
             }
         },
+        "types.CreateBlocklistRequest": {
+            "type": "object",
+            "required": [
+                "pattern",
+                "scheduler_cluster_id"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string",
+                    "maxLength": 1024
+                },
+                "scheduler_cluster_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "regex",
+                        "glob"
+                    ]
+                }
+            }
+        },
         "types.CreateBucketRequest": {
             "type": "object",
             "required": [
@@ -4779,6 +6073,9 @@ const docTemplate = `{
                         "type": "integer"
                     }
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
                 "type": {
                     "type": "string"
                 },
@@ -4915,6 +6212,9 @@ const docTemplate = `{
                 "config": {
                     "$ref": "#/definitions/types.SchedulerClusterConfig"
                 },
+                "features": {
+                    "$ref": "#/definitions/types.SchedulerClusterFeatures"
+                },
                 "is_default": {
                     "type": "boolean"
                 },
@@ -4929,6 +6229,9 @@ const docTemplate = `{
                 },
                 "seed_peer_cluster_id": {
                     "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -4975,6 +6278,9 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -5020,6 +6326,9 @@ const docTemplate = `{
                 },
                 "scopes": {
                     "$ref": "#/definitions/types.SeedPeerClusterScopes"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -5071,6 +6380,20 @@ const docTemplate = `{
                 }
             }
         },
+        "types.CreateTenantRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "types.CreateV1PreheatRequest": {
             "type": "object",
             "required": [
@@ -5135,6 +6458,15 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.V1PreheatNode"
+                    }
+                },
+                "percentage": {
+                    "type": "number"
+                },
                 "startTime": {
                     "type": "string"
                 },
@@ -5270,12 +6602,56 @@ const docTemplate = `{
                     "type": "integer",
                     "maximum": 1000,
                     "minimum": 1
+                },
+                "gc": {
+                    "$ref": "#/definitions/types.SchedulerClusterGCConfig"
+                }
+            }
+        },
+        "types.SchedulerClusterFeatures": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "boolean"
+            }
+        },
+        "types.SchedulerClusterGCConfig": {
+            "type": "object",
+            "properties": {
+                "host_gc_interval": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "host_ttl": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "peer_gc_interval": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "peer_ttl": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "task_gc_interval": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "task_ttl": {
+                    "type": "integer",
+                    "minimum": 1
                 }
             }
         },
         "types.SchedulerClusterScopes": {
             "type": "object",
             "properties": {
+                "cidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "idc": {
                     "type": "string"
                 },
@@ -5376,6 +6752,25 @@ const docTemplate = `{
                 }
             }
         },
+        "types.UpdateBlocklistRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string",
+                    "maxLength": 1024
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "regex",
+                        "glob"
+                    ]
+                }
+            }
+        },
         "types.UpdateConfigRequest": {
             "type": "object",
             "properties": {
@@ -5480,6 +6875,9 @@ const docTemplate = `{
                 "config": {
                     "$ref": "#/definitions/types.SchedulerClusterConfig"
                 },
+                "features": {
+                    "$ref": "#/definitions/types.SchedulerClusterFeatures"
+                },
                 "is_default": {
                     "type": "boolean"
                 },
@@ -5494,6 +6892,9 @@ const docTemplate = `{
                 },
                 "seed_peer_cluster_id": {
                     "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -5531,6 +6932,9 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -5568,6 +6972,9 @@ const docTemplate = `{
                 },
                 "scopes": {
                     "$ref": "#/definitions/types.SeedPeerClusterScopes"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -5608,6 +7015,17 @@ const docTemplate = `{
                 }
             }
         },
+        "types.UpdateTenantRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "types.UpdateUserRequest": {
             "type": "object",
             "properties": {
@@ -5627,6 +7045,29 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "types.V1PreheatNode": {
+            "type": "object",
+            "properties": {
+                "finishedPieceCount": {
+                    "type": "integer"
+                },
+                "schedulerHostname": {
+                    "type": "string"
+                },
+                "seedPeerHostID": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "totalPieceCount": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
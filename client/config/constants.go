@@ -45,6 +45,12 @@ const (
 	PatternSource   = "source"
 )
 
+// Source signer type.
+const (
+	SourceSignerS3     = "s3"
+	SourceSignerHeader = "header"
+)
+
 // Download limit.
 const (
 	DefaultPerPeerDownloadLimit = 20 * unit.MB
@@ -59,6 +65,7 @@ const (
 	SchemaHTTP             = "http"
 
 	DefaultTaskExpireTime  = 6 * time.Hour
+	DefaultMaxPinTTL       = 7 * 24 * time.Hour
 	DefaultGCInterval      = 1 * time.Minute
 	DefaultDaemonAliveTime = 5 * time.Minute
 	DefaultScheduleTimeout = 5 * time.Minute
@@ -70,6 +77,26 @@ const (
 
 	DefaultPieceChanSize     = 16
 	DefaultObjectMaxReplicas = 3
+
+	DefaultPeerExchangeInterval = 30 * time.Second
+	DefaultPeerExchangeTTL      = 2 * time.Minute
+
+	DefaultUploadTokenTTL = 5 * time.Minute
+
+	// DefaultUploadPerPeerQueueTimeout is shorter than the response header timeout of piece downloader
+	DefaultUploadPerPeerQueueSize    = 16
+	DefaultUploadPerPeerQueueTimeout = time.Second
+
+	DefaultPieceResultBatchInterval = 100 * time.Millisecond
+	DefaultParentExclusionTTL       = 1 * time.Minute
+
+	DefaultMemoryTierWatermark    = 1 * unit.GB
+	DefaultMemoryTierMaxTaskSize  = 128 * unit.MB
+	DefaultMemoryTierHotThreshold = 64
+
+	DefaultObjectMultipartThreshold   = 64 * unit.MB
+	DefaultObjectMultipartPartSize    = 16 * unit.MB
+	DefaultObjectMultipartConcurrency = 4
 )
 
 // Store strategy.
@@ -92,5 +119,7 @@ const (
 	DefaultPeerStartPort          = 65000
 	DefaultUploadStartPort        = 65002
 	DefaultObjectStorageStartPort = 65004
+	DefaultPeerExchangePort       = 65006
 	DefaultHealthyStartPort       = 40901
+	DefaultDebugStartPort         = 40902
 )
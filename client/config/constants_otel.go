@@ -45,6 +45,10 @@ const (
 	AttributeGetPieceRetry     = attribute.Key("d7y.peer.piece.retry")
 	AttributeWritePieceSuccess = attribute.Key("d7y.peer.piece.write.success")
 	AttributeSeedTaskSuccess   = attribute.Key("d7y.seed.task.success")
+	AttributeScheduleWait      = attribute.Key("d7y.peer.task.schedule_wait")
+	AttributeFirstPieceCost    = attribute.Key("d7y.peer.task.first_piece_cost")
+	AttributePieceRetries      = attribute.Key("d7y.peer.task.piece_retries")
+	AttributePeerBytes         = attribute.Key("d7y.peer.task.peer_bytes")
 
 	SpanFileTask          = "file-task"
 	SpanStreamTask        = "stream-task"
@@ -19,6 +19,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strings"
 )
 
@@ -48,13 +49,12 @@ func (sv *SupernodesValue) Set(value string) error {
 			return errors.New("invalid nodes")
 		}
 		// ignore weight
-		node := v[0]
-		vv := strings.Split(node, ":")
-		if len(vv) >= 2 {
+		if _, _, err := net.SplitHostPort(v[0]); err == nil {
 			return errors.New("invalid nodes")
 		}
-		if len(vv) == 1 {
-			node = fmt.Sprintf("%s:%d", node, DefaultSchedulerPort)
+		node, err := withDefaultPort(v[0], DefaultSchedulerPort)
+		if err != nil {
+			return errors.New("invalid nodes")
 		}
 		sv.Nodes = append(sv.Nodes, node)
 	}
@@ -19,17 +19,20 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"d7y.io/dragonfly/v2/client/util"
 	"d7y.io/dragonfly/v2/cmd/dependency/base"
+	"d7y.io/dragonfly/v2/internal/constants"
 	"d7y.io/dragonfly/v2/internal/dferrors"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/basic"
@@ -40,13 +43,16 @@ import (
 
 type DfgetConfig = ClientOption
 
+// StdoutOutput is the output which streams the content to stdout in order, like dfget -O - url | tar x.
+const StdoutOutput = "-"
+
 // ClientOption holds all the runtime config information.
 type ClientOption struct {
 	base.Options `yaml:",inline" mapstructure:",squash"`
 	// URL download URL.
 	URL string `yaml:"url,omitempty" mapstructure:"url,omitempty"`
 
-	// Output full output path.
+	// Output full output path, StdoutOutput streams the content to stdout.
 	Output string `yaml:"output,omitempty" mapstructure:"output,omitempty"`
 
 	// Timeout download timeout(second).
@@ -70,6 +76,9 @@ type ClientOption struct {
 	// Application application name that executes dfget.
 	Application string `yaml:"application,omitempty" mapstructure:"application,omitempty"`
 
+	// CallSystem is the caller system name, it is used as application when application is empty.
+	CallSystem string `yaml:"callSystem,omitempty" mapstructure:"callSystem,omitempty"`
+
 	// DaemonSock is daemon download socket path.
 	DaemonSock string `yaml:"daemonSock,omitempty" mapstructure:"daemon-sock,omitempty"`
 
@@ -131,8 +140,34 @@ type ClientOption struct {
 
 	KeepOriginalOffset bool `yaml:"keepOriginalOffset,omitempty" mapstructure:"original-offset,omitempty"`
 
+	// InPlace indicates to write ranged data into the existing output file at original offset instead of hardlink,
+	// it works with KeepOriginalOffset
+	InPlace bool `yaml:"inPlace,omitempty" mapstructure:"in-place,omitempty"`
+
 	// Range stands download range for url, like: 0-9, will download 10 bytes from 0 to 9 ([0:9])
 	Range string `yaml:"range,omitempty" mapstructure:"range,omitempty"`
+
+	// Resume indicates to resume the interrupted task from the pieces persisted in daemon storage
+	Resume bool `yaml:"resume,omitempty" mapstructure:"resume,omitempty"`
+
+	// Sync indicates to sync the output file and its directory to disk before download completes
+	Sync bool `yaml:"sync,omitempty" mapstructure:"sync,omitempty"`
+
+	// UID is the owner user id of the output file, the daemon changes the owner of output to it
+	UID int `yaml:"uid,omitempty" mapstructure:"uid,omitempty"`
+
+	// GID is the owner group id of the output file, the daemon changes the group of output to it
+	GID int `yaml:"gid,omitempty" mapstructure:"gid,omitempty"`
+
+	// Mode is the permissions of the output file in octal, like 0644, empty keeps the default permissions
+	Mode string `yaml:"mode,omitempty" mapstructure:"mode,omitempty"`
+
+	// Mirrors are alternate origins which serve the same content as the url,
+	// they are tried in order when the url fails and the content is validated by digest
+	Mirrors []string `yaml:"mirrors,omitempty" mapstructure:"mirror,omitempty"`
+
+	// CheckOnly checks whether the url is fully cached in daemon and validates the digests without downloading
+	CheckOnly bool `yaml:"checkOnly,omitempty" mapstructure:"check-only,omitempty"`
 }
 
 func NewDfgetConfig() *ClientOption {
@@ -156,10 +191,36 @@ func (cfg *ClientOption) Validate() error {
 		return err
 	}
 
-	if err := cfg.checkOutput(); err != nil {
+	if err := cfg.checkMirrors(); err != nil {
+		return fmt.Errorf("mirrors %s: %w", err.Error(), dferrors.ErrInvalidArgument)
+	}
+
+	if cfg.CheckOnly {
+		if cfg.Recursive {
+			return fmt.Errorf("check only conflicts with recursive: %w", dferrors.ErrInvalidArgument)
+		}
+
+		if cfg.Pattern == constants.SourcePattern {
+			return fmt.Errorf("check only requires daemon, it conflicts with source pattern: %w", dferrors.ErrInvalidArgument)
+		}
+	} else if cfg.IsStdoutOutput() {
+		if err := cfg.checkStdoutOutput(); err != nil {
+			return fmt.Errorf("stdout output %s: %w", err.Error(), dferrors.ErrInvalidArgument)
+		}
+	} else if err := cfg.checkOutput(); err != nil {
 		return fmt.Errorf("output %s: %w", err.Error(), dferrors.ErrInvalidArgument)
 	}
 
+	if cfg.InPlace && !cfg.KeepOriginalOffset {
+		return fmt.Errorf("in place requires original offset: %w", dferrors.ErrInvalidArgument)
+	}
+
+	if cfg.Mode != "" {
+		if _, err := ParseFileMode(cfg.Mode); err != nil {
+			return fmt.Errorf("mode %s: %w", err.Error(), dferrors.ErrInvalidArgument)
+		}
+	}
+
 	if err := cfg.checkHeader(); err != nil {
 		return fmt.Errorf("output %s: %w", err.Error(), dferrors.ErrInvalidHeader)
 	}
@@ -181,7 +242,7 @@ func (cfg *ClientOption) Convert(args []string) error {
 		cfg.Output = url[idx+1:]
 	}
 
-	if !filepath.IsAbs(cfg.Output) {
+	if !cfg.IsStdoutOutput() && !filepath.IsAbs(cfg.Output) {
 		absPath, err := filepath.Abs(cfg.Output)
 		if err != nil {
 			return fmt.Errorf("get absolute path[%s] error: %v", cfg.Output, err)
@@ -190,8 +251,12 @@ func (cfg *ClientOption) Convert(args []string) error {
 	}
 	if cfg.URL == "" && len(args) > 0 {
 		cfg.URL = args[0]
+		args = args[1:]
 	}
 
+	// the rest of position arguments are mirrors of the url
+	cfg.Mirrors = append(cfg.Mirrors, args...)
+
 	if cfg.Digest != "" {
 		cfg.Tag = ""
 	}
@@ -207,6 +272,48 @@ func (cfg *ClientOption) String() string {
 	return string(js)
 }
 
+// ParseFileMode parses the permissions of file in octal, like 0644.
+func ParseFileMode(mode string) (os.FileMode, error) {
+	m, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	if m == 0 || m > uint64(os.ModePerm) {
+		return 0, fmt.Errorf("permissions %s out of range", mode)
+	}
+
+	return os.FileMode(m), nil
+}
+
+// checkMirrors is for checking the mirrors of url
+func (cfg *ClientOption) checkMirrors() error {
+	if len(cfg.Mirrors) == 0 {
+		return nil
+	}
+
+	if cfg.Recursive {
+		return errors.New("mirrors conflict with recursive download")
+	}
+
+	if pkgstrings.IsBlank(cfg.Digest) {
+		return errors.New("digest is required to validate the content of mirrors")
+	}
+
+	for _, mirror := range cfg.Mirrors {
+		if !url.IsValid(mirror) {
+			return fmt.Errorf("mirror %s is invalid", mirror)
+		}
+
+		// mirrors are passed to daemon in header separated by comma
+		if strings.Contains(mirror, ",") {
+			return fmt.Errorf("mirror %s contains comma", mirror)
+		}
+	}
+
+	return nil
+}
+
 // checkHeader is for checking the header format
 func (cfg *ClientOption) checkHeader() error {
 	if len(cfg.Header) == 0 {
@@ -226,6 +333,29 @@ func (cfg *ClientOption) checkHeader() error {
 	return nil
 }
 
+// IsStdoutOutput returns whether the content is streamed to stdout.
+func (cfg *ClientOption) IsStdoutOutput() bool {
+	return cfg.Output == StdoutOutput
+}
+
+// checkStdoutOutput checks the options which require an output file.
+func (cfg *ClientOption) checkStdoutOutput() error {
+	switch {
+	case cfg.Recursive:
+		return errors.New("conflicts with recursive")
+	case cfg.KeepOriginalOffset:
+		return errors.New("conflicts with original offset")
+	case cfg.Resume:
+		return errors.New("conflicts with resume")
+	case cfg.Sync:
+		return errors.New("conflicts with sync")
+	case cfg.Mode != "":
+		return errors.New("conflicts with mode")
+	}
+
+	return nil
+}
+
 // This function must be called after checkURL
 func (cfg *ClientOption) checkOutput() error {
 	if !filepath.IsAbs(cfg.Output) {
@@ -23,6 +23,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/pkg/basic"
 )
 
 var dfgetConfig = ClientOption{
@@ -46,4 +47,6 @@ var dfgetConfig = ClientOption{
 	ShowProgress:      false,
 	Recursive:         false,
 	RecursiveLevel:    5,
+	UID:               basic.UserID,
+	GID:               basic.UserGroup,
 }
@@ -192,3 +192,163 @@ func TestCheckHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckMirrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		mirrors   []string
+		digest    string
+		recursive bool
+		hasErr    bool
+	}{
+		{
+			name:    "without mirrors",
+			mirrors: nil,
+			hasErr:  false,
+		},
+		{
+			name:    "valid mirrors",
+			mirrors: []string{"http://a.example.com/foo", "https://b.example.com/foo"},
+			digest:  "sha256:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4",
+			hasErr:  false,
+		},
+		{
+			name:    "without digest",
+			mirrors: []string{"http://a.example.com/foo"},
+			hasErr:  true,
+		},
+		{
+			name:      "recursive download",
+			mirrors:   []string{"http://a.example.com/foo"},
+			digest:    "sha256:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4",
+			recursive: true,
+			hasErr:    true,
+		},
+		{
+			name:    "invalid mirror",
+			mirrors: []string{"foo"},
+			digest:  "sha256:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4",
+			hasErr:  true,
+		},
+		{
+			name:    "mirror contains comma",
+			mirrors: []string{"http://a.example.com/foo,bar"},
+			digest:  "sha256:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4",
+			hasErr:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &ClientOption{
+				Mirrors:   tc.mirrors,
+				Digest:    tc.digest,
+				Recursive: tc.recursive,
+			}
+			if tc.hasErr {
+				testifyassert.NotNil(t, cfg.checkMirrors())
+			} else {
+				testifyassert.Nil(t, cfg.checkMirrors())
+			}
+		})
+	}
+}
+
+func TestCheckStdoutOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *ClientOption
+		hasErr bool
+	}{
+		{
+			name:   "stream to stdout",
+			cfg:    &ClientOption{},
+			hasErr: false,
+		},
+		{
+			name:   "recursive download",
+			cfg:    &ClientOption{Recursive: true},
+			hasErr: true,
+		},
+		{
+			name:   "keep original offset",
+			cfg:    &ClientOption{KeepOriginalOffset: true},
+			hasErr: true,
+		},
+		{
+			name:   "resume",
+			cfg:    &ClientOption{Resume: true},
+			hasErr: true,
+		},
+		{
+			name:   "sync",
+			cfg:    &ClientOption{Sync: true},
+			hasErr: true,
+		},
+		{
+			name:   "mode",
+			cfg:    &ClientOption{Mode: "0644"},
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.Output = StdoutOutput
+			testifyassert.True(t, tc.cfg.IsStdoutOutput())
+			if tc.hasErr {
+				testifyassert.NotNil(t, tc.cfg.checkStdoutOutput())
+			} else {
+				testifyassert.Nil(t, tc.cfg.checkStdoutOutput())
+			}
+		})
+	}
+}
+
+func TestParseFileMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		expect os.FileMode
+		hasErr bool
+	}{
+		{
+			name:   "octal mode",
+			mode:   "0644",
+			expect: 0644,
+		},
+		{
+			name:   "octal mode without leading zero",
+			mode:   "600",
+			expect: 0600,
+		},
+		{
+			name:   "invalid octal mode",
+			mode:   "0689",
+			hasErr: true,
+		},
+		{
+			name:   "zero mode",
+			mode:   "0",
+			hasErr: true,
+		},
+		{
+			name:   "mode with special bits",
+			mode:   "4755",
+			hasErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mode, err := ParseFileMode(tc.mode)
+			if tc.hasErr {
+				testifyassert.NotNil(t, err)
+				return
+			}
+
+			testifyassert.Nil(t, err)
+			testifyassert.Equal(t, tc.expect, mode)
+		})
+	}
+}
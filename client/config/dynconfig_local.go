@@ -18,6 +18,7 @@ package config
 
 import (
 	"errors"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc/resolver"
@@ -25,6 +26,7 @@ import (
 	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/reachable"
 	"d7y.io/dragonfly/v2/pkg/slices"
 )
@@ -35,7 +37,9 @@ var (
 
 type dynconfigLocal struct {
 	config    *DaemonOption
+	netAddrs  []dfnet.NetAddr
 	observers map[Observer]struct{}
+	mu        sync.RWMutex
 	done      chan bool
 }
 
@@ -43,6 +47,7 @@ type dynconfigLocal struct {
 func newDynconfigLocal(cfg *DaemonOption) (Dynconfig, error) {
 	return &dynconfigLocal{
 		config:    cfg,
+		netAddrs:  cfg.Scheduler.NetAddrs,
 		observers: map[Observer]struct{}{},
 		done:      make(chan bool),
 	}, nil
@@ -50,8 +55,12 @@ func newDynconfigLocal(cfg *DaemonOption) (Dynconfig, error) {
 
 // Get the dynamic schedulers resolve addrs.
 func (d *dynconfigLocal) GetResolveSchedulerAddrs() ([]resolver.Address, error) {
+	d.mu.RLock()
+	netAddrs := d.netAddrs
+	d.mu.RUnlock()
+
 	addrs := []string{}
-	for _, schedulerAddr := range d.config.Scheduler.NetAddrs {
+	for _, schedulerAddr := range netAddrs {
 		r := reachable.New(&reachable.Config{Address: schedulerAddr.Addr})
 		if err := r.Check(); err != nil {
 			logger.Warnf("scheduler address %s is unreachable", schedulerAddr.Addr)
@@ -91,13 +100,31 @@ func (d *dynconfigLocal) Refresh() error {
 	return nil
 }
 
+// ReloadSchedulerAddrs replaces the scheduler addresses with reloaded config,
+// and observers are notified to resolve the addresses again.
+func (d *dynconfigLocal) ReloadSchedulerAddrs(netAddrs []dfnet.NetAddr) {
+	d.mu.Lock()
+	d.netAddrs = netAddrs
+	d.mu.Unlock()
+
+	for _, o := range d.loadObservers() {
+		o.OnNotify(nil)
+	}
+}
+
 // Register allows an instance to register itself to listen/observe events.
 func (d *dynconfigLocal) Register(l Observer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	d.observers[l] = struct{}{}
 }
 
 // Deregister allows an instance to remove itself from the collection of observers/listeners.
 func (d *dynconfigLocal) Deregister(l Observer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
 	delete(d.observers, l)
 }
 
@@ -108,13 +135,26 @@ func (d *dynconfigLocal) Notify() error {
 		return err
 	}
 
-	for o := range d.observers {
+	for _, o := range d.loadObservers() {
 		o.OnNotify(data)
 	}
 
 	return nil
 }
 
+// loadObservers returns a copy of observers, so observers can be notified without lock.
+func (d *dynconfigLocal) loadObservers() []Observer {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	observers := make([]Observer, 0, len(d.observers))
+	for o := range d.observers {
+		observers = append(observers, o)
+	}
+
+	return observers
+}
+
 // Serve the dynconfig listening service.
 func (d *dynconfigLocal) Serve() error {
 	if err := d.Notify(); err != nil {
@@ -108,3 +108,47 @@ func TestDynconfigGetResolveSchedulerAddrs_LocalSourceType(t *testing.T) {
 		})
 	}
 }
+
+type mockObserver struct {
+	count int
+}
+
+func (o *mockObserver) OnNotify(*DynconfigData) {
+	o.count++
+}
+
+func TestDynconfigReloadSchedulerAddrs_LocalSourceType(t *testing.T) {
+	l, err := net.Listen("tcp", ":3001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	dynconfig, err := NewDynconfig(LocalSourceType, &DaemonOption{
+		Scheduler: SchedulerOption{
+			NetAddrs: []dfnet.NetAddr{
+				{
+					Addr: "127.0.0.1:3003",
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert := assert.New(t)
+	observer := &mockObserver{}
+	dynconfig.Register(observer)
+
+	result, err := dynconfig.GetResolveSchedulerAddrs()
+	assert.NoError(err)
+	assert.EqualValues(result, []resolver.Address{})
+
+	dynconfig.(*dynconfigLocal).ReloadSchedulerAddrs([]dfnet.NetAddr{{Addr: "127.0.0.1:3001"}})
+	assert.Equal(observer.count, 1)
+
+	result, err = dynconfig.GetResolveSchedulerAddrs()
+	assert.NoError(err)
+	assert.EqualValues(result, []resolver.Address{{Addr: "127.0.0.1:3001"}})
+}
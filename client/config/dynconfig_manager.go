@@ -18,9 +18,10 @@ package config
 
 import (
 	"context"
-	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -76,7 +77,7 @@ func (d *dynconfigManager) GetResolveSchedulerAddrs() ([]resolver.Address, error
 
 	addrs := []string{}
 	for _, scheduler := range schedulers {
-		addr := fmt.Sprintf("%s:%d", scheduler.GetIp(), scheduler.GetPort())
+		addr := net.JoinHostPort(scheduler.GetIp(), strconv.Itoa(int(scheduler.GetPort())))
 		r := reachable.New(&reachable.Config{Address: addr})
 		if err := r.Check(); err != nil {
 			logger.Warnf("scheduler address %s is unreachable", addr)
@@ -19,6 +19,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 	"time"
@@ -54,12 +55,9 @@ func (nv *NetAddrsValue) String() string {
 }
 
 func (nv *NetAddrsValue) Set(value string) error {
-	vv := strings.Split(value, ":")
-	if len(vv) > 2 || len(vv) == 0 {
-		return errors.New("invalid net address")
-	}
-	if len(vv) == 1 {
-		value = fmt.Sprintf("%s:%d", value, DefaultSchedulerPort)
+	value, err := withDefaultPort(value, DefaultSchedulerPort)
+	if err != nil {
+		return err
 	}
 
 	if !nv.isSet && len(*nv.n) > 0 {
@@ -134,3 +132,18 @@ func (d *DurationValue) Type() string {
 }
 
 func (d *DurationValue) String() string { return (*time.Duration)(d).String() }
+
+// withDefaultPort appends port to address without port, IPv6 address is enclosed in square brackets,
+// like 127.0.0.1 to 127.0.0.1:8002 and fd00::1 to [fd00::1]:8002.
+func withDefaultPort(addr string, port int) (string, error) {
+	if _, _, err := net.SplitHostPort(addr); err == nil {
+		return addr, nil
+	}
+
+	host := strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if host == "" || (strings.Contains(host, ":") && net.ParseIP(host) == nil) {
+		return "", errors.New("invalid net address")
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/dfnet"
+)
+
+func TestNetAddrsValue_Set(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect func(t *testing.T, netAddrs []dfnet.NetAddr, err error)
+	}{
+		{
+			name:  "ipv4 address with port",
+			value: "127.0.0.1:8002",
+			expect: func(t *testing.T, netAddrs []dfnet.NetAddr, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal("127.0.0.1:8002", netAddrs[0].Addr)
+			},
+		},
+		{
+			name:  "ipv4 address without port",
+			value: "127.0.0.1",
+			expect: func(t *testing.T, netAddrs []dfnet.NetAddr, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal("127.0.0.1:8002", netAddrs[0].Addr)
+			},
+		},
+		{
+			name:  "ipv6 address with port",
+			value: "[fd00::1]:8002",
+			expect: func(t *testing.T, netAddrs []dfnet.NetAddr, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal("[fd00::1]:8002", netAddrs[0].Addr)
+			},
+		},
+		{
+			name:  "ipv6 address without port",
+			value: "fd00::1",
+			expect: func(t *testing.T, netAddrs []dfnet.NetAddr, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal("[fd00::1]:8002", netAddrs[0].Addr)
+			},
+		},
+		{
+			name:  "invalid address",
+			value: "foo:bar:baz",
+			expect: func(t *testing.T, netAddrs []dfnet.NetAddr, err error) {
+				assert := testifyassert.New(t)
+				assert.EqualError(err, "invalid net address")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var netAddrs []dfnet.NetAddr
+			err := NewNetAddrsValue(&netAddrs).Set(tc.value)
+			tc.expect(t, netAddrs, err)
+		})
+	}
+}
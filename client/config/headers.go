@@ -17,6 +17,9 @@
 package config
 
 const (
+	// HeaderDragonflyPrefix is the prefix of all dragonfly headers.
+	HeaderDragonflyPrefix = "X-Dragonfly-"
+
 	HeaderDragonflyFilter = "X-Dragonfly-Filter"
 	HeaderDragonflyPeer   = "X-Dragonfly-Peer"
 	HeaderDragonflyTask   = "X-Dragonfly-Task"
@@ -29,4 +32,16 @@ const (
 	HeaderDragonflyRegistry = "X-Dragonfly-Registry"
 	// HeaderDragonflyObjectMetaDigest is used for digest of object storage.
 	HeaderDragonflyObjectMetaDigest = "X-Dragonfly-Object-Meta-Digest"
+	// HeaderDragonflyResume is used for resuming the interrupted task from the persisted pieces.
+	HeaderDragonflyResume = "X-Dragonfly-Resume"
+	// HeaderDragonflyMirrors is used for alternate origins which serve the same content as the url, separated by comma.
+	HeaderDragonflyMirrors = "X-Dragonfly-Mirrors"
+	// HeaderDragonflySync is used for syncing the output file to disk before it is renamed to output.
+	HeaderDragonflySync = "X-Dragonfly-Sync"
+	// HeaderDragonflyMode is used for the permissions of the output file in octal, like 0644.
+	HeaderDragonflyMode = "X-Dragonfly-Mode"
+	// HeaderDragonflyDigest is used for digest of content like sha256:xxx, the task is verified with the digest.
+	HeaderDragonflyDigest = "X-Dragonfly-Digest"
+	// HeaderDragonflyInPlace is used for writing the range into the existing output file at original offset instead of hardlink.
+	HeaderDragonflyInPlace = "X-Dragonfly-In-Place"
 )
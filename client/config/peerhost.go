@@ -26,8 +26,10 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 	"time"
 
@@ -39,6 +41,7 @@ import (
 	"d7y.io/dragonfly/v2/cmd/dependency/base"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/dfnet"
+	"d7y.io/dragonfly/v2/pkg/idgen"
 	netip "d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/pkg/unit"
 )
@@ -67,7 +70,10 @@ type DaemonOption struct {
 	ObjectStorage ObjectStorageOption `mapstructure:"objectStorage" yaml:"objectStorage"`
 	Storage       StorageOption       `mapstructure:"storage" yaml:"storage"`
 	Health        *HealthOption       `mapstructure:"health" yaml:"health"`
+	Debug         *DebugOption        `mapstructure:"debug" yaml:"debug"`
 	Reload        ReloadOption        `mapstructure:"reload" yaml:"reload"`
+	PeerExchange  PeerExchangeOption  `mapstructure:"peerExchange" yaml:"peerExchange"`
+	Mount         MountOption         `mapstructure:"mount" yaml:"mount"`
 }
 
 func NewDaemonConfig() *DaemonOption {
@@ -101,12 +107,32 @@ func (p *DaemonOption) Load(path string) error {
 func (p *DaemonOption) Convert() error {
 	// AdvertiseIP
 	ip := net.ParseIP(p.Host.AdvertiseIP)
-	if ip == nil || net.IPv4zero.Equal(ip) {
-		p.Host.AdvertiseIP = netip.IPv4
+	if ip == nil || ip.IsUnspecified() || (p.Host.PreferIPv6 && p.Host.AdvertiseIP == netip.IPv4) {
+		p.Host.AdvertiseIP = netip.Advertise(p.Host.PreferIPv6)
 	} else {
 		p.Host.AdvertiseIP = ip.String()
 	}
 
+	// Listen on all IPv4 and IPv6 interfaces when IPv6 is preferred
+	if p.Host.PreferIPv6 {
+		listens := []*ListenOption{&p.Download.PeerGRPC, &p.Upload.ListenOption, &p.ObjectStorage.ListenOption}
+		if p.Proxy != nil {
+			listens = append(listens, &p.Proxy.ListenOption)
+		}
+		if p.Health != nil {
+			listens = append(listens, &p.Health.ListenOption)
+		}
+		if p.Debug != nil {
+			listens = append(listens, &p.Debug.ListenOption)
+		}
+
+		for _, listen := range listens {
+			if listen.TCPListen != nil && net.IPv4zero.String() == listen.TCPListen.Listen {
+				listen.TCPListen.Listen = net.IPv6unspecified.String()
+			}
+		}
+	}
+
 	// ScheduleTimeout should not great then AliveTime
 	if p.AliveTime.Duration > 0 && p.Scheduler.ScheduleTimeout.Duration > p.AliveTime.Duration {
 		p.Scheduler.ScheduleTimeout.Duration = p.AliveTime.Duration - time.Second
@@ -124,6 +150,10 @@ func (p *DaemonOption) Validate() error {
 		if p.Scheduler.Manager.RefreshInterval == 0 {
 			return errors.New("manager refreshInterval is not specified")
 		}
+
+		if tls := p.Scheduler.Manager.TLS; tls != nil && (tls.Cert == "") != (tls.Key == "") {
+			return errors.New("manager tls cert and key must be specified together")
+		}
 		return nil
 	}
 
@@ -131,6 +161,32 @@ func (p *DaemonOption) Validate() error {
 		return errors.New("empty schedulers and config server is not specified")
 	}
 
+	if p.Scheduler.PieceResultBatch.Size > 1 && p.Scheduler.PieceResultBatch.Interval.Duration <= 0 {
+		return errors.New("piece result batch interval must be greater than 0")
+	}
+
+	if p.Download.LocalTransport.Enable && p.Download.LocalTransport.SocketDir == "" && runtime.GOOS != "linux" {
+		return errors.New("local transport socketDir must be specified when abstract namespace is not supported")
+	}
+
+	if runtime.GOOS != "linux" {
+		if p.Download.PieceNetwork.Interface != "" {
+			return errors.New("piece network interface is only supported in linux")
+		}
+
+		for _, listen := range []ListenOption{p.Download.PeerGRPC, p.Upload.ListenOption, p.ObjectStorage.ListenOption} {
+			if listen.TCPListen != nil && listen.TCPListen.Interface != "" {
+				return errors.New("tcp listen interface is only supported in linux")
+			}
+		}
+	}
+
+	for _, pattern := range p.Download.TaskID.StripQuery {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("task id stripQuery pattern %q is invalid: %w", pattern, err)
+		}
+	}
+
 	if int64(p.Download.TotalRateLimit.Limit) < DefaultMinRate.ToNumber() {
 		return fmt.Errorf("rate limit must be greater than %s", DefaultMinRate.String())
 	}
@@ -139,16 +195,107 @@ func (p *DaemonOption) Validate() error {
 		return fmt.Errorf("rate limit must be greater than %s", DefaultMinRate.String())
 	}
 
+	if p.Upload.Auth.Enable {
+		if p.Upload.Auth.Secret == "" {
+			return errors.New("upload auth secret is not specified")
+		}
+
+		if p.Upload.Auth.TokenTTL <= 0 {
+			return errors.New("upload auth tokenTTL must be greater than 0")
+		}
+	}
+
+	if p.Upload.PerPeer.Concurrency < 0 || p.Upload.PerPeer.QueueSize < 0 {
+		return errors.New("upload per peer concurrency and queueSize must not be negative")
+	}
+
+	if p.Scheduler.Manager.SeedPeer.Admission.Enable {
+		admission := p.Scheduler.Manager.SeedPeer.Admission
+		if admission.MaxBandwidth.Limit <= admission.SystemReservedBandwidth.Limit {
+			return errors.New("seed peer max bandwidth must be greater than system reserved bandwidth")
+		}
+
+		if admission.MaxQueueLength < 0 {
+			return errors.New("seed peer max queue length must not be negative")
+		}
+
+		if admission.DiskHighWatermark < 0 || admission.DiskHighWatermark > 100 {
+			return errors.New("seed peer disk high watermark must be in range [0, 100]")
+		}
+	}
+
+	if p.Storage.MemoryTier.Enable {
+		if p.Storage.MemoryTier.Watermark <= 0 || p.Storage.MemoryTier.MaxTaskSize <= 0 {
+			return errors.New("memory tier watermark and maxTaskSize must be greater than 0")
+		}
+
+		if p.Storage.MemoryTier.HotThreshold <= 0 {
+			return errors.New("memory tier hotThreshold must be greater than 0")
+		}
+	}
+
 	if p.ObjectStorage.Enable {
 		if p.ObjectStorage.MaxReplicas <= 0 {
 			return errors.New("max replicas must be greater than 0")
 		}
+
+		if p.ObjectStorage.MultipartUpload.Threshold > 0 {
+			if p.ObjectStorage.MultipartUpload.PartSize < 5*unit.MB {
+				return errors.New("multipart upload part size must be at least 5MB")
+			}
+
+			if p.ObjectStorage.MultipartUpload.Concurrency <= 0 {
+				return errors.New("multipart upload concurrency must be greater than 0")
+			}
+		}
 	}
 
 	if p.Reload.Interval.Duration > 0 && p.Reload.Interval.Duration < time.Second {
 		return errors.New("reload interval too short, must great than 1 second")
 	}
 
+	if p.PeerExchange.Enable {
+		if p.PeerExchange.Interval.Duration <= 0 {
+			return errors.New("peer exchange interval must be greater than 0")
+		}
+
+		if p.PeerExchange.TTL.Duration < p.PeerExchange.Interval.Duration {
+			return errors.New("peer exchange ttl must be greater than interval")
+		}
+
+		for _, subnet := range p.PeerExchange.Subnets {
+			if _, _, err := net.ParseCIDR(subnet); err != nil {
+				return fmt.Errorf("invalid peer exchange subnet %s: %w", subnet, err)
+			}
+		}
+	}
+
+	if p.Mount.Enable && p.Mount.Dir == "" {
+		return errors.New("mount dir is not specified")
+	}
+
+	for _, signer := range p.Download.SourceSigners {
+		if signer.Regx == nil {
+			return errors.New("source signer regx is not specified")
+		}
+
+		switch signer.Type {
+		case SourceSignerS3:
+		case SourceSignerHeader:
+			if signer.Header == "" {
+				return errors.New("source signer header is not specified")
+			}
+		default:
+			return errors.New("available source signer type: s3, header")
+		}
+	}
+
+	for scheme := range p.Download.SourceClients {
+		if scheme == "" {
+			return errors.New("source client scheme is not specified")
+		}
+	}
+
 	switch p.Download.DefaultPattern {
 	case PatternP2P, PatternSeedPeer, PatternSource:
 	default:
@@ -181,6 +328,25 @@ type SchedulerOption struct {
 	ScheduleTimeout util.Duration `mapstructure:"scheduleTimeout" yaml:"scheduleTimeout"`
 	// DisableAutoBackSource indicates not back source normally, only scheduler says back source.
 	DisableAutoBackSource bool `mapstructure:"disableAutoBackSource" yaml:"disableAutoBackSource"`
+	// PieceResultBatch is the batch option for reporting piece results.
+	PieceResultBatch PieceResultBatchOption `mapstructure:"pieceResultBatch" yaml:"pieceResultBatch"`
+	// ParentExclusion is the option for hinting scheduler to exclude recently failed or slow parents.
+	ParentExclusion ParentExclusionOption `mapstructure:"parentExclusion" yaml:"parentExclusion"`
+}
+
+type PieceResultBatchOption struct {
+	// Size is the max count of successful piece results reported in one batch,
+	// batching is disabled when size is less than 2.
+	Size int `mapstructure:"size" yaml:"size"`
+	// Interval is the max duration of holding a piece result before it is reported.
+	Interval util.Duration `mapstructure:"interval" yaml:"interval"`
+}
+
+type ParentExclusionOption struct {
+	// TTL is the duration of excluding a failed or slow parent, exclusion is disabled when ttl is 0.
+	TTL util.Duration `mapstructure:"ttl" yaml:"ttl"`
+	// SlowPieceCost is the piece cost above which the parent is treated as slow, 0 means never.
+	SlowPieceCost util.Duration `mapstructure:"slowPieceCost" yaml:"slowPieceCost"`
 }
 
 type ManagerOption struct {
@@ -192,6 +358,21 @@ type ManagerOption struct {
 	RefreshInterval time.Duration `mapstructure:"refreshInterval" yaml:"refreshInterval"`
 	// SeedPeer configuration.
 	SeedPeer SeedPeerOption `mapstructure:"seedPeer" yaml:"seedPeer"`
+	// TLS configuration, the connection is plaintext when it is not set.
+	TLS *ManagerTLSOption `mapstructure:"tls" yaml:"tls"`
+}
+
+type ManagerTLSOption struct {
+	// CA is the file path to verify manager certificate, system roots are used when it is not set.
+	CA string `mapstructure:"ca" yaml:"ca"`
+	// Cert is the client certificate file path for mutual tls.
+	Cert string `mapstructure:"cert" yaml:"cert"`
+	// Key is the client key file path for mutual tls.
+	Key string `mapstructure:"key" yaml:"key"`
+	// ServerName overrides the server name to verify manager certificate.
+	ServerName string `mapstructure:"serverName" yaml:"serverName"`
+	// ReloadInterval is the interval of checking certificate files for rotation.
+	ReloadInterval time.Duration `mapstructure:"reloadInterval" yaml:"reloadInterval"`
 }
 
 type SeedPeerOption struct {
@@ -203,6 +384,34 @@ type SeedPeerOption struct {
 	ClusterID uint `mapstructure:"clusterID" yaml:"clusterID"`
 	// KeepAlive configuration.
 	KeepAlive KeepAliveOption `yaml:"keepAlive" mapstructure:"keepAlive"`
+	// Admission configuration.
+	Admission SeedAdmissionOption `yaml:"admission" mapstructure:"admission"`
+}
+
+type SeedAdmissionOption struct {
+	// Enable queues new seed tasks when the inbound bandwidth is saturated by running seed tasks.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+	// MaxBandwidth is the inbound bandwidth of seed peer.
+	MaxBandwidth util.RateLimit `yaml:"maxBandwidth" mapstructure:"maxBandwidth"`
+	// SystemReservedBandwidth is the inbound bandwidth reserved for other processes.
+	SystemReservedBandwidth util.RateLimit `yaml:"systemReservedBandwidth" mapstructure:"systemReservedBandwidth"`
+	// MaxQueueLength rejects new seed tasks as busy when the count of queued seed tasks reaches it,
+	// scheduler triggers the rejected seed tasks in other seed peers, 0 means no limit.
+	MaxQueueLength int `yaml:"maxQueueLength" mapstructure:"maxQueueLength"`
+	// DiskHighWatermark rejects new seed tasks as busy when the used percent of the disk
+	// storing data exceeds it, 0 means no limit.
+	DiskHighWatermark float64 `yaml:"diskHighWatermark" mapstructure:"diskHighWatermark"`
+}
+
+// Capacity returns the count of seed tasks running concurrently,
+// every seed task is expected to consume the per peer rate limit of inbound bandwidth.
+func (s SeedAdmissionOption) Capacity(perPeerRateLimit util.RateLimit) int {
+	available := s.MaxBandwidth.Limit - s.SystemReservedBandwidth.Limit
+	if perPeerRateLimit.Limit <= 0 || available <= perPeerRateLimit.Limit {
+		return 1
+	}
+
+	return int(available / perPeerRateLimit.Limit)
 }
 
 type KeepAliveOption struct {
@@ -225,6 +434,9 @@ type HostOption struct {
 	ListenIP string `mapstructure:"listenIP" yaml:"listenIP"`
 	// The ip report to scheduler, normal same with listen ip
 	AdvertiseIP string `mapstructure:"advertiseIP" yaml:"advertiseIP"`
+	// PreferIPv6 advertises the external IPv6 when advertise ip is not specified,
+	// and listens on all IPv4 and IPv6 interfaces instead of 0.0.0.0
+	PreferIPv6 bool `mapstructure:"preferIPv6" yaml:"preferIPv6"`
 }
 
 type DownloadOption struct {
@@ -240,6 +452,56 @@ type DownloadOption struct {
 	Prefetch             bool              `mapstructure:"prefetch" yaml:"prefetch"`
 	WatchdogTimeout      time.Duration     `mapstructure:"watchdogTimeout" yaml:"watchdogTimeout"`
 	Concurrent           *ConcurrentOption `mapstructure:"concurrent" yaml:"concurrent"`
+	// SourceSigners signs the back source requests of private origins
+	SourceSigners []*SourceSignerOption `mapstructure:"sourceSigners" yaml:"sourceSigners"`
+	// SourceClients is the options of back source clients by url scheme, like ftp, git-lfs and oss,
+	// the options of unregistered scheme are passed to the source plugin
+	SourceClients map[string]map[string]string `mapstructure:"sourceClients" yaml:"sourceClients"`
+	// LocalTransport transfers pieces between daemons on the same host by unix sockets
+	LocalTransport LocalTransportOption `mapstructure:"localTransport" yaml:"localTransport"`
+	// PieceNetwork pins the connections of piece downloading to a network interface,
+	// like the interface of storage network in data center
+	PieceNetwork NetworkOption `mapstructure:"pieceNetwork" yaml:"pieceNetwork"`
+	// TaskID canonicalizes the url and salts the task id with content version,
+	// it must be the same among the daemons downloading the same tasks
+	TaskID TaskIDOption `mapstructure:"taskID" yaml:"taskID"`
+}
+
+// TaskIDOption is the option of task id generation, it is shared with schedulers.
+type TaskIDOption = idgen.TaskIDConfig
+
+type NetworkOption struct {
+	// Interface is the network interface to bind, like eth1 or a SR-IOV VF, only supported in linux
+	Interface string `mapstructure:"interface" yaml:"interface"`
+	// Namespace is the linux net namespace where the interface is, like /proc/1/ns/net
+	Namespace string `mapstructure:"namespace" yaml:"namespace"`
+}
+
+type LocalTransportOption struct {
+	// Enable serves peer grpc and upload on unix sockets as well, and connects
+	// the parents on the same host by their unix sockets instead of tcp.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// SocketDir is the directory of unix sockets shared by daemons on the same host,
+	// sockets are in the abstract namespace when it is empty, which is only supported in linux.
+	SocketDir string `mapstructure:"socketDir" yaml:"socketDir"`
+}
+
+// PeerSocket returns the unix socket address of peer grpc which listens on ip and port.
+func (o LocalTransportOption) PeerSocket(ip string, port int) dfnet.NetAddr {
+	return o.socket(fmt.Sprintf("dfdaemon-peer-%s-%d.sock", ip, port))
+}
+
+// UploadSocket returns the unix socket address of upload server which listens on ip and port.
+func (o LocalTransportOption) UploadSocket(ip string, port int) dfnet.NetAddr {
+	return o.socket(fmt.Sprintf("dfdaemon-upload-%s-%d.sock", ip, port))
+}
+
+func (o LocalTransportOption) socket(name string) dfnet.NetAddr {
+	if o.SocketDir == "" {
+		return dfnet.NetAddr{Type: dfnet.UNIX, Addr: dfnet.UnixAbstractPrefix + "dragonfly/" + name}
+	}
+
+	return dfnet.NetAddr{Type: dfnet.UNIX, Addr: filepath.Join(o.SocketDir, name)}
 }
 
 type TransportOption struct {
@@ -265,6 +527,22 @@ type ConcurrentOption struct {
 	MaxBackoff float64 `mapstructure:"maxBackoff" yaml:"maxBackoff"`
 	// MaxAttempts for every piece failed,default: 3
 	MaxAttempts int `mapstructure:"maxAttempts" yaml:"maxAttempts"`
+	// PiecesPerRequest indicates the max continuous pieces downloaded in one ranged request,
+	// larger value reduces requests to high-latency source, default: 1
+	PiecesPerRequest int `mapstructure:"piecesPerRequest" yaml:"piecesPerRequest"`
+}
+
+type SourceSignerOption struct {
+	// Regx matches the url of back source requests
+	Regx *Regexp `mapstructure:"regx" yaml:"regx"`
+	// Type is the signer type, available: s3, header
+	Type string `mapstructure:"type" yaml:"type"`
+	// Region is the region of s3 signer, region of object storage in manager is used when empty
+	Region string `mapstructure:"region" yaml:"region"`
+	// Header is the header key of header signer
+	Header string `mapstructure:"header" yaml:"header"`
+	// Value is the header value of header signer
+	Value string `mapstructure:"value" yaml:"value"`
 }
 
 type ProxyOption struct {
@@ -278,6 +556,7 @@ type ProxyOption struct {
 	RegistryMirror     *RegistryMirror `mapstructure:"registryMirror" yaml:"registryMirror"`
 	WhiteList          []*WhiteList    `mapstructure:"whiteList" yaml:"whiteList"`
 	ProxyRules         []*ProxyRule    `mapstructure:"proxies" yaml:"proxies"`
+	Passthrough        []*Regexp       `mapstructure:"passthrough" yaml:"passthrough"`
 	HijackHTTPS        *HijackConfig   `mapstructure:"hijackHTTPS" yaml:"hijackHTTPS"`
 	DumpHTTPContent    bool            `mapstructure:"dumpHTTPContent" yaml:"dumpHTTPContent"`
 	// ExtraRegistryMirrors add more mirror for different ports
@@ -367,6 +646,7 @@ func (p *ProxyOption) unmarshal(unmarshal func(in []byte, out any) (err error),
 		RegistryMirror       *RegistryMirror   `mapstructure:"registryMirror" yaml:"registryMirror"`
 		WhiteList            []*WhiteList      `mapstructure:"whiteList" yaml:"whiteList"`
 		Proxies              []*ProxyRule      `mapstructure:"proxies" yaml:"proxies"`
+		Passthrough          []*Regexp         `mapstructure:"passthrough" yaml:"passthrough"`
 		HijackHTTPS          *HijackConfig     `mapstructure:"hijackHTTPS" yaml:"hijackHTTPS"`
 		DumpHTTPContent      bool              `mapstructure:"dumpHTTPContent" yaml:"dumpHTTPContent"`
 		ExtraRegistryMirrors []*RegistryMirror `mapstructure:"extraRegistryMirrors" yaml:"extraRegistryMirrors"`
@@ -379,6 +659,7 @@ func (p *ProxyOption) unmarshal(unmarshal func(in []byte, out any) (err error),
 	p.ListenOption = pt.ListenOption
 	p.RegistryMirror = pt.RegistryMirror
 	p.ProxyRules = pt.Proxies
+	p.Passthrough = pt.Passthrough
 	p.HijackHTTPS = pt.HijackHTTPS
 	p.WhiteList = pt.WhiteList
 	p.MaxConcurrency = pt.MaxConcurrency
@@ -394,6 +675,40 @@ func (p *ProxyOption) unmarshal(unmarshal func(in []byte, out any) (err error),
 type UploadOption struct {
 	ListenOption `yaml:",inline" mapstructure:",squash"`
 	RateLimit    util.RateLimit `mapstructure:"rateLimit" yaml:"rateLimit"`
+	// Auth authorizes piece downloading with per task tokens
+	Auth UploadAuthOption `mapstructure:"auth" yaml:"auth"`
+	// PerPeer isolates the upload concurrency of remote peers
+	PerPeer UploadPerPeerOption `mapstructure:"perPeer" yaml:"perPeer"`
+	// HTTP2 multiplexes piece requests over one connection per peer
+	HTTP2 UploadHTTP2Option `mapstructure:"http2" yaml:"http2"`
+}
+
+type UploadHTTP2Option struct {
+	// Enable serves pieces with http2 and downloads pieces from parents with http2,
+	// h2c is used when tls is disabled and falls back to http/1.1 for the parents without http2,
+	// http/1.1 requests are still served
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// MaxConcurrentStreams limits the concurrent piece requests in one connection, 0 means the default of golang
+	MaxConcurrentStreams uint32 `mapstructure:"maxConcurrentStreams" yaml:"maxConcurrentStreams"`
+}
+
+type UploadPerPeerOption struct {
+	// Concurrency limits the concurrent piece uploads to one remote peer, 0 means no limit
+	Concurrency int `mapstructure:"concurrency" yaml:"concurrency"`
+	// QueueSize is the max waiting requests of one remote peer when its concurrency is reached,
+	// the requests are rejected with 429 when the queue is full
+	QueueSize int `mapstructure:"queueSize" yaml:"queueSize"`
+	// QueueTimeout is the max waiting time in queue, the requests are rejected with 429 after timeout
+	QueueTimeout time.Duration `mapstructure:"queueTimeout" yaml:"queueTimeout"`
+}
+
+type UploadAuthOption struct {
+	// Enable per task token authorization of upload service
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// Secret is shared by peers in cluster to sign and verify tokens
+	Secret string `mapstructure:"secret" yaml:"secret"`
+	// TokenTTL is the lifetime of tokens
+	TokenTTL time.Duration `mapstructure:"tokenTTL" yaml:"tokenTTL"`
 }
 
 type ObjectStorageOption struct {
@@ -405,10 +720,22 @@ type ObjectStorageOption struct {
 	Filter string `mapstructure:"filter" yaml:"filter"`
 	// MaxReplicas is the maximum number of replicas of an object cache in seed peers.
 	MaxReplicas int `mapstructure:"maxReplicas" yaml:"maxReplicas"`
+	// MultipartUpload is used to write large objects to backend in parts concurrently.
+	MultipartUpload MultipartUploadOption `mapstructure:"multipartUpload" yaml:"multipartUpload"`
 	// ListenOption is object storage service listener.
 	ListenOption `yaml:",inline" mapstructure:",squash"`
 }
 
+type MultipartUploadOption struct {
+	// Threshold is the min size of objects written to backend by multipart upload,
+	// 0 disables multipart upload, it only works on backends supporting multipart upload.
+	Threshold unit.Bytes `mapstructure:"threshold" yaml:"threshold"`
+	// PartSize is the size of parts, it is at least 5MB.
+	PartSize unit.Bytes `mapstructure:"partSize" yaml:"partSize"`
+	// Concurrency is the count of parts uploaded concurrently.
+	Concurrency int `mapstructure:"concurrency" yaml:"concurrency"`
+}
+
 type ListenOption struct {
 	Security   SecurityOption    `mapstructure:"security" yaml:"security"`
 	TCPListen  *TCPListenOption  `mapstructure:"tcpListen,omitempty" yaml:"tcpListen,omitempty"`
@@ -431,6 +758,10 @@ type TCPListenOption struct {
 	// Namespace stands the linux net namespace, like /proc/1/ns/net
 	// It's useful for running daemon in pod with ip allocated and listen in host
 	Namespace string `mapstructure:"namespace" yaml:"namespace"`
+
+	// Interface stands the network interface to bind, like eth1 or a SR-IOV VF, it's looked up in Namespace.
+	// The ip of interface is listened when Listen is unspecified, like 0.0.0.0, only supported in linux
+	Interface string `mapstructure:"interface" yaml:"interface"`
 }
 
 type TCPListenPortRange struct {
@@ -540,6 +871,36 @@ type StorageOption struct {
 	// Multiplex indicates reusing underlying storage for same task id
 	Multiplex     bool          `mapstructure:"multiplex" yaml:"multiplex"`
 	StoreStrategy StoreStrategy `mapstructure:"strategy" yaml:"strategy"`
+	// MmapRead indicates serving pieces of finished tasks with memory mapped data file,
+	// it reduces syscalls for hot tasks, the data file must not be truncated by others when enabled
+	MmapRead bool `mapstructure:"mmapRead" yaml:"mmapRead"`
+	// RevalidateInterval indicates the duration after which completed tasks are revalidated with origin
+	// by conditional requests before reused, the cached pieces are reused when origin is not modified,
+	// 0 disables revalidation
+	RevalidateInterval util.Duration `mapstructure:"revalidateInterval" yaml:"revalidateInterval"`
+	// PartialReclaim indicates reclaiming the downloaded pieces of expired unfinished tasks by punching
+	// holes in data files, the metadata is kept for resuming until the task expires again, only works on linux
+	PartialReclaim bool `mapstructure:"partialReclaim" yaml:"partialReclaim"`
+	// MemoryTier promotes hot finished tasks from disk to memory and demotes cold ones
+	MemoryTier MemoryTierOption `mapstructure:"memoryTier" yaml:"memoryTier"`
+	// Dedup indicates sharing on-disk storage of identical pieces across tasks by piece digest,
+	// only works on linux filesystems supporting reflink, e.g. btrfs and xfs
+	Dedup bool `mapstructure:"dedup" yaml:"dedup"`
+	// MaxPinTTL indicates the max duration of pinning tasks, pinned tasks are excluded from gc,
+	// pinning without ttl or with a longer ttl is limited to it
+	MaxPinTTL util.Duration `mapstructure:"maxPinTTL" yaml:"maxPinTTL"`
+}
+
+type MemoryTierOption struct {
+	// Enable serving pieces of hot tasks from memory
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// Watermark is the max bytes of task data held in memory
+	Watermark unit.Bytes `mapstructure:"watermark" yaml:"watermark"`
+	// MaxTaskSize is the max content length of promoted tasks, larger tasks are always served from disk
+	MaxTaskSize unit.Bytes `mapstructure:"maxTaskSize" yaml:"maxTaskSize"`
+	// HotThreshold is the heat of tasks to be promoted, the heat is the count of piece requests
+	// in every gc interval, and it is decayed by half in every interval
+	HotThreshold int64 `mapstructure:"hotThreshold" yaml:"hotThreshold"`
 }
 
 type StoreStrategy string
@@ -549,10 +910,43 @@ type HealthOption struct {
 	Path         string `mapstructure:"path" yaml:"path"`
 }
 
+// DebugOption is the option of debug http server, which serves timing summaries of peer tasks
+// in /debug/tasks/{taskID} for troubleshooting without tracing deployment.
+type DebugOption struct {
+	ListenOption `yaml:",inline" mapstructure:",squash"`
+}
+
 type ReloadOption struct {
 	Interval util.Duration `mapstructure:"interval" yaml:"interval"`
 }
 
+type MountOption struct {
+	// Enable serves the completed tasks as a read-only fuse filesystem,
+	// applications open the cached content without copying it to an output path.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// Dir is the mount point, tasks are in tasks/<task id> and urls/<scheme>/<host>/<path>.
+	Dir string `mapstructure:"dir" yaml:"dir"`
+	// AllowOther allows other users to access the mount, user_allow_other is required in /etc/fuse.conf.
+	AllowOther bool `mapstructure:"allowOther" yaml:"allowOther"`
+}
+
+type PeerExchangeOption struct {
+	// Enable advertises finished tasks to daemons in the same subnet,
+	// and downloads from them when scheduler is unreachable.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// ListenPort is the udp port receiving peer exchange messages.
+	ListenPort int `mapstructure:"listenPort" yaml:"listenPort"`
+	// Seeds are udp addresses of neighbor daemons, like 192.168.1.2:65006.
+	Seeds []string `mapstructure:"seeds" yaml:"seeds"`
+	// Subnets are cidrs of neighbor daemons, like 192.168.1.0/24,
+	// messages are only accepted from seeds and daemons in subnets.
+	Subnets []string `mapstructure:"subnets" yaml:"subnets"`
+	// Interval is the interval of advertising finished tasks.
+	Interval util.Duration `mapstructure:"interval" yaml:"interval"`
+	// TTL is the expire time of tasks advertised by neighbors.
+	TTL util.Duration `mapstructure:"ttl" yaml:"ttl"`
+}
+
 type FileString string
 
 func (f *FileString) UnmarshalJSON(b []byte) error {
@@ -765,8 +1159,24 @@ type ProxyRule struct {
 	UseHTTPS bool    `yaml:"useHTTPS" mapstructure:"useHTTPS"`
 	Direct   bool    `yaml:"direct" mapstructure:"direct"`
 
-	// Redirect is the host to redirect to, if not empty
+	// Redirect is the host to redirect to, if not empty.
+	// When it contains "/", it is a regexp replacement to rewrite the url, like "https://d7y.io/$1".
 	Redirect string `yaml:"redirect" mapstructure:"redirect"`
+
+	// Tag is the tag of the matched requests without X-Dragonfly-Tag header.
+	Tag string `yaml:"tag" mapstructure:"tag"`
+
+	// Application is the application of the matched requests without X-Dragonfly-Application header.
+	Application string `yaml:"application" mapstructure:"application"`
+
+	// Filter is the filter of the matched requests without X-Dragonfly-Filter header.
+	Filter string `yaml:"filter" mapstructure:"filter"`
+
+	// DigestHeader is the request header carrying the digest of content, like "sha256:xxx".
+	DigestHeader string `yaml:"digestHeader" mapstructure:"digestHeader"`
+
+	// MinSize is the content length threshold, the matched requests smaller than it are proxied directly.
+	MinSize unit.Bytes `yaml:"minSize" mapstructure:"minSize"`
 }
 
 func NewProxyRule(regx string, useHTTPS bool, direct bool, redirect string) (*ProxyRule, error) {
@@ -57,11 +57,17 @@ var peerHostConfig = func() *DaemonOption {
 				},
 			},
 			ScheduleTimeout: util.Duration{Duration: DefaultScheduleTimeout},
+			PieceResultBatch: PieceResultBatchOption{
+				Interval: util.Duration{Duration: DefaultPieceResultBatchInterval},
+			},
+			ParentExclusion: ParentExclusionOption{
+				TTL: util.Duration{Duration: DefaultParentExclusionTTL},
+			},
 		},
 		Host: HostOption{
 			Hostname:       fqdn.FQDNHostname,
 			ListenIP:       net.IPv4zero.String(),
-			AdvertiseIP:    ip.IPv4,
+			AdvertiseIP:    ip.Advertise(false),
 			SecurityDomain: "",
 			Location:       "",
 			IDC:            "",
@@ -116,11 +122,24 @@ var peerHostConfig = func() *DaemonOption {
 					},
 				},
 			},
+			Auth: UploadAuthOption{
+				Enable:   false,
+				TokenTTL: DefaultUploadTokenTTL,
+			},
+			PerPeer: UploadPerPeerOption{
+				QueueSize:    DefaultUploadPerPeerQueueSize,
+				QueueTimeout: DefaultUploadPerPeerQueueTimeout,
+			},
 		},
 		ObjectStorage: ObjectStorageOption{
 			Enable:      false,
 			Filter:      "Expires&Signature&ns",
 			MaxReplicas: DefaultObjectMaxReplicas,
+			MultipartUpload: MultipartUploadOption{
+				Threshold:   DefaultObjectMultipartThreshold,
+				PartSize:    DefaultObjectMultipartPartSize,
+				Concurrency: DefaultObjectMultipartConcurrency,
+			},
 			ListenOption: ListenOption{
 				Security: SecurityOption{
 					Insecure:  true,
@@ -151,9 +170,17 @@ var peerHostConfig = func() *DaemonOption {
 			TaskExpireTime: util.Duration{
 				Duration: DefaultTaskExpireTime,
 			},
+			MaxPinTTL: util.Duration{
+				Duration: DefaultMaxPinTTL,
+			},
 			StoreStrategy:          AdvanceLocalTaskStoreStrategy,
 			Multiplex:              false,
 			DiskGCThresholdPercent: 95,
+			MemoryTier: MemoryTierOption{
+				Watermark:    DefaultMemoryTierWatermark,
+				MaxTaskSize:  DefaultMemoryTierMaxTaskSize,
+				HotThreshold: DefaultMemoryTierHotThreshold,
+			},
 		},
 		Health: &HealthOption{
 			ListenOption: ListenOption{
@@ -171,10 +198,35 @@ var peerHostConfig = func() *DaemonOption {
 			},
 			Path: "/server/ping",
 		},
+		Debug: &DebugOption{
+			ListenOption: ListenOption{
+				Security: SecurityOption{
+					Insecure:  true,
+					TLSVerify: false,
+				},
+				TCPListen: &TCPListenOption{
+					Listen: "127.0.0.1",
+					PortRange: TCPListenPortRange{
+						Start: DefaultDebugStartPort,
+						End:   DefaultEndPort,
+					},
+				},
+			},
+		},
 		Reload: ReloadOption{
 			Interval: util.Duration{
 				Duration: time.Minute,
 			},
 		},
+		PeerExchange: PeerExchangeOption{
+			Enable:     false,
+			ListenPort: DefaultPeerExchangePort,
+			Interval: util.Duration{
+				Duration: DefaultPeerExchangeInterval,
+			},
+			TTL: util.Duration{
+				Duration: DefaultPeerExchangeTTL,
+			},
+		},
 	}
 }
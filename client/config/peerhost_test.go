@@ -23,12 +23,14 @@ import (
 	"time"
 
 	testifyassert "github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 	"gopkg.in/yaml.v3"
 
 	"d7y.io/dragonfly/v2/client/util"
 	"d7y.io/dragonfly/v2/cmd/dependency/base"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/pkg/dfnet"
+	netip "d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/pkg/unit"
 )
 
@@ -218,6 +220,7 @@ func TestPeerHostOption_Load(t *testing.T) {
 
 	proxyExp, _ := NewRegexp("blobs/sha256.*")
 	hijackExp, _ := NewRegexp("mirror.aliyuncs.com:443")
+	passthroughExp, _ := NewRegexp("blobs/sha256/direct.*")
 
 	peerHostOption := &DaemonOption{
 		Options: base.Options{
@@ -251,6 +254,13 @@ func TestPeerHostOption_Load(t *testing.T) {
 					},
 				},
 				RefreshInterval: 5 * time.Minute,
+				TLS: &ManagerTLSOption{
+					CA:             "ca.crt",
+					Cert:           "client.crt",
+					Key:            "client.key",
+					ServerName:     "manager",
+					ReloadInterval: 30 * time.Second,
+				},
 				SeedPeer: SeedPeerOption{
 					Enable:    false,
 					Type:      model.SeedPeerTypeStrongSeed,
@@ -337,11 +347,37 @@ func TestPeerHostOption_Load(t *testing.T) {
 				ThresholdSize: util.Size{
 					Limit: 1,
 				},
-				ThresholdSpeed: unit.Bytes(1),
-				GoroutineCount: 1,
-				InitBackoff:    1,
-				MaxBackoff:     1,
-				MaxAttempts:    1,
+				ThresholdSpeed:   unit.Bytes(1),
+				GoroutineCount:   1,
+				InitBackoff:      1,
+				MaxBackoff:       1,
+				MaxAttempts:      1,
+				PiecesPerRequest: 1,
+			},
+			SourceSigners: []*SourceSignerOption{
+				{
+					Regx:   proxyExp,
+					Type:   SourceSignerHeader,
+					Header: "Authorization",
+					Value:  "Bearer token",
+				},
+			},
+			SourceClients: map[string]map[string]string{
+				"ftp": {
+					"username": "foo",
+					"password": "bar",
+				},
+			},
+			PieceNetwork: NetworkOption{
+				Interface: "eth1",
+				Namespace: "/proc/1/ns/net",
+			},
+			TaskID: TaskIDOption{
+				SortQuery:          true,
+				LowercaseHost:      true,
+				StripTrackingQuery: true,
+				StripQuery:         []string{"session"},
+				VersionHeader:      "X-Content-Version",
 			},
 		},
 		Upload: UploadOption{
@@ -362,13 +398,33 @@ func TestPeerHostOption_Load(t *testing.T) {
 						Start: 65002,
 						End:   0,
 					},
+					Interface: "eth1",
 				},
 			},
+			Auth: UploadAuthOption{
+				Enable:   true,
+				Secret:   "secret",
+				TokenTTL: time.Minute,
+			},
+			PerPeer: UploadPerPeerOption{
+				Concurrency:  4,
+				QueueSize:    8,
+				QueueTimeout: 500 * time.Millisecond,
+			},
+			HTTP2: UploadHTTP2Option{
+				Enable:               true,
+				MaxConcurrentStreams: 100,
+			},
 		},
 		ObjectStorage: ObjectStorageOption{
 			Enable:      true,
 			Filter:      "Expires&Signature&ns",
 			MaxReplicas: 3,
+			MultipartUpload: MultipartUploadOption{
+				Threshold:   128 * unit.MB,
+				PartSize:    32 * unit.MB,
+				Concurrency: 8,
+			},
 			ListenOption: ListenOption{
 				Security: SecurityOption{
 					Insecure:  true,
@@ -395,10 +451,36 @@ func TestPeerHostOption_Load(t *testing.T) {
 			DiskGCThreshold:        60 * unit.MB,
 			DiskGCThresholdPercent: 0.6,
 			Multiplex:              true,
+			MmapRead:               true,
+			RevalidateInterval: util.Duration{
+				Duration: time.Minute,
+			},
+			PartialReclaim: true,
+			MemoryTier: MemoryTierOption{
+				Enable:       true,
+				Watermark:    512 * unit.MB,
+				MaxTaskSize:  64 * unit.MB,
+				HotThreshold: 32,
+			},
+			Dedup: true,
+			MaxPinTTL: util.Duration{
+				Duration: 24 * time.Hour,
+			},
 		},
 		Health: &HealthOption{
 			Path: "/health",
 		},
+		Debug: &DebugOption{
+			ListenOption: ListenOption{
+				TCPListen: &TCPListenOption{
+					Listen: "127.0.0.1",
+					PortRange: TCPListenPortRange{
+						Start: 40902,
+						End:   0,
+					},
+				},
+			},
+		},
 		Proxy: &ProxyOption{
 			ListenOption: ListenOption{
 				Security: SecurityOption{
@@ -448,12 +530,18 @@ func TestPeerHostOption_Load(t *testing.T) {
 			},
 			ProxyRules: []*ProxyRule{
 				{
-					Regx:     proxyExp,
-					UseHTTPS: false,
-					Direct:   false,
-					Redirect: "d7y.io",
+					Regx:         proxyExp,
+					UseHTTPS:     false,
+					Direct:       false,
+					Redirect:     "d7y.io",
+					Tag:          "tag",
+					Application:  "application",
+					Filter:       "Expires&Signature",
+					DigestHeader: "X-Checksum",
+					MinSize:      unit.MB,
 				},
 			},
+			Passthrough: []*Regexp{passthroughExp},
 			HijackHTTPS: &HijackConfig{
 				Cert: "cert",
 				Key:  "key",
@@ -486,6 +574,23 @@ func TestPeerHostOption_Load(t *testing.T) {
 				Duration: 180000000000,
 			},
 		},
+		PeerExchange: PeerExchangeOption{
+			Enable:     true,
+			ListenPort: 65006,
+			Seeds:      []string{"127.0.0.1:65016"},
+			Subnets:    []string{"192.168.1.0/24"},
+			Interval: util.Duration{
+				Duration: 30 * time.Second,
+			},
+			TTL: util.Duration{
+				Duration: 2 * time.Minute,
+			},
+		},
+		Mount: MountOption{
+			Enable:     true,
+			Dir:        "/var/lib/dragonfly/mount",
+			AllowOther: true,
+		},
 	}
 
 	peerHostOptionYAML := &DaemonOption{}
@@ -495,3 +600,37 @@ func TestPeerHostOption_Load(t *testing.T) {
 
 	assert.EqualValues(peerHostOption, peerHostOptionYAML)
 }
+
+func TestPeerHostOption_ConvertPreferIPv6(t *testing.T) {
+	assert := testifyassert.New(t)
+	ipv4, ipv6 := netip.IPv4, netip.IPv6
+	defer func() {
+		netip.IPv4, netip.IPv6 = ipv4, ipv6
+	}()
+	netip.IPv4, netip.IPv6 = "192.168.1.1", "fd00::1"
+
+	cfg := NewDaemonConfig()
+	cfg.Host.AdvertiseIP = "0.0.0.0"
+	cfg.Host.PreferIPv6 = true
+	assert.Nil(cfg.Convert())
+	assert.Equal("fd00::1", cfg.Host.AdvertiseIP)
+	assert.Equal("::", cfg.Upload.TCPListen.Listen)
+
+	cfg = NewDaemonConfig()
+	cfg.Host.AdvertiseIP = "192.168.1.2"
+	cfg.Host.PreferIPv6 = true
+	assert.Nil(cfg.Convert())
+	assert.Equal("192.168.1.2", cfg.Host.AdvertiseIP)
+}
+
+func TestSeedAdmissionOption_Capacity(t *testing.T) {
+	assert := testifyassert.New(t)
+	admission := SeedAdmissionOption{
+		Enable:                  true,
+		MaxBandwidth:            util.RateLimit{Limit: rate.Limit(1000 * unit.MB)},
+		SystemReservedBandwidth: util.RateLimit{Limit: rate.Limit(200 * unit.MB)},
+	}
+	assert.Equal(8, admission.Capacity(util.RateLimit{Limit: rate.Limit(100 * unit.MB)}))
+	assert.Equal(1, admission.Capacity(util.RateLimit{Limit: rate.Limit(1000 * unit.MB)}))
+	assert.Equal(1, admission.Capacity(util.RateLimit{}))
+}
@@ -25,12 +25,14 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"runtime"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/net/http2"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
@@ -44,6 +46,7 @@ import (
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/gc"
 	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	"d7y.io/dragonfly/v2/client/daemon/mount"
 	"d7y.io/dragonfly/v2/client/daemon/objectstorage"
 	"d7y.io/dragonfly/v2/client/daemon/peer"
 	"d7y.io/dragonfly/v2/client/daemon/proxy"
@@ -57,11 +60,14 @@ import (
 	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/dfpath"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	netip "d7y.io/dragonfly/v2/pkg/net/ip"
+	"d7y.io/dragonfly/v2/pkg/net/netns"
 	"d7y.io/dragonfly/v2/pkg/resolver"
 	"d7y.io/dragonfly/v2/pkg/rpc"
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
 	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/source/signers"
 )
 
 type Daemon interface {
@@ -91,6 +97,13 @@ type clientDaemon struct {
 
 	PeerTaskManager peer.TaskManager
 	PieceManager    peer.PieceManager
+	PeerExchange    peer.PeerExchange
+	Mount           mount.Mount
+
+	// downloadLimiter and uploadLimiter are shared with piece manager and upload manager,
+	// so rate limits can be reloaded at runtime.
+	downloadLimiter *rate.Limiter
+	uploadLimiter   *rate.Limiter
 
 	dynconfig       config.Dynconfig
 	dfpath          dfpath.Dfpath
@@ -102,6 +115,19 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	// update plugin directory
 	source.UpdatePluginDir(d.PluginDir())
 
+	// task id is generated with the canonicalized url and content version
+	idgen.SetTaskIDOptions(opt.Download.TaskID.Options())
+
+	// pieces are downloaded from the upload service, so the ip of its interface is advertised
+	if listen := opt.Upload.TCPListen; listen != nil && listen.Interface != "" {
+		if err := netns.Do(listen.Namespace, func() (err error) {
+			opt.Host.AdvertiseIP, err = netip.InterfaceIP(listen.Interface, opt.Host.PreferIPv6)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to get ip of upload interface %s: %w", listen.Interface, err)
+		}
+	}
+
 	host := &schedulerv1.PeerHost{
 		Id:             idgen.HostID(opt.Host.Hostname, int32(opt.Download.PeerGRPC.TCPListen.PortRange.Start)),
 		Ip:             opt.Host.AdvertiseIP,
@@ -130,6 +156,14 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 			)
 		}
 
+		if tlsOption := opt.Scheduler.Manager.TLS; tlsOption != nil {
+			certReloader, err := rpc.NewCertReloader(tlsOption.Cert, tlsOption.Key, tlsOption.CA, tlsOption.ReloadInterval)
+			if err != nil {
+				return nil, err
+			}
+			managerDialOptions = append(managerDialOptions, grpc.WithTransportCredentials(certReloader.ClientCredentials(tlsOption.ServerName)))
+		}
+
 		var err error
 		managerClient, err = managerclient.GetClientByAddr(opt.Scheduler.Manager.NetAddrs, managerDialOptions...)
 		if err != nil {
@@ -159,6 +193,14 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	resolver.RegisterScheduler(dynconfig)
 	balancer.Register(pkgbalancer.NewConsistentHashingBuilder())
 
+	// register signers of back source requests.
+	registerSourceSigners(opt.Download.SourceSigners, dynconfig)
+
+	// configure source clients by scheme, seed peer downloads from source with them too.
+	if err := configureSourceClients(opt.Download.SourceClients); err != nil {
+		return nil, err
+	}
+
 	var schedulerClientOptions []grpc.DialOption
 	if opt.Options.Telemetry.Jaeger != "" {
 		schedulerClientOptions = append(schedulerClientOptions,
@@ -191,18 +233,49 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		return nil, err
 	}
 
+	// Pieces are downloaded from upload service of other peers,
+	// so the security and auth options of upload service are used.
+	pieceDownloadTLSConfig, err := loadPieceDownloadTLSConfig(opt.Upload.Security)
+	if err != nil {
+		return nil, err
+	}
+
+	var pieceDownloadAuthSecret string
+	if opt.Upload.Auth.Enable {
+		pieceDownloadAuthSecret = opt.Upload.Auth.Secret
+	}
+
+	// Parents on the same host are connected by unix sockets when local transport is enabled.
+	localTransport := peer.NewLocalTransport(opt.Download.LocalTransport, opt.Host.AdvertiseIP)
+
+	downloadLimiter := rate.NewLimiter(opt.Download.TotalRateLimit.Limit, int(opt.Download.TotalRateLimit.Limit))
 	pieceManager, err := peer.NewPieceManager(
 		opt.Download.PieceDownloadTimeout,
-		peer.WithLimiter(rate.NewLimiter(opt.Download.TotalRateLimit.Limit, int(opt.Download.TotalRateLimit.Limit))),
+		peer.WithLimiter(downloadLimiter),
 		peer.WithCalculateDigest(opt.Download.CalculateDigest), peer.WithTransportOption(opt.Download.Transport),
 		peer.WithConcurrentOption(opt.Download.Concurrent),
+		peer.WithPieceDownloaderOptions(
+			peer.WithTLSConfig(pieceDownloadTLSConfig),
+			peer.WithAuthToken(pieceDownloadAuthSecret, opt.Upload.Auth.TokenTTL),
+			peer.WithLocalTransport(localTransport),
+			peer.WithNetwork(opt.Download.PieceNetwork),
+			peer.WithHTTP2(opt.Upload.HTTP2.Enable),
+		),
 	)
 	if err != nil {
 		return nil, err
 	}
+	var peerExchange peer.PeerExchange
+	if opt.PeerExchange.Enable {
+		peerExchange, err = peer.NewPeerExchange(host, opt.PeerExchange)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	peerTaskManager, err := peer.NewPeerTaskManager(host, pieceManager, storageManager, sched, opt.Scheduler,
 		opt.Download.PerPeerRateLimit.Limit, opt.Storage.Multiplex, opt.Download.Prefetch, opt.Download.CalculateDigest,
-		opt.Download.GetPiecesMaxRetry, opt.Download.WatchdogTimeout)
+		opt.Download.GetPiecesMaxRetry, opt.Download.WatchdogTimeout, peerExchange, localTransport)
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +310,20 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 			grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()),
 		)
 	}
-	rpcManager, err := rpcserver.New(host, peerTaskManager, storageManager, defaultPattern, downloadServerOption, peerServerOption)
+	var rpcOptions []rpcserver.Option
+	if seedPeer := opt.Scheduler.Manager.SeedPeer; seedPeer.Enable && seedPeer.Admission.Enable {
+		rpcOptions = append(rpcOptions, rpcserver.WithSeedAdmission(
+			seedPeer.Admission.Capacity(opt.Download.PerPeerRateLimit),
+			seedPeer.Admission.MaxQueueLength,
+			seedPeer.Admission.DiskHighWatermark))
+	}
+
+	uploadLimiter := rate.NewLimiter(opt.Upload.RateLimit.Limit, int(opt.Upload.RateLimit.Limit))
+	rpcOptions = append(rpcOptions,
+		rpcserver.WithRateLimiters(downloadLimiter, uploadLimiter, opt.Download.PerPeerRateLimit.Limit),
+		rpcserver.WithDynconfig(dynconfig))
+
+	rpcManager, err := rpcserver.New(host, peerTaskManager, storageManager, defaultPattern, downloadServerOption, peerServerOption, rpcOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +334,7 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	}
 
 	uploadManager, err := upload.NewUploadManager(opt, storageManager, d.LogDir(),
-		upload.WithLimiter(rate.NewLimiter(opt.Upload.RateLimit.Limit, int(opt.Upload.RateLimit.Limit))))
+		upload.WithLimiter(uploadLimiter))
 	if err != nil {
 		return nil, err
 	}
@@ -261,6 +347,11 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		}
 	}
 
+	var fsMount mount.Mount
+	if opt.Mount.Enable {
+		fsMount = mount.New(opt.Mount, storageManager)
+	}
+
 	return &clientDaemon{
 		once:            &sync.Once{},
 		done:            make(chan bool),
@@ -269,11 +360,15 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		RPCManager:      rpcManager,
 		PeerTaskManager: peerTaskManager,
 		PieceManager:    pieceManager,
+		PeerExchange:    peerExchange,
+		Mount:           fsMount,
 		ProxyManager:    proxyManager,
 		UploadManager:   uploadManager,
 		ObjectStorage:   objectStorage,
 		StorageManager:  storageManager,
 		GCManager:       gc.NewManager(opt.GCInterval.Duration),
+		downloadLimiter: downloadLimiter,
+		uploadLimiter:   uploadLimiter,
 		dynconfig:       dynconfig,
 		dfpath:          d,
 		managerClient:   managerClient,
@@ -281,6 +376,38 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	}, nil
 }
 
+// loadPieceDownloadTLSConfig loads tls config of downloading pieces from upload service,
+// the certificate is sent to upload service as client certificate in mutual tls.
+func loadPieceDownloadTLSConfig(opt config.SecurityOption) (*tls.Config, error) {
+	if opt.Insecure {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if opt.CACert != "" {
+		caCert, err := os.ReadFile(opt.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to add CA's certificate")
+		}
+		tlsConfig.RootCAs = certPool
+	}
+
+	if opt.Cert != "" && opt.Key != "" {
+		cert, err := tls.LoadX509KeyPair(opt.Cert, opt.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
 func loadGPRCTLSCredentials(opt config.SecurityOption) (credentials.TransportCredentials, error) {
 	// Load certificate of the CA who signed client's certificate
 	pemClientCA, err := os.ReadFile(opt.CACert)
@@ -316,22 +443,6 @@ func loadGPRCTLSCredentials(opt config.SecurityOption) (credentials.TransportCre
 }
 
 func (*clientDaemon) prepareTCPListener(opt config.ListenOption, withTLS bool) (net.Listener, int, error) {
-	if len(opt.TCPListen.Namespace) > 0 {
-		runtime.LockOSThread()
-		defer runtime.UnlockOSThread()
-		recoverFunc, err := switchNetNamespace(opt.TCPListen.Namespace)
-		if err != nil {
-			logger.Errorf("failed to change net namespace: %v", err)
-			return nil, -1, err
-		}
-		defer func() {
-			err := recoverFunc()
-			if err != nil {
-				logger.Errorf("failed to recover net namespace: %v", err)
-			}
-		}()
-	}
-
 	var (
 		ln   net.Listener
 		port int
@@ -341,7 +452,22 @@ func (*clientDaemon) prepareTCPListener(opt config.ListenOption, withTLS bool) (
 		return nil, -1, errors.New("empty tcp listen option")
 	}
 
-	ln, port, err = rpc.ListenWithPortRange(opt.TCPListen.Listen, opt.TCPListen.PortRange.Start, opt.TCPListen.PortRange.End)
+	// the socket is created in the net namespace and bound to the interface
+	err = netns.Do(opt.TCPListen.Namespace, func() error {
+		var lc net.ListenConfig
+		listen := opt.TCPListen.Listen
+		if opt.TCPListen.Interface != "" {
+			lc.Control = netns.BindToDevice(opt.TCPListen.Interface)
+			if addr := net.ParseIP(listen); addr == nil || addr.IsUnspecified() {
+				if listen, err = netip.InterfaceIP(opt.TCPListen.Interface, addr != nil && addr.To4() == nil); err != nil {
+					return err
+				}
+			}
+		}
+
+		ln, port, err = rpc.ListenWithPortRangeAndConfig(lc, listen, opt.TCPListen.PortRange.Start, opt.TCPListen.PortRange.End)
+		return err
+	})
 	if err != nil {
 		return nil, -1, err
 	}
@@ -351,8 +477,39 @@ func (*clientDaemon) prepareTCPListener(opt config.ListenOption, withTLS bool) (
 		return ln, port, err
 	}
 
+	ln, err = wrapTLSListener(opt, ln)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return ln, port, nil
+}
+
+// prepareLocalListener listens on the unix socket of local transport,
+// the stale socket file is removed before listening.
+func (*clientDaemon) prepareLocalListener(opt config.ListenOption, socket dfnet.NetAddr, withTLS bool) (net.Listener, error) {
+	if !strings.HasPrefix(socket.Addr, dfnet.UnixAbstractPrefix) {
+		if err := os.MkdirAll(filepath.Dir(socket.Addr), 0755); err != nil {
+			return nil, err
+		}
+		_ = os.Remove(socket.Addr)
+	}
+
+	ln, err := rpc.Listen(socket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !withTLS || opt.Security.Insecure {
+		return ln, nil
+	}
+
+	return wrapTLSListener(opt, ln)
+}
+
+func wrapTLSListener(opt config.ListenOption, ln net.Listener) (net.Listener, error) {
 	if opt.Security.Cert == "" || opt.Security.Key == "" {
-		return nil, -1, errors.New("empty cert or key for tls")
+		return nil, errors.New("empty cert or key for tls")
 	}
 
 	// Create the TLS ClientOption with the CA pool and enable Client certificate validation
@@ -364,7 +521,7 @@ func (*clientDaemon) prepareTCPListener(opt config.ListenOption, withTLS bool) (
 	if opt.Security.CACert != "" {
 		caCert, err := os.ReadFile(opt.Security.CACert)
 		if err != nil {
-			return nil, -1, err
+			return nil, err
 		}
 		caCertPool := x509.NewCertPool()
 		caCertPool.AppendCertsFromPEM(caCert)
@@ -374,18 +531,31 @@ func (*clientDaemon) prepareTCPListener(opt config.ListenOption, withTLS bool) (
 		}
 	}
 
+	var err error
 	tlsConfig.Certificates = make([]tls.Certificate, 1)
 	tlsConfig.Certificates[0], err = tls.LoadX509KeyPair(opt.Security.Cert, opt.Security.Key)
 	if err != nil {
-		return nil, -1, err
+		return nil, err
 	}
 
-	return tls.NewListener(ln, tlsConfig), port, nil
+	return tls.NewListener(ln, tlsConfig), nil
+}
+
+// withHTTP2NextProtos returns a copy of tls config which negotiates h2 by alpn.
+func withHTTP2NextProtos(tlsConfig *tls.Config) *tls.Config {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	} else {
+		tlsConfig = tlsConfig.Clone()
+	}
+
+	tlsConfig.NextProtos = []string{http2.NextProtoTLS, "http/1.1"}
+	return tlsConfig
 }
 
 func (cd *clientDaemon) Serve() error {
 	var (
-		watchers []func(daemon *config.DaemonOption)
+		watchers = cd.reloadWatchers()
 		interval = cd.Option.Reload.Interval.Duration
 	)
 	cd.GCManager.Start()
@@ -418,7 +588,11 @@ func (cd *clientDaemon) Serve() error {
 	if cd.Option.Upload.TCPListen == nil {
 		return errors.New("upload tcp listen option is empty")
 	}
-	uploadListener, uploadPort, err := cd.prepareTCPListener(cd.Option.Upload.ListenOption, true)
+	uploadListenOption := cd.Option.Upload.ListenOption
+	if cd.Option.Upload.HTTP2.Enable {
+		uploadListenOption.Security.TLSConfig = withHTTP2NextProtos(uploadListenOption.Security.TLSConfig)
+	}
+	uploadListener, uploadPort, err := cd.prepareTCPListener(uploadListenOption, true)
 	if err != nil {
 		logger.Errorf("failed to listen for upload service: %v", err)
 		return err
@@ -438,6 +612,25 @@ func (cd *clientDaemon) Serve() error {
 		}
 	}
 
+	// prepare local transport listen, peers on the same host connect
+	// the unix sockets named by the ip and ports in peer host
+	var localPeerListener, localUploadListener net.Listener
+	if localTransport := cd.Option.Download.LocalTransport; localTransport.Enable {
+		localPeerListener, err = cd.prepareLocalListener(cd.Option.Download.PeerGRPC,
+			localTransport.PeerSocket(cd.schedPeerHost.Ip, peerPort), false)
+		if err != nil {
+			logger.Errorf("failed to listen for local peer grpc service: %v", err)
+			return err
+		}
+
+		localUploadListener, err = cd.prepareLocalListener(cd.Option.Upload.ListenOption,
+			localTransport.UploadSocket(cd.schedPeerHost.Ip, uploadPort), true)
+		if err != nil {
+			logger.Errorf("failed to listen for local upload service: %v", err)
+			return err
+		}
+	}
+
 	g := errgroup.Group{}
 	// serve download grpc service
 	g.Go(func() error {
@@ -509,6 +702,29 @@ func (cd *clientDaemon) Serve() error {
 		})
 	}
 
+	// serve local transport
+	if localPeerListener != nil {
+		g.Go(func() error {
+			defer localPeerListener.Close()
+			logger.Infof("serve local peer grpc at unix://%s", localPeerListener.Addr().String())
+			if err := cd.RPCManager.ServePeer(localPeerListener); err != nil {
+				logger.Errorf("failed to serve for local peer grpc service: %v", err)
+				return err
+			}
+			return nil
+		})
+
+		g.Go(func() error {
+			defer localUploadListener.Close()
+			logger.Infof("serve local upload service at unix://%s", localUploadListener.Addr().String())
+			if err := cd.UploadManager.Serve(localUploadListener); err != nil && err != http.ErrServerClosed {
+				logger.Errorf("failed to serve for local upload service: %v", err)
+				return err
+			}
+			return nil
+		})
+	}
+
 	// serve upload service
 	g.Go(func() error {
 		defer uploadListener.Close()
@@ -537,6 +753,17 @@ func (cd *clientDaemon) Serve() error {
 		})
 	}
 
+	// serve peer exchange service
+	if cd.PeerExchange != nil {
+		g.Go(func() error {
+			if err := cd.PeerExchange.Serve(); err != nil {
+				logger.Errorf("failed to serve for peer exchange service: %v", err)
+				return err
+			}
+			return nil
+		})
+	}
+
 	// enable seed peer mode
 	if cd.managerClient != nil && cd.Option.Scheduler.Manager.SeedPeer.Enable {
 		logger.Info("announce to manager")
@@ -551,7 +778,15 @@ func (cd *clientDaemon) Serve() error {
 				HostName:   cd.Option.Host.Hostname,
 				Ip:         cd.Option.Host.AdvertiseIP,
 				ClusterId:  uint64(cd.Option.Scheduler.Manager.SeedPeer.ClusterID),
-			})
+			}, managerclient.WithRegister(func(ctx context.Context) error {
+				// Announce to manager again and resync dynconfig,
+				// when manager is restarted.
+				if err := cd.announceSeedPeerWithContext(ctx); err != nil {
+					return err
+				}
+
+				return cd.dynconfig.Refresh()
+			}))
 			return err
 		})
 	}
@@ -637,7 +872,40 @@ func (cd *clientDaemon) Serve() error {
 		}()
 	}
 
-	if len(watchers) > 0 && interval > 0 {
+	if cd.Option.Debug != nil {
+		if cd.Option.Debug.ListenOption.TCPListen == nil {
+			logger.Fatalf("debug listen not found")
+		}
+
+		r := gin.Default()
+		r.GET("/debug/tasks/:task_id", func(c *gin.Context) {
+			timing, ok := cd.PeerTaskManager.GetTaskTiming(c.Param("task_id"))
+			if !ok {
+				c.JSON(http.StatusNotFound, gin.H{"errors": "task not found"})
+				return
+			}
+
+			c.JSON(http.StatusOK, timing)
+		})
+
+		listener, _, err := cd.prepareTCPListener(cd.Option.Debug.ListenOption, false)
+		if err != nil {
+			logger.Fatalf("init debug http server error: %v", err)
+		}
+
+		go func() {
+			logger.Infof("serve http debug at %#v", cd.Option.Debug.ListenOption.TCPListen)
+			if err = http.Serve(listener, r); err != nil {
+				if err == http.ErrServerClosed {
+					return
+				}
+				logger.Errorf("debug http server error: %v", err)
+			}
+		}()
+	}
+
+	// Config file is reloaded when it is changed or SIGHUP is received.
+	if len(watchers) > 0 {
 		go func() {
 			dependency.WatchConfig(interval, func() any {
 				return config.NewDaemonConfig()
@@ -650,6 +918,18 @@ func (cd *clientDaemon) Serve() error {
 		}()
 	}
 
+	// serve completed tasks as a read-only filesystem
+	if cd.Mount != nil {
+		g.Go(func() error {
+			if err := cd.Mount.Serve(); err != nil {
+				logger.Errorf("failed to serve for mount: %v", err)
+				return err
+			}
+			logger.Infof("mount closed")
+			return nil
+		})
+	}
+
 	werr := g.Wait()
 	cd.Stop()
 	return werr
@@ -670,6 +950,18 @@ func (cd *clientDaemon) Stop() {
 			}
 		}
 
+		if cd.PeerExchange != nil {
+			if err := cd.PeerExchange.Stop(); err != nil {
+				logger.Errorf("peer exchange stop failed %s", err)
+			}
+		}
+
+		if cd.Mount != nil {
+			if err := cd.Mount.Stop(); err != nil {
+				logger.Errorf("mount stop failed %s", err)
+			}
+		}
+
 		if cd.ProxyManager.IsEnabled() {
 			if err := cd.ProxyManager.Stop(); err != nil {
 				logger.Errorf("proxy manager stop failed %s", err)
@@ -699,12 +991,17 @@ func (cd *clientDaemon) Stop() {
 
 // announceSeedPeer announces seed peer to manager.
 func (cd *clientDaemon) announceSeedPeer() error {
+	return cd.announceSeedPeerWithContext(context.Background())
+}
+
+// announceSeedPeerWithContext announces seed peer to manager with context.
+func (cd *clientDaemon) announceSeedPeerWithContext(ctx context.Context) error {
 	var objectStoragePort int32
 	if cd.Option.ObjectStorage.Enable {
 		objectStoragePort = int32(cd.Option.ObjectStorage.TCPListen.PortRange.Start)
 	}
 
-	if _, err := cd.managerClient.UpdateSeedPeer(context.Background(), &managerv1.UpdateSeedPeerRequest{
+	if _, err := cd.managerClient.UpdateSeedPeer(ctx, &managerv1.UpdateSeedPeerRequest{
 		SourceType:        managerv1.SourceType_SEED_PEER_SOURCE,
 		HostName:          cd.Option.Host.Hostname,
 		Type:              cd.Option.Scheduler.Manager.SeedPeer.Type,
@@ -730,3 +1027,42 @@ func (cd *clientDaemon) ExportTaskManager() peer.TaskManager {
 func (cd *clientDaemon) ExportPeerHost() *schedulerv1.PeerHost {
 	return cd.schedPeerHost
 }
+
+// configureSourceClients applies the options of source clients by scheme.
+func configureSourceClients(options map[string]map[string]string) error {
+	for scheme, opts := range options {
+		if err := source.Configure(scheme, opts); err != nil {
+			return fmt.Errorf("configure %s source client: %w", scheme, err)
+		}
+
+		logger.Infof("configure %s source client", scheme)
+	}
+
+	return nil
+}
+
+// registerSourceSigners registers signers of back source requests,
+// credentials of s3 signer are fetched from object storage config of manager.
+func registerSourceSigners(options []*config.SourceSignerOption, dynconfig config.Dynconfig) {
+	for _, option := range options {
+		switch option.Type {
+		case config.SourceSignerS3:
+			source.RegisterSigner(option.Regx.Regexp, signers.NewS3Signer(option.Region, func() (*signers.Credential, error) {
+				objectStorage, err := dynconfig.GetObjectStorage()
+				if err != nil {
+					return nil, err
+				}
+
+				return &signers.Credential{
+					AccessKey: objectStorage.AccessKey,
+					SecretKey: objectStorage.SecretKey,
+					Region:    objectStorage.Region,
+				}, nil
+			}))
+		case config.SourceSignerHeader:
+			source.RegisterSigner(option.Regx.Regexp, signers.NewHeaderSigner(option.Header, option.Value))
+		}
+
+		logger.Infof("register %s source signer for %s", option.Type, option.Regx.String())
+	}
+}
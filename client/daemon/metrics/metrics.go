@@ -41,6 +41,18 @@ const (
 
 	// SeedPeerDownload type is back-to-source
 	SeedPeerDownloadTypeBackToSource = "back_to_source"
+
+	// PieceDownload type is p2p, pieces are downloaded from parents
+	PieceDownloadTypeP2P = "p2p"
+
+	// PieceDownload type is back-to-source, pieces are downloaded from source
+	PieceDownloadTypeBackToSource = "back_to_source"
+
+	// SchedulerRequest method is register peer task
+	SchedulerRequestMethodRegisterPeerTask = "register_peer_task"
+
+	// SchedulerRequest method is report peer result
+	SchedulerRequestMethodReportPeerResult = "report_peer_result"
 )
 
 var (
@@ -107,6 +119,57 @@ var (
 		Help:      "Counter of the total failed piece tasks.",
 	})
 
+	PieceDownloadCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "piece_download_total",
+		Help:      "Counter of the total downloaded pieces from parents or source.",
+	}, []string{"type", "success"})
+
+	PieceDownloadTraffic = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "piece_download_traffic",
+		Help:      "Counter of the total bytes of downloaded pieces from parents or source.",
+	}, []string{"type"})
+
+	PieceDownloadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "piece_download_duration_milliseconds",
+		Help:      "Histogram of the time each piece downloading from parents or source.",
+		Buckets:   []float64{1, 5, 10, 20, 50, 100, 200, 500, 1000, 2 * 1000, 5 * 1000, 10 * 1000, 30 * 1000},
+	}, []string{"type", "success"})
+
+	SchedulerRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "scheduler_request_duration_milliseconds",
+		Help:      "Histogram of the round trip time each request to scheduler.",
+		Buckets:   []float64{1, 5, 10, 20, 50, 100, 200, 500, 1000, 2 * 1000, 5 * 1000},
+	}, []string{"method", "success"})
+
+	PieceTaskThrottledCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "piece_task_throttled_total",
+		Help:      "Counter of the total piece tasks rejected by parents with too many requests.",
+	})
+
+	UploadQueueWaitingCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "upload_queue_waiting",
+		Help:      "Gauge of the upload requests waiting in queues of remote peers.",
+	})
+
+	UploadRejectedCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "upload_rejected_total",
+		Help:      "Counter of the total upload requests rejected when queues of remote peers are full or timeout.",
+	})
+
 	FileTaskCount = promauto.NewCounter(prometheus.CounterOpts{
 		Namespace: constants.MetricsNamespace,
 		Subsystem: constants.DfdaemonMetricsName,
@@ -149,6 +212,20 @@ var (
 		Help:      "Gauger of the number of concurrent of the seed peer downloading.",
 	})
 
+	SeedPeerQueuedDownloadGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "seed_peer_queued_download_total",
+		Help:      "Gauger of the number of queued seed peer downloading waiting for inbound bandwidth.",
+	})
+
+	SeedPeerBusyRejectedCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "seed_peer_busy_rejected_total",
+		Help:      "Counter of the number of seed peer downloading rejected when seed peer is above the watermark.",
+	})
+
 	PeerTaskCacheHitCount = promauto.NewCounter(prometheus.CounterOpts{
 		Namespace: constants.MetricsNamespace,
 		Subsystem: constants.DfdaemonMetricsName,
@@ -162,6 +239,76 @@ var (
 		Name:      "prefetch_task_total",
 		Help:      "Counter of the total prefetched tasks.",
 	})
+
+	PeerTaskResumedPieceCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "peer_task_resumed_piece_total",
+		Help:      "Counter of the total pieces restored from storage when resuming peer tasks.",
+	})
+
+	PeerTaskCorruptedPieceCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "peer_task_corrupted_piece_total",
+		Help:      "Counter of the total corrupted pieces found when validating digest of peer tasks.",
+	})
+
+	PeerTaskRepairCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "peer_task_repair_total",
+		Help:      "Counter of the total peer tasks repaired by re-downloading corrupted pieces.",
+	}, []string{"success"})
+
+	PeerTaskMigrateCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "peer_task_migrate_total",
+		Help:      "Counter of the total peer tasks migrated to other schedulers when the scheduler connection is broken.",
+	}, []string{"success"})
+
+	StorageReclaimedTaskCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "storage_reclaimed_task_total",
+		Help:      "Counter of the total tasks reclaimed by storage gc.",
+	})
+
+	StorageReclaimedBytesCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "storage_reclaimed_bytes_total",
+		Help:      "Counter of the total bytes reclaimed by storage gc.",
+	})
+
+	StoragePartialReclaimedTaskCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "storage_partial_reclaimed_task_total",
+		Help:      "Counter of the total expired unfinished tasks whose downloaded pieces are reclaimed by punching holes.",
+	})
+
+	StoragePartialReclaimedBytesCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "storage_partial_reclaimed_bytes_total",
+		Help:      "Counter of the total bytes reclaimed by punching holes in data files of unfinished tasks.",
+	})
+
+	StorageQuotaEvictedTaskCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "storage_quota_evicted_task_total",
+		Help:      "Counter of the total least recently used tasks evicted when disk quota or usage threshold is exceeded.",
+	})
+
+	StorageUsedBytes = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.DfdaemonMetricsName,
+		Name:      "storage_used_bytes",
+		Help:      "Gauge of the bytes used by tasks which are not reclaimed in storage.",
+	})
 )
 
 func New(addr string) *http.Server {
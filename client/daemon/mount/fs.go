@@ -0,0 +1,216 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mount
+
+import (
+	"context"
+	"io"
+	"strings"
+	"syscall"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	"d7y.io/dragonfly/v2/client/util"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+const (
+	// tasksDir exposes completed tasks by task id.
+	tasksDir = "tasks"
+
+	// urlsDir exposes completed tasks by original url, like urls/https/example.com/foo.
+	urlsDir = "urls"
+
+	// fileMode is the mode of task files, they are read-only.
+	fileMode = 0444
+
+	// dirMode is the mode of directories.
+	dirMode = 0555
+)
+
+// rootNode is the root directory which contains tasksDir and urlsDir.
+type rootNode struct {
+	fs.Inode
+	storageManager storage.Manager
+}
+
+var _ = (fs.NodeOnAdder)((*rootNode)(nil))
+
+func newRootNode(storageManager storage.Manager) *rootNode {
+	return &rootNode{storageManager: storageManager}
+}
+
+// OnAdd adds the static directories when the filesystem is mounted.
+func (n *rootNode) OnAdd(ctx context.Context) {
+	n.AddChild(tasksDir, n.NewPersistentInode(ctx, &tasksNode{storageManager: n.storageManager}, fs.StableAttr{Mode: fuse.S_IFDIR}), false)
+	n.AddChild(urlsDir, n.NewPersistentInode(ctx, &urlNode{storageManager: n.storageManager}, fs.StableAttr{Mode: fuse.S_IFDIR}), false)
+}
+
+// tasksNode is the directory of completed tasks addressed by task id.
+type tasksNode struct {
+	fs.Inode
+	storageManager storage.Manager
+}
+
+var (
+	_ = (fs.NodeLookuper)((*tasksNode)(nil))
+	_ = (fs.NodeReaddirer)((*tasksNode)(nil))
+)
+
+// Lookup finds the completed task by task id.
+func (n *tasksNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	task := n.storageManager.FindCompletedTask(name)
+	if task == nil {
+		return nil, syscall.ENOENT
+	}
+
+	return newTaskInode(ctx, &n.Inode, n.storageManager, task, out), fs.OK
+}
+
+// Readdir lists all completed tasks.
+func (n *tasksNode) Readdir(ctx context.Context) (fs.DirStream, syscall.Errno) {
+	var entries []fuse.DirEntry
+	for _, taskID := range n.storageManager.CompletedTaskIDs() {
+		entries = append(entries, fuse.DirEntry{Name: taskID, Mode: fuse.S_IFREG})
+	}
+
+	return fs.NewListDirStream(entries), fs.OK
+}
+
+// urlNode is the directory of a url prefix, the first level is scheme and the second level is host.
+// The prefix is a directory only when it is a prefix of the url of a completed task,
+// tasks stored without url are not exposed here.
+type urlNode struct {
+	fs.Inode
+	storageManager storage.Manager
+	elems          []string
+}
+
+var _ = (fs.NodeLookuper)((*urlNode)(nil))
+
+// Lookup returns the task file when the url of the path is a completed task, a directory when it is
+// a prefix of the url of completed tasks, otherwise returns ENOENT.
+func (n *urlNode) Lookup(ctx context.Context, name string, out *fuse.EntryOut) (*fs.Inode, syscall.Errno) {
+	elems := append(append([]string{}, n.elems...), name)
+	if len(elems) >= 2 {
+		url := elems[0] + "://" + strings.Join(elems[1:], "/")
+		if task := n.storageManager.FindCompletedTask(idgen.TaskID(url, &commonv1.UrlMeta{})); task != nil {
+			return newTaskInode(ctx, &n.Inode, n.storageManager, task, out), fs.OK
+		}
+	}
+
+	if !isURLPrefix(n.storageManager.CompletedTaskURLs(), elems) {
+		return nil, syscall.ENOENT
+	}
+
+	out.Mode = fuse.S_IFDIR | dirMode
+	return n.NewInode(ctx, &urlNode{storageManager: n.storageManager, elems: elems}, fs.StableAttr{Mode: fuse.S_IFDIR}), fs.OK
+}
+
+// isURLPrefix returns whether the path elements are a directory prefix of any url,
+// elements are the scheme, the host and the segments of path.
+func isURLPrefix(urls []string, elems []string) bool {
+	prefix := elems[0] + "://"
+	if len(elems) > 1 {
+		prefix += strings.Join(elems[1:], "/") + "/"
+	}
+
+	for _, url := range urls {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// taskNode is a read-only file of completed task, it is read from storage directly.
+type taskNode struct {
+	fs.Inode
+	storageManager storage.Manager
+	task           *storage.ReusePeerTask
+}
+
+var (
+	_ = (fs.NodeGetattrer)((*taskNode)(nil))
+	_ = (fs.NodeOpener)((*taskNode)(nil))
+	_ = (fs.NodeReader)((*taskNode)(nil))
+)
+
+func newTaskInode(ctx context.Context, parent *fs.Inode, storageManager storage.Manager, task *storage.ReusePeerTask, out *fuse.EntryOut) *fs.Inode {
+	node := &taskNode{storageManager: storageManager, task: task}
+	node.fillAttr(&out.Attr)
+	return parent.NewInode(ctx, node, fs.StableAttr{Mode: fuse.S_IFREG})
+}
+
+func (n *taskNode) fillAttr(attr *fuse.Attr) {
+	attr.Mode = fuse.S_IFREG | fileMode
+	attr.Size = uint64(n.task.ContentLength)
+}
+
+// Getattr returns the attributes of task file.
+func (n *taskNode) Getattr(ctx context.Context, f fs.FileHandle, out *fuse.AttrOut) syscall.Errno {
+	n.fillAttr(&out.Attr)
+	return fs.OK
+}
+
+// Open rejects writing, the content of task is immutable so it is cached by kernel.
+func (n *taskNode) Open(ctx context.Context, flags uint32) (fs.FileHandle, uint32, syscall.Errno) {
+	if flags&(syscall.O_WRONLY|syscall.O_RDWR|syscall.O_APPEND|syscall.O_TRUNC) != 0 {
+		return nil, 0, syscall.EROFS
+	}
+
+	return nil, fuse.FOPEN_KEEP_CACHE, fs.OK
+}
+
+// Read reads the content of task from storage.
+func (n *taskNode) Read(ctx context.Context, f fs.FileHandle, dest []byte, off int64) (fuse.ReadResult, syscall.Errno) {
+	if off >= n.task.ContentLength {
+		return fuse.ReadResultData(nil), fs.OK
+	}
+
+	length := int64(len(dest))
+	if off+length > n.task.ContentLength {
+		length = n.task.ContentLength - off
+	}
+
+	reader, closer, err := n.storageManager.ReadPiece(ctx, &storage.ReadPieceRequest{
+		PeerTaskMetadata: n.task.PeerTaskMetadata,
+		PieceMetadata: storage.PieceMetadata{
+			Num:   -1,
+			Range: util.Range{Start: off, Length: length},
+		},
+	})
+	if err != nil {
+		logger.Errorf("read task %s error: %s", n.task.TaskID, err)
+		return nil, syscall.EIO
+	}
+	defer closer.Close()
+
+	count, err := io.ReadFull(reader, dest[:length])
+	if err != nil {
+		logger.Errorf("read task %s error: %s", n.task.TaskID, err)
+		return nil, syscall.EIO
+	}
+
+	return fuse.ReadResultData(dest[:count]), fs.OK
+}
@@ -0,0 +1,232 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mount
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"syscall"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
+)
+
+func TestTaskNode_Read(t *testing.T) {
+	content := []byte("hello dragonfly")
+	task := &storage.ReusePeerTask{
+		PeerTaskMetadata: storage.PeerTaskMetadata{
+			PeerID: "peer",
+			TaskID: "task",
+		},
+		ContentLength: int64(len(content)),
+	}
+
+	tests := []struct {
+		name   string
+		size   int
+		off    int64
+		mock   func(ms *mocks.MockManagerMockRecorder)
+		expect func(t *testing.T, data []byte, errno syscall.Errno)
+	}{
+		{
+			name: "read from the beginning",
+			size: 5,
+			off:  0,
+			mock: func(ms *mocks.MockManagerMockRecorder) {
+				ms.ReadPiece(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+						data := content[req.Range.Start : req.Range.Start+req.Range.Length]
+						return bytes.NewReader(data), io.NopCloser(nil), nil
+					}).Times(1)
+			},
+			expect: func(t *testing.T, data []byte, errno syscall.Errno) {
+				assert := testifyassert.New(t)
+				assert.Equal(fs.OK, errno)
+				assert.Equal("hello", string(data))
+			},
+		},
+		{
+			name: "read beyond the end",
+			size: 16,
+			off:  6,
+			mock: func(ms *mocks.MockManagerMockRecorder) {
+				ms.ReadPiece(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+						data := content[req.Range.Start : req.Range.Start+req.Range.Length]
+						return bytes.NewReader(data), io.NopCloser(nil), nil
+					}).Times(1)
+			},
+			expect: func(t *testing.T, data []byte, errno syscall.Errno) {
+				assert := testifyassert.New(t)
+				assert.Equal(fs.OK, errno)
+				assert.Equal("dragonfly", string(data))
+			},
+		},
+		{
+			name: "read at the end",
+			size: 16,
+			off:  int64(len(content)),
+			mock: func(ms *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, data []byte, errno syscall.Errno) {
+				assert := testifyassert.New(t)
+				assert.Equal(fs.OK, errno)
+				assert.Empty(data)
+			},
+		},
+		{
+			name: "task is reclaimed",
+			size: 5,
+			off:  0,
+			mock: func(ms *mocks.MockManagerMockRecorder) {
+				ms.ReadPiece(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, data []byte, errno syscall.Errno) {
+				assert := testifyassert.New(t)
+				assert.Equal(syscall.EIO, errno)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			storageManager := mocks.NewMockManager(ctl)
+			tc.mock(storageManager.EXPECT())
+
+			node := &taskNode{storageManager: storageManager, task: task}
+			result, errno := node.Read(context.Background(), nil, make([]byte, tc.size), tc.off)
+			var data []byte
+			if result != nil {
+				data, _ = result.Bytes(nil)
+			}
+			tc.expect(t, data, errno)
+		})
+	}
+}
+
+func TestTaskNode_Open(t *testing.T) {
+	assert := testifyassert.New(t)
+	node := &taskNode{task: &storage.ReusePeerTask{ContentLength: 10}}
+
+	_, _, errno := node.Open(context.Background(), syscall.O_RDONLY)
+	assert.Equal(fs.OK, errno)
+
+	for _, flags := range []uint32{syscall.O_WRONLY, syscall.O_RDWR, syscall.O_RDONLY | syscall.O_TRUNC} {
+		_, _, errno = node.Open(context.Background(), flags)
+		assert.Equal(syscall.EROFS, errno)
+	}
+
+	var out fuse.AttrOut
+	assert.Equal(fs.OK, node.Getattr(context.Background(), nil, &out))
+	assert.Equal(uint64(10), out.Size)
+	assert.Equal(uint32(fuse.S_IFREG|fileMode), out.Mode)
+}
+
+func TestTasksNode_Readdir(t *testing.T) {
+	assert := testifyassert.New(t)
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	storageManager := mocks.NewMockManager(ctl)
+	storageManager.EXPECT().CompletedTaskIDs().Return([]string{"foo", "bar"}).Times(1)
+
+	node := &tasksNode{storageManager: storageManager}
+	stream, errno := node.Readdir(context.Background())
+	assert.Equal(fs.OK, errno)
+
+	var names []string
+	for stream.HasNext() {
+		entry, errno := stream.Next()
+		assert.Equal(fs.OK, errno)
+		assert.Equal(uint32(fuse.S_IFREG), entry.Mode)
+		names = append(names, entry.Name)
+	}
+	assert.Equal([]string{"foo", "bar"}, names)
+}
+
+func TestURLNode_Lookup(t *testing.T) {
+	tests := []struct {
+		name  string
+		elems []string
+		look  string
+		mock  func(ms *mocks.MockManagerMockRecorder)
+	}{
+		{
+			name: "unknown scheme",
+			look: "ftp",
+			mock: func(ms *mocks.MockManagerMockRecorder) {
+				ms.CompletedTaskURLs().Return([]string{"https://example.com/foo"}).Times(1)
+			},
+		},
+		{
+			name:  "unknown host",
+			elems: []string{"https"},
+			look:  "example.org",
+			mock: func(ms *mocks.MockManagerMockRecorder) {
+				ms.FindCompletedTask(gomock.Any()).Return(nil).Times(1)
+				ms.CompletedTaskURLs().Return([]string{"https://example.com/foo"}).Times(1)
+			},
+		},
+		{
+			name:  "no completed task at full depth",
+			elems: []string{"https", "example.com"},
+			look:  "bar",
+			mock: func(ms *mocks.MockManagerMockRecorder) {
+				ms.FindCompletedTask(gomock.Any()).Return(nil).Times(1)
+				ms.CompletedTaskURLs().Return([]string{"https://example.com/foo", "https://example.com/barbaz"}).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			storageManager := mocks.NewMockManager(ctl)
+			tc.mock(storageManager.EXPECT())
+
+			node := &urlNode{storageManager: storageManager, elems: tc.elems}
+			inode, errno := node.Lookup(context.Background(), tc.look, &fuse.EntryOut{})
+			assert := testifyassert.New(t)
+			assert.Nil(inode)
+			assert.Equal(syscall.ENOENT, errno)
+		})
+	}
+}
+
+func TestIsURLPrefix(t *testing.T) {
+	assert := testifyassert.New(t)
+	urls := []string{"https://example.com/foo/bar", "http://localhost:8080/baz?a=b"}
+
+	assert.True(isURLPrefix(urls, []string{"https"}))
+	assert.True(isURLPrefix(urls, []string{"https", "example.com"}))
+	assert.True(isURLPrefix(urls, []string{"https", "example.com", "foo"}))
+	assert.True(isURLPrefix(urls, []string{"http", "localhost:8080"}))
+	assert.False(isURLPrefix(urls, []string{"ftp"}))
+	assert.False(isURLPrefix(urls, []string{"https", "example.org"}))
+	assert.False(isURLPrefix(urls, []string{"https", "example.com", "fo"}))
+	assert.False(isURLPrefix(urls, []string{"https", "example.com", "foo", "bar"}))
+	assert.False(isURLPrefix(nil, []string{"https"}))
+}
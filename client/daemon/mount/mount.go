@@ -0,0 +1,107 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package mount
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fs"
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// fsName is the name of filesystem shown in mount table.
+	fsName = "dragonfly"
+
+	// entryTimeout is the kernel cache timeout of entries and attributes,
+	// tasks are reclaimed by gc, so it is short.
+	entryTimeout = 10 * time.Second
+)
+
+// Mount serves the completed tasks as a read-only fuse filesystem.
+type Mount interface {
+	// Serve mounts the filesystem and serves until it is unmounted.
+	Serve() error
+
+	// Stop unmounts the filesystem.
+	Stop() error
+}
+
+type mount struct {
+	option         config.MountOption
+	storageManager storage.Manager
+
+	mu      sync.Mutex
+	server  *fuse.Server
+	stopped bool
+}
+
+// New returns a new Mount instance.
+func New(option config.MountOption, storageManager storage.Manager) Mount {
+	return &mount{
+		option:         option,
+		storageManager: storageManager,
+	}
+}
+
+// Serve mounts the filesystem and serves until it is unmounted.
+func (m *mount) Serve() error {
+	timeout := entryTimeout
+	server, err := fs.Mount(m.option.Dir, newRootNode(m.storageManager), &fs.Options{
+		MountOptions: fuse.MountOptions{
+			FsName:     fsName,
+			Name:       fsName,
+			AllowOther: m.option.AllowOther,
+			Options:    []string{"ro"},
+		},
+		EntryTimeout: &timeout,
+		AttrTimeout:  &timeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return server.Unmount()
+	}
+	m.server = server
+	m.mu.Unlock()
+
+	logger.Infof("serve completed tasks at %s", m.option.Dir)
+	server.Wait()
+	return nil
+}
+
+// Stop unmounts the filesystem.
+func (m *mount) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.stopped = true
+	if m.server == nil {
+		return nil
+	}
+
+	return m.server.Unmount()
+}
@@ -73,6 +73,10 @@ const (
 
 const (
 	RouterGroupBuckets = "/buckets"
+
+	// RouterS3Object is the path style route of S3 compatible object api,
+	// only object key is allowed in the bucket path.
+	RouterS3Object = "/:bucket/*object_key"
 )
 
 var GinLogFileName = "gin-object-stroage.log"
@@ -159,6 +163,10 @@ func (o *objectStorage) initRouter(cfg *config.DaemonOption, logDir string) *gin
 			return RouterGroupBuckets
 		}
 
+		if c.FullPath() == RouterS3Object {
+			return RouterS3Object
+		}
+
 		return c.Request.URL.Path
 	}
 	p.Use(r)
@@ -178,9 +186,22 @@ func (o *objectStorage) initRouter(cfg *config.DaemonOption, logDir string) *gin
 	b.DELETE(":id/objects/*object_key", o.destroyObject)
 	b.PUT(":id/objects/*object_key", o.putObject)
 
+	// S3 compatible object gateway, applications can use the daemon as endpoint
+	// of S3 SDK with path style addressing.
+	r.HEAD(RouterS3Object, o.headS3Object)
+	r.GET(RouterS3Object, o.getS3Object)
+
 	return r
 }
 
+// abortFunc writes the error response of the request.
+type abortFunc func(ctx *gin.Context, code int, err error)
+
+// abortWithJSON writes the error response in json.
+func abortWithJSON(ctx *gin.Context, code int, err error) {
+	ctx.JSON(code, gin.H{"errors": err.Error()})
+}
+
 // getHealth uses to check server health.
 func (o *objectStorage) getHealth(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, http.StatusText(http.StatusOK))
@@ -199,20 +220,25 @@ func (o *objectStorage) headObject(ctx *gin.Context) {
 		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
 	)
 
+	o.writeObjectMetadata(ctx, bucketName, objectKey, abortWithJSON)
+}
+
+// writeObjectMetadata writes metadata of object to the response headers.
+func (o *objectStorage) writeObjectMetadata(ctx *gin.Context, bucketName, objectKey string, abort abortFunc) {
 	client, err := o.client()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		abort(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	meta, isExist, err := client.GetObjectMetadata(ctx, bucketName, objectKey)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		abort(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	if !isExist {
-		ctx.JSON(http.StatusNotFound, gin.H{"errors": http.StatusText(http.StatusNotFound)})
+		abort(ctx, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 		return
 	}
 
@@ -225,7 +251,6 @@ func (o *objectStorage) headObject(ctx *gin.Context) {
 	ctx.Header(config.HeaderDragonflyObjectMetaDigest, meta.Digest)
 
 	ctx.Status(http.StatusOK)
-	return
 }
 
 // getObject uses to download object data.
@@ -243,9 +268,16 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 	}
 
 	var (
-		bucketName    = params.ID
-		objectKey     = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
-		filter        = query.Filter
+		bucketName = params.ID
+		objectKey  = strings.TrimPrefix(params.ObjectKey, string(os.PathSeparator))
+	)
+
+	o.streamObject(ctx, bucketName, objectKey, query.Filter, abortWithJSON)
+}
+
+// streamObject downloads object data by peer task and streams it to the response.
+func (o *objectStorage) streamObject(ctx *gin.Context, bucketName, objectKey, filter string, abort abortFunc) {
+	var (
 		artifactRange *util.Range
 		ranges        []util.Range
 		err           error
@@ -259,18 +291,18 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 
 	client, err := o.client()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		abort(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	meta, isExist, err := client.GetObjectMetadata(ctx, bucketName, objectKey)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		abort(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
 	if !isExist {
-		ctx.JSON(http.StatusNotFound, gin.H{"errors": http.StatusText(http.StatusNotFound)})
+		abort(ctx, http.StatusNotFound, errors.New(http.StatusText(http.StatusNotFound)))
 		return
 	}
 
@@ -281,7 +313,7 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 	if len(rangeHeader) > 0 {
 		ranges, err = o.parseRangeHeader(rangeHeader)
 		if err != nil {
-			ctx.JSON(http.StatusRequestedRangeNotSatisfiable, gin.H{"errors": err.Error()})
+			abort(ctx, http.StatusRequestedRangeNotSatisfiable, err)
 			return
 		}
 		artifactRange = &ranges[0]
@@ -296,7 +328,7 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 
 	signURL, err := client.GetSignURL(ctx, bucketName, objectKey, objectstorage.MethodGet, defaultSignExpireTime)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		abort(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -311,7 +343,7 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 		PeerID:  o.peerIDGenerator.PeerID(),
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		abort(ctx, http.StatusInternalServerError, err)
 		return
 	}
 	defer reader.Close()
@@ -324,7 +356,9 @@ func (o *objectStorage) getObject(ctx *gin.Context) {
 	}
 
 	log.Infof("object content length is %d and content type is %s", contentLength, attr[headers.ContentType])
-	ctx.DataFromReader(http.StatusOK, contentLength, attr[headers.ContentType], reader, nil)
+	ctx.DataFromReader(http.StatusOK, contentLength, attr[headers.ContentType], reader, map[string]string{
+		headers.ETag: meta.ETag,
+	})
 }
 
 // destroyObject uses to delete object data.
@@ -395,6 +429,12 @@ func (o *objectStorage) putObject(ctx *gin.Context) {
 	urlMeta := &commonv1.UrlMeta{Filter: o.config.ObjectStorage.Filter}
 	dgst := o.md5FromFileHeader(fileHeader)
 	urlMeta.Digest = dgst.String()
+
+	// Verify the uploaded data with the digest from client.
+	if form.Digest != "" && form.Digest != urlMeta.Digest {
+		ctx.JSON(http.StatusBadRequest, gin.H{"errors": fmt.Sprintf("digest mismatch, expected %s, actual %s", form.Digest, urlMeta.Digest)})
+		return
+	}
 	if filter != "" {
 		urlMeta.Filter = filter
 	}
@@ -489,7 +529,8 @@ func (o *objectStorage) md5FromFileHeader(fileHeader *multipart.FileHeader) *dig
 	return digest.New(digest.AlgorithmMD5, digest.MD5FromReader(f))
 }
 
-// importObjectToBackend uses to import object to backend.
+// importObjectToBackend uses to import object to backend,
+// large objects are imported by multipart upload when backend supports it.
 func (o *objectStorage) importObjectToBackend(ctx context.Context, bucketName, objectKey string, dgst *digest.Digest, fileHeader *multipart.FileHeader, client objectstorage.ObjectStorage) error {
 	f, err := fileHeader.Open()
 	if err != nil {
@@ -497,6 +538,11 @@ func (o *objectStorage) importObjectToBackend(ctx context.Context, bucketName, o
 	}
 	defer f.Close()
 
+	option := o.config.ObjectStorage.MultipartUpload
+	if uploader, ok := client.(objectstorage.MultipartUploader); ok && option.Threshold > 0 && fileHeader.Size >= int64(option.Threshold) {
+		return objectstorage.MultipartUpload(ctx, uploader, bucketName, objectKey, dgst.String(), f, fileHeader.Size, int64(option.PartSize), option.Concurrency)
+	}
+
 	if err := client.PutObject(ctx, bucketName, objectKey, dgst.String(), f); err != nil {
 		return err
 	}
@@ -543,7 +589,7 @@ func (o *objectStorage) importObjectToSeedPeers(ctx context.Context, bucketName,
 	for _, scheduler := range schedulers {
 		for _, seedPeer := range scheduler.SeedPeers {
 			if o.config.Host.AdvertiseIP != seedPeer.Ip && seedPeer.ObjectStoragePort > 0 {
-				seedPeerHosts = append(seedPeerHosts, fmt.Sprintf("%s:%d", seedPeer.Ip, seedPeer.ObjectStoragePort))
+				seedPeerHosts = append(seedPeerHosts, net.JoinHostPort(seedPeer.Ip, strconv.Itoa(int(seedPeer.ObjectStoragePort))))
 			}
 		}
 	}
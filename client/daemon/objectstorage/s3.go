@@ -0,0 +1,104 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// S3 error codes, refer to https://docs.aws.amazon.com/AmazonS3/latest/API/ErrorResponses.html.
+	S3ErrorCodeNoSuchKey       = "NoSuchKey"
+	S3ErrorCodeInvalidRange    = "InvalidRange"
+	S3ErrorCodeInvalidArgument = "InvalidArgument"
+	S3ErrorCodeNotImplemented  = "NotImplemented"
+	S3ErrorCodeInternalError   = "InternalError"
+)
+
+// headS3Object uses to head object with S3 compatible api.
+func (o *objectStorage) headS3Object(ctx *gin.Context) {
+	bucketName, objectKey, ok := o.bindS3ObjectParams(ctx)
+	if !ok {
+		return
+	}
+
+	o.writeObjectMetadata(ctx, bucketName, objectKey, abortWithS3Error)
+}
+
+// getS3Object uses to download object data with S3 compatible api,
+// the object is downloaded by peer task, so S3 SDK benefits from P2P.
+func (o *objectStorage) getS3Object(ctx *gin.Context) {
+	bucketName, objectKey, ok := o.bindS3ObjectParams(ctx)
+	if !ok {
+		return
+	}
+
+	o.streamObject(ctx, bucketName, objectKey, "", abortWithS3Error)
+}
+
+// bindS3ObjectParams binds bucket name and object key of S3 compatible api.
+func (o *objectStorage) bindS3ObjectParams(ctx *gin.Context) (string, string, bool) {
+	var params S3ObjectParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		abortWithS3Error(ctx, http.StatusBadRequest, err)
+		return "", "", false
+	}
+
+	objectKey := strings.TrimPrefix(params.ObjectKey, "/")
+	if objectKey == "" {
+		abortWithS3Error(ctx, http.StatusNotImplemented, errors.New("bucket operations are not supported"))
+		return "", "", false
+	}
+
+	return params.Bucket, objectKey, true
+}
+
+// abortWithS3Error writes the error response in S3 format.
+func abortWithS3Error(ctx *gin.Context, code int, err error) {
+	// Response of HEAD request has no body.
+	if ctx.Request.Method == http.MethodHead {
+		ctx.AbortWithStatus(code)
+		return
+	}
+
+	ctx.XML(code, S3Error{
+		Code:     s3ErrorCode(code),
+		Message:  err.Error(),
+		Resource: ctx.Request.URL.Path,
+	})
+	ctx.Abort()
+}
+
+// s3ErrorCode returns S3 error code by http status code.
+func s3ErrorCode(code int) string {
+	switch code {
+	case http.StatusNotFound:
+		return S3ErrorCodeNoSuchKey
+	case http.StatusRequestedRangeNotSatisfiable:
+		return S3ErrorCodeInvalidRange
+	case http.StatusBadRequest:
+		return S3ErrorCodeInvalidArgument
+	case http.StatusNotImplemented:
+		return S3ErrorCodeNotImplemented
+	default:
+		return S3ErrorCodeInternalError
+	}
+}
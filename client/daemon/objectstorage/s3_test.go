@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/cmd/dependency/base"
+)
+
+func TestObjectStorage_S3Router(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "health check is not routed to object",
+			method: http.MethodGet,
+			path:   "/healthy",
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name:   "get bucket is not supported",
+			method: http.MethodGet,
+			path:   "/foo/",
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusNotImplemented, w.Code)
+
+				var s3Err S3Error
+				assert.NoError(xml.Unmarshal(w.Body.Bytes(), &s3Err))
+				assert.Equal(S3ErrorCodeNotImplemented, s3Err.Code)
+				assert.Equal("/foo/", s3Err.Resource)
+			},
+		},
+		{
+			name:   "head bucket is not supported",
+			method: http.MethodHead,
+			path:   "/foo/",
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusNotImplemented, w.Code)
+				assert.Equal(0, w.Body.Len())
+			},
+		},
+	}
+
+	o := &objectStorage{}
+	r := o.initRouter(&config.DaemonOption{Options: base.Options{Console: true}}, "")
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			tc.expect(t, w)
+		})
+	}
+}
+
+func TestS3ErrorCode(t *testing.T) {
+	assert := testifyassert.New(t)
+	assert.Equal(S3ErrorCodeNoSuchKey, s3ErrorCode(http.StatusNotFound))
+	assert.Equal(S3ErrorCodeInvalidRange, s3ErrorCode(http.StatusRequestedRangeNotSatisfiable))
+	assert.Equal(S3ErrorCodeInvalidArgument, s3ErrorCode(http.StatusBadRequest))
+	assert.Equal(S3ErrorCodeNotImplemented, s3ErrorCode(http.StatusNotImplemented))
+	assert.Equal(S3ErrorCodeInternalError, s3ErrorCode(http.StatusInternalServerError))
+}
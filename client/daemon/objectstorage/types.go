@@ -16,7 +16,10 @@
 
 package objectstorage
 
-import "mime/multipart"
+import (
+	"encoding/xml"
+	"mime/multipart"
+)
 
 type ObjectParams struct {
 	ID        string `uri:"id" binding:"required"`
@@ -27,9 +30,22 @@ type PutObjectRequset struct {
 	Mode        uint                  `form:"mode,default=0" binding:"omitempty,gte=0,lte=2"`
 	Filter      string                `form:"filter" binding:"omitempty"`
 	MaxReplicas int                   `form:"maxReplicas" binding:"omitempty,gt=0,lte=100"`
+	Digest      string                `form:"digest" binding:"omitempty"`
 	File        *multipart.FileHeader `form:"file" binding:"required"`
 }
 
 type GetObjectQuery struct {
 	Filter string `form:"filter" binding:"omitempty"`
 }
+
+type S3ObjectParams struct {
+	Bucket    string `uri:"bucket" binding:"required"`
+	ObjectKey string `uri:"object_key" binding:"required"`
+}
+
+type S3Error struct {
+	XMLName  xml.Name `xml:"Error"`
+	Code     string   `xml:"Code"`
+	Message  string   `xml:"Message"`
+	Resource string   `xml:"Resource"`
+}
@@ -0,0 +1,78 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// hostLoadRefreshInterval is the min interval to sample host load,
+// piece results in this interval share the same host load.
+const hostLoadRefreshInterval = 5 * time.Second
+
+// hostLoadCollector samples cpu and memory usage of host, which is reported
+// to scheduler with piece results for choosing less loaded parents.
+type hostLoadCollector struct {
+	mu       sync.Mutex
+	load     *commonv1.HostLoad
+	updateAt time.Time
+}
+
+func newHostLoadCollector() *hostLoadCollector {
+	return &hostLoadCollector{}
+}
+
+// Load returns the latest host load, it samples again when the load is expired.
+func (c *hostLoadCollector) Load() *commonv1.HostLoad {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.load != nil && time.Since(c.updateAt) < hostLoadRefreshInterval {
+		return c.load
+	}
+
+	load := &commonv1.HostLoad{}
+	// Zero interval compares with the last call, so it does not block.
+	percents, err := cpu.Percent(0, false)
+	if err != nil {
+		logger.Warnf("get cpu percent error: %s", err)
+	} else if len(percents) > 0 {
+		load.CpuRatio = float32(percents[0] / 100)
+	}
+
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		logger.Warnf("get virtual memory error: %s", err)
+	} else {
+		load.MemRatio = float32(vm.UsedPercent / 100)
+	}
+
+	c.load = load
+	c.updateAt = time.Now()
+	return c.load
+}
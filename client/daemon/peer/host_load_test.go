@@ -0,0 +1,44 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestHostLoadCollector_Load(t *testing.T) {
+	assert := testifyassert.New(t)
+	c := newHostLoadCollector()
+
+	load := c.Load()
+	assert.NotNil(load)
+	assert.True(load.CpuRatio >= 0 && load.CpuRatio <= 1)
+	assert.True(load.MemRatio > 0 && load.MemRatio <= 1)
+
+	// load is cached in refresh interval
+	assert.Same(load, c.Load())
+
+	c.updateAt = time.Now().Add(-hostLoadRefreshInterval)
+	assert.NotSame(load, c.Load())
+
+	// nil collector reports no load
+	var nilCollector *hostLoadCollector
+	assert.Nil(nilCollector.Load())
+}
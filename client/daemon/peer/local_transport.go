@@ -0,0 +1,134 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
+)
+
+const (
+	// localSocketProbeTimeout is the timeout of probing the unix socket of parent.
+	localSocketProbeTimeout = 100 * time.Millisecond
+
+	// localSocketProbeInterval is the interval of probing the unix socket of parent again.
+	localSocketProbeInterval = 30 * time.Second
+)
+
+// LocalTransport connects the parents on the same host by their unix sockets,
+// the socket of parent is named by the ip and port in its peer host, and tcp is
+// used when the parent is on another host or its socket is not reachable.
+type LocalTransport struct {
+	option config.LocalTransportOption
+	// ip is the advertise ip of the host
+	ip string
+	// probes caches the probe results of unix sockets, key is socket address
+	probes sync.Map
+}
+
+type localSocketProbe struct {
+	reachable bool
+	expireAt  time.Time
+}
+
+// NewLocalTransport returns a local transport, nil is returned when local transport is disabled.
+func NewLocalTransport(option config.LocalTransportOption, ip string) *LocalTransport {
+	if !option.Enable {
+		return nil
+	}
+
+	return &LocalTransport{
+		option: option,
+		ip:     ip,
+	}
+}
+
+// PeerAddr returns the address of peer grpc of parent.
+func (t *LocalTransport) PeerAddr(ip string, port int32) dfnet.NetAddr {
+	addr := dfnet.NetAddr{
+		Type: dfnet.TCP,
+		Addr: net.JoinHostPort(ip, strconv.Itoa(int(port))),
+	}
+
+	if t == nil || ip != t.ip {
+		return addr
+	}
+
+	socket := t.option.PeerSocket(ip, int(port))
+	if !t.reachable(socket.Addr) {
+		return addr
+	}
+
+	return socket
+}
+
+// DialContext wraps the dial function of piece downloading, the upload server of
+// parent on the same host is dialed by its unix socket.
+func (t *LocalTransport) DialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, rawPort, err := net.SplitHostPort(addr)
+		if err != nil || host != t.ip {
+			return dial(ctx, network, addr)
+		}
+
+		port, err := strconv.Atoi(rawPort)
+		if err != nil {
+			return dial(ctx, network, addr)
+		}
+
+		socket := t.option.UploadSocket(host, port)
+		if !t.reachable(socket.Addr) {
+			return dial(ctx, network, addr)
+		}
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, string(dfnet.UNIX), socket.Addr)
+		if err != nil {
+			logger.Warnf("dial local socket %s error: %s, fallback to %s", socket.Addr, err, addr)
+			t.probes.Store(socket.Addr, &localSocketProbe{expireAt: time.Now().Add(localSocketProbeInterval)})
+			return dial(ctx, network, addr)
+		}
+
+		return conn, nil
+	}
+}
+
+// reachable returns whether the unix socket is reachable, the result is cached for a while.
+func (t *LocalTransport) reachable(socket string) bool {
+	if rawProbe, ok := t.probes.Load(socket); ok {
+		probe := rawProbe.(*localSocketProbe)
+		if time.Now().Before(probe.expireAt) {
+			return probe.reachable
+		}
+	}
+
+	probe := &localSocketProbe{expireAt: time.Now().Add(localSocketProbeInterval)}
+	if conn, err := net.DialTimeout(string(dfnet.UNIX), socket, localSocketProbeTimeout); err == nil {
+		conn.Close()
+		probe.reachable = true
+	}
+
+	t.probes.Store(socket, probe)
+	return probe.reachable
+}
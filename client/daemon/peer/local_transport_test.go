@@ -0,0 +1,99 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
+)
+
+func TestLocalTransport_PeerAddr(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	// disabled local transport uses tcp
+	disabled := NewLocalTransport(config.LocalTransportOption{}, "127.0.0.1")
+	assert.Nil(disabled)
+	assert.Equal(dfnet.NetAddr{Type: dfnet.TCP, Addr: "127.0.0.1:65000"}, disabled.PeerAddr("127.0.0.1", 65000))
+
+	option := config.LocalTransportOption{Enable: true, SocketDir: t.TempDir()}
+	localTransport := NewLocalTransport(option, "127.0.0.1")
+
+	// parent on another host uses tcp
+	assert.Equal(dfnet.NetAddr{Type: dfnet.TCP, Addr: "127.0.0.2:65000"}, localTransport.PeerAddr("127.0.0.2", 65000))
+
+	// socket of parent is not reachable
+	assert.Equal(dfnet.NetAddr{Type: dfnet.TCP, Addr: "127.0.0.1:65000"}, localTransport.PeerAddr("127.0.0.1", 65000))
+
+	// socket of parent is reachable
+	socket := option.PeerSocket("127.0.0.1", 65001)
+	ln, err := net.Listen(string(socket.Type), socket.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	assert.Equal(socket, localTransport.PeerAddr("127.0.0.1", 65001))
+}
+
+func TestLocalTransport_DialContext(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	option := config.LocalTransportOption{Enable: true, SocketDir: t.TempDir()}
+	localTransport := NewLocalTransport(option, "127.0.0.1")
+
+	socket := option.UploadSocket("127.0.0.1", 65002)
+	ln, err := net.Listen(string(socket.Type), socket.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	go http.Serve(ln, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("local"))
+	}))
+
+	var dialed []string
+	dial := localTransport.DialContext(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, errors.New("tcp is not reachable")
+	})
+
+	// upload server on the same host is dialed by unix socket
+	client := &http.Client{Transport: &http.Transport{DialContext: dial}}
+	resp, err := client.Get(fmt.Sprintf("http://%s/", "127.0.0.1:65002"))
+	assert.Nil(err)
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	assert.Equal("local", string(data))
+	assert.Empty(dialed)
+
+	// upload server on another host and unreachable socket use tcp
+	_, err = dial(context.Background(), "tcp", "127.0.0.2:65002")
+	assert.NotNil(err)
+	_, err = dial(context.Background(), "tcp", "127.0.0.1:65003")
+	assert.NotNil(err)
+	assert.Equal([]string{"127.0.0.2:65002", "127.0.0.1:65003"}, dialed)
+}
@@ -0,0 +1,496 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// peerExchangeMaxTasksPerMessage keeps every message in one udp datagram without fragment.
+	peerExchangeMaxTasksPerMessage = 8
+
+	// peerExchangeMaxMessageSize is the max size of udp datagram.
+	peerExchangeMaxMessageSize = 64 * 1024
+
+	// peerExchangeMaxLocalTasks is the max count of local finished tasks to advertise.
+	peerExchangeMaxLocalTasks = 1024
+
+	// peerExchangeMaxNeighbors is the max count of neighbors learned from messages.
+	peerExchangeMaxNeighbors = 256
+
+	// peerExchangeMaxRemoteTasks is the max count of tasks finished by neighbors.
+	peerExchangeMaxRemoteTasks = 4096
+
+	// peerExchangeMaxHostsPerTask is the max count of neighbors recorded for one task.
+	peerExchangeMaxHostsPerTask = 16
+)
+
+// PeerExchange advertises finished tasks to daemons in the same subnet,
+// and finds neighbors which finished the task when scheduler is unreachable.
+type PeerExchange interface {
+	// Serve starts to receive and advertise messages.
+	Serve() error
+
+	// Stop stops peer exchange.
+	Stop() error
+
+	// Advertise announces the finished task to neighbors.
+	Advertise(taskID, peerID string)
+
+	// FindPeers returns the neighbors which finished the task.
+	FindPeers(taskID string) []*schedulerv1.PeerPacket_DestPeer
+}
+
+// peerExchangeTask is the task advertised in peer exchange message.
+type peerExchangeTask struct {
+	TaskID string `json:"taskID"`
+	PeerID string `json:"peerID"`
+}
+
+// peerExchangeMessage is the udp message of peer exchange,
+// the ip of neighbor is always the source address of the message.
+type peerExchangeMessage struct {
+	HostID  string             `json:"hostID"`
+	RPCPort int32              `json:"rpcPort"`
+	Tasks   []peerExchangeTask `json:"tasks"`
+}
+
+// peerExchangeRemoteTask is the task finished by neighbor.
+type peerExchangeRemoteTask struct {
+	destPeer *schedulerv1.PeerPacket_DestPeer
+	expireAt time.Time
+}
+
+type peerExchange struct {
+	host *schedulerv1.PeerHost
+	opt  config.PeerExchangeOption
+	conn *net.UDPConn
+
+	// seedIPs and subnets are the sources allowed to send messages.
+	seedIPs map[string]bool
+	subnets []*net.IPNet
+
+	mu sync.RWMutex
+	// localTasks stores finished tasks of local daemon, key is task id.
+	localTasks map[string]*peerExchangeLocalTask
+	// remoteTasks stores finished tasks of neighbors, key is task id, then host id.
+	remoteTasks map[string]map[string]*peerExchangeRemoteTask
+	// neighbors stores udp addresses of neighbors, value is expire time,
+	// zero expire time means the neighbor is configured seed.
+	neighbors map[string]time.Time
+
+	done chan struct{}
+	once sync.Once
+}
+
+// peerExchangeLocalTask is the finished task of local daemon.
+type peerExchangeLocalTask struct {
+	peerID       string
+	advertisedAt time.Time
+}
+
+// NewPeerExchange returns a new PeerExchange.
+func NewPeerExchange(host *schedulerv1.PeerHost, opt config.PeerExchangeOption) (PeerExchange, error) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: opt.ListenPort})
+	if err != nil {
+		return nil, err
+	}
+
+	neighbors := map[string]time.Time{}
+	seedIPs := map[string]bool{}
+	for _, seed := range opt.Seeds {
+		addr, err := net.ResolveUDPAddr("udp", seed)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("invalid peer exchange seed %s: %w", seed, err)
+		}
+		neighbors[seed] = time.Time{}
+		seedIPs[addr.IP.String()] = true
+	}
+
+	var subnets []*net.IPNet
+	for _, subnet := range opt.Subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("invalid peer exchange subnet %s: %w", subnet, err)
+		}
+		subnets = append(subnets, ipNet)
+	}
+
+	return &peerExchange{
+		host:        host,
+		opt:         opt,
+		conn:        conn,
+		seedIPs:     seedIPs,
+		subnets:     subnets,
+		localTasks:  map[string]*peerExchangeLocalTask{},
+		remoteTasks: map[string]map[string]*peerExchangeRemoteTask{},
+		neighbors:   neighbors,
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Serve starts to receive and advertise messages.
+func (pe *peerExchange) Serve() error {
+	logger.Infof("peer exchange listen on %s, seeds: %v", pe.conn.LocalAddr(), pe.opt.Seeds)
+	go pe.advertiseLoop()
+
+	buf := make([]byte, peerExchangeMaxMessageSize)
+	for {
+		n, addr, err := pe.conn.ReadFromUDP(buf)
+		if err != nil {
+			select {
+			case <-pe.done:
+				return nil
+			default:
+			}
+
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			logger.Warnf("peer exchange read message error: %s", err)
+			continue
+		}
+
+		msg := &peerExchangeMessage{}
+		if err := json.Unmarshal(buf[:n], msg); err != nil {
+			logger.Warnf("peer exchange unmarshal message from %s error: %s", addr, err)
+			continue
+		}
+
+		pe.handleMessage(addr, msg)
+	}
+}
+
+// Stop stops peer exchange.
+func (pe *peerExchange) Stop() error {
+	var err error
+	pe.once.Do(func() {
+		close(pe.done)
+		err = pe.conn.Close()
+	})
+	return err
+}
+
+// Advertise announces the finished task to neighbors.
+func (pe *peerExchange) Advertise(taskID, peerID string) {
+	pe.mu.Lock()
+	if _, ok := pe.localTasks[taskID]; !ok && len(pe.localTasks) >= peerExchangeMaxLocalTasks {
+		pe.evictLocalTaskLocked()
+	}
+	pe.localTasks[taskID] = &peerExchangeLocalTask{
+		peerID:       peerID,
+		advertisedAt: time.Now(),
+	}
+	pe.mu.Unlock()
+
+	pe.send([]peerExchangeTask{{TaskID: taskID, PeerID: peerID}})
+}
+
+// FindPeers returns the neighbors which finished the task.
+func (pe *peerExchange) FindPeers(taskID string) []*schedulerv1.PeerPacket_DestPeer {
+	pe.mu.RLock()
+	defer pe.mu.RUnlock()
+
+	now := time.Now()
+	var destPeers []*schedulerv1.PeerPacket_DestPeer
+	for _, remoteTask := range pe.remoteTasks[taskID] {
+		if now.After(remoteTask.expireAt) {
+			continue
+		}
+		destPeers = append(destPeers, remoteTask.destPeer)
+	}
+
+	sort.Slice(destPeers, func(i, j int) bool {
+		return destPeers[i].PeerId < destPeers[j].PeerId
+	})
+	return destPeers
+}
+
+// allowed returns whether the source ip is a configured seed or in configured subnets.
+func (pe *peerExchange) allowed(ip net.IP) bool {
+	if pe.seedIPs[ip.String()] {
+		return true
+	}
+
+	for _, subnet := range pe.subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleMessage records the neighbor and its finished tasks,
+// messages from sources which are not seeds or in subnets are dropped.
+func (pe *peerExchange) handleMessage(addr *net.UDPAddr, msg *peerExchangeMessage) {
+	if msg.HostID == "" || msg.HostID == pe.host.Id {
+		return
+	}
+
+	if !pe.allowed(addr.IP) {
+		logger.Debugf("peer exchange drop message from unknown source %s", addr)
+		return
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	expireAt := time.Now().Add(pe.opt.TTL.Duration)
+	if t, ok := pe.neighbors[addr.String()]; ok {
+		if !t.IsZero() {
+			pe.neighbors[addr.String()] = expireAt
+		}
+	} else if len(pe.neighbors) < peerExchangeMaxNeighbors {
+		pe.neighbors[addr.String()] = expireAt
+	}
+
+	for _, task := range msg.Tasks {
+		if task.TaskID == "" || task.PeerID == "" {
+			continue
+		}
+
+		remoteTasks, ok := pe.remoteTasks[task.TaskID]
+		if !ok {
+			if len(pe.remoteTasks) >= peerExchangeMaxRemoteTasks {
+				continue
+			}
+			remoteTasks = map[string]*peerExchangeRemoteTask{}
+			pe.remoteTasks[task.TaskID] = remoteTasks
+		}
+
+		if _, ok := remoteTasks[msg.HostID]; !ok && len(remoteTasks) >= peerExchangeMaxHostsPerTask {
+			continue
+		}
+
+		remoteTasks[msg.HostID] = &peerExchangeRemoteTask{
+			destPeer: &schedulerv1.PeerPacket_DestPeer{
+				Ip:      addr.IP.String(),
+				RpcPort: msg.RPCPort,
+				PeerId:  task.PeerID,
+			},
+			expireAt: expireAt,
+		}
+	}
+}
+
+// advertiseLoop advertises all local finished tasks periodically,
+// and cleans expired neighbors and tasks.
+func (pe *peerExchange) advertiseLoop() {
+	ticker := time.NewTicker(pe.opt.Interval.Duration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pe.gc()
+
+			pe.mu.RLock()
+			tasks := make([]peerExchangeTask, 0, len(pe.localTasks))
+			for taskID, localTask := range pe.localTasks {
+				tasks = append(tasks, peerExchangeTask{TaskID: taskID, PeerID: localTask.peerID})
+			}
+			pe.mu.RUnlock()
+
+			pe.send(tasks)
+		case <-pe.done:
+			return
+		}
+	}
+}
+
+// send sends tasks to all neighbors in batches, message without tasks keeps local daemon alive in neighbors.
+func (pe *peerExchange) send(tasks []peerExchangeTask) {
+	pe.mu.RLock()
+	neighbors := make([]string, 0, len(pe.neighbors))
+	for neighbor := range pe.neighbors {
+		neighbors = append(neighbors, neighbor)
+	}
+	pe.mu.RUnlock()
+
+	if len(neighbors) == 0 {
+		return
+	}
+
+	for start := 0; start < len(tasks) || start == 0; start += peerExchangeMaxTasksPerMessage {
+		end := start + peerExchangeMaxTasksPerMessage
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+
+		b, err := json.Marshal(&peerExchangeMessage{
+			HostID:  pe.host.Id,
+			RPCPort: pe.host.RpcPort,
+			Tasks:   tasks[start:end],
+		})
+		if err != nil {
+			logger.Errorf("peer exchange marshal message error: %s", err)
+			return
+		}
+
+		for _, neighbor := range neighbors {
+			addr, err := net.ResolveUDPAddr("udp", neighbor)
+			if err != nil {
+				logger.Warnf("peer exchange resolve neighbor %s error: %s", neighbor, err)
+				continue
+			}
+
+			if _, err := pe.conn.WriteToUDP(b, addr); err != nil {
+				logger.Debugf("peer exchange send message to %s error: %s", neighbor, err)
+			}
+		}
+	}
+}
+
+// gc cleans expired neighbors and tasks.
+func (pe *peerExchange) gc() {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	now := time.Now()
+	for neighbor, expireAt := range pe.neighbors {
+		if !expireAt.IsZero() && now.After(expireAt) {
+			delete(pe.neighbors, neighbor)
+		}
+	}
+
+	for taskID, remoteTasks := range pe.remoteTasks {
+		for hostID, remoteTask := range remoteTasks {
+			if now.After(remoteTask.expireAt) {
+				delete(remoteTasks, hostID)
+			}
+		}
+
+		if len(remoteTasks) == 0 {
+			delete(pe.remoteTasks, taskID)
+		}
+	}
+}
+
+// evictLocalTaskLocked removes the least recently advertised local task, the caller must hold the lock.
+func (pe *peerExchange) evictLocalTaskLocked() {
+	var (
+		oldestTaskID string
+		oldestAt     time.Time
+	)
+	for taskID, localTask := range pe.localTasks {
+		if oldestTaskID == "" || localTask.advertisedAt.Before(oldestAt) {
+			oldestTaskID, oldestAt = taskID, localTask.advertisedAt
+		}
+	}
+	delete(pe.localTasks, oldestTaskID)
+}
+
+// peerExchangePeerPacketStream is used when scheduler is unreachable and neighbors finished the task,
+// it returns neighbors as parents once, and asks to back source (or fails the task when auto back source
+// is disabled) after all neighbors failed.
+type peerExchangePeerPacketStream struct {
+	grpc.ClientStream
+	packets  chan *schedulerv1.PeerPacket
+	failCode commonv1.Code
+
+	mu          sync.Mutex
+	destPeers   map[string]bool
+	failedPeers map[string]bool
+	closed      bool
+}
+
+func newPeerExchangePeerPacketStream(destPeers []*schedulerv1.PeerPacket_DestPeer, parallelCount int32, disableAutoBackSource bool) *peerExchangePeerPacketStream {
+	failCode := commonv1.Code_SchedNeedBackSource
+	if disableAutoBackSource {
+		failCode = commonv1.Code_SchedError
+	}
+
+	s := &peerExchangePeerPacketStream{
+		packets:     make(chan *schedulerv1.PeerPacket, 2),
+		failCode:    failCode,
+		destPeers:   map[string]bool{},
+		failedPeers: map[string]bool{},
+	}
+	for _, destPeer := range destPeers {
+		s.destPeers[destPeer.PeerId] = true
+	}
+
+	s.packets <- &schedulerv1.PeerPacket{
+		Code:           commonv1.Code_Success,
+		ParallelCount:  parallelCount,
+		MainPeer:       destPeers[0],
+		CandidatePeers: destPeers[1:],
+	}
+	return s
+}
+
+func (s *peerExchangePeerPacketStream) Recv() (*schedulerv1.PeerPacket, error) {
+	pp, ok := <-s.packets
+	if !ok {
+		return nil, io.EOF
+	}
+	return pp, nil
+}
+
+func (s *peerExchangePeerPacketStream) Send(pr *schedulerv1.PieceResult) error {
+	if pr.Success || pr.Code == commonv1.Code_Success || !s.destPeers[pr.DstPid] {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+
+	s.failedPeers[pr.DstPid] = true
+	if len(s.failedPeers) < len(s.destPeers) {
+		return nil
+	}
+
+	s.packets <- &schedulerv1.PeerPacket{
+		TaskId: pr.TaskId,
+		SrcPid: pr.SrcPid,
+		Code:   s.failCode,
+	}
+	close(s.packets)
+	s.closed = true
+	return nil
+}
+
+func (s *peerExchangePeerPacketStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		close(s.packets)
+		s.closed = true
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	testifyrequire "github.com/stretchr/testify/require"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/util"
+)
+
+func newTestPeerExchange(t *testing.T, hostID string, rpcPort int32, seeds ...string) *peerExchange {
+	pe, err := NewPeerExchange(&schedulerv1.PeerHost{
+		Id:      hostID,
+		Ip:      "127.0.0.1",
+		RpcPort: rpcPort,
+	}, config.PeerExchangeOption{
+		Enable:   true,
+		Seeds:    seeds,
+		Subnets:  []string{"127.0.0.0/8"},
+		Interval: util.Duration{Duration: 100 * time.Millisecond},
+		TTL:      util.Duration{Duration: time.Minute},
+	})
+	testifyrequire.Nil(t, err)
+
+	go pe.Serve()                   // nolint: errcheck
+	t.Cleanup(func() { pe.Stop() }) // nolint: errcheck
+	return pe.(*peerExchange)
+}
+
+func TestPeerExchange_Advertise(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	pe2 := newTestPeerExchange(t, "host-2", 65102)
+	pe1 := newTestPeerExchange(t, "host-1", 65101, fmt.Sprintf("127.0.0.1:%d", pe2.conn.LocalAddr().(*net.UDPAddr).Port))
+
+	// pe1 advertises to seed pe2
+	pe1.Advertise("task-1", "peer-1")
+	assert.Eventually(func() bool {
+		return len(pe2.FindPeers("task-1")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(&schedulerv1.PeerPacket_DestPeer{
+		Ip:      "127.0.0.1",
+		RpcPort: 65101,
+		PeerId:  "peer-1",
+	}, pe2.FindPeers("task-1")[0])
+	assert.Len(pe1.FindPeers("task-1"), 0)
+
+	// pe2 learns pe1 as neighbor from the message
+	pe2.Advertise("task-2", "peer-2")
+	assert.Eventually(func() bool {
+		return len(pe1.FindPeers("task-2")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal("peer-2", pe1.FindPeers("task-2")[0].PeerId)
+	assert.Len(pe2.FindPeers("unknown"), 0)
+}
+
+func TestPeerExchange_HandleMessage(t *testing.T) {
+	assert := testifyassert.New(t)
+	_, subnet, _ := net.ParseCIDR("192.168.1.0/24")
+	pe := &peerExchange{
+		host:        &schedulerv1.PeerHost{Id: "local"},
+		opt:         config.PeerExchangeOption{TTL: util.Duration{Duration: time.Minute}},
+		seedIPs:     map[string]bool{"10.0.0.1": true},
+		subnets:     []*net.IPNet{subnet},
+		localTasks:  map[string]*peerExchangeLocalTask{},
+		remoteTasks: map[string]map[string]*peerExchangeRemoteTask{},
+		neighbors:   map[string]time.Time{},
+	}
+	addr := &net.UDPAddr{IP: net.ParseIP("192.168.1.2"), Port: 65006}
+
+	// ignore message from local host
+	pe.handleMessage(addr, &peerExchangeMessage{HostID: "local", Tasks: []peerExchangeTask{{TaskID: "foo", PeerID: "bar"}}})
+	assert.Len(pe.neighbors, 0)
+	assert.Len(pe.FindPeers("foo"), 0)
+
+	// ignore message from source which is neither seed nor in subnets
+	pe.handleMessage(&net.UDPAddr{IP: net.ParseIP("172.16.0.1"), Port: 65006}, &peerExchangeMessage{HostID: "unknown", Tasks: []peerExchangeTask{{TaskID: "foo", PeerID: "bar"}}})
+	assert.Len(pe.neighbors, 0)
+	assert.Len(pe.FindPeers("foo"), 0)
+
+	// accept message from seed
+	pe.handleMessage(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 65006}, &peerExchangeMessage{HostID: "seed", RPCPort: 65000, Tasks: []peerExchangeTask{{TaskID: "qux", PeerID: "bar"}}})
+	assert.Equal([]*schedulerv1.PeerPacket_DestPeer{{Ip: "10.0.0.1", RpcPort: 65000, PeerId: "bar"}}, pe.FindPeers("qux"))
+	delete(pe.neighbors, "10.0.0.1:65006")
+	delete(pe.remoteTasks, "qux")
+
+	// use source ip of the message
+	pe.handleMessage(addr, &peerExchangeMessage{HostID: "remote", RPCPort: 65000, Tasks: []peerExchangeTask{{TaskID: "foo", PeerID: "bar"}, {TaskID: "baz"}}})
+	assert.Len(pe.neighbors, 1)
+	assert.Equal([]*schedulerv1.PeerPacket_DestPeer{{Ip: "192.168.1.2", RpcPort: 65000, PeerId: "bar"}}, pe.FindPeers("foo"))
+	assert.Len(pe.FindPeers("baz"), 0)
+
+	// expired tasks are cleaned
+	pe.remoteTasks["foo"]["remote"].expireAt = time.Now().Add(-time.Second)
+	pe.neighbors[addr.String()] = time.Now().Add(-time.Second)
+	assert.Len(pe.FindPeers("foo"), 0)
+	pe.gc()
+	assert.Len(pe.remoteTasks, 0)
+	assert.Len(pe.neighbors, 0)
+}
+
+func TestPeerExchange_HandleMessageLimit(t *testing.T) {
+	assert := testifyassert.New(t)
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/8")
+	pe := &peerExchange{
+		host:        &schedulerv1.PeerHost{Id: "local"},
+		opt:         config.PeerExchangeOption{TTL: util.Duration{Duration: time.Minute}},
+		subnets:     []*net.IPNet{subnet},
+		localTasks:  map[string]*peerExchangeLocalTask{},
+		remoteTasks: map[string]map[string]*peerExchangeRemoteTask{},
+		neighbors:   map[string]time.Time{},
+	}
+
+	for i := 0; i < peerExchangeMaxNeighbors+10; i++ {
+		addr := &net.UDPAddr{IP: net.IPv4(10, 0, byte(i/256), byte(i%256)), Port: 65006}
+		pe.handleMessage(addr, &peerExchangeMessage{HostID: addr.String(), Tasks: []peerExchangeTask{{TaskID: "foo", PeerID: "bar"}}})
+	}
+	assert.Len(pe.neighbors, peerExchangeMaxNeighbors)
+	assert.Len(pe.remoteTasks["foo"], peerExchangeMaxHostsPerTask)
+
+	addr := &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 65006}
+	for i := 0; i < peerExchangeMaxRemoteTasks+10; i++ {
+		pe.handleMessage(addr, &peerExchangeMessage{HostID: "remote", Tasks: []peerExchangeTask{{TaskID: fmt.Sprintf("task-%d", i), PeerID: "bar"}}})
+	}
+	assert.Len(pe.remoteTasks, peerExchangeMaxRemoteTasks)
+}
+
+func TestPeerExchange_EvictLocalTask(t *testing.T) {
+	assert := testifyassert.New(t)
+	pe := &peerExchange{
+		host:        &schedulerv1.PeerHost{Id: "local"},
+		localTasks:  map[string]*peerExchangeLocalTask{},
+		remoteTasks: map[string]map[string]*peerExchangeRemoteTask{},
+		neighbors:   map[string]time.Time{},
+	}
+	pe.localTasks["oldest"] = &peerExchangeLocalTask{peerID: "peer", advertisedAt: time.Now().Add(-time.Hour)}
+	for i := 1; i < peerExchangeMaxLocalTasks; i++ {
+		pe.localTasks[string(rune(i))] = &peerExchangeLocalTask{peerID: "peer", advertisedAt: time.Now()}
+	}
+
+	pe.Advertise("newest", "peer")
+	assert.Len(pe.localTasks, peerExchangeMaxLocalTasks)
+	assert.NotContains(pe.localTasks, "oldest")
+	assert.Contains(pe.localTasks, "newest")
+}
+
+func TestPeerExchangePeerPacketStream(t *testing.T) {
+	destPeers := []*schedulerv1.PeerPacket_DestPeer{{PeerId: "peer-1"}, {PeerId: "peer-2"}}
+	testCases := []struct {
+		name                  string
+		disableAutoBackSource bool
+		expectCode            commonv1.Code
+	}{
+		{
+			name:       "back source after all neighbors failed",
+			expectCode: commonv1.Code_SchedNeedBackSource,
+		},
+		{
+			name:                  "fail after all neighbors failed when auto back source disabled",
+			disableAutoBackSource: true,
+			expectCode:            commonv1.Code_SchedError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			s := newPeerExchangePeerPacketStream(destPeers, 4, tc.disableAutoBackSource)
+
+			pp, err := s.Recv()
+			assert.Nil(err)
+			assert.Equal(commonv1.Code_Success, pp.Code)
+			assert.Equal("peer-1", pp.MainPeer.PeerId)
+			assert.Len(pp.CandidatePeers, 1)
+			assert.Equal(int32(4), pp.ParallelCount)
+
+			assert.Nil(s.Send(&schedulerv1.PieceResult{DstPid: "peer-1", Success: true, Code: commonv1.Code_Success}))
+			assert.Nil(s.Send(&schedulerv1.PieceResult{DstPid: "peer-1", Code: commonv1.Code_ClientPieceDownloadFail}))
+			assert.Nil(s.Send(&schedulerv1.PieceResult{DstPid: "unknown", Code: commonv1.Code_ClientPieceDownloadFail}))
+			assert.Nil(s.Send(&schedulerv1.PieceResult{DstPid: "peer-2", Code: commonv1.Code_ClientPieceDownloadFail}))
+
+			pp, err = s.Recv()
+			assert.Nil(err)
+			assert.Equal(tc.expectCode, pp.Code)
+
+			_, err = s.Recv()
+			assert.Equal(io.EOF, err)
+			assert.Nil(s.Send(&schedulerv1.PieceResult{DstPid: "peer-2", Code: commonv1.Code_ClientPieceDownloadFail}))
+			assert.Nil(s.CloseSend())
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+)
+
+var (
+	// ErrTaskNotRunning means the peer task is not running, it may be done or never started.
+	ErrTaskNotRunning = errors.New("peer task is not running")
+
+	// ErrTaskSubscribed means the peer task is still shared by other local requests.
+	ErrTaskSubscribed = errors.New("peer task is subscribed by other requests")
+)
+
+// CancelTask cancels the running peer task and reclaims its storage, the peer leaves the task
+// in scheduler when the storage is reclaimed. Unless force is set, the peer task is canceled
+// only when no local request subscribes to it, so the requests sharing it are not interrupted.
+func (ptm *peerTaskManager) CancelTask(ctx context.Context, taskID string, force bool) error {
+	ptc, ok := ptm.findPeerTaskConductor(taskID)
+	if !ok {
+		return ErrTaskNotRunning
+	}
+
+	return ptm.cancelPeerTask(ctx, ptc, force)
+}
+
+func (ptm *peerTaskManager) cancelPeerTask(ctx context.Context, ptc *peerTaskConductor, force bool) error {
+	if !force {
+		if subscribers := ptc.subscribers.Load(); subscribers > 0 {
+			return fmt.Errorf("%w, subscribers: %d", ErrTaskSubscribed, subscribers)
+		}
+	}
+
+	if !ptc.abort(commonv1.Code_ClientContextCanceled, "peer task canceled") {
+		return ErrTaskNotRunning
+	}
+	ptc.Infof("peer task canceled, force: %t", force)
+
+	// storage is registered after the content length is known,
+	// before that there is nothing to reclaim, leave task in scheduler directly
+	if ptc.GetStorage() == nil {
+		err := ptm.schedulerClient.LeaveTask(ctx, &schedulerv1.PeerTarget{
+			TaskId: ptc.taskID,
+			PeerId: ptc.peerID,
+		})
+		if err != nil {
+			ptc.Errorf("leave task of canceled peer task error: %s", err)
+		}
+		return nil
+	}
+
+	return ptm.storageManager.UnregisterTask(ctx, storage.CommonTaskRequest{
+		PeerID: ptc.peerID,
+		TaskID: ptc.taskID,
+	})
+}
@@ -0,0 +1,78 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func TestPeerTaskManager_CancelTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		subscribers int32
+		running     bool
+		force       bool
+		expect      func(t *testing.T, err error)
+	}{
+		{
+			name: "task is not running",
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(err, ErrTaskNotRunning)
+			},
+		},
+		{
+			name:        "task is subscribed by other requests",
+			subscribers: 1,
+			running:     true,
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(err, ErrTaskSubscribed)
+			},
+		},
+		{
+			name:    "task is already done",
+			running: true,
+			force:   true,
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(err, ErrTaskNotRunning)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ptm := &peerTaskManager{}
+			if tc.running {
+				ptc := &peerTaskConductor{
+					taskID:      "task",
+					subscribers: atomic.NewInt32(tc.subscribers),
+				}
+				// the conductor finished before canceling
+				ptc.statusOnce.Do(func() {})
+				ptm.runningPeerTasks.Store(ptc.taskID, ptc)
+			}
+
+			tc.expect(t, ptm.CancelTask(context.Background(), "task", tc.force))
+		})
+	}
+}
@@ -43,8 +43,11 @@ import (
 	"d7y.io/dragonfly/v2/client/util"
 	"d7y.io/dragonfly/v2/internal/dferrors"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	internalutil "d7y.io/dragonfly/v2/internal/util"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
 	"d7y.io/dragonfly/v2/pkg/source"
 )
@@ -105,8 +108,12 @@ type peerTaskConductor struct {
 	completedLength *atomic.Int64
 	usedTraffic     *atomic.Uint64
 	header          atomic.Value
+	// eta is the estimated remaining time of downloading pushed by scheduler
+	eta *atomic.Duration
 
 	broker *pieceBroker
+	// subscribers is the count of local requests sharing the peer task
+	subscribers *atomic.Int32
 
 	sizeScope   commonv1.SizeScope
 	singlePiece *schedulerv1.SinglePiece
@@ -114,6 +121,9 @@ type peerTaskConductor struct {
 
 	// peerPacketStream stands schedulerclient.PeerPacketStream from scheduler
 	peerPacketStream schedulerv1.Scheduler_ReportPieceResultClient
+	// migratedCount is the count of migrating to other schedulers,
+	// it is only accessed in receivePeerPacket
+	migratedCount int
 	// peerPacket is the latest available peers from peerPacketCh
 	// Deprecated: remove in future release
 	peerPacket      atomic.Value // *schedulerv1.PeerPacket
@@ -135,6 +145,10 @@ type peerTaskConductor struct {
 
 	// span stands open telemetry trace span
 	span trace.Span
+	// timing records the timing summary of peer task
+	timing *taskTimingRecorder
+	// parentExclusion tracks recently failed or slow parents reported to scheduler
+	parentExclusion *parentExclusion
 
 	// failedPieceCh will hold all pieces which download failed,
 	// those pieces will be retried later
@@ -144,6 +158,10 @@ type peerTaskConductor struct {
 	// failedReason will be set when peer task failed
 	failedCode commonv1.Code
 
+	// digestVerifier verifies the digest of url meta as pieces complete in order,
+	// it is nil when the digest is not set or not verifiable
+	digestVerifier *digestVerifier
+
 	// readyPieces stands all downloaded pieces
 	readyPieces *Bitmap
 	// lock used by piece result manage, when update readyPieces, lock first
@@ -208,9 +226,10 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 
 	span.SetAttributes(config.AttributeTaskID.String(taskID))
 
+	startTime := time.Now()
 	ptc := &peerTaskConductor{
 		ptm:                 ptm,
-		startTime:           time.Now(),
+		startTime:           startTime,
 		ctx:                 ctx,
 		broker:              newPieceBroker(),
 		host:                ptm.host,
@@ -225,6 +244,8 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 		failCh:              make(chan struct{}),
 		legacyPeerCount:     atomic.NewInt64(0),
 		span:                span,
+		timing:              newTaskTimingRecorder(taskID, request.PeerId, startTime),
+		parentExclusion:     newParentExclusion(ptm.schedulerOption.ParentExclusion),
 		readyPieces:         NewBitmap(),
 		runningPieces:       NewBitmap(),
 		requestedPieces:     NewBitmap(),
@@ -238,6 +259,8 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 		limiter:             rate.NewLimiter(limit, int(limit)),
 		completedLength:     atomic.NewInt64(0),
 		usedTraffic:         atomic.NewUint64(0),
+		eta:                 atomic.NewDuration(0),
+		subscribers:         atomic.NewInt32(0),
 		SugaredLoggerOnWith: log,
 		seed:                seed,
 
@@ -250,6 +273,17 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 		peerTaskConductor: ptc,
 	}
 
+	// subtask only downloads a range of the content, the digest of url meta can not be verified.
+	if ptm.calculateDigest && parent == nil && rg == nil && request.UrlMeta != nil &&
+		request.UrlMeta.Digest != "" && request.UrlMeta.Range == "" {
+		verifier, err := newDigestVerifier(request.UrlMeta.Digest)
+		if err != nil {
+			log.Warnf("digest %s is not verifiable: %s", request.UrlMeta.Digest, err)
+		} else {
+			ptc.digestVerifier = verifier
+		}
+	}
+
 	ptc.pieceDownloadCtx, ptc.pieceDownloadCancel = context.WithCancel(ptc.ctx)
 
 	return ptc
@@ -274,7 +308,7 @@ func (pt *peerTaskConductor) register() error {
 	pt.Infof("step 1: peer %s start to register", pt.request.PeerId)
 	pt.schedulerClient = pt.peerTaskManager.schedulerClient
 
-	result, err := pt.schedulerClient.RegisterPeerTask(regCtx, pt.request)
+	result, err := pt.registerPeerTask(regCtx)
 	regSpan.RecordError(err)
 	regSpan.End()
 
@@ -283,6 +317,17 @@ func (pt *peerTaskConductor) register() error {
 			pt.Errorf("scheduler did not response in %s", pt.peerTaskManager.schedulerOption.ScheduleTimeout.Duration)
 		}
 		pt.Errorf("step 1: peer %s register failed: %s", pt.request.PeerId, err)
+		// task is blocked by scheduler, do not try to back source
+		if status.Code(err) == codes.PermissionDenied {
+			pt.peerPacketStream = &dummyPeerPacketStream{}
+			pt.Errorf("register peer task failed: %s, peer id: %s, task is blocked by scheduler", err, pt.request.PeerId)
+			pt.span.RecordError(err)
+			pt.cancel(commonv1.Code_SchedError, err.Error())
+			return err
+		}
+		if pt.registerWithPeerExchange() {
+			return nil
+		}
 		if pt.peerTaskManager.schedulerOption.DisableAutoBackSource {
 			// when peer register failed, some actions need to do with peerPacketStream
 			pt.peerPacketStream = &dummyPeerPacketStream{}
@@ -347,7 +392,7 @@ func (pt *peerTaskConductor) register() error {
 		return err
 	}
 
-	pt.peerPacketStream = peerPacketStream
+	pt.peerPacketStream = newBatchPeerPacketStream(peerPacketStream, pt.schedulerOption.PieceResultBatch, pt.SugaredLoggerOnWith)
 	pt.sizeScope = sizeScope
 	pt.singlePiece = singlePiece
 	pt.tinyData = tinyData
@@ -356,9 +401,59 @@ func (pt *peerTaskConductor) register() error {
 	if len(header) > 0 {
 		pt.SetHeader(header)
 	}
+
+	if !needBackSource && sizeScope == commonv1.SizeScope_NORMAL {
+		go pt.watchETA()
+	}
 	return nil
 }
 
+// registerWithPeerExchange downloads from neighbors which finished the task when scheduler is unreachable
+// registerPeerTask registers peer task to scheduler, when the registration is throttled by scheduler,
+// it is retried after the delay told by scheduler until the context is done.
+func (pt *peerTaskConductor) registerPeerTask(ctx context.Context) (*schedulerv1.RegisterResult, error) {
+	for {
+		start := time.Now()
+		result, err := pt.schedulerClient.RegisterPeerTask(ctx, pt.request)
+		recordSchedulerRequestMetrics(metrics.SchedulerRequestMethodRegisterPeerTask, start, err)
+
+		delay, ok := common.RetryAfter(err)
+		if !ok {
+			return result, err
+		}
+
+		pt.Warnf("register peer task is throttled by scheduler, retry after %s", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+func (pt *peerTaskConductor) registerWithPeerExchange() bool {
+	if pt.peerTaskManager.peerExchange == nil {
+		return false
+	}
+
+	destPeers := pt.peerTaskManager.peerExchange.FindPeers(pt.taskID)
+	if len(destPeers) == 0 {
+		pt.Infof("no neighbor finished the task in peer exchange")
+		return false
+	}
+
+	pt.Infof("scheduler is unreachable, download from %d neighbors in peer exchange", len(destPeers))
+	pt.span.AddEvent("register with peer exchange")
+	pt.schedulerClient = &dummySchedulerClient{}
+	pt.peerPacketStream = newPeerExchangePeerPacketStream(destPeers, defaultDownloadPieceWorkerCount,
+		pt.peerTaskManager.schedulerOption.DisableAutoBackSource)
+	pt.sizeScope = commonv1.SizeScope_NORMAL
+	pt.needBackSource = atomic.NewBool(false)
+	return true
+}
+
 func (pt *peerTaskConductor) start() error {
 	// when is seed task, setup back source
 	if pt.seed {
@@ -374,6 +469,14 @@ func (pt *peerTaskConductor) start() error {
 	}
 
 	go pt.broker.Start()
+
+	// all pieces are restored from storage, no need to pull pieces
+	if pt.completedLength.Load() > 0 && pt.isCompleted() {
+		pt.Infof("all pieces are restored from storage, mark peer task done")
+		pt.Done()
+		return nil
+	}
+
 	go pt.pullPieces()
 	return nil
 }
@@ -458,6 +561,18 @@ func (pt *peerTaskConductor) cancel(code commonv1.Code, reason string) {
 	})
 }
 
+// abort cancels the peer task like cancel, and reports whether the peer task is stopped by this call,
+// it is false when the peer task has already succeeded or failed.
+func (pt *peerTaskConductor) abort(code commonv1.Code, reason string) (aborted bool) {
+	pt.statusOnce.Do(func() {
+		aborted = true
+		pt.failedCode = code
+		pt.failedReason = reason
+		pt.fail()
+	})
+	return aborted
+}
+
 func (pt *peerTaskConductor) cancelNotRegisterred(code commonv1.Code, reason string) {
 	pt.statusOnce.Do(func() {
 		pt.failedCode = code
@@ -509,6 +624,7 @@ func (pt *peerTaskConductor) forceBackSource() {
 }
 
 func (pt *peerTaskConductor) backSource() {
+	pt.timing.markBackSource()
 	// cancel all piece download
 	pt.pieceDownloadCancel()
 	// cancel all sync pieces
@@ -586,6 +702,7 @@ func (pt *peerTaskConductor) storeTinyPeerTask() {
 				PeerID: pt.tinyData.PeerID,
 				TaskID: pt.tinyData.TaskID,
 			},
+			URL:             pt.request.Url,
 			DesiredLocation: "",
 			ContentLength:   contentLength,
 			TotalPieces:     1,
@@ -684,6 +801,10 @@ loop:
 			break loop
 		}
 		if err != nil {
+			// scheduler connection is broken, continue with another scheduler
+			if pt.tryMigrate(err) {
+				continue
+			}
 			pt.confirmReceivePeerPacketError(err)
 			if !firstPacketReceived {
 				firstPeerSpan.RecordError(err)
@@ -694,6 +815,7 @@ loop:
 		pt.Debugf("receive peerPacket %v", peerPacket)
 		if peerPacket.Code != commonv1.Code_Success {
 			if peerPacket.Code == commonv1.Code_SchedNeedBackSource {
+				pt.timing.scheduled()
 				pt.markBackSource()
 				pt.Infof("receive back source code")
 				return
@@ -726,6 +848,7 @@ loop:
 			trace.WithAttributes(config.AttributeMainPeer.String(peerPacket.MainPeer.PeerId)))
 
 		if !firstPacketReceived {
+			pt.timing.scheduled()
 			pt.initDownloadPieceWorkers(peerPacket.ParallelCount, pieceRequestCh)
 			firstPeerSpan.SetAttributes(config.AttributeMainPeer.String(peerPacket.MainPeer.PeerId))
 			firstPeerSpan.End()
@@ -1016,7 +1139,10 @@ func (pt *peerTaskConductor) updateMetadata(piecePacket *commonv1.PiecePacket) {
 
 func (pt *peerTaskConductor) initDownloadPieceWorkers(count int32, pieceRequestCh chan *DownloadPieceRequest) {
 	if count < 1 {
-		count = 4
+		count = defaultDownloadPieceWorkerCount
+	}
+	if pt.pieceTaskSyncManager != nil {
+		pt.pieceTaskSyncManager.setWorkerCount(count)
 	}
 	for i := int32(0); i < count; i++ {
 		go pt.downloadPieceWorker(i, pieceRequestCh)
@@ -1160,6 +1286,7 @@ func (pt *peerTaskConductor) downloadPieceWorker(id int32, requests chan *Downlo
 			pt.readyPiecesLock.RLock()
 			if pt.readyPieces.IsSet(request.piece.PieceNum) {
 				pt.readyPiecesLock.RUnlock()
+				request.limiter.cancel()
 				pt.Log().Debugf("piece %d is already downloaded, skip", request.piece.PieceNum)
 				continue
 			}
@@ -1183,6 +1310,7 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 	pt.runningPiecesLock.Lock()
 	if pt.runningPieces.IsSet(request.piece.PieceNum) {
 		pt.runningPiecesLock.Unlock()
+		request.limiter.cancel()
 		pt.Log().Debugf("piece %d is downloading, skip", request.piece.PieceNum)
 		// TODO save to queue for failed pieces
 		return
@@ -1202,6 +1330,7 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 
 	// wait limit
 	if pt.limiter != nil && !pt.waitLimit(ctx, request) {
+		request.limiter.cancel()
 		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
 		span.End()
 		return
@@ -1213,8 +1342,15 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 	// download piece
 	// result is always not nil, pieceManager will report begin and end time
 	result, err := pt.pieceManager.DownloadPiece(ctx, request)
+	pt.releaseDestPeerLimiter(request, result, err)
 	if err != nil {
-		pt.ReportPieceResult(request, result, err)
+		if isPieceThrottled(err) {
+			// the limiter of dest peer is decreased, retry the piece without failing the dest peer
+			metrics.PieceTaskThrottledCount.Add(1)
+			pt.Infof("piece %d is throttled by dest peer %s, retry later", request.piece.PieceNum, request.DstPid)
+		} else {
+			pt.ReportPieceResult(request, result, err)
+		}
 		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
 		span.End()
 		if pt.needBackSource.Load() {
@@ -1259,6 +1395,20 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 	span.End()
 }
 
+// releaseDestPeerLimiter returns the slot of destination peer and learns its rate from the piece download.
+func (pt *peerTaskConductor) releaseDestPeerLimiter(request *DownloadPieceRequest, result *DownloadPieceResult, err error) {
+	if request.limiter == nil {
+		return
+	}
+	if result == nil {
+		request.limiter.release(0, 0, err == nil)
+		return
+	}
+
+	request.limiter.release(result.Size, time.Duration(result.FinishTime-result.BeginTime), err == nil)
+	pt.Debugf("dest peer %s limit: %d, rate: %.0f bytes/s", request.DstPid, request.limiter.Limit(), request.limiter.Rate())
+}
+
 func (pt *peerTaskConductor) waitLimit(ctx context.Context, request *DownloadPieceRequest) bool {
 	_, waitSpan := tracer.Start(ctx, config.SpanWaitPieceLimit)
 	err := pt.limiter.WaitN(pt.ctx, int(request.piece.RangeSize))
@@ -1368,25 +1518,53 @@ func (pt *peerTaskConductor) ReportPieceResult(request *DownloadPieceRequest, re
 	pt.reportFailResult(request, result, code)
 }
 
+// peerAddr returns the peer grpc address of parent, unix socket is used for the parent on the same host.
+func (pt *peerTaskConductor) peerAddr(ip string, port int32) dfnet.NetAddr {
+	var localTransport *LocalTransport
+	if pt.peerTaskManager != nil {
+		localTransport = pt.peerTaskManager.localTransport
+	}
+
+	return localTransport.PeerAddr(ip, port)
+}
+
+// hostLoad returns the host load reported with piece results.
+func (pt *peerTaskConductor) hostLoad() *commonv1.HostLoad {
+	if pt.peerTaskManager == nil {
+		return nil
+	}
+	return pt.peerTaskManager.hostLoad.Load()
+}
+
 func (pt *peerTaskConductor) reportSuccessResult(request *DownloadPieceRequest, result *DownloadPieceResult) {
 	metrics.PieceTaskCount.Add(1)
+	recordPieceMetrics(request, result, true)
+	pt.timing.addPeerBytes(request.DstPid, int64(request.piece.RangeSize))
 	_, span := tracer.Start(pt.ctx, config.SpanReportPieceResult)
 	span.SetAttributes(config.AttributeWritePieceSuccess.Bool(true))
 
-	err := pt.sendPieceResult(
-		&schedulerv1.PieceResult{
-			TaskId:        pt.GetTaskID(),
-			SrcPid:        pt.GetPeerID(),
-			DstPid:        request.DstPid,
-			PieceInfo:     request.piece,
-			BeginTime:     uint64(result.BeginTime),
-			EndTime:       uint64(result.FinishTime),
-			Success:       true,
-			Code:          commonv1.Code_Success,
-			HostLoad:      nil, // TODO(jim): update host load
-			FinishedCount: pt.readyPieces.Settled(),
-			// TODO range_start, range_size, piece_md5, piece_offset, piece_style
-		})
+	pieceResult := &schedulerv1.PieceResult{
+		TaskId:        pt.GetTaskID(),
+		SrcPid:        pt.GetPeerID(),
+		DstPid:        request.DstPid,
+		PieceInfo:     request.piece,
+		BeginTime:     uint64(result.BeginTime),
+		EndTime:       uint64(result.FinishTime),
+		Success:       true,
+		Code:          commonv1.Code_Success,
+		HostLoad:      pt.hostLoad(),
+		FinishedCount: pt.readyPieces.Settled(),
+		// TODO range_start, range_size, piece_md5, piece_offset, piece_style
+	}
+
+	// Hint scheduler to exclude the slow parent, only slow results carry the hint,
+	// so that the other results can still be reported in batch.
+	if pt.parentExclusion.addIfSlow(request.DstPid, time.Duration(result.FinishTime-result.BeginTime)) {
+		pt.Warnf("piece %d from parent %s is slow, exclude it", request.piece.PieceNum, request.DstPid)
+		common.SetExcludePeers(pieceResult, pt.parentExclusion.list())
+	}
+
+	err := pt.sendPieceResult(pieceResult)
 	if err != nil {
 		pt.Errorf("report piece task error: %v", err)
 		span.RecordError(err)
@@ -1397,10 +1575,12 @@ func (pt *peerTaskConductor) reportSuccessResult(request *DownloadPieceRequest,
 
 func (pt *peerTaskConductor) reportFailResult(request *DownloadPieceRequest, result *DownloadPieceResult, code commonv1.Code) {
 	metrics.PieceTaskFailedCount.Add(1)
+	recordPieceMetrics(request, result, false)
+	pt.timing.addPieceRetry()
 	_, span := tracer.Start(pt.ctx, config.SpanReportPieceResult)
 	span.SetAttributes(config.AttributeWritePieceSuccess.Bool(false))
 
-	err := pt.sendPieceResult(&schedulerv1.PieceResult{
+	pieceResult := &schedulerv1.PieceResult{
 		TaskId:        pt.GetTaskID(),
 		SrcPid:        pt.GetPeerID(),
 		DstPid:        request.DstPid,
@@ -1409,9 +1589,15 @@ func (pt *peerTaskConductor) reportFailResult(request *DownloadPieceRequest, res
 		EndTime:       uint64(result.FinishTime),
 		Success:       false,
 		Code:          code,
-		HostLoad:      nil,
+		HostLoad:      pt.hostLoad(),
 		FinishedCount: pt.readyPieces.Settled(),
-	})
+	}
+
+	// Hint scheduler to exclude the failed parent and other recently excluded parents.
+	pt.parentExclusion.addFailed(request.DstPid)
+	common.SetExcludePeers(pieceResult, pt.parentExclusion.list())
+
+	err := pt.sendPieceResult(pieceResult)
 	if err != nil {
 		pt.Errorf("report piece task error: %v", err)
 	}
@@ -1427,6 +1613,7 @@ func (pt *peerTaskConductor) initStorage(desiredLocation string) (err error) {
 					PeerID: pt.GetPeerID(),
 					TaskID: pt.GetTaskID(),
 				},
+				URL:             pt.request.Url,
 				DesiredLocation: desiredLocation,
 				ContentLength:   pt.GetContentLength(),
 				TotalPieces:     pt.GetTotalPieces(),
@@ -1448,11 +1635,93 @@ func (pt *peerTaskConductor) initStorage(desiredLocation string) (err error) {
 	}
 	if err != nil {
 		pt.Log().Errorf("register task to storage manager failed: %s", err)
+		return err
 	}
-	return err
+
+	if pt.parent == nil {
+		pt.restoreReadyPieces()
+	}
+	return nil
+}
+
+// restoreReadyPieces marks the pieces which already exist in storage as ready,
+// it's used for resuming the interrupted peer task with the same peer id.
+func (pt *peerTaskConductor) restoreReadyPieces() {
+	meta := &storage.PeerTaskMetadata{
+		PeerID: pt.GetPeerID(),
+		TaskID: pt.GetTaskID(),
+	}
+	totalPieces, err := pt.storage.GetTotalPieces(pt.ctx, meta)
+	if err != nil {
+		pt.Warnf("get total pieces from storage error: %s", err)
+		return
+	}
+
+	// the task data is not written to storage yet
+	packet, err := pt.storage.GetPieces(pt.ctx, &commonv1.PieceTaskRequest{TaskId: pt.taskID, Limit: 1})
+	if err != nil || packet.ContentLength < 0 {
+		return
+	}
+
+	limit := totalPieces
+	if limit <= 0 {
+		// the piece size may differ from current policy, use the max possible piece count
+		limit = internalutil.ComputePieceCount(packet.ContentLength, internalutil.DefaultMinPieceSize)
+	}
+	packet, err = pt.storage.GetPieces(pt.ctx,
+		&commonv1.PieceTaskRequest{
+			TaskId:   pt.taskID,
+			StartNum: 0,
+			Limit:    uint32(limit),
+		})
+	if err != nil {
+		pt.Warnf("get pieces from storage error: %s", err)
+		return
+	}
+	if len(packet.PieceInfos) == 0 {
+		return
+	}
+
+	pt.readyPiecesLock.Lock()
+	pt.requestedPiecesLock.Lock()
+	for _, piece := range packet.PieceInfos {
+		if pt.readyPieces.IsSet(piece.PieceNum) {
+			continue
+		}
+		pt.readyPieces.Set(piece.PieceNum)
+		pt.requestedPieces.Set(piece.PieceNum)
+		pt.completedLength.Add(int64(piece.RangeSize))
+	}
+	pt.requestedPiecesLock.Unlock()
+	pt.readyPiecesLock.Unlock()
+
+	pt.SetContentLength(packet.ContentLength)
+	if totalPieces > 0 {
+		pt.SetTotalPieces(totalPieces)
+	}
+	if len(packet.PieceMd5Sign) > 0 {
+		pt.SetPieceMd5Sign(packet.PieceMd5Sign)
+	}
+	metrics.PeerTaskResumedPieceCount.Add(float64(len(packet.PieceInfos)))
+	pt.Infof("restored %d ready pieces from storage, completed length: %d, content length: %d",
+		len(packet.PieceInfos), pt.completedLength.Load(), packet.ContentLength)
 }
 
 func (pt *peerTaskConductor) UpdateStorage() error {
+	// the total pieces may be known after the last piece published in back source case,
+	// so verify the remaining content before recording the digest
+	var verifiedDigest string
+	if pt.digestVerifier != nil {
+		if err := pt.verifyDigest(); err != nil {
+			pt.Log().Errorf("verify digest error: %s", err)
+			return err
+		}
+
+		if pt.digestVerifier.isVerified() {
+			verifiedDigest = pt.request.UrlMeta.Digest
+		}
+	}
+
 	// update storage
 	err := pt.GetStorage().UpdateTask(pt.ctx,
 		&storage.UpdateTaskRequest{
@@ -1464,6 +1733,7 @@ func (pt *peerTaskConductor) UpdateStorage() error {
 			TotalPieces:   pt.GetTotalPieces(),
 			PieceMd5Sign:  pt.GetPieceMd5Sign(),
 			Header:        pt.GetHeader(),
+			Digest:        verifiedDigest,
 		})
 	if err != nil {
 		pt.Log().Errorf("update task to storage manager failed: %s", err)
@@ -1490,6 +1760,8 @@ func (pt *peerTaskConductor) done() {
 		cost    = time.Since(pt.startTime).Milliseconds()
 		success = true
 		code    = commonv1.Code_Success
+		// corrupted pieces are removed from storage and re-downloaded after this peer task finished
+		corrupted *storage.CorruptedPiecesError
 	)
 	pt.Log().Infof("peer task done, cost: %dms", cost)
 	// TODO merge error handle
@@ -1499,6 +1771,9 @@ func (pt *peerTaskConductor) done() {
 		if err = pt.Validate(); err == nil {
 			close(pt.successCh)
 			pt.span.SetAttributes(config.AttributePeerTaskSuccess.Bool(true))
+			if pt.peerTaskManager.peerExchange != nil && pt.parent == nil {
+				pt.peerTaskManager.peerExchange.Advertise(pt.taskID, pt.peerID)
+			}
 		} else {
 			close(pt.failCh)
 			success = false
@@ -1511,6 +1786,7 @@ func (pt *peerTaskConductor) done() {
 			pt.span.SetAttributes(config.AttributePeerTaskMessage.String(pt.failedReason))
 			pt.Errorf("validate digest failed: %s", err)
 			metrics.PeerTaskFailedCount.WithLabelValues(metrics.FailTypeP2P).Add(1)
+			errors.As(err, &corrupted)
 		}
 	} else {
 		close(pt.failCh)
@@ -1526,9 +1802,14 @@ func (pt *peerTaskConductor) done() {
 		metrics.PeerTaskFailedCount.WithLabelValues(metrics.FailTypeP2P).Add(1)
 	}
 
+	pt.peerTaskManager.taskTimings.store(pt.timing.finish(success, pt.span))
 	pt.peerTaskManager.PeerTaskDone(pt.taskID)
 	peerResultCtx, peerResultSpan := tracer.Start(pt.ctx, config.SpanReportPeerResult)
 	defer peerResultSpan.End()
+	if corrupted != nil {
+		// repair after the peer result is reported, the repairing peer task registers with the same peer id
+		defer pt.peerTaskManager.repairPeerTask(pt.request, pt.seed, corrupted.PieceNums)
+	}
 
 	// send EOF piece result to scheduler
 	err := pt.sendPieceResult(
@@ -1538,6 +1819,7 @@ func (pt *peerTaskConductor) done() {
 	err = pt.peerPacketStream.CloseSend()
 	pt.Debugf("close stream result: %v", err)
 
+	reportStart := time.Now()
 	err = pt.schedulerClient.ReportPeerResult(
 		peerResultCtx,
 		&schedulerv1.PeerResult{
@@ -1554,6 +1836,7 @@ func (pt *peerTaskConductor) done() {
 			Success:         success,
 			Code:            code,
 		})
+	recordSchedulerRequestMetrics(metrics.SchedulerRequestMethodReportPeerResult, reportStart, err)
 	if err != nil {
 		peerResultSpan.RecordError(err)
 		pt.Errorf("step 3: report successful peer result, error: %v", err)
@@ -1581,6 +1864,7 @@ func (pt *peerTaskConductor) fail() {
 			pt.pieceTaskSyncManager.cancel()
 		}
 	}()
+	pt.peerTaskManager.taskTimings.store(pt.timing.finish(false, pt.span))
 	pt.peerTaskManager.PeerTaskDone(pt.taskID)
 	var end = time.Now()
 	pt.Log().Errorf("peer task failed, code: %d, reason: %s", pt.failedCode, pt.failedReason)
@@ -1625,7 +1909,9 @@ func (pt *peerTaskConductor) fail() {
 			SourceError: sourceError,
 		}
 	}
+	reportStart := time.Now()
 	err = pt.schedulerClient.ReportPeerResult(peerResultCtx, peerResult)
+	recordSchedulerRequestMetrics(metrics.SchedulerRequestMethodReportPeerResult, reportStart, err)
 	if err != nil {
 		peerResultSpan.RecordError(err)
 		pt.Log().Errorf("step 3: report fail peer result, error: %v", err)
@@ -1683,6 +1969,13 @@ func (pt *peerTaskConductor) PublishPieceInfo(pieceNum int32, size uint32) {
 	pt.readyPieces.Set(pieceNum)
 	pt.completedLength.Add(int64(size))
 	pt.readyPiecesLock.Unlock()
+	pt.timing.pieceDownloaded()
+
+	if err := pt.verifyDigest(); err != nil {
+		pt.Errorf("verify digest error: %s", err)
+		pt.cancel(commonv1.Code_ClientError, err.Error())
+		return
+	}
 
 	finished := pt.isCompleted()
 	if finished {
@@ -1695,6 +1988,32 @@ func (pt *peerTaskConductor) PublishPieceInfo(pieceNum int32, size uint32) {
 		})
 }
 
+// verifyDigest verifies the digest of url meta with the pieces downloaded in order.
+func (pt *peerTaskConductor) verifyDigest() error {
+	if pt.digestVerifier == nil {
+		return nil
+	}
+
+	return pt.digestVerifier.verify(
+		pt.GetTotalPieces(),
+		func(num int32) bool {
+			pt.readyPiecesLock.RLock()
+			defer pt.readyPiecesLock.RUnlock()
+			return pt.readyPieces.IsSet(num)
+		},
+		func(num int32) (io.Reader, io.Closer, error) {
+			return pt.GetStorage().ReadPiece(pt.ctx, &storage.ReadPieceRequest{
+				PeerTaskMetadata: storage.PeerTaskMetadata{
+					PeerID: pt.peerID,
+					TaskID: pt.taskID,
+				},
+				PieceMetadata: storage.PieceMetadata{
+					Num: num,
+				},
+			})
+		})
+}
+
 func (pt *peerTaskConductor) sendPieceResult(pr *schedulerv1.PieceResult) error {
 	pt.sendPieceResultLock.Lock()
 	err := pt.peerPacketStream.Send(pr)
@@ -0,0 +1,164 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// defaultDownloadPieceWorkerCount is the worker count when scheduler does not set parallel count
+	defaultDownloadPieceWorkerCount = 4
+
+	// destPeerRateSmoothingFactor is the weight of latest observed rate in the moving average
+	destPeerRateSmoothingFactor = 0.2
+
+	// destPeerSlowRateRatio is the ratio of average rate, below which a piece download is treated as slow
+	destPeerSlowRateRatio = 0.5
+)
+
+// destPeerLimiter limits the concurrent piece downloads from one destination peer,
+// so a slow or overloaded parent can not occupy all download piece workers.
+// The limit grows additively when pieces are downloaded in time and halves
+// when a piece failed or is much slower than the learned rate.
+type destPeerLimiter struct {
+	mu       sync.Mutex
+	inflight int
+	limit    int
+	maxLimit int
+	// rate is the moving average of download rate in bytes per second
+	rate float64
+	// released is closed and replaced when a slot is returned
+	released chan struct{}
+}
+
+func newDestPeerLimiter(maxLimit int) *destPeerLimiter {
+	if maxLimit < 1 {
+		maxLimit = 1
+	}
+	return &destPeerLimiter{
+		limit:    maxLimit,
+		maxLimit: maxLimit,
+		released: make(chan struct{}),
+	}
+}
+
+// destPeerLimit returns the max concurrent piece downloads for one destination peer,
+// keep at least one worker available for other peers.
+func destPeerLimit(workerCount int32) int {
+	if workerCount < 1 {
+		workerCount = defaultDownloadPieceWorkerCount
+	}
+	if workerCount == 1 {
+		return 1
+	}
+	return int(workerCount - 1)
+}
+
+// tryAcquire takes a slot when the limit is not reached, otherwise it returns
+// a channel which will be closed when any slot is returned.
+func (l *destPeerLimiter) tryAcquire() (bool, <-chan struct{}) {
+	if l == nil {
+		return true, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.inflight < l.limit {
+		l.inflight++
+		return true, nil
+	}
+	return false, l.released
+}
+
+// cancel returns a slot without observation, used when the piece is skipped.
+func (l *destPeerLimiter) cancel() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+}
+
+// release returns a slot and adjusts the limit with the observed piece download.
+func (l *destPeerLimiter) release(size int64, cost time.Duration, success bool) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.releaseLocked()
+
+	if !success {
+		l.decreaseLocked()
+		return
+	}
+
+	if size <= 0 || cost <= 0 {
+		return
+	}
+
+	rate := float64(size) / cost.Seconds()
+	if l.rate == 0 {
+		l.rate = rate
+		return
+	}
+
+	slow := rate < l.rate*destPeerSlowRateRatio
+	l.rate = l.rate*(1-destPeerRateSmoothingFactor) + rate*destPeerRateSmoothingFactor
+	if slow {
+		l.decreaseLocked()
+		return
+	}
+
+	if l.limit < l.maxLimit {
+		l.limit++
+	}
+}
+
+// Limit returns the current concurrent limit.
+func (l *destPeerLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Rate returns the learned download rate in bytes per second.
+func (l *destPeerLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+func (l *destPeerLimiter) decreaseLocked() {
+	l.limit /= 2
+	if l.limit < 1 {
+		l.limit = 1
+	}
+}
+
+func (l *destPeerLimiter) releaseLocked() {
+	if l.inflight > 0 {
+		l.inflight--
+	}
+	close(l.released)
+	l.released = make(chan struct{})
+}
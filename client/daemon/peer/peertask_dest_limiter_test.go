@@ -0,0 +1,121 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestDestPeerLimit(t *testing.T) {
+	assert := testifyassert.New(t)
+	assert.Equal(3, destPeerLimit(0))
+	assert.Equal(1, destPeerLimit(1))
+	assert.Equal(1, destPeerLimit(2))
+	assert.Equal(15, destPeerLimit(16))
+}
+
+func TestDestPeerLimiter_Acquire(t *testing.T) {
+	assert := testifyassert.New(t)
+	l := newDestPeerLimiter(2)
+
+	ok, _ := l.tryAcquire()
+	assert.True(ok)
+	ok, _ = l.tryAcquire()
+	assert.True(ok)
+	ok, released := l.tryAcquire()
+	assert.False(ok)
+
+	l.cancel()
+	select {
+	case <-released:
+	case <-time.After(time.Second):
+		t.Fatal("released channel is not closed")
+	}
+	ok, _ = l.tryAcquire()
+	assert.True(ok)
+
+	// nil limiter never blocks
+	var nilLimiter *destPeerLimiter
+	ok, _ = nilLimiter.tryAcquire()
+	assert.True(ok)
+	nilLimiter.cancel()
+	nilLimiter.release(1, time.Second, true)
+}
+
+func TestDestPeerLimiter_Adjust(t *testing.T) {
+	testCases := []struct {
+		name   string
+		expect func(t *testing.T, l *destPeerLimiter)
+	}{
+		{
+			name: "decrease limit when piece failed",
+			expect: func(t *testing.T, l *destPeerLimiter) {
+				assert := testifyassert.New(t)
+				l.tryAcquire()
+				l.release(0, 0, false)
+				assert.Equal(4, l.Limit())
+				l.tryAcquire()
+				l.release(0, 0, false)
+				assert.Equal(2, l.Limit())
+				for i := 0; i < 3; i++ {
+					l.tryAcquire()
+					l.release(0, 0, false)
+				}
+				assert.Equal(1, l.Limit())
+			},
+		},
+		{
+			name: "decrease limit when piece is slow",
+			expect: func(t *testing.T, l *destPeerLimiter) {
+				assert := testifyassert.New(t)
+				l.tryAcquire()
+				l.release(1024, time.Second, true)
+				assert.Equal(float64(1024), l.Rate())
+				assert.Equal(8, l.Limit())
+
+				l.tryAcquire()
+				l.release(1024, 4*time.Second, true)
+				assert.Equal(4, l.Limit())
+				assert.InDelta(1024*0.8+256*0.2, l.Rate(), 0.001)
+			},
+		},
+		{
+			name: "increase limit when piece is in time",
+			expect: func(t *testing.T, l *destPeerLimiter) {
+				assert := testifyassert.New(t)
+				l.tryAcquire()
+				l.release(0, 0, false)
+				assert.Equal(4, l.Limit())
+
+				for i := 0; i < 10; i++ {
+					l.tryAcquire()
+					l.release(1024, time.Second, true)
+				}
+				assert.Equal(8, l.Limit())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, newDestPeerLimiter(8))
+		})
+	}
+}
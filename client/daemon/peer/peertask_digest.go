@@ -0,0 +1,192 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+// digestVerifier computes the digest of content incrementally as pieces complete in order,
+// and compares each range against the range digests, so corrupted content fails
+// the peer task as soon as the range is downloaded rather than after the whole content.
+type digestVerifier struct {
+	// mu guards the verifier, pieces are fed by concurrent piece download workers.
+	mu sync.Mutex
+
+	// expected is the expected digest layout.
+	expected *digest.RangeDigest
+
+	// contentHash hashes the whole content.
+	contentHash hash.Hash
+
+	// rangeHash hashes the current range.
+	rangeHash hash.Hash
+
+	// rangeNum is the number of the current range.
+	rangeNum int
+
+	// rangeLength is the length of content written to the current range.
+	rangeLength int64
+
+	// nextPieceNum is the number of the next piece to be verified.
+	nextPieceNum int32
+
+	// done is true when the whole content is verified or verification failed.
+	done bool
+
+	// verified is true when the whole content is verified.
+	verified bool
+}
+
+// newDigestVerifier returns a new digestVerifier with the digest of url meta.
+func newDigestVerifier(d string) (*digestVerifier, error) {
+	expected, err := digest.ParseRange(d)
+	if err != nil {
+		return nil, err
+	}
+
+	contentHash, err := digest.HashFromAlgorithm(expected.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeHash, _ := digest.HashFromAlgorithm(expected.Algorithm)
+	return &digestVerifier{
+		expected:    expected,
+		contentHash: contentHash,
+		rangeHash:   rangeHash,
+	}, nil
+}
+
+// Write writes the content in order and verifies the ranges which are completed.
+func (v *digestVerifier) Write(p []byte) (int, error) {
+	v.contentHash.Write(p)
+	if len(v.expected.Ranges) == 0 {
+		return len(p), nil
+	}
+
+	var written int
+	for written < len(p) {
+		n := len(p) - written
+		if left := v.expected.RangeSize - v.rangeLength; int64(n) > left {
+			n = int(left)
+		}
+
+		v.rangeHash.Write(p[written : written+n])
+		v.rangeLength += int64(n)
+		written += n
+
+		if v.rangeLength == v.expected.RangeSize {
+			if err := v.verifyRange(); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// verifyRange verifies the current range and moves to the next range.
+func (v *digestVerifier) verifyRange() error {
+	if v.rangeNum >= len(v.expected.Ranges) {
+		return fmt.Errorf("%w, content exceeds %d ranges", storage.ErrInvalidDigest, len(v.expected.Ranges))
+	}
+
+	if encoded := hex.EncodeToString(v.rangeHash.Sum(nil)); encoded != v.expected.Ranges[v.rangeNum] {
+		return fmt.Errorf("%w, range %d desired: %s, actual: %s", storage.ErrInvalidDigest, v.rangeNum, v.expected.Ranges[v.rangeNum], encoded)
+	}
+
+	v.rangeHash.Reset()
+	v.rangeLength = 0
+	v.rangeNum++
+	return nil
+}
+
+// finish verifies the last range and the whole content.
+func (v *digestVerifier) finish() error {
+	if len(v.expected.Ranges) > 0 {
+		if v.rangeLength > 0 {
+			if err := v.verifyRange(); err != nil {
+				return err
+			}
+		}
+
+		if v.rangeNum != len(v.expected.Ranges) {
+			return fmt.Errorf("%w, desired %d ranges, actual: %d", storage.ErrInvalidDigest, len(v.expected.Ranges), v.rangeNum)
+		}
+	}
+
+	if encoded := hex.EncodeToString(v.contentHash.Sum(nil)); encoded != v.expected.Encoded {
+		return fmt.Errorf("%w, desired: %s, actual: %s", storage.ErrInvalidDigest, v.expected.Encoded, encoded)
+	}
+
+	return nil
+}
+
+// verify feeds the ready pieces to the verifier in order, readPiece reads the content of piece
+// and isReady reports whether the piece is downloaded. When all pieces are fed, the whole content
+// is verified, totalPieces is negative when the total pieces is unknown.
+// The verifier stops after the first error, as the content hashed is incomplete.
+func (v *digestVerifier) verify(totalPieces int32, isReady func(int32) bool, readPiece func(int32) (io.Reader, io.Closer, error)) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.done {
+		return nil
+	}
+
+	for ; isReady(v.nextPieceNum); v.nextPieceNum++ {
+		r, c, err := readPiece(v.nextPieceNum)
+		if err != nil {
+			v.done = true
+			return err
+		}
+
+		_, err = io.Copy(v, r)
+		c.Close()
+		if err != nil {
+			v.done = true
+			return err
+		}
+	}
+
+	if totalPieces >= 0 && v.nextPieceNum >= totalPieces {
+		v.done = true
+		if err := v.finish(); err != nil {
+			return err
+		}
+
+		v.verified = true
+	}
+
+	return nil
+}
+
+// isVerified returns whether the whole content is verified.
+func (v *digestVerifier) isVerified() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return v.verified
+}
@@ -0,0 +1,194 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// rangeDigestOf returns the digest of content with range digests of rangeSize.
+func rangeDigestOf(content []byte, rangeSize int) string {
+	var ranges []string
+	for start := 0; start < len(content); start += rangeSize {
+		end := start + rangeSize
+		if end > len(content) {
+			end = len(content)
+		}
+		ranges = append(ranges, sha256Hex(content[start:end]))
+	}
+
+	return fmt.Sprintf("sha256:%s;%d:%s", sha256Hex(content), rangeSize, strings.Join(ranges, ","))
+}
+
+func TestDigestVerifier(t *testing.T) {
+	content := []byte("0123456789abcdefghijklmnopqrstuvwxyz")
+	corrupted := append([]byte{}, content...)
+	corrupted[1] = 'x'
+	pieceSize := 4
+	totalPieces := int32((len(content) + pieceSize - 1) / pieceSize)
+
+	piecesOf := func(data []byte) func(int32) (io.Reader, io.Closer, error) {
+		return func(num int32) (io.Reader, io.Closer, error) {
+			start := int(num) * pieceSize
+			end := start + pieceSize
+			if end > len(data) {
+				end = len(data)
+			}
+			return bytes.NewReader(data[start:end]), io.NopCloser(nil), nil
+		}
+	}
+
+	tests := []struct {
+		name   string
+		digest string
+		data   []byte
+		expect func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error))
+	}{
+		{
+			name:   "verify content with ranges",
+			digest: rangeDigestOf(content, 10),
+			data:   content,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				// pieces arrive out of order, only the in-order prefix is verified
+				ready := map[int32]bool{0: true, 2: true}
+				isReady := func(num int32) bool { return ready[num] }
+				assert.NoError(v.verify(totalPieces, isReady, readPiece))
+				assert.Equal(int32(1), v.nextPieceNum)
+
+				for i := int32(0); i < totalPieces; i++ {
+					ready[i] = true
+				}
+				assert.NoError(v.verify(totalPieces, isReady, readPiece))
+				assert.Equal(totalPieces, v.nextPieceNum)
+				assert.Equal(4, v.rangeNum)
+				assert.True(v.done)
+			},
+		},
+		{
+			name:   "fail fast with corrupted range",
+			digest: rangeDigestOf(content, 10),
+			data:   corrupted,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				ready := map[int32]bool{0: true, 1: true, 2: true}
+				err := v.verify(totalPieces, func(num int32) bool { return ready[num] }, readPiece)
+				assert.ErrorIs(err, storage.ErrInvalidDigest)
+				assert.Contains(err.Error(), "range 0")
+
+				// verifier stops after the first error
+				assert.NoError(v.verify(totalPieces, func(num int32) bool { return num < totalPieces }, readPiece))
+			},
+		},
+		{
+			name:   "verify content without ranges",
+			digest: "sha256:" + sha256Hex(content),
+			data:   content,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				assert.NoError(v.verify(totalPieces, func(num int32) bool { return num < totalPieces }, readPiece))
+				assert.True(v.done)
+			},
+		},
+		{
+			name:   "corrupted content without ranges",
+			digest: "sha256:" + sha256Hex(content),
+			data:   corrupted,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				// corrupted content is detected after all pieces are verified
+				assert.NoError(v.verify(totalPieces, func(num int32) bool { return num < totalPieces-1 }, readPiece))
+				assert.ErrorIs(v.verify(totalPieces, func(num int32) bool { return num < totalPieces }, readPiece), storage.ErrInvalidDigest)
+			},
+		},
+		{
+			name:   "content length mismatches ranges",
+			digest: rangeDigestOf(append(append([]byte{}, content...), 'z'), 10),
+			data:   content,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(v.verify(totalPieces, func(num int32) bool { return num < totalPieces }, readPiece), storage.ErrInvalidDigest)
+			},
+		},
+		{
+			name:   "total pieces unknown",
+			digest: "sha256:" + sha256Hex(content),
+			data:   content,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				isReady := func(num int32) bool { return num < totalPieces }
+				assert.NoError(v.verify(-1, isReady, readPiece))
+				assert.False(v.done)
+				assert.NoError(v.verify(totalPieces, isReady, readPiece))
+				assert.True(v.done)
+			},
+		},
+		{
+			name:   "read piece failed",
+			digest: "sha256:" + sha256Hex(content),
+			data:   content,
+			expect: func(t *testing.T, v *digestVerifier, readPiece func(int32) (io.Reader, io.Closer, error)) {
+				assert := testifyassert.New(t)
+				err := v.verify(totalPieces, func(num int32) bool { return num < totalPieces }, func(int32) (io.Reader, io.Closer, error) {
+					return nil, nil, errors.New("foo")
+				})
+				assert.EqualError(err, "foo")
+				assert.True(v.done)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := newDigestVerifier(tc.digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, v, piecesOf(tc.data))
+		})
+	}
+}
+
+func TestNewDigestVerifier(t *testing.T) {
+	assert := testifyassert.New(t)
+	_, err := newDigestVerifier("foo:bar")
+	assert.Error(err)
+
+	_, err = newDigestVerifier("sha256:foo;bar")
+	assert.Error(err)
+
+	v, err := newDigestVerifier("md5:foo")
+	assert.NoError(err)
+	assert.Empty(v.expected.Ranges)
+}
@@ -20,12 +20,15 @@ import (
 	"context"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/internal/dferrors"
 	"d7y.io/dragonfly/v2/pkg/dfnet"
+	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
 )
 
 // when scheduler is not available, use dummySchedulerClient to back source
@@ -56,6 +59,10 @@ func (d *dummySchedulerClient) AnnounceTask(ctx context.Context, request *schedu
 	panic("should not call this function")
 }
 
+func (d *dummySchedulerClient) WatchPeerETA(ctx context.Context, target *schedulerv1.PeerTarget, option ...grpc.CallOption) (schedulerclient.PeerETAStream, error) {
+	return nil, status.Error(codes.Unimplemented, "estimator is not available")
+}
+
 func (d *dummySchedulerClient) Close() error {
 	return nil
 }
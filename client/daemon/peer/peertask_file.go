@@ -19,6 +19,7 @@ package peer
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
@@ -42,6 +43,28 @@ type FileTaskRequest struct {
 	Callsystem         string
 	Range              *util.Range
 	KeepOriginalOffset bool
+	// InPlace indicates to write the range into the existing output file at original offset instead of hardlink,
+	// it works with KeepOriginalOffset
+	InPlace bool
+	// Resume indicates to continue the interrupted peer task with the pieces in local storage
+	Resume bool
+	// Sync indicates to sync the output file and its directory to disk after stored
+	Sync bool
+	// Uid and Gid indicate the owner of the output file, zero keeps the owner unchanged
+	Uid int64
+	Gid int64
+	// Mode indicates the permissions of the output file, zero keeps the permissions unchanged
+	Mode os.FileMode
+}
+
+// outputOption returns the option of storing the output file.
+func (r *FileTaskRequest) outputOption() storage.OutputOption {
+	return storage.OutputOption{
+		Sync: r.Sync,
+		Uid:  r.Uid,
+		Gid:  r.Gid,
+		Mode: r.Mode,
+	}
 }
 
 // FileTask represents a peer task to download a file
@@ -96,6 +119,14 @@ func (ptm *peerTaskManager) newFileTask(
 	}
 
 	taskID := idgen.TaskID(request.Url, request.UrlMeta)
+	// reuse the peer id of the interrupted peer task, the persisted pieces will be restored from storage
+	if request.Resume && request.Range == nil {
+		if resumable := ptm.storageManager.FindResumableTask(taskID); resumable != nil {
+			logger.Infof("resume peer task %s/%s", taskID, resumable.PeerID)
+			request.PeerId = resumable.PeerID
+		}
+	}
+
 	ptc, err := ptm.getPeerTaskConductor(ctx, taskID, &request.PeerTaskRequest, limit, parent, request.Range, request.Output, false)
 	if err != nil {
 		return nil, nil, err
@@ -107,7 +138,7 @@ func (ptm *peerTaskManager) newFileTask(
 		ctx:                 ctx,
 		span:                span,
 		peerTaskConductor:   ptc,
-		pieceCh:             ptc.broker.Subscribe(),
+		pieceCh:             ptc.subscribe(),
 		request:             request,
 
 		progressCh:        make(chan *FileTaskProgress),
@@ -126,6 +157,7 @@ func (f *fileTask) Start(ctx context.Context) (chan *FileTaskProgress, error) {
 
 func (f *fileTask) syncProgress() {
 	defer f.span.End()
+	defer f.peerTaskConductor.unsubscribe(f.pieceCh)
 	for {
 		select {
 		case <-f.peerTaskConductor.successCh:
@@ -136,6 +168,8 @@ func (f *fileTask) syncProgress() {
 			f.sendFailProgress(f.peerTaskConductor.failedCode, f.peerTaskConductor.failedReason)
 			return
 		case <-f.ctx.Done():
+			f.Warnf("sync progress stopped, file task context done due to %s", f.ctx.Err())
+			return
 		case piece := <-f.pieceCh:
 			if piece.Finished {
 				continue
@@ -177,6 +211,8 @@ func (f *fileTask) storeToOutput() {
 			MetadataOnly:   false,
 			TotalPieces:    f.peerTaskConductor.GetTotalPieces(),
 			OriginalOffset: f.request.KeepOriginalOffset,
+			InPlace:        f.request.InPlace,
+			OutputOption:   f.request.outputOption(),
 		})
 	if err != nil {
 		f.sendFailProgress(commonv1.Code_ClientError, err.Error())
@@ -23,6 +23,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -51,17 +54,34 @@ type TaskManager interface {
 	// tiny stands task file is tiny and task is done
 	StartFileTask(ctx context.Context, req *FileTaskRequest) (
 		progress chan *FileTaskProgress, tiny *TinyData, err error)
-	// StartStreamTask starts a peer task with stream io
+	// StartStreamTask starts a peer task with stream io,
+	// the returned reader delivers bytes in piece order as soon as the pieces arrive,
+	// so the content can be piped to other writers without waiting for the whole task done
 	StartStreamTask(ctx context.Context, req *StreamTaskRequest) (
 		readCloser io.ReadCloser, attribute map[string]string, err error)
 	// StartSeedTask starts a seed peer task
 	StartSeedTask(ctx context.Context, req *SeedTaskRequest) (
 		seedTaskResult *SeedTaskResponse, reuse bool, err error)
 
+	// StartPrefetchTask starts a peer task to warm the task into local storage without output,
+	// reuse is true when the task is already completed in local storage
+	StartPrefetchTask(ctx context.Context, req *PrefetchTaskRequest) (
+		subscribeResponse *SubscribeResponse, reuse bool, err error)
+
 	Subscribe(request *commonv1.PieceTaskRequest) (*SubscribeResponse, bool)
 
 	IsPeerTaskRunning(taskID string) (Task, bool)
 
+	// CancelTask cancels the running peer task and reclaims its storage,
+	// unless force is set, the peer task shared by other local requests is not canceled
+	CancelTask(ctx context.Context, taskID string, force bool) error
+
+	// GetTaskTiming returns the timing summary of a running or recently finished peer task
+	GetTaskTiming(taskID string) (*TaskTiming, bool)
+
+	// GetRunningTasks returns the progress of all running peer tasks
+	GetRunningTasks() []*TaskStatus
+
 	// StatTask checks whether the given task exists in P2P network
 	StatTask(ctx context.Context, taskID string) (*schedulerv1.Task, error)
 
@@ -141,6 +161,18 @@ type peerTaskManager struct {
 	calculateDigest bool
 
 	getPiecesMaxRetry int
+
+	// peerExchange finds neighbors which finished the task when scheduler is unreachable
+	peerExchange PeerExchange
+
+	// hostLoad samples host load which is reported with piece results
+	hostLoad *hostLoadCollector
+
+	// taskTimings keeps timing summaries of recently finished peer tasks
+	taskTimings *taskTimingHistory
+
+	// localTransport connects the parents on the same host by unix sockets
+	localTransport *LocalTransport
 }
 
 func NewPeerTaskManager(
@@ -154,7 +186,9 @@ func NewPeerTaskManager(
 	prefetch bool,
 	calculateDigest bool,
 	getPiecesMaxRetry int,
-	watchdog time.Duration) (TaskManager, error) {
+	watchdog time.Duration,
+	peerExchange PeerExchange,
+	localTransport *LocalTransport) (TaskManager, error) {
 
 	ptm := &peerTaskManager{
 		host:              host,
@@ -170,6 +204,10 @@ func NewPeerTaskManager(
 		watchdogTimeout:   watchdog,
 		calculateDigest:   calculateDigest,
 		getPiecesMaxRetry: getPiecesMaxRetry,
+		peerExchange:      peerExchange,
+		hostLoad:          newHostLoadCollector(),
+		taskTimings:       newTaskTimingHistory(maxTaskTimingHistory),
+		localTransport:    localTransport,
 	}
 	return ptm, nil
 }
@@ -326,7 +364,7 @@ func (ptm *peerTaskManager) StartStreamTask(ctx context.Context, req *StreamTask
 		UrlMeta:     req.URLMeta,
 		PeerId:      req.PeerID,
 		PeerHost:    ptm.host,
-		HostLoad:    nil,
+		HostLoad:    ptm.hostLoad.Load(),
 		IsMigrating: false,
 		Pattern:     req.Pattern,
 	}
@@ -343,6 +381,8 @@ func (ptm *peerTaskManager) StartStreamTask(ctx context.Context, req *StreamTask
 	if err != nil {
 		return nil, nil, err
 	}
+	// only the request starting the peer task cancels it, the shared peer task may be started by prefetching or seeding
+	pt.cancelOnDisconnect = req.CancelOnDisconnect && pt.peerTaskConductor.peerID == req.PeerID
 
 	// FIXME when failed due to schedulerClient error, relocate schedulerClient and retry
 	readCloser, attribute, err := pt.Start(ctx)
@@ -356,6 +396,13 @@ func (ptm *peerTaskManager) StartSeedTask(ctx context.Context, req *SeedTaskRequ
 		return response, true, nil
 	}
 
+	// the same content may be seeded with a different url
+	response, ok = ptm.tryReuseDigestSeedPeerTask(ctx, req)
+	if ok {
+		metrics.PeerTaskCacheHitCount.Add(1)
+		return response, true, nil
+	}
+
 	var limit = rate.Inf
 	if ptm.perPeerRateLimit > 0 {
 		limit = ptm.perPeerRateLimit
@@ -406,6 +453,28 @@ func (ptm *peerTaskManager) PeerTaskDone(taskID string) {
 	ptm.runningPeerTasks.Delete(taskID)
 }
 
+// GetTaskTiming returns the timing summary of a running or recently finished peer task.
+func (ptm *peerTaskManager) GetTaskTiming(taskID string) (*TaskTiming, bool) {
+	if ptc, ok := ptm.findPeerTaskConductor(taskID); ok {
+		return ptc.timing.snapshot(), true
+	}
+
+	return ptm.taskTimings.load(taskID)
+}
+
+func (ptm *peerTaskManager) GetRunningTasks() []*TaskStatus {
+	var tasks []*TaskStatus
+	ptm.runningPeerTasks.Range(func(_, value any) bool {
+		tasks = append(tasks, value.(*peerTaskConductor).status())
+		return true
+	})
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartTime.Before(tasks[j].StartTime)
+	})
+	return tasks
+}
+
 func (ptm *peerTaskManager) IsPeerTaskRunning(taskID string) (Task, bool) {
 	ptc, ok := ptm.runningPeerTasks.Load(taskID)
 	if ok {
@@ -447,7 +516,7 @@ func (ptm *peerTaskManager) AnnouncePeerTask(ctx context.Context, meta storage.P
 	if err != nil {
 		return err
 	}
-	piecePacket.DstAddr = fmt.Sprintf("%s:%d", ptm.host.Ip, ptm.host.DownPort)
+	piecePacket.DstAddr = net.JoinHostPort(ptm.host.Ip, strconv.Itoa(int(ptm.host.DownPort)))
 
 	// Announce peer task to scheduler
 	if err := ptm.schedulerClient.AnnounceTask(ctx, &schedulerv1.AnnounceTaskRequest{
@@ -54,6 +54,20 @@ func (mr *MockTaskManagerMockRecorder) AnnouncePeerTask(ctx, meta, url, taskType
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnouncePeerTask", reflect.TypeOf((*MockTaskManager)(nil).AnnouncePeerTask), ctx, meta, url, taskType, urlMeta)
 }
 
+// CancelTask mocks base method.
+func (m *MockTaskManager) CancelTask(ctx context.Context, taskID string, force bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelTask", ctx, taskID, force)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelTask indicates an expected call of CancelTask.
+func (mr *MockTaskManagerMockRecorder) CancelTask(ctx, taskID, force interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockTaskManager)(nil).CancelTask), ctx, taskID, force)
+}
+
 // GetPieceManager mocks base method.
 func (m *MockTaskManager) GetPieceManager() PieceManager {
 	m.ctrl.T.Helper()
@@ -68,6 +82,35 @@ func (mr *MockTaskManagerMockRecorder) GetPieceManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPieceManager", reflect.TypeOf((*MockTaskManager)(nil).GetPieceManager))
 }
 
+// GetRunningTasks mocks base method.
+func (m *MockTaskManager) GetRunningTasks() []*TaskStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunningTasks")
+	ret0, _ := ret[0].([]*TaskStatus)
+	return ret0
+}
+
+// GetRunningTasks indicates an expected call of GetRunningTasks.
+func (mr *MockTaskManagerMockRecorder) GetRunningTasks() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunningTasks", reflect.TypeOf((*MockTaskManager)(nil).GetRunningTasks))
+}
+
+// GetTaskTiming mocks base method.
+func (m *MockTaskManager) GetTaskTiming(taskID string) (*TaskTiming, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskTiming", taskID)
+	ret0, _ := ret[0].(*TaskTiming)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetTaskTiming indicates an expected call of GetTaskTiming.
+func (mr *MockTaskManagerMockRecorder) GetTaskTiming(taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskTiming", reflect.TypeOf((*MockTaskManager)(nil).GetTaskTiming), taskID)
+}
+
 // IsPeerTaskRunning mocks base method.
 func (m *MockTaskManager) IsPeerTaskRunning(taskID string) (Task, bool) {
 	m.ctrl.T.Helper()
@@ -99,6 +142,22 @@ func (mr *MockTaskManagerMockRecorder) StartFileTask(ctx, req interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartFileTask", reflect.TypeOf((*MockTaskManager)(nil).StartFileTask), ctx, req)
 }
 
+// StartPrefetchTask mocks base method.
+func (m *MockTaskManager) StartPrefetchTask(ctx context.Context, req *PrefetchTaskRequest) (*SubscribeResponse, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartPrefetchTask", ctx, req)
+	ret0, _ := ret[0].(*SubscribeResponse)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StartPrefetchTask indicates an expected call of StartPrefetchTask.
+func (mr *MockTaskManagerMockRecorder) StartPrefetchTask(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPrefetchTask", reflect.TypeOf((*MockTaskManager)(nil).StartPrefetchTask), ctx, req)
+}
+
 // StartSeedTask mocks base method.
 func (m *MockTaskManager) StartSeedTask(ctx context.Context, req *SeedTaskRequest) (*SeedTaskResponse, bool, error) {
 	m.ctrl.T.Helper()
@@ -262,6 +262,7 @@ func setupPeerTaskManagerComponents(ctrl *gomock.Controller, opt componentsOptio
 		func(ctx context.Context, pr *schedulerv1.PeerResult, opts ...grpc.CallOption) error {
 			return nil
 		})
+	sched.EXPECT().WatchPeerETA(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, status.Error(codes.Unimplemented, "estimator is not available"))
 	tempDir, _ := os.MkdirTemp("", "d7y-test-*")
 	storageManager, _ := storage.NewStorageManager(
 		config.SimpleLocalTaskStoreStrategy,
@@ -0,0 +1,52 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"strconv"
+	"time"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+// recordPieceMetrics records the metrics of piece result,
+// the piece without parent is downloaded from source.
+func recordPieceMetrics(request *DownloadPieceRequest, result *DownloadPieceResult, success bool) {
+	downloadType := metrics.PieceDownloadTypeP2P
+	if request.DstPid == "" {
+		downloadType = metrics.PieceDownloadTypeBackToSource
+	}
+
+	metrics.PieceDownloadCount.WithLabelValues(downloadType, strconv.FormatBool(success)).Add(1)
+	if result == nil {
+		return
+	}
+
+	if result.FinishTime > result.BeginTime {
+		cost := time.Duration(result.FinishTime - result.BeginTime)
+		metrics.PieceDownloadDuration.WithLabelValues(downloadType, strconv.FormatBool(success)).Observe(float64(cost.Milliseconds()))
+	}
+
+	if success && result.Size > 0 {
+		metrics.PieceDownloadTraffic.WithLabelValues(downloadType).Add(float64(result.Size))
+	}
+}
+
+// recordSchedulerRequestMetrics records the round trip time of request to scheduler.
+func recordSchedulerRequestMetrics(method string, start time.Time, err error) {
+	metrics.SchedulerRequestDuration.WithLabelValues(method, strconv.FormatBool(err == nil)).Observe(float64(time.Since(start).Milliseconds()))
+}
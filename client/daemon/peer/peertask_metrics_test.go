@@ -0,0 +1,80 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+func histogramSampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	metric := &dto.Metric{}
+	if err := observer.(prometheus.Histogram).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestRecordPieceMetrics(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	p2pSuccess := testutil.ToFloat64(metrics.PieceDownloadCount.WithLabelValues(metrics.PieceDownloadTypeP2P, "true"))
+	p2pTraffic := testutil.ToFloat64(metrics.PieceDownloadTraffic.WithLabelValues(metrics.PieceDownloadTypeP2P))
+	sourceFailed := testutil.ToFloat64(metrics.PieceDownloadCount.WithLabelValues(metrics.PieceDownloadTypeBackToSource, "false"))
+	sourceTraffic := testutil.ToFloat64(metrics.PieceDownloadTraffic.WithLabelValues(metrics.PieceDownloadTypeBackToSource))
+	p2pDuration := histogramSampleCount(t, metrics.PieceDownloadDuration.WithLabelValues(metrics.PieceDownloadTypeP2P, "true"))
+
+	now := time.Now().UnixNano()
+	recordPieceMetrics(&DownloadPieceRequest{DstPid: "parent"}, &DownloadPieceResult{
+		Size:       1024,
+		BeginTime:  now - int64(10*time.Millisecond),
+		FinishTime: now,
+	}, true)
+	recordPieceMetrics(&DownloadPieceRequest{}, &DownloadPieceResult{
+		Size:       512,
+		BeginTime:  now,
+		FinishTime: now,
+	}, false)
+	recordPieceMetrics(&DownloadPieceRequest{}, nil, false)
+
+	assert.Equal(p2pSuccess+1, testutil.ToFloat64(metrics.PieceDownloadCount.WithLabelValues(metrics.PieceDownloadTypeP2P, "true")))
+	assert.Equal(p2pTraffic+1024, testutil.ToFloat64(metrics.PieceDownloadTraffic.WithLabelValues(metrics.PieceDownloadTypeP2P)))
+	assert.Equal(sourceFailed+2, testutil.ToFloat64(metrics.PieceDownloadCount.WithLabelValues(metrics.PieceDownloadTypeBackToSource, "false")))
+	assert.Equal(sourceTraffic, testutil.ToFloat64(metrics.PieceDownloadTraffic.WithLabelValues(metrics.PieceDownloadTypeBackToSource)))
+	assert.Equal(p2pDuration+1, histogramSampleCount(t, metrics.PieceDownloadDuration.WithLabelValues(metrics.PieceDownloadTypeP2P, "true")))
+}
+
+func TestRecordSchedulerRequestMetrics(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	registered := histogramSampleCount(t, metrics.SchedulerRequestDuration.WithLabelValues(metrics.SchedulerRequestMethodRegisterPeerTask, "true"))
+	reportFailed := histogramSampleCount(t, metrics.SchedulerRequestDuration.WithLabelValues(metrics.SchedulerRequestMethodReportPeerResult, "false"))
+
+	recordSchedulerRequestMetrics(metrics.SchedulerRequestMethodRegisterPeerTask, time.Now(), nil)
+	recordSchedulerRequestMetrics(metrics.SchedulerRequestMethodReportPeerResult, time.Now(), errors.New("foo"))
+	assert.Equal(registered+1, histogramSampleCount(t, metrics.SchedulerRequestDuration.WithLabelValues(metrics.SchedulerRequestMethodRegisterPeerTask, "true")))
+	assert.Equal(reportFailed+1, histogramSampleCount(t, metrics.SchedulerRequestDuration.WithLabelValues(metrics.SchedulerRequestMethodReportPeerResult, "false")))
+}
@@ -0,0 +1,148 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+// maxMigrateCount is the max count of migrating to other schedulers in a peer task.
+const maxMigrateCount = 3
+
+// isSchedulerUnavailable returns whether the error is caused by the broken scheduler connection.
+func isSchedulerUnavailable(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.Unavailable
+}
+
+// tryMigrate migrates the running peer task to another scheduler when the scheduler connection is broken,
+// returns true when the peer task can continue receiving peer packets from the new scheduler.
+func (pt *peerTaskConductor) tryMigrate(err error) bool {
+	if !isSchedulerUnavailable(err) {
+		return false
+	}
+
+	select {
+	case <-pt.successCh:
+		return false
+	case <-pt.failCh:
+		return false
+	default:
+	}
+
+	if pt.migratedCount >= maxMigrateCount {
+		pt.Warnf("scheduler connection is broken, but migrated %d times, stop migrating", pt.migratedCount)
+		return false
+	}
+	pt.migratedCount++
+
+	pt.Warnf("scheduler connection is broken: %s, migrate to another scheduler", err)
+	if err := pt.migrate(); err != nil {
+		pt.Errorf("migrate to another scheduler error: %s", err)
+		metrics.PeerTaskMigrateCount.WithLabelValues(strconv.FormatBool(false)).Add(1)
+		return false
+	}
+
+	pt.Infof("migrate to another scheduler successfully")
+	metrics.PeerTaskMigrateCount.WithLabelValues(strconv.FormatBool(true)).Add(1)
+	return true
+}
+
+// migrate registers the peer task with migrating flag, the scheduler is picked by balancer
+// from the available schedulers in dynconfig, then the ready pieces are reported to the new scheduler,
+// so the peer task continues downloading without restarting.
+func (pt *peerTaskConductor) migrate() error {
+	request := proto.Clone(pt.request).(*schedulerv1.PeerTaskRequest)
+	request.IsMigrating = true
+
+	regCtx, cancel := context.WithTimeout(pt.ctx, pt.peerTaskManager.schedulerOption.ScheduleTimeout.Duration)
+	defer cancel()
+	if _, err := pt.schedulerClient.RegisterPeerTask(regCtx, request); err != nil {
+		return err
+	}
+
+	peerPacketStream, err := pt.schedulerClient.ReportPieceResult(pt.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	pt.sendPieceResultLock.Lock()
+	legacyPeerPacketStream := pt.peerPacketStream
+	pt.peerPacketStream = newBatchPeerPacketStream(peerPacketStream, pt.schedulerOption.PieceResultBatch, pt.SugaredLoggerOnWith)
+	pt.sendPieceResultLock.Unlock()
+
+	if err := legacyPeerPacketStream.CloseSend(); err != nil {
+		pt.Debugf("close legacy peer packet stream error: %s", err)
+	}
+
+	return pt.reportReadyPieces()
+}
+
+// reportReadyPieces reports the downloaded pieces to scheduler,
+// so the scheduler knows the existing piece state of peer.
+func (pt *peerTaskConductor) reportReadyPieces() error {
+	pt.readyPiecesLock.RLock()
+	limit := pt.readyPieces.cap
+	pt.readyPiecesLock.RUnlock()
+
+	packet, err := pt.GetStorage().GetPieces(pt.ctx,
+		&commonv1.PieceTaskRequest{
+			TaskId:   pt.taskID,
+			StartNum: 0,
+			Limit:    uint32(limit),
+		})
+	if err != nil {
+		return fmt.Errorf("get pieces from storage error: %w", err)
+	}
+
+	for _, piece := range packet.PieceInfos {
+		pt.readyPiecesLock.RLock()
+		ready := pt.readyPieces.IsSet(piece.PieceNum)
+		pt.readyPiecesLock.RUnlock()
+		if !ready {
+			continue
+		}
+
+		// dst peer is the peer itself, so scheduler knows the piece is downloaded before migrating
+		if err := pt.sendPieceResult(&schedulerv1.PieceResult{
+			TaskId:        pt.GetTaskID(),
+			SrcPid:        pt.GetPeerID(),
+			DstPid:        pt.GetPeerID(),
+			PieceInfo:     piece,
+			Success:       true,
+			Code:          commonv1.Code_Success,
+			HostLoad:      pt.hostLoad(),
+			FinishedCount: pt.readyPieces.Settled(),
+		}); err != nil {
+			return fmt.Errorf("report ready piece %d error: %w", piece.PieceNum, err)
+		}
+	}
+
+	pt.Infof("reported %d ready pieces to the new scheduler", pt.readyPieces.Settled())
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	testifyassert "github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	schedulerv1mocks "d7y.io/api/pkg/apis/scheduler/v1/mocks"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
+	"d7y.io/dragonfly/v2/client/util"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	schedulerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client/mocks"
+)
+
+func TestPeerTaskConductor_tryMigrate(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		migratedCount int
+		mock          func(sc *schedulerclientmocks.MockClientMockRecorder, legacy *schedulerv1mocks.MockScheduler_ReportPieceResultClientMockRecorder, stream schedulerv1.Scheduler_ReportPieceResultClient, ts *mocks.MockTaskStorageDriverMockRecorder)
+		expect        func(t *testing.T, ok bool, reported []int32)
+	}{
+		{
+			name: "migrate and report ready pieces",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+			mock: func(sc *schedulerclientmocks.MockClientMockRecorder, legacy *schedulerv1mocks.MockScheduler_ReportPieceResultClientMockRecorder, stream schedulerv1.Scheduler_ReportPieceResultClient, ts *mocks.MockTaskStorageDriverMockRecorder) {
+				sc.RegisterPeerTask(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *schedulerv1.PeerTaskRequest, opts ...grpc.CallOption) (*schedulerv1.RegisterResult, error) {
+						if !req.IsMigrating {
+							return nil, errors.New("peer task is not migrating")
+						}
+						return &schedulerv1.RegisterResult{}, nil
+					}).Times(1)
+				sc.ReportPieceResult(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1)
+				legacy.CloseSend().Return(nil).Times(1)
+				ts.GetPieces(gomock.Any(), gomock.Any()).Return(&commonv1.PiecePacket{
+					PieceInfos: []*commonv1.PieceInfo{{PieceNum: 0}, {PieceNum: 1}, {PieceNum: 2}},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, ok bool, reported []int32) {
+				assert := testifyassert.New(t)
+				assert.True(ok)
+				assert.Equal([]int32{0, 2}, reported)
+			},
+		},
+		{
+			name: "error is not caused by scheduler connection",
+			err:  status.Error(codes.Internal, "internal error"),
+			mock: func(sc *schedulerclientmocks.MockClientMockRecorder, legacy *schedulerv1mocks.MockScheduler_ReportPieceResultClientMockRecorder, stream schedulerv1.Scheduler_ReportPieceResultClient, ts *mocks.MockTaskStorageDriverMockRecorder) {
+			},
+			expect: func(t *testing.T, ok bool, reported []int32) {
+				assert := testifyassert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name:          "migrated count exceeds the limit",
+			err:           status.Error(codes.Unavailable, "connection refused"),
+			migratedCount: maxMigrateCount,
+			mock: func(sc *schedulerclientmocks.MockClientMockRecorder, legacy *schedulerv1mocks.MockScheduler_ReportPieceResultClientMockRecorder, stream schedulerv1.Scheduler_ReportPieceResultClient, ts *mocks.MockTaskStorageDriverMockRecorder) {
+			},
+			expect: func(t *testing.T, ok bool, reported []int32) {
+				assert := testifyassert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "register to another scheduler failed",
+			err:  status.Error(codes.Unavailable, "connection refused"),
+			mock: func(sc *schedulerclientmocks.MockClientMockRecorder, legacy *schedulerv1mocks.MockScheduler_ReportPieceResultClientMockRecorder, stream schedulerv1.Scheduler_ReportPieceResultClient, ts *mocks.MockTaskStorageDriverMockRecorder) {
+				sc.RegisterPeerTask(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "no scheduler")).Times(1)
+			},
+			expect: func(t *testing.T, ok bool, reported []int32) {
+				assert := testifyassert.New(t)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			schedulerClient := schedulerclientmocks.NewMockClient(ctrl)
+			legacyStream := schedulerv1mocks.NewMockScheduler_ReportPieceResultClient(ctrl)
+			stream := schedulerv1mocks.NewMockScheduler_ReportPieceResultClient(ctrl)
+			taskStorage := mocks.NewMockTaskStorageDriver(ctrl)
+
+			var reported []int32
+			stream.EXPECT().Send(gomock.Any()).DoAndReturn(
+				func(pr *schedulerv1.PieceResult) error {
+					reported = append(reported, pr.PieceInfo.PieceNum)
+					return nil
+				}).AnyTimes()
+			tc.mock(schedulerClient.EXPECT(), legacyStream.EXPECT(), stream, taskStorage.EXPECT())
+
+			readyPieces := NewBitmap()
+			readyPieces.Sets(0, 2)
+			pt := &peerTaskConductor{
+				SugaredLoggerOnWith: logger.With("peer", "test"),
+				ctx:                 context.Background(),
+				taskID:              "task",
+				peerID:              "peer",
+				request:             &schedulerv1.PeerTaskRequest{PeerId: "peer", UrlMeta: &commonv1.UrlMeta{}},
+				peerTaskManager: &peerTaskManager{
+					schedulerOption: config.SchedulerOption{ScheduleTimeout: util.Duration{Duration: time.Second}},
+				},
+				schedulerClient:  schedulerClient,
+				peerPacketStream: legacyStream,
+				storage:          taskStorage,
+				readyPieces:      readyPieces,
+				migratedCount:    tc.migratedCount,
+				successCh:        make(chan struct{}),
+				failCh:           make(chan struct{}),
+			}
+
+			tc.expect(t, pt.tryMigrate(tc.err), reported)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"sync"
+	"time"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+// parentExclusion tracks parents recently failed or slow for a peer task,
+// they are reported to scheduler as hints to exclude them in the next schedule.
+type parentExclusion struct {
+	option config.ParentExclusionOption
+
+	mu      sync.Mutex
+	parents map[string]time.Time
+}
+
+// newParentExclusion returns a parent exclusion, nil is returned when exclusion is disabled.
+func newParentExclusion(option config.ParentExclusionOption) *parentExclusion {
+	if option.TTL.Duration <= 0 {
+		return nil
+	}
+
+	return &parentExclusion{
+		option:  option,
+		parents: map[string]time.Time{},
+	}
+}
+
+// addFailed excludes the parent which failed to download a piece.
+func (e *parentExclusion) addFailed(parentID string) {
+	if e == nil || parentID == "" {
+		return
+	}
+
+	e.mu.Lock()
+	e.parents[parentID] = time.Now().Add(e.option.TTL.Duration)
+	e.mu.Unlock()
+}
+
+// addIfSlow excludes the parent when downloading a piece from it costs more than slow piece cost,
+// returns whether the parent is excluded.
+func (e *parentExclusion) addIfSlow(parentID string, cost time.Duration) bool {
+	if e == nil || parentID == "" || e.option.SlowPieceCost.Duration <= 0 || cost <= e.option.SlowPieceCost.Duration {
+		return false
+	}
+
+	e.addFailed(parentID)
+	return true
+}
+
+// list returns the excluded parents which are not expired.
+func (e *parentExclusion) list() []string {
+	if e == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	var parents []string
+	for parentID, expireAt := range e.parents {
+		if now.After(expireAt) {
+			delete(e.parents, parentID)
+			continue
+		}
+
+		parents = append(parents, parentID)
+	}
+
+	return parents
+}
@@ -0,0 +1,59 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/util"
+)
+
+func TestParentExclusion(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	// disabled exclusion is nil and safe to use
+	disabled := newParentExclusion(config.ParentExclusionOption{})
+	assert.Nil(disabled)
+	disabled.addFailed("foo")
+	assert.False(disabled.addIfSlow("foo", time.Hour))
+	assert.Empty(disabled.list())
+
+	exclusion := newParentExclusion(config.ParentExclusionOption{
+		TTL:           util.Duration{Duration: 50 * time.Millisecond},
+		SlowPieceCost: util.Duration{Duration: time.Second},
+	})
+	exclusion.addFailed("foo")
+	exclusion.addFailed("")
+	assert.False(exclusion.addIfSlow("bar", 500*time.Millisecond))
+	assert.True(exclusion.addIfSlow("baz", 2*time.Second))
+	assert.ElementsMatch([]string{"foo", "baz"}, exclusion.list())
+
+	// excluded parents expire after ttl
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(exclusion.list())
+
+	// slow parents are not excluded without slow piece cost
+	exclusion = newParentExclusion(config.ParentExclusionOption{
+		TTL: util.Duration{Duration: time.Minute},
+	})
+	assert.False(exclusion.addIfSlow("foo", time.Hour))
+	assert.Empty(exclusion.list())
+}
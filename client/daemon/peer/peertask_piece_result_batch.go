@@ -0,0 +1,149 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"sync"
+	"time"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
+)
+
+// batchPeerPacketStream aggregates successful piece results and sends them in batch,
+// to cut the per piece overhead of tasks with lots of tiny pieces.
+type batchPeerPacketStream struct {
+	schedulerv1.Scheduler_ReportPieceResultClient
+
+	size int
+	log  *logger.SugaredLoggerOnWith
+
+	mu      sync.Mutex
+	pending []*schedulerv1.PieceResult
+	err     error
+	done    chan struct{}
+	closed  bool
+}
+
+// newBatchPeerPacketStream wraps the stream with batch reporting when it is enabled in option.
+func newBatchPeerPacketStream(stream schedulerv1.Scheduler_ReportPieceResultClient,
+	option config.PieceResultBatchOption, log *logger.SugaredLoggerOnWith) schedulerv1.Scheduler_ReportPieceResultClient {
+	if option.Size < 2 || option.Interval.Duration <= 0 {
+		return stream
+	}
+
+	s := &batchPeerPacketStream{
+		Scheduler_ReportPieceResultClient: stream,
+		size:                              option.Size,
+		log:                               log,
+		pending:                           make([]*schedulerv1.PieceResult, 0, option.Size),
+		done:                              make(chan struct{}),
+	}
+	go s.flushLoop(option.Interval.Duration)
+	return s
+}
+
+func (s *batchPeerPacketStream) Send(pr *schedulerv1.PieceResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// report the error of background flush to caller
+	if s.err != nil {
+		err := s.err
+		s.err = nil
+		return err
+	}
+
+	if !batchable(pr) {
+		// keep report order, flush pending piece results first
+		if err := s.flush(); err != nil {
+			return err
+		}
+		return s.Scheduler_ReportPieceResultClient.Send(pr)
+	}
+
+	s.pending = append(s.pending, pr)
+	if len(s.pending) >= s.size {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *batchPeerPacketStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+
+	if err := s.flush(); err != nil {
+		s.log.Warnf("flush piece results before close send error: %s", err)
+	}
+	return s.Scheduler_ReportPieceResultClient.CloseSend()
+}
+
+func (s *batchPeerPacketStream) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.mu.Lock()
+			if err := s.flush(); err != nil && s.err == nil {
+				s.err = err
+			}
+			s.mu.Unlock()
+		case <-s.done:
+			return
+		case <-s.Context().Done():
+			return
+		}
+	}
+}
+
+// flush sends pending piece results in one batch, the caller must hold s.mu.
+func (s *batchPeerPacketStream) flush() error {
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	packed, err := common.PackPieceResults(s.pending)
+	s.pending = s.pending[:0]
+	if err != nil {
+		return err
+	}
+	return s.Scheduler_ReportPieceResultClient.Send(packed)
+}
+
+// batchable reports whether the piece result can be aggregated,
+// only successful piece results of normal pieces are batched.
+func batchable(pr *schedulerv1.PieceResult) bool {
+	if !pr.Success || pr.Code != commonv1.Code_Success || pr.PieceInfo == nil {
+		return false
+	}
+	if pr.PieceInfo.PieceNum == common.BeginOfPiece || pr.PieceInfo.PieceNum == common.EndOfPiece {
+		return false
+	}
+	return pr.ExtendAttribute == nil
+}
@@ -0,0 +1,125 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	testifyassert "github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	schedulerv1mocks "d7y.io/api/pkg/apis/scheduler/v1/mocks"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/util"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
+)
+
+func TestBatchPeerPacketStream(t *testing.T) {
+	successPiece := func(num int32) *schedulerv1.PieceResult {
+		return &schedulerv1.PieceResult{
+			SrcPid:    "peer",
+			Success:   true,
+			Code:      commonv1.Code_Success,
+			PieceInfo: &commonv1.PieceInfo{PieceNum: num},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		option   config.PieceResultBatchOption
+		send     []*schedulerv1.PieceResult
+		expected [][]int32
+	}{
+		{
+			name:     "batch disabled",
+			option:   config.PieceResultBatchOption{Size: 1, Interval: util.Duration{Duration: time.Hour}},
+			send:     []*schedulerv1.PieceResult{successPiece(0), successPiece(1)},
+			expected: [][]int32{{0}, {1}},
+		},
+		{
+			name:     "flush by size",
+			option:   config.PieceResultBatchOption{Size: 2, Interval: util.Duration{Duration: time.Hour}},
+			send:     []*schedulerv1.PieceResult{successPiece(0), successPiece(1), successPiece(2)},
+			expected: [][]int32{{0, 1}, {2}},
+		},
+		{
+			name:   "flush before unbatchable piece result",
+			option: config.PieceResultBatchOption{Size: 4, Interval: util.Duration{Duration: time.Hour}},
+			send: []*schedulerv1.PieceResult{successPiece(0), successPiece(1), {
+				SrcPid:    "peer",
+				Code:      commonv1.Code_ClientPieceDownloadFail,
+				PieceInfo: &commonv1.PieceInfo{PieceNum: 2},
+			}},
+			expected: [][]int32{{0, 1}, {2}},
+		},
+		{
+			name:     "flush by interval",
+			option:   config.PieceResultBatchOption{Size: 100, Interval: util.Duration{Duration: 10 * time.Millisecond}},
+			send:     []*schedulerv1.PieceResult{successPiece(0), successPiece(1)},
+			expected: [][]int32{{0, 1}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			sent := make(chan []int32, 10)
+			stream := schedulerv1mocks.NewMockScheduler_ReportPieceResultClient(ctrl)
+			stream.EXPECT().Context().Return(context.Background()).AnyTimes()
+			stream.EXPECT().CloseSend().Return(nil).AnyTimes()
+			stream.EXPECT().Send(gomock.Any()).DoAndReturn(func(pr *schedulerv1.PieceResult) error {
+				results, err := common.UnpackPieceResults(pr)
+				assert.Nil(err)
+				var nums []int32
+				for _, result := range results {
+					nums = append(nums, result.PieceInfo.PieceNum)
+				}
+				sent <- nums
+				return nil
+			}).AnyTimes()
+
+			s := newBatchPeerPacketStream(stream, tc.option, logger.With("test", t.Name()))
+			for _, pr := range tc.send {
+				assert.Nil(s.Send(pr))
+			}
+			if tc.option.Interval.Duration >= time.Hour {
+				// pending piece results are flushed when close send
+				assert.Nil(s.CloseSend())
+			}
+
+			for _, expected := range tc.expected {
+				select {
+				case nums := <-sent:
+					assert.Equal(expected, nums)
+				case <-time.After(time.Second):
+					t.Fatalf("wait piece results %v timeout", expected)
+				}
+			}
+			assert.Nil(s.CloseSend())
+			assert.Empty(sent)
+		})
+	}
+}
@@ -106,6 +106,12 @@ retry:
 	}
 	ptc.Debugf("get piece task error: %s", err)
 
+	// peer kept returning empty pieces, it is not a fatal error of the peer
+	if retry.IsExhausted(err) {
+		span.AddEvent("retry exhausted")
+		ptc.Warnf("get piece task from peer %s retry exhausted: %s", peer.PeerId, err)
+	}
+
 	// grpc error
 	if se, ok := err.(interface{ GRPCStatus() *status.Status }); ok {
 		ptc.Debugf("get piece task with grpc error, code: %d", se.GRPCStatus().Code())
@@ -158,14 +164,13 @@ func (poller *pieceTaskPoller) getPieceTasksByPeer(
 	request *commonv1.PieceTaskRequest) (*commonv1.PiecePacket, error) {
 	var (
 		peerPacketChanged bool
-		count             int
 		ptc               = poller.peerTaskConductor
 	)
-	p, _, err := retry.Run(ptc.ctx, 0.05, 0.2, 40, func() (any, bool, error) {
+	p, err := retry.Run(ptc.ctx, 0.05, 0.2, 40, func(attempt int) (any, error) {
 		// GetPieceTasks must be fast, so short time out is okay
 		ctx, cancel := context.WithTimeout(ptc.ctx, 4*time.Second)
 		defer cancel()
-		piecePacket, getError := dfclient.GetPieceTasks(ctx, peer, request)
+		piecePacket, getError := dfclient.GetPieceTasksByAddr(ctx, ptc.peerAddr(peer.Ip, peer.RpcPort), request)
 		// when GetPieceTasks returns err, exit retry
 		if getError != nil {
 			ptc.Errorf("get piece tasks with error: %s", getError)
@@ -178,7 +183,7 @@ func (poller *pieceTaskPoller) getPieceTasksByPeer(
 				if de.Code == commonv1.Code_BadRequest {
 					span.AddEvent("bad request")
 					ptc.Warnf("get piece task from peer %s canceled: %s", peer.PeerId, getError)
-					return nil, true, getError
+					return nil, retry.Abort(getError)
 				}
 			}
 
@@ -188,22 +193,22 @@ func (poller *pieceTaskPoller) getPieceTasksByPeer(
 				ptc.Warnf("get piece tasks with error: %s, but peer packet changed, switch to new peer packet, current destPeer %s, new destPeer %s", getError,
 					curPeerPacket.CandidatePeers[0].PeerId, lastPeerPacket.CandidatePeers[0].PeerId)
 				peerPacketChanged = true
-				return nil, true, nil
+				return nil, nil
 			}
-			return nil, true, getError
+			return nil, retry.Abort(getError)
 		}
 		// got any pieces
 		if len(piecePacket.PieceInfos) > 0 {
-			return piecePacket, false, nil
+			return piecePacket, nil
 		}
 		// need update metadata
 		if piecePacket.ContentLength > ptc.GetContentLength() || piecePacket.TotalPiece > ptc.GetTotalPieces() {
-			return piecePacket, false, nil
+			return piecePacket, nil
 		}
 		// invalid request num
 		if piecePacket.TotalPiece > -1 && uint32(piecePacket.TotalPiece) <= request.StartNum {
 			ptc.Warnf("invalid start num: %d, total piece: %d", request.StartNum, piecePacket.TotalPiece)
-			return piecePacket, false, nil
+			return piecePacket, nil
 		}
 
 		// by santong: when peer return empty, retry later
@@ -221,7 +226,7 @@ func (poller *pieceTaskPoller) getPieceTasksByPeer(
 			ptc.cancel(commonv1.Code_ClientPieceRequestFail, sendError.Error())
 			span.RecordError(sendError)
 			ptc.Errorf("send piece result with commonv1.Code_ClientWaitPieceReady error: %s", sendError)
-			return nil, true, sendError
+			return nil, retry.Abort(sendError)
 		}
 		// fast way to exit retry
 		lastPeerPacket := ptc.peerPacket.Load().(*schedulerv1.PeerPacket)
@@ -229,13 +234,12 @@ func (poller *pieceTaskPoller) getPieceTasksByPeer(
 			ptc.Warnf("get empty pieces and peer packet changed, switch to new peer packet, current destPeer %s, new destPeer %s",
 				curPeerPacket.CandidatePeers[0].PeerId, lastPeerPacket.CandidatePeers[0].PeerId)
 			peerPacketChanged = true
-			return nil, true, nil
+			return nil, nil
 		}
-		count++
 		span.AddEvent("retry due to empty pieces",
-			trace.WithAttributes(config.AttributeGetPieceRetry.Int(count)))
+			trace.WithAttributes(config.AttributeGetPieceRetry.Int(attempt+1)))
 		ptc.Infof("peer %s returns success but with empty pieces, retry later", peer.PeerId)
-		return nil, false, dferrors.ErrEmptyValue
+		return nil, dferrors.ErrEmptyValue
 	})
 	if peerPacketChanged {
 		return nil, errPeerPacketChanged
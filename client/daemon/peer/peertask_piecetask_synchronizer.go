@@ -46,6 +46,10 @@ type pieceTaskSyncManager struct {
 	pieceRequestCh    chan *DownloadPieceRequest
 	workers           map[string]*pieceTaskSynchronizer
 	watchdog          *synchronizerWatchdog
+	// destPeerLimiters limit the concurrent piece downloads per destination peer
+	destPeerLimiters map[string]*destPeerLimiter
+	// workerCount is the count of download piece workers
+	workerCount int32
 }
 
 type pieceTaskSynchronizer struct {
@@ -56,6 +60,7 @@ type pieceTaskSynchronizer struct {
 	error             atomic.Value
 	peerTaskConductor *peerTaskConductor
 	pieceRequestCh    chan *DownloadPieceRequest
+	limiter           *destPeerLimiter
 }
 
 type synchronizerWatchdog struct {
@@ -161,7 +166,7 @@ func (s *pieceTaskSyncManager) newPieceTaskSynchronizer(
 		delete(s.workers, dstPeer.PeerId)
 	}
 
-	client, err := dfclient.SyncPieceTasks(ctx, dstPeer, request)
+	client, err := dfclient.SyncPieceTasksByAddr(ctx, s.peerTaskConductor.peerAddr(dstPeer.Ip, dstPeer.RpcPort), request)
 	// Refer: https://github.com/grpc/grpc-go/blob/v1.44.0/stream.go#L104
 	// When receive io.EOF, the real error should be discovered using RecvMsg, here is client.Recv() here
 	if err == io.EOF && client != nil {
@@ -187,6 +192,7 @@ func (s *pieceTaskSyncManager) newPieceTaskSynchronizer(
 		span:                span,
 		peerTaskConductor:   s.peerTaskConductor,
 		pieceRequestCh:      s.pieceRequestCh,
+		limiter:             s.destPeerLimiter(dstPeer.PeerId),
 		client:              client,
 		dstPeer:             dstPeer,
 		error:               atomic.Value{},
@@ -290,6 +296,27 @@ func (s *pieceTaskSyncManager) acquire(request *commonv1.PieceTaskRequest) (atte
 	return
 }
 
+// setWorkerCount updates the count of download piece workers, which bounds the destination peer limit.
+func (s *pieceTaskSyncManager) setWorkerCount(count int32) {
+	s.Lock()
+	s.workerCount = count
+	s.Unlock()
+}
+
+// destPeerLimiter returns the limiter of destination peer, the caller must hold the lock.
+// The limiter is kept when the synchronizer is reset, so the learned rate is not lost.
+func (s *pieceTaskSyncManager) destPeerLimiter(peerID string) *destPeerLimiter {
+	if s.destPeerLimiters == nil {
+		s.destPeerLimiters = map[string]*destPeerLimiter{}
+	}
+	limiter, ok := s.destPeerLimiters[peerID]
+	if !ok {
+		limiter = newDestPeerLimiter(destPeerLimit(s.workerCount))
+		s.destPeerLimiters[peerID] = limiter
+	}
+	return limiter
+}
+
 func (s *pieceTaskSyncManager) cancel() {
 	s.ctxCancel()
 	s.Lock()
@@ -339,14 +366,41 @@ func (s *pieceTaskSynchronizer) dispatchPieceRequest(piecePacket *commonv1.Piece
 			PeerID:  s.peerTaskConductor.GetPeerID(),
 			DstPid:  piecePacket.DstPid,
 			DstAddr: piecePacket.DstAddr,
+			limiter: s.limiter,
+		}
+		// wait for a free slot of the destination peer, block here instead of occupying download piece workers
+		if !s.acquireLimiter(piece.PieceNum) {
+			return
 		}
 		select {
 		case s.pieceRequestCh <- req:
 			s.span.AddEvent(fmt.Sprintf("send piece #%d request to piece download queue", piece.PieceNum))
+		case <-s.peerTaskConductor.successCh:
+			s.limiter.cancel()
+			s.Infof("peer task success, stop dispatch piece request, dest peer: %s", s.dstPeer.PeerId)
+		case <-s.peerTaskConductor.failCh:
+			s.limiter.cancel()
+			s.Warnf("peer task fail, stop dispatch piece request, dest peer: %s", s.dstPeer.PeerId)
+		}
+	}
+}
+
+func (s *pieceTaskSynchronizer) acquireLimiter(pieceNum int32) bool {
+	for {
+		ok, released := s.limiter.tryAcquire()
+		if ok {
+			return true
+		}
+		s.Debugf("wait dest peer limiter for piece %d, limit: %d, rate: %.0f",
+			pieceNum, s.limiter.Limit(), s.limiter.Rate())
+		select {
+		case <-released:
 		case <-s.peerTaskConductor.successCh:
 			s.Infof("peer task success, stop dispatch piece request, dest peer: %s", s.dstPeer.PeerId)
+			return false
 		case <-s.peerTaskConductor.failCh:
 			s.Warnf("peer task fail, stop dispatch piece request, dest peer: %s", s.dstPeer.PeerId)
+			return false
 		}
 	}
 }
@@ -0,0 +1,65 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+// PrefetchTaskRequest is the request to warm a task into local storage
+type PrefetchTaskRequest struct {
+	schedulerv1.PeerTaskRequest
+}
+
+func (ptm *peerTaskManager) StartPrefetchTask(ctx context.Context, req *PrefetchTaskRequest) (*SubscribeResponse, bool, error) {
+	taskID := idgen.TaskID(req.Url, req.UrlMeta)
+	if ptm.storageManager.FindCompletedTask(taskID) != nil {
+		logger.Debugf("prefetch task %s already completed, skip", taskID)
+		return nil, true, nil
+	}
+
+	var limit = rate.Inf
+	if ptm.perPeerRateLimit > 0 {
+		limit = ptm.perPeerRateLimit
+	}
+
+	ptc, err := ptm.getPeerTaskConductor(ctx, taskID, &req.PeerTaskRequest, limit, nil, nil, "", false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if ptc.peerID == req.PeerId {
+		metrics.PrefetchTaskCount.Add(1)
+	}
+
+	logger.Infof("prefetch peer task %s/%s", taskID, ptc.peerID)
+	// prefetch task has no reader, so do not subscribe piece info
+	return &SubscribeResponse{
+		Storage:    ptc.storage,
+		Success:    ptc.successCh,
+		Fail:       ptc.failCh,
+		FailReason: ptc.getFailedError,
+	}, false, nil
+}
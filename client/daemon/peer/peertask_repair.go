@@ -0,0 +1,82 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"strconv"
+
+	"golang.org/x/time/rate"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+// repairPeerTask re-downloads the corrupted pieces of the peer task which were removed from storage,
+// the peer task is started again with the same peer id, so the other pieces are restored from storage.
+// If the digest still does not match after repaired, the storage invalidates the whole task.
+func (ptm *peerTaskManager) repairPeerTask(request *schedulerv1.PeerTaskRequest, seed bool, pieceNums []int32) {
+	req := &schedulerv1.PeerTaskRequest{
+		Url:         request.Url,
+		PeerId:      request.PeerId,
+		PeerHost:    ptm.host,
+		HostLoad:    request.HostLoad,
+		IsMigrating: request.IsMigrating,
+		Pattern:     request.Pattern,
+		UrlMeta: &commonv1.UrlMeta{
+			Digest:      request.UrlMeta.Digest,
+			Tag:         request.UrlMeta.Tag,
+			Range:       request.UrlMeta.Range,
+			Filter:      request.UrlMeta.Filter,
+			Application: request.UrlMeta.Application,
+			Header:      map[string]string{},
+		},
+	}
+	for k, v := range request.UrlMeta.Header {
+		req.UrlMeta.Header[k] = v
+	}
+	taskID := idgen.TaskID(req.Url, req.UrlMeta)
+
+	var limit = rate.Inf
+	if ptm.perPeerRateLimit > 0 {
+		limit = ptm.perPeerRateLimit
+	}
+
+	logger.Infof("repair peer task %s/%s, corrupted pieces: %v", taskID, req.PeerId, pieceNums)
+	metrics.PeerTaskCorruptedPieceCount.Add(float64(len(pieceNums)))
+	ptc, err := ptm.getPeerTaskConductor(context.Background(), taskID, req, limit, nil, nil, "", seed)
+	if err != nil {
+		logger.Errorf("repair peer task %s/%s error: %s", taskID, req.PeerId, err)
+		metrics.PeerTaskRepairCount.WithLabelValues(strconv.FormatBool(false)).Add(1)
+		return
+	}
+
+	go func() {
+		select {
+		case <-ptc.successCh:
+			ptc.Infof("repair peer task ok")
+			metrics.PeerTaskRepairCount.WithLabelValues(strconv.FormatBool(true)).Add(1)
+		case <-ptc.failCh:
+			ptc.Errorf("repair peer task failed: %s", ptc.failedReason)
+			metrics.PeerTaskRepairCount.WithLabelValues(strconv.FormatBool(false)).Add(1)
+		}
+	}()
+}
@@ -28,12 +28,14 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	"d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/source"
 )
 
 var _ *logger.SugaredLoggerOnWith // pin this package for no log code generation
@@ -76,6 +78,10 @@ func (ptm *peerTaskManager) tryReuseFilePeerTask(ctx context.Context,
 		}
 	}
 
+	if !ptm.revalidate(ctx, request.Url, request.UrlMeta, reuse) {
+		return nil, false
+	}
+
 	if reuseRange == nil {
 		log = logger.With("peer", request.PeerId, "task", taskID, "component", "reuseFilePeerTask")
 		log.Infof("reuse from peer task: %s, total size: %d", reuse.PeerID, reuse.ContentLength)
@@ -123,6 +129,9 @@ func (ptm *peerTaskManager) tryReuseFilePeerTask(ctx context.Context,
 			StoreDataOnly:  true,
 			TotalPieces:    reuse.TotalPieces,
 			OriginalOffset: request.KeepOriginalOffset,
+			InPlace:        request.InPlace,
+			Range:          reuseRange,
+			OutputOption:   request.outputOption(),
 		}
 		err = ptm.storageManager.Store(ctx, storeRequest)
 	} else {
@@ -164,11 +173,6 @@ func (ptm *peerTaskManager) tryReuseFilePeerTask(ctx context.Context,
 
 func (ptm *peerTaskManager) storePartialFile(ctx context.Context, request *FileTaskRequest,
 	log *logger.SugaredLoggerOnWith, reuse *storage.ReusePeerTask, rg *util.Range) error {
-	f, err := os.OpenFile(request.Output, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
-	if err != nil {
-		log.Errorf("open dest file error when reuse peer task: %s", err)
-		return err
-	}
 	rc, err := ptm.storageManager.ReadAllPieces(ctx,
 		&storage.ReadAllPiecesRequest{PeerTaskMetadata: reuse.PeerTaskMetadata, Range: rg})
 	if err != nil {
@@ -176,16 +180,26 @@ func (ptm *peerTaskManager) storePartialFile(ctx context.Context, request *FileT
 		return err
 	}
 	defer rc.Close()
-	n, err := io.Copy(f, rc)
-	if err != nil {
-		log.Errorf("copy data error when reuse peer task: %s", err)
-		return err
-	}
-	if n != rg.Length {
-		log.Errorf("copy data length not match when reuse peer task, actual: %d, desire: %d", n, rg.Length)
-		return io.ErrShortBuffer
-	}
-	return nil
+
+	return storage.StoreOutput(request.Output, request.outputOption(), func(tmp string) error {
+		f, err := os.OpenFile(tmp, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Errorf("open dest file error when reuse peer task: %s", err)
+			return err
+		}
+		defer f.Close()
+
+		n, err := io.Copy(f, rc)
+		if err != nil {
+			log.Errorf("copy data error when reuse peer task: %s", err)
+			return err
+		}
+		if n != rg.Length {
+			log.Errorf("copy data length not match when reuse peer task, actual: %d, desire: %d", n, rg.Length)
+			return io.ErrShortBuffer
+		}
+		return nil
+	})
 }
 
 func (ptm *peerTaskManager) tryReuseStreamPeerTask(ctx context.Context,
@@ -217,6 +231,10 @@ func (ptm *peerTaskManager) tryReuseStreamPeerTask(ctx context.Context,
 		}
 	}
 
+	if !ptm.revalidate(ctx, request.URL, request.URLMeta, reuse) {
+		return nil, nil, false
+	}
+
 	if reuseRange == nil {
 		log = logger.With("peer", request.PeerID, "task", taskID, "component", "reuseStreamPeerTask")
 		log.Infof("reuse from peer task: %s, total size: %d", reuse.PeerID, reuse.ContentLength)
@@ -326,6 +344,10 @@ func (ptm *peerTaskManager) tryReuseSeedPeerTask(ctx context.Context,
 		//}
 	}
 
+	if !ptm.revalidate(ctx, request.Url, request.UrlMeta, reuse) {
+		return nil, false
+	}
+
 	if reuseRange == nil {
 		log = logger.With("peer", request.PeerId, "task", taskID, "component", "reuseSeedPeerTask")
 		log.Infof("reuse from peer task: %s, total size: %d", reuse.PeerID, reuse.ContentLength)
@@ -367,3 +389,37 @@ func (ptm *peerTaskManager) tryReuseSeedPeerTask(ctx context.Context,
 		},
 	}, true
 }
+
+// revalidate revalidates the reused task with origin by conditional request when it is required by storage,
+// the cached pieces are reused when origin is not modified, otherwise the task is invalid and returns false.
+// When origin is unreachable, the cached pieces are still reused.
+func (ptm *peerTaskManager) revalidate(ctx context.Context, url string, urlMeta *commonv1.UrlMeta, reuse *storage.ReusePeerTask) bool {
+	if reuse.ExpireInfo == nil {
+		return true
+	}
+
+	log := logger.With("peer", reuse.PeerID, "task", reuse.TaskID, "component", "revalidatePeerTask")
+	request, err := newSourceOrigins(&schedulerv1.PeerTaskRequest{Url: url, UrlMeta: urlMeta}).newRequest(ctx, 0)
+	if err != nil {
+		log.Warnf("create revalidate request error: %s", err)
+		return true
+	}
+
+	expired, err := source.IsExpired(request, reuse.ExpireInfo)
+	if err != nil {
+		log.Warnf("revalidate with origin error, reuse the cached pieces: %s", err)
+		return true
+	}
+
+	if err := ptm.storageManager.MarkRevalidated(reuse.TaskID, expired); err != nil {
+		log.Warnf("mark task revalidated error: %s", err)
+	}
+
+	if expired {
+		log.Infof("task is modified in origin, download it again")
+		return false
+	}
+
+	log.Infof("task is not modified in origin, reuse the cached pieces")
+	return true
+}
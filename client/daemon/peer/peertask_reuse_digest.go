@@ -0,0 +1,203 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	"d7y.io/dragonfly/v2/client/util"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+// tryReuseDigestSeedPeerTask reuses the data of a completed task with the same digest for the seed task,
+// the reused task may be downloaded from a different url. The pieces are copied into the storage of
+// seed task, which shares the extents with the reused task when storage dedup works. The content is
+// verified against the digest while copying, the seed task downloads from origin when it mismatches.
+func (ptm *peerTaskManager) tryReuseDigestSeedPeerTask(ctx context.Context,
+	request *SeedTaskRequest) (*SeedTaskResponse, bool) {
+	if request.UrlMeta == nil || request.UrlMeta.Digest == "" || request.UrlMeta.Range != "" || request.Range != nil {
+		return nil, false
+	}
+
+	taskID := idgen.TaskID(request.Url, request.UrlMeta)
+	reuse := ptm.storageManager.FindCompletedTaskByDigest(request.UrlMeta.Digest)
+	if reuse == nil || reuse.TaskID == taskID {
+		return nil, false
+	}
+
+	log := logger.With("peer", request.PeerId, "task", taskID, "component", "reuseDigestSeedPeerTask")
+	log.Infof("reuse data of task %s/%s with the same digest, total size: %d", reuse.TaskID, reuse.PeerID, reuse.ContentLength)
+
+	meta := storage.PeerTaskMetadata{
+		PeerID: request.PeerId,
+		TaskID: taskID,
+	}
+	tsd, err := ptm.copyReusedTask(ctx, meta, request.UrlMeta.Digest, reuse)
+	if err != nil {
+		log.Warnf("reuse data of task %s/%s error, download from origin: %s", reuse.TaskID, reuse.PeerID, err)
+		return nil, false
+	}
+
+	ctx, span := tracer.Start(ctx, config.SpanReusePeerTask, trace.WithSpanKind(trace.SpanKindClient))
+	span.SetAttributes(config.AttributePeerHost.String(ptm.host.Id))
+	span.SetAttributes(semconv.NetHostIPKey.String(ptm.host.Ip))
+	span.SetAttributes(config.AttributeTaskID.String(taskID))
+	span.SetAttributes(config.AttributePeerID.String(request.PeerId))
+	span.SetAttributes(config.AttributeReusePeerID.String(reuse.PeerID))
+	span.SetAttributes(semconv.HTTPURLKey.String(request.Url))
+
+	successCh := make(chan struct{}, 1)
+	successCh <- struct{}{}
+
+	span.SetAttributes(config.AttributePeerTaskSuccess.Bool(true))
+	return &SeedTaskResponse{
+		Context: ctx,
+		Span:    span,
+		TaskID:  taskID,
+		PeerID:  request.PeerId,
+		SubscribeResponse: SubscribeResponse{
+			Storage:          tsd,
+			PieceInfoChannel: nil,
+			Success:          successCh,
+			Fail:             nil,
+			FailReason: func() error {
+				return nil
+			},
+		},
+	}, true
+}
+
+// copyReusedTask copies the pieces of reused task into a new task and verifies the content against the digest,
+// the new task is unregistered when copying failed.
+func (ptm *peerTaskManager) copyReusedTask(ctx context.Context, meta storage.PeerTaskMetadata,
+	digest string, reuse *storage.ReusePeerTask) (tsd storage.TaskStorageDriver, err error) {
+	verifier, err := newDigestVerifier(digest)
+	if err != nil {
+		return nil, err
+	}
+
+	packet, err := reuse.Storage.GetPieces(ctx, &commonv1.PieceTaskRequest{
+		TaskId:   reuse.TaskID,
+		StartNum: 0,
+		Limit:    uint32(reuse.TotalPieces),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packet.PieceInfos) != int(reuse.TotalPieces) {
+		return nil, fmt.Errorf("reused task has %d pieces, desired: %d", len(packet.PieceInfos), reuse.TotalPieces)
+	}
+
+	tsd, err = ptm.storageManager.RegisterTask(ctx, &storage.RegisterTaskRequest{
+		PeerTaskMetadata: meta,
+		ContentLength:    reuse.ContentLength,
+		TotalPieces:      reuse.TotalPieces,
+		PieceMd5Sign:     reuse.PieceMd5Sign,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if e := ptm.storageManager.UnregisterTask(ctx, storage.CommonTaskRequest{
+			PeerID: meta.PeerID,
+			TaskID: meta.TaskID,
+		}); e != nil {
+			logger.Errorf("unregister task %s/%s error: %s", meta.TaskID, meta.PeerID, e)
+		}
+	}()
+
+	for _, piece := range packet.PieceInfos {
+		if err = copyReusedPiece(ctx, tsd, meta, reuse, piece, verifier); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = verifier.finish(); err != nil {
+		return nil, err
+	}
+
+	if err = tsd.UpdateTask(ctx, &storage.UpdateTaskRequest{
+		PeerTaskMetadata: meta,
+		ContentLength:    reuse.ContentLength,
+		TotalPieces:      reuse.TotalPieces,
+		PieceMd5Sign:     reuse.PieceMd5Sign,
+		Header:           reuse.Header,
+		Digest:           digest,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = tsd.Store(ctx, &storage.StoreRequest{
+		CommonTaskRequest: storage.CommonTaskRequest{
+			PeerID: meta.PeerID,
+			TaskID: meta.TaskID,
+		},
+		MetadataOnly: true,
+		TotalPieces:  reuse.TotalPieces,
+	}); err != nil {
+		return nil, err
+	}
+
+	return tsd, nil
+}
+
+// copyReusedPiece copies a piece of reused task, the content is written to verifier too.
+func copyReusedPiece(ctx context.Context, tsd storage.TaskStorageDriver, meta storage.PeerTaskMetadata,
+	reuse *storage.ReusePeerTask, piece *commonv1.PieceInfo, verifier io.Writer) error {
+	r, c, err := reuse.Storage.ReadPiece(ctx, &storage.ReadPieceRequest{
+		PeerTaskMetadata: reuse.PeerTaskMetadata,
+		PieceMetadata: storage.PieceMetadata{
+			Num: piece.PieceNum,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	_, err = tsd.WritePiece(ctx, &storage.WritePieceRequest{
+		PeerTaskMetadata: meta,
+		PieceMetadata: storage.PieceMetadata{
+			Num:    piece.PieceNum,
+			Md5:    piece.PieceMd5,
+			Offset: piece.PieceOffset,
+			Range: util.Range{
+				Start:  int64(piece.RangeStart),
+				Length: int64(piece.RangeSize),
+			},
+			Style: piece.PieceStyle,
+		},
+		Reader: io.TeeReader(r, verifier),
+	})
+	return err
+}
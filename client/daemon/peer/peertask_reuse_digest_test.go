@@ -0,0 +1,189 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	testifyrequire "github.com/stretchr/testify/require"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	"d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+func TestPeerTaskManager_tryReuseDigestSeedPeerTask(t *testing.T) {
+	content := []byte("abcdefghijklmnopqrstuvwxyz")
+	pieceSize := 10
+
+	var testCases = []struct {
+		name          string
+		url           string
+		recordDigest  string
+		requestDigest string
+		expectReused  bool
+	}{
+		{
+			name:          "same content with different url",
+			url:           "http://example.com/mirror",
+			recordDigest:  "sha256:" + sha256Hex(content),
+			requestDigest: "sha256:" + sha256Hex(content),
+			expectReused:  true,
+		},
+		{
+			name:          "same content with range digest",
+			url:           "http://example.com/mirror",
+			recordDigest:  rangeDigestOf(content, pieceSize),
+			requestDigest: rangeDigestOf(content, pieceSize),
+			expectReused:  true,
+		},
+		{
+			name:          "no task with the same digest",
+			url:           "http://example.com/mirror",
+			recordDigest:  "sha256:" + sha256Hex(content),
+			requestDigest: "sha256:" + sha256Hex([]byte("foo")),
+			expectReused:  false,
+		},
+		{
+			name:          "same url",
+			url:           "http://example.com/origin",
+			recordDigest:  "sha256:" + sha256Hex(content),
+			requestDigest: "sha256:" + sha256Hex(content),
+			expectReused:  false,
+		},
+		{
+			name:          "data mismatches the recorded digest",
+			url:           "http://example.com/mirror",
+			recordDigest:  "sha256:" + sha256Hex([]byte("foo")),
+			requestDigest: "sha256:" + sha256Hex([]byte("foo")),
+			expectReused:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			require := testifyrequire.New(t)
+
+			dataDir, err := os.MkdirTemp("", "reuse-digest")
+			require.Nil(err)
+			defer os.RemoveAll(dataDir)
+
+			sm, err := storage.NewStorageManager(config.SimpleLocalTaskStoreStrategy,
+				&config.StorageOption{
+					DataPath: dataDir,
+					TaskExpireTime: util.Duration{
+						Duration: time.Minute,
+					},
+				}, func(request storage.CommonTaskRequest) {})
+			require.Nil(err)
+
+			// the completed task downloaded from origin url
+			origin := storage.PeerTaskMetadata{
+				PeerID: "peer-origin",
+				TaskID: idgen.TaskID("http://example.com/origin", &commonv1.UrlMeta{Digest: tc.recordDigest}),
+			}
+			totalPieces := int32((len(content) + pieceSize - 1) / pieceSize)
+			ts, err := sm.RegisterTask(context.Background(), &storage.RegisterTaskRequest{
+				PeerTaskMetadata: origin,
+				ContentLength:    int64(len(content)),
+				TotalPieces:      totalPieces,
+			})
+			require.Nil(err)
+			for i := int32(0); i < totalPieces; i++ {
+				start := int(i) * pieceSize
+				end := start + pieceSize
+				if end > len(content) {
+					end = len(content)
+				}
+				_, err = ts.WritePiece(context.Background(), &storage.WritePieceRequest{
+					PeerTaskMetadata: origin,
+					PieceMetadata: storage.PieceMetadata{
+						Num:    i,
+						Md5:    digest.MD5FromBytes(content[start:end]),
+						Offset: uint64(start),
+						Range: util.Range{
+							Start:  int64(start),
+							Length: int64(end - start),
+						},
+						Style: commonv1.PieceStyle_PLAIN,
+					},
+					Reader: bytes.NewBuffer(content[start:end]),
+				})
+				require.Nil(err)
+			}
+			require.Nil(ts.UpdateTask(context.Background(), &storage.UpdateTaskRequest{
+				PeerTaskMetadata: origin,
+				Digest:           tc.recordDigest,
+			}))
+			require.Nil(ts.Store(context.Background(), &storage.StoreRequest{
+				CommonTaskRequest: storage.CommonTaskRequest{
+					PeerID: origin.PeerID,
+					TaskID: origin.TaskID,
+				},
+				MetadataOnly: true,
+			}))
+
+			ptm := &peerTaskManager{
+				host:           &schedulerv1.PeerHost{},
+				storageManager: sm,
+			}
+			urlMeta := &commonv1.UrlMeta{Digest: tc.requestDigest}
+			resp, ok := ptm.tryReuseDigestSeedPeerTask(context.Background(), &SeedTaskRequest{
+				PeerTaskRequest: schedulerv1.PeerTaskRequest{
+					Url:     tc.url,
+					UrlMeta: urlMeta,
+					PeerId:  "peer-mirror",
+				},
+			})
+			assert.Equal(tc.expectReused, ok)
+
+			taskID := idgen.TaskID(tc.url, urlMeta)
+			reuse := sm.FindCompletedTask(taskID)
+			if !tc.expectReused {
+				if taskID != origin.TaskID {
+					assert.Nil(reuse, "task should be unregistered when not reused")
+				}
+				return
+			}
+
+			assert.Equal(taskID, resp.TaskID)
+			if assert.NotNil(reuse) {
+				assert.Equal(int64(len(content)), reuse.ContentLength)
+				rc, err := reuse.Storage.ReadAllPieces(context.Background(), &storage.ReadAllPiecesRequest{
+					PeerTaskMetadata: reuse.PeerTaskMetadata,
+				})
+				require.Nil(err)
+				defer rc.Close()
+				data, err := io.ReadAll(rc)
+				assert.Nil(err)
+				assert.Equal(content, data)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ package peer
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -28,6 +29,7 @@ import (
 	"github.com/go-http-utils/headers"
 	"github.com/golang/mock/gomock"
 	testifyassert "github.com/stretchr/testify/assert"
+	testifyrequire "github.com/stretchr/testify/require"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
@@ -36,6 +38,9 @@ import (
 	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
 	"d7y.io/dragonfly/v2/client/daemon/test"
 	"d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/source/clients/httpprotocol"
+	sourcemocks "d7y.io/dragonfly/v2/pkg/source/mocks"
 )
 
 func TestReuseFilePeerTask(t *testing.T) {
@@ -703,3 +708,76 @@ func TestReuseStreamPeerTask(t *testing.T) {
 		})
 	}
 }
+
+func TestPeerTaskManager_revalidate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sourceClient := sourcemocks.NewMockResourceClient(ctrl)
+	source.UnRegister("http")
+	testifyrequire.Nil(t, source.Register("http", sourceClient, httpprotocol.Adapter))
+	defer source.UnRegister("http")
+
+	expireInfo := &source.ExpireInfo{
+		LastModified: "Sun, 06 Jun 2021 12:52:30 GMT",
+		ETag:         "foo",
+	}
+
+	var testCases = []struct {
+		name           string
+		expireInfo     *source.ExpireInfo
+		mock           func(sm *mocks.MockManager)
+		expectReusable bool
+	}{
+		{
+			name:           "revalidation is not required",
+			mock:           func(sm *mocks.MockManager) {},
+			expectReusable: true,
+		},
+		{
+			name:       "not modified in origin",
+			expireInfo: expireInfo,
+			mock: func(sm *mocks.MockManager) {
+				sourceClient.EXPECT().IsExpired(gomock.Any(), expireInfo).Return(false, nil)
+				sm.EXPECT().MarkRevalidated("task-revalidate", false).Return(nil)
+			},
+			expectReusable: true,
+		},
+		{
+			name:       "modified in origin",
+			expireInfo: expireInfo,
+			mock: func(sm *mocks.MockManager) {
+				sourceClient.EXPECT().IsExpired(gomock.Any(), expireInfo).Return(true, nil)
+				sm.EXPECT().MarkRevalidated("task-revalidate", true).Return(nil)
+			},
+			expectReusable: false,
+		},
+		{
+			name:       "origin is unreachable",
+			expireInfo: expireInfo,
+			mock: func(sm *mocks.MockManager) {
+				sourceClient.EXPECT().IsExpired(gomock.Any(), expireInfo).Return(false, errors.New("foo"))
+			},
+			expectReusable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sm := mocks.NewMockManager(ctrl)
+			tc.mock(sm)
+			ptm := &peerTaskManager{
+				host:           &schedulerv1.PeerHost{},
+				storageManager: sm,
+			}
+			reusable := ptm.revalidate(context.Background(), "http://example.com/1", &commonv1.UrlMeta{}, &storage.ReusePeerTask{
+				PeerTaskMetadata: storage.PeerTaskMetadata{
+					PeerID: "peer-revalidate",
+					TaskID: "task-revalidate",
+				},
+				ExpireInfo: tc.expireInfo,
+			})
+			testifyassert.Equal(t, tc.expectReusable, reusable)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+)
+
+// TaskStatus is the progress summary of a running peer task.
+type TaskStatus struct {
+	TaskID string
+	PeerID string
+	URL    string
+	Seed   bool
+
+	// ContentLength is -1 when it is not known yet
+	ContentLength   int64
+	CompletedLength int64
+
+	// TotalPieces is -1 when it is not known yet
+	TotalPieces     int32
+	CompletedPieces int32
+
+	// Subscribers is the count of local requests sharing the peer task
+	Subscribers int32
+
+	// ETA is the estimated remaining time of downloading pushed by scheduler,
+	// it is zero when it is not estimated yet
+	ETA time.Duration
+
+	StartTime time.Time
+}
+
+func (pt *peerTaskConductor) status() *TaskStatus {
+	return &TaskStatus{
+		TaskID:          pt.taskID,
+		PeerID:          pt.peerID,
+		URL:             pt.request.Url,
+		Seed:            pt.seed,
+		ContentLength:   pt.GetContentLength(),
+		CompletedLength: pt.completedLength.Load(),
+		TotalPieces:     pt.GetTotalPieces(),
+		CompletedPieces: pt.readyPieces.Settled(),
+		Subscribers:     pt.subscribers.Load(),
+		ETA:             pt.eta.Load(),
+		StartTime:       pt.startTime,
+	}
+}
+
+// watchETA receives the estimated remaining time of downloading from scheduler until the peer task is done.
+func (pt *peerTaskConductor) watchETA() {
+	ctx, cancel := context.WithCancel(pt.ctx)
+	defer cancel()
+	defer pt.eta.Store(0)
+
+	go func() {
+		select {
+		case <-pt.successCh:
+		case <-pt.failCh:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	stream, err := pt.schedulerClient.WatchPeerETA(ctx, &schedulerv1.PeerTarget{
+		TaskId: pt.taskID,
+		PeerId: pt.peerID,
+	})
+	if err != nil {
+		pt.logWatchETAError(err)
+		return
+	}
+
+	for {
+		eta, err := stream.Recv()
+		if err != nil {
+			pt.logWatchETAError(err)
+			return
+		}
+		pt.eta.Store(eta)
+	}
+}
+
+func (pt *peerTaskConductor) logWatchETAError(err error) {
+	// old schedulers do not serve estimator
+	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+		return
+	}
+	switch status.Code(err) {
+	case codes.Canceled, codes.Unimplemented:
+		return
+	}
+	pt.Debugf("watch eta error: %s", err)
+}
+
+// subscribe registers a local request to the peer task, simultaneous requests of
+// the same task share the pieces downloaded by one conductor instead of downloading them again.
+func (pt *peerTaskConductor) subscribe() chan *PieceInfo {
+	pt.subscribers.Inc()
+	return pt.broker.Subscribe()
+}
+
+// unsubscribe unregisters a local request from the peer task.
+func (pt *peerTaskConductor) unsubscribe(pieceCh chan *PieceInfo) {
+	pt.subscribers.Dec()
+	pt.broker.Unsubscribe(pieceCh)
+}
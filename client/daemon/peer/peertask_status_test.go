@@ -0,0 +1,124 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	testifyassert "github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	schedulerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client/mocks"
+)
+
+func TestPeerTaskConductor_subscribe(t *testing.T) {
+	assert := testifyassert.New(t)
+	pt := &peerTaskConductor{
+		broker:      newPieceBroker(),
+		subscribers: atomic.NewInt32(0),
+	}
+	go pt.broker.Start()
+
+	// simultaneous requests share the pieces of one conductor
+	ch1 := pt.subscribe()
+	ch2 := pt.subscribe()
+	assert.Equal(int32(2), pt.subscribers.Load())
+
+	pt.broker.Publish(&PieceInfo{Num: 0})
+	for _, ch := range []chan *PieceInfo{ch1, ch2} {
+		select {
+		case piece := <-ch:
+			assert.Equal(int32(0), piece.Num)
+		case <-time.After(time.Second):
+			assert.Fail("piece not received")
+		}
+	}
+
+	pt.unsubscribe(ch1)
+	assert.Equal(int32(1), pt.subscribers.Load())
+
+	pt.broker.Publish(&PieceInfo{Num: 1})
+	select {
+	case piece := <-ch2:
+		assert.Equal(int32(1), piece.Num)
+	case <-time.After(time.Second):
+		assert.Fail("piece not received")
+	}
+	assert.Len(ch1, 0)
+
+	// unsubscribe does not block after the broker stopped
+	pt.broker.Stop()
+	pt.unsubscribe(ch2)
+	assert.Equal(int32(0), pt.subscribers.Load())
+}
+
+func TestPeerTaskConductor_watchETA(t *testing.T) {
+	assert := testifyassert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	received, release := make(chan struct{}), make(chan struct{})
+	stream := schedulerclientmocks.NewMockPeerETAStream(ctrl)
+	gomock.InOrder(
+		stream.EXPECT().Recv().Return(5*time.Second, nil),
+		stream.EXPECT().Recv().DoAndReturn(func() (time.Duration, error) {
+			close(received)
+			<-release
+			return 0, io.EOF
+		}),
+	)
+	schedulerClient := schedulerclientmocks.NewMockClient(ctrl)
+	schedulerClient.EXPECT().WatchPeerETA(gomock.Any(), gomock.Any()).Return(stream, nil)
+
+	pt := &peerTaskConductor{
+		SugaredLoggerOnWith: logger.With("peer", "test"),
+		ctx:                 context.Background(),
+		taskID:              "task",
+		peerID:              "peer",
+		schedulerClient:     schedulerClient,
+		eta:                 atomic.NewDuration(0),
+		successCh:           make(chan struct{}),
+		failCh:              make(chan struct{}),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		pt.watchETA()
+		close(done)
+	}()
+
+	select {
+	case <-received:
+		assert.Equal(5*time.Second, pt.eta.Load())
+	case <-time.After(time.Second):
+		assert.Fail("eta not received")
+	}
+	close(release)
+
+	select {
+	case <-done:
+		// eta is reset after the stream closed
+		assert.Equal(time.Duration(0), pt.eta.Load())
+	case <-time.After(time.Second):
+		assert.Fail("watch eta not returned")
+	}
+}
@@ -47,6 +47,9 @@ type StreamTaskRequest struct {
 	PeerID string
 	// Pattern to register to scheduler
 	Pattern commonv1.Pattern
+	// CancelOnDisconnect cancels the peer task started by this request when the request context is done
+	// before the content is read, and no other local request shares the peer task
+	CancelOnDisconnect bool
 }
 
 // StreamTask represents a peer task with stream io for reading directly without once more disk io
@@ -63,6 +66,8 @@ type streamTask struct {
 	span              trace.Span
 	peerTaskConductor *peerTaskConductor
 	pieceCh           chan *PieceInfo
+
+	cancelOnDisconnect bool
 }
 
 func (ptm *peerTaskManager) newStreamTask(
@@ -93,7 +98,7 @@ func (ptm *peerTaskManager) newStreamTask(
 		ctx:                 ctx,
 		span:                span,
 		peerTaskConductor:   ptc,
-		pieceCh:             ptc.broker.Subscribe(),
+		pieceCh:             ptc.subscribe(),
 	}
 	return pt, nil
 }
@@ -102,6 +107,17 @@ func (s *streamTask) Start(ctx context.Context) (io.ReadCloser, map[string]strin
 	// wait first piece to get content length and attribute (eg, response header for http/https)
 	var firstPiece *PieceInfo
 
+	// the piece channel is released by writeToPipe after the pipe started
+	var piped, disconnected bool
+	defer func() {
+		if !piped {
+			s.peerTaskConductor.unsubscribe(s.pieceCh)
+		}
+		if disconnected {
+			s.cancelAbandoned()
+		}
+	}()
+
 	attr := map[string]string{}
 	attr[config.HeaderDragonflyTask] = s.peerTaskConductor.taskID
 	attr[config.HeaderDragonflyPeer] = s.peerTaskConductor.peerID
@@ -110,6 +126,7 @@ func (s *streamTask) Start(ctx context.Context) (io.ReadCloser, map[string]strin
 		s.Errorf("%s", ctx.Err())
 		s.span.RecordError(ctx.Err())
 		s.span.End()
+		disconnected = true
 		return nil, attr, ctx.Err()
 	case <-s.peerTaskConductor.failCh:
 		err := s.peerTaskConductor.getFailedError()
@@ -161,6 +178,7 @@ func (s *streamTask) Start(ctx context.Context) (io.ReadCloser, map[string]strin
 
 	pr, pw := io.Pipe()
 	var readCloser io.ReadCloser = pr
+	piped = true
 	go s.writeToPipe(firstPiece, pw)
 
 	return readCloser, attr, nil
@@ -188,7 +206,12 @@ func (s *streamTask) writeOnePiece(w io.Writer, pieceNum int32) (int64, error) {
 }
 
 func (s *streamTask) writeToPipe(firstPiece *PieceInfo, pw *io.PipeWriter) {
+	var disconnected bool
 	defer func() {
+		s.peerTaskConductor.unsubscribe(s.pieceCh)
+		if disconnected {
+			s.cancelAbandoned()
+		}
 		s.span.End()
 	}()
 	var (
@@ -215,6 +238,7 @@ func (s *streamTask) writeToPipe(firstPiece *PieceInfo, pw *io.PipeWriter) {
 			err = fmt.Errorf("context done due to: %s", s.ctx.Err())
 			s.Errorf(err.Error())
 			s.closeWithError(pw, err)
+			disconnected = true
 			return
 		case <-s.peerTaskConductor.failCh:
 			err = fmt.Errorf("stream close with peer task fail: %d/%s",
@@ -276,6 +300,21 @@ func (s *streamTask) writeRemainingPieces(desired int32, pw *io.PipeWriter) {
 	}
 }
 
+// cancelAbandoned cancels the peer task after the request is gone,
+// it must be called after unsubscribing, otherwise the request itself is counted as a subscriber.
+func (s *streamTask) cancelAbandoned() {
+	if !s.cancelOnDisconnect {
+		return
+	}
+
+	ptc := s.peerTaskConductor
+	if err := ptc.peerTaskManager.cancelPeerTask(context.Background(), ptc, false); err != nil {
+		s.Infof("skip canceling abandoned peer task: %s", err)
+		return
+	}
+	s.Infof("abandoned peer task canceled")
+}
+
 func (s *streamTask) closeWithError(pw *io.PipeWriter, err error) {
 	s.Error(err)
 	s.span.RecordError(err)
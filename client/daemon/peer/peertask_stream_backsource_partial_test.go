@@ -179,6 +179,7 @@ func setupBackSourcePartialComponents(ctrl *gomock.Controller, testBytes []byte,
 		func(ctx context.Context, pr *schedulerv1.PeerResult, opts ...grpc.CallOption) error {
 			return nil
 		})
+	sched.EXPECT().WatchPeerETA(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, status.Error(codes.Unimplemented, "estimator is not available"))
 	tempDir, _ := os.MkdirTemp("", "d7y-test-*")
 	storageManager, _ := storage.NewStorageManager(
 		config.SimpleLocalTaskStoreStrategy,
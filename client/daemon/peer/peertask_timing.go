@@ -0,0 +1,233 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"d7y.io/dragonfly/v2/client/config"
+)
+
+const (
+	// TaskTimingStateRunning is the state of running peer task.
+	TaskTimingStateRunning = "running"
+
+	// TaskTimingStateSucceeded is the state of succeeded peer task.
+	TaskTimingStateSucceeded = "succeeded"
+
+	// TaskTimingStateFailed is the state of failed peer task.
+	TaskTimingStateFailed = "failed"
+)
+
+const (
+	// sourcePeerID is the key of back-to-source bytes in peer bytes of task timing.
+	sourcePeerID = "source"
+
+	// maxTaskTimingHistory is the max count of finished task timings kept in memory.
+	maxTaskTimingHistory = 1024
+)
+
+// TaskTiming is the timing summary of a peer task.
+type TaskTiming struct {
+	TaskID     string    `json:"task_id"`
+	PeerID     string    `json:"peer_id"`
+	State      string    `json:"state"`
+	BackSource bool      `json:"back_source"`
+	StartTime  time.Time `json:"start_time"`
+
+	// ScheduleWait is the milliseconds from start to the first schedule result.
+	ScheduleWait int64 `json:"schedule_wait_ms"`
+
+	// FirstPiece is the milliseconds from start to the first downloaded piece.
+	FirstPiece int64 `json:"first_piece_ms"`
+
+	// Cost is the milliseconds from start to finish, it is the elapsed time when task is running.
+	Cost int64 `json:"cost_ms"`
+
+	// PieceRetries is the count of failed piece downloads which are retried.
+	PieceRetries int64 `json:"piece_retries"`
+
+	// PeerBytes is the downloaded bytes by peer id, back-to-source bytes are keyed by "source".
+	PeerBytes map[string]int64 `json:"peer_bytes"`
+}
+
+// taskTimingRecorder records the timing summary of a peer task.
+type taskTimingRecorder struct {
+	mu     sync.Mutex
+	timing TaskTiming
+}
+
+// newTaskTimingRecorder returns a new taskTimingRecorder instance.
+func newTaskTimingRecorder(taskID, peerID string, startTime time.Time) *taskTimingRecorder {
+	return &taskTimingRecorder{
+		timing: TaskTiming{
+			TaskID:    taskID,
+			PeerID:    peerID,
+			State:     TaskTimingStateRunning,
+			StartTime: startTime,
+			PeerBytes: map[string]int64{},
+		},
+	}
+}
+
+// scheduled records the first schedule result.
+func (r *taskTimingRecorder) scheduled() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timing.ScheduleWait == 0 {
+		r.timing.ScheduleWait = r.elapsed()
+	}
+}
+
+// pieceDownloaded records the first downloaded piece.
+func (r *taskTimingRecorder) pieceDownloaded() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.timing.FirstPiece == 0 {
+		r.timing.FirstPiece = r.elapsed()
+	}
+}
+
+// addPeerBytes adds downloaded bytes of the peer, empty peer id stands for back-to-source.
+func (r *taskTimingRecorder) addPeerBytes(peerID string, n int64) {
+	if peerID == "" {
+		peerID = sourcePeerID
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.PeerBytes[peerID] += n
+}
+
+// addPieceRetry adds a failed piece download.
+func (r *taskTimingRecorder) addPieceRetry() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.PieceRetries++
+}
+
+// markBackSource marks the task downloading from source.
+func (r *taskTimingRecorder) markBackSource() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timing.BackSource = true
+}
+
+// finish sets the final state of task and records the summary in span.
+func (r *taskTimingRecorder) finish(success bool, span trace.Span) *TaskTiming {
+	r.mu.Lock()
+	if success {
+		r.timing.State = TaskTimingStateSucceeded
+	} else {
+		r.timing.State = TaskTimingStateFailed
+	}
+	r.timing.Cost = r.elapsed()
+	r.mu.Unlock()
+
+	timing := r.snapshot()
+	span.SetAttributes(config.AttributeScheduleWait.Int64(timing.ScheduleWait))
+	span.SetAttributes(config.AttributeFirstPieceCost.Int64(timing.FirstPiece))
+	span.SetAttributes(config.AttributePieceRetries.Int64(timing.PieceRetries))
+	for peerID, n := range timing.PeerBytes {
+		span.AddEvent("peer bytes", trace.WithAttributes(
+			config.AttributeTargetPeerID.String(peerID),
+			config.AttributePeerBytes.Int64(n)))
+	}
+
+	return timing
+}
+
+// snapshot returns a copy of current timing summary.
+func (r *taskTimingRecorder) snapshot() *TaskTiming {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	timing := r.timing
+	if timing.State == TaskTimingStateRunning {
+		timing.Cost = r.elapsed()
+	}
+
+	timing.PeerBytes = make(map[string]int64, len(r.timing.PeerBytes))
+	for peerID, n := range r.timing.PeerBytes {
+		timing.PeerBytes[peerID] = n
+	}
+
+	return &timing
+}
+
+// elapsed returns milliseconds since start, it is at least 1 to tell from unrecorded values.
+func (r *taskTimingRecorder) elapsed() int64 {
+	if ms := time.Since(r.timing.StartTime).Milliseconds(); ms > 0 {
+		return ms
+	}
+
+	return 1
+}
+
+// taskTimingHistory keeps timing summaries of recently finished tasks.
+type taskTimingHistory struct {
+	mu       sync.Mutex
+	timings  map[string]*TaskTiming
+	taskIDs  []string
+	capacity int
+}
+
+// newTaskTimingHistory returns a new taskTimingHistory instance.
+func newTaskTimingHistory(capacity int) *taskTimingHistory {
+	return &taskTimingHistory{
+		timings:  map[string]*TaskTiming{},
+		capacity: capacity,
+	}
+}
+
+// store stores the timing summary, the oldest one is evicted when history is full.
+func (h *taskTimingHistory) store(timing *TaskTiming) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.timings[timing.TaskID]; !ok {
+		if len(h.taskIDs) >= h.capacity {
+			delete(h.timings, h.taskIDs[0])
+			h.taskIDs = h.taskIDs[1:]
+		}
+		h.taskIDs = append(h.taskIDs, timing.TaskID)
+	}
+
+	h.timings[timing.TaskID] = timing
+}
+
+// load returns the timing summary of task.
+func (h *taskTimingHistory) load(taskID string) (*TaskTiming, bool) {
+	if h == nil {
+		return nil, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	timing, ok := h.timings[taskID]
+	return timing, ok
+}
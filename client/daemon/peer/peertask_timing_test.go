@@ -0,0 +1,92 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTaskTimingRecorder(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	recorder := newTaskTimingRecorder("task", "peer", time.Now().Add(-time.Second))
+	timing := recorder.snapshot()
+	assert.Equal(TaskTimingStateRunning, timing.State)
+	assert.Equal(int64(0), timing.ScheduleWait)
+	assert.Equal(int64(0), timing.FirstPiece)
+	assert.GreaterOrEqual(timing.Cost, int64(1000))
+
+	recorder.scheduled()
+	recorder.pieceDownloaded()
+	recorder.addPeerBytes("parent", 100)
+	recorder.addPeerBytes("parent", 200)
+	recorder.addPeerBytes("", 50)
+	recorder.addPieceRetry()
+	recorder.markBackSource()
+
+	timing = recorder.snapshot()
+	scheduleWait, firstPiece := timing.ScheduleWait, timing.FirstPiece
+	assert.GreaterOrEqual(scheduleWait, int64(1000))
+	assert.GreaterOrEqual(firstPiece, int64(1000))
+	assert.Equal(map[string]int64{"parent": 300, sourcePeerID: 50}, timing.PeerBytes)
+	assert.Equal(int64(1), timing.PieceRetries)
+	assert.True(timing.BackSource)
+
+	// only the first schedule result and piece are recorded
+	time.Sleep(10 * time.Millisecond)
+	recorder.scheduled()
+	recorder.pieceDownloaded()
+	timing = recorder.finish(true, trace.SpanFromContext(context.Background()))
+	assert.Equal(TaskTimingStateSucceeded, timing.State)
+	assert.Equal(scheduleWait, timing.ScheduleWait)
+	assert.Equal(firstPiece, timing.FirstPiece)
+
+	// snapshot is a copy
+	timing.PeerBytes["parent"] = 0
+	assert.Equal(int64(300), recorder.snapshot().PeerBytes["parent"])
+}
+
+func TestTaskTimingHistory(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	history := newTaskTimingHistory(2)
+	history.store(&TaskTiming{TaskID: "foo"})
+	history.store(&TaskTiming{TaskID: "bar"})
+	history.store(&TaskTiming{TaskID: "foo", State: TaskTimingStateFailed})
+
+	timing, ok := history.load("foo")
+	assert.True(ok)
+	assert.Equal(TaskTimingStateFailed, timing.State)
+
+	history.store(&TaskTiming{TaskID: "baz"})
+	_, ok = history.load("foo")
+	assert.False(ok)
+	_, ok = history.load("bar")
+	assert.True(ok)
+	_, ok = history.load("baz")
+	assert.True(ok)
+
+	var empty *taskTimingHistory
+	empty.store(&TaskTiming{TaskID: "foo"})
+	_, ok = empty.load("foo")
+	assert.False(ok)
+}
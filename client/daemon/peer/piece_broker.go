@@ -98,7 +98,10 @@ func (b *pieceBroker) Subscribe() chan *PieceInfo {
 }
 
 func (b *pieceBroker) Unsubscribe(msgCh chan *PieceInfo) {
-	b.unsubCh <- msgCh
+	select {
+	case <-b.stopCh:
+	case b.unsubCh <- msgCh:
+	}
 }
 
 func (b *pieceBroker) Publish(msg *PieceInfo) {
@@ -20,22 +20,30 @@ package peer
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
+	"github.com/go-http-utils/headers"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/net/http2"
 	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/internal/uploadauth"
 	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/net/netns"
 	"d7y.io/dragonfly/v2/pkg/source"
 )
 
@@ -43,6 +51,7 @@ type DownloadPieceRequest struct {
 	piece      *commonv1.PieceInfo
 	log        *logger.SugaredLoggerOnWith
 	storage    storage.TaskStorageDriver
+	limiter    *destPeerLimiter
 	TaskID     string
 	PeerID     string
 	DstPid     string
@@ -66,6 +75,17 @@ type PieceDownloader interface {
 type pieceDownloader struct {
 	transport  http.RoundTripper
 	httpClient *http.Client
+	// tlsConfig enables https with client certificate when downloading pieces
+	tlsConfig *tls.Config
+	// authSecret signs the per task tokens of piece downloading
+	authSecret string
+	tokenTTL   time.Duration
+	// localTransport dials the parents on the same host by unix sockets
+	localTransport *LocalTransport
+	// network binds the connections to parents to the network interface in the net namespace
+	network config.NetworkOption
+	// http2 multiplexes piece requests to the same parent over one connection
+	http2 bool
 }
 
 type pieceDownloadError struct {
@@ -95,14 +115,22 @@ func isPieceNotFound(err error) bool {
 	return false
 }
 
-func isBackSourceError(err error) bool {
-	if _, ok := err.(*backSourceError); ok {
-		return true
+// isPieceThrottled indicates the parent is busy with requests of this peer,
+// the piece should be downloaded later instead of reporting the parent failed.
+func isPieceThrottled(err error) bool {
+	if e, ok := err.(*pieceDownloadError); ok {
+		return e.statusCode == http.StatusTooManyRequests
 	}
-	if _, ok := err.(*source.UnexpectedStatusCodeError); ok {
+	return false
+}
+
+func isBackSourceError(err error) bool {
+	var bse *backSourceError
+	if errors.As(err, &bse) {
 		return true
 	}
-	return false
+	var usce *source.UnexpectedStatusCodeError
+	return errors.As(err, &usce)
 }
 
 func (e *pieceDownloadError) Error() string {
@@ -121,13 +149,22 @@ func (e *backSourceError) Error() string {
 
 var _ PieceDownloader = (*pieceDownloader)(nil)
 
+var defaultDialer = &net.Dialer{
+	Timeout:   2 * time.Second,
+	KeepAlive: 30 * time.Second,
+	DualStack: true,
+}
+
+const (
+	// http2ReadIdleTimeout is the interval of health check by ping when no frame is received in http2 connection
+	http2ReadIdleTimeout = 15 * time.Second
+	// http2PingTimeout is the timeout of ping, the connection is closed after timeout
+	http2PingTimeout = 5 * time.Second
+)
+
 var defaultTransport http.RoundTripper = &http.Transport{
-	Proxy: http.ProxyFromEnvironment,
-	DialContext: (&net.Dialer{
-		Timeout:   2 * time.Second,
-		KeepAlive: 30 * time.Second,
-		DualStack: true,
-	}).DialContext,
+	Proxy:                 http.ProxyFromEnvironment,
+	DialContext:           defaultDialer.DialContext,
 	MaxIdleConns:          100,
 	IdleConnTimeout:       90 * time.Second,
 	ResponseHeaderTimeout: 2 * time.Second,
@@ -148,6 +185,57 @@ func NewPieceDownloader(timeout time.Duration, opts ...func(*pieceDownloader) er
 		pd.transport = defaultTransport
 	}
 
+	if pd.tlsConfig != nil {
+		transport, ok := pd.transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("tls is not supported by transport %T", pd.transport)
+		}
+
+		transport = transport.Clone()
+		transport.TLSClientConfig = pd.tlsConfig
+		pd.transport = transport
+	}
+
+	if pd.network.Interface != "" || pd.network.Namespace != "" {
+		transport, ok := pd.transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("piece network is not supported by transport %T", pd.transport)
+		}
+
+		transport = transport.Clone()
+		transport.DialContext = networkDialContext(pd.network)
+		pd.transport = transport
+	}
+
+	if pd.localTransport != nil {
+		transport, ok := pd.transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("local transport is not supported by transport %T", pd.transport)
+		}
+
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+
+		transport = transport.Clone()
+		transport.DialContext = pd.localTransport.DialContext(dial)
+		pd.transport = transport
+	}
+
+	if pd.http2 {
+		transport, ok := pd.transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("http2 is not supported by transport %T", pd.transport)
+		}
+
+		rt, err := newHTTP2Transport(transport, pd.tlsConfig != nil)
+		if err != nil {
+			return nil, err
+		}
+		pd.transport = rt
+	}
+
 	pd.httpClient = &http.Client{
 		Transport: pd.transport,
 		Timeout:   timeout,
@@ -162,8 +250,143 @@ func WithTransport(rt http.RoundTripper) func(*pieceDownloader) error {
 	}
 }
 
+// WithNetwork binds the connections of piece downloading to the network interface in the net namespace,
+// like the interface of storage network, the local transport is not affected.
+func WithNetwork(network config.NetworkOption) func(*pieceDownloader) error {
+	return func(d *pieceDownloader) error {
+		d.network = network
+		return nil
+	}
+}
+
+// networkDialContext returns the dial function which creates sockets in the net namespace
+// and binds them to the network interface.
+func networkDialContext(network config.NetworkOption) func(ctx context.Context, netw, addr string) (net.Conn, error) {
+	dialer := *defaultDialer
+	if network.Interface != "" {
+		dialer.Control = netns.BindToDevice(network.Interface)
+	}
+
+	return func(ctx context.Context, netw, addr string) (conn net.Conn, err error) {
+		err = netns.Do(network.Namespace, func() error {
+			conn, err = dialer.DialContext(ctx, netw, addr)
+			return err
+		})
+		return conn, err
+	}
+}
+
+// WithTLSConfig downloads pieces with https, the client certificate in tlsConfig is used in mutual tls.
+func WithTLSConfig(tlsConfig *tls.Config) func(*pieceDownloader) error {
+	return func(d *pieceDownloader) error {
+		d.tlsConfig = tlsConfig
+		return nil
+	}
+}
+
+// WithLocalTransport downloads pieces from the parents on the same host by unix sockets.
+func WithLocalTransport(localTransport *LocalTransport) func(*pieceDownloader) error {
+	return func(d *pieceDownloader) error {
+		d.localTransport = localTransport
+		return nil
+	}
+}
+
+// WithHTTP2 downloads pieces with http2, the piece requests to the same parent are multiplexed over one connection.
+func WithHTTP2(enable bool) func(*pieceDownloader) error {
+	return func(d *pieceDownloader) error {
+		d.http2 = enable
+		return nil
+	}
+}
+
+// newHTTP2Transport returns the http2 transport based on transport, h2 is negotiated by alpn when tls is enabled
+// and falls back to http/1.1, otherwise h2c is used with prior knowledge and falls back to http/1.1
+// for the parents which do not serve h2c.
+func newHTTP2Transport(transport *http.Transport, withTLS bool) (http.RoundTripper, error) {
+	if withTLS {
+		transport = transport.Clone()
+		h2, err := http2.ConfigureTransports(transport)
+		if err != nil {
+			return nil, err
+		}
+
+		h2.ReadIdleTimeout = http2ReadIdleTimeout
+		h2.PingTimeout = http2PingTimeout
+		return transport, nil
+	}
+
+	dial := transport.DialContext
+	if dial == nil {
+		dial = defaultDialer.DialContext
+	}
+
+	return &h2cTransport{
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			// Dial plaintext connections for h2c, the tls config is ignored.
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(context.Background(), network, addr)
+			},
+			ReadIdleTimeout: http2ReadIdleTimeout,
+			PingTimeout:     http2PingTimeout,
+		},
+		http1: transport,
+	}, nil
+}
+
+// h2cTransport sends requests with h2c prior knowledge, and falls back to http/1.1
+// when the parent fails the h2c preface, e.g. http2 is not enabled in the parent.
+type h2cTransport struct {
+	h2c   *http2.Transport
+	http1 http.RoundTripper
+
+	// http1Hosts is the hosts of parents which only serve http/1.1.
+	http1Hosts sync.Map
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, ok := t.http1Hosts.Load(req.URL.Host); ok {
+		return t.http1.RoundTrip(req)
+	}
+
+	resp, err := t.h2c.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+
+	// Only the requests without body can be sent again.
+	if (req.Body != nil && req.Body != http.NoBody) || req.Context().Err() != nil {
+		return nil, err
+	}
+
+	resp, http1Err := t.http1.RoundTrip(req)
+	if http1Err != nil {
+		return nil, err
+	}
+
+	// Parent serves http/1.1 but not h2c, send the requests with http/1.1 from now on.
+	logger.Infof("parent %s does not serve h2c, fall back to http/1.1: %s", req.URL.Host, err)
+	t.http1Hosts.Store(req.URL.Host, struct{}{})
+	return resp, nil
+}
+
+// WithAuthToken signs per task tokens with secret when downloading pieces.
+func WithAuthToken(secret string, ttl time.Duration) func(*pieceDownloader) error {
+	return func(d *pieceDownloader) error {
+		d.authSecret = secret
+		d.tokenTTL = ttl
+		return nil
+	}
+}
+
 func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceRequest) (io.Reader, io.Closer, error) {
-	httpRequest := buildDownloadPieceHTTPRequest(ctx, req)
+	httpRequest, err := p.buildDownloadPieceHTTPRequest(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	resp, err := p.httpClient.Do(httpRequest)
 	if err != nil {
 		logger.Errorf("task id: %s, piece num: %d, dst: %s, download piece failed: %s",
@@ -198,10 +421,14 @@ func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceR
 	return reader, closer, nil
 }
 
-func buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest) *http.Request {
-	// FIXME switch to https when tls enabled
+func (p *pieceDownloader) buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest) (*http.Request, error) {
+	scheme := "http"
+	if p.tlsConfig != nil {
+		scheme = "https"
+	}
+
 	targetURL := url.URL{
-		Scheme:   "http",
+		Scheme:   scheme,
 		Host:     d.DstAddr,
 		Path:     fmt.Sprintf("download/%s/%s", d.TaskID[:3], d.TaskID),
 		RawQuery: fmt.Sprintf("peerId=%s", d.DstPid),
@@ -214,7 +441,15 @@ func buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest)
 	req.Header.Add("Range", fmt.Sprintf("bytes=%d-%d",
 		d.piece.RangeStart, d.piece.RangeStart+uint64(d.piece.RangeSize)-1))
 
+	if p.authSecret != "" {
+		token, err := uploadauth.GenerateToken(p.authSecret, d.TaskID, p.tokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(headers.Authorization, uploadauth.BearerToken(token))
+	}
+
 	// inject trace id into request header
 	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
-	return req
+	return req, nil
 }
@@ -19,26 +19,34 @@ package peer
 import (
 	"context"
 	"crypto/md5"
+	"crypto/tls"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"math"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-http-utils/headers"
 	testifyassert "github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/test"
 	"d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/internal/uploadauth"
 	"d7y.io/dragonfly/v2/pkg/source"
 	"d7y.io/dragonfly/v2/pkg/source/clients/httpprotocol"
 )
@@ -160,3 +168,270 @@ func TestPieceDownloader_DownloadPiece(t *testing.T) {
 		server.Close()
 	}
 }
+
+func TestPieceDownloader_DownloadPieceWithTLSAndToken(t *testing.T) {
+	assert := testifyassert.New(t)
+	data := []byte("test test ")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get(headers.Authorization), "Bearer ")
+		if err := uploadauth.VerifyToken("secret", token, "task-0"); err != nil {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	addr, _ := url.Parse(server.URL)
+	tlsConfig := server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+
+	tests := []struct {
+		name   string
+		secret string
+		expect func(t *testing.T, r io.Reader, c io.Closer, err error)
+	}{
+		{
+			name:   "download piece with valid token",
+			secret: "secret",
+			expect: func(t *testing.T, r io.Reader, c io.Closer, err error) {
+				assert := testifyassert.New(t)
+				assert.NoError(err)
+				defer c.Close()
+				content, err := io.ReadAll(r)
+				assert.NoError(err)
+				assert.Equal(data, content)
+			},
+		},
+		{
+			name:   "download piece with invalid token",
+			secret: "foo",
+			expect: func(t *testing.T, r io.Reader, c io.Closer, err error) {
+				assert := testifyassert.New(t)
+				assert.Error(err)
+				assert.Equal(http.StatusForbidden, err.(*pieceDownloadError).statusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pd, err := NewPieceDownloader(30*time.Second, WithTLSConfig(tlsConfig), WithAuthToken(tc.secret, time.Minute))
+			assert.NoError(err)
+			r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+				TaskID:  "task-0",
+				DstAddr: addr.Host,
+				piece: &commonv1.PieceInfo{
+					RangeStart: 0,
+					RangeSize:  uint32(len(data)),
+				},
+				log: logger.With("test", "test"),
+			})
+			tc.expect(t, r, c, err)
+		})
+	}
+}
+
+func TestPieceDownloader_DownloadPieceWithHTTP2(t *testing.T) {
+	data := []byte("test test ")
+	handler := func(remoteAddrs map[string]struct{}) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ProtoMajor != 2 {
+				w.WriteHeader(http.StatusHTTPVersionNotSupported)
+				return
+			}
+
+			remoteAddrs[r.RemoteAddr] = struct{}{}
+			w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+			if _, err := w.Write(data); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	tests := []struct {
+		name      string
+		newServer func(handler http.Handler) (*httptest.Server, *tls.Config)
+	}{
+		{
+			name: "download pieces with h2c",
+			newServer: func(handler http.Handler) (*httptest.Server, *tls.Config) {
+				return httptest.NewServer(h2c.NewHandler(handler, &http2.Server{})), nil
+			},
+		},
+		{
+			name: "download pieces with h2",
+			newServer: func(handler http.Handler) (*httptest.Server, *tls.Config) {
+				server := httptest.NewUnstartedServer(handler)
+				server.EnableHTTP2 = true
+				server.StartTLS()
+				return server, server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			remoteAddrs := map[string]struct{}{}
+			server, tlsConfig := tc.newServer(handler(remoteAddrs))
+			defer server.Close()
+			addr, _ := url.Parse(server.URL)
+
+			pd, err := NewPieceDownloader(30*time.Second, WithTLSConfig(tlsConfig), WithHTTP2(true))
+			assert.NoError(err)
+			for i := 0; i < 3; i++ {
+				r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+					TaskID:  "task-0",
+					DstAddr: addr.Host,
+					piece: &commonv1.PieceInfo{
+						PieceNum:   int32(i),
+						RangeStart: 0,
+						RangeSize:  uint32(len(data)),
+					},
+					log: logger.With("test", "test"),
+				})
+				assert.NoError(err)
+				content, err := io.ReadAll(r)
+				assert.NoError(err)
+				assert.Equal(data, content)
+				c.Close()
+			}
+
+			// pieces are multiplexed over one connection
+			assert.Len(remoteAddrs, 1)
+		})
+	}
+}
+
+func TestPieceDownloader_DownloadPieceWithHTTP2Fallback(t *testing.T) {
+	assert := testifyassert.New(t)
+	data := []byte("test test ")
+	var protoMajors []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// h2c preface is received as a request without h2c handler
+		if r.Method == "PRI" {
+			w.WriteHeader(http.StatusHTTPVersionNotSupported)
+			return
+		}
+
+		protoMajors = append(protoMajors, r.ProtoMajor)
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	addr, _ := url.Parse(server.URL)
+
+	// parent serves http/1.1 only
+	pd, err := NewPieceDownloader(30*time.Second, WithHTTP2(true))
+	assert.NoError(err)
+	for i := 0; i < 3; i++ {
+		r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+			TaskID:  "task-0",
+			DstAddr: addr.Host,
+			piece: &commonv1.PieceInfo{
+				PieceNum:   int32(i),
+				RangeStart: 0,
+				RangeSize:  uint32(len(data)),
+			},
+			log: logger.With("test", "test"),
+		})
+		assert.NoError(err)
+		content, err := io.ReadAll(r)
+		assert.NoError(err)
+		assert.Equal(data, content)
+		c.Close()
+	}
+
+	assert.Equal([]int{1, 1, 1}, protoMajors)
+}
+
+func TestPieceDownloader_DownloadPieceWithNetwork(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("binding to network interface is only supported in linux")
+	}
+
+	data := []byte("test test ")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	addr, _ := url.Parse(server.URL)
+
+	ifaces, err := net.Interfaces()
+	require.Nil(t, err)
+	var loopback string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			loopback = iface.Name
+			break
+		}
+	}
+
+	tests := []struct {
+		name    string
+		network config.NetworkOption
+		expect  func(t *testing.T, r io.Reader, c io.Closer, err error)
+	}{
+		{
+			name: "download piece with loopback interface",
+			network: config.NetworkOption{
+				Interface: loopback,
+			},
+			expect: func(t *testing.T, r io.Reader, c io.Closer, err error) {
+				assert := testifyassert.New(t)
+				assert.NoError(err)
+				defer c.Close()
+				content, err := io.ReadAll(r)
+				assert.NoError(err)
+				assert.Equal(data, content)
+			},
+		},
+		{
+			name: "download piece with unknown interface",
+			network: config.NetworkOption{
+				Interface: "d7y-not-exist",
+			},
+			expect: func(t *testing.T, r io.Reader, c io.Closer, err error) {
+				assert := testifyassert.New(t)
+				assert.Error(err)
+				assert.True(isConnectionError(err))
+			},
+		},
+		{
+			name: "download piece with unknown net namespace",
+			network: config.NetworkOption{
+				Namespace: "/proc/d7y-not-exist/ns/net",
+			},
+			expect: func(t *testing.T, r io.Reader, c io.Closer, err error) {
+				assert := testifyassert.New(t)
+				assert.Error(err)
+				assert.True(isConnectionError(err))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pd, err := NewPieceDownloader(30*time.Second, WithNetwork(tc.network))
+			require.Nil(t, err)
+			r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+				TaskID:  "task-0",
+				DstAddr: addr.Host,
+				piece: &commonv1.PieceInfo{
+					RangeStart: 0,
+					RangeSize:  uint32(len(data)),
+				},
+				log: logger.With("test", "test"),
+			})
+			tc.expect(t, r, c, err)
+		})
+	}
+}
@@ -21,7 +21,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -62,6 +61,8 @@ type pieceManager struct {
 	computePieceSize func(contentLength int64) uint32
 	calculateDigest  bool
 	concurrentOption *config.ConcurrentOption
+	// pieceDownloaderOptions are used to create default piece downloader
+	pieceDownloaderOptions []func(*pieceDownloader) error
 }
 
 func NewPieceManager(pieceDownloadTimeout time.Duration, opts ...func(*pieceManager)) (PieceManager, error) {
@@ -75,7 +76,11 @@ func NewPieceManager(pieceDownloadTimeout time.Duration, opts ...func(*pieceMana
 
 	// set default value
 	if pm.pieceDownloader == nil {
-		pm.pieceDownloader, _ = NewPieceDownloader(pieceDownloadTimeout)
+		pieceDownloader, err := NewPieceDownloader(pieceDownloadTimeout, pm.pieceDownloaderOptions...)
+		if err != nil {
+			return nil, err
+		}
+		pm.pieceDownloader = pieceDownloader
 	}
 	return pm, nil
 }
@@ -103,11 +108,8 @@ func WithTransportOption(opt *config.TransportOption) func(*pieceManager) {
 			defaultTransport.(*http.Transport).IdleConnTimeout = opt.IdleConnTimeout
 		}
 		if opt.DialTimeout > 0 && opt.KeepAlive > 0 {
-			defaultTransport.(*http.Transport).DialContext = (&net.Dialer{
-				Timeout:   opt.DialTimeout,
-				KeepAlive: opt.KeepAlive,
-				DualStack: true,
-			}).DialContext
+			defaultDialer.Timeout = opt.DialTimeout
+			defaultDialer.KeepAlive = opt.KeepAlive
 		}
 		if opt.MaxIdleConns > 0 {
 			defaultTransport.(*http.Transport).MaxIdleConns = opt.MaxIdleConns
@@ -126,6 +128,13 @@ func WithTransportOption(opt *config.TransportOption) func(*pieceManager) {
 	}
 }
 
+// WithPieceDownloaderOptions sets options of default piece downloader, such as tls and auth token
+func WithPieceDownloaderOptions(opts ...func(*pieceDownloader) error) func(*pieceManager) {
+	return func(manager *pieceManager) {
+		manager.pieceDownloaderOptions = append(manager.pieceDownloaderOptions, opts...)
+	}
+}
+
 func WithConcurrentOption(opt *config.ConcurrentOption) func(*pieceManager) {
 	return func(manager *pieceManager) {
 		manager.concurrentOption = opt
@@ -144,6 +153,9 @@ func WithConcurrentOption(opt *config.ConcurrentOption) func(*pieceManager) {
 		if manager.concurrentOption.MaxAttempts <= 0 {
 			manager.concurrentOption.MaxAttempts = 3
 		}
+		if manager.concurrentOption.PiecesPerRequest <= 0 {
+			manager.concurrentOption.PiecesPerRequest = 1
+		}
 	}
 }
 
@@ -211,7 +223,7 @@ func (pm *pieceManager) DownloadPiece(ctx context.Context, request *DownloadPiec
 }
 
 func (pm *pieceManager) processPieceFromSource(pt Task,
-	reader io.Reader, contentLength int64, pieceNum int32, pieceOffset uint64, pieceSize uint32,
+	reader io.Reader, contentLength int64, pieceNum int32, pieceOffset uint64, pieceSize uint32, pieceDigestAlgorithm string,
 	isLastPiece func(n int64) (totalPieces int32, contentLength int64, ok bool)) (
 	result *DownloadPieceResult, md5 string, err error) {
 	result = &DownloadPieceResult{
@@ -233,7 +245,7 @@ func (pm *pieceManager) processPieceFromSource(pt Task,
 	}
 	if pm.calculateDigest {
 		pt.Log().Debugf("calculate digest")
-		reader, _ = digest.NewReader(reader, digest.WithLogger(pt.Log()))
+		reader, _ = digest.NewReader(reader, digest.WithAlgorithm(pieceDigestAlgorithm), digest.WithLogger(pt.Log()))
 	}
 	var n int64
 	result.Size, err = pt.GetStorage().WritePiece(
@@ -267,7 +279,7 @@ func (pm *pieceManager) processPieceFromSource(pt Task,
 		return
 	}
 	if pm.calculateDigest {
-		md5 = reader.(digest.Reader).Encoded()
+		md5 = reader.(digest.Reader).Digest()
 	}
 	return
 }
@@ -288,11 +300,9 @@ func (pm *pieceManager) DownloadSource(ctx context.Context, pt Task, peerTaskReq
 	log := pt.Log()
 	log.Infof("start to download from source")
 
-	backSourceRequest, err := source.NewRequestWithContext(ctx, peerTaskRequest.Url, peerTaskRequest.UrlMeta.Header)
-	if err != nil {
-		return err
-	}
+	origins := newSourceOrigins(peerTaskRequest)
 	var (
+		err                 error
 		metadata            *source.Metadata
 		supportConcurrent   bool
 		targetContentLength int64
@@ -301,8 +311,8 @@ func (pm *pieceManager) DownloadSource(ctx context.Context, pt Task, peerTaskReq
 		// check metadata
 		// 1. support range request
 		// 2. target content length is greater than concurrentOption.ThresholdSize
-		metadata, err = source.GetMetadata(backSourceRequest)
-		if err == nil && metadata.Validate != nil && metadata.Validate() == nil {
+		metadata, err = pm.getSourceMetadata(ctx, log, origins)
+		if err == nil {
 			if !metadata.SupportRange || metadata.TotalContentLength == -1 {
 				goto singleDownload
 			}
@@ -326,6 +336,7 @@ func (pm *pieceManager) DownloadSource(ctx context.Context, pt Task, peerTaskReq
 			}
 
 			if targetContentLength > int64(pm.concurrentOption.ThresholdSize.Limit) {
+				expireInfo := metadata.ExpireInfo()
 				err = pt.GetStorage().UpdateTask(ctx,
 					&storage.UpdateTaskRequest{
 						PeerTaskMetadata: storage.PeerTaskMetadata{
@@ -334,20 +345,21 @@ func (pm *pieceManager) DownloadSource(ctx context.Context, pt Task, peerTaskReq
 						},
 						ContentLength: targetContentLength,
 						Header:        &metadata.Header,
+						ExpireInfo:    &expireInfo,
 					})
 				if err != nil {
 					log.Errorf("update task error: %s", err)
 					return err
 				}
 				// use concurrent piece download mode
-				return pm.concurrentDownloadSource(ctx, pt, peerTaskRequest, parsedRange, metadata, 0)
+				return pm.concurrentDownloadSource(ctx, pt, peerTaskRequest, origins, parsedRange, metadata, 0)
 			}
 		}
 	}
 
 singleDownload:
 	// 1. download pieces from source
-	response, err := source.Download(backSourceRequest)
+	response, err := pm.downloadFromOrigins(ctx, log, origins)
 	// TODO update expire info
 	if err != nil {
 		return err
@@ -384,8 +396,20 @@ singleDownload:
 		}
 	}
 	contentLength := response.ContentLength
+	expireInfo := response.ExpireInfo()
 	if contentLength < 0 {
 		log.Warnf("can not get content length for %s", peerTaskRequest.Url)
+		err = pt.GetStorage().UpdateTask(ctx,
+			&storage.UpdateTaskRequest{
+				PeerTaskMetadata: storage.PeerTaskMetadata{
+					PeerID: pt.GetPeerID(),
+					TaskID: pt.GetTaskID(),
+				},
+				ExpireInfo: &expireInfo,
+			})
+		if err != nil {
+			return err
+		}
 	} else {
 		log.Debugf("back source content length: %d", contentLength)
 		err = pt.GetStorage().UpdateTask(ctx,
@@ -396,6 +420,7 @@ singleDownload:
 				},
 				ContentLength: contentLength,
 				Header:        &response.Header,
+				ExpireInfo:    &expireInfo,
 			})
 		if err != nil {
 			return err
@@ -418,13 +443,81 @@ singleDownload:
 	// 2. save to storage
 	// handle resource which content length is unknown
 	if contentLength < 0 {
-		return pm.downloadUnknownLengthSource(pt, pieceSize, reader)
+		return pm.downloadUnknownLengthSource(pt, pieceSize, pieceDigestAlgorithm(peerTaskRequest.UrlMeta), reader)
+	}
+
+	return pm.downloadKnownLengthSource(ctx, pt, contentLength, pieceSize, reader, response, peerTaskRequest, origins, parsedRange, metadata, supportConcurrent, targetContentLength)
+}
+
+// getSourceMetadata gets metadata from the origins in order, the first origin with valid metadata is used.
+func (pm *pieceManager) getSourceMetadata(ctx context.Context, log *logger.SugaredLoggerOnWith, origins *sourceOrigins) (*source.Metadata, error) {
+	var err error
+	for attempt := range origins.urls {
+		var (
+			request  *source.Request
+			metadata *source.Metadata
+		)
+		if request, err = origins.newRequest(ctx, attempt); err != nil {
+			return nil, err
+		}
+
+		metadata, err = source.GetMetadata(request)
+		if err == nil {
+			if metadata.Validate == nil {
+				return nil, errors.New("metadata can not be validated")
+			}
+			err = metadata.Validate()
+		}
+
+		if err == nil {
+			origins.use(attempt)
+			return metadata, nil
+		}
+
+		if attempt < len(origins.urls)-1 {
+			log.Warnf("get metadata from origin %s error: %s, try next mirror", origins.url(attempt), err)
+		}
 	}
 
-	return pm.downloadKnownLengthSource(ctx, pt, contentLength, pieceSize, reader, response, peerTaskRequest, parsedRange, metadata, supportConcurrent, targetContentLength)
+	return nil, err
 }
 
-func (pm *pieceManager) downloadKnownLengthSource(ctx context.Context, pt Task, contentLength int64, pieceSize uint32, reader io.Reader, response *source.Response, peerTaskRequest *schedulerv1.PeerTaskRequest, parsedRange *clientutil.Range, metadata *source.Metadata, supportConcurrent bool, targetContentLength int64) error {
+// downloadFromOrigins downloads from the origins in order until one responds successfully,
+// the response of the last origin is returned when all origins fail.
+func (pm *pieceManager) downloadFromOrigins(ctx context.Context, log *logger.SugaredLoggerOnWith, origins *sourceOrigins) (*source.Response, error) {
+	last := len(origins.urls) - 1
+	for attempt := 0; attempt < last; attempt++ {
+		request, err := origins.newRequest(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := source.Download(request)
+		if err != nil {
+			log.Warnf("download from origin %s error: %s, try next mirror", origins.url(attempt), err)
+			continue
+		}
+
+		if err = response.Validate(); err != nil {
+			log.Warnf("origin %s response %d/%s is not valid, try next mirror", origins.url(attempt), response.StatusCode, response.Status)
+			response.Body.Close()
+			continue
+		}
+
+		origins.use(attempt)
+		return response, nil
+	}
+
+	request, err := origins.newRequest(ctx, last)
+	if err != nil {
+		return nil, err
+	}
+
+	origins.use(last)
+	return source.Download(request)
+}
+
+func (pm *pieceManager) downloadKnownLengthSource(ctx context.Context, pt Task, contentLength int64, pieceSize uint32, reader io.Reader, response *source.Response, peerTaskRequest *schedulerv1.PeerTaskRequest, origins *sourceOrigins, parsedRange *clientutil.Range, metadata *source.Metadata, supportConcurrent bool, targetContentLength int64) error {
 	log := pt.Log()
 	maxPieceNum := util.ComputePieceCount(contentLength, pieceSize)
 	pt.SetContentLength(contentLength)
@@ -440,7 +533,7 @@ func (pm *pieceManager) downloadKnownLengthSource(ctx context.Context, pt Task,
 
 		log.Debugf("download piece %d", pieceNum)
 		result, md5, err := pm.processPieceFromSource(
-			pt, reader, contentLength, pieceNum, offset, size,
+			pt, reader, contentLength, pieceNum, offset, size, pieceDigestAlgorithm(peerTaskRequest.UrlMeta),
 			func(int64) (int32, int64, bool) {
 				return maxPieceNum, contentLength, pieceNum == maxPieceNum-1
 			})
@@ -488,7 +581,7 @@ func (pm *pieceManager) downloadKnownLengthSource(ctx context.Context, pt Task,
 					return err
 				}
 				response.Body.Close()
-				return pm.concurrentDownloadSource(ctx, pt, peerTaskRequest, parsedRange, metadata, pieceNum+1)
+				return pm.concurrentDownloadSource(ctx, pt, peerTaskRequest, origins, parsedRange, metadata, pieceNum+1)
 			}
 		}
 	}
@@ -497,7 +590,7 @@ func (pm *pieceManager) downloadKnownLengthSource(ctx context.Context, pt Task,
 	return nil
 }
 
-func (pm *pieceManager) downloadUnknownLengthSource(pt Task, pieceSize uint32, reader io.Reader) error {
+func (pm *pieceManager) downloadUnknownLengthSource(pt Task, pieceSize uint32, pieceDigestAlgorithm string, reader io.Reader) error {
 	var (
 		contentLength int64 = -1
 		totalPieces   int32 = -1
@@ -508,7 +601,7 @@ func (pm *pieceManager) downloadUnknownLengthSource(pt Task, pieceSize uint32, r
 		offset := uint64(pieceNum) * uint64(pieceSize)
 		log.Debugf("download piece %d", pieceNum)
 		result, md5, err := pm.processPieceFromSource(
-			pt, reader, contentLength, pieceNum, offset, size,
+			pt, reader, contentLength, pieceNum, offset, size, pieceDigestAlgorithm,
 			func(n int64) (int32, int64, bool) {
 				if n >= int64(pieceSize) {
 					return -1, -1, false
@@ -749,7 +842,7 @@ func (pm *pieceManager) Import(ctx context.Context, ptm storage.PeerTaskMetadata
 	return nil
 }
 
-func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, peerTaskRequest *schedulerv1.PeerTaskRequest, parsedRange *clientutil.Range, metadata *source.Metadata, startPieceNum int32) error {
+func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, peerTaskRequest *schedulerv1.PeerTaskRequest, origins *sourceOrigins, parsedRange *clientutil.Range, metadata *source.Metadata, startPieceNum int32) error {
 	// parsedRange is always exist
 	pieceSize := pm.computePieceSize(parsedRange.Length)
 	pieceCount := util.ComputePieceCount(parsedRange.Length, pieceSize)
@@ -759,17 +852,21 @@ func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, p
 	pt.SetContentLength(parsedRange.Length)
 	pt.SetTotalPieces(pieceCount)
 
+	// every worker downloads a segment of continuous pieces with one ranged request
+	piecesPerRequest := int32(pm.concurrentOption.PiecesPerRequest)
+	segmentCount := (pieceCount - startPieceNum + piecesPerRequest - 1) / piecesPerRequest
+
 	con := pm.concurrentOption.GoroutineCount
-	if int(pieceCount) < con {
-		con = int(pieceCount)
+	if int(segmentCount) < con {
+		con = int(segmentCount)
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	var pieceCh = make(chan int32, con)
+	var segmentCh = make(chan int32, con)
 
 	wg := sync.WaitGroup{}
-	wg.Add(int(pieceCount - startPieceNum))
+	wg.Add(int(segmentCount))
 
 	downloadedPieceCount := atomic.NewInt32(startPieceNum)
 
@@ -780,28 +877,45 @@ func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, p
 				case <-ctx.Done():
 					log.Warnf("concurrent worker %d context done due to %s", i, ctx.Err())
 					return
-				case num, ok := <-pieceCh:
+				case start, ok := <-segmentCh:
 					if !ok {
 						log.Debugf("concurrent worker %d exit", i)
 						return
 					}
-					log.Infof("concurrent worker %d start to download piece %d", i, num)
-					_, _, retryErr := retry.Run(ctx,
+					end := start + piecesPerRequest
+					if end > pieceCount {
+						end = pieceCount
+					}
+					log.Infof("concurrent worker %d start to download piece %d-%d", i, start, end-1)
+					// retry from the first piece not downloaded in the segment,
+					// every retry switches to the next origin if there are mirrors
+					next := start
+					_, retryErr := retry.Run(ctx,
 						pm.concurrentOption.InitBackoff,
 						pm.concurrentOption.MaxBackoff,
 						pm.concurrentOption.MaxAttempts,
-						func() (data any, cancel bool, err error) {
-							err = pm.downloadPieceFromSource(ctx, pt, log,
-								peerTaskRequest, pieceSize, num,
+						func(attempt int) (any, error) {
+							var err error
+							next, err = pm.downloadPiecesFromSource(ctx, pt, log,
+								peerTaskRequest, origins, attempt, pieceSize, next, end,
 								parsedRange, pieceCount, downloadedPieceCount)
-							return nil, err == context.Canceled, err
-						})
+							if errors.Is(err, context.Canceled) {
+								return nil, retry.Abort(err)
+							}
+							return nil, err
+						},
+						retry.WithOnAttempt(func(attempt int, err error, backoff time.Duration) {
+							if err != nil && backoff > 0 {
+								log.Warnf("concurrent worker %d failed to download piece %d at attempt %d, retry after %s: %s",
+									i, next, attempt, backoff, err)
+							}
+						}))
 					if retryErr != nil {
 						// download piece error after many retry, cancel task
 						cancel()
 						downloadError.Store(&backSourceError{err: retryErr})
-						log.Infof("concurrent worker %d failed to download piece %d after %d retries, last error: %s",
-							i, num, pm.concurrentOption.MaxAttempts, retryErr.Error())
+						log.Infof("concurrent worker %d failed to download piece %d, exhausted: %t, error: %s",
+							i, next, retry.IsExhausted(retryErr), retryErr.Error())
 					}
 					wg.Done()
 				}
@@ -809,7 +923,7 @@ func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, p
 		}(i)
 	}
 
-	for i := startPieceNum; i < pieceCount; i++ {
+	for i := startPieceNum; i < pieceCount; i += piecesPerRequest {
 		select {
 		case <-ctx.Done():
 			log.Warnf("context cancelled")
@@ -817,7 +931,7 @@ func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, p
 				return downloadError.Load().(*backSourceError).err
 			}
 			return ctx.Err()
-		case pieceCh <- i:
+		case segmentCh <- i:
 		}
 	}
 
@@ -826,7 +940,7 @@ func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, p
 	wg.Wait()
 
 	// let all working goroutines exit
-	close(pieceCh)
+	close(segmentCh)
 
 	// check error
 	if downloadError.Load() != nil {
@@ -836,46 +950,72 @@ func (pm *pieceManager) concurrentDownloadSource(ctx context.Context, pt Task, p
 	return nil
 }
 
-func (pm *pieceManager) downloadPieceFromSource(ctx context.Context,
+// downloadPiecesFromSource downloads pieces in [start, end) with one ranged request,
+// and writes every piece as soon as it is read. It returns the first piece not downloaded.
+func (pm *pieceManager) downloadPiecesFromSource(ctx context.Context,
 	pt Task, log *logger.SugaredLoggerOnWith,
 	peerTaskRequest *schedulerv1.PeerTaskRequest,
-	pieceSize uint32, num int32,
+	origins *sourceOrigins, attempt int,
+	pieceSize uint32, start, end int32,
 	parsedRange *clientutil.Range,
 	pieceCount int32,
-	downloadedPieceCount *atomic.Int32) error {
-	backSourceRequest, err := source.NewRequestWithContext(ctx, peerTaskRequest.Url, peerTaskRequest.UrlMeta.Header)
+	downloadedPieceCount *atomic.Int32) (int32, error) {
+	backSourceRequest, err := origins.newRequest(ctx, attempt)
 	if err != nil {
-		log.Errorf("build piece %d back source request error: %s", num, err)
-		return err
+		log.Errorf("build piece %d-%d back source request error: %s", start, end-1, err)
+		return start, err
 	}
-	size := pieceSize
-	offset := uint64(num) * uint64(pieceSize)
-	// calculate piece size for last piece
-	if int64(offset)+int64(size) > parsedRange.Length {
-		size = uint32(parsedRange.Length - int64(offset))
+	rangeStart := uint64(start) * uint64(pieceSize)
+	rangeEnd := uint64(end) * uint64(pieceSize)
+	// calculate range end for last piece
+	if int64(rangeEnd) > parsedRange.Length {
+		rangeEnd = uint64(parsedRange.Length)
 	}
 
 	// offset is the position for current peer task, if this peer task already has range
 	// we need add the start to the offset when download from source
-	rg := fmt.Sprintf("bytes=%d-%d", offset+uint64(parsedRange.Start), offset+uint64(parsedRange.Start)+uint64(size)-1)
+	rg := fmt.Sprintf("bytes=%d-%d", rangeStart+uint64(parsedRange.Start), rangeEnd+uint64(parsedRange.Start)-1)
 	backSourceRequest.Header.Set(headers.Range, rg)
 
 	response, err := source.Download(backSourceRequest)
 	if err != nil {
-		log.Errorf("piece %d back source response error: %s", num, err)
-		return err
+		log.Errorf("piece %d-%d back source response error: %s", start, end-1, err)
+		return start, err
 	}
 	defer response.Body.Close()
 
 	err = response.Validate()
 	if err != nil {
-		log.Errorf("piece %d back source response validate error: %s", num, err)
-		return err
+		log.Errorf("piece %d-%d back source response validate error: %s", start, end-1, err)
+		return start, err
 	}
 
-	log.Debugf("piece %d back source response ok", num)
+	log.Debugf("piece %d-%d back source response ok", start, end-1)
+	for num := start; num < end; num++ {
+		size := pieceSize
+		offset := uint64(num) * uint64(pieceSize)
+		// calculate piece size for last piece
+		if int64(offset)+int64(size) > parsedRange.Length {
+			size = uint32(parsedRange.Length - int64(offset))
+		}
+
+		if err := pm.processPieceFromResponse(pt, log, peerTaskRequest, response.Body, num, offset, size,
+			parsedRange, pieceCount, downloadedPieceCount); err != nil {
+			return num, err
+		}
+	}
+
+	return end, nil
+}
+
+func (pm *pieceManager) processPieceFromResponse(pt Task, log *logger.SugaredLoggerOnWith,
+	peerTaskRequest *schedulerv1.PeerTaskRequest,
+	reader io.Reader, num int32, offset uint64, size uint32,
+	parsedRange *clientutil.Range,
+	pieceCount int32,
+	downloadedPieceCount *atomic.Int32) error {
 	result, md5, err := pm.processPieceFromSource(
-		pt, response.Body, parsedRange.Length, num, offset, size,
+		pt, reader, parsedRange.Length, num, offset, size, pieceDigestAlgorithm(peerTaskRequest.UrlMeta),
 		func(int64) (int32, int64, bool) {
 			downloadedPieceCount.Inc()
 			return pieceCount, parsedRange.Length, downloadedPieceCount.Load() == pieceCount
@@ -908,3 +1048,22 @@ func (pm *pieceManager) downloadPieceFromSource(ctx context.Context,
 	pt.PublishPieceInfo(num, uint32(result.Size))
 	return nil
 }
+
+// pieceDigestAlgorithm returns the algorithm of piece digest negotiated via the digest of url meta,
+// when the digest is empty or its algorithm is not supported, falls back to md5.
+func pieceDigestAlgorithm(urlMeta *commonv1.UrlMeta) string {
+	if urlMeta == nil || urlMeta.Digest == "" {
+		return digest.AlgorithmMD5
+	}
+
+	d, err := digest.Parse(urlMeta.Digest)
+	if err != nil {
+		return digest.AlgorithmMD5
+	}
+
+	if _, err := digest.HashFromAlgorithm(d.Algorithm); err != nil {
+		return digest.AlgorithmMD5
+	}
+
+	return d.Algorithm
+}
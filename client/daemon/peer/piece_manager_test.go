@@ -45,6 +45,7 @@ import (
 	clientutil "d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/internal/util"
+	"d7y.io/dragonfly/v2/pkg/digest"
 	_ "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 	"d7y.io/dragonfly/v2/pkg/source"
 	"d7y.io/dragonfly/v2/pkg/source/clients/httpprotocol"
@@ -153,6 +154,34 @@ func TestPieceManager_DownloadSource(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:               "multiple pieces with content length, concurrent download with 4 goroutines and 3 pieces per request",
+			pieceSize:          1024,
+			checkDigest:        false,
+			withContentLength:  true,
+			recordDownloadTime: true,
+			concurrentOption: &config.ConcurrentOption{
+				GoroutineCount:   4,
+				PiecesPerRequest: 3,
+				ThresholdSize: clientutil.Size{
+					Limit: 1024,
+				},
+			},
+		},
+		{
+			name:               "multiple pieces with content length, concurrent download with pieces per request greater than piece count",
+			pieceSize:          1024,
+			checkDigest:        true,
+			withContentLength:  true,
+			recordDownloadTime: true,
+			concurrentOption: &config.ConcurrentOption{
+				GoroutineCount:   4,
+				PiecesPerRequest: 1024,
+				ThresholdSize: clientutil.Size{
+					Limit: 1024,
+				},
+			},
+		},
 		{
 			name:               "multiple pieces with content length, single-thread download with download bandwidth 2KB/s",
 			pieceSize:          2048,
@@ -539,3 +568,39 @@ func TestDetectBackSourceError(t *testing.T) {
 		})
 	}
 }
+
+func TestPieceDigestAlgorithm(t *testing.T) {
+	assert := testifyassert.New(t)
+	testCases := []struct {
+		name    string
+		urlMeta *commonv1.UrlMeta
+		expect  string
+	}{
+		{
+			name:    "nil url meta",
+			urlMeta: nil,
+			expect:  digest.AlgorithmMD5,
+		},
+		{
+			name:    "empty digest",
+			urlMeta: &commonv1.UrlMeta{},
+			expect:  digest.AlgorithmMD5,
+		},
+		{
+			name:    "sha256 digest",
+			urlMeta: &commonv1.UrlMeta{Digest: "sha256:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4"},
+			expect:  digest.AlgorithmSHA256,
+		},
+		{
+			name:    "unsupported digest",
+			urlMeta: &commonv1.UrlMeta{Digest: "blake3:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4"},
+			expect:  digest.AlgorithmMD5,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(tc.expect, pieceDigestAlgorithm(tc.urlMeta))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"strings"
+
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+// sourceOrigins are the url and the mirrors of a back source task,
+// mirrors serve the same content as the url and are tried in order when the former origin fails.
+type sourceOrigins struct {
+	urls   []string
+	header map[string]string
+
+	// first is the index of the origin which is tried first.
+	first int
+}
+
+// newSourceOrigins parses mirrors from the header of peer task request,
+// the mirrors header is only used by daemon and is not sent to source.
+// Mirrors are ignored when the digest of task is not set, because the content
+// of mirrors is cached under the task of url and can not be validated without digest.
+func newSourceOrigins(request *schedulerv1.PeerTaskRequest) *sourceOrigins {
+	origins := &sourceOrigins{
+		urls:   []string{request.Url},
+		header: map[string]string{},
+	}
+
+	for k, v := range request.UrlMeta.Header {
+		if k == config.HeaderDragonflyMirrors {
+			if request.UrlMeta.Digest == "" {
+				logger.Warnf("ignore mirrors of %s without digest", request.Url)
+				continue
+			}
+
+			for _, mirror := range strings.Split(v, ",") {
+				if mirror = strings.TrimSpace(mirror); mirror != "" && mirror != request.Url {
+					origins.urls = append(origins.urls, mirror)
+				}
+			}
+			continue
+		}
+
+		origins.header[k] = v
+	}
+
+	return origins
+}
+
+// url returns the origin url of the attempt, attempts beyond the origins start over from the first one.
+func (o *sourceOrigins) url(attempt int) string {
+	return o.urls[(o.first+attempt)%len(o.urls)]
+}
+
+// use makes the origin of the attempt to be tried first by the following requests.
+func (o *sourceOrigins) use(attempt int) {
+	o.first = (o.first + attempt) % len(o.urls)
+}
+
+// newRequest returns the back source request of the attempt.
+func (o *sourceOrigins) newRequest(ctx context.Context, attempt int) (*source.Request, error) {
+	return source.NewRequestWithContext(ctx, o.url(attempt), o.header)
+}
@@ -0,0 +1,148 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/source/clients/httpprotocol"
+)
+
+func TestSourceOrigins(t *testing.T) {
+	assert := testifyassert.New(t)
+	origins := newSourceOrigins(&schedulerv1.PeerTaskRequest{
+		Url: "http://a.example.com/foo",
+		UrlMeta: &commonv1.UrlMeta{
+			Digest: "sha256:c71d239df91726fc519c6eb72d318ec65820627232b2f796219e87dcf35d0ab4",
+			Header: map[string]string{
+				"Accept":                      "*",
+				config.HeaderDragonflyMirrors: "http://b.example.com/foo, ,http://a.example.com/foo,http://c.example.com/foo",
+			},
+		},
+	})
+
+	assert.Equal([]string{"http://a.example.com/foo", "http://b.example.com/foo", "http://c.example.com/foo"}, origins.urls)
+	assert.Equal(map[string]string{"Accept": "*"}, origins.header)
+	assert.Equal("http://a.example.com/foo", origins.url(0))
+	assert.Equal("http://c.example.com/foo", origins.url(2))
+	assert.Equal("http://a.example.com/foo", origins.url(3))
+
+	origins.use(1)
+	assert.Equal("http://b.example.com/foo", origins.url(0))
+	assert.Equal("http://a.example.com/foo", origins.url(2))
+}
+
+func TestSourceOrigins_WithoutDigest(t *testing.T) {
+	assert := testifyassert.New(t)
+	origins := newSourceOrigins(&schedulerv1.PeerTaskRequest{
+		Url: "http://a.example.com/foo",
+		UrlMeta: &commonv1.UrlMeta{
+			Header: map[string]string{
+				"Accept":                      "*",
+				config.HeaderDragonflyMirrors: "http://b.example.com/foo",
+			},
+		},
+	})
+
+	assert.Equal([]string{"http://a.example.com/foo"}, origins.urls)
+	assert.Equal(map[string]string{"Accept": "*"}, origins.header)
+}
+
+func TestPieceManager_DownloadFromOrigins(t *testing.T) {
+	source.UnRegister("http")
+	require.Nil(t, source.Register("http", httpprotocol.NewHTTPSourceClient(), httpprotocol.Adapter))
+	defer source.UnRegister("http")
+
+	failed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failed.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(config.HeaderDragonflyMirrors) != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("foo")) // nolint: errcheck
+	}))
+	defer mirror.Close()
+
+	tests := []struct {
+		name    string
+		url     string
+		mirrors string
+		expect  func(t *testing.T, origins *sourceOrigins, response *source.Response, err error)
+	}{
+		{
+			name:    "fail over to mirror",
+			url:     failed.URL,
+			mirrors: "http://127.0.0.1:0/foo," + mirror.URL,
+			expect: func(t *testing.T, origins *sourceOrigins, response *source.Response, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				defer response.Body.Close()
+				assert.Nil(response.Validate())
+				data, err := io.ReadAll(response.Body)
+				assert.Nil(err)
+				assert.Equal("foo", string(data))
+				assert.Equal(mirror.URL, origins.url(0))
+			},
+		},
+		{
+			name: "without mirrors",
+			url:  failed.URL,
+			expect: func(t *testing.T, origins *sourceOrigins, response *source.Response, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				defer response.Body.Close()
+				assert.Equal(http.StatusInternalServerError, response.StatusCode)
+				assert.Equal(failed.URL, origins.url(0))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := &schedulerv1.PeerTaskRequest{
+				Url:     tc.url,
+				UrlMeta: &commonv1.UrlMeta{Header: map[string]string{}},
+			}
+			if tc.mirrors != "" {
+				request.UrlMeta.Header[config.HeaderDragonflyMirrors] = tc.mirrors
+				request.UrlMeta.Digest = "sha256:2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+			}
+
+			origins := newSourceOrigins(request)
+			pm := &pieceManager{}
+			response, err := pm.downloadFromOrigins(context.Background(), logger.With("test", t.Name()), origins)
+			tc.expect(t, origins, response, err)
+		})
+	}
+}
@@ -27,6 +27,8 @@ import (
 	"net"
 	"time"
 
+	"github.com/golang/groupcache/lru"
+
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
 
@@ -56,6 +58,7 @@ func genLeafCert(ca *tls.Certificate, leafCertSpec *LeafCertSpec, host string) (
 		NotBefore:             now,
 		NotAfter:              now.Add(24 * time.Hour),
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageKeyAgreement,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 		SignatureAlgorithm:    leafCertSpec.signatureAlgorithm,
 	}
@@ -76,3 +79,50 @@ func genLeafCert(ca *tls.Certificate, leafCertSpec *LeafCertSpec, host string) (
 	cert.Leaf, _ = x509.ParseCertificate(newCert)
 	return cert, nil
 }
+
+// serverTLSConfig returns the tls.Config used to serve hijacked connections. If the cert is a CA,
+// leaf certs are generated for the host returned by serverName and cached, otherwise the cert is served as is.
+func (proxy *Proxy) serverTLSConfig(serverName func(hello *tls.ClientHelloInfo) string) *tls.Config {
+	sConfig := new(tls.Config)
+	if proxy.cert.Leaf == nil || !proxy.cert.Leaf.IsCA {
+		sConfig.Certificates = []tls.Certificate{*proxy.cert}
+		return sConfig
+	}
+
+	sConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return proxy.leafCert(serverName(hello))
+	}
+	return sConfig
+}
+
+// leafCert returns the cached leaf cert of host, if it is missing or expired, generates a new one signed by the CA.
+func (proxy *Proxy) leafCert(host string) (*tls.Certificate, error) {
+	proxy.certCacheLock.Lock()
+	defer proxy.certCacheLock.Unlock()
+
+	if proxy.certCache == nil { // Initialize proxy.certCache on first access. (Lazy init)
+		proxy.certCache = lru.New(100) // Default max entries size = 100
+	}
+
+	cached, hit := proxy.certCache.Get(host)
+	if hit && time.Now().Before(cached.(*tls.Certificate).Leaf.NotAfter) { // If cache hit and the cert is not expired
+		logger.Debugf("TLS cert cache hit, cacheKey = <%s>", host)
+		return cached.(*tls.Certificate), nil
+	}
+
+	logger.Debugf("Generate temporal leaf TLS cert for host <%s>", host)
+	leafCertSpec := LeafCertSpec{
+		proxy.cert.Leaf.PublicKey,
+		proxy.cert.PrivateKey,
+		proxy.cert.Leaf.SignatureAlgorithm}
+	cert, err := genLeafCert(proxy.cert, &leafCertSpec, host)
+	if err != nil {
+		// Unrecoverable error happened in genLeafCert(...)
+		return nil, err
+	}
+
+	// Put cert in cache only if there is no error. So all certs in cache are always valid.
+	// But certs in cache maybe expired (After 24 hours, see the default duration of generated certs)
+	proxy.certCache.Add(host, cert)
+	return cert, nil
+}
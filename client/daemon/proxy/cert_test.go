@@ -0,0 +1,118 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+	testifyrequire "github.com/stretchr/testify/require"
+)
+
+func newTestCA(t *testing.T) *tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	testifyrequire.Nil(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dragonfly test ca"},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	testifyrequire.Nil(t, err)
+
+	leaf, err := x509.ParseCertificate(der)
+	testifyrequire.Nil(t, err)
+
+	return &tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}
+}
+
+func TestProxy_ServerTLSConfig(t *testing.T) {
+	assert := testifyassert.New(t)
+	ca := newTestCA(t)
+
+	proxy, err := NewProxy(WithCert(ca))
+	testifyrequire.Nil(t, err)
+
+	sConfig := proxy.serverTLSConfig(func(hello *tls.ClientHelloInfo) string {
+		return hello.ServerName
+	})
+	assert.Nil(sConfig.Certificates)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+	for _, host := range []string{"index.docker.io", "127.0.0.1"} {
+		cert, err := sConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+		testifyrequire.Nil(t, err)
+
+		_, err = cert.Leaf.Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		assert.Nil(err)
+
+		cached, err := sConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: host})
+		assert.Nil(err)
+		assert.Same(cert, cached)
+	}
+
+	// leaf certs are generated concurrently by https and sni hijack connections
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sConfig.GetCertificate(&tls.ClientHelloInfo{ServerName: "ghcr.io"})
+			assert.Nil(err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(3, proxy.certCache.Len())
+}
+
+func TestProxy_ServerTLSConfigWithServerCert(t *testing.T) {
+	assert := testifyassert.New(t)
+	cert := newTestCA(t)
+	cert.Leaf.IsCA = false
+
+	proxy, err := NewProxy(WithCert(cert))
+	testifyrequire.Nil(t, err)
+
+	sConfig := proxy.serverTLSConfig(func(hello *tls.ClientHelloInfo) string {
+		return hello.ServerName
+	})
+	assert.Nil(sConfig.GetCertificate)
+	assert.Len(sConfig.Certificates, 1)
+}
@@ -67,6 +67,12 @@ type Proxy struct {
 	// proxy rules
 	rules atomic.Value
 
+	// passthrough is the list of url regexps which are proxied directly
+	passthrough atomic.Value
+
+	// probeTransport is used to probe the content length of requests
+	probeTransport http.RoundTripper
+
 	// httpsHosts is the list of hosts whose https requests will be hijacked
 	httpsHosts []*config.HijackHost
 
@@ -76,6 +82,9 @@ type Proxy struct {
 	// certCache is an in-memory cache store for TLS certs used in HTTPS hijack. Lazy init.
 	certCache *lru.Cache
 
+	// certCacheLock protects certCache, it is shared by https and sni hijack connections.
+	certCacheLock sync.Mutex
+
 	// directHandler are used to handle non-proxy requests
 	directHandler http.Handler
 
@@ -195,6 +204,14 @@ func WithRules(rules []*config.ProxyRule) Option {
 	}
 }
 
+// WithPassthrough sets the url regexps which are proxied directly
+func WithPassthrough(passthrough []*config.Regexp) Option {
+	return func(p *Proxy) *Proxy {
+		p.passthrough.Store(passthrough)
+		return p
+	}
+}
+
 // WithWhiteList sets the proxy whitelist
 func WithWhiteList(whiteList []*config.WhiteList) Option {
 	return func(p *Proxy) *Proxy {
@@ -268,9 +285,12 @@ func NewProxy(options ...Option) (*Proxy, error) {
 // NewProxyWithOptions constructs a new instance of a Proxy with additional options.
 func NewProxyWithOptions(options ...Option) (*Proxy, error) {
 	proxy := &Proxy{
-		directHandler: http.NewServeMux(),
-		tracer:        otel.Tracer("dfget-daemon-proxy"),
+		directHandler:  http.NewServeMux(),
+		tracer:         otel.Tracer("dfget-daemon-proxy"),
+		probeTransport: newProbeTransport(nil),
 	}
+	proxy.rules.Store([]*config.ProxyRule(nil))
+	proxy.passthrough.Store([]*config.Regexp(nil))
 
 	for _, opt := range options {
 		opt(proxy)
@@ -427,38 +447,12 @@ func (proxy *Proxy) handleHTTPS(w http.ResponseWriter, r *http.Request) {
 
 	logger.Debugf("hijack https request to %s", r.Host)
 
-	sConfig := new(tls.Config)
-	if proxy.cert.Leaf != nil && proxy.cert.Leaf.IsCA {
-		if proxy.certCache == nil { // Initialize proxy.certCache on first access. (Lazy init)
-			proxy.certCache = lru.New(100) // Default max entries size = 100
-		}
-		leafCertSpec := LeafCertSpec{
-			proxy.cert.Leaf.PublicKey,
-			proxy.cert.PrivateKey,
-			proxy.cert.Leaf.SignatureAlgorithm}
-		host, _, _ := net.SplitHostPort(r.Host)
-		sConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			cConfig.ServerName = host
-			// It's assumed that `hello.ServerName` is always same as `host`, in practice.
-			cacheKey := host
-			cached, hit := proxy.certCache.Get(cacheKey)
-			if hit && time.Now().Before(cached.(*tls.Certificate).Leaf.NotAfter) { // If cache hit and the cert is not expired
-				logger.Debugf("TLS cert cache hit, cacheKey = <%s>", cacheKey)
-				return cached.(*tls.Certificate), nil
-			}
-			logger.Debugf("Generate temporal leaf TLS cert for ServerName <%s>, host <%s>", hello.ServerName, host)
-			cert, err := genLeafCert(proxy.cert, &leafCertSpec, host)
-			if err == nil {
-				// Put cert in cache only if there is no error. So all certs in cache are always valid.
-				// But certs in cache maybe expired (After 24 hours, see the default duration of generated certs)
-				proxy.certCache.Add(cacheKey, cert)
-			}
-			// If err != nil, means unrecoverable error happened in genLeafCert(...)
-			return cert, err
-		}
-	} else {
-		sConfig.Certificates = []tls.Certificate{*proxy.cert}
-	}
+	host, _, _ := net.SplitHostPort(r.Host)
+	sConfig := proxy.serverTLSConfig(func(hello *tls.ClientHelloInfo) string {
+		cConfig.ServerName = host
+		// It's assumed that `hello.ServerName` is always same as `host`, in practice.
+		return host
+	})
 
 	// TODO support http2 by set sConfig.NextProtos = []string{"http/1.1", "h2"}
 	// then check conn.ConnectionState().NegotiatedProtocol in handshake(w, sConfig)
@@ -593,14 +587,23 @@ func (proxy *Proxy) checkWhiteList(r *http.Request) bool {
 	return false
 }
 
-// shouldUseDragonfly returns whether we should use dragonfly to proxy a request. It
-// also changes the scheme of the given request if the matched rule has
-// UseHTTPS = true
+// shouldUseDragonfly returns whether we should use dragonfly to proxy a request.
+// The passthrough list is checked first, then the first matched rule in order decides:
+// it changes the scheme of the given request if the matched rule has UseHTTPS = true,
+// rewrites the url with Redirect, sets the UrlMeta headers of the rule,
+// and proxies requests smaller than MinSize directly.
 func (proxy *Proxy) shouldUseDragonfly(req *http.Request) bool {
 	if req.Method != http.MethodGet {
 		return false
 	}
 
+	for _, regx := range proxy.passthrough.Load().([]*config.Regexp) {
+		if regx.MatchString(req.URL.String()) {
+			logger.Debugf("passthrough request: %s", req.URL.String())
+			return false
+		}
+	}
+
 	for _, rule := range proxy.rules.Load().([]*config.ProxyRule) {
 		if rule.Match(req.URL.String()) {
 			if rule.UseHTTPS {
@@ -619,12 +622,91 @@ func (proxy *Proxy) shouldUseDragonfly(req *http.Request) bool {
 				req.URL.Host = rule.Redirect
 				req.Host = rule.Redirect
 			}
-			return !rule.Direct
+
+			if rule.Direct {
+				return false
+			}
+
+			setRuleHeaders(rule, req.Header)
+			if rule.MinSize > 0 && proxy.smallerThan(req, rule.MinSize.ToNumber()) {
+				logger.Debugf("request is smaller than %s, proxy directly: %s", rule.MinSize, req.URL.String())
+				return false
+			}
+			return true
 		}
 	}
 	return false
 }
 
+// setRuleHeaders sets the UrlMeta headers of the rule when they are not set by request.
+func setRuleHeaders(rule *config.ProxyRule, header http.Header) {
+	for key, value := range map[string]string{
+		config.HeaderDragonflyTag:         rule.Tag,
+		config.HeaderDragonflyApplication: rule.Application,
+		config.HeaderDragonflyFilter:      rule.Filter,
+	} {
+		if value != "" && header.Get(key) == "" {
+			header.Set(key, value)
+		}
+	}
+
+	if rule.DigestHeader != "" && header.Get(config.HeaderDragonflyDigest) == "" {
+		if digest := header.Get(rule.DigestHeader); digest != "" {
+			header.Set(config.HeaderDragonflyDigest, digest)
+		}
+	}
+}
+
+// smallerThan returns whether the content length of request is smaller than size,
+// it sends a HEAD request to the origin, and returns false when the content length is unknown.
+func (proxy *Proxy) smallerThan(req *http.Request, size int64) bool {
+	probe, err := http.NewRequestWithContext(req.Context(), http.MethodHead, req.URL.String(), nil)
+	if err != nil {
+		logger.Errorf("create probe request error: %s", err)
+		return false
+	}
+
+	for key, values := range req.Header {
+		// UrlMeta headers are for dragonfly only
+		if key == headers.ProxyAuthorization || strings.HasPrefix(http.CanonicalHeaderKey(key), "X-Dragonfly-") {
+			continue
+		}
+		probe.Header[key] = values
+	}
+
+	rt := proxy.probeTransport
+	if tlsConfig := proxy.remoteConfig(req.URL.Host); tlsConfig != nil && req.URL.Scheme == schemaHTTPS {
+		t := newProbeTransport(tlsConfig)
+		t.DisableKeepAlives = true
+		rt = t
+	}
+
+	resp, err := rt.RoundTrip(probe)
+	if err != nil {
+		logger.Warnf("probe content length of %s error: %s", req.URL.String(), err)
+		return false
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || resp.ContentLength < 0 {
+		return false
+	}
+	return resp.ContentLength < size
+}
+
+// newProbeTransport returns a transport for probing the content length of requests.
+func newProbeTransport(tlsConfig *tls.Config) *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+		}).DialContext,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+}
+
 // shouldUseDragonflyForMirror returns whether we should use dragonfly to proxy a request
 // when we use registry mirror.
 func (proxy *Proxy) shouldUseDragonflyForMirror(req *http.Request) bool {
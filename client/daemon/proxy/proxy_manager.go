@@ -76,6 +76,7 @@ func NewProxyManager(peerHost *schedulerv1.PeerHost, peerTaskManager peer.TaskMa
 		WithPeerIDGenerator(peer.NewPeerIDGenerator(peerHost.Ip)),
 		WithPeerTaskManager(peerTaskManager),
 		WithRules(proxyRules),
+		WithPassthrough(proxyOption.Passthrough),
 		WithWhiteList(whiteList),
 		WithMaxConcurrency(proxyOption.MaxConcurrency),
 		WithDefaultFilter(proxyOption.DefaultFilter),
@@ -106,6 +107,10 @@ func NewProxyManager(peerHost *schedulerv1.PeerHost, peerTaskManager peer.TaskMa
 		}
 	}
 
+	for _, regx := range proxyOption.Passthrough {
+		logger.Infof("passthrough %s directly", regx)
+	}
+
 	if hijackHTTPS != nil {
 		options = append(options, WithHTTPSHosts(hijackHTTPS.Hosts...))
 		if hijackHTTPS.Cert != "" && hijackHTTPS.Key != "" {
@@ -168,6 +173,22 @@ func (pm *proxyManager) Watch(opt *config.ProxyOption) {
 		logger.Infof("update proxy rules")
 		pm.Proxy.rules.Store(opt.ProxyRules)
 	}
+
+	old, err = yaml.Marshal(pm.Proxy.passthrough.Load().([]*config.Regexp))
+	if err != nil {
+		logger.Errorf("yaml marshal proxy passthrough error: %s", err.Error())
+		return
+	}
+
+	fresh, err = yaml.Marshal(opt.Passthrough)
+	if err != nil {
+		logger.Errorf("yaml marshal proxy passthrough error: %s", err.Error())
+		return
+	}
+	if string(old) != string(fresh) {
+		logger.Infof("update proxy passthrough: %s", string(fresh))
+		pm.Proxy.passthrough.Store(opt.Passthrough)
+	}
 }
 
 func newDirectHandler() *http.ServeMux {
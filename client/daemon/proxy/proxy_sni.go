@@ -24,9 +24,6 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"sync"
-	"time"
-
-	"github.com/golang/groupcache/lru"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
@@ -57,7 +54,7 @@ func (proxy *Proxy) ServeSNI(l net.Listener) error {
 }
 
 // handshakeTLSConn performs the TLS handshake.
-func handshakeTLSConn(clientConn net.Conn, config *tls.Config) (net.Conn, error) {
+func handshakeTLSConn(clientConn net.Conn, config *tls.Config) (*tls.Conn, error) {
 	conn := tls.Server(clientConn, config)
 	if err := conn.Handshake(); err != nil {
 		conn.Close()
@@ -69,36 +66,11 @@ func handshakeTLSConn(clientConn net.Conn, config *tls.Config) (net.Conn, error)
 
 func (proxy *Proxy) handleTLSConn(clientConn net.Conn, port int) {
 	var serverName string
-	sConfig := new(tls.Config)
-	if !proxy.cert.Leaf.IsCA {
-		sConfig.Certificates = []tls.Certificate{*proxy.cert}
-	} else {
-		if proxy.certCache == nil { // Initialize proxy.certCache on first access. (Lazy init)
-			proxy.certCache = lru.New(100) // Default max entries size = 100
-		}
-		leafCertSpec := LeafCertSpec{
-			proxy.cert.Leaf.PublicKey,
-			proxy.cert.PrivateKey,
-			proxy.cert.Leaf.SignatureAlgorithm}
-		sConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			// It's assumed that `hello.ServerName` is always same as `host`, in practice.
-			serverName = hello.ServerName
-			cached, hit := proxy.certCache.Get(serverName)
-			if hit && time.Now().Before(cached.(*tls.Certificate).Leaf.NotAfter) { // If cache hit and the cert is not expired
-				logger.Debugf("TLS Cache hit, cacheKey = <%s>", serverName)
-				return cached.(*tls.Certificate), nil
-			}
-			logger.Debugf("Generate temporal leaf TLS cert for ServerName <%s>", hello.ServerName)
-			cert, err := genLeafCert(proxy.cert, &leafCertSpec, serverName)
-			if err == nil {
-				// Put cert in cache only if there is no error. So all certs in cache are always valid.
-				// But certs in cache maybe expired (After 24 hours, see the default duration of generated certs)
-				proxy.certCache.Add(serverName, cert)
-			}
-			// If err != nil, means unrecoverable error happened in genLeafCert(...)
-			return cert, err
-		}
-	}
+	sConfig := proxy.serverTLSConfig(func(hello *tls.ClientHelloInfo) string {
+		// Route the connection to the host in sni.
+		serverName = hello.ServerName
+		return serverName
+	})
 
 	tlsConn, err := handshakeTLSConn(clientConn, sConfig)
 	if err != nil {
@@ -107,6 +79,9 @@ func (proxy *Proxy) handleTLSConn(clientConn net.Conn, port int) {
 	}
 	defer tlsConn.Close()
 
+	// GetCertificate is not called when the cert is not a CA, use the sni of handshake.
+	serverName = tlsConn.ConnectionState().ServerName
+
 	rp := &httputil.ReverseProxy{
 		Director: func(req *http.Request) {
 			req.URL.Scheme = schemaHTTPS
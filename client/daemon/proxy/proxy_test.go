@@ -19,7 +19,9 @@ package proxy
 import (
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -235,3 +237,81 @@ func TestMatchWithRedirect(t *testing.T) {
 		TestMirror(t)
 
 }
+
+func TestMatchWithPassthrough(t *testing.T) {
+	assert := assert.New(t)
+	rule, err := config.NewProxyRule("/blobs/sha256/", false, false, "")
+	assert.Nil(err)
+	passthrough, err := config.NewRegexp("/blobs/sha256/direct")
+	assert.Nil(err)
+
+	tp, err := NewProxy(WithRules([]*config.ProxyRule{rule}), WithPassthrough([]*config.Regexp{passthrough}))
+	assert.Nil(err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://h/v2/blobs/sha256/direct", nil)
+	assert.Nil(err)
+	assert.False(tp.shouldUseDragonfly(req))
+
+	req, err = http.NewRequest(http.MethodGet, "http://h/v2/blobs/sha256/xxx", nil)
+	assert.Nil(err)
+	assert.True(tp.shouldUseDragonfly(req))
+}
+
+func TestMatchWithRuleMeta(t *testing.T) {
+	assert := assert.New(t)
+	rule, err := config.NewProxyRule("/blobs/sha256/", false, false, "")
+	assert.Nil(err)
+	rule.Tag = "tag"
+	rule.Application = "application"
+	rule.Filter = "Expires&Signature"
+	rule.DigestHeader = "X-Checksum"
+
+	tp, err := NewProxy(WithRules([]*config.ProxyRule{rule}))
+	assert.Nil(err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://h/v2/blobs/sha256/xxx", nil)
+	assert.Nil(err)
+	req.Header.Set(config.HeaderDragonflyTag, "foo")
+	req.Header.Set("X-Checksum", "sha256:bar")
+	assert.True(tp.shouldUseDragonfly(req))
+
+	// headers of request take precedence over rule
+	assert.Equal("foo", req.Header.Get(config.HeaderDragonflyTag))
+	assert.Equal("application", req.Header.Get(config.HeaderDragonflyApplication))
+	assert.Equal("Expires&Signature", req.Header.Get(config.HeaderDragonflyFilter))
+	assert.Equal("sha256:bar", req.Header.Get(config.HeaderDragonflyDigest))
+}
+
+func TestMatchWithMinSize(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(http.MethodHead, r.Method)
+		assert.Empty(r.Header.Get(config.HeaderDragonflyTag))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		w.Header().Set("Content-Length", strconv.Itoa(size))
+	}))
+	defer server.Close()
+
+	rule, err := config.NewProxyRule("/blobs/", false, false, "")
+	assert.Nil(err)
+	rule.Tag = "tag"
+	rule.MinSize = 1024
+
+	tp, err := NewProxy(WithRules([]*config.ProxyRule{rule}))
+	assert.Nil(err)
+
+	tests := []struct {
+		size   int
+		expect bool
+	}{
+		{size: 100, expect: false},
+		{size: 1024, expect: true},
+		{size: 4096, expect: true},
+	}
+
+	for _, tc := range tests {
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/blobs/foo?size=%d", server.URL, tc.size), nil)
+		assert.Nil(err)
+		assert.Equal(tc.expect, tp.shouldUseDragonfly(req), "size %d", tc.size)
+	}
+}
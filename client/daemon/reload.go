@@ -0,0 +1,97 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package daemon
+
+import (
+	"reflect"
+
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/time/rate"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
+)
+
+// schedulerAddrsReloader is implemented by dynconfig resolving scheduler addresses from local config.
+type schedulerAddrsReloader interface {
+	ReloadSchedulerAddrs([]dfnet.NetAddr)
+}
+
+// reloadWatchers returns watchers of the options which are safe to reload at runtime,
+// the other options take effect after restarting daemon.
+func (cd *clientDaemon) reloadWatchers() []func(daemon *config.DaemonOption) {
+	var (
+		verbose        = cd.Option.Verbose
+		schedulerAddrs = cd.Option.Scheduler.NetAddrs
+	)
+
+	return []func(daemon *config.DaemonOption){
+		// Reload rate limits of downloading and uploading.
+		func(daemon *config.DaemonOption) {
+			reloadLimiter("download", cd.downloadLimiter, daemon.Download.TotalRateLimit.Limit)
+			reloadLimiter("upload", cd.uploadLimiter, daemon.Upload.RateLimit.Limit)
+		},
+		// Reload log level, it is only changed when verbose is changed,
+		// so the log level changed by SIGUSR1 is kept.
+		func(daemon *config.DaemonOption) {
+			if daemon.Verbose == verbose {
+				return
+			}
+
+			verbose = daemon.Verbose
+			if verbose {
+				logger.SetLevel(zapcore.DebugLevel)
+				return
+			}
+
+			logger.SetLevel(zapcore.InfoLevel)
+		},
+		// Reload scheduler addresses, they are only used when manager is disabled.
+		func(daemon *config.DaemonOption) {
+			if cd.Option.Scheduler.Manager.Enable || reflect.DeepEqual(daemon.Scheduler.NetAddrs, schedulerAddrs) {
+				return
+			}
+
+			reloader, ok := cd.dynconfig.(schedulerAddrsReloader)
+			if !ok {
+				return
+			}
+
+			if len(daemon.Scheduler.NetAddrs) == 0 {
+				logger.Warn("reloaded scheduler addresses are empty, skip reloading")
+				return
+			}
+
+			schedulerAddrs = daemon.Scheduler.NetAddrs
+			logger.Infof("reload scheduler addresses to %v", schedulerAddrs)
+			reloader.ReloadSchedulerAddrs(schedulerAddrs)
+		},
+	}
+}
+
+// reloadLimiter updates the limit and burst of limiter,
+// the burst is same as the limit, which is the way limiters are created.
+func reloadLimiter(name string, limiter *rate.Limiter, limit rate.Limit) {
+	if limiter == nil || limiter.Limit() == limit {
+		return
+	}
+
+	logger.Infof("reload %s rate limit from %v to %v", name, limiter.Limit(), limit)
+	limiter.SetLimit(limit)
+	limiter.SetBurst(int(limit))
+}
@@ -0,0 +1,111 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/storage"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	dfdaemonserver "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
+)
+
+// CheckCache checks whether the task is fully cached in local storage without downloading,
+// the digests of pieces are validated, and the content is validated when the digest of url meta is set.
+func (s *server) CheckCache(ctx context.Context, req *dfdaemonv1.StatTaskRequest) (*dfdaemonserver.CacheStatus, error) {
+	s.Keep()
+	taskID := idgen.TaskID(req.Url, req.UrlMeta)
+	log := logger.With("function", "CheckCache", "URL", req.Url, "taskID", taskID)
+
+	log.Info("new check cache request")
+	status := &dfdaemonserver.CacheStatus{
+		TaskID: taskID,
+		State:  dfdaemonserver.CacheStateMiss,
+	}
+
+	reuse := s.storageManager.FindCompletedTask(taskID)
+	if reuse == nil {
+		if reuse = s.storageManager.FindResumableTask(taskID); reuse != nil {
+			status.State = dfdaemonserver.CacheStatePartial
+			status.ContentLength = reuse.ContentLength
+			status.TotalPieces = reuse.TotalPieces
+			status.Reason = "task is not completed"
+		} else {
+			status.Reason = "task not found in local cache"
+		}
+
+		log.Infof("task is %s in local cache: %s", status.State, status.Reason)
+		return status, nil
+	}
+
+	status.ContentLength = reuse.ContentLength
+	status.TotalPieces = reuse.TotalPieces
+
+	// Piece digests are validated only when they are calculated in downloading,
+	// otherwise the task is invalidated by storage.
+	if reuse.PieceMd5Sign != "" {
+		if err := reuse.Storage.ValidateDigest(&reuse.PeerTaskMetadata); err != nil {
+			var corrupted *storage.CorruptedPiecesError
+			if errors.As(err, &corrupted) {
+				status.State = dfdaemonserver.CacheStatePartial
+			}
+
+			status.Reason = err.Error()
+			log.Warnf("task is %s in local cache: %s", status.State, status.Reason)
+			return status, nil
+		}
+	}
+
+	if req.UrlMeta != nil && req.UrlMeta.Digest != "" {
+		if err := s.validateContent(ctx, reuse, req.UrlMeta.Digest); err != nil {
+			status.Reason = err.Error()
+			log.Warnf("task is %s in local cache: %s", status.State, status.Reason)
+			return status, nil
+		}
+	}
+
+	status.State = dfdaemonserver.CacheStateHit
+	log.Info("task is hit in local cache")
+	return status, nil
+}
+
+// validateContent reads all pieces of the cached task and validates them with the digest.
+func (s *server) validateContent(ctx context.Context, reuse *storage.ReusePeerTask, d string) error {
+	rc, err := s.storageManager.ReadAllPieces(ctx, &storage.ReadAllPiecesRequest{PeerTaskMetadata: reuse.PeerTaskMetadata})
+	if err != nil {
+		return fmt.Errorf("read pieces: %w", err)
+	}
+	defer rc.Close()
+
+	reader, err := digest.NewReader(rc, digest.WithDigest(d))
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return fmt.Errorf("validate content digest: %w", err)
+	}
+
+	return nil
+}
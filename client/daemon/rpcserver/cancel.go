@@ -0,0 +1,56 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"d7y.io/dragonfly/v2/client/daemon/peer"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	dfdaemonserver "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
+)
+
+// CancelTask aborts the in-flight task, the peer leaves the task and its local storage is reclaimed,
+// so the callers like dfget can release the resources immediately after they give up.
+func (s *server) CancelTask(ctx context.Context, req *dfdaemonserver.CancelTaskRequest) error {
+	s.Keep()
+	log := logger.With("function", "CancelTask", "taskID", req.TaskID)
+
+	log.Infof("new cancel task request, force: %t", req.Force)
+	if req.TaskID == "" {
+		return status.Error(codes.InvalidArgument, "task id is empty")
+	}
+
+	if err := s.peerTaskManager.CancelTask(ctx, req.TaskID, req.Force); err != nil {
+		log.Warnf("cancel task failed: %s", err)
+		switch {
+		case errors.Is(err, peer.ErrTaskNotRunning):
+			return status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, peer.ErrTaskSubscribed):
+			return status.Error(codes.FailedPrecondition, err.Error())
+		default:
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	log.Info("task canceled")
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/daemon/peer"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+// prefetchQueueSize is the max count of prefetch requests waiting for warming
+const prefetchQueueSize = 1024
+
+// Prefetch accepts the tasks and warms them into local storage in the background,
+// tasks are warmed one by one, so prefetching does not compete with the normal downloads.
+func (s *server) Prefetch(ctx context.Context, reqs []*dfdaemonv1.StatTaskRequest) error {
+	s.Keep()
+	for _, req := range reqs {
+		if req.Url == "" {
+			return status.Error(codes.InvalidArgument, "url is empty")
+		}
+		if req.UrlMeta == nil {
+			req.UrlMeta = &commonv1.UrlMeta{}
+		}
+	}
+
+	// The queue is only drained by prefetchLoop out of the lock, so the free slots
+	// checked before enqueueing are enough for the whole batch.
+	s.prefetchLock.Lock()
+	defer s.prefetchLock.Unlock()
+	if free := cap(s.prefetchQueue) - len(s.prefetchQueue); free < len(reqs) {
+		return status.Error(codes.ResourceExhausted, fmt.Sprintf("prefetch queue is full, %d free slots for %d requests", free, len(reqs)))
+	}
+
+	for _, req := range reqs {
+		s.prefetchQueue <- req
+		logger.Infof("prefetch request accepted, url: %s, task id: %s", req.Url, idgen.TaskID(req.Url, req.UrlMeta))
+	}
+
+	return nil
+}
+
+// prefetchLoop warms the prefetch requests one by one
+func (s *server) prefetchLoop() {
+	for {
+		select {
+		case req := <-s.prefetchQueue:
+			s.prefetch(req)
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *server) prefetch(req *dfdaemonv1.StatTaskRequest) {
+	taskID := idgen.TaskID(req.Url, req.UrlMeta)
+	log := logger.With("function", "prefetch", "URL", req.Url, "taskID", taskID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	resp, reuse, err := s.peerTaskManager.StartPrefetchTask(ctx, &peer.PrefetchTaskRequest{
+		PeerTaskRequest: schedulerv1.PeerTaskRequest{
+			Url:      req.Url,
+			UrlMeta:  req.UrlMeta,
+			PeerId:   idgen.PeerID(s.peerHost.Ip),
+			PeerHost: s.peerHost,
+			Pattern:  s.defaultPattern,
+		},
+	})
+	if err != nil {
+		log.Errorf("start prefetch task failed: %s", err)
+		return
+	}
+
+	if reuse {
+		log.Info("task already completed, skip prefetch")
+		return
+	}
+
+	select {
+	case <-resp.Success:
+		log.Info("prefetch task succeeded")
+	case <-resp.Fail:
+		log.Errorf("prefetch task failed: %s", resp.FailReason())
+	case <-ctx.Done():
+		log.Warnf("prefetch task canceled: %s", ctx.Err())
+	}
+}
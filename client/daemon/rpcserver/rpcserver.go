@@ -29,18 +29,23 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/gammazero/deque"
 	"github.com/google/uuid"
 	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
@@ -79,17 +84,50 @@ type server struct {
 	downloadServer *grpc.Server
 	peerServer     *grpc.Server
 	uploadAddr     string
+
+	prefetchQueue chan *dfdaemonv1.StatTaskRequest
+	// prefetchLock serializes enqueueing batches, so a batch is accepted or rejected as a whole
+	prefetchLock sync.Mutex
+	done         chan struct{}
+
+	// seedAdmission queues seed tasks when inbound bandwidth is saturated, nil means no limit
+	seedAdmission *seedAdmission
+
+	// downloadLimiter, uploadLimiter, perPeerRateLimit and dynconfig are only reported in status
+	downloadLimiter  *rate.Limiter
+	uploadLimiter    *rate.Limiter
+	perPeerRateLimit rate.Limit
+	dynconfig        config.Dynconfig
+}
+
+// Option is a functional option for configuring the rpc server.
+type Option func(s *server)
+
+// WithSeedAdmission limits the count of running seed tasks, the exceeding seed tasks are queued,
+// new seed tasks are rejected as busy when the queue is full or the disk is above the watermark.
+func WithSeedAdmission(limit int, maxQueueLength int, diskHighWatermark float64) func(*server) {
+	return func(s *server) {
+		s.seedAdmission = newSeedAdmission(limit)
+		s.seedAdmission.maxQueueLength = maxQueueLength
+		s.seedAdmission.diskHighWatermark = diskHighWatermark
+	}
 }
 
 func New(peerHost *schedulerv1.PeerHost, peerTaskManager peer.TaskManager,
 	storageManager storage.Manager, defaultPattern commonv1.Pattern,
-	downloadOpts []grpc.ServerOption, peerOpts []grpc.ServerOption) (Server, error) {
+	downloadOpts []grpc.ServerOption, peerOpts []grpc.ServerOption, opts ...Option) (Server, error) {
 	s := &server{
 		KeepAlive:       util.NewKeepAlive("rpc server"),
 		peerHost:        peerHost,
 		peerTaskManager: peerTaskManager,
 		storageManager:  storageManager,
 		defaultPattern:  defaultPattern,
+		prefetchQueue:   make(chan *dfdaemonv1.StatTaskRequest, prefetchQueueSize),
+		done:            make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
 
 	sd := &seeder{
@@ -98,11 +136,20 @@ func New(peerHost *schedulerv1.PeerHost, peerTaskManager peer.TaskManager,
 
 	s.downloadServer = dfdaemonserver.New(s, downloadOpts...)
 	healthpb.RegisterHealthServer(s.downloadServer, health.NewServer())
+	dfdaemonserver.RegisterPrefetchServer(s.downloadServer, s)
+	dfdaemonserver.RegisterStatusServer(s.downloadServer, s)
+	dfdaemonserver.RegisterCacheServer(s.downloadServer, s)
+	dfdaemonserver.RegisterCancelServer(s.downloadServer, s)
+	dfdaemonserver.RegisterStreamServer(s.downloadServer, s)
+	// reflection is only served on download server, which is not exposed to other peers
+	reflection.Register(s.downloadServer)
 
 	s.peerServer = dfdaemonserver.New(s, peerOpts...)
 	healthpb.RegisterHealthServer(s.peerServer, health.NewServer())
 
 	cdnsystemv1.RegisterSeederServer(s.peerServer, sd)
+
+	go s.prefetchLoop()
 	return s, nil
 }
 
@@ -111,11 +158,12 @@ func (s *server) ServeDownload(listener net.Listener) error {
 }
 
 func (s *server) ServePeer(listener net.Listener) error {
-	s.uploadAddr = fmt.Sprintf("%s:%d", s.peerHost.Ip, s.peerHost.DownPort)
+	s.uploadAddr = net.JoinHostPort(s.peerHost.Ip, strconv.Itoa(int(s.peerHost.DownPort)))
 	return s.peerServer.Serve(listener)
 }
 
 func (s *server) Stop() {
+	close(s.done)
 	s.peerServer.GracefulStop()
 	s.downloadServer.GracefulStop()
 }
@@ -238,9 +286,10 @@ func (s *server) SyncPieceTasks(sync dfdaemonv1.Daemon_SyncPieceTasksServer) err
 		}
 		p.DstAddr = s.uploadAddr
 		if !attributeSent && len(p.PieceInfos) > 0 {
+			// the request may be redirected to the running peer task, use the peer id in piece packet
 			exa, e := s.storageManager.GetExtendAttribute(ctx,
 				&storage.PeerTaskMetadata{
-					PeerID: request.DstPid,
+					PeerID: p.DstPid,
 					TaskID: request.TaskId,
 				})
 			if e != nil {
@@ -406,7 +455,7 @@ func copyDownRequest(req *dfdaemonv1.DownRequest) *dfdaemonv1.DownRequest {
 		Timeout:            req.Timeout,
 		Limit:              req.Limit,
 		DisableBackSource:  req.DisableBackSource,
-		UrlMeta:            req.UrlMeta,
+		UrlMeta:            proto.Clone(req.UrlMeta).(*commonv1.UrlMeta), // headers of daemon are deleted in every child request
 		Pattern:            req.Pattern,
 		Callsystem:         req.Callsystem,
 		Uid:                req.Uid,
@@ -430,6 +479,45 @@ func (s *server) doDownload(ctx context.Context, req *dfdaemonv1.DownRequest, st
 	if peerID == "" {
 		peerID = idgen.PeerID(s.peerHost.Ip)
 	}
+
+	// callsystem is used as application when application is empty,
+	// so the downloads are reported by the caller in scheduler
+	if req.UrlMeta.Application == "" && req.Callsystem != "" {
+		req.UrlMeta.Application = req.Callsystem
+	}
+
+	// resume header is only used by daemon, do not send it to source
+	var resume bool
+	if v, ok := req.UrlMeta.Header[config.HeaderDragonflyResume]; ok {
+		resume = v == "true"
+		delete(req.UrlMeta.Header, config.HeaderDragonflyResume)
+	}
+
+	// sync header is only used by daemon, do not send it to source
+	var sync bool
+	if v, ok := req.UrlMeta.Header[config.HeaderDragonflySync]; ok {
+		sync = v == "true"
+		delete(req.UrlMeta.Header, config.HeaderDragonflySync)
+	}
+
+	// in place header is only used by daemon, do not send it to source
+	var inPlace bool
+	if v, ok := req.UrlMeta.Header[config.HeaderDragonflyInPlace]; ok {
+		inPlace = v == "true"
+		delete(req.UrlMeta.Header, config.HeaderDragonflyInPlace)
+	}
+
+	// mode header is only used by daemon, do not send it to source
+	var mode os.FileMode
+	if v, ok := req.UrlMeta.Header[config.HeaderDragonflyMode]; ok {
+		delete(req.UrlMeta.Header, config.HeaderDragonflyMode)
+		m, err := config.ParseFileMode(v)
+		if err != nil {
+			return dferrors.New(commonv1.Code_BadRequest, fmt.Sprintf("parse mode %s error: %s", v, err))
+		}
+		mode = m
+	}
+
 	peerTask := &peer.FileTaskRequest{
 		PeerTaskRequest: schedulerv1.PeerTaskRequest{
 			Url:      req.Url,
@@ -443,6 +531,12 @@ func (s *server) doDownload(ctx context.Context, req *dfdaemonv1.DownRequest, st
 		DisableBackSource:  req.DisableBackSource,
 		Callsystem:         req.Callsystem,
 		KeepOriginalOffset: req.KeepOriginalOffset,
+		InPlace:            inPlace,
+		Resume:             resume,
+		Sync:               sync,
+		Uid:                req.Uid,
+		Gid:                req.Gid,
+		Mode:               mode,
 	}
 	if len(req.UrlMeta.Range) > 0 {
 		r, err := http.ParseRange(req.UrlMeta.Range, math.MaxInt)
@@ -510,13 +604,6 @@ func (s *server) doDownload(ctx context.Context, req *dfdaemonv1.DownRequest, st
 			if p.PeerTaskDone {
 				p.DoneCallback()
 				log.Infof("task %s/%s done", p.PeerID, p.TaskID)
-				if req.Uid != 0 && req.Gid != 0 {
-					log.Infof("change own to uid %d gid %d", req.Uid, req.Gid)
-					if err = os.Chown(req.Output, int(req.Uid), int(req.Gid)); err != nil {
-						log.Errorf("change own failed: %s", err)
-						return err
-					}
-				}
 				return nil
 			}
 		case <-ctx.Done():
@@ -611,6 +698,7 @@ func (s *server) ImportTask(ctx context.Context, req *dfdaemonv1.ImportTaskReque
 			PeerID: peerID,
 			TaskID: taskID,
 		},
+		URL: req.Url,
 	})
 	if err != nil {
 		msg := fmt.Sprintf("register task to storage manager failed: %v", err)
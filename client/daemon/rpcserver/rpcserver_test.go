@@ -17,18 +17,26 @@
 package rpcserver
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/distribution/distribution/v3/uuid"
 	"github.com/golang/mock/gomock"
 	"github.com/phayes/freeport"
 	testifyassert "github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
@@ -39,6 +47,7 @@ import (
 	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
 	"d7y.io/dragonfly/v2/client/util"
 	"d7y.io/dragonfly/v2/pkg/dfnet"
+	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	dfclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
@@ -113,6 +122,477 @@ func Test_ServeDownload(t *testing.T) {
 	assert.True(lastResult.Done)
 }
 
+func Test_Prefetch(t *testing.T) {
+	assert := testifyassert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	mockPeerTaskManager := peer.NewMockTaskManager(ctrl)
+	mockPeerTaskManager.EXPECT().StartPrefetchTask(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *peer.PrefetchTaskRequest) (*peer.SubscribeResponse, bool, error) {
+			defer wg.Done()
+			if req.Url == "http://localhost/completed" {
+				return nil, true, nil
+			}
+
+			success := make(chan struct{})
+			close(success)
+			return &peer.SubscribeResponse{
+				Success: success,
+				Fail:    make(chan struct{}),
+			}, false, nil
+		}).Times(2)
+
+	m := &server{
+		KeepAlive:       util.NewKeepAlive("test"),
+		peerHost:        &schedulerv1.PeerHost{},
+		peerTaskManager: mockPeerTaskManager,
+		prefetchQueue:   make(chan *dfdaemonv1.StatTaskRequest, prefetchQueueSize),
+		done:            make(chan struct{}),
+	}
+	defer close(m.done)
+	go m.prefetchLoop()
+
+	m.downloadServer = dfdaemonserver.New(m)
+	dfdaemonserver.RegisterPrefetchServer(m.downloadServer, m)
+	_, client := setupPeerServerAndClient(t, m, assert, m.ServeDownload)
+
+	err := client.Prefetch(context.Background(), []*dfdaemonv1.StatTaskRequest{
+		{
+			Url: "http://localhost/completed",
+		},
+		{
+			Url: "http://localhost/test",
+			UrlMeta: &commonv1.UrlMeta{
+				Tag: "unit test",
+			},
+		},
+	})
+	assert.Nil(err, "client prefetch grpc call should be ok")
+	wg.Wait()
+
+	err = client.Prefetch(context.Background(), []*dfdaemonv1.StatTaskRequest{{}})
+	assert.NotNil(err, "prefetch with empty url should fail")
+}
+
+func Test_PrefetchQueueFull(t *testing.T) {
+	assert := testifyassert.New(t)
+	m := &server{
+		KeepAlive:     util.NewKeepAlive("test"),
+		prefetchQueue: make(chan *dfdaemonv1.StatTaskRequest, 2),
+	}
+
+	assert.Nil(m.Prefetch(context.Background(), []*dfdaemonv1.StatTaskRequest{{Url: "http://localhost/a"}}))
+
+	err := m.Prefetch(context.Background(), []*dfdaemonv1.StatTaskRequest{{Url: "http://localhost/b"}, {Url: "http://localhost/c"}})
+	assert.Equal(codes.ResourceExhausted, status.Code(err))
+	assert.Equal(1, len(m.prefetchQueue), "rejected batch should not be enqueued partly")
+
+	assert.Nil(m.Prefetch(context.Background(), []*dfdaemonv1.StatTaskRequest{{Url: "http://localhost/b"}}))
+	assert.Equal(2, len(m.prefetchQueue))
+}
+
+func Test_Status(t *testing.T) {
+	assert := testifyassert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	startTime := time.Unix(1660000000, 0).UTC()
+	mockPeerTaskManager := peer.NewMockTaskManager(ctrl)
+	mockPeerTaskManager.EXPECT().GetRunningTasks().Return([]*peer.TaskStatus{
+		{
+			TaskID:          "task",
+			PeerID:          "peer",
+			URL:             "http://localhost/test",
+			ContentLength:   10 * 1024 * 1024 * 1024,
+			CompletedLength: 1024,
+			TotalPieces:     2560,
+			CompletedPieces: 1,
+			Subscribers:     2,
+			StartTime:       startTime,
+		},
+	})
+	mockStorageManger := mocks.NewMockManager(ctrl)
+	mockStorageManger.EXPECT().Usage().Return(&storage.Usage{
+		DataPath:   "/data",
+		TaskCount:  3,
+		UsedBytes:  4096,
+		QuotaBytes: 8192,
+	})
+
+	m := &server{
+		KeepAlive:        util.NewKeepAlive("test"),
+		peerHost:         &schedulerv1.PeerHost{},
+		peerTaskManager:  mockPeerTaskManager,
+		storageManager:   mockStorageManger,
+		downloadLimiter:  rate.NewLimiter(rate.Limit(1024), 1024),
+		uploadLimiter:    rate.NewLimiter(rate.Inf, 0),
+		perPeerRateLimit: rate.Limit(512),
+	}
+
+	m.downloadServer = dfdaemonserver.New(m)
+	dfdaemonserver.RegisterStatusServer(m.downloadServer, m)
+	_, client := setupPeerServerAndClient(t, m, assert, m.ServeDownload)
+
+	status, err := client.Status(context.Background())
+	assert.Nil(err, "client status grpc call should be ok")
+	assert.Equal(&dfdaemonserver.Status{
+		Tasks: []*dfdaemonserver.TaskStatus{
+			{
+				TaskID:          "task",
+				PeerID:          "peer",
+				URL:             "http://localhost/test",
+				ContentLength:   10 * 1024 * 1024 * 1024,
+				CompletedLength: 1024,
+				TotalPieces:     2560,
+				CompletedPieces: 1,
+				Subscribers:     2,
+				StartTime:       startTime,
+			},
+		},
+		Storage: dfdaemonserver.StorageStatus{
+			DataPath:   "/data",
+			TaskCount:  3,
+			UsedBytes:  4096,
+			QuotaBytes: 8192,
+		},
+		RateLimit: dfdaemonserver.RateLimitStatus{
+			Download: 1024,
+			Upload:   -1,
+			PerPeer:  512,
+		},
+	}, status)
+}
+
+func Test_CheckCache(t *testing.T) {
+	const content = "hello dragonfly"
+	request := &dfdaemonv1.StatTaskRequest{
+		Url:     "http://localhost/test",
+		UrlMeta: &commonv1.UrlMeta{Tag: "test"},
+	}
+	// task id is changed by digest, cases with digest accept any task id
+	taskID := idgen.TaskID(request.Url, request.UrlMeta)
+	metadata := storage.PeerTaskMetadata{PeerID: "peer", TaskID: taskID}
+
+	tests := []struct {
+		name   string
+		digest string
+		mock   func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver)
+		expect func(t *testing.T, status *dfdaemonserver.CacheStatus)
+	}{
+		{
+			name: "task not found",
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Eq(taskID)).Return(nil)
+				sm.EXPECT().FindResumableTask(gomock.Eq(taskID)).Return(nil)
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStateMiss, status.State)
+				assert.Equal(taskID, status.TaskID)
+			},
+		},
+		{
+			name: "task is not completed",
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Eq(taskID)).Return(nil)
+				sm.EXPECT().FindResumableTask(gomock.Eq(taskID)).Return(&storage.ReusePeerTask{
+					PeerTaskMetadata: metadata,
+					ContentLength:    int64(len(content)),
+					TotalPieces:      1,
+				})
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStatePartial, status.State)
+				assert.Equal(int64(len(content)), status.ContentLength)
+			},
+		},
+		{
+			name: "task is completed without piece digests",
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Eq(taskID)).Return(&storage.ReusePeerTask{
+					PeerTaskMetadata: metadata,
+					ContentLength:    int64(len(content)),
+					TotalPieces:      1,
+				})
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStateHit, status.State)
+				assert.Equal(int32(1), status.TotalPieces)
+			},
+		},
+		{
+			name: "task has corrupted pieces",
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Eq(taskID)).Return(&storage.ReusePeerTask{
+					PeerTaskMetadata: metadata,
+					PieceMd5Sign:     "sign",
+					Storage:          sd,
+				})
+				sd.EXPECT().ValidateDigest(gomock.Eq(&metadata)).Return(&storage.CorruptedPiecesError{PieceNums: []int32{0}})
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStatePartial, status.State)
+				assert.Contains(status.Reason, "corrupted pieces")
+			},
+		},
+		{
+			name: "task has invalid piece digests",
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Eq(taskID)).Return(&storage.ReusePeerTask{
+					PeerTaskMetadata: metadata,
+					PieceMd5Sign:     "sign",
+					Storage:          sd,
+				})
+				sd.EXPECT().ValidateDigest(gomock.Eq(&metadata)).Return(storage.ErrInvalidDigest)
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStateMiss, status.State)
+				assert.Equal(storage.ErrInvalidDigest.Error(), status.Reason)
+			},
+		},
+		{
+			name:   "content matches digest",
+			digest: "md5:" + digest.MD5FromBytes([]byte(content)),
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Any()).Return(&storage.ReusePeerTask{
+					PeerTaskMetadata: metadata,
+					PieceMd5Sign:     "sign",
+					Storage:          sd,
+				})
+				sd.EXPECT().ValidateDigest(gomock.Eq(&metadata)).Return(nil)
+				sm.EXPECT().ReadAllPieces(gomock.Any(), gomock.Any()).Return(io.NopCloser(strings.NewReader(content)), nil)
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStateHit, status.State)
+			},
+		},
+		{
+			name:   "content does not match digest",
+			digest: "md5:" + digest.MD5FromBytes([]byte("foo")),
+			mock: func(sm *mocks.MockManager, sd *mocks.MockTaskStorageDriver) {
+				sm.EXPECT().FindCompletedTask(gomock.Any()).Return(&storage.ReusePeerTask{
+					PeerTaskMetadata: metadata,
+				})
+				sm.EXPECT().ReadAllPieces(gomock.Any(), gomock.Any()).Return(io.NopCloser(strings.NewReader(content)), nil)
+			},
+			expect: func(t *testing.T, status *dfdaemonserver.CacheStatus) {
+				assert := testifyassert.New(t)
+				assert.Equal(dfdaemonserver.CacheStateMiss, status.State)
+				assert.Contains(status.Reason, "digest")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorageManger := mocks.NewMockManager(ctrl)
+			mockTaskStorageDriver := mocks.NewMockTaskStorageDriver(ctrl)
+			tc.mock(mockStorageManger, mockTaskStorageDriver)
+
+			m := &server{
+				KeepAlive:      util.NewKeepAlive("test"),
+				peerHost:       &schedulerv1.PeerHost{},
+				storageManager: mockStorageManger,
+			}
+			m.downloadServer = dfdaemonserver.New(m)
+			dfdaemonserver.RegisterCacheServer(m.downloadServer, m)
+			_, client := setupPeerServerAndClient(t, m, assert, m.ServeDownload)
+
+			status, err := client.CheckCache(context.Background(), &dfdaemonv1.StatTaskRequest{
+				Url:     request.Url,
+				UrlMeta: &commonv1.UrlMeta{Tag: request.UrlMeta.Tag, Digest: tc.digest},
+			})
+			assert.Nil(err)
+			tc.expect(t, status)
+		})
+	}
+}
+
+func Test_CancelTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *dfdaemonserver.CancelTaskRequest
+		mock   func(ptm *peer.MockTaskManagerMockRecorder)
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "cancel task",
+			req:  &dfdaemonserver.CancelTaskRequest{TaskID: "task", Force: true},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {
+				ptm.CancelTask(gomock.Any(), gomock.Eq("task"), gomock.Eq(true)).Return(nil)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+			},
+		},
+		{
+			name: "task id is empty",
+			req:  &dfdaemonserver.CancelTaskRequest{},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {},
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorContains(err, "task id is empty")
+			},
+		},
+		{
+			name: "task is not running",
+			req:  &dfdaemonserver.CancelTaskRequest{TaskID: "task"},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {
+				ptm.CancelTask(gomock.Any(), gomock.Eq("task"), gomock.Eq(false)).Return(peer.ErrTaskNotRunning)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.Equal(codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "task is subscribed by other requests",
+			req:  &dfdaemonserver.CancelTaskRequest{TaskID: "task"},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {
+				ptm.CancelTask(gomock.Any(), gomock.Eq("task"), gomock.Eq(false)).Return(fmt.Errorf("%w, subscribers: 1", peer.ErrTaskSubscribed))
+			},
+			expect: func(t *testing.T, err error) {
+				assert := testifyassert.New(t)
+				assert.Equal(codes.FailedPrecondition, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPeerTaskManager := peer.NewMockTaskManager(ctrl)
+			tc.mock(mockPeerTaskManager.EXPECT())
+
+			m := &server{
+				KeepAlive:       util.NewKeepAlive("test"),
+				peerHost:        &schedulerv1.PeerHost{},
+				peerTaskManager: mockPeerTaskManager,
+			}
+			m.downloadServer = dfdaemonserver.New(m)
+			dfdaemonserver.RegisterCancelServer(m.downloadServer, m)
+			_, client := setupPeerServerAndClient(t, m, assert, m.ServeDownload)
+
+			tc.expect(t, client.CancelTask(context.Background(), tc.req))
+		})
+	}
+}
+
+func Test_StreamTask(t *testing.T) {
+	// larger than one chunk of stream
+	content := []byte(strings.Repeat("dragonfly", 300*1024))
+
+	tests := []struct {
+		name   string
+		req    *dfdaemonv1.DownRequest
+		mock   func(ptm *peer.MockTaskManagerMockRecorder)
+		expect func(t *testing.T, data []byte, err error)
+	}{
+		{
+			name: "stream task",
+			req: &dfdaemonv1.DownRequest{
+				Url:     "http://localhost/test",
+				Output:  "-",
+				UrlMeta: &commonv1.UrlMeta{Range: "0-99"},
+			},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {
+				ptm.StartStreamTask(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *peer.StreamTaskRequest) (io.ReadCloser, map[string]string, error) {
+						if req.URL != "http://localhost/test" || !req.CancelOnDisconnect ||
+							req.Range == nil || req.Range.Start != 0 || req.Range.Length != 100 {
+							return nil, nil, fmt.Errorf("unexpected request: %#v", req)
+						}
+						return io.NopCloser(bytes.NewReader(content)), nil, nil
+					})
+			},
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal(content, data)
+			},
+		},
+		{
+			name: "url is empty",
+			req:  &dfdaemonv1.DownRequest{Output: "-"},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {},
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorContains(err, "Url")
+			},
+		},
+		{
+			name: "start stream task error",
+			req:  &dfdaemonv1.DownRequest{Url: "http://localhost/test", Output: "-"},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {
+				ptm.StartStreamTask(gomock.Any(), gomock.Any()).Return(nil, nil, errors.New("foo"))
+			},
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorContains(err, "foo")
+				assert.Empty(data)
+			},
+		},
+		{
+			name: "stream task error after content written",
+			req:  &dfdaemonv1.DownRequest{Url: "http://localhost/test", Output: "-"},
+			mock: func(ptm *peer.MockTaskManagerMockRecorder) {
+				ptm.StartStreamTask(gomock.Any(), gomock.Any()).Return(
+					io.NopCloser(io.MultiReader(bytes.NewReader(content[:10]), iotest.ErrReader(errors.New("bar")))), nil, nil)
+			},
+			expect: func(t *testing.T, data []byte, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorContains(err, "bar")
+				assert.Equal(content[:10], data)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockPeerTaskManager := peer.NewMockTaskManager(ctrl)
+			tc.mock(mockPeerTaskManager.EXPECT())
+
+			m := &server{
+				KeepAlive:       util.NewKeepAlive("test"),
+				peerHost:        &schedulerv1.PeerHost{},
+				peerTaskManager: mockPeerTaskManager,
+			}
+			m.downloadServer = dfdaemonserver.New(m)
+			dfdaemonserver.RegisterStreamServer(m.downloadServer, m)
+			_, client := setupPeerServerAndClient(t, m, assert, m.ServeDownload)
+
+			rc, err := client.StreamTask(context.Background(), tc.req)
+			if !assert.Nil(err) {
+				return
+			}
+			defer rc.Close()
+
+			data, err := io.ReadAll(rc)
+			tc.expect(t, data, err)
+		})
+	}
+}
+
 func Test_ServePeer(t *testing.T) {
 	assert := testifyassert.New(t)
 	ctrl := gomock.NewController(t)
@@ -0,0 +1,128 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+// seedQueuePositionHeader is the grpc header of ObtainSeeds carrying the position in queue of the seed task.
+const seedQueuePositionHeader = "x-dragonfly-seed-queue-position"
+
+// errSeedQueueFull is returned when the count of queued seed tasks reaches the max queue length.
+var errSeedQueueFull = errors.New("seed task queue is full")
+
+// seedAdmission limits the count of running seed tasks, the seed tasks exceeding the limit
+// are queued in arrival order until running seed tasks finished.
+type seedAdmission struct {
+	mu      sync.Mutex
+	limit   int
+	running int
+	waiters *list.List
+
+	// maxQueueLength is the max count of queued seed tasks, 0 means no limit
+	maxQueueLength int
+	// diskHighWatermark is the max used percent of disk to accept seed tasks, 0 means no limit
+	diskHighWatermark float64
+}
+
+func newSeedAdmission(limit int) *seedAdmission {
+	return &seedAdmission{
+		limit:   limit,
+		waiters: list.New(),
+	}
+}
+
+// free returns the count of seed tasks can be admitted without queuing.
+func (a *seedAdmission) free() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running >= a.limit || a.waiters.Len() > 0 {
+		return 0
+	}
+
+	return a.limit - a.running
+}
+
+// exceedDiskWatermark returns whether the disk usage is above the watermark.
+func (a *seedAdmission) exceedDiskWatermark(diskUsedPercent float64) bool {
+	return a.diskHighWatermark > 0 && diskUsedPercent > a.diskHighWatermark
+}
+
+// acquire waits until the seed task is admitted, queued is called with the position in queue
+// starting from 1 when the seed task is queued. It returns errSeedQueueFull without queuing
+// when the queue is full.
+func (a *seedAdmission) acquire(ctx context.Context, queued func(position int)) error {
+	a.mu.Lock()
+	if a.running < a.limit && a.waiters.Len() == 0 {
+		a.running++
+		a.mu.Unlock()
+		return nil
+	}
+
+	if a.maxQueueLength > 0 && a.waiters.Len() >= a.maxQueueLength {
+		a.mu.Unlock()
+		return errSeedQueueFull
+	}
+
+	ready := make(chan struct{})
+	elem := a.waiters.PushBack(ready)
+	position := a.waiters.Len()
+	a.mu.Unlock()
+
+	metrics.SeedPeerQueuedDownloadGauge.Inc()
+	defer metrics.SeedPeerQueuedDownloadGauge.Dec()
+	queued(position)
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		select {
+		case <-ready:
+			// admitted concurrently, hand over to next waiter
+			a.releaseLocked()
+		default:
+			a.waiters.Remove(elem)
+		}
+		return ctx.Err()
+	}
+}
+
+// release finishes a running seed task and admits the first queued one.
+func (a *seedAdmission) release() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.releaseLocked()
+}
+
+func (a *seedAdmission) releaseLocked() {
+	if front := a.waiters.Front(); front != nil {
+		a.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+
+	a.running--
+}
@@ -0,0 +1,77 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func Test_seedAdmission(t *testing.T) {
+	assert := testifyassert.New(t)
+	admission := newSeedAdmission(1)
+
+	// first seed task is admitted without queuing
+	assert.Nil(admission.acquire(context.Background(), func(int) {
+		t.Fatal("first seed task should not be queued")
+	}))
+
+	acquire := func(ctx context.Context) (chan int, chan error) {
+		positions, done := make(chan int, 1), make(chan error, 1)
+		go func() {
+			done <- admission.acquire(ctx, func(position int) {
+				positions <- position
+			})
+		}()
+		return positions, done
+	}
+
+	positions2, done2 := acquire(context.Background())
+	assert.Equal(1, <-positions2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	positions3, done3 := acquire(ctx)
+	assert.Equal(2, <-positions3)
+
+	positions4, done4 := acquire(context.Background())
+	assert.Equal(3, <-positions4)
+
+	// canceled seed task leaves the queue
+	cancel()
+	assert.ErrorIs(<-done3, context.Canceled)
+
+	// queued seed tasks are admitted in order
+	admission.release()
+	assert.Nil(<-done2)
+	select {
+	case <-done4:
+		t.Fatal("seed task should be queued")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	admission.release()
+	assert.Nil(<-done4)
+
+	admission.release()
+	admission.mu.Lock()
+	assert.Equal(0, admission.running)
+	assert.Equal(0, admission.waiters.Len())
+	admission.mu.Unlock()
+}
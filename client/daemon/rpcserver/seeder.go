@@ -18,11 +18,15 @@ package rpcserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
@@ -91,6 +95,40 @@ func (s *seeder) ObtainSeeds(seedRequest *cdnsystemv1.SeedRequest, seedsServer c
 		}
 	}
 
+	if s.server.seedAdmission != nil && !s.isSeedTaskStarted(seedRequest.TaskId) {
+		// advertise the capacity, the header is sent with the first message or the status
+		capacity := s.seedCapacity()
+		if err := seedsServer.SetHeader(capacity.Metadata()); err != nil {
+			log.Warnf("set seed capacity header error: %s", err.Error())
+		}
+
+		if s.server.seedAdmission.exceedDiskWatermark(capacity.DiskUsedPercent) {
+			metrics.SeedPeerBusyRejectedCount.Add(1)
+			err := fmt.Errorf("disk used percent %.2f exceeds watermark %.2f", capacity.DiskUsedPercent, s.server.seedAdmission.diskHighWatermark)
+			log.Warnf("seed peer is busy: %s", err.Error())
+			return common.NewSeedPeerBusyError(err.Error())
+		}
+
+		err := s.server.seedAdmission.acquire(seedsServer.Context(), func(position int) {
+			log.Infof("inbound bandwidth is saturated, seed task is queued at position %d", position)
+			if err := seedsServer.SendHeader(metadata.Pairs(seedQueuePositionHeader, strconv.Itoa(position))); err != nil {
+				log.Warnf("send queue position error: %s", err.Error())
+			}
+		})
+		if errors.Is(err, errSeedQueueFull) {
+			metrics.SeedPeerBusyRejectedCount.Add(1)
+			log.Warnf("seed peer is busy: %s", err.Error())
+			return common.NewSeedPeerBusyError(err.Error())
+		}
+
+		if err != nil {
+			metrics.SeedPeerDownloadFailureCount.Add(1)
+			log.Errorf("wait for seed task admission error: %s", err.Error())
+			return status.FromContextError(err).Err()
+		}
+		defer s.server.seedAdmission.release()
+	}
+
 	resp, reuse, err := s.server.peerTaskManager.StartSeedTask(seedsServer.Context(), &req)
 	if err != nil {
 		metrics.SeedPeerDownloadFailureCount.Add(1)
@@ -147,6 +185,32 @@ func (s *seeder) ObtainSeeds(seedRequest *cdnsystemv1.SeedRequest, seedsServer c
 	return nil
 }
 
+// seedCapacity returns the capacity of seed peer, the free bandwidth is the inbound bandwidth
+// of the seed tasks can be admitted without queuing.
+func (s *seeder) seedCapacity() common.SeedCapacity {
+	free := int64(s.server.seedAdmission.free())
+	bandwidth := free * int64(s.server.perPeerRateLimit)
+	if free > 0 && s.server.perPeerRateLimit == rate.Inf {
+		bandwidth = math.MaxInt64
+	}
+
+	return common.SeedCapacity{
+		FreeBandwidth:   bandwidth,
+		DiskUsedPercent: s.server.storageManager.Usage().DiskUsedPercent,
+	}
+}
+
+// isSeedTaskStarted returns whether the seed task is completed or running,
+// it consumes no more inbound bandwidth and is not queued.
+func (s *seeder) isSeedTaskStarted(taskID string) bool {
+	if s.server.storageManager.FindCompletedTask(taskID) != nil {
+		return true
+	}
+
+	_, ok := s.server.peerTaskManager.IsPeerTaskRunning(taskID)
+	return ok
+}
+
 type seedSynchronizer struct {
 	*peer.SeedTaskResponse
 	*logger.SugaredLoggerOnWith
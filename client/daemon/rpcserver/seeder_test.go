@@ -29,6 +29,9 @@ import (
 	testifyassert "github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
@@ -362,6 +365,85 @@ func Test_ObtainSeeds(t *testing.T) {
 	}
 }
 
+func Test_ObtainSeedsBusy(t *testing.T) {
+	tests := []struct {
+		name            string
+		diskUsedPercent float64
+		queued          int
+		expect          func(t *testing.T, header metadata.MD, err error)
+	}{
+		{
+			name:            "disk used percent exceeds watermark",
+			diskUsedPercent: 95,
+			expect: func(t *testing.T, header metadata.MD, err error) {
+				assert := testifyassert.New(t)
+				assert.True(common.IsSeedPeerBusy(err))
+				assert.Contains(err.Error(), "watermark")
+				capacity, ok := common.ParseSeedCapacity(header)
+				assert.True(ok)
+				assert.Equal(float64(95), capacity.DiskUsedPercent)
+				assert.Equal(int64(0), capacity.FreeBandwidth)
+			},
+		},
+		{
+			name:            "seed task queue is full",
+			diskUsedPercent: 50,
+			queued:          1,
+			expect: func(t *testing.T, header metadata.MD, err error) {
+				assert := testifyassert.New(t)
+				assert.True(common.IsSeedPeerBusy(err))
+				assert.Contains(err.Error(), errSeedQueueFull.Error())
+				capacity, ok := common.ParseSeedCapacity(header)
+				assert.True(ok)
+				assert.Equal(float64(50), capacity.DiskUsedPercent)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorageManger := mocks.NewMockManager(ctrl)
+			mockStorageManger.EXPECT().FindCompletedTask(gomock.Any()).Return(nil)
+			mockStorageManger.EXPECT().Usage().Return(&storage.Usage{DiskUsedPercent: tc.diskUsedPercent})
+			mockTaskManager := peer.NewMockTaskManager(ctrl)
+			mockTaskManager.EXPECT().IsPeerTaskRunning(gomock.Any()).Return(nil, false)
+
+			s := &server{
+				KeepAlive:        util.NewKeepAlive("test"),
+				peerHost:         &schedulerv1.PeerHost{},
+				storageManager:   mockStorageManger,
+				peerTaskManager:  mockTaskManager,
+				perPeerRateLimit: rate.Limit(100),
+			}
+			WithSeedAdmission(1, 1, 90)(s)
+			s.seedAdmission.running = 1
+			for i := 0; i < tc.queued; i++ {
+				s.seedAdmission.waiters.PushBack(make(chan struct{}))
+			}
+			sd := &seeder{server: s}
+
+			_, client := setupSeederServerAndClient(t, s, sd, assert, s.ServePeer)
+			defer s.peerServer.GracefulStop()
+
+			var header metadata.MD
+			pps, err := client.ObtainSeeds(
+				context.Background(),
+				&cdnsystemv1.SeedRequest{
+					TaskId: "fake-task-id",
+					Url:    "http://localhost/path/to/file",
+				}, grpc.Header(&header))
+			assert.Nil(err)
+
+			_, err = pps.Recv()
+			tc.expect(t, header, err)
+		})
+	}
+}
+
 func setupSeederServerAndClient(t *testing.T, srv *server, sd *seeder, assert *testifyassert.Assertions, serveFunc func(listener net.Listener) error) (int, client.Client) {
 	srv.peerServer = dfdaemonserver.New(srv)
 	cdnsystemv1.RegisterSeederServer(srv.peerServer, sd)
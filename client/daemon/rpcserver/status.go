@@ -0,0 +1,111 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	dfdaemonserver "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
+)
+
+// WithRateLimiters reports the rate limits in status, the limiters are shared with
+// piece manager and upload manager, so the reloaded limits are reported too.
+func WithRateLimiters(download, upload *rate.Limiter, perPeer rate.Limit) func(*server) {
+	return func(s *server) {
+		s.downloadLimiter = download
+		s.uploadLimiter = upload
+		s.perPeerRateLimit = perPeer
+	}
+}
+
+// WithDynconfig reports the schedulers resolved by dynconfig in status.
+func WithDynconfig(dynconfig config.Dynconfig) func(*server) {
+	return func(s *server) {
+		s.dynconfig = dynconfig
+	}
+}
+
+// GetStatus returns the running tasks, storage usage, rate limits and schedulers of daemon.
+func (s *server) GetStatus(ctx context.Context) (*dfdaemonserver.Status, error) {
+	status := &dfdaemonserver.Status{
+		RateLimit: dfdaemonserver.RateLimitStatus{
+			Download: limiterStatus(s.downloadLimiter),
+			Upload:   limiterStatus(s.uploadLimiter),
+			PerPeer:  limitStatus(s.perPeerRateLimit),
+		},
+	}
+
+	for _, task := range s.peerTaskManager.GetRunningTasks() {
+		status.Tasks = append(status.Tasks, &dfdaemonserver.TaskStatus{
+			TaskID:          task.TaskID,
+			PeerID:          task.PeerID,
+			URL:             task.URL,
+			Seed:            task.Seed,
+			ContentLength:   task.ContentLength,
+			CompletedLength: task.CompletedLength,
+			TotalPieces:     task.TotalPieces,
+			CompletedPieces: task.CompletedPieces,
+			Subscribers:     task.Subscribers,
+			ETA:             task.ETA,
+			StartTime:       task.StartTime,
+		})
+	}
+
+	usage := s.storageManager.Usage()
+	status.Storage = dfdaemonserver.StorageStatus{
+		DataPath:        usage.DataPath,
+		TaskCount:       usage.TaskCount,
+		UsedBytes:       usage.UsedBytes,
+		QuotaBytes:      usage.QuotaBytes,
+		DiskUsedPercent: usage.DiskUsedPercent,
+	}
+
+	if s.dynconfig != nil {
+		addrs, err := s.dynconfig.GetResolveSchedulerAddrs()
+		if err != nil {
+			logger.Warnf("get scheduler addresses for status error: %s", err)
+		}
+
+		for _, addr := range addrs {
+			status.Schedulers = append(status.Schedulers, addr.Addr)
+		}
+	}
+
+	return status, nil
+}
+
+// limiterStatus converts the limit of limiter to bytes per second, nil limiter is reported as unlimited.
+func limiterStatus(limiter *rate.Limiter) int64 {
+	if limiter == nil {
+		return -1
+	}
+
+	return limitStatus(limiter.Limit())
+}
+
+// limitStatus converts the limit to bytes per second, rate.Inf is reported as -1.
+func limitStatus(limit rate.Limit) int64 {
+	if limit == rate.Inf {
+		return -1
+	}
+
+	return int64(limit)
+}
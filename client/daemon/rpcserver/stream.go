@@ -0,0 +1,104 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/peer"
+	"d7y.io/dragonfly/v2/client/util"
+	"d7y.io/dragonfly/v2/internal/dferrors"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/net/http"
+)
+
+// StreamTask downloads the task with stream peer task and writes the content to w in order,
+// so callers like dfget can pipe the content to other programs without waiting for the task done.
+func (s *server) StreamTask(ctx context.Context, req *dfdaemonv1.DownRequest, w io.Writer) error {
+	s.Keep()
+	if req.Url == "" {
+		return status.Error(codes.InvalidArgument, "url is empty")
+	}
+
+	if req.UrlMeta == nil {
+		req.UrlMeta = &commonv1.UrlMeta{}
+	}
+
+	// callsystem is used as application when application is empty,
+	// so the downloads are reported by the caller in scheduler
+	if req.UrlMeta.Application == "" && req.Callsystem != "" {
+		req.UrlMeta.Application = req.Callsystem
+	}
+
+	var rg *util.Range
+	if len(req.UrlMeta.Range) > 0 {
+		r, err := http.ParseRange(req.UrlMeta.Range, math.MaxInt)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("parse range %s error: %s", req.UrlMeta.Range, err))
+		}
+
+		rg = &util.Range{
+			Start:  int64(r.StartIndex),
+			Length: int64(r.Length()),
+		}
+	}
+
+	peerID := idgen.PeerID(s.peerHost.Ip)
+	log := logger.With("peer", peerID, "component", "streamService")
+	log.Infof("new stream task request, url: %s", req.Url)
+
+	rc, _, err := s.peerTaskManager.StartStreamTask(ctx, &peer.StreamTaskRequest{
+		URL:     req.Url,
+		URLMeta: req.UrlMeta,
+		Range:   rg,
+		PeerID:  peerID,
+		Pattern: config.ConvertPattern(req.Pattern, s.defaultPattern),
+		// reclaim the resources immediately when the caller gives up
+		CancelOnDisconnect: true,
+	})
+	if err != nil {
+		log.Errorf("start stream task error: %s", err)
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return dferrors.New(commonv1.Code_UnknownError, err.Error())
+	}
+	defer rc.Close()
+
+	n, err := io.Copy(w, rc)
+	if err != nil {
+		log.Errorf("stream task error after %d bytes: %s", n, err)
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return dferrors.New(commonv1.Code_UnknownError, err.Error())
+	}
+
+	log.Infof("stream task done, length: %d bytes", n)
+	return nil
+}
@@ -113,10 +113,59 @@ func searchNextPieceNum(sentMap map[int32]struct{}, cur uint32) (nextPieceNum ui
 	return nextPieceNum
 }
 
-// sendExistPieces will send as much as possible pieces
-func (s *subscriber) sendExistPieces(startNum uint32) (total int32, err error) {
+// sendNewPieces pushes the finished pieces which are not sent to remote peer,
+// it starts from the least unsent piece num, so the pieces of dropped notifications
+// are pushed too, and the pieces already sent are not sent again.
+func (s *subscriber) sendNewPieces(startNum uint32) (total int32, err error) {
+	if s.request.Limit <= 0 {
+		s.request.Limit = 16
+	}
 	s.request.StartNum = startNum
-	return sendExistPieces(s.sync.Context(), s.SugaredLoggerOnWith, s.getPieces, s.request, s.sync, s.sentMap, true)
+
+	for {
+		pp, err := s.getPieces(s.sync.Context(), s.request)
+		if err != nil {
+			s.Errorf("get piece error: %s", err)
+			return -1, err
+		}
+
+		var (
+			count   = len(pp.PieceInfos)
+			nextNum uint32
+			pieces  []*commonv1.PieceInfo
+		)
+		if count > 0 {
+			// the get piece func always return sorted pieces, use last piece num + 1 to get more pieces
+			nextNum = uint32(pp.PieceInfos[count-1].PieceNum + 1)
+		}
+
+		for _, p := range pp.PieceInfos {
+			if _, ok := s.sentMap[p.PieceNum]; !ok {
+				pieces = append(pieces, p)
+			}
+		}
+
+		if len(pieces) > 0 {
+			pp.PieceInfos = pieces
+			if err = s.sync.Send(pp); err != nil {
+				s.Errorf("send pieces error: %s", err)
+				return pp.TotalPiece, err
+			}
+
+			for _, p := range pieces {
+				s.Infof("push new piece %d", p.PieceNum)
+				s.sentMap[p.PieceNum] = struct{}{}
+			}
+		} else if pp.ExtendAttribute != nil {
+			// extend attribute is not sent with the pieces, send it with the next pieces
+			s.attributeSent.Store(false)
+		}
+
+		if uint32(count) < s.request.Limit {
+			return pp.TotalPiece, nil
+		}
+		s.request.StartNum = nextNum
+	}
 }
 
 func (s *subscriber) receiveRemainingPieceTaskRequests() {
@@ -171,7 +220,25 @@ func (s *subscriber) sendRemainingPieceTasks() error {
 			break
 		}
 	}
+	// pieces may land in storage after the exist pieces are sent and before subscribed,
+	// catch up them first, otherwise they are not sent until the next piece is downloaded
+	total, err := s.sendNewPieces(nextPieceNum)
+	if err != nil {
+		err = s.saveError(err)
+		s.Unlock()
+		return err
+	}
+	if total > -1 && s.totalPieces == -1 {
+		s.totalPieces = total
+	}
+	caughtUp := s.totalPieces > -1 && len(s.sentMap)+int(s.skipPieceCount) == int(s.totalPieces)
+	nextPieceNum = s.searchNextPieceNum(nextPieceNum)
 	s.Unlock()
+	if caughtUp {
+		s.Infof("all pieces are sent after subscribed, wait remote SyncPieceTasks done")
+		<-s.done
+		return nil
+	}
 loop:
 	for {
 		select {
@@ -186,7 +253,7 @@ loop:
 			}
 
 			s.Lock()
-			total, err := s.sendExistPieces(uint32(info.Num))
+			total, err := s.sendNewPieces(nextPieceNum)
 			if err != nil {
 				err = s.saveError(err)
 				s.Unlock()
@@ -213,7 +280,7 @@ loop:
 				s.Unlock()
 				break loop
 			}
-			total, err := s.sendExistPieces(nextPieceNum)
+			total, err := s.sendNewPieces(nextPieceNum)
 			if err != nil {
 				err = s.saveError(err)
 				s.Unlock()
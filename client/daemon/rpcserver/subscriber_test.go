@@ -0,0 +1,185 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	testifyassert "github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	dfdaemonv1mocks "d7y.io/api/pkg/apis/dfdaemon/v1/mocks"
+
+	"d7y.io/dragonfly/v2/client/daemon/peer"
+	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+func Test_subscriber_sendNewPieces(t *testing.T) {
+	tests := []struct {
+		name     string
+		finished []int32
+		sent     []int32
+		limit    uint32
+		expect   []int32
+	}{
+		{
+			name:     "push all finished pieces",
+			finished: []int32{0, 1, 2},
+			limit:    16,
+			expect:   []int32{0, 1, 2},
+		},
+		{
+			name:     "skip sent pieces",
+			finished: []int32{0, 1, 2, 3},
+			sent:     []int32{0, 2},
+			limit:    16,
+			expect:   []int32{1, 3},
+		},
+		{
+			name:     "push pieces in multiple packets",
+			finished: []int32{0, 1, 2, 3, 4},
+			sent:     []int32{1},
+			limit:    2,
+			expect:   []int32{0, 2, 3, 4},
+		},
+		{
+			name:     "all pieces are sent",
+			finished: []int32{0, 1},
+			sent:     []int32{0, 1},
+			limit:    16,
+			expect:   nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			storageDriver := mocks.NewMockTaskStorageDriver(ctrl)
+			storageDriver.EXPECT().GetPieces(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+				func(ctx context.Context, req *commonv1.PieceTaskRequest) (*commonv1.PiecePacket, error) {
+					pp := &commonv1.PiecePacket{TotalPiece: int32(len(tc.finished))}
+					for _, num := range tc.finished {
+						if uint32(num) < req.StartNum || uint32(len(pp.PieceInfos)) >= req.Limit {
+							continue
+						}
+						pp.PieceInfos = append(pp.PieceInfos, &commonv1.PieceInfo{PieceNum: num})
+					}
+					return pp, nil
+				})
+			storageDriver.EXPECT().GetExtendAttribute(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil)
+
+			var pushed []int32
+			sync := dfdaemonv1mocks.NewMockDaemon_SyncPieceTasksServer(ctrl)
+			sync.EXPECT().Context().AnyTimes().Return(context.Background())
+			sync.EXPECT().Send(gomock.Any()).AnyTimes().DoAndReturn(
+				func(pp *commonv1.PiecePacket) error {
+					assert.NotEmpty(pp.PieceInfos)
+					for _, p := range pp.PieceInfos {
+						pushed = append(pushed, p.PieceNum)
+					}
+					return nil
+				})
+
+			sentMap := map[int32]struct{}{}
+			for _, num := range tc.sent {
+				sentMap[num] = struct{}{}
+			}
+
+			s := &subscriber{
+				SugaredLoggerOnWith: logger.With("test", "subscriber"),
+				SubscribeResponse: &peer.SubscribeResponse{
+					Storage: storageDriver,
+				},
+				sync:          sync,
+				request:       &commonv1.PieceTaskRequest{Limit: tc.limit},
+				totalPieces:   -1,
+				sentMap:       sentMap,
+				attributeSent: atomic.NewBool(false),
+			}
+
+			total, err := s.sendNewPieces(0)
+			assert.Nil(err)
+			assert.Equal(int32(len(tc.finished)), total)
+			assert.Equal(tc.expect, pushed)
+			assert.Equal(len(tc.finished), len(s.sentMap))
+		})
+	}
+}
+
+func Test_subscriber_sendRemainingPieceTasks(t *testing.T) {
+	assert := testifyassert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// pieces 1 and 2 land in storage after piece 0 is sent and before subscribed
+	storageDriver := mocks.NewMockTaskStorageDriver(ctrl)
+	storageDriver.EXPECT().GetPieces(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(ctx context.Context, req *commonv1.PieceTaskRequest) (*commonv1.PiecePacket, error) {
+			pp := &commonv1.PiecePacket{TotalPiece: 3}
+			for num := int32(req.StartNum); num < 3; num++ {
+				pp.PieceInfos = append(pp.PieceInfos, &commonv1.PieceInfo{PieceNum: num})
+			}
+			return pp, nil
+		})
+	storageDriver.EXPECT().GetExtendAttribute(gomock.Any(), gomock.Any()).AnyTimes().Return(nil, nil)
+
+	var pushed []int32
+	sync := dfdaemonv1mocks.NewMockDaemon_SyncPieceTasksServer(ctrl)
+	sync.EXPECT().Context().AnyTimes().Return(context.Background())
+	sync.EXPECT().Send(gomock.Any()).AnyTimes().DoAndReturn(
+		func(pp *commonv1.PiecePacket) error {
+			for _, p := range pp.PieceInfos {
+				pushed = append(pushed, p.PieceNum)
+			}
+			return nil
+		})
+
+	done := make(chan struct{})
+	s := &subscriber{
+		SugaredLoggerOnWith: logger.With("test", "subscriber"),
+		// no new piece is downloaded and the peer task is still running
+		SubscribeResponse: &peer.SubscribeResponse{
+			Storage:          storageDriver,
+			PieceInfoChannel: make(chan *peer.PieceInfo),
+			Success:          make(chan struct{}),
+			Fail:             make(chan struct{}),
+		},
+		sync:          sync,
+		request:       &commonv1.PieceTaskRequest{Limit: 16},
+		totalPieces:   -1,
+		sentMap:       map[int32]struct{}{0: {}},
+		done:          done,
+		attributeSent: atomic.NewBool(true),
+	}
+
+	result := make(chan error)
+	go func() {
+		result <- s.sendRemainingPieceTasks()
+	}()
+
+	close(done)
+	assert.Nil(<-result)
+	assert.Equal([]int32{1, 2}, pushed)
+	assert.Equal(int32(3), s.totalPieces)
+}
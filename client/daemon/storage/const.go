@@ -19,6 +19,7 @@ package storage
 import (
 	"errors"
 	"os"
+	"time"
 )
 
 const (
@@ -27,6 +28,9 @@ const (
 
 	defaultFileMode      = os.FileMode(0644)
 	defaultDirectoryMode = os.FileMode(0755)
+
+	// checkpointInterval is the minimum interval to persist metadata of the unfinished task
+	checkpointInterval = time.Second
 )
 
 var (
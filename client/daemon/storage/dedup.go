@@ -0,0 +1,226 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// dedupPiece is a piece range in the data file of task.
+type dedupPiece struct {
+	task   PeerTaskMetadata
+	path   string
+	offset int64
+}
+
+// dedupGroup is the pieces with the same digest and length, they share the extents on disk,
+// so the count of pieces is the reference count of the extents.
+type dedupGroup struct {
+	length int64
+	pieces []dedupPiece
+}
+
+// dedupIndex shares the on-disk storage of identical pieces across tasks, pieces are keyed by digest,
+// the ranges are deduplicated by the filesystem, which compares the data before sharing extents,
+// so a wrong digest never corrupts data. It only works on filesystems supporting reflink, e.g. btrfs and xfs.
+type dedupIndex struct {
+	mu     sync.Mutex
+	groups map[string]*dedupGroup
+	// tasks is the keys of groups referenced by task
+	tasks map[PeerTaskMetadata][]string
+	// sharedBytes is the bytes not occupying extra disk space
+	sharedBytes int64
+	// disabled is set when the filesystem does not support deduplication
+	disabled *atomic.Bool
+}
+
+func newDedupIndex() *dedupIndex {
+	return &dedupIndex{
+		groups:   map[string]*dedupGroup{},
+		tasks:    map[PeerTaskMetadata][]string{},
+		disabled: atomic.NewBool(false),
+	}
+}
+
+func dedupKey(digest string, length int64) string {
+	return fmt.Sprintf("%s:%d", digest, length)
+}
+
+// add indexes the piece without deduplication, it is used for pieces loaded from disk,
+// the piece is only indexed as the source of deduplication when no identical piece is indexed.
+func (d *dedupIndex) add(task PeerTaskMetadata, path string, offset, length int64, digest string) {
+	if digest == "" || length <= 0 {
+		return
+	}
+
+	key := dedupKey(digest, length)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.groups[key]; ok {
+		return
+	}
+
+	d.groups[key] = &dedupGroup{length: length, pieces: []dedupPiece{{task: task, path: path, offset: offset}}}
+	d.tasks[task] = append(d.tasks[task], key)
+}
+
+// dedupe shares the extents of the written piece with an identical piece of other tasks,
+// the piece is indexed as the source of deduplication when no identical piece is indexed.
+// It returns whether the piece is deduplicated.
+func (d *dedupIndex) dedupe(task PeerTaskMetadata, file *os.File, offset, length int64, digest string) bool {
+	if digest == "" || length <= 0 || d.disabled.Load() {
+		return false
+	}
+
+	key := dedupKey(digest, length)
+	d.mu.Lock()
+	group, ok := d.groups[key]
+	if !ok {
+		d.groups[key] = &dedupGroup{length: length, pieces: []dedupPiece{{task: task, path: file.Name(), offset: offset}}}
+		d.tasks[task] = append(d.tasks[task], key)
+		d.mu.Unlock()
+		return false
+	}
+
+	var (
+		source dedupPiece
+		found  bool
+	)
+	for _, piece := range group.pieces {
+		if piece.task != task {
+			source, found = piece, true
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	if !found {
+		return false
+	}
+
+	// the source may be reclaimed concurrently, the extents are kept by the filesystem until all references are removed
+	if err := dedupeFileRange(source.path, source.offset, file, offset, length); err != nil {
+		if errors.Is(err, errDedupeNotSupported) {
+			logger.Warnf("disable storage dedup: %s", err)
+			d.disabled.Store(true)
+			return false
+		}
+
+		logger.Debugf("dedupe piece %s with %s/%s error: %s", key, source.task.TaskID, source.task.PeerID, err)
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	group, ok = d.groups[key]
+	if !ok {
+		// all pieces of group are removed, index the piece as the source
+		d.groups[key] = &dedupGroup{length: length, pieces: []dedupPiece{{task: task, path: file.Name(), offset: offset}}}
+	} else {
+		group.pieces = append(group.pieces, dedupPiece{task: task, path: file.Name(), offset: offset})
+		d.sharedBytes += length
+	}
+	d.tasks[task] = append(d.tasks[task], key)
+	return true
+}
+
+// remove drops the references of task, it must be called before the data of task is removed.
+func (d *dedupIndex) remove(task PeerTaskMetadata) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, key := range d.tasks[task] {
+		group, ok := d.groups[key]
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < len(group.pieces); i++ {
+			if group.pieces[i].task != task {
+				continue
+			}
+
+			group.pieces = append(group.pieces[:i], group.pieces[i+1:]...)
+			i--
+			if len(group.pieces) > 0 {
+				d.sharedBytes -= group.length
+			}
+		}
+
+		if len(group.pieces) == 0 {
+			delete(d.groups, key)
+		}
+	}
+	delete(d.tasks, task)
+}
+
+// SharedBytes returns the bytes of pieces which share the extents with others,
+// they do not occupy extra disk space.
+func (d *dedupIndex) SharedBytes() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sharedBytes
+}
+
+// refCount returns the reference count of the piece extents.
+func (d *dedupIndex) refCount(digest string, length int64) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	group, ok := d.groups[dedupKey(digest, length)]
+	if !ok {
+		return 0
+	}
+
+	return len(group.pieces)
+}
+
+// exclusiveBytes returns the bytes released after the task is reclaimed,
+// pieces referenced by other tasks are not released.
+func (d *dedupIndex) exclusiveBytes(task PeerTaskMetadata, contentLength int64) int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	exclusive := contentLength
+	for _, key := range d.tasks[task] {
+		group, ok := d.groups[key]
+		if !ok {
+			continue
+		}
+
+		var refs int
+		for _, piece := range group.pieces {
+			if piece.task == task {
+				refs++
+			}
+		}
+
+		if refs > 0 && len(group.pieces) > refs {
+			exclusive -= group.length * int64(refs)
+		}
+	}
+
+	if exclusive < 0 {
+		return 0
+	}
+
+	return exclusive
+}
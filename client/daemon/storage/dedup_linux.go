@@ -0,0 +1,74 @@
+//go:build linux
+// +build linux
+
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var errDedupeNotSupported = errors.New("dedupe file range is not supported")
+
+// dedupeFileRange shares the extents of the source range with the destination range,
+// the filesystem compares the data of ranges and rejects different ones.
+func dedupeFileRange(srcPath string, srcOffset int64, dst *os.File, dstOffset, length int64) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	value := &unix.FileDedupeRange{
+		Src_offset: uint64(srcOffset),
+		Src_length: uint64(length),
+		Info: []unix.FileDedupeRangeInfo{{
+			Dest_fd:     int64(dst.Fd()),
+			Dest_offset: uint64(dstOffset),
+		}},
+	}
+	if err := unix.IoctlFileDedupeRange(int(src.Fd()), value); err != nil {
+		if errors.Is(err, unix.EOPNOTSUPP) || errors.Is(err, unix.ENOTTY) || errors.Is(err, unix.EXDEV) {
+			return fmt.Errorf("%w: %s", errDedupeNotSupported, err)
+		}
+		return err
+	}
+
+	info := value.Info[0]
+	if info.Status < 0 {
+		errno := unix.Errno(-info.Status)
+		if errno == unix.EOPNOTSUPP {
+			return fmt.Errorf("%w: %s", errDedupeNotSupported, errno)
+		}
+		return errno
+	}
+
+	if info.Status == unix.FILE_DEDUPE_RANGE_DIFFERS {
+		return errors.New("data of ranges differs")
+	}
+
+	if info.Bytes_deduped != uint64(length) {
+		return fmt.Errorf("deduped %d bytes, expected %d bytes", info.Bytes_deduped, length)
+	}
+
+	return nil
+}
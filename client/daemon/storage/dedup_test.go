@@ -0,0 +1,159 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestDedupIndex(t *testing.T) {
+	foo := PeerTaskMetadata{PeerID: "peer", TaskID: "foo"}
+	bar := PeerTaskMetadata{PeerID: "peer", TaskID: "bar"}
+
+	tests := []struct {
+		name   string
+		run    func(d *dedupIndex)
+		expect func(t *testing.T, d *dedupIndex)
+	}{
+		{
+			name: "index the first piece as source",
+			run: func(d *dedupIndex) {
+				d.add(foo, "foo", 0, 4, "md5")
+				d.add(bar, "bar", 0, 4, "md5")
+			},
+			expect: func(t *testing.T, d *dedupIndex) {
+				assert := testifyassert.New(t)
+				assert.Equal(1, d.refCount("md5", 4))
+				assert.Equal(int64(0), d.SharedBytes())
+				assert.Len(d.tasks, 1)
+			},
+		},
+		{
+			name: "pieces without digest are not indexed",
+			run: func(d *dedupIndex) {
+				d.add(foo, "foo", 0, 4, "")
+			},
+			expect: func(t *testing.T, d *dedupIndex) {
+				assert := testifyassert.New(t)
+				assert.Len(d.groups, 0)
+			},
+		},
+		{
+			name: "piece of different length is another group",
+			run: func(d *dedupIndex) {
+				d.add(foo, "foo", 0, 4, "md5")
+				d.add(bar, "bar", 0, 8, "md5")
+			},
+			expect: func(t *testing.T, d *dedupIndex) {
+				assert := testifyassert.New(t)
+				assert.Equal(1, d.refCount("md5", 4))
+				assert.Equal(1, d.refCount("md5", 8))
+			},
+		},
+		{
+			name: "shared pieces are not exclusive",
+			run: func(d *dedupIndex) {
+				d.add(foo, "foo", 0, 4, "md5")
+				d.add(foo, "foo", 4, 4, "other")
+				shareDedupPiece(d, bar, "bar", 0, 4, "md5")
+			},
+			expect: func(t *testing.T, d *dedupIndex) {
+				assert := testifyassert.New(t)
+				assert.Equal(2, d.refCount("md5", 4))
+				assert.Equal(int64(4), d.SharedBytes())
+				assert.Equal(int64(4), d.exclusiveBytes(foo, 8))
+				assert.Equal(int64(0), d.exclusiveBytes(bar, 4))
+			},
+		},
+		{
+			name: "remove references of task",
+			run: func(d *dedupIndex) {
+				d.add(foo, "foo", 0, 4, "md5")
+				shareDedupPiece(d, bar, "bar", 0, 4, "md5")
+				d.remove(foo)
+			},
+			expect: func(t *testing.T, d *dedupIndex) {
+				assert := testifyassert.New(t)
+				assert.Equal(1, d.refCount("md5", 4))
+				assert.Equal(int64(0), d.SharedBytes())
+				assert.Equal(int64(4), d.exclusiveBytes(bar, 4))
+
+				d.remove(bar)
+				assert.Equal(0, d.refCount("md5", 4))
+				assert.Len(d.groups, 0)
+				assert.Len(d.tasks, 0)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := newDedupIndex()
+			tc.run(d)
+			tc.expect(t, d)
+		})
+	}
+}
+
+func TestDedupIndex_Dedupe(t *testing.T) {
+	assert := testifyassert.New(t)
+	dir := t.TempDir()
+	data := bytes.Repeat([]byte("dragonfly"), 4096)
+	foo := path.Join(dir, "foo")
+	bar := path.Join(dir, "bar")
+	assert.Nil(os.WriteFile(foo, data, defaultFileMode))
+	assert.Nil(os.WriteFile(bar, data, defaultFileMode))
+
+	d := newDedupIndex()
+	fooTask := PeerTaskMetadata{PeerID: "peer", TaskID: "foo"}
+	barTask := PeerTaskMetadata{PeerID: "peer", TaskID: "bar"}
+
+	fooFile, err := os.OpenFile(foo, os.O_RDWR, defaultFileMode)
+	assert.Nil(err)
+	defer fooFile.Close()
+	assert.False(d.dedupe(fooTask, fooFile, 0, int64(len(data)), "md5"))
+
+	barFile, err := os.OpenFile(bar, os.O_RDWR, defaultFileMode)
+	assert.Nil(err)
+	defer barFile.Close()
+	if !d.dedupe(barTask, barFile, 0, int64(len(data)), "md5") {
+		// the filesystem does not support deduplication, pieces are kept in separate extents
+		assert.True(d.disabled.Load())
+		assert.Equal(1, d.refCount("md5", int64(len(data))))
+		return
+	}
+
+	assert.Equal(2, d.refCount("md5", int64(len(data))))
+	assert.Equal(int64(len(data)), d.SharedBytes())
+	content, err := os.ReadFile(bar)
+	assert.Nil(err)
+	assert.Equal(data, content)
+}
+
+// shareDedupPiece indexes the piece as a member of the group, as if it is deduplicated by filesystem.
+func shareDedupPiece(d *dedupIndex, task PeerTaskMetadata, path string, offset, length int64, digest string) {
+	key := dedupKey(digest, length)
+	group := d.groups[key]
+	group.pieces = append(group.pieces, dedupPiece{task: task, path: path, offset: offset})
+	d.tasks[task] = append(d.tasks[task], key)
+	d.sharedBytes += length
+}
@@ -18,6 +18,7 @@ package storage
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -36,6 +37,7 @@ import (
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/internal/util"
 	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/source"
 )
 
 type localTaskStore struct {
@@ -49,17 +51,40 @@ type localTaskStore struct {
 	metadataFile     *os.File
 	metadataFilePath string
 
-	expireTime    time.Duration
-	lastAccess    atomic.Int64
-	reclaimMarked atomic.Bool
-	gcCallback    func(CommonTaskRequest)
+	expireTime     time.Duration
+	lastAccess     atomic.Int64
+	lastCheckpoint atomic.Int64
+	reclaimMarked  atomic.Bool
+	gcCallback     func(CommonTaskRequest)
 
 	// when digest not match, invalid will be set
 	invalid atomic.Bool
 
+	// when corrupted pieces are removed for re-downloading, repaired will be set,
+	// the task will be invalid if the digest still does not match after repaired
+	repaired atomic.Bool
+
 	// content stores tiny file which length less than 128 bytes
 	content []byte
 
+	// mmapRead serves reading pieces of done task with memory mapped data
+	mmapRead   bool
+	mmapFailed atomic.Bool
+	mappedLock sync.Mutex
+	mapped     *mappedData
+
+	// memData is the task data promoted to memory by memory tier, guarded by mappedLock,
+	// accessCount is the count of piece requests since last evaluation of memory tier
+	memData     *mappedData
+	accessCount atomic.Int64
+	heat        float64
+
+	// partialReclaim reclaims the downloaded pieces of expired unfinished task by punching holes
+	partialReclaim bool
+
+	// dedup shares on-disk storage of identical pieces with other tasks, nil when disabled
+	dedup *dedupIndex
+
 	subtasks map[PeerTaskMetadata]*localSubTaskStore
 }
 
@@ -163,7 +188,7 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 	if req.PieceMetadata.Md5 == "" {
 		t.Debugf("piece md5 not found in metadata, read from reader")
 		if get, ok := req.Reader.(digest.Reader); ok {
-			req.PieceMetadata.Md5 = get.Encoded()
+			req.PieceMetadata.Md5 = get.Digest()
 			t.Infof("read md5 from reader, value: %s", req.PieceMetadata.Md5)
 		} else {
 			t.Debugf("reader is not a digest.Reader")
@@ -173,17 +198,61 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 	t.Debugf("wrote %d bytes to file %s, piece %d, start %d, length: %d",
 		n, t.DataFilePath, req.Num, req.Range.Start, req.Range.Length)
 	t.Lock()
-	defer t.Unlock()
 	// double check
 	if _, ok := t.Pieces[req.Num]; ok {
+		t.Unlock()
 		return n, nil
 	}
 	req.PieceMetadata.Cost = uint64(time.Now().UnixNano() - start)
 	t.Pieces[req.Num] = req.PieceMetadata
+	t.updatePieceSize(req)
 	t.genMetadata(n, req)
+	t.Unlock()
+
+	if t.dedup != nil && t.dedup.dedupe(PeerTaskMetadata{PeerID: t.PeerID, TaskID: t.TaskID}, file, req.Range.Start, n, req.PieceMetadata.Md5) {
+		t.Debugf("piece %d shares storage with identical piece of other task", req.Num)
+	}
+
+	t.checkpoint()
 	return n, nil
 }
 
+// updatePieceSize records the piece size chosen by the downloader, the caller must hold the lock.
+// All pieces except the last one have the same size, so the piece size can be got from any piece.
+func (t *localTaskStore) updatePieceSize(req *WritePieceRequest) {
+	if t.PieceSize > 0 || req.Range.Length <= 0 {
+		return
+	}
+
+	if req.Num > 0 {
+		t.PieceSize = uint32(req.Range.Start / int64(req.Num))
+		return
+	}
+	t.PieceSize = uint32(req.Range.Length)
+}
+
+// checkpoint persists metadata of the downloaded pieces at most once per checkpointInterval,
+// the interrupted task can be resumed from these pieces after daemon restarted.
+func (t *localTaskStore) checkpoint() {
+	now := time.Now().UnixNano()
+	last := t.lastCheckpoint.Load()
+	if now-last < int64(checkpointInterval) || !t.lastCheckpoint.CAS(last, now) {
+		return
+	}
+
+	if err := t.saveMetadata(); err != nil {
+		t.Warnf("checkpoint task metadata error: %s", err)
+	}
+}
+
+// resumable indicates the task is unfinished and some pieces are already downloaded,
+// or the downloaded pieces were partially reclaimed and the metadata is kept
+func (t *localTaskStore) resumable() bool {
+	t.RLock()
+	defer t.RUnlock()
+	return !t.Done && (len(t.Pieces) > 0 || t.Punched)
+}
+
 func (t *localTaskStore) genMetadata(n int64, req *WritePieceRequest) {
 	if req.GenMetadata == nil {
 		return
@@ -227,20 +296,50 @@ func (t *localTaskStore) UpdateTask(ctx context.Context, req *UpdateTaskRequest)
 		t.Header = req.Header
 		t.Debugf("update header: %#v", t.Header)
 	}
+	if req.ExpireInfo != nil && (req.ExpireInfo.LastModified != "" || req.ExpireInfo.ETag != "") {
+		t.ExpireInfo = &ExpireInfo{
+			LastModified: req.ExpireInfo.LastModified,
+			ETag:         req.ExpireInfo.ETag,
+			ValidatedAt:  time.Now().UnixNano(),
+		}
+		t.Debugf("update expire info: %#v", t.ExpireInfo)
+	}
+	if req.Digest != "" {
+		t.Digest = req.Digest
+		t.Debugf("update digest: %s", t.Digest)
+	}
 	return nil
 }
 
 func (t *localTaskStore) ValidateDigest(*PeerTaskMetadata) error {
+	corrupted, err := t.validateDigest()
+	if len(corrupted) == 0 {
+		return err
+	}
+
+	// persist the removed pieces, the task can be resumed with the remaining pieces
+	if err := t.saveMetadata(); err != nil {
+		t.Errorf("save metadata after removing corrupted pieces error: %s", err)
+		t.invalid.Store(true)
+		return ErrInvalidDigest
+	}
+
+	return &CorruptedPiecesError{PieceNums: corrupted}
+}
+
+// validateDigest validates digest of pieces, when digest does not match, it tries to find the corrupted pieces
+// whose data does not match md5 of piece and removes them, the task is invalid only when no corrupted piece is found.
+func (t *localTaskStore) validateDigest() ([]int32, error) {
 	t.Lock()
 	defer t.Unlock()
 	if t.persistentMetadata.PieceMd5Sign == "" {
 		t.invalid.Store(true)
-		return ErrDigestNotSet
+		return nil, ErrDigestNotSet
 	}
 	if t.TotalPieces <= 0 {
 		t.Errorf("total piece count not set when validate digest")
 		t.invalid.Store(true)
-		return ErrPieceCountNotSet
+		return nil, ErrPieceCountNotSet
 	}
 
 	var pieceDigests []string
@@ -251,10 +350,76 @@ func (t *localTaskStore) ValidateDigest(*PeerTaskMetadata) error {
 	digest := digest.SHA256FromStrings(pieceDigests...)
 	if digest != t.PieceMd5Sign {
 		t.Errorf("invalid digest, desired: %s, actual: %s", t.PieceMd5Sign, digest)
+		// repair only once, fall back to invalidate the whole task
+		if !t.repaired.Load() {
+			if corrupted := t.removeCorruptedPieces(); len(corrupted) > 0 {
+				t.repaired.Store(true)
+				return corrupted, ErrInvalidDigest
+			}
+		}
+
 		t.invalid.Store(true)
-		return ErrInvalidDigest
+		return nil, ErrInvalidDigest
 	}
-	return nil
+	return nil, nil
+}
+
+// removeCorruptedPieces removes pieces whose data in file does not match digest of piece,
+// and marks task undone for re-downloading them, the caller must hold the lock.
+func (t *localTaskStore) removeCorruptedPieces() []int32 {
+	file, err := os.Open(t.DataFilePath)
+	if err != nil {
+		t.Errorf("open data file to check pieces error: %s", err)
+		return nil
+	}
+	defer file.Close()
+
+	var corrupted []int32
+	for i := int32(0); i < t.TotalPieces; i++ {
+		piece, ok := t.Pieces[i]
+		if !ok || piece.Md5 == "" {
+			continue
+		}
+
+		if actual, err := pieceDigest(io.NewSectionReader(file, piece.Range.Start, piece.Range.Length), piece.Md5); err != nil || actual != piece.Md5 {
+			t.Warnf("piece %d is corrupted, desired digest: %s, actual: %s, error: %v", i, piece.Md5, actual, err)
+			corrupted = append(corrupted, i)
+		}
+	}
+
+	for _, num := range corrupted {
+		delete(t.Pieces, num)
+	}
+
+	if len(corrupted) > 0 {
+		t.Done = false
+	}
+	return corrupted
+}
+
+// pieceDigest computes the digest of piece data with the algorithm of desired digest,
+// md5 digest is encoded only and others are prefixed with algorithm, like the piece digests written.
+func pieceDigest(reader io.Reader, desired string) (string, error) {
+	d, err := digest.Parse(desired)
+	if err != nil {
+		return "", err
+	}
+
+	h, err := digest.HashFromAlgorithm(d.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := io.Copy(h, reader); err != nil {
+		return "", err
+	}
+
+	encoded := hex.EncodeToString(h.Sum(nil))
+	if d.Algorithm == digest.AlgorithmMD5 {
+		return encoded, nil
+	}
+
+	return digest.New(d.Algorithm, encoded).String(), nil
 }
 
 func (t *localTaskStore) IsInvalid(*PeerTaskMetadata) (bool, error) {
@@ -269,10 +434,6 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 	}
 
 	t.touch()
-	file, err := os.Open(t.DataFilePath)
-	if err != nil {
-		return nil, nil, err
-	}
 
 	// If req.Num is equal to -1, range has a fixed value.
 	if req.Num != -1 {
@@ -282,12 +443,20 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 			req.Range = piece.Range
 		} else {
 			t.RUnlock()
-			file.Close()
 			t.Errorf("invalid piece num: %d", req.Num)
 			return nil, nil, ErrPieceNotFound
 		}
 	}
 
+	if reader := t.readMappedData(req.Range.Start, req.Range.Length); reader != nil {
+		return reader, reader, nil
+	}
+
+	file, err := os.Open(t.DataFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if _, err = file.Seek(req.Range.Start, io.SeekStart); err != nil {
 		file.Close()
 		t.Errorf("file seek failed: %v", err)
@@ -305,6 +474,14 @@ func (t *localTaskStore) ReadAllPieces(ctx context.Context, req *ReadAllPiecesRe
 
 	t.touch()
 
+	if req.Range == nil {
+		if reader := t.readMappedData(0, t.ContentLength); reader != nil {
+			return reader, nil
+		}
+	} else if reader := t.readMappedData(req.Range.Start, req.Range.Length); reader != nil {
+		return reader, nil
+	}
+
 	// who call ReadPiece, who close the io.ReadCloser
 	file, err := os.Open(t.DataFilePath)
 	if err != nil {
@@ -354,47 +531,40 @@ func (t *localTaskStore) Store(ctx context.Context, req *StoreRequest) error {
 		return nil
 	}
 
-	if req.OriginalOffset {
-		return hardlink(t.SugaredLoggerOnWith, req.Destination, t.DataFilePath)
+	if req.OriginalOffset && req.InPlace {
+		rg := clientutil.Range{Start: 0, Length: t.ContentLength}
+		if req.Range != nil {
+			rg = *req.Range
+		}
+		t.Infof("write task data %s into file %q in place", rg.String(), req.Destination)
+		return writeInPlace(t.DataFilePath, rg.Start, rg.Length, req.Destination, req.OutputOption)
 	}
 
-	_, err := os.Stat(req.Destination)
-	if err == nil {
-		// remove exist file
-		t.Infof("destination file %q exists, purge it first", req.Destination)
-		os.Remove(req.Destination)
-	}
-	// 1. try to link
-	err = os.Link(t.DataFilePath, req.Destination)
-	if err == nil {
-		t.Infof("task data link to file %q success", req.Destination)
-		return nil
-	}
-	t.Warnf("task data link to file %q error: %s", req.Destination, err)
-	// 2. link failed, copy it
-	file, err := os.Open(t.DataFilePath)
-	if err != nil {
-		t.Debugf("open tasks data error: %s", err)
-		return err
+	if req.OriginalOffset && !req.ChangesAttributes() {
+		return hardlink(t.SugaredLoggerOnWith, req.Destination, t.DataFilePath)
 	}
-	defer file.Close()
 
-	_, err = file.Seek(0, io.SeekStart)
-	if err != nil {
-		t.Debugf("task seek file error: %s", err)
-		return err
-	}
-	dstFile, err := os.OpenFile(req.Destination, os.O_CREATE|os.O_RDWR|os.O_TRUNC, defaultFileMode)
-	if err != nil {
-		t.Errorf("open tasks destination file error: %s", err)
-		return err
-	}
-	defer dstFile.Close()
-	// copy_file_range is valid in linux
-	// https://go-review.googlesource.com/c/go/+/229101/
-	n, err := io.Copy(dstFile, file)
-	t.Debugf("copied tasks data %d bytes to %s", n, req.Destination)
-	return err
+	return StoreOutput(req.Destination, req.OutputOption, func(tmp string) error {
+		// 1. try to link, the owner and permissions of link are shared with task data,
+		// so do not link when the owner or permissions of output are specified
+		if !req.ChangesAttributes() {
+			err := os.Link(t.DataFilePath, tmp)
+			if err == nil {
+				t.Infof("task data link to file %q success", req.Destination)
+				return nil
+			}
+			t.Warnf("task data link to file %q error: %s", req.Destination, err)
+		}
+
+		// 2. link failed, copy it
+		n, err := copyFile(t.DataFilePath, 0, -1, tmp)
+		if err != nil {
+			t.Errorf("copy task data to file %q error: %s", req.Destination, err)
+			return err
+		}
+		t.Debugf("copied tasks data %d bytes to %s", n, req.Destination)
+		return nil
+	})
 }
 
 func (t *localTaskStore) GetPieces(ctx context.Context, req *commonv1.PieceTaskRequest) (*commonv1.PiecePacket, error) {
@@ -409,6 +579,7 @@ func (t *localTaskStore) GetPieces(ctx context.Context, req *commonv1.PieceTaskR
 	t.RLock()
 	defer t.RUnlock()
 	t.touch()
+	t.accessCount.Inc()
 	piecePacket := &commonv1.PiecePacket{
 		TaskId:        req.TaskId,
 		DstPid:        t.PeerID,
@@ -471,12 +642,85 @@ func (t *localTaskStore) CanReclaim() bool {
 	if t.invalid.Load() {
 		return true
 	}
+	if t.pinned() {
+		t.Debugf("reclaim check, task is pinned")
+		return false
+	}
 	access := time.Unix(0, t.lastAccess.Load())
 	reclaim := access.Add(t.expireTime).Before(time.Now())
 	t.Debugf("reclaim check, last access: %v, reclaim: %v", access, reclaim)
 	return reclaim
 }
 
+// revalidateInfo returns the validators when the task is not validated with origin in interval,
+// it returns nil when the interval is 0 or origin does not provide validators.
+func (t *localTaskStore) revalidateInfo(interval time.Duration) *source.ExpireInfo {
+	if interval <= 0 {
+		return nil
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	if t.ExpireInfo == nil || time.Unix(0, t.ExpireInfo.ValidatedAt).Add(interval).After(time.Now()) {
+		return nil
+	}
+
+	return &source.ExpireInfo{
+		LastModified: t.ExpireInfo.LastModified,
+		ETag:         t.ExpireInfo.ETag,
+	}
+}
+
+// revalidated records the revalidation result with origin,
+// the task is invalid when it is modified in origin and will be reclaimed by gc.
+func (t *localTaskStore) revalidated(modified bool) error {
+	if modified {
+		t.Infof("task is modified in origin, mark it invalid")
+		t.invalid.Store(true)
+		return nil
+	}
+
+	t.Lock()
+	if t.ExpireInfo != nil {
+		t.ExpireInfo.ValidatedAt = time.Now().UnixNano()
+	}
+	t.Unlock()
+
+	return t.saveMetadata()
+}
+
+// Pin excludes the task from gc, ttl 0 means the task is pinned until unpinned.
+func (t *localTaskStore) Pin(ttl time.Duration) error {
+	t.Lock()
+	t.Pinned = true
+	t.PinExpireAt = 0
+	if ttl > 0 {
+		t.PinExpireAt = time.Now().Add(ttl).UnixNano()
+	}
+	t.Unlock()
+
+	t.touch()
+	return t.saveMetadata()
+}
+
+// Unpin makes the task available for gc again.
+func (t *localTaskStore) Unpin() error {
+	t.Lock()
+	t.Pinned = false
+	t.PinExpireAt = 0
+	t.Unlock()
+
+	// keep the task for another expire time after unpinned
+	t.touch()
+	return t.saveMetadata()
+}
+
+func (t *localTaskStore) pinned() bool {
+	t.RLock()
+	defer t.RUnlock()
+	return t.Pinned && (t.PinExpireAt == 0 || time.Now().UnixNano() < t.PinExpireAt)
+}
+
 // MarkReclaim will try to invoke gcCallback (normal leave peer task)
 func (t *localTaskStore) MarkReclaim() {
 	if t.reclaimMarked.Load() {
@@ -508,6 +752,10 @@ func (t *localTaskStore) MarkReclaim() {
 
 func (t *localTaskStore) Reclaim() error {
 	t.Infof("start gc task data")
+	t.releaseMappedData()
+	if t.dedup != nil {
+		t.dedup.remove(PeerTaskMetadata{PeerID: t.PeerID, TaskID: t.TaskID})
+	}
 	err := t.reclaimData()
 	if err != nil && !os.IsNotExist(err) {
 		return err
@@ -601,8 +849,10 @@ func (t *localTaskStore) saveMetadata() error {
 	_, err = t.metadataFile.Write(data)
 	if err != nil {
 		t.Errorf("save metadata error: %s", err)
+		return err
 	}
-	return err
+	// metadata may be shorter than the previous checkpoint, truncate the stale data
+	return t.metadataFile.Truncate(int64(len(data)))
 }
 
 func (t *localTaskStore) partialCompleted(rg *clientutil.Range) bool {
@@ -623,7 +873,16 @@ func (t *localTaskStore) partialCompleted(rg *clientutil.Range) bool {
 		realRange.Length = t.ContentLength - realRange.Start
 	}
 
-	start, end := computePiecePosition(t.ContentLength, realRange, util.ComputePieceSize)
+	// use the recorded piece size, the piece size policy may be changed after the task downloaded
+	computePieceSize := util.ComputePieceSize
+	if t.PieceSize > 0 {
+		pieceSize := t.PieceSize
+		computePieceSize = func(int64) uint32 {
+			return pieceSize
+		}
+	}
+
+	start, end := computePiecePosition(t.ContentLength, realRange, computePieceSize)
 	// fix int overflow
 	if start < 0 || end < 0 {
 		t.Warnf("wrong start and end piece num, %d, %d", start, end)
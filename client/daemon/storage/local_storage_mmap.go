@@ -0,0 +1,150 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"sync"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// mappedData is a read only memory mapping of task data shared by all readers,
+// it is unmapped when released and no reader holds it.
+type mappedData struct {
+	sync.Mutex
+	data     []byte
+	refs     int
+	released bool
+	// heap is set when data is loaded in memory by memory tier instead of mmap
+	heap bool
+	log  *logger.SugaredLoggerOnWith
+}
+
+func (m *mappedData) acquire() bool {
+	m.Lock()
+	defer m.Unlock()
+	if m.released {
+		return false
+	}
+	m.refs++
+	return true
+}
+
+func (m *mappedData) put() {
+	m.Lock()
+	defer m.Unlock()
+	m.refs--
+	if m.refs == 0 && m.released {
+		m.unmap()
+	}
+}
+
+func (m *mappedData) release() {
+	m.Lock()
+	defer m.Unlock()
+	if m.released {
+		return
+	}
+	m.released = true
+	if m.refs == 0 {
+		m.unmap()
+	}
+}
+
+func (m *mappedData) unmap() {
+	if !m.heap {
+		if err := munmapFile(m.data); err != nil {
+			m.log.Warnf("munmap task data error: %s", err)
+		}
+	}
+	m.data = nil
+}
+
+// mappedReader reads a range of mapped task data, the mapping is hold until closed.
+type mappedReader struct {
+	*bytes.Reader
+	mapped *mappedData
+	once   sync.Once
+}
+
+func (r *mappedReader) Close() error {
+	r.once.Do(r.mapped.put)
+	return nil
+}
+
+// acquireMappedData returns the mapping of task data, nil will be returned when mmap read is disabled,
+// the task is not done or mmap failed, then caller should fall back to read file.
+func (t *localTaskStore) acquireMappedData() *mappedData {
+	if !t.mmapRead || t.mmapFailed.Load() || !t.Done || t.ContentLength <= 0 {
+		return nil
+	}
+
+	t.mappedLock.Lock()
+	defer t.mappedLock.Unlock()
+	if t.mapped == nil {
+		data, err := mmapFile(t.DataFilePath, t.ContentLength)
+		if err != nil {
+			t.Warnf("mmap task data error: %s, fall back to read file", err)
+			t.mmapFailed.Store(true)
+			return nil
+		}
+		t.Debugf("mmap task data, length: %d", t.ContentLength)
+		t.mapped = &mappedData{data: data, log: t.SugaredLoggerOnWith}
+	}
+
+	if !t.mapped.acquire() {
+		return nil
+	}
+	return t.mapped
+}
+
+// readMappedData returns a reader of the range in mapped task data, nil will be returned when not available.
+func (t *localTaskStore) readMappedData(start, length int64) *mappedReader {
+	mapped := t.acquireMemoryData()
+	if mapped == nil {
+		mapped = t.acquireMappedData()
+	}
+	if mapped == nil {
+		return nil
+	}
+
+	if start < 0 || length < 0 || start+length > int64(len(mapped.data)) {
+		mapped.put()
+		return nil
+	}
+
+	return &mappedReader{
+		Reader: bytes.NewReader(mapped.data[start : start+length]),
+		mapped: mapped,
+	}
+}
+
+// releaseMappedData releases the mapping and the memory of task data, they will be freed after all readers closed.
+func (t *localTaskStore) releaseMappedData() {
+	t.mappedLock.Lock()
+	defer t.mappedLock.Unlock()
+	if t.mapped != nil {
+		t.mapped.release()
+		t.mapped = nil
+	}
+
+	if t.memData != nil {
+		t.memData.release()
+		t.memData = nil
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"path"
+)
+
+var errPartialReclaimSkipped = errors.New("partial reclaim is skipped")
+
+// CanPartialReclaim indicates the task is an expired unfinished task, its downloaded pieces
+// can be reclaimed while the metadata is kept for resuming. A task is partially reclaimed
+// at most once, it is reclaimed entirely when expired again.
+func (t *localTaskStore) CanPartialReclaim() bool {
+	if !t.partialReclaim || t.invalid.Load() || !t.CanReclaim() {
+		return false
+	}
+
+	t.RLock()
+	defer t.RUnlock()
+	// sub tasks share the data file with parent
+	return !t.Done && !t.Punched && len(t.Pieces) > 0 && len(t.subtasks) == 0
+}
+
+// PartialReclaim punches holes in the data file for the downloaded pieces and drops them
+// from metadata, the data file keeps its size. It returns the reclaimed bytes.
+func (t *localTaskStore) PartialReclaim() (int64, error) {
+	data := path.Join(t.dataDir, taskData)
+	// the data file is linked to the output of simple strategy, do not punch it
+	stat, err := os.Lstat(data)
+	if err != nil {
+		return 0, err
+	}
+	if !stat.Mode().IsRegular() {
+		return 0, errPartialReclaimSkipped
+	}
+
+	file, err := os.OpenFile(data, os.O_RDWR, defaultFileMode)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	// punched pieces can not be the source of deduplication
+	if t.dedup != nil {
+		t.dedup.remove(PeerTaskMetadata{PeerID: t.PeerID, TaskID: t.TaskID})
+	}
+
+	t.Lock()
+	var reclaimed int64
+	for num, piece := range t.Pieces {
+		if err := punchHole(file, piece.Range.Start, piece.Range.Length); err != nil {
+			t.Unlock()
+			return reclaimed, err
+		}
+		// the punched piece reads as zeros, it must be downloaded again
+		delete(t.Pieces, num)
+		reclaimed += piece.Range.Length
+	}
+	t.Punched = true
+	t.Unlock()
+
+	t.Infof("partial reclaimed %d bytes, metadata is kept for resuming", reclaimed)
+	// keep the metadata for another expire time
+	t.touch()
+	return reclaimed, t.saveMetadata()
+}
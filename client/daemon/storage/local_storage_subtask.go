@@ -111,7 +111,7 @@ func (t *localSubTaskStore) WritePiece(ctx context.Context, req *WritePieceReque
 	if req.PieceMetadata.Md5 == "" {
 		t.Debugf("piece md5 not found in metadata, read from reader")
 		if get, ok := req.Reader.(digest.Reader); ok {
-			req.PieceMetadata.Md5 = get.Encoded()
+			req.PieceMetadata.Md5 = get.Digest()
 			t.Infof("read md5 from reader, value: %s", req.PieceMetadata.Md5)
 		} else {
 			t.Debugf("reader is not a digest.Reader")
@@ -281,40 +281,36 @@ func (t *localSubTaskStore) Store(ctx context.Context, req *StoreRequest) error
 		return nil
 	}
 
-	if req.OriginalOffset {
-		return hardlink(t.SugaredLoggerOnWith, req.Destination, t.parent.DataFilePath)
+	if req.OriginalOffset && req.InPlace {
+		t.Infof("write task data %s into file %q in place", t.Range.String(), req.Destination)
+		return writeInPlace(t.parent.DataFilePath, t.Range.Start, t.ContentLength, req.Destination, req.OutputOption)
 	}
 
-	_, err := os.Stat(req.Destination)
-	if err == nil {
-		// remove exist file
-		t.Infof("destination file %q exists, purge it first", req.Destination)
-		os.Remove(req.Destination)
+	if req.OriginalOffset && !req.ChangesAttributes() {
+		return hardlink(t.SugaredLoggerOnWith, req.Destination, t.parent.DataFilePath)
 	}
 
-	file, err := os.Open(t.parent.DataFilePath)
-	if err != nil {
-		t.Debugf("open tasks data error: %s", err)
-		return err
-	}
-	defer file.Close()
+	return StoreOutput(req.Destination, req.OutputOption, func(tmp string) error {
+		// the owner and permissions of output are specified, copy the whole task data
+		// with original offset instead of linking it
+		if req.OriginalOffset {
+			n, err := copyFile(t.parent.DataFilePath, 0, -1, tmp)
+			if err != nil {
+				t.Errorf("copy task data to file %q error: %s", req.Destination, err)
+				return err
+			}
+			t.Debugf("copied tasks data %d bytes to %s", n, req.Destination)
+			return nil
+		}
 
-	_, err = file.Seek(t.Range.Start, io.SeekStart)
-	if err != nil {
-		t.Debugf("task seek file error: %s", err)
-		return err
-	}
-	dstFile, err := os.OpenFile(req.Destination, os.O_CREATE|os.O_RDWR|os.O_TRUNC, defaultFileMode)
-	if err != nil {
-		t.Errorf("open tasks destination file error: %s", err)
-		return err
-	}
-	defer dstFile.Close()
-	// copy_file_range is valid in linux
-	// https://go-review.googlesource.com/c/go/+/229101/
-	n, err := io.Copy(dstFile, io.LimitReader(file, t.ContentLength))
-	t.Debugf("copied tasks data %d bytes to %s", n, req.Destination)
-	return err
+		n, err := copyFile(t.parent.DataFilePath, t.Range.Start, t.ContentLength, tmp)
+		if err != nil {
+			t.Errorf("copy task data to file %q error: %s", req.Destination, err)
+			return err
+		}
+		t.Debugf("copied tasks data %d bytes to %s", n, req.Destination)
+		return nil
+	})
 }
 
 func (t *localSubTaskStore) ValidateDigest(req *PeerTaskMetadata) error {
@@ -20,26 +20,33 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"os"
 	"path"
+	"runtime"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	testifyassert "github.com/stretchr/testify/assert"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/daemon/test"
 	clientutil "d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/internal/util"
 	"d7y.io/dragonfly/v2/pkg/digest"
 	_ "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
+	"d7y.io/dragonfly/v2/pkg/source"
+	"d7y.io/dragonfly/v2/pkg/unit"
 )
 
 func TestMain(m *testing.M) {
@@ -363,6 +370,52 @@ func TestLocalTaskStore_StoreTaskData_Simple(t *testing.T) {
 	assert.Equal(testData, bs, "data must match")
 }
 
+func TestLocalTaskStore_StoreTaskData_OutputAttributes(t *testing.T) {
+	for _, originalOffset := range []bool{true, false} {
+		t.Run(fmt.Sprintf("original offset %v", originalOffset), func(t *testing.T) {
+			assert := testifyassert.New(t)
+			dir := t.TempDir()
+			src := path.Join(dir, taskData)
+			dst := path.Join(dir, taskData+".copy")
+			testData := []byte("test data")
+			assert.Nil(os.WriteFile(src, testData, defaultFileMode))
+
+			ts := localTaskStore{
+				SugaredLoggerOnWith: logger.With("test", "localTaskStore"),
+				persistentMetadata: persistentMetadata{
+					TaskID:       "test",
+					DataFilePath: src,
+				},
+				dataDir: dir,
+			}
+			ts.lastAccess.Store(time.Now().UnixNano())
+			err := ts.Store(context.Background(), &StoreRequest{
+				CommonTaskRequest: CommonTaskRequest{
+					TaskID:      ts.TaskID,
+					Destination: dst,
+				},
+				StoreDataOnly:  true,
+				OriginalOffset: originalOffset,
+				OutputOption:   OutputOption{Mode: 0600},
+			})
+			assert.Nil(err)
+
+			bs, err := os.ReadFile(dst)
+			assert.Nil(err)
+			assert.Equal(testData, bs)
+
+			// output is copied, task data keeps its own inode and permissions
+			srcInfo, err := os.Stat(src)
+			assert.Nil(err)
+			dstInfo, err := os.Stat(dst)
+			assert.Nil(err)
+			assert.False(os.SameFile(srcInfo, dstInfo))
+			assert.Equal(defaultFileMode, srcInfo.Mode().Perm())
+			assert.Equal(os.FileMode(0600), dstInfo.Mode().Perm())
+		})
+	}
+}
+
 func calcFileMd5(filePath string, rg *clientutil.Range) (string, error) {
 	var md5String string
 	file, err := os.Open(filePath)
@@ -488,6 +541,7 @@ func TestLocalTaskStore_partialCompleted(t *testing.T) {
 	var testCases = []struct {
 		name            string
 		ContentLength   int64
+		PieceSize       uint32
 		ReadyPieceCount int32
 		Range           clientutil.Range
 		Found           bool
@@ -522,6 +576,17 @@ func TestLocalTaskStore_partialCompleted(t *testing.T) {
 			},
 			Found: false,
 		},
+		{
+			name:            "range bytes=x-y partial completed with recorded piece size",
+			ContentLength:   util.DefaultPieceSize * 10,
+			PieceSize:       util.DefaultPieceSize * 4,
+			ReadyPieceCount: 1,
+			Range: clientutil.Range{
+				Start:  1,
+				Length: util.DefaultPieceSize * 2,
+			},
+			Found: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -530,6 +595,7 @@ func TestLocalTaskStore_partialCompleted(t *testing.T) {
 			lts := &localTaskStore{
 				persistentMetadata: persistentMetadata{
 					ContentLength: tc.ContentLength,
+					PieceSize:     tc.PieceSize,
 					Pieces:        map[int32]PieceMetadata{},
 				},
 			}
@@ -541,3 +607,811 @@ func TestLocalTaskStore_partialCompleted(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalTaskStore_updatePieceSize(t *testing.T) {
+	var testCases = []struct {
+		name      string
+		pieceSize uint32
+		req       *WritePieceRequest
+		expect    uint32
+	}{
+		{
+			name: "first piece",
+			req: &WritePieceRequest{
+				PieceMetadata: PieceMetadata{Num: 0, Range: clientutil.Range{Start: 0, Length: 1024}},
+			},
+			expect: 1024,
+		},
+		{
+			name: "last piece",
+			req: &WritePieceRequest{
+				PieceMetadata: PieceMetadata{Num: 2, Range: clientutil.Range{Start: 2048, Length: 10}},
+			},
+			expect: 1024,
+		},
+		{
+			name:      "piece size is recorded",
+			pieceSize: 4096,
+			req: &WritePieceRequest{
+				PieceMetadata: PieceMetadata{Num: 2, Range: clientutil.Range{Start: 2048, Length: 10}},
+			},
+			expect: 4096,
+		},
+		{
+			name: "empty piece",
+			req: &WritePieceRequest{
+				PieceMetadata: PieceMetadata{Num: 0, Range: clientutil.Range{Start: 0, Length: 0}},
+			},
+			expect: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			lts := &localTaskStore{
+				persistentMetadata: persistentMetadata{
+					PieceSize: tc.pieceSize,
+				},
+			}
+			lts.updatePieceSize(tc.req)
+			assert.Equal(tc.expect, lts.PieceSize)
+		})
+	}
+}
+
+func TestStorageManager_FindResumableTask(t *testing.T) {
+	assert := testifyassert.New(t)
+	var (
+		taskID   = "task-resumable"
+		peerID   = "peer-resumable"
+		testData = []byte("test data")
+	)
+	dataDir, err := os.MkdirTemp("", "resumable")
+	assert.Nil(err)
+	defer os.RemoveAll(dataDir)
+
+	option := &config.StorageOption{
+		DataPath: dataDir,
+		TaskExpireTime: clientutil.Duration{
+			Duration: time.Minute,
+		},
+	}
+	sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+
+	ts, err := sm.RegisterTask(context.Background(),
+		&RegisterTaskRequest{
+			PeerTaskMetadata: PeerTaskMetadata{
+				PeerID: peerID,
+				TaskID: taskID,
+			},
+			ContentLength: int64(2 * len(testData)),
+			TotalPieces:   2,
+		})
+	assert.Nil(err)
+	assert.Nil(sm.FindResumableTask(taskID), "no piece downloaded")
+
+	_, err = ts.WritePiece(context.Background(), &WritePieceRequest{
+		PeerTaskMetadata: PeerTaskMetadata{
+			PeerID: peerID,
+			TaskID: taskID,
+		},
+		PieceMetadata: PieceMetadata{
+			Num:   0,
+			Md5:   calcPieceMd5(testData),
+			Range: clientutil.Range{Start: 0, Length: int64(len(testData))},
+			Style: commonv1.PieceStyle_PLAIN,
+		},
+		Reader: bytes.NewBuffer(testData),
+	})
+	assert.Nil(err)
+
+	// reload the checkpoint like daemon restarted
+	reloaded, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+
+	resumable := reloaded.FindResumableTask(taskID)
+	if assert.NotNil(resumable) {
+		assert.Equal(peerID, resumable.PeerID)
+		assert.Equal(int32(2), resumable.TotalPieces)
+
+		packet, err := resumable.Storage.GetPieces(context.Background(),
+			&commonv1.PieceTaskRequest{TaskId: taskID, StartNum: 0, Limit: 2})
+		assert.Nil(err)
+		assert.Len(packet.PieceInfos, 1)
+	}
+	assert.Nil(reloaded.FindCompletedTask(taskID))
+}
+
+func TestLocalTaskStore_ValidateDigest(t *testing.T) {
+	var (
+		taskID   = "task-validate"
+		peerID   = "peer-validate"
+		testData = [][]byte{[]byte("test data 0"), []byte("test data 1")}
+	)
+
+	tests := []struct {
+		name      string
+		algorithm string
+		mock      func(t *testing.T, ts *localTaskStore)
+		expect    func(t *testing.T, ts *localTaskStore, err error)
+	}{
+		{
+			name: "digest matched",
+			mock: func(t *testing.T, ts *localTaskStore) {},
+			expect: func(t *testing.T, ts *localTaskStore, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.False(ts.invalid.Load())
+			},
+		},
+		{
+			name: "remove corrupted pieces",
+			mock: func(t *testing.T, ts *localTaskStore) {
+				ts.PieceMd5Sign = digest.SHA256FromStrings("foo")
+				corruptPiece(t, ts, 1)
+			},
+			expect: func(t *testing.T, ts *localTaskStore, err error) {
+				assert := testifyassert.New(t)
+				assert.ErrorIs(err, ErrInvalidDigest)
+				var corrupted *CorruptedPiecesError
+				if assert.ErrorAs(err, &corrupted) {
+					assert.Equal([]int32{1}, corrupted.PieceNums)
+				}
+				assert.False(ts.invalid.Load())
+				assert.False(ts.Done)
+				assert.True(ts.resumable())
+				_, ok := ts.Pieces[1]
+				assert.False(ok)
+			},
+		},
+		{
+			name:      "remove corrupted pieces of sha256 digests",
+			algorithm: digest.AlgorithmSHA256,
+			mock: func(t *testing.T, ts *localTaskStore) {
+				ts.PieceMd5Sign = digest.SHA256FromStrings("foo")
+				corruptPiece(t, ts, 1)
+			},
+			expect: func(t *testing.T, ts *localTaskStore, err error) {
+				assert := testifyassert.New(t)
+				var corrupted *CorruptedPiecesError
+				if assert.ErrorAs(err, &corrupted) {
+					assert.Equal([]int32{1}, corrupted.PieceNums)
+				}
+				_, ok := ts.Pieces[0]
+				assert.True(ok)
+			},
+		},
+		{
+			name:      "corrupted pieces of sha256 digests not found",
+			algorithm: digest.AlgorithmSHA256,
+			mock: func(t *testing.T, ts *localTaskStore) {
+				ts.PieceMd5Sign = digest.SHA256FromStrings("foo")
+			},
+			expect: func(t *testing.T, ts *localTaskStore, err error) {
+				assert := testifyassert.New(t)
+				assert.Equal(ErrInvalidDigest, err)
+				assert.True(ts.invalid.Load())
+				assert.Len(ts.Pieces, 2)
+			},
+		},
+		{
+			name: "corrupted pieces not found",
+			mock: func(t *testing.T, ts *localTaskStore) {
+				ts.PieceMd5Sign = digest.SHA256FromStrings("foo")
+			},
+			expect: func(t *testing.T, ts *localTaskStore, err error) {
+				assert := testifyassert.New(t)
+				assert.Equal(ErrInvalidDigest, err)
+				assert.True(ts.invalid.Load())
+			},
+		},
+		{
+			name: "digest does not match after repaired",
+			mock: func(t *testing.T, ts *localTaskStore) {
+				ts.PieceMd5Sign = digest.SHA256FromStrings("foo")
+				ts.repaired.Store(true)
+				corruptPiece(t, ts, 1)
+			},
+			expect: func(t *testing.T, ts *localTaskStore, err error) {
+				assert := testifyassert.New(t)
+				assert.Equal(ErrInvalidDigest, err)
+				assert.True(ts.invalid.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy,
+				&config.StorageOption{
+					DataPath: t.TempDir(),
+					TaskExpireTime: clientutil.Duration{
+						Duration: time.Minute,
+					},
+				}, func(request CommonTaskRequest) {})
+			assert.Nil(err)
+
+			var (
+				pieceDigests []string
+				offset       int64
+			)
+			for _, data := range testData {
+				if tc.algorithm == digest.AlgorithmSHA256 {
+					pieceDigests = append(pieceDigests, digest.New(digest.AlgorithmSHA256, fmt.Sprintf("%x", sha256.Sum256(data))).String())
+					continue
+				}
+				pieceDigests = append(pieceDigests, calcPieceMd5(data))
+			}
+			driver, err := sm.RegisterTask(context.Background(),
+				&RegisterTaskRequest{
+					PeerTaskMetadata: PeerTaskMetadata{
+						PeerID: peerID,
+						TaskID: taskID,
+					},
+					ContentLength: int64(len(testData[0]) + len(testData[1])),
+					TotalPieces:   int32(len(testData)),
+					PieceMd5Sign:  digest.SHA256FromStrings(pieceDigests...),
+				})
+			assert.Nil(err)
+
+			for num, data := range testData {
+				_, err = driver.WritePiece(context.Background(), &WritePieceRequest{
+					PeerTaskMetadata: PeerTaskMetadata{
+						PeerID: peerID,
+						TaskID: taskID,
+					},
+					PieceMetadata: PieceMetadata{
+						Num:   int32(num),
+						Md5:   pieceDigests[num],
+						Range: clientutil.Range{Start: offset, Length: int64(len(data))},
+						Style: commonv1.PieceStyle_PLAIN,
+					},
+					Reader: bytes.NewBuffer(data),
+				})
+				assert.Nil(err)
+				offset += int64(len(data))
+			}
+
+			ts := driver.(*localTaskStore)
+			ts.Done = true
+			tc.mock(t, ts)
+			tc.expect(t, ts, ts.ValidateDigest(&PeerTaskMetadata{PeerID: peerID, TaskID: taskID}))
+		})
+	}
+}
+
+// corruptPiece overwrites the first byte of piece in data file.
+func corruptPiece(t *testing.T, ts *localTaskStore, num int32) {
+	file, err := os.OpenFile(ts.DataFilePath, os.O_RDWR, defaultFileMode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteAt([]byte{'x'}, ts.Pieces[num].Range.Start); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStorageManager_TryGCWithQuota(t *testing.T) {
+	assert := testifyassert.New(t)
+	dataDir, err := os.MkdirTemp("", "quota")
+	assert.Nil(err)
+	defer os.RemoveAll(dataDir)
+
+	option := &config.StorageOption{
+		DataPath: dataDir,
+		TaskExpireTime: clientutil.Duration{
+			Duration: 24 * time.Hour,
+		},
+		DiskGCThreshold: 15,
+	}
+	manager, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	sm := manager.(*storageManager)
+
+	// the least recently used task is registered first
+	var metas []PeerTaskMetadata
+	for i := 0; i < 2; i++ {
+		meta := PeerTaskMetadata{
+			PeerID: fmt.Sprintf("peer-quota-%d", i),
+			TaskID: fmt.Sprintf("task-quota-%d", i),
+		}
+		ts, err := sm.RegisterTask(context.Background(), &RegisterTaskRequest{
+			PeerTaskMetadata: meta,
+			ContentLength:    10,
+			TotalPieces:      1,
+		})
+		assert.Nil(err)
+		ts.(*localTaskStore).lastAccess.Store(time.Now().Add(time.Duration(i-2) * time.Hour).UnixNano())
+		metas = append(metas, meta)
+	}
+
+	reclaimedTasks := testutil.ToFloat64(metrics.StorageReclaimedTaskCount)
+	reclaimedBytes := testutil.ToFloat64(metrics.StorageReclaimedBytesCount)
+	evictedTasks := testutil.ToFloat64(metrics.StorageQuotaEvictedTaskCount)
+
+	// first gc marks the least recently used task, second gc reclaims it
+	for i := 0; i < 2; i++ {
+		_, err = sm.TryGC()
+		assert.Nil(err)
+	}
+
+	_, ok := sm.LoadTask(metas[0])
+	assert.False(ok)
+	_, ok = sm.LoadTask(metas[1])
+	assert.True(ok)
+
+	assert.Equal(float64(10), testutil.ToFloat64(metrics.StorageUsedBytes))
+	assert.Equal(reclaimedTasks+1, testutil.ToFloat64(metrics.StorageReclaimedTaskCount))
+	assert.Equal(reclaimedBytes+10, testutil.ToFloat64(metrics.StorageReclaimedBytesCount))
+	assert.Equal(evictedTasks+1, testutil.ToFloat64(metrics.StorageQuotaEvictedTaskCount))
+}
+
+func TestStorageManager_PinTask(t *testing.T) {
+	assert := testifyassert.New(t)
+	dataDir, err := os.MkdirTemp("", "pin")
+	assert.Nil(err)
+	defer os.RemoveAll(dataDir)
+
+	option := &config.StorageOption{
+		DataPath: dataDir,
+		TaskExpireTime: clientutil.Duration{
+			Duration: time.Minute,
+		},
+	}
+	manager, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	sm := manager.(*storageManager)
+
+	var metas []PeerTaskMetadata
+	for i := 0; i < 2; i++ {
+		meta := PeerTaskMetadata{
+			PeerID: fmt.Sprintf("peer-pin-%d", i),
+			TaskID: fmt.Sprintf("task-pin-%d", i),
+		}
+		_, err := sm.RegisterTask(context.Background(), &RegisterTaskRequest{
+			PeerTaskMetadata: meta,
+			ContentLength:    10,
+			TotalPieces:      1,
+		})
+		assert.Nil(err)
+		metas = append(metas, meta)
+	}
+
+	assert.ErrorIs(sm.PinTask("task-pin-unknown", 0), ErrTaskNotFound)
+	assert.ErrorIs(sm.UnpinTask("task-pin-unknown"), ErrTaskNotFound)
+	assert.Error(sm.PinTask(metas[0].TaskID, -time.Hour))
+	assert.Nil(sm.PinTask(metas[0].TaskID, 0))
+
+	// pinning without ttl is limited to the max pin ttl
+	ts, ok := sm.LoadTask(metas[0])
+	if assert.True(ok) {
+		expireAt := time.Unix(0, ts.(*localTaskStore).PinExpireAt)
+		assert.WithinDuration(time.Now().Add(config.DefaultMaxPinTTL), expireAt, time.Minute)
+	}
+
+	// expire all tasks
+	for _, meta := range metas {
+		ts, ok := sm.LoadTask(meta)
+		assert.True(ok)
+		ts.(*localTaskStore).lastAccess.Store(time.Now().Add(-time.Hour).UnixNano())
+	}
+
+	// pinned state is persisted in metadata
+	reloaded, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	ts, ok = reloaded.(*storageManager).LoadTask(metas[0])
+	if assert.True(ok) {
+		assert.True(ts.(*localTaskStore).Pinned)
+	}
+
+	// first gc marks the expired task, second gc reclaims it
+	for i := 0; i < 2; i++ {
+		_, err = sm.TryGC()
+		assert.Nil(err)
+	}
+
+	ts, ok = sm.LoadTask(metas[0])
+	assert.True(ok, "pinned task is not reclaimed")
+	_, ok = sm.LoadTask(metas[1])
+	assert.False(ok)
+
+	lts := ts.(*localTaskStore)
+	assert.False(lts.CanReclaim())
+	lts.PinExpireAt = time.Now().Add(-time.Second).UnixNano()
+	lts.lastAccess.Store(time.Now().Add(-time.Hour).UnixNano())
+	assert.True(lts.CanReclaim(), "pin expired")
+
+	assert.Nil(sm.PinTask(metas[0].TaskID, time.Hour))
+	assert.False(lts.CanReclaim())
+
+	// ttl exceeding the max pin ttl is limited
+	sm.storeOption.MaxPinTTL.Duration = time.Minute
+	assert.Nil(sm.PinTask(metas[0].TaskID, time.Hour))
+	assert.WithinDuration(time.Now().Add(time.Minute), time.Unix(0, lts.PinExpireAt), 10*time.Second)
+	assert.Nil(sm.UnpinTask(metas[0].TaskID))
+	assert.False(lts.Pinned)
+	assert.False(lts.CanReclaim(), "task is touched when unpinned")
+	lts.lastAccess.Store(time.Now().Add(-time.Hour).UnixNano())
+	assert.True(lts.CanReclaim())
+}
+
+func TestStorageManager_MarkRevalidated(t *testing.T) {
+	assert := testifyassert.New(t)
+	dataDir, err := os.MkdirTemp("", "revalidate")
+	assert.Nil(err)
+	defer os.RemoveAll(dataDir)
+
+	option := &config.StorageOption{
+		DataPath: dataDir,
+		TaskExpireTime: clientutil.Duration{
+			Duration: time.Minute,
+		},
+		RevalidateInterval: clientutil.Duration{
+			Duration: time.Hour,
+		},
+	}
+	manager, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	sm := manager.(*storageManager)
+
+	meta := PeerTaskMetadata{
+		PeerID: "peer-revalidate",
+		TaskID: "task-revalidate",
+	}
+	ts, err := sm.RegisterTask(context.Background(), &RegisterTaskRequest{
+		PeerTaskMetadata: meta,
+		ContentLength:    10,
+		TotalPieces:      1,
+	})
+	assert.Nil(err)
+	assert.Nil(ts.UpdateTask(context.Background(), &UpdateTaskRequest{
+		PeerTaskMetadata: meta,
+		ExpireInfo: &source.ExpireInfo{
+			LastModified: "Sun, 06 Jun 2021 12:52:30 GMT",
+			ETag:         "foo",
+		},
+	}))
+	lts := ts.(*localTaskStore)
+	lts.Done = true
+
+	// validated recently
+	reuse := sm.FindCompletedTask(meta.TaskID)
+	if assert.NotNil(reuse) {
+		assert.Nil(reuse.ExpireInfo)
+	}
+
+	lts.ExpireInfo.ValidatedAt = time.Now().Add(-2 * time.Hour).UnixNano()
+	reuse = sm.FindCompletedTask(meta.TaskID)
+	if assert.NotNil(reuse) && assert.NotNil(reuse.ExpireInfo) {
+		assert.Equal("foo", reuse.ExpireInfo.ETag)
+		assert.Equal("Sun, 06 Jun 2021 12:52:30 GMT", reuse.ExpireInfo.LastModified)
+	}
+
+	// not modified in origin
+	assert.ErrorIs(sm.MarkRevalidated("task-revalidate-unknown", false), ErrTaskNotFound)
+	assert.Nil(sm.MarkRevalidated(meta.TaskID, false))
+	reuse = sm.FindCompletedTask(meta.TaskID)
+	if assert.NotNil(reuse) {
+		assert.Nil(reuse.ExpireInfo)
+	}
+
+	// validators are persisted in metadata
+	reloaded, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	reloadedTask, ok := reloaded.(*storageManager).LoadTask(meta)
+	if assert.True(ok) && assert.NotNil(reloadedTask.(*localTaskStore).ExpireInfo) {
+		assert.Equal("foo", reloadedTask.(*localTaskStore).ExpireInfo.ETag)
+	}
+
+	// modified in origin
+	assert.Nil(sm.MarkRevalidated(meta.TaskID, true))
+	assert.Nil(sm.FindCompletedTask(meta.TaskID))
+	assert.True(lts.CanReclaim())
+}
+
+func TestStorageManager_CompletedTaskIDs(t *testing.T) {
+	assert := testifyassert.New(t)
+	dataDir, err := os.MkdirTemp("", "completed")
+	assert.Nil(err)
+	defer os.RemoveAll(dataDir)
+
+	option := &config.StorageOption{
+		DataPath: dataDir,
+		TaskExpireTime: clientutil.Duration{
+			Duration: time.Minute,
+		},
+	}
+	manager, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	sm := manager.(*storageManager)
+
+	var stores []*localTaskStore
+	for _, meta := range []PeerTaskMetadata{
+		{PeerID: "peer-completed-0", TaskID: "task-completed"},
+		{PeerID: "peer-completed-1", TaskID: "task-completed"},
+		{PeerID: "peer-running", TaskID: "task-running"},
+		{PeerID: "peer-reclaimed", TaskID: "task-reclaimed"},
+	} {
+		ts, err := sm.RegisterTask(context.Background(), &RegisterTaskRequest{
+			PeerTaskMetadata: meta,
+			ContentLength:    10,
+			TotalPieces:      1,
+		})
+		assert.Nil(err)
+		stores = append(stores, ts.(*localTaskStore))
+	}
+	stores[0].Done = true
+	stores[1].Done = true
+	stores[3].Done = true
+	stores[3].MarkReclaim()
+
+	assert.Equal([]string{"task-completed"}, sm.CompletedTaskIDs())
+}
+
+func TestLocalTaskStore_MmapRead(t *testing.T) {
+	assert := testifyassert.New(t)
+	var (
+		taskID   = "task-mmap"
+		peerID   = "peer-mmap"
+		testData = [][]byte{[]byte("test data 0"), []byte("test data 1")}
+	)
+
+	sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy,
+		&config.StorageOption{
+			DataPath: t.TempDir(),
+			TaskExpireTime: clientutil.Duration{
+				Duration: time.Minute,
+			},
+			MmapRead: true,
+		}, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+
+	driver, err := sm.RegisterTask(context.Background(),
+		&RegisterTaskRequest{
+			PeerTaskMetadata: PeerTaskMetadata{
+				PeerID: peerID,
+				TaskID: taskID,
+			},
+			ContentLength: int64(len(testData[0]) + len(testData[1])),
+			TotalPieces:   int32(len(testData)),
+		})
+	assert.Nil(err)
+
+	var offset int64
+	for num, data := range testData {
+		_, err = driver.WritePiece(context.Background(), &WritePieceRequest{
+			PeerTaskMetadata: PeerTaskMetadata{
+				PeerID: peerID,
+				TaskID: taskID,
+			},
+			PieceMetadata: PieceMetadata{
+				Num:   int32(num),
+				Md5:   calcPieceMd5(data),
+				Range: clientutil.Range{Start: offset, Length: int64(len(data))},
+				Style: commonv1.PieceStyle_PLAIN,
+			},
+			Reader: bytes.NewBuffer(data),
+		})
+		assert.Nil(err)
+		offset += int64(len(data))
+	}
+
+	ts := driver.(*localTaskStore)
+
+	// unfinished task reads from file
+	rd, cl, err := ts.ReadPiece(context.Background(), &ReadPieceRequest{PieceMetadata: PieceMetadata{Num: 0}})
+	assert.Nil(err)
+	_, ok := rd.(*mappedReader)
+	assert.False(ok)
+	cl.Close()
+
+	ts.Done = true
+	for num, data := range testData {
+		rd, cl, err := ts.ReadPiece(context.Background(), &ReadPieceRequest{PieceMetadata: PieceMetadata{Num: int32(num)}})
+		assert.Nil(err)
+		if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+			_, ok := rd.(*mappedReader)
+			assert.True(ok)
+		}
+		read, err := io.ReadAll(rd)
+		assert.Nil(err)
+		assert.Equal(data, read)
+		assert.Nil(cl.Close())
+	}
+
+	rc, err := ts.ReadAllPieces(context.Background(), &ReadAllPiecesRequest{})
+	assert.Nil(err)
+
+	// data is still readable when reclaimed before reader closed
+	ts.lastAccess.Store(time.Now().Add(-1 * time.Hour).UnixNano())
+	assert.Nil(ts.Reclaim())
+	read, err := io.ReadAll(rc)
+	assert.Nil(err)
+	assert.Equal(append(append([]byte{}, testData[0]...), testData[1]...), read)
+	assert.Nil(rc.Close())
+	assert.Nil(ts.mapped)
+}
+
+func TestMemoryTier_TryGC(t *testing.T) {
+	assert := testifyassert.New(t)
+	testData := []byte("test data 0")
+	option := &config.StorageOption{
+		DataPath: t.TempDir(),
+		TaskExpireTime: clientutil.Duration{
+			Duration: time.Minute,
+		},
+		MemoryTier: config.MemoryTierOption{
+			Enable:       true,
+			Watermark:    unit.Bytes(len(testData) + 1),
+			MaxTaskSize:  unit.Bytes(len(testData)),
+			HotThreshold: 2,
+		},
+	}
+	sm, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy, option, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+
+	var tasks []*localTaskStore
+	for _, id := range []string{"task-hot", "task-warm", "task-cold"} {
+		driver, err := sm.RegisterTask(context.Background(),
+			&RegisterTaskRequest{
+				PeerTaskMetadata: PeerTaskMetadata{
+					PeerID: "peer-" + id,
+					TaskID: id,
+				},
+				ContentLength: int64(len(testData)),
+				TotalPieces:   1,
+			})
+		assert.Nil(err)
+
+		_, err = driver.WritePiece(context.Background(), &WritePieceRequest{
+			PeerTaskMetadata: PeerTaskMetadata{
+				PeerID: "peer-" + id,
+				TaskID: id,
+			},
+			PieceMetadata: PieceMetadata{
+				Num:   0,
+				Md5:   calcPieceMd5(testData),
+				Range: clientutil.Range{Start: 0, Length: int64(len(testData))},
+				Style: commonv1.PieceStyle_PLAIN,
+			},
+			Reader: bytes.NewBuffer(testData),
+		})
+		assert.Nil(err)
+
+		ts := driver.(*localTaskStore)
+		ts.Done = true
+		tasks = append(tasks, ts)
+	}
+
+	getPieces := func(ts *localTaskStore, count int) {
+		for i := 0; i < count; i++ {
+			_, err := ts.GetPieces(context.Background(), &commonv1.PieceTaskRequest{TaskId: ts.TaskID, Limit: 1})
+			assert.Nil(err)
+		}
+	}
+
+	// only the hottest task is promoted under watermark
+	getPieces(tasks[0], 4)
+	getPieces(tasks[1], 3)
+	getPieces(tasks[2], 1)
+	tier := newMemoryTier(sm.(*storageManager), &option.MemoryTier)
+	ok, err := tier.TryGC()
+	assert.True(ok)
+	assert.Nil(err)
+	assert.NotNil(tasks[0].memData)
+	assert.Nil(tasks[1].memData)
+	assert.Nil(tasks[2].memData)
+
+	// pieces of promoted task are served from memory
+	rd, cl, err := tasks[0].ReadPiece(context.Background(), &ReadPieceRequest{PieceMetadata: PieceMetadata{Num: 0}})
+	assert.Nil(err)
+	_, ok = rd.(*mappedReader)
+	assert.True(ok)
+
+	// the hotter task replaces the cooling one, the reader is still readable after demoted
+	getPieces(tasks[1], 6)
+	ok, err = tier.TryGC()
+	assert.True(ok)
+	assert.Nil(err)
+	assert.Nil(tasks[0].memData)
+	assert.NotNil(tasks[1].memData)
+	read, err := io.ReadAll(rd)
+	assert.Nil(err)
+	assert.Equal(testData, read)
+	assert.Nil(cl.Close())
+
+	// task larger than max task size is not promoted
+	option.MemoryTier.MaxTaskSize = 1
+	ok, err = tier.TryGC()
+	assert.True(ok)
+	assert.Nil(err)
+	assert.Nil(tasks[1].memData)
+}
+
+func TestLocalTaskStore_PartialReclaim(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("punch hole only works on linux")
+	}
+
+	assert := testifyassert.New(t)
+	var (
+		taskID   = "task-punch"
+		peerID   = "peer-punch"
+		testData = bytes.Repeat([]byte("test data"), 1024)
+	)
+
+	s, err := NewStorageManager(config.SimpleLocalTaskStoreStrategy,
+		&config.StorageOption{
+			DataPath: t.TempDir(),
+			TaskExpireTime: clientutil.Duration{
+				Duration: time.Minute,
+			},
+			PartialReclaim: true,
+		}, func(request CommonTaskRequest) {})
+	assert.Nil(err)
+	sm := s.(*storageManager)
+
+	meta := PeerTaskMetadata{PeerID: peerID, TaskID: taskID}
+	driver, err := sm.RegisterTask(context.Background(),
+		&RegisterTaskRequest{
+			PeerTaskMetadata: meta,
+			ContentLength:    int64(2 * len(testData)),
+			TotalPieces:      2,
+		})
+	assert.Nil(err)
+
+	// only the first piece is downloaded
+	_, err = driver.WritePiece(context.Background(), &WritePieceRequest{
+		PeerTaskMetadata: meta,
+		PieceMetadata: PieceMetadata{
+			Num:   0,
+			Md5:   calcPieceMd5(testData),
+			Range: clientutil.Range{Start: 0, Length: int64(len(testData))},
+			Style: commonv1.PieceStyle_PLAIN,
+		},
+		Reader: bytes.NewBuffer(testData),
+	})
+	assert.Nil(err)
+
+	ts := driver.(*localTaskStore)
+	assert.False(ts.CanPartialReclaim())
+
+	// expired unfinished task is partially reclaimed
+	ts.lastAccess.Store(time.Now().Add(-1 * time.Hour).UnixNano())
+	assert.True(ts.CanPartialReclaim())
+	_, err = sm.TryGC()
+	assert.Nil(err)
+
+	_, ok := sm.LoadTask(meta)
+	assert.True(ok)
+	assert.False(ts.reclaimMarked.Load())
+	assert.True(ts.Punched)
+	assert.Len(ts.Pieces, 0)
+	assert.Equal(int64(2*len(testData)), ts.ContentLength)
+
+	data, err := os.ReadFile(ts.DataFilePath)
+	assert.Nil(err)
+	assert.Equal(make([]byte, len(testData)), data[:len(testData)])
+
+	// metadata is kept for resuming
+	resumable := sm.FindResumableTask(taskID)
+	assert.NotNil(resumable)
+	assert.Equal(peerID, resumable.PeerID)
+	assert.Equal(int32(2), resumable.TotalPieces)
+
+	// task is reclaimed entirely when expired again
+	ts.lastAccess.Store(time.Now().Add(-1 * time.Hour).UnixNano())
+	assert.False(ts.CanPartialReclaim())
+	_, err = sm.TryGC()
+	assert.Nil(err)
+	assert.True(ts.reclaimMarked.Load())
+	_, err = sm.TryGC()
+	assert.Nil(err)
+	_, ok = sm.LoadTask(meta)
+	assert.False(ok)
+}
@@ -0,0 +1,139 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"io"
+	"os"
+	"sort"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	MemoryTierGCName = "MemoryTier"
+
+	// memoryTierHeatDecay decays the heat of tasks in every evaluation,
+	// the tasks are demoted when piece requests are stopped for a few intervals
+	memoryTierHeatDecay = 0.5
+)
+
+// memoryTier promotes hot tasks from disk to memory and demotes cold ones,
+// it is evaluated with gc interval by gc manager.
+type memoryTier struct {
+	manager *storageManager
+	option  *config.MemoryTierOption
+}
+
+func newMemoryTier(manager *storageManager, option *config.MemoryTierOption) *memoryTier {
+	return &memoryTier{
+		manager: manager,
+		option:  option,
+	}
+}
+
+// TryGC updates the heat of tasks, then keeps the hottest tasks in memory under watermark.
+func (m *memoryTier) TryGC() (bool, error) {
+	var tasks []*localTaskStore
+	m.manager.tasks.Range(func(_, val any) bool {
+		task, ok := val.(*localTaskStore)
+		if !ok {
+			return true
+		}
+
+		task.heat = task.heat*memoryTierHeatDecay + float64(task.accessCount.Swap(0))
+		tasks = append(tasks, task)
+		return true
+	})
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].heat > tasks[j].heat
+	})
+
+	var usedBytes int64
+	for _, task := range tasks {
+		if m.promotable(task) && usedBytes+task.ContentLength <= int64(m.option.Watermark) {
+			if err := task.promoteToMemory(); err != nil {
+				task.Warnf("promote task to memory error: %s", err)
+				continue
+			}
+
+			usedBytes += task.ContentLength
+			continue
+		}
+
+		task.demoteFromMemory()
+	}
+
+	logger.Debugf("memory tier holds %d bytes task data", usedBytes)
+	return true, nil
+}
+
+func (m *memoryTier) promotable(task *localTaskStore) bool {
+	return task.heat >= float64(m.option.HotThreshold) &&
+		task.Done && !task.invalid.Load() && !task.reclaimMarked.Load() &&
+		task.ContentLength > 0 && task.ContentLength <= int64(m.option.MaxTaskSize)
+}
+
+// promoteToMemory loads the task data in memory, pieces are served from memory until demoted.
+func (t *localTaskStore) promoteToMemory() error {
+	t.mappedLock.Lock()
+	defer t.mappedLock.Unlock()
+	if t.memData != nil {
+		return nil
+	}
+
+	file, err := os.Open(t.DataFilePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data := make([]byte, t.ContentLength)
+	if _, err := io.ReadFull(file, data); err != nil {
+		return err
+	}
+
+	t.Infof("promote task to memory, length: %d, heat: %.1f", t.ContentLength, t.heat)
+	t.memData = &mappedData{data: data, heap: true, log: t.SugaredLoggerOnWith}
+	return nil
+}
+
+// demoteFromMemory releases the task data in memory, it will be freed after all readers closed.
+func (t *localTaskStore) demoteFromMemory() {
+	t.mappedLock.Lock()
+	defer t.mappedLock.Unlock()
+	if t.memData == nil {
+		return
+	}
+
+	t.Infof("demote task from memory, heat: %.1f", t.heat)
+	t.memData.release()
+	t.memData = nil
+}
+
+// acquireMemoryData returns the task data in memory, nil will be returned when the task is not promoted.
+func (t *localTaskStore) acquireMemoryData() *mappedData {
+	t.mappedLock.Lock()
+	defer t.mappedLock.Unlock()
+	if t.memData == nil || !t.memData.acquire() {
+		return nil
+	}
+
+	return t.memData
+}
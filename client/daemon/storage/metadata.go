@@ -31,12 +31,34 @@ type persistentMetadata struct {
 	TaskMeta      map[string]string       `json:"taskMeta"`
 	ContentLength int64                   `json:"contentLength"`
 	TotalPieces   int32                   `json:"totalPieces"`
+	PieceSize     uint32                  `json:"pieceSize,omitempty"`
 	PeerID        string                  `json:"peerID"`
 	Pieces        map[int32]PieceMetadata `json:"pieces"`
 	PieceMd5Sign  string                  `json:"pieceMd5Sign"`
 	DataFilePath  string                  `json:"dataFilePath"`
 	Done          bool                    `json:"done"`
 	Header        *source.Header          `json:"header"`
+	// Pinned tasks are excluded from gc until unpinned or PinExpireAt is reached
+	Pinned bool `json:"pinned,omitempty"`
+	// PinExpireAt is the unix nano time when the pin expires, 0 means never expire
+	PinExpireAt int64 `json:"pinExpireAt,omitempty"`
+	// ExpireInfo is the validators of origin, it is used to revalidate the task with origin
+	ExpireInfo *ExpireInfo `json:"expireInfo,omitempty"`
+	// Punched indicates the downloaded pieces of the unfinished task were reclaimed by punching holes
+	Punched bool `json:"punched,omitempty"`
+	// Digest is the content digest of url meta which the task data is verified against,
+	// tasks of different urls with the same digest share the data
+	Digest string `json:"digest,omitempty"`
+	// URL is the original url of task, it is empty for tasks stored by earlier versions
+	URL string `json:"url,omitempty"`
+}
+
+// ExpireInfo records the validators of origin response.
+type ExpireInfo struct {
+	LastModified string `json:"lastModified,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	// ValidatedAt is the unix nano time when the task is downloaded or revalidated with origin
+	ValidatedAt int64 `json:"validatedAt,omitempty"`
 }
 
 type PeerTaskMetadata struct {
@@ -62,6 +84,7 @@ type CommonTaskRequest struct {
 
 type RegisterTaskRequest struct {
 	PeerTaskMetadata
+	URL             string
 	DesiredLocation string
 	ContentLength   int64
 	TotalPieces     int32
@@ -85,6 +108,13 @@ type StoreRequest struct {
 	TotalPieces   int32
 	// OriginalOffset stands keep original offset in the target file, if the target file is not original file, return error
 	OriginalOffset bool
+	// InPlace stands write the task data into the target file at original offset instead of hardlink, works with OriginalOffset,
+	// the target file is not truncated, so concurrent range tasks can write into one pre-allocated target file
+	InPlace bool
+	// Range stands the range of task data written in place, the whole task data is written when it is nil
+	Range *util.Range
+	// OutputOption stands the sync, owner and permissions of the destination file
+	OutputOption
 }
 
 type ReadPieceRequest struct {
@@ -109,6 +139,10 @@ type UpdateTaskRequest struct {
 	TotalPieces   int32
 	PieceMd5Sign  string
 	Header        *source.Header
+	// ExpireInfo is the validators of origin response
+	ExpireInfo *source.ExpireInfo
+	// Digest is the content digest of url meta which the task data is verified against
+	Digest string
 }
 
 type ReusePeerTask struct {
@@ -118,4 +152,7 @@ type ReusePeerTask struct {
 	PieceMd5Sign  string
 	Header        *source.Header
 	Storage       TaskStorageDriver
+	// ExpireInfo is the validators to revalidate the task with origin before reusing,
+	// it is nil when the task does not need revalidation
+	ExpireInfo *source.ExpireInfo
 }
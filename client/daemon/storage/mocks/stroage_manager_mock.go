@@ -299,6 +299,34 @@ func (mr *MockManagerMockRecorder) CleanUp() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CleanUp", reflect.TypeOf((*MockManager)(nil).CleanUp))
 }
 
+// CompletedTaskIDs mocks base method.
+func (m *MockManager) CompletedTaskIDs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletedTaskIDs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// CompletedTaskIDs indicates an expected call of CompletedTaskIDs.
+func (mr *MockManagerMockRecorder) CompletedTaskIDs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletedTaskIDs", reflect.TypeOf((*MockManager)(nil).CompletedTaskIDs))
+}
+
+// CompletedTaskURLs mocks base method.
+func (m *MockManager) CompletedTaskURLs() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletedTaskURLs")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// CompletedTaskURLs indicates an expected call of CompletedTaskURLs.
+func (mr *MockManagerMockRecorder) CompletedTaskURLs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletedTaskURLs", reflect.TypeOf((*MockManager)(nil).CompletedTaskURLs))
+}
+
 // FindCompletedSubTask mocks base method.
 func (m *MockManager) FindCompletedSubTask(taskID string) *storage.ReusePeerTask {
 	m.ctrl.T.Helper()
@@ -327,6 +355,20 @@ func (mr *MockManagerMockRecorder) FindCompletedTask(taskID interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCompletedTask", reflect.TypeOf((*MockManager)(nil).FindCompletedTask), taskID)
 }
 
+// FindCompletedTaskByDigest mocks base method.
+func (m *MockManager) FindCompletedTaskByDigest(digest string) *storage.ReusePeerTask {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindCompletedTaskByDigest", digest)
+	ret0, _ := ret[0].(*storage.ReusePeerTask)
+	return ret0
+}
+
+// FindCompletedTaskByDigest indicates an expected call of FindCompletedTaskByDigest.
+func (mr *MockManagerMockRecorder) FindCompletedTaskByDigest(digest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindCompletedTaskByDigest", reflect.TypeOf((*MockManager)(nil).FindCompletedTaskByDigest), digest)
+}
+
 // FindPartialCompletedTask mocks base method.
 func (m *MockManager) FindPartialCompletedTask(taskID string, rg *util.Range) *storage.ReusePeerTask {
 	m.ctrl.T.Helper()
@@ -341,6 +383,20 @@ func (mr *MockManagerMockRecorder) FindPartialCompletedTask(taskID, rg interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPartialCompletedTask", reflect.TypeOf((*MockManager)(nil).FindPartialCompletedTask), taskID, rg)
 }
 
+// FindResumableTask mocks base method.
+func (m *MockManager) FindResumableTask(taskID string) *storage.ReusePeerTask {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindResumableTask", taskID)
+	ret0, _ := ret[0].(*storage.ReusePeerTask)
+	return ret0
+}
+
+// FindResumableTask indicates an expected call of FindResumableTask.
+func (mr *MockManagerMockRecorder) FindResumableTask(taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindResumableTask", reflect.TypeOf((*MockManager)(nil).FindResumableTask), taskID)
+}
+
 // GetExtendAttribute mocks base method.
 func (m *MockManager) GetExtendAttribute(ctx context.Context, req *storage.PeerTaskMetadata) (*v1.ExtendAttribute, error) {
 	m.ctrl.T.Helper()
@@ -413,6 +469,34 @@ func (mr *MockManagerMockRecorder) Keep() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Keep", reflect.TypeOf((*MockManager)(nil).Keep))
 }
 
+// MarkRevalidated mocks base method.
+func (m *MockManager) MarkRevalidated(taskID string, modified bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRevalidated", taskID, modified)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRevalidated indicates an expected call of MarkRevalidated.
+func (mr *MockManagerMockRecorder) MarkRevalidated(taskID, modified interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRevalidated", reflect.TypeOf((*MockManager)(nil).MarkRevalidated), taskID, modified)
+}
+
+// PinTask mocks base method.
+func (m *MockManager) PinTask(taskID string, ttl time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinTask", taskID, ttl)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PinTask indicates an expected call of PinTask.
+func (mr *MockManagerMockRecorder) PinTask(taskID, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinTask", reflect.TypeOf((*MockManager)(nil).PinTask), taskID, ttl)
+}
+
 // ReadAllPieces mocks base method.
 func (m *MockManager) ReadAllPieces(ctx context.Context, req *storage.ReadAllPiecesRequest) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -488,6 +572,20 @@ func (mr *MockManagerMockRecorder) Store(ctx, req interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockManager)(nil).Store), ctx, req)
 }
 
+// UnpinTask mocks base method.
+func (m *MockManager) UnpinTask(taskID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnpinTask", taskID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnpinTask indicates an expected call of UnpinTask.
+func (mr *MockManagerMockRecorder) UnpinTask(taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnpinTask", reflect.TypeOf((*MockManager)(nil).UnpinTask), taskID)
+}
+
 // UnregisterTask mocks base method.
 func (m *MockManager) UnregisterTask(ctx context.Context, req storage.CommonTaskRequest) error {
 	m.ctrl.T.Helper()
@@ -516,6 +614,20 @@ func (mr *MockManagerMockRecorder) UpdateTask(ctx, req interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockManager)(nil).UpdateTask), ctx, req)
 }
 
+// Usage mocks base method.
+func (m *MockManager) Usage() *storage.Usage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Usage")
+	ret0, _ := ret[0].(*storage.Usage)
+	return ret0
+}
+
+// Usage indicates an expected call of Usage.
+func (mr *MockManagerMockRecorder) Usage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Usage", reflect.TypeOf((*MockManager)(nil).Usage))
+}
+
 // ValidateDigest mocks base method.
 func (m *MockManager) ValidateDigest(req *storage.PeerTaskMetadata) error {
 	m.ctrl.T.Helper()
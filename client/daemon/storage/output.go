@@ -0,0 +1,184 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// OutputOption is the option of storing output file.
+type OutputOption struct {
+	// Sync stands sync the destination file and directory to disk after stored
+	Sync bool
+	// Uid and Gid stand the owner of the destination file, zero keeps the owner unchanged
+	Uid int64
+	Gid int64
+	// Mode stands the permissions of the destination file, zero keeps the permissions unchanged
+	Mode os.FileMode
+}
+
+// ChangesAttributes returns whether the owner or permissions of output are requested,
+// the output must not share the inode with task data then, otherwise the requested owner
+// and permissions are applied to the task data which is served to other peers.
+func (o OutputOption) ChangesAttributes() bool {
+	return o.Uid != 0 || o.Gid != 0 || o.Mode != 0
+}
+
+// StoreOutput stores output to a temporary file in the directory of destination with store function,
+// then renames it to destination, so partially written destination is never observed by consumers.
+// The owner and permissions of option are applied before renaming, and when option.Sync is true,
+// the output file and the directory are synced to disk.
+func StoreOutput(destination string, option OutputOption, store func(tmp string) error) error {
+	tmp := filepath.Join(filepath.Dir(destination), fmt.Sprintf(".%s.%s.tmp", filepath.Base(destination), uuid.NewString()))
+	if err := store(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := ChangeOutputAttributes(tmp, option); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if option.Sync {
+		if err := syncPath(tmp); err != nil {
+			os.Remove(tmp)
+			return err
+		}
+	}
+
+	if err := os.Rename(tmp, destination); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Rename does nothing when tmp and destination are links to the same file,
+	// e.g. destination is already a hardlink of the stored file, remove tmp then.
+	if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if option.Sync {
+		return syncPath(filepath.Dir(destination))
+	}
+
+	return nil
+}
+
+// ChangeOutputAttributes changes the owner and permissions of output file with option.
+func ChangeOutputAttributes(name string, option OutputOption) error {
+	if option.Uid != 0 || option.Gid != 0 {
+		uid, gid := -1, -1
+		if option.Uid != 0 {
+			uid = int(option.Uid)
+		}
+		if option.Gid != 0 {
+			gid = int(option.Gid)
+		}
+
+		if err := os.Chown(name, uid, gid); err != nil {
+			return fmt.Errorf("change owner of %q to uid %d gid %d error: %w", name, option.Uid, option.Gid, err)
+		}
+	}
+
+	if option.Mode != 0 {
+		if err := os.Chmod(name, option.Mode); err != nil {
+			return fmt.Errorf("change mode of %q to %s error: %w", name, option.Mode, err)
+		}
+	}
+
+	return nil
+}
+
+// syncPath commits the content of file or the entries of directory to disk.
+func syncPath(name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Sync()
+}
+
+// copyFile copies length bytes from offset of src to a new file dst, length less than 0 means until the end of src.
+func copyFile(src string, offset, length int64, dst string) (int64, error) {
+	file, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, defaultFileMode)
+	if err != nil {
+		return 0, err
+	}
+	defer dstFile.Close()
+
+	// copy_file_range is valid in linux
+	// https://go-review.googlesource.com/c/go/+/229101/
+	if length < 0 {
+		return io.Copy(dstFile, file)
+	}
+	return io.Copy(dstFile, io.LimitReader(file, length))
+}
+
+// writeInPlace writes length bytes from offset of src into the same offset of dst without truncating dst,
+// so the ranges of one file can be written into a pre-allocated dst by concurrent tasks.
+func writeInPlace(src string, offset, length int64, dst string, option OutputOption) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	dstFile, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY, defaultFileMode)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	if _, err = dstFile.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	n, err := io.Copy(dstFile, io.NewSectionReader(file, offset, length))
+	if err != nil {
+		return err
+	}
+
+	if n != length {
+		return fmt.Errorf("write %d bytes into %q in place, desired: %d: %w", n, dst, length, io.ErrShortWrite)
+	}
+
+	if option.Sync {
+		if err = dstFile.Sync(); err != nil {
+			return err
+		}
+	}
+
+	return ChangeOutputAttributes(dst, option)
+}
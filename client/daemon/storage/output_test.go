@@ -0,0 +1,157 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestStoreOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		option OutputOption
+		store  func(tmp string) error
+		expect func(t *testing.T, output string, err error)
+	}{
+		{
+			name: "store output",
+			store: func(tmp string) error {
+				return os.WriteFile(tmp, []byte("new"), defaultFileMode)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				data, err := os.ReadFile(output)
+				assert.Nil(err)
+				assert.Equal("new", string(data))
+			},
+		},
+		{
+			name:   "store output with sync",
+			option: OutputOption{Sync: true},
+			store: func(tmp string) error {
+				return os.WriteFile(tmp, []byte("new"), defaultFileMode)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				data, err := os.ReadFile(output)
+				assert.Nil(err)
+				assert.Equal("new", string(data))
+			},
+		},
+		{
+			name:   "store output with owner and mode",
+			option: OutputOption{Uid: int64(os.Getuid()), Gid: int64(os.Getgid()), Mode: 0600},
+			store: func(tmp string) error {
+				return os.WriteFile(tmp, []byte("new"), defaultFileMode)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				info, err := os.Stat(output)
+				assert.Nil(err)
+				assert.Equal(os.FileMode(0600), info.Mode().Perm())
+			},
+		},
+		{
+			name: "output is already a hardlink of stored file",
+			store: func(tmp string) error {
+				return os.Link(path.Join(path.Dir(tmp), "output"), tmp)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				data, err := os.ReadFile(output)
+				assert.Nil(err)
+				assert.Equal("old", string(data))
+			},
+		},
+		{
+			name: "partially written output is not observed",
+			store: func(tmp string) error {
+				if err := os.WriteFile(tmp, []byte("ne"), defaultFileMode); err != nil {
+					return err
+				}
+				return errors.New("foo")
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert := testifyassert.New(t)
+				assert.EqualError(err, "foo")
+				data, err := os.ReadFile(output)
+				assert.Nil(err)
+				assert.Equal("old", string(data))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			output := path.Join(dir, "output")
+			if err := os.WriteFile(output, []byte("old"), defaultFileMode); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, output, StoreOutput(output, tc.option, tc.store))
+
+			// temporary file is removed or renamed
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			testifyassert.Len(t, entries, 1)
+		})
+	}
+}
+
+func TestWriteInPlace(t *testing.T) {
+	assert := testifyassert.New(t)
+	dir := t.TempDir()
+
+	src := path.Join(dir, "src")
+	content := []byte("0123456789abcdefghij")
+	assert.Nil(os.WriteFile(src, content, defaultFileMode))
+
+	// pre-allocated output
+	dst := path.Join(dir, "dst")
+	assert.Nil(os.WriteFile(dst, make([]byte, len(content)), defaultFileMode))
+
+	var wg sync.WaitGroup
+	for _, rg := range [][2]int64{{0, 7}, {7, 6}, {13, 7}} {
+		wg.Add(1)
+		go func(offset, length int64) {
+			defer wg.Done()
+			assert.Nil(writeInPlace(src, offset, length, dst, OutputOption{Sync: true}))
+		}(rg[0], rg[1])
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(dst)
+	assert.Nil(err)
+	assert.Equal(content, data)
+
+	err = writeInPlace(src, 15, 10, dst, OutputOption{})
+	assert.True(errors.Is(err, io.ErrShortWrite))
+}
@@ -42,6 +42,7 @@ import (
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/gc"
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	"d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
@@ -97,14 +98,44 @@ type Manager interface {
 	UnregisterTask(ctx context.Context, req CommonTaskRequest) error
 	// FindCompletedTask try to find a completed task for fast path
 	FindCompletedTask(taskID string) *ReusePeerTask
+	// FindCompletedTaskByDigest try to find a completed task whose data is verified against the digest,
+	// the task may be downloaded from a different url
+	FindCompletedTaskByDigest(digest string) *ReusePeerTask
+	// CompletedTaskIDs returns the ids of all completed tasks
+	CompletedTaskIDs() []string
+	// CompletedTaskURLs returns the original urls of all completed tasks which recorded url
+	CompletedTaskURLs() []string
 	// FindCompletedSubTask try to find a completed subtask for fast path
 	FindCompletedSubTask(taskID string) *ReusePeerTask
 	// FindPartialCompletedTask try to find a partial completed task for fast path
 	FindPartialCompletedTask(taskID string, rg *util.Range) *ReusePeerTask
+	// FindResumableTask try to find an interrupted task which has downloaded some pieces
+	FindResumableTask(taskID string) *ReusePeerTask
+	// PinTask excludes all peer tasks of the task from gc until ttl is reached,
+	// ttl 0 or exceeding the max pin ttl of storage option is limited to the max pin ttl
+	PinTask(taskID string, ttl time.Duration) error
+	// UnpinTask makes all peer tasks of the task available for gc again
+	UnpinTask(taskID string) error
+	// MarkRevalidated records the revalidation result of the task with origin,
+	// all peer tasks of the task are invalid when it is modified in origin
+	MarkRevalidated(taskID string, modified bool) error
+	// Usage returns the usage of storage
+	Usage() *Usage
 	// CleanUp cleans all storage data
 	CleanUp()
 }
 
+// Usage is the usage of storage, reclaimed tasks are not counted.
+type Usage struct {
+	DataPath  string
+	TaskCount int
+	UsedBytes int64
+	// QuotaBytes is the gc threshold of used bytes, 0 means no quota
+	QuotaBytes int64
+	// DiskUsedPercent is the used percent of the disk which data path is in
+	DiskUsedPercent float64
+}
+
 var (
 	ErrTaskNotFound     = errors.New("task not found")
 	ErrPieceNotFound    = errors.New("piece not found")
@@ -114,6 +145,20 @@ var (
 	ErrBadRequest       = errors.New("bad request")
 )
 
+// CorruptedPiecesError is returned when digest does not match and the corrupted pieces are found,
+// the corrupted pieces are removed from storage and need to be downloaded again.
+type CorruptedPiecesError struct {
+	PieceNums []int32
+}
+
+func (e *CorruptedPiecesError) Error() string {
+	return fmt.Sprintf("%s, corrupted pieces: %v", ErrInvalidDigest, e.PieceNums)
+}
+
+func (e *CorruptedPiecesError) Unwrap() error {
+	return ErrInvalidDigest
+}
+
 const (
 	GCName = "StorageManager"
 )
@@ -140,6 +185,9 @@ type storageManager struct {
 
 	subIndexRWMutex       sync.RWMutex
 	subIndexTask2PeerTask map[string][]*localSubTaskStore // key: task id, value: slice of localSubTaskStore
+
+	// dedup shares on-disk storage of identical pieces across tasks, nil when disabled
+	dedup *dedupIndex
 }
 
 var _ gc.GC = (*storageManager)(nil)
@@ -184,6 +232,10 @@ func NewStorageManager(storeStrategy config.StoreStrategy, opt *config.StorageOp
 		subIndexTask2PeerTask: map[string][]*localSubTaskStore{},
 	}
 
+	if opt.Dedup {
+		s.dedup = newDedupIndex()
+	}
+
 	for _, o := range moreOpts {
 		if err := o(s); err != nil {
 			return nil, err
@@ -195,6 +247,9 @@ func NewStorageManager(storeStrategy config.StoreStrategy, opt *config.StorageOp
 	}
 
 	gc.Register(GCName, s)
+	if s.storeOption.MemoryTier.Enable {
+		gc.Register(MemoryTierGCName, newMemoryTier(s, &s.storeOption.MemoryTier))
+	}
 	return s, nil
 }
 
@@ -398,11 +453,15 @@ func (s *storageManager) CreateTask(req *RegisterTaskRequest) (TaskStorageDriver
 			PieceMd5Sign:  req.PieceMd5Sign,
 			PeerID:        req.PeerID,
 			Pieces:        map[int32]PieceMetadata{},
+			URL:           req.URL,
 		},
 		gcCallback:       s.gcCallback,
 		dataDir:          dataDir,
 		metadataFilePath: path.Join(dataDir, taskMetadata),
 		expireTime:       s.storeOption.TaskExpireTime.Duration,
+		mmapRead:         s.storeOption.MmapRead,
+		partialReclaim:   s.storeOption.PartialReclaim,
+		dedup:            s.dedup,
 		subtasks:         map[PeerTaskMetadata]*localSubTaskStore{},
 
 		SugaredLoggerOnWith: logger.With("task", req.TaskID, "peer", req.PeerID, "component", "localTaskStore"),
@@ -509,6 +568,70 @@ func (s *storageManager) FindCompletedTask(taskID string) *ReusePeerTask {
 				},
 				ContentLength: t.ContentLength,
 				TotalPieces:   t.TotalPieces,
+				PieceMd5Sign:  t.PieceMd5Sign,
+				Header:        t.Header,
+				ExpireInfo:    t.revalidateInfo(s.storeOption.RevalidateInterval.Duration),
+			}
+		}
+	}
+	return nil
+}
+
+func (s *storageManager) CompletedTaskIDs() []string {
+	s.indexRWMutex.RLock()
+	defer s.indexRWMutex.RUnlock()
+
+	var taskIDs []string
+	for taskID, ts := range s.indexTask2PeerTask {
+		for _, t := range ts {
+			if t.Done && !t.invalid.Load() && !t.reclaimMarked.Load() {
+				taskIDs = append(taskIDs, taskID)
+				break
+			}
+		}
+	}
+	return taskIDs
+}
+
+func (s *storageManager) CompletedTaskURLs() []string {
+	s.indexRWMutex.RLock()
+	defer s.indexRWMutex.RUnlock()
+
+	var urls []string
+	for _, ts := range s.indexTask2PeerTask {
+		for _, t := range ts {
+			if t.Done && t.URL != "" && !t.invalid.Load() && !t.reclaimMarked.Load() {
+				urls = append(urls, t.URL)
+				break
+			}
+		}
+	}
+	return urls
+}
+
+func (s *storageManager) FindCompletedTaskByDigest(digest string) *ReusePeerTask {
+	if digest == "" {
+		return nil
+	}
+
+	s.indexRWMutex.RLock()
+	defer s.indexRWMutex.RUnlock()
+	for taskID, ts := range s.indexTask2PeerTask {
+		for _, t := range ts {
+			if !t.Done || t.Digest != digest || t.invalid.Load() || t.reclaimMarked.Load() {
+				continue
+			}
+
+			t.touch()
+			return &ReusePeerTask{
+				Storage: t,
+				PeerTaskMetadata: PeerTaskMetadata{
+					PeerID: t.PeerID,
+					TaskID: taskID,
+				},
+				ContentLength: t.ContentLength,
+				TotalPieces:   t.TotalPieces,
+				PieceMd5Sign:  t.PieceMd5Sign,
 				Header:        t.Header,
 			}
 		}
@@ -544,12 +667,110 @@ func (s *storageManager) FindPartialCompletedTask(taskID string, rg *util.Range)
 				ContentLength: t.ContentLength,
 				TotalPieces:   t.TotalPieces,
 				Header:        t.Header,
+				ExpireInfo:    t.revalidateInfo(s.storeOption.RevalidateInterval.Duration),
 			}
 		}
 	}
 	return nil
 }
 
+func (s *storageManager) FindResumableTask(taskID string) *ReusePeerTask {
+	s.indexRWMutex.RLock()
+	defer s.indexRWMutex.RUnlock()
+	ts, ok := s.indexTask2PeerTask[taskID]
+	if !ok {
+		return nil
+	}
+	for _, t := range ts {
+		if t.invalid.Load() {
+			continue
+		}
+		// touch it before marking reclaim
+		t.touch()
+		// already marked, skip
+		if t.reclaimMarked.Load() {
+			continue
+		}
+
+		if t.resumable() {
+			return &ReusePeerTask{
+				Storage: t,
+				PeerTaskMetadata: PeerTaskMetadata{
+					PeerID: t.PeerID,
+					TaskID: taskID,
+				},
+				ContentLength: t.ContentLength,
+				TotalPieces:   t.TotalPieces,
+				PieceMd5Sign:  t.PieceMd5Sign,
+				Header:        t.Header,
+			}
+		}
+	}
+	return nil
+}
+
+func (s *storageManager) PinTask(taskID string, ttl time.Duration) error {
+	if ttl < 0 {
+		return fmt.Errorf("invalid pin ttl %s", ttl)
+	}
+
+	// Pinned tasks always expire, otherwise their disk can never be reclaimed.
+	maxTTL := s.storeOption.MaxPinTTL.Duration
+	if maxTTL <= 0 {
+		maxTTL = config.DefaultMaxPinTTL
+	}
+
+	if ttl == 0 || ttl > maxTTL {
+		ttl = maxTTL
+	}
+
+	return s.forEachPeerTask(taskID, func(t *localTaskStore) error {
+		if err := t.Pin(ttl); err != nil {
+			return err
+		}
+		t.Infof("task pinned, ttl: %s", ttl)
+		return nil
+	})
+}
+
+func (s *storageManager) UnpinTask(taskID string) error {
+	return s.forEachPeerTask(taskID, func(t *localTaskStore) error {
+		if err := t.Unpin(); err != nil {
+			return err
+		}
+		t.Infof("task unpinned")
+		return nil
+	})
+}
+
+func (s *storageManager) MarkRevalidated(taskID string, modified bool) error {
+	return s.forEachPeerTask(taskID, func(t *localTaskStore) error {
+		return t.revalidated(modified)
+	})
+}
+
+// forEachPeerTask calls fn with all valid peer tasks of the task which are not marked reclaimed.
+func (s *storageManager) forEachPeerTask(taskID string, fn func(t *localTaskStore) error) error {
+	s.indexRWMutex.RLock()
+	defer s.indexRWMutex.RUnlock()
+
+	var found bool
+	for _, t := range s.indexTask2PeerTask[taskID] {
+		if t.invalid.Load() || t.reclaimMarked.Load() {
+			continue
+		}
+		found = true
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
 func (s *storageManager) FindCompletedSubTask(taskID string) *ReusePeerTask {
 	s.subIndexRWMutex.RLock()
 	defer s.subIndexRWMutex.RUnlock()
@@ -684,12 +905,17 @@ func (s *storageManager) ReloadPersistentTask(gcCallback GCCallback) error {
 				dataDir:             dataDir,
 				metadataFilePath:    path.Join(dataDir, taskMetadata),
 				expireTime:          s.storeOption.TaskExpireTime.Duration,
+				mmapRead:            s.storeOption.MmapRead,
+				partialReclaim:      s.storeOption.PartialReclaim,
+				dedup:               s.dedup,
 				gcCallback:          gcCallback,
+				subtasks:            map[PeerTaskMetadata]*localSubTaskStore{},
 				SugaredLoggerOnWith: logger.With("task", taskID, "peer", peerID, "component", s.storeStrategy),
 			}
 			t.touch()
 
-			if t.metadataFile, err = os.Open(t.metadataFilePath); err != nil {
+			// open with write mode, the unfinished task will update metadata after resumed
+			if t.metadataFile, err = os.OpenFile(t.metadataFilePath, os.O_RDWR, defaultFileMode); err != nil {
 				loadErrs = append(loadErrs, err)
 				loadErrDirs = append(loadErrDirs, dataDir)
 				logger.With("action", "reload", "stage", "read metadata", "taskID", taskID, "peerID", peerID).
@@ -726,6 +952,13 @@ func (s *storageManager) ReloadPersistentTask(gcCallback GCCallback) error {
 			} else {
 				s.indexTask2PeerTask[taskID] = []*localTaskStore{t}
 			}
+
+			// pieces on disk are the sources of deduplication for new tasks
+			if s.dedup != nil && !t.Punched {
+				for _, piece := range t.Pieces {
+					s.dedup.add(PeerTaskMetadata{PeerID: peerID, TaskID: taskID}, t.DataFilePath, piece.Range.Start, piece.Range.Length, piece.Md5)
+				}
+			}
 		}
 	}
 	// remove load error peer tasks
@@ -777,12 +1010,19 @@ func (s *storageManager) TryGC() (bool, error) {
 	var markedTasks []PeerTaskMetadata
 	var totalNotMarkedSize int64
 	s.tasks.Range(func(key, task any) bool {
+		if lts, ok := task.(*localTaskStore); ok && lts.CanPartialReclaim() {
+			if s.partialReclaim(lts) {
+				return true
+			}
+		}
+
 		if task.(Reclaimer).CanReclaim() {
 			task.(Reclaimer).MarkReclaim()
 			markedTasks = append(markedTasks, key.(PeerTaskMetadata))
 		} else {
 			lts, ok := task.(*localTaskStore)
-			if ok {
+			// tasks marked in the previous gc will be reclaimed soon, do not count them into quota
+			if ok && !lts.reclaimMarked.Load() {
 				// just calculate not reclaimed task
 				totalNotMarkedSize += lts.ContentLength
 				logger.Debugf("task %s/%s not reach gc time",
@@ -792,6 +1032,16 @@ func (s *storageManager) TryGC() (bool, error) {
 		return true
 	})
 
+	// shared pieces occupy disk space only once
+	if s.dedup != nil {
+		totalNotMarkedSize -= s.dedup.SharedBytes()
+		if totalNotMarkedSize < 0 {
+			totalNotMarkedSize = 0
+		}
+	}
+
+	metrics.StorageUsedBytes.Set(float64(totalNotMarkedSize))
+
 	quotaBytesExceed := totalNotMarkedSize - int64(s.storeOption.DiskGCThreshold)
 	quotaExceed := s.storeOption.DiskGCThreshold > 0 && quotaBytesExceed > 0
 	usageExceed, usageBytesExceed := s.diskUsageExceed()
@@ -814,6 +1064,10 @@ func (s *storageManager) TryGC() (bool, error) {
 			if task.reclaimMarked.Load() {
 				return true
 			}
+			// pinned task is kept even if quota threshold reached
+			if task.pinned() {
+				return true
+			}
 			// task is not done, and is active in s.gcInterval
 			// next gc loop will check it again
 			if !task.Done && time.Since(time.Unix(0, task.lastAccess.Load())) < s.gcInterval {
@@ -828,11 +1082,17 @@ func (s *storageManager) TryGC() (bool, error) {
 		})
 		for _, task := range tasks {
 			task.MarkReclaim()
+			metrics.StorageQuotaEvictedTaskCount.Add(1)
 			markedTasks = append(markedTasks, PeerTaskMetadata{task.PeerID, task.TaskID})
 			logger.Infof("quota threshold reached, mark task %s/%s reclaimed, last access: %s, size: %s",
 				task.TaskID, task.PeerID, time.Unix(0, task.lastAccess.Load()).Format(time.RFC3339Nano),
 				units.BytesSize(float64(task.ContentLength)))
-			bytesExceed -= task.ContentLength
+			// pieces referenced by other tasks are not released
+			if s.dedup != nil {
+				bytesExceed -= s.dedup.exclusiveBytes(PeerTaskMetadata{PeerID: task.PeerID, TaskID: task.TaskID}, task.ContentLength)
+			} else {
+				bytesExceed -= task.ContentLength
+			}
 			if bytesExceed <= 0 {
 				break
 			}
@@ -869,6 +1129,11 @@ func (s *storageManager) TryGC() (bool, error) {
 			continue
 		}
 		logger.Infof("task %s/%s reclaimed", key.TaskID, key.PeerID)
+		metrics.StorageReclaimedTaskCount.Add(1)
+		// sub task shares the data file with its parent, only count the bytes of normal task
+		if lts, ok := t.(*localTaskStore); ok && lts.ContentLength > 0 {
+			metrics.StorageReclaimedBytesCount.Add(float64(lts.ContentLength))
+		}
 		// remove reclaimed task in markedTasks
 		for i, k := range markedTasks {
 			if k.TaskID == key.TaskID && k.PeerID == key.PeerID {
@@ -883,6 +1148,26 @@ func (s *storageManager) TryGC() (bool, error) {
 	return true, nil
 }
 
+// partialReclaim reclaims the downloaded pieces of expired unfinished task and keeps its metadata,
+// it returns false when the task should be reclaimed entirely.
+func (s *storageManager) partialReclaim(task *localTaskStore) bool {
+	reclaimed, err := task.PartialReclaim()
+	if reclaimed > 0 {
+		metrics.StoragePartialReclaimedBytesCount.Add(float64(reclaimed))
+	}
+
+	if err != nil {
+		if err != errPartialReclaimSkipped {
+			logger.Warnf("partial reclaim task %s/%s error: %s, reclaim it entirely", task.TaskID, task.PeerID, err)
+		}
+		return false
+	}
+
+	metrics.StoragePartialReclaimedTaskCount.Add(1)
+	logger.Infof("task %s/%s partial reclaimed, size: %s", task.TaskID, task.PeerID, units.BytesSize(float64(reclaimed)))
+	return true
+}
+
 func (s *storageManager) deleteTask(meta PeerTaskMetadata) error {
 	task, ok := s.LoadAndDeleteTask(meta)
 	if !ok {
@@ -923,6 +1208,38 @@ func (s *storageManager) forceGC() (bool, error) {
 	return true, nil
 }
 
+func (s *storageManager) Usage() *Usage {
+	usage := &Usage{
+		DataPath:   s.storeOption.DataPath,
+		QuotaBytes: int64(s.storeOption.DiskGCThreshold),
+	}
+
+	s.tasks.Range(func(_, val any) bool {
+		task, ok := val.(*localTaskStore)
+		if !ok || task.reclaimMarked.Load() {
+			return true
+		}
+
+		usage.TaskCount++
+		if task.ContentLength > 0 {
+			usage.UsedBytes += task.ContentLength
+		}
+		return true
+	})
+
+	if s.dedup != nil {
+		usage.UsedBytes -= s.dedup.SharedBytes()
+		if usage.UsedBytes < 0 {
+			usage.UsedBytes = 0
+		}
+	}
+
+	if disk, err := disk.Usage(s.storeOption.DataPath); err == nil {
+		usage.DiskUsedPercent = disk.UsedPercent
+	}
+	return usage
+}
+
 func (s *storageManager) diskUsageExceed() (exceed bool, bytes int64) {
 	if s.storeOption.DiskGCThresholdPercent <= 0 {
 		return false, 0
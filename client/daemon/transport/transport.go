@@ -251,14 +251,19 @@ func (rt *transport) download(ctx context.Context, req *http.Request) (*http.Res
 	filter := nethttp.PickHeader(req.Header, config.HeaderDragonflyFilter, rt.defaultFilter)
 	tag := nethttp.PickHeader(req.Header, config.HeaderDragonflyTag, rt.defaultTag)
 	application := nethttp.PickHeader(req.Header, config.HeaderDragonflyApplication, rt.defaultApplication)
+	digest := nethttp.PickHeader(req.Header, config.HeaderDragonflyDigest, "")
 
 	// Delete hop-by-hop headers
 	delHopHeaders(req.Header)
 
+	// Delete dragonfly control headers, they are only accepted from dfget
+	delDragonflyHeaders(req.Header)
+
 	meta.Header = nethttp.HeaderToMap(req.Header)
 	meta.Tag = tag
 	meta.Filter = filter
 	meta.Application = application
+	meta.Digest = digest
 
 	body, attr, err := rt.peerTaskManager.StartStreamTask(
 		ctx,
@@ -267,6 +272,8 @@ func (rt *transport) download(ctx context.Context, req *http.Request) (*http.Res
 			URLMeta: meta,
 			Range:   rg,
 			PeerID:  peerID,
+			// reclaim the resources immediately when the proxy client disconnects
+			CancelOnDisconnect: true,
 		},
 	)
 	if err != nil {
@@ -407,6 +414,16 @@ func delHopHeaders(header http.Header) {
 	}
 }
 
+// delDragonflyHeaders deletes the remaining dragonfly headers of proxy client,
+// like X-Dragonfly-Mirrors, which control the daemon and must not be set by proxy clients.
+func delDragonflyHeaders(header http.Header) {
+	for k := range header {
+		if strings.HasPrefix(k, config.HeaderDragonflyPrefix) {
+			header.Del(k)
+		}
+	}
+}
+
 func compositeErrorHTTPResponse(req *http.Request, status int, body string) (*http.Response, error) {
 	resp := &http.Response{
 		StatusCode:    status,
@@ -27,6 +27,7 @@ import (
 	"github.com/golang/mock/gomock"
 	testifyassert "github.com/stretchr/testify/assert"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/peer"
 	"d7y.io/dragonfly/v2/client/daemon/test"
 )
@@ -46,6 +47,8 @@ func TestTransport_RoundTrip(t *testing.T) {
 	peerTaskManager.EXPECT().StartStreamTask(gomock.Any(), gomock.Any()).DoAndReturn(
 		func(ctx context.Context, req *peer.StreamTaskRequest) (io.ReadCloser, map[string]string, error) {
 			assert.Equal(req.URL, url)
+			assert.True(req.CancelOnDisconnect)
+			assert.Equal(map[string]string{"Accept": "*"}, req.URLMeta.Header)
 			return io.NopCloser(bytes.NewBuffer(testData)), nil, nil
 		},
 	)
@@ -57,6 +60,9 @@ func TestTransport_RoundTrip(t *testing.T) {
 		}))
 	assert.NotNil(rt)
 	req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	req.Header.Set("Accept", "*")
+	req.Header.Set(config.HeaderDragonflyMirrors, "http://z/y")
+	req.Header.Set(config.HeaderDragonflyResume, "true")
 	resp, err := rt.RoundTrip(req)
 	assert.Nil(err)
 	if err != nil {
@@ -0,0 +1,122 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
+)
+
+// ErrTooManyRequests is returned when the queue of remote peer is full or waiting timeout.
+var ErrTooManyRequests = errors.New("too many requests of remote peer")
+
+// peerLimiter limits the concurrent uploads of each remote peer, so one aggressive
+// downloader can not occupy all the upload rate limit. The requests exceeding the
+// concurrency wait in the queue of the remote peer, they are rejected when the queue
+// is full or they wait longer than queueTimeout.
+type peerLimiter struct {
+	mu           sync.Mutex
+	concurrency  int
+	queueSize    int
+	queueTimeout time.Duration
+	peers        map[string]*remotePeer
+}
+
+// remotePeer is the upload slots and waiting requests of one remote peer.
+type remotePeer struct {
+	slots   chan struct{}
+	waiting int
+}
+
+func newPeerLimiter(concurrency, queueSize int, queueTimeout time.Duration) *peerLimiter {
+	return &peerLimiter{
+		concurrency:  concurrency,
+		queueSize:    queueSize,
+		queueTimeout: queueTimeout,
+		peers:        map[string]*remotePeer{},
+	}
+}
+
+// acquire takes an upload slot of the remote peer, the returned release must be called
+// after uploading.
+func (l *peerLimiter) acquire(ctx context.Context, key string) (func(), error) {
+	l.mu.Lock()
+	peer, ok := l.peers[key]
+	if !ok {
+		peer = &remotePeer{slots: make(chan struct{}, l.concurrency)}
+		l.peers[key] = peer
+	}
+
+	select {
+	case peer.slots <- struct{}{}:
+		l.mu.Unlock()
+		return func() { l.release(key, peer) }, nil
+	default:
+	}
+
+	if peer.waiting >= l.queueSize {
+		l.mu.Unlock()
+		metrics.UploadRejectedCount.Add(1)
+		return nil, ErrTooManyRequests
+	}
+	peer.waiting++
+	l.mu.Unlock()
+	metrics.UploadQueueWaitingCount.Inc()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case peer.slots <- struct{}{}:
+	case <-timer.C:
+		metrics.UploadRejectedCount.Add(1)
+		err = ErrTooManyRequests
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	metrics.UploadQueueWaitingCount.Dec()
+
+	l.mu.Lock()
+	peer.waiting--
+	if err != nil {
+		l.cleanLocked(key, peer)
+		l.mu.Unlock()
+		return nil, err
+	}
+	l.mu.Unlock()
+
+	return func() { l.release(key, peer) }, nil
+}
+
+func (l *peerLimiter) release(key string, peer *remotePeer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	<-peer.slots
+	l.cleanLocked(key, peer)
+}
+
+// cleanLocked deletes the idle remote peer.
+func (l *peerLimiter) cleanLocked(key string, peer *remotePeer) {
+	if len(peer.slots) == 0 && peer.waiting == 0 {
+		delete(l.peers, key)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package upload
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+func TestPeerLimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		run    func(t *testing.T, l *peerLimiter)
+		expect func(t *testing.T, l *peerLimiter)
+	}{
+		{
+			name: "remote peers are isolated",
+			run: func(t *testing.T, l *peerLimiter) {
+				assert := testifyassert.New(t)
+				release, err := l.acquire(context.Background(), "foo")
+				assert.Nil(err)
+				defer release()
+
+				// the queue of foo is empty, waiting timeout
+				_, err = l.acquire(context.Background(), "foo")
+				assert.ErrorIs(err, ErrTooManyRequests)
+
+				release, err = l.acquire(context.Background(), "bar")
+				assert.Nil(err)
+				release()
+			},
+			expect: func(t *testing.T, l *peerLimiter) {
+				assert := testifyassert.New(t)
+				assert.Len(l.peers, 0)
+			},
+		},
+		{
+			name: "waiting request acquires released slot",
+			run: func(t *testing.T, l *peerLimiter) {
+				assert := testifyassert.New(t)
+				release, err := l.acquire(context.Background(), "foo")
+				assert.Nil(err)
+				go func() {
+					time.Sleep(10 * time.Millisecond)
+					release()
+				}()
+
+				release, err = l.acquire(context.Background(), "foo")
+				assert.Nil(err)
+				release()
+			},
+			expect: func(t *testing.T, l *peerLimiter) {
+				assert := testifyassert.New(t)
+				assert.Len(l.peers, 0)
+			},
+		},
+		{
+			name: "reject request when queue is full",
+			run: func(t *testing.T, l *peerLimiter) {
+				assert := testifyassert.New(t)
+				release, err := l.acquire(context.Background(), "foo")
+				assert.Nil(err)
+				defer release()
+
+				ctx, cancel := context.WithCancel(context.Background())
+				waited := make(chan error)
+				go func() {
+					_, err := l.acquire(ctx, "foo")
+					waited <- err
+				}()
+
+				assert.Eventually(func() bool {
+					l.mu.Lock()
+					defer l.mu.Unlock()
+					return l.peers["foo"].waiting == 1
+				}, time.Second, time.Millisecond)
+
+				_, err = l.acquire(context.Background(), "foo")
+				assert.ErrorIs(err, ErrTooManyRequests)
+
+				cancel()
+				assert.ErrorIs(<-waited, context.Canceled)
+			},
+			expect: func(t *testing.T, l *peerLimiter) {
+				assert := testifyassert.New(t)
+				assert.Len(l.peers, 0)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newPeerLimiter(1, 1, 100*time.Millisecond)
+			tc.run(t, l)
+			tc.expect(t, l)
+		})
+	}
+}
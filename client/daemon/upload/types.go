@@ -24,3 +24,11 @@ type DownloadParams struct {
 type DownalodQuery struct {
 	PeerID string `form:"peerId" binding:"required"`
 }
+
+type TaskParams struct {
+	TaskID string `uri:"task_id" binding:"required"`
+}
+
+type PinTaskQuery struct {
+	TTL string `form:"ttl" binding:"required"`
+}
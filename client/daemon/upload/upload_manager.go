@@ -20,6 +20,7 @@ package upload
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -28,17 +29,21 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-http-utils/headers"
 	ginprometheus "github.com/mcuadros/go-gin-prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/time/rate"
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	"d7y.io/dragonfly/v2/client/util"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/internal/uploadauth"
 )
 
 const (
@@ -48,6 +53,7 @@ const (
 
 const (
 	RouterGroupDownload = "/download"
+	RouterGroupTasks    = "/tasks"
 )
 
 var GinLogFileName = "gin-upload.log"
@@ -66,6 +72,10 @@ type uploadManager struct {
 	*http.Server
 	*rate.Limiter
 	storageManager storage.Manager
+	// authSecret verifies tokens of piece downloading, tokens are not required when it is empty
+	authSecret string
+	// peerLimiter limits the concurrent uploads of each remote peer, it is nil when not limited
+	peerLimiter *peerLimiter
 }
 
 // Option is a functional option for configuring the upload manager.
@@ -84,9 +94,23 @@ func NewUploadManager(cfg *config.DaemonOption, storageManager storage.Manager,
 		storageManager: storageManager,
 	}
 
+	if cfg.Upload.Auth.Enable {
+		um.authSecret = cfg.Upload.Auth.Secret
+	}
+
+	if perPeer := cfg.Upload.PerPeer; perPeer.Concurrency > 0 {
+		um.peerLimiter = newPeerLimiter(perPeer.Concurrency, perPeer.QueueSize, perPeer.QueueTimeout)
+	}
+
 	router := um.initRouter(cfg, logDir)
 	um.Server = &http.Server{
-		Handler: router,
+		Handler: withResponseWriter(router),
+	}
+
+	if cfg.Upload.HTTP2.Enable {
+		if err := configureHTTP2(um.Server, cfg.Upload.HTTP2.MaxConcurrentStreams); err != nil {
+			return nil, err
+		}
 	}
 
 	for _, opt := range opts {
@@ -136,6 +160,10 @@ func (um *uploadManager) initRouter(cfg *config.DaemonOption, logDir string) *gi
 			return RouterGroupDownload
 		}
 
+		if strings.HasPrefix(c.Request.URL.Path, RouterGroupTasks) {
+			return RouterGroupTasks
+		}
+
 		return c.Request.URL.Path
 	}
 	p.Use(r)
@@ -150,8 +178,19 @@ func (um *uploadManager) initRouter(cfg *config.DaemonOption, logDir string) *gi
 
 	// Peer download task.
 	d := r.Group(RouterGroupDownload)
+	if um.authSecret != "" {
+		d.Use(um.authorize(uploadauth.VerifyToken))
+	}
 	d.GET(":task_prefix/:task_id", um.getDownload)
 
+	// Pin task in storage. Pinned tasks are not reclaimed by gc,
+	// so pinning is only served when the upload auth is enabled.
+	if um.authSecret != "" {
+		ts := r.Group(RouterGroupTasks, um.authorize(uploadauth.VerifyPinToken))
+		ts.PUT(":task_id/pin", um.pinTask)
+		ts.DELETE(":task_id/pin", um.unpinTask)
+	}
+
 	return r
 }
 
@@ -160,6 +199,26 @@ func (um *uploadManager) getHealth(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, http.StatusText(http.StatusOK))
 }
 
+// authorize verifies the token in authorization header with verify,
+// tokens of piece downloading and task pinning are verified by different audiences.
+func (um *uploadManager) authorize(verify func(secret, token, taskID string) error) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		token, ok := uploadauth.ParseBearerToken(ctx.GetHeader(headers.Authorization))
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "token is required"})
+			return
+		}
+
+		if err := verify(um.authSecret, token, ctx.Param("task_id")); err != nil {
+			logger.Warnf("verify token from %s failed: %s", ctx.Request.RemoteAddr, err)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": err.Error()})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
 // getDownload uses to upload a task file when other peers download from it.
 func (um *uploadManager) getDownload(ctx *gin.Context) {
 	var params DownloadParams
@@ -178,7 +237,7 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 	peerID := query.PeerID
 
 	log := logger.WithTaskAndPeerID(taskID, peerID).With("component", "uploadManager")
-	log.Debugf("upload piece for task %s/%s to %s, request header: %#v", taskID, peerID, ctx.Request.RemoteAddr, ctx.Request.Header)
+	log.Debugf("upload piece for task %s/%s to %s, request header: %#v", taskID, peerID, ctx.Request.RemoteAddr, redactHeader(ctx.Request.Header))
 	rg, err := util.ParseRange(ctx.GetHeader(headers.Range), math.MaxInt64)
 	if err != nil {
 		log.Errorf("parse range with error: %s", err)
@@ -192,6 +251,17 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 		return
 	}
 
+	// requests of the remote peer wait in its queue when the concurrency is reached
+	if um.peerLimiter != nil {
+		release, err := um.peerLimiter.acquire(ctx.Request.Context(), remoteHost(ctx.Request.RemoteAddr))
+		if err != nil {
+			log.Warnf("upload piece to %s rejected: %s", ctx.Request.RemoteAddr, err)
+			ctx.JSON(http.StatusTooManyRequests, gin.H{"errors": err.Error()})
+			return
+		}
+		defer release()
+	}
+
 	reader, closer, err := um.storageManager.ReadPiece(ctx,
 		&storage.ReadPieceRequest{
 			PeerTaskMetadata: storage.PeerTaskMetadata{
@@ -227,7 +297,7 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 
 	// If w is a socket, golang will use sendfile or splice syscall for zero copy feature
 	// when start to transfer data, we could not call http.Error with header.
-	if n, err := io.Copy(ctx.Writer, reader); err != nil {
+	if n, err := io.Copy(pieceWriter(ctx), reader); err != nil {
 		log.Errorf("transfer data failed: %s", err)
 		return
 	} else if n != rg[0].Length {
@@ -236,3 +306,164 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 		return
 	}
 }
+
+// configureHTTP2 serves http2 for the tls connections negotiated h2 by alpn,
+// and h2c for the plaintext connections, http/1.1 requests are still served.
+func configureHTTP2(server *http.Server, maxConcurrentStreams uint32) error {
+	h2s := &http2.Server{
+		MaxConcurrentStreams: maxConcurrentStreams,
+	}
+
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		return err
+	}
+
+	server.Handler = h2c.NewHandler(server.Handler, h2s)
+	return nil
+}
+
+// remoteHost returns the host of remote address, the remote peer is identified by host.
+func remoteHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// pinTask excludes the task from storage gc until it is unpinned or ttl is reached.
+func (um *uploadManager) pinTask(ctx *gin.Context) {
+	var params TaskParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query PinTaskQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	ttl, err := time.ParseDuration(query.TTL)
+	if err != nil || ttl <= 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": fmt.Sprintf("invalid ttl %q", query.TTL)})
+		return
+	}
+
+	if err := um.storageManager.PinTask(params.TaskID, ttl); err != nil {
+		um.handleTaskError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// unpinTask makes the task available for storage gc again.
+func (um *uploadManager) unpinTask(ctx *gin.Context) {
+	var params TaskParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := um.storageManager.UnpinTask(params.TaskID); err != nil {
+		um.handleTaskError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// handleTaskError writes the error of task operation to response.
+func (um *uploadManager) handleTaskError(ctx *gin.Context, err error) {
+	if errors.Is(err, storage.ErrTaskNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"errors": err.Error()})
+		return
+	}
+
+	logger.Errorf("operate task %s failed: %s", ctx.Param("task_id"), err)
+	ctx.JSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+}
+
+// responseWriterKey is the context key of the original http.ResponseWriter.
+type responseWriterKey struct{}
+
+// withResponseWriter stores the original http.ResponseWriter in the request context,
+// because gin wraps it and hides io.ReaderFrom which is required by sendfile and splice.
+func withResponseWriter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), responseWriterKey{}, w)))
+	})
+}
+
+// pieceWriter returns the writer of piece body. When TLS is disabled, it returns the original
+// http.ResponseWriter whose io.ReaderFrom copies file data to the socket with sendfile or splice
+// syscall, bypassing user-space copies. Otherwise it falls back to the writer of gin.
+// The original http.ResponseWriter is wrapped as the writer of gin, so the bytes copied
+// by it are still counted in the response size of metrics and access logs.
+func pieceWriter(ctx *gin.Context) io.Writer {
+	if ctx.Request.TLS != nil {
+		return ctx.Writer
+	}
+
+	w, ok := ctx.Request.Context().Value(responseWriterKey{}).(http.ResponseWriter)
+	if !ok {
+		return ctx.Writer
+	}
+
+	rf, ok := w.(io.ReaderFrom)
+	if !ok {
+		return ctx.Writer
+	}
+
+	pw := &pieceResponseWriter{ResponseWriter: ctx.Writer, readerFrom: rf}
+	ctx.Writer = pw
+	return pw
+}
+
+// pieceResponseWriter is the writer of gin which copies piece body with the io.ReaderFrom
+// of the original http.ResponseWriter, and counts the copied bytes in the response size.
+type pieceResponseWriter struct {
+	gin.ResponseWriter
+	readerFrom io.ReaderFrom
+	size       int
+}
+
+// ReadFrom copies data from r with the io.ReaderFrom of the original http.ResponseWriter.
+func (w *pieceResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	w.WriteHeaderNow()
+	n, err := w.readerFrom.ReadFrom(r)
+	w.size += int(n)
+	return n, err
+}
+
+// Size returns the bytes written by the writer of gin and copied by ReadFrom.
+func (w *pieceResponseWriter) Size() int {
+	size := w.ResponseWriter.Size()
+	if w.size == 0 {
+		return size
+	}
+
+	if size < 0 {
+		size = 0
+	}
+	return size + w.size
+}
+
+// redactedValue replaces the values of sensitive headers in logs.
+const redactedValue = "[REDACTED]"
+
+// redactHeader returns a copy of header whose credentials are redacted,
+// including authorization, cookie and the headers named with token.
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for key := range redacted {
+		switch k := strings.ToLower(key); {
+		case k == "authorization", k == "proxy-authorization", k == "cookie", strings.Contains(k, "token"):
+			redacted[key] = []string{redactedValue}
+		}
+	}
+
+	return redacted
+}
@@ -19,21 +19,29 @@ package upload
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
 	testifyassert "github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
 	"golang.org/x/time/rate"
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	"d7y.io/dragonfly/v2/client/daemon/storage/mocks"
 	"d7y.io/dragonfly/v2/client/daemon/test"
+	"d7y.io/dragonfly/v2/internal/uploadauth"
 	_ "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 )
 
@@ -108,3 +116,492 @@ func TestUploadManager_Serve(t *testing.T) {
 		assert.Equal(tt.targetPieceData, data)
 	}
 }
+
+func TestUploadManager_ServeFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert := testifyassert.New(t)
+	testData, err := os.ReadFile(test.File)
+	assert.Nil(err, "load test file")
+
+	mockStorageManager := mocks.NewMockManager(ctrl)
+	mockStorageManager.EXPECT().ReadPiece(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+			f, err := os.Open(test.File)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if _, err := f.Seek(req.Range.Start, io.SeekStart); err != nil {
+				f.Close()
+				return nil, nil, err
+			}
+
+			return io.LimitReader(f, req.Range.Length), f, nil
+		})
+
+	um, err := NewUploadManager(config.NewDaemonConfig(), mockStorageManager, os.TempDir())
+	assert.Nil(err, "NewUploadManager")
+
+	listen, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(err, "Listen")
+	addr := listen.Addr().String()
+
+	go func() {
+		if err := um.Serve(listen); err != nil && err != http.ErrServerClosed {
+			t.Error(err)
+		}
+	}()
+	defer um.Stop()
+
+	req, _ := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("http://%s/%s/%s/%s?peerId=%s", addr, "download", "666", "task-0", "peer-0"), nil)
+	req.Header.Add("Range", "bytes=1024-4095")
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.Nil(err, "get piece data")
+
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(testData[1024:4096], data)
+}
+
+func TestUploadManager_ServeHTTP2(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert := testifyassert.New(t)
+	testData, err := os.ReadFile(test.File)
+	assert.Nil(err, "load test file")
+
+	mockStorageManager := mocks.NewMockManager(ctrl)
+	mockStorageManager.EXPECT().ReadPiece(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+			return bytes.NewBuffer(testData[req.Range.Start : req.Range.Start+req.Range.Length]),
+				io.NopCloser(nil), nil
+		})
+
+	cfg := config.NewDaemonConfig()
+	cfg.Upload.HTTP2.Enable = true
+	um, err := NewUploadManager(cfg, mockStorageManager, os.TempDir())
+	assert.Nil(err, "NewUploadManager")
+
+	listen, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(err, "Listen")
+	addr := listen.Addr().String()
+
+	go func() {
+		if err := um.Serve(listen); err != nil && err != http.ErrServerClosed {
+			t.Error(err)
+		}
+	}()
+	defer um.Stop()
+
+	h2cClient := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		client     *http.Client
+		protoMajor int
+	}{
+		{
+			name:       "serve piece with h2c",
+			client:     h2cClient,
+			protoMajor: 2,
+		},
+		{
+			name:       "serve piece with http/1.1",
+			client:     http.DefaultClient,
+			protoMajor: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			req, _ := http.NewRequest(http.MethodGet,
+				fmt.Sprintf("http://%s/%s/%s/%s?peerId=%s", addr, "download", "666", "task-0", "peer-0"), nil)
+			req.Header.Add("Range", "bytes=0-1023")
+
+			resp, err := tc.client.Do(req)
+			assert.Nil(err, "get piece data")
+			defer resp.Body.Close()
+
+			data, _ := io.ReadAll(resp.Body)
+			assert.Equal(tc.protoMajor, resp.ProtoMajor)
+			assert.Equal(testData[:1024], data)
+		})
+	}
+}
+
+func TestUploadManager_pieceWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(req *http.Request, w http.ResponseWriter) *http.Request
+		expect func(t *testing.T, ctx *gin.Context, w http.ResponseWriter)
+	}{
+		{
+			name: "tls is disabled",
+			mock: func(req *http.Request, w http.ResponseWriter) *http.Request {
+				return req.WithContext(context.WithValue(req.Context(), responseWriterKey{}, w))
+			},
+			expect: func(t *testing.T, ctx *gin.Context, w http.ResponseWriter) {
+				assert := testifyassert.New(t)
+				pw := pieceWriter(ctx)
+				assert.Equal(ctx.Writer, pw)
+				assert.Implements((*io.ReaderFrom)(nil), pw)
+
+				n, err := io.Copy(pw, strings.NewReader("foo"))
+				assert.NoError(err)
+				assert.Equal(int64(3), n)
+				assert.Equal(3, ctx.Writer.Size())
+				assert.Equal("foo", w.(*readerFromRecorder).Body.String())
+			},
+		},
+		{
+			name: "tls is enabled",
+			mock: func(req *http.Request, w http.ResponseWriter) *http.Request {
+				req.TLS = &tls.ConnectionState{}
+				return req.WithContext(context.WithValue(req.Context(), responseWriterKey{}, w))
+			},
+			expect: func(t *testing.T, ctx *gin.Context, w http.ResponseWriter) {
+				assert := testifyassert.New(t)
+				assert.Equal(ctx.Writer, pieceWriter(ctx))
+			},
+		},
+		{
+			name: "original response writer not found",
+			mock: func(req *http.Request, w http.ResponseWriter) *http.Request {
+				return req
+			},
+			expect: func(t *testing.T, ctx *gin.Context, w http.ResponseWriter) {
+				assert := testifyassert.New(t)
+				assert.Equal(ctx.Writer, pieceWriter(ctx))
+			},
+		},
+		{
+			name: "original response writer does not implement io.ReaderFrom",
+			mock: func(req *http.Request, w http.ResponseWriter) *http.Request {
+				return req.WithContext(context.WithValue(req.Context(), responseWriterKey{}, httptest.NewRecorder()))
+			},
+			expect: func(t *testing.T, ctx *gin.Context, w http.ResponseWriter) {
+				assert := testifyassert.New(t)
+				assert.Equal(ctx.Writer, pieceWriter(ctx))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &readerFromRecorder{httptest.NewRecorder()}
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = tc.mock(httptest.NewRequest(http.MethodGet, "/download/tas/task-0?peerId=peer-0", nil), w)
+			tc.expect(t, ctx, w)
+		})
+	}
+}
+
+// readerFromRecorder is a response recorder implementing io.ReaderFrom like the response writer of net/http.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+}
+
+func (r *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	return io.Copy(r.ResponseRecorder, src)
+}
+
+func TestUploadManager_Authorize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorageManager := mocks.NewMockManager(ctrl)
+	mockStorageManager.EXPECT().ReadPiece(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+			return bytes.NewBufferString("foo"), io.NopCloser(nil), nil
+		})
+
+	cfg := config.NewDaemonConfig()
+	cfg.Upload.Auth = config.UploadAuthOption{
+		Enable:   true,
+		Secret:   "secret",
+		TokenTTL: time.Minute,
+	}
+	um, err := NewUploadManager(cfg, mockStorageManager, os.TempDir(), WithLimiter(rate.NewLimiter(16*1024, 16*1024)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  func() string
+		expect func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name: "authorize with valid token",
+			token: func() string {
+				token, _ := uploadauth.GenerateToken("secret", "task-0", time.Minute)
+				return uploadauth.BearerToken(token)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name: "token of pinning task",
+			token: func() string {
+				token, _ := uploadauth.GeneratePinToken("secret", "task-0", time.Minute)
+				return uploadauth.BearerToken(token)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusForbidden, resp.StatusCode)
+			},
+		},
+		{
+			name: "token not found",
+			token: func() string {
+				return ""
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			name: "token of another task",
+			token: func() string {
+				token, _ := uploadauth.GenerateToken("secret", "task-1", time.Minute)
+				return uploadauth.BearerToken(token)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusForbidden, resp.StatusCode)
+			},
+		},
+		{
+			name: "token signed by another secret",
+			token: func() string {
+				token, _ := uploadauth.GenerateToken("foo", "task-0", time.Minute)
+				return uploadauth.BearerToken(token)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusForbidden, resp.StatusCode)
+			},
+		},
+		{
+			name: "token is expired",
+			token: func() string {
+				token, _ := uploadauth.GenerateToken("secret", "task-0", -time.Minute)
+				return uploadauth.BearerToken(token)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusForbidden, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/download/tas/task-0?peerId=peer-0", nil)
+			req.Header.Set("Range", "bytes=0-2")
+			req.Header.Set("Authorization", tc.token())
+
+			w := httptest.NewRecorder()
+			um.(*uploadManager).Server.Handler.ServeHTTP(w, req)
+			tc.expect(t, w.Result())
+		})
+	}
+}
+
+func TestUploadManager_PinTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		url    string
+		// disableAuth disables the upload auth, withoutToken sends requests without token,
+		// and downloadToken sends requests with the token of piece downloading
+		disableAuth   bool
+		withoutToken  bool
+		downloadToken bool
+		mock          func(m *mocks.MockManagerMockRecorder)
+		expect        func(t *testing.T, resp *http.Response)
+	}{
+		{
+			name:   "pin task with ttl",
+			method: http.MethodPut,
+			url:    "/tasks/task-0/pin?ttl=1h",
+			mock: func(m *mocks.MockManagerMockRecorder) {
+				m.PinTask(gomock.Eq("task-0"), gomock.Eq(time.Hour)).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name:   "pin task without ttl",
+			method: http.MethodPut,
+			url:    "/tasks/task-0/pin",
+			mock:   func(m *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+			},
+		},
+		{
+			name:   "pin task with zero ttl",
+			method: http.MethodPut,
+			url:    "/tasks/task-0/pin?ttl=0s",
+			mock:   func(m *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+			},
+		},
+		{
+			name:         "pin task without token",
+			method:       http.MethodPut,
+			url:          "/tasks/task-0/pin?ttl=1h",
+			withoutToken: true,
+			mock:         func(m *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusUnauthorized, resp.StatusCode)
+			},
+		},
+		{
+			name:          "pin task with download token",
+			method:        http.MethodPut,
+			url:           "/tasks/task-0/pin?ttl=1h",
+			downloadToken: true,
+			mock:          func(m *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusForbidden, resp.StatusCode)
+			},
+		},
+		{
+			name:        "pin task without upload auth",
+			method:      http.MethodPut,
+			url:         "/tasks/task-0/pin?ttl=1h",
+			disableAuth: true,
+			mock:        func(m *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name:   "pin task with invalid ttl",
+			method: http.MethodPut,
+			url:    "/tasks/task-0/pin?ttl=-1h",
+			mock:   func(m *mocks.MockManagerMockRecorder) {},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+			},
+		},
+		{
+			name:   "pin task not found",
+			method: http.MethodPut,
+			url:    "/tasks/task-0/pin?ttl=1h",
+			mock: func(m *mocks.MockManagerMockRecorder) {
+				m.PinTask(gomock.Eq("task-0"), gomock.Any()).Return(storage.ErrTaskNotFound).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusNotFound, resp.StatusCode)
+			},
+		},
+		{
+			name:   "unpin task",
+			method: http.MethodDelete,
+			url:    "/tasks/task-0/pin",
+			mock: func(m *mocks.MockManagerMockRecorder) {
+				m.UnpinTask(gomock.Eq("task-0")).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusOK, resp.StatusCode)
+			},
+		},
+		{
+			name:   "unpin task failed",
+			method: http.MethodDelete,
+			url:    "/tasks/task-0/pin",
+			mock: func(m *mocks.MockManagerMockRecorder) {
+				m.UnpinTask(gomock.Eq("task-0")).Return(errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, resp *http.Response) {
+				assert := testifyassert.New(t)
+				assert.Equal(http.StatusInternalServerError, resp.StatusCode)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockStorageManager := mocks.NewMockManager(ctrl)
+			tc.mock(mockStorageManager.EXPECT())
+			cfg := config.NewDaemonConfig()
+			if !tc.disableAuth {
+				cfg.Upload.Auth.Enable = true
+				cfg.Upload.Auth.Secret = "secret"
+			}
+			um, err := NewUploadManager(cfg, mockStorageManager, os.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(tc.method, tc.url, nil)
+			if !tc.withoutToken {
+				generateToken := uploadauth.GeneratePinToken
+				if tc.downloadToken {
+					generateToken = uploadauth.GenerateToken
+				}
+
+				token, err := generateToken("secret", "task-0", time.Hour)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+
+			w := httptest.NewRecorder()
+			um.(*uploadManager).Server.Handler.ServeHTTP(w, req)
+			tc.expect(t, w.Result())
+		})
+	}
+}
+
+func TestUploadManager_redactHeader(t *testing.T) {
+	assert := testifyassert.New(t)
+	header := http.Header{}
+	header.Set("Authorization", "Bearer foo")
+	header.Set("Proxy-Authorization", "Basic foo")
+	header.Set("Cookie", "session=foo")
+	header.Set("X-Auth-Token", "foo")
+	header.Set("Range", "bytes=0-1")
+
+	redacted := redactHeader(header)
+	assert.Equal("[REDACTED]", redacted.Get("Authorization"))
+	assert.Equal("[REDACTED]", redacted.Get("Proxy-Authorization"))
+	assert.Equal("[REDACTED]", redacted.Get("Cookie"))
+	assert.Equal("[REDACTED]", redacted.Get("X-Auth-Token"))
+	assert.Equal("bytes=0-1", redacted.Get("Range"))
+	assert.Equal("Bearer foo", header.Get("Authorization"))
+}
@@ -23,48 +23,71 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/gammazero/deque"
 	"github.com/go-http-utils/headers"
 	"github.com/schollz/progressbar/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
-	"d7y.io/dragonfly/v2/pkg/basic"
 	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/idgen"
 	daemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+	daemonserver "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 	"d7y.io/dragonfly/v2/pkg/source"
 	pkgstrings "d7y.io/dragonfly/v2/pkg/strings"
 )
 
+const (
+	// cancelTaskTimeout is the max time of canceling the interrupted task in daemon.
+	cancelTaskTimeout = time.Second
+
+	// cancelTaskInterval is the interval of retrying to cancel the interrupted task in daemon.
+	cancelTaskInterval = 100 * time.Millisecond
+)
+
+// stdout is where the content is streamed when output is stdout, it's captured
+// before the messages of dfget are redirected to stderr.
+var stdout io.Writer = os.Stdout
+
 func Download(cfg *config.DfgetConfig, client daemonclient.DaemonClient) error {
 	var (
 		ctx       = context.Background()
 		cancel    context.CancelFunc
 		wLog      = logger.With("url", cfg.URL)
 		downError error
+		done      = make(chan struct{})
 	)
 
 	wLog.Info("init success and start to download")
 	fmt.Println("init success and start to download")
 
+	// interrupting stops downloading, and the task in daemon is canceled
+	signalCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	if cfg.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		ctx, cancel = context.WithTimeout(signalCtx, cfg.Timeout)
 	} else {
-		ctx, cancel = context.WithCancel(ctx)
+		ctx, cancel = context.WithCancel(signalCtx)
 	}
 
 	go func() {
 		downError = download(ctx, client, cfg, wLog)
 		cancel()
+		close(done)
 	}()
 
 	<-ctx.Done()
@@ -72,9 +95,40 @@ func Download(cfg *config.DfgetConfig, client daemonclient.DaemonClient) error {
 	if ctx.Err() == context.DeadlineExceeded {
 		return fmt.Errorf("download timeout(%s)", cfg.Timeout)
 	}
+
+	if signalCtx.Err() != nil {
+		// wait for canceling the task in daemon
+		select {
+		case <-done:
+		case <-time.After(2 * cancelTaskTimeout):
+		}
+		return errors.New("download interrupted")
+	}
 	return downError
 }
 
+// CheckCache asks the daemon whether the url is fully cached locally and validates the digests,
+// the task is not downloaded.
+func CheckCache(cfg *config.DfgetConfig, client daemonclient.DaemonClient) (*daemonserver.CacheStatus, error) {
+	if client == nil {
+		return nil, errors.New("daemon is not available")
+	}
+
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	request := newDownRequest(cfg, parseHeader(cfg.Header))
+	return client.CheckCache(ctx, &dfdaemonv1.StatTaskRequest{
+		Url:       request.Url,
+		UrlMeta:   request.UrlMeta,
+		LocalOnly: true,
+	})
+}
+
 func download(ctx context.Context, client daemonclient.DaemonClient, cfg *config.DfgetConfig, wLog *logger.SugaredLoggerOnWith) error {
 	if cfg.Recursive {
 		return recursiveDownload(ctx, client, cfg)
@@ -85,6 +139,10 @@ func download(ctx context.Context, client daemonclient.DaemonClient, cfg *config
 func singleDownload(ctx context.Context, client daemonclient.DaemonClient, cfg *config.DfgetConfig, wLog *logger.SugaredLoggerOnWith) error {
 	hdr := parseHeader(cfg.Header)
 
+	if cfg.IsStdoutOutput() {
+		return streamDownload(ctx, client, cfg, hdr, stdout, wLog)
+	}
+
 	if client == nil {
 		return downloadFromSource(ctx, cfg, hdr)
 	}
@@ -95,6 +153,7 @@ func singleDownload(ctx context.Context, client daemonclient.DaemonClient, cfg *
 		result    *dfdaemonv1.DownResult
 		pb        *progressbar.ProgressBar
 		request   = newDownRequest(cfg, hdr)
+		taskID    = idgen.TaskID(request.Url, request.UrlMeta)
 		downError error
 	)
 
@@ -108,6 +167,11 @@ func singleDownload(ctx context.Context, client daemonclient.DaemonClient, cfg *
 				break
 			}
 
+			// task id generated by daemon may differ when daemon canonicalizes the url
+			if result.TaskId != "" {
+				taskID = result.TaskId
+			}
+
 			if result.CompletedLength > 0 && pb != nil {
 				_ = pb.Set64(int64(result.CompletedLength))
 			}
@@ -127,6 +191,12 @@ func singleDownload(ctx context.Context, client daemonclient.DaemonClient, cfg *
 		}
 	}
 
+	if downError != nil && errors.Is(ctx.Err(), context.Canceled) {
+		wLog.Warnf("daemon downloads file interrupted: %v", downError)
+		cancelTask(client, taskID, wLog)
+		return downError
+	}
+
 	if downError != nil && !cfg.KeepOriginalOffset {
 		wLog.Warnf("daemon downloads file error: %v", downError)
 		fmt.Printf("daemon downloads file error: %v\n", downError)
@@ -136,7 +206,63 @@ func singleDownload(ctx context.Context, client daemonclient.DaemonClient, cfg *
 	return downError
 }
 
-func downloadFromSource(ctx context.Context, cfg *config.DfgetConfig, hdr map[string]string) error {
+// streamDownload streams the content to w in order. The content is downloaded from source only when
+// daemon fails before any content is written, as the written content can not be taken back.
+func streamDownload(ctx context.Context, client daemonclient.DaemonClient, cfg *config.DfgetConfig, hdr map[string]string,
+	w io.Writer, wLog *logger.SugaredLoggerOnWith) error {
+	if client == nil {
+		return streamFromSource(ctx, cfg, hdr, w)
+	}
+
+	var (
+		start     = time.Now()
+		counter   = &countingWriter{w: w}
+		pb        *progressbar.ProgressBar
+		rc        io.ReadCloser
+		downError error
+	)
+
+	// the task in daemon is canceled when the stream is closed before all content is read
+	if rc, downError = client.StreamTask(ctx, newDownRequest(cfg, hdr)); downError == nil {
+		var dst io.Writer = counter
+		if cfg.ShowProgress {
+			pb = newProgressBar(-1)
+			dst = io.MultiWriter(counter, pb)
+		}
+
+		_, downError = io.Copy(dst, rc)
+		rc.Close()
+	}
+
+	if downError == nil {
+		if pb != nil {
+			pb.Describe("Downloaded")
+			_ = pb.Close()
+		}
+
+		wLog.Infof("stream from daemon success, length: %d bytes cost: %d ms", counter.n, time.Since(start).Milliseconds())
+		fmt.Printf("finish total length %d bytes\n", counter.n)
+		return nil
+	}
+
+	if errors.Is(ctx.Err(), context.Canceled) {
+		wLog.Warnf("daemon streams file interrupted: %v", downError)
+		return downError
+	}
+
+	if counter.n > 0 {
+		wLog.Errorf("daemon streams file error after %d bytes written: %v", counter.n, downError)
+		return fmt.Errorf("stream from daemon error after %d bytes written: %w", counter.n, downError)
+	}
+
+	wLog.Warnf("daemon streams file error: %v", downError)
+	fmt.Printf("daemon streams file error: %v\n", downError)
+	return streamFromSource(ctx, cfg, hdr, w)
+}
+
+// streamFromSource streams the content from origins to w, the mirrors are tried in order until one origin responds.
+// The content is validated by digest after all content is written, mismatched content fails the download.
+func streamFromSource(ctx context.Context, cfg *config.DfgetConfig, hdr map[string]string, w io.Writer) error {
 	if cfg.DisableBackSource {
 		return errors.New("try to download from source but back source is disabled")
 	}
@@ -144,10 +270,101 @@ func downloadFromSource(ctx context.Context, cfg *config.DfgetConfig, hdr map[st
 	var (
 		wLog     = logger.With("url", cfg.URL)
 		start    = time.Now()
-		target   *os.File
 		response *source.Response
 		err      error
-		written  int64
+	)
+
+	wLog.Info("try to stream from source and ignore rate limit")
+	fmt.Println("try to stream from source and ignore rate limit")
+
+	origins := append([]string{cfg.URL}, cfg.Mirrors...)
+	for i, origin := range origins {
+		if response, err = openOrigin(ctx, origin, hdr); err == nil {
+			break
+		}
+
+		if i < len(origins)-1 {
+			wLog.Warnf("open origin %s error: %s, try next mirror", origin, err)
+			fmt.Printf("open origin %s error: %s, try next mirror\n", origin, err)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	var body io.Reader = response.Body
+	if !pkgstrings.IsBlank(cfg.Digest) {
+		if body, err = digest.NewReader(body, digest.WithDigest(cfg.Digest), digest.WithLogger(wLog)); err != nil {
+			return err
+		}
+	}
+
+	written, err := io.Copy(w, body)
+	if err != nil {
+		return err
+	}
+
+	wLog.Infof("stream from source success, length: %d bytes cost: %d ms", written, time.Since(start).Milliseconds())
+	fmt.Printf("finish total length %d bytes\n", written)
+	return nil
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// cancelTask asks daemon to cancel the interrupted task and reclaim its resources, the task shared
+// by other requests is kept. Daemon unsubscribes the interrupted request asynchronously,
+// so canceling is retried while the task is still subscribed.
+func cancelTask(client daemonclient.DaemonClient, taskID string, wLog *logger.SugaredLoggerOnWith) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTaskTimeout)
+	defer cancel()
+
+	req := &daemonserver.CancelTaskRequest{
+		TaskID: taskID,
+	}
+	for {
+		err := client.CancelTask(ctx, req)
+		if err == nil {
+			wLog.Infof("task %s canceled in daemon", req.TaskID)
+			return
+		}
+
+		if status.Code(err) != codes.FailedPrecondition {
+			wLog.Warnf("cancel task %s in daemon error: %v", req.TaskID, err)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			wLog.Infof("task %s is shared by other requests, keep it in daemon", req.TaskID)
+			return
+		case <-time.After(cancelTaskInterval):
+		}
+	}
+}
+
+func downloadFromSource(ctx context.Context, cfg *config.DfgetConfig, hdr map[string]string) error {
+	if cfg.DisableBackSource {
+		return errors.New("try to download from source but back source is disabled")
+	}
+
+	var (
+		wLog    = logger.With("url", cfg.URL)
+		start   = time.Now()
+		target  *os.File
+		err     error
+		written int64
 	)
 
 	wLog.Info("try to download from source and ignore rate limit")
@@ -159,51 +376,133 @@ func downloadFromSource(ctx context.Context, cfg *config.DfgetConfig, hdr map[st
 	defer os.Remove(target.Name())
 	defer target.Close()
 
-	downloadRequest, err := source.NewRequestWithContext(ctx, cfg.URL, hdr)
+	// mirrors are tried in order when the former origin fails
+	origins := append([]string{cfg.URL}, cfg.Mirrors...)
+	for i, origin := range origins {
+		if written, err = downloadFromOrigin(ctx, cfg, origin, hdr, target); err == nil {
+			break
+		}
+
+		if i < len(origins)-1 {
+			wLog.Warnf("download from origin %s error: %s, try next mirror", origin, err)
+			fmt.Printf("download from origin %s error: %s, try next mirror\n", origin, err)
+		}
+	}
+
 	if err != nil {
 		return err
 	}
-	if response, err = source.Download(downloadRequest); err != nil {
-		return err
+
+	// change file owner
+	if err = os.Chown(target.Name(), cfg.UID, cfg.GID); err != nil {
+		return fmt.Errorf("change file owner to uid[%d] gid[%d]: %w", cfg.UID, cfg.GID, err)
 	}
-	defer response.Body.Close()
-	if err = response.Validate(); err != nil {
+
+	// change file mode
+	if cfg.Mode != "" {
+		mode, err := config.ParseFileMode(cfg.Mode)
+		if err != nil {
+			return err
+		}
+
+		if err = os.Chmod(target.Name(), mode); err != nil {
+			return fmt.Errorf("change file mode to %s: %w", cfg.Mode, err)
+		}
+	}
+
+	if cfg.Sync {
+		if err = target.Sync(); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Rename(target.Name(), cfg.Output); err != nil {
 		return err
 	}
 
-	if written, err = io.Copy(target, response.Body); err != nil {
+	if cfg.Sync {
+		if err = syncDir(filepath.Dir(cfg.Output)); err != nil {
+			return err
+		}
+	}
+
+	wLog.Infof("download from source success, length: %d bytes cost: %d ms", written, time.Since(start).Milliseconds())
+	fmt.Printf("finish total length %d bytes\n", written)
+
+	return nil
+}
+
+// syncDir commits the entries of directory to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
 		return err
 	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// downloadFromOrigin downloads content from the origin into the target,
+// the content is validated by digest when digest is set.
+func downloadFromOrigin(ctx context.Context, cfg *config.DfgetConfig, origin string, hdr map[string]string, target *os.File) (int64, error) {
+	// discard the content downloaded from the former origin
+	if err := target.Truncate(0); err != nil {
+		return 0, err
+	}
+
+	if _, err := target.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	response, err := openOrigin(ctx, origin, hdr)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	written, err := io.Copy(target, response.Body)
+	if err != nil {
+		return written, err
+	}
 
 	if !pkgstrings.IsBlank(cfg.Digest) {
 		d, err := digest.Parse(cfg.Digest)
 		if err != nil {
-			return err
+			return written, err
 		}
 
 		encoded, err := digest.HashFile(target.Name(), d.Algorithm)
 		if err != nil {
-			return err
+			return written, err
 		}
 
 		if encoded != "" && encoded != d.Encoded {
-			return fmt.Errorf("%s digest is not matched: real[%s] expected[%s]", d.Algorithm, encoded, d.Encoded)
+			return written, fmt.Errorf("%s digest is not matched: real[%s] expected[%s]", d.Algorithm, encoded, d.Encoded)
 		}
 	}
 
-	// change file owner
-	if err = os.Chown(target.Name(), basic.UserID, basic.UserGroup); err != nil {
-		return fmt.Errorf("change file owner to uid[%d] gid[%d]: %w", basic.UserID, basic.UserGroup, err)
+	return written, nil
+}
+
+// openOrigin starts downloading from the origin and validates the response.
+func openOrigin(ctx context.Context, origin string, hdr map[string]string) (*source.Response, error) {
+	downloadRequest, err := source.NewRequestWithContext(ctx, origin, hdr)
+	if err != nil {
+		return nil, err
 	}
 
-	if err = os.Rename(target.Name(), cfg.Output); err != nil {
-		return err
+	response, err := source.Download(downloadRequest)
+	if err != nil {
+		return nil, err
 	}
 
-	wLog.Infof("download from source success, length: %d bytes cost: %d ms", written, time.Since(start).Milliseconds())
-	fmt.Printf("finish total length %d bytes\n", written)
+	if err = response.Validate(); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
 
-	return nil
+	return response, nil
 }
 
 func parseHeader(s []string) map[string]string {
@@ -228,6 +527,36 @@ func newDownRequest(cfg *config.DfgetConfig, hdr map[string]string) *dfdaemonv1.
 	} else {
 		rg = cfg.Range
 	}
+
+	if cfg.Resume || cfg.Sync || cfg.InPlace || cfg.Mode != "" || len(cfg.Mirrors) > 0 {
+		// copy header to avoid sending headers of daemon to source when back source in dfget
+		daemonHdr := make(map[string]string, len(hdr)+4)
+		for k, v := range hdr {
+			daemonHdr[k] = v
+		}
+
+		if cfg.Resume {
+			daemonHdr[config.HeaderDragonflyResume] = "true"
+		}
+
+		if cfg.Sync {
+			daemonHdr[config.HeaderDragonflySync] = "true"
+		}
+
+		if cfg.InPlace {
+			daemonHdr[config.HeaderDragonflyInPlace] = "true"
+		}
+
+		if cfg.Mode != "" {
+			daemonHdr[config.HeaderDragonflyMode] = cfg.Mode
+		}
+
+		if len(cfg.Mirrors) > 0 {
+			daemonHdr[config.HeaderDragonflyMirrors] = strings.Join(cfg.Mirrors, ",")
+		}
+		hdr = daemonHdr
+	}
+
 	return &dfdaemonv1.DownRequest{
 		Url:               cfg.URL,
 		Output:            cfg.Output,
@@ -243,8 +572,9 @@ func newDownRequest(cfg *config.DfgetConfig, hdr map[string]string) *dfdaemonv1.
 			Application: cfg.Application,
 		},
 		Pattern:            cfg.Pattern,
-		Uid:                int64(basic.UserID),
-		Gid:                int64(basic.UserGroup),
+		Callsystem:         cfg.CallSystem,
+		Uid:                int64(cfg.UID),
+		Gid:                int64(cfg.GID),
 		KeepOriginalOffset: cfg.KeepOriginalOffset,
 	}
 }
@@ -17,20 +17,31 @@
 package dfget
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	clientmocks "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client/mocks"
+	daemonserver "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 	"d7y.io/dragonfly/v2/pkg/source"
 	"d7y.io/dragonfly/v2/pkg/source/mocks"
 )
@@ -61,3 +72,203 @@ func Test_downloadFromSource(t *testing.T) {
 	err = downloadFromSource(context.Background(), cfg, nil)
 	assert.Nil(t, err)
 }
+
+func Test_downloadFromSourceWithMirrors(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	assert.Nil(t, err)
+	output := filepath.Join(homeDir, idgen.UUIDString())
+	defer os.Remove(output)
+
+	content := idgen.UUIDString()
+
+	sourceClient := mocks.NewMockResourceClient(gomock.NewController(t))
+	require.Nil(t, source.Register("http", sourceClient, func(request *source.Request) *source.Request {
+		return request
+	}))
+	defer source.UnRegister("http")
+
+	cfg := &config.DfgetConfig{
+		URL:     "http://a.b.c/xx",
+		Output:  output,
+		Digest:  strings.Join([]string{digest.AlgorithmSHA256, digest.SHA256FromStrings(content)}, ":"),
+		Mirrors: []string{"http://d.e.f/xx", "http://g.h.i/xx"},
+	}
+
+	// primary origin fails
+	request, err := source.NewRequest(cfg.URL)
+	assert.Nil(t, err)
+	sourceClient.EXPECT().Download(request).Return(nil, io.ErrUnexpectedEOF)
+
+	// first mirror serves different content which does not match digest
+	request, err = source.NewRequest(cfg.Mirrors[0])
+	assert.Nil(t, err)
+	sourceClient.EXPECT().Download(request).Return(source.NewResponse(io.NopCloser(strings.NewReader(idgen.UUIDString()))), nil)
+
+	request, err = source.NewRequest(cfg.Mirrors[1])
+	assert.Nil(t, err)
+	sourceClient.EXPECT().Download(request).Return(source.NewResponse(io.NopCloser(strings.NewReader(content))), nil)
+
+	err = downloadFromSource(context.Background(), cfg, nil)
+	assert.Nil(t, err)
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func Test_cancelTask(t *testing.T) {
+	request := &dfdaemonv1.DownRequest{
+		Url:     "http://a.b.c/xx",
+		UrlMeta: &commonv1.UrlMeta{Tag: "d7y"},
+	}
+	cancelRequest := &daemonserver.CancelTaskRequest{
+		TaskID: idgen.TaskID(request.Url, request.UrlMeta),
+	}
+
+	tests := []struct {
+		name string
+		mock func(m *clientmocks.MockDaemonClientMockRecorder)
+	}{
+		{
+			name: "cancel task",
+			mock: func(m *clientmocks.MockDaemonClientMockRecorder) {
+				m.CancelTask(gomock.Any(), gomock.Eq(cancelRequest)).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "retry until the interrupted request is unsubscribed",
+			mock: func(m *clientmocks.MockDaemonClientMockRecorder) {
+				gomock.InOrder(
+					m.CancelTask(gomock.Any(), gomock.Eq(cancelRequest)).Return(status.Error(codes.FailedPrecondition, "subscribed")).Times(2),
+					m.CancelTask(gomock.Any(), gomock.Eq(cancelRequest)).Return(nil).Times(1),
+				)
+			},
+		},
+		{
+			name: "task is not running",
+			mock: func(m *clientmocks.MockDaemonClientMockRecorder) {
+				m.CancelTask(gomock.Any(), gomock.Eq(cancelRequest)).Return(status.Error(codes.NotFound, "not running")).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			client := clientmocks.NewMockDaemonClient(ctl)
+			tc.mock(client.EXPECT())
+			cancelTask(client, cancelRequest.TaskID, logger.With("url", request.Url))
+		})
+	}
+}
+
+func Test_streamDownload(t *testing.T) {
+	content := idgen.UUIDString()
+
+	sourceClient := mocks.NewMockResourceClient(gomock.NewController(t))
+	require.Nil(t, source.Register("http", sourceClient, func(request *source.Request) *source.Request {
+		return request
+	}))
+	defer source.UnRegister("http")
+
+	cfg := &config.DfgetConfig{
+		URL:    "http://a.b.c/xx",
+		Output: config.StdoutOutput,
+		Digest: strings.Join([]string{digest.AlgorithmSHA256, digest.SHA256FromStrings(content)}, ":"),
+	}
+	request, err := source.NewRequest(cfg.URL)
+	require.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		mock   func(m *clientmocks.MockDaemonClientMockRecorder)
+		expect func(t *testing.T, output string, err error)
+	}{
+		{
+			name: "stream from daemon",
+			mock: func(m *clientmocks.MockDaemonClientMockRecorder) {
+				m.StreamTask(gomock.Any(), gomock.Any()).Return(io.NopCloser(strings.NewReader(content)), nil)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, content, output)
+			},
+		},
+		{
+			name: "stream from source when daemon fails before content written",
+			mock: func(m *clientmocks.MockDaemonClientMockRecorder) {
+				m.StreamTask(gomock.Any(), gomock.Any()).Return(io.NopCloser(iotest.ErrReader(errors.New("foo"))), nil)
+				sourceClient.EXPECT().Download(request).Return(source.NewResponse(io.NopCloser(strings.NewReader(content))), nil)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, content, output)
+			},
+		},
+		{
+			name: "daemon fails after content written",
+			mock: func(m *clientmocks.MockDaemonClientMockRecorder) {
+				m.StreamTask(gomock.Any(), gomock.Any()).Return(
+					io.NopCloser(io.MultiReader(strings.NewReader(content[:10]), iotest.ErrReader(errors.New("foo")))), nil)
+			},
+			expect: func(t *testing.T, output string, err error) {
+				assert.ErrorContains(t, err, "foo")
+				assert.Equal(t, content[:10], output)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := clientmocks.NewMockDaemonClient(ctrl)
+			tc.mock(client.EXPECT())
+
+			var output bytes.Buffer
+			err := streamDownload(context.Background(), client, cfg, nil, &output, logger.With("test", "test"))
+			tc.expect(t, output.String(), err)
+		})
+	}
+}
+
+func Test_streamFromSource(t *testing.T) {
+	content := idgen.UUIDString()
+
+	sourceClient := mocks.NewMockResourceClient(gomock.NewController(t))
+	require.Nil(t, source.Register("http", sourceClient, func(request *source.Request) *source.Request {
+		return request
+	}))
+	defer source.UnRegister("http")
+
+	cfg := &config.DfgetConfig{
+		URL:     "http://a.b.c/xx",
+		Output:  config.StdoutOutput,
+		Digest:  strings.Join([]string{digest.AlgorithmSHA256, digest.SHA256FromStrings(content)}, ":"),
+		Mirrors: []string{"http://d.e.f/xx"},
+	}
+
+	// primary origin fails, and the mirror serves the content
+	request, err := source.NewRequest(cfg.URL)
+	require.Nil(t, err)
+	sourceClient.EXPECT().Download(request).Return(nil, io.ErrUnexpectedEOF)
+
+	request, err = source.NewRequest(cfg.Mirrors[0])
+	require.Nil(t, err)
+	sourceClient.EXPECT().Download(request).Return(source.NewResponse(io.NopCloser(strings.NewReader(content))), nil)
+
+	var output bytes.Buffer
+	assert.Nil(t, streamFromSource(context.Background(), cfg, nil, &output))
+	assert.Equal(t, content, output.String())
+
+	// content does not match digest
+	request, err = source.NewRequest(cfg.URL)
+	require.Nil(t, err)
+	sourceClient.EXPECT().Download(request).Return(source.NewResponse(io.NopCloser(strings.NewReader(idgen.UUIDString()))), nil)
+
+	output.Reset()
+	assert.NotNil(t, streamFromSource(context.Background(), cfg, nil, &output))
+}
@@ -278,6 +278,10 @@ type PutOjectInput struct {
 	// replicas of an object cache in seed peers.
 	MaxReplicas int
 
+	// Digest is the digest of object in the format of algorithm:encoded, e.g. md5:xxx,
+	// the object is verified by dfdaemon before written to backend.
+	Digest string
+
 	// Reader is reader of object.
 	Reader io.Reader
 }
@@ -330,6 +334,12 @@ func (dfs *dfstore) PutObjectRequestWithContext(ctx context.Context, input *PutO
 		}
 	}
 
+	if input.Digest != "" {
+		if err := writer.WriteField("digest", input.Digest); err != nil {
+			return nil, err
+		}
+	}
+
 	part, err := writer.CreateFormFile("file", filepath.Base(input.ObjectKey))
 	if err != nil {
 		return nil, err
@@ -194,6 +194,9 @@ func LoadConfig(config any) error {
 	return viper.Unmarshal(config, initDecoderConfig)
 }
 
+// WatchConfig watches the config file, watcher is called with the new config
+// when the file content is changed or SIGHUP is received.
+// The file is not polled when interval is zero.
 func WatchConfig(interval time.Duration, newConfig func() (cfg any), watcher func(cfg any)) {
 	var oldData string
 	file := viper.ConfigFileUsed()
@@ -203,28 +206,45 @@ func WatchConfig(interval time.Duration, newConfig func() (cfg any), watcher fun
 		logger.Errorf("read file %s error: %v", file, err)
 	}
 	oldData = string(data)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
 loop:
 	for {
+		var force bool
 		select {
-		case <-time.After(interval):
-			// for k8s configmap case, the config file is symbol link
-			// reload file instead use fsnotify
-			data, err = ioutil.ReadFile(file)
+		case <-tick:
+		case sig := <-signals:
+			logger.Infof("receive signal: %v, reload config file %s", sig, file)
+			force = true
+		}
+
+		// for k8s configmap case, the config file is symbol link
+		// reload file instead use fsnotify
+		data, err = ioutil.ReadFile(file)
+		if err != nil {
+			logger.Errorf("read file %s error: %v", file, err)
+			continue loop
+		}
+		if force || oldData != string(data) {
+			cfg := newConfig()
+			err = LoadConfig(cfg)
 			if err != nil {
-				logger.Errorf("read file %s error: %v", file, err)
+				logger.Errorf("load config file %s error: %v", file, err)
 				continue loop
 			}
-			if oldData != string(data) {
-				cfg := newConfig()
-				err = LoadConfig(cfg)
-				if err != nil {
-					logger.Errorf("load config file %s error: %v", file, err)
-					continue loop
-				}
-				logger.Infof("config file %s changed", file)
-				watcher(cfg)
-				oldData = string(data)
-			}
+			logger.Infof("config file %s changed", file)
+			watcher(cfg)
+			oldData = string(data)
 		}
 	}
 }
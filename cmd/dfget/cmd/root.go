@@ -41,6 +41,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/dfpath"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 	"d7y.io/dragonfly/v2/pkg/source"
 	"d7y.io/dragonfly/v2/pkg/unit"
 	"d7y.io/dragonfly/v2/version"
@@ -50,6 +51,24 @@ var (
 	dfgetConfig *config.DfgetConfig
 )
 
+const (
+	// exitCodeCachePartial is the exit code of check only mode when the task is partially cached.
+	exitCodeCachePartial = 2
+
+	// exitCodeCacheMiss is the exit code of check only mode when the task is not cached or invalid.
+	exitCodeCacheMiss = 3
+)
+
+// exitError is the error with exit code of dfget.
+type exitError struct {
+	code int
+	msg  string
+}
+
+func (e *exitError) Error() string {
+	return e.msg
+}
+
 var dfgetDescription = `dfget is the client of dragonfly which takes a role of peer in a P2P network.
 When user triggers a file downloading task, dfget will download the pieces of
 file from other peers. Meanwhile, it will act as an uploader to support other
@@ -59,10 +78,10 @@ limit, transmission encryption and so on.`
 
 // rootCmd represents the commonv1 command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:                "dfget url -O path",
+	Use:                "dfget url [mirror...] -O path",
 	Short:              "the P2P client of dragonfly",
 	Long:               dfgetDescription,
-	Args:               cobra.MaximumNArgs(1),
+	Args:               cobra.ArbitraryArgs,
 	DisableAutoGenTag:  true,
 	SilenceUsage:       true,
 	FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
@@ -79,6 +98,11 @@ var rootCmd = &cobra.Command{
 			return err
 		}
 
+		// the content is streamed to stdout, so the messages are printed to stderr
+		if dfgetConfig.IsStdoutOutput() {
+			os.Stdout = os.Stderr
+		}
+
 		// Initialize daemon dfpath
 		d, err := initDfgetDfpath(dfgetConfig)
 		if err != nil {
@@ -93,6 +117,10 @@ var rootCmd = &cobra.Command{
 		// update plugin directory
 		source.UpdatePluginDir(d.PluginDir())
 
+		if dfgetConfig.CheckOnly {
+			return runCheckOnly(d.DfgetLockPath(), d.DaemonSockPath())
+		}
+
 		fmt.Printf("--%s--  %s\n", start.Format("2006-01-02 15:04:05"), dfgetConfig.URL)
 		fmt.Printf("dfget version: %s\n", version.GitVersion)
 		fmt.Printf("current user: %s, default peer ip: %s\n", basic.Username, ip.IPv4)
@@ -119,6 +147,10 @@ var rootCmd = &cobra.Command{
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		logger.Error(err)
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
@@ -135,8 +167,13 @@ func init() {
 	flagSet.StringP("url", "u", dfgetConfig.URL,
 		"Download one file from the url, equivalent to the command's first position argument")
 
+	flagSet.StringSlice("mirror", dfgetConfig.Mirrors,
+		"Alternate urls which serve the same content as the url, they are tried in order when the url fails, it requires --digest to validate the content. "+
+			"The rest of position arguments after the url are also used as mirrors")
+
 	flagSet.StringP("output", "O", dfgetConfig.Output,
-		"Destination path which is used to store the downloaded file, it must be a full path")
+		"Destination path which is used to store the downloaded file, it must be a full path, "+
+			"'-' streams the content to stdout in order, like: dfget -O - url | tar x")
 
 	flagSet.Duration("timeout", dfgetConfig.Timeout, "Timeout for the downloading task, 0 is infinite")
 
@@ -144,7 +181,8 @@ func init() {
 		"The downloading network bandwidth limit per second in format of G(B)/g/M(B)/m/K(B)/k/B, pure number will be parsed as Byte, 0 is infinite")
 
 	flagSet.String("digest", dfgetConfig.Digest,
-		"Check the integrity of the downloaded file with digest, in format of md5:xxx or sha256:yyy")
+		"Check the integrity of the downloaded file with digest, in format of md5:xxx or sha256:yyy, "+
+			"digests of fixed size ranges can be appended like sha256:yyy;4194304:aaa,bbb to fail fast on corrupted ranges")
 
 	flagSet.String("tag", dfgetConfig.Tag,
 		"Different tags for the same url will be divided into different P2P overlay, it conflicts with --digest")
@@ -164,6 +202,8 @@ func init() {
 
 	flagSet.String("application", dfgetConfig.Application, "The caller name which is mainly used for statistics and access control")
 
+	flagSet.String("callsystem", dfgetConfig.CallSystem, "The caller system name, it is used as application when application is empty")
+
 	flagSet.String("daemon-sock", dfgetConfig.DaemonSock, "Download socket path of daemon. In linux, default value is /var/run/dfdaemon.sock, in macos(just for testing), default value is /tmp/dfdaemon.sock")
 
 	flagSet.String("workhome", dfgetConfig.WorkHome, "Dfget working directory")
@@ -188,9 +228,28 @@ func init() {
 	flagSet.Bool("original-offset", dfgetConfig.KeepOriginalOffset,
 		`Range request only. Download ranged data into target file with original offset. Daemon will make a hardlink to target file. Client can download many ranged data into one file for same url. When enabled, back source in client will be disabled`)
 
+	flagSet.Bool("in-place", dfgetConfig.InPlace,
+		`Original offset only. Write ranged data into the existing target file at original offset instead of hardlink, the target file is not truncated. Many clients can download ranged data of same url into one pre-allocated file concurrently`)
+
 	flagSet.String("range", dfgetConfig.Range,
 		`Download range. Like: 0-9, stands download 10 bytes from 0 -9, [0:9] in real url`)
 
+	flagSet.Bool("resume", dfgetConfig.Resume,
+		`Resume the interrupted download from the pieces already persisted in daemon storage instead of downloading all pieces again`)
+
+	flagSet.Bool("sync", dfgetConfig.Sync,
+		`Sync the output file and its directory to disk before download completes, so the output survives power failure`)
+
+	flagSet.Bool("check-only", dfgetConfig.CheckOnly,
+		`Check whether the url is fully cached in daemon and validate the digests without downloading, `+
+			`it exits with 0 when the cache is hit, 2 when the cache is partial and 3 when the cache is missed`)
+
+	flagSet.Int("uid", dfgetConfig.UID, "The owner user id of the output file, default is the current user")
+
+	flagSet.Int("gid", dfgetConfig.GID, "The owner group id of the output file, default is the group of current user")
+
+	flagSet.String("mode", dfgetConfig.Mode, "The permissions of the output file in octal, like 0644, default permissions are kept when it is empty")
+
 	// Bind cmd flags
 	if err := viper.BindPFlags(flagSet); err != nil {
 		panic(fmt.Errorf("bind dfget flags to viper: %w", err))
@@ -242,6 +301,32 @@ func runDfget(dfgetLockPath, daemonSockPath string) error {
 	return dfget.Download(dfgetConfig, daemonClient)
 }
 
+// runCheckOnly checks the local cache of daemon, the exit code is decided by the state of cache.
+func runCheckOnly(dfgetLockPath, daemonSockPath string) error {
+	daemonClient, err := checkAndSpawnDaemon(dfgetLockPath, daemonSockPath)
+	if err != nil {
+		return fmt.Errorf("check and spawn daemon: %w", err)
+	}
+	defer daemonClient.Close()
+
+	status, err := dfget.CheckCache(dfgetConfig, daemonClient)
+	if err != nil {
+		return fmt.Errorf("check cache of url %s: %w", dfgetConfig.URL, err)
+	}
+
+	logger.With("url", dfgetConfig.URL).Infof("cache state of task %s is %s", status.TaskID, status.State)
+	fmt.Printf("task id: %s\ncache state: %s\n", status.TaskID, status.State)
+
+	switch status.State {
+	case server.CacheStateHit:
+		return nil
+	case server.CacheStatePartial:
+		return &exitError{code: exitCodeCachePartial, msg: fmt.Sprintf("url %s is partially cached: %s", dfgetConfig.URL, status.Reason)}
+	default:
+		return &exitError{code: exitCodeCacheMiss, msg: fmt.Sprintf("url %s is not cached: %s", dfgetConfig.URL, status.Reason)}
+	}
+}
+
 // checkAndSpawnDaemon do checking at three checkpoints
 func checkAndSpawnDaemon(dfgetLockPath, daemonSockPath string) (client.DaemonClient, error) {
 	target := dfnet.NetAddr{Type: dfnet.UNIX, Addr: daemonSockPath}
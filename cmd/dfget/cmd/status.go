@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
+	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
+	"d7y.io/dragonfly/v2/pkg/unit"
+)
+
+var (
+	statusTimeout time.Duration
+	statusJSON    bool
+)
+
+// statusCmd represents the daemon status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "show the runtime status of the running client daemon",
+	Long: `show the runtime status of the running client daemon through the unix socket,
+including running tasks, storage usage, rate limits and connected schedulers.`,
+	Args:              cobra.NoArgs,
+	DisableAutoGenTag: true,
+	SilenceUsage:      true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		d, err := initDaemonDfpath(cfg)
+		if err != nil {
+			return err
+		}
+
+		// Initialize logger, the logs of grpc are not printed to stdout
+		if err := logger.InitDfget(cfg.Verbose, cfg.Console, d.LogDir()); err != nil {
+			return fmt.Errorf("init client dfget logger: %w", err)
+		}
+
+		daemonClient, err := client.GetClientByAddr([]dfnet.NetAddr{{Type: dfnet.UNIX, Addr: d.DaemonSockPath()}})
+		if err != nil {
+			return err
+		}
+		defer daemonClient.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+		defer cancel()
+
+		status, err := daemonClient.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("get status of daemon %s: %w", d.DaemonSockPath(), err)
+		}
+
+		if statusJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(status)
+		}
+
+		return printStatus(os.Stdout, status)
+	},
+}
+
+func init() {
+	// Add the command to parent
+	daemonCmd.AddCommand(statusCmd)
+
+	flags := statusCmd.Flags()
+	flags.DurationVar(&statusTimeout, "timeout", 5*time.Second, "Timeout for getting status from daemon")
+	flags.BoolVar(&statusJSON, "json", false, "Print status in json format")
+}
+
+// printStatus prints status in human readable format.
+func printStatus(out io.Writer, status *server.Status) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Schedulers:")
+	if len(status.Schedulers) == 0 {
+		fmt.Fprintln(w, "  <none>")
+	}
+	for _, scheduler := range status.Schedulers {
+		fmt.Fprintf(w, "  %s\n", scheduler)
+	}
+
+	fmt.Fprintln(w, "\nRate Limits:")
+	fmt.Fprintf(w, "  Download:\t%s\n", rateLimitString(status.RateLimit.Download))
+	fmt.Fprintf(w, "  Upload:\t%s\n", rateLimitString(status.RateLimit.Upload))
+	fmt.Fprintf(w, "  Per Peer:\t%s\n", rateLimitString(status.RateLimit.PerPeer))
+
+	fmt.Fprintln(w, "\nStorage:")
+	fmt.Fprintf(w, "  Data Path:\t%s\n", status.Storage.DataPath)
+	fmt.Fprintf(w, "  Tasks:\t%d\n", status.Storage.TaskCount)
+	if status.Storage.QuotaBytes > 0 {
+		fmt.Fprintf(w, "  Used:\t%s / %s\n", unit.ToBytes(status.Storage.UsedBytes), unit.ToBytes(status.Storage.QuotaBytes))
+	} else {
+		fmt.Fprintf(w, "  Used:\t%s\n", unit.ToBytes(status.Storage.UsedBytes))
+	}
+	fmt.Fprintf(w, "  Disk Used:\t%.1f%%\n", status.Storage.DiskUsedPercent)
+
+	fmt.Fprintf(w, "\nRunning Tasks: %d\n", len(status.Tasks))
+	if len(status.Tasks) > 0 {
+		fmt.Fprintln(w, "  TASK ID\tPEER ID\tSEED\tSUBSCRIBERS\tPIECES\tPROGRESS\tETA\tAGE\tURL")
+	}
+	for _, task := range status.Tasks {
+		fmt.Fprintf(w, "  %s\t%s\t%t\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			task.TaskID, task.PeerID, task.Seed, task.Subscribers,
+			progressString(int64(task.CompletedPieces), int64(task.TotalPieces), func(n int64) string { return fmt.Sprint(n) }),
+			progressString(task.CompletedLength, task.ContentLength, func(n int64) string { return unit.ToBytes(n).String() }),
+			etaString(task.ETA), time.Since(task.StartTime).Truncate(time.Second), task.URL)
+	}
+
+	return w.Flush()
+}
+
+// rateLimitString formats rate limit in bytes per second, negative means unlimited.
+func rateLimitString(limit int64) string {
+	if limit < 0 {
+		return "unlimited"
+	}
+
+	return unit.ToBytes(limit).String() + "/s"
+}
+
+// etaString formats the estimated remaining time, zero means unknown.
+func etaString(eta time.Duration) string {
+	if eta <= 0 {
+		return "-"
+	}
+
+	return eta.Round(time.Second).String()
+}
+
+// progressString formats completed and total, total is unknown when it is negative.
+func progressString(completed, total int64, format func(int64) string) string {
+	if total < 0 {
+		return format(completed) + "/?"
+	}
+
+	var sb strings.Builder
+	sb.WriteString(format(completed))
+	sb.WriteString("/")
+	sb.WriteString(format(total))
+	if total > 0 {
+		fmt.Fprintf(&sb, " (%.1f%%)", float64(completed)*100/float64(total))
+	}
+	return sb.String()
+}
@@ -30,6 +30,7 @@ import (
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/dfstore"
+	"d7y.io/dragonfly/v2/pkg/digest"
 )
 
 var copyDescription = "copies a local file or dragonfly object to another location locally or in dragonfly object storage."
@@ -179,6 +180,12 @@ func copyLocalFileToObjectStorage(ctx context.Context, cfg *config.DfstoreConfig
 		return err
 	}
 
+	// Digest is verified by dfdaemon before the object is written to backend.
+	dgst := digest.New(digest.AlgorithmMD5, digest.MD5FromReader(f))
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
 	size := fi.Size()
 	bar := progressbar.NewOptions64(
 		size,
@@ -205,6 +212,7 @@ func copyLocalFileToObjectStorage(ctx context.Context, cfg *config.DfstoreConfig
 		Filter:      cfg.Filter,
 		Mode:        cfg.Mode,
 		MaxReplicas: cfg.MaxReplicas,
+		Digest:      dgst.String(),
 		Reader:      tr,
 	}); err != nil {
 		return err
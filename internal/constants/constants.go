@@ -28,4 +28,5 @@ const (
 	ManagerMetricsName   = "manager"
 	SchedulerMetricsName = "scheduler"
 	DfdaemonMetricsName  = "dfdaemon"
+	RPCClientMetricsName = "rpc_client"
 )
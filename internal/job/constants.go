@@ -24,7 +24,8 @@ const (
 
 // Job Name.
 const (
-	PreheatJob = "preheat"
+	PreheatJob    = "preheat"
+	DeleteTaskJob = "delete_task"
 )
 
 // Machinery server configuration.
@@ -0,0 +1,281 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+)
+
+const (
+	// signatureKeyPrefix is the prefix of redis key storing signature of sent job.
+	signatureKeyPrefix = "job_signature"
+
+	// signatureScanCount is the count of keys scanned in one iteration when listing jobs.
+	signatureScanCount = 100
+
+	// canceledJobError is the error of job state when the job is canceled.
+	canceledJobError = "job is canceled"
+)
+
+// Filter of listing jobs by state.
+const (
+	// JobFilterActive filters jobs which are not finished.
+	JobFilterActive = "active"
+
+	// JobFilterFailed filters jobs which are failed.
+	JobFilterFailed = "failed"
+)
+
+// ErrInvalidJobState is returned when the operation is not allowed in the state of job.
+var ErrInvalidJobState = errors.New("invalid job state")
+
+// JobInfo is the payload and state of a job sent to queue.
+type JobInfo struct {
+	UUID      string                 `json:"uuid"`
+	Name      string                 `json:"name"`
+	Queue     string                 `json:"queue"`
+	GroupUUID string                 `json:"group_uuid"`
+	Args      []machineryv1tasks.Arg `json:"args"`
+	State     string                 `json:"state"`
+	Error     string                 `json:"error"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// SendGroup sends the group job, and records signatures of jobs
+// which can be inspected, retried and canceled later.
+func (t *Job) SendGroup(ctx context.Context, group *machineryv1tasks.Group) error {
+	if t.backend == nil {
+		return errors.New("job backend is not initialized")
+	}
+
+	for _, signature := range group.Tasks {
+		b, err := json.Marshal(signature)
+		if err != nil {
+			return err
+		}
+
+		if err := t.backend.Set(ctx, signatureKey(signature.UUID), b, DefaultResultsExpireIn*time.Second).Err(); err != nil {
+			return err
+		}
+	}
+
+	_, err := t.Server.SendGroupWithContext(ctx, group, 0)
+	return err
+}
+
+// ListJobs returns the jobs sent by SendGroup which match the filter,
+// all jobs are returned when filter is empty, newer jobs come first.
+func (t *Job) ListJobs(ctx context.Context, filter string) ([]*JobInfo, error) {
+	if t.backend == nil {
+		return nil, errors.New("job backend is not initialized")
+	}
+
+	var jobInfos []*JobInfo
+	iter := t.backend.Scan(ctx, 0, signatureKey("*"), signatureScanCount).Iterator()
+	for iter.Next(ctx) {
+		signature, err := t.getSignature(ctx, iter.Val())
+		if err != nil {
+			// Signature may be expired after scanning.
+			continue
+		}
+
+		state, err := t.Server.GetBackend().GetState(signature.UUID)
+		if err != nil {
+			// State of job may be expired or not stored yet.
+			continue
+		}
+
+		if !matchJobFilter(state.State, filter) {
+			continue
+		}
+
+		jobInfos = append(jobInfos, newJobInfo(signature, state))
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(jobInfos, func(i, j int) bool {
+		return jobInfos[i].CreatedAt.After(jobInfos[j].CreatedAt)
+	})
+
+	return jobInfos, nil
+}
+
+// GetJob returns the job sent by SendGroup.
+func (t *Job) GetJob(ctx context.Context, uuid string) (*JobInfo, error) {
+	if t.backend == nil {
+		return nil, errors.New("job backend is not initialized")
+	}
+
+	signature, err := t.getSignature(ctx, signatureKey(uuid))
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := t.Server.GetBackend().GetState(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	return newJobInfo(signature, state), nil
+}
+
+// RetryJob sends the failed job to its queue again with the same uuid,
+// so the state of its group job is recovered when the job succeeds.
+func (t *Job) RetryJob(ctx context.Context, uuid string) (*JobInfo, error) {
+	jobInfo, err := t.GetJob(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobInfo.State != machineryv1tasks.StateFailure {
+		return nil, fmt.Errorf("%w: job %s is %s, only failed job can be retried", ErrInvalidJobState, uuid, jobInfo.State)
+	}
+
+	signature, err := t.getSignature(ctx, signatureKey(uuid))
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh expiration of signature for the retried job.
+	if err := t.backend.Expire(ctx, signatureKey(uuid), DefaultResultsExpireIn*time.Second).Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := t.Server.SendTaskWithContext(ctx, signature); err != nil {
+		return nil, err
+	}
+
+	return t.GetJob(ctx, uuid)
+}
+
+// CancelJob removes the unfinished job from its queue and marks it failed.
+// Job being processed by a worker is only marked failed, it is useful for
+// the job whose worker is gone, the state is overwritten if the worker finishes it.
+func (t *Job) CancelJob(ctx context.Context, uuid string) (*JobInfo, error) {
+	jobInfo, err := t.GetJob(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !matchJobFilter(jobInfo.State, JobFilterActive) {
+		return nil, fmt.Errorf("%w: job %s is %s, only unfinished job can be canceled", ErrInvalidJobState, uuid, jobInfo.State)
+	}
+
+	signature, err := t.getSignature(ctx, signatureKey(uuid))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.removeQueueMessage(ctx, signature.RoutingKey, uuid); err != nil {
+		return nil, err
+	}
+
+	if err := t.Server.GetBackend().SetStateFailure(signature, canceledJobError); err != nil {
+		return nil, err
+	}
+
+	return t.GetJob(ctx, uuid)
+}
+
+// removeQueueMessage removes the message of job from the queue of broker.
+func (t *Job) removeQueueMessage(ctx context.Context, queue, uuid string) error {
+	if t.broker == nil {
+		return errors.New("job broker is not initialized")
+	}
+
+	messages, err := t.broker.LRange(ctx, queue, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+
+	message, ok := findQueueMessage(messages, uuid)
+	if !ok {
+		return nil
+	}
+
+	return t.broker.LRem(ctx, queue, 1, message).Err()
+}
+
+func (t *Job) getSignature(ctx context.Context, key string) (*machineryv1tasks.Signature, error) {
+	b, err := t.backend.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	signature := &machineryv1tasks.Signature{}
+	if err := json.Unmarshal(b, signature); err != nil {
+		return nil, err
+	}
+
+	return signature, nil
+}
+
+// findQueueMessage finds the raw message of job in messages of queue.
+func findQueueMessage(messages []string, uuid string) (string, bool) {
+	for _, message := range messages {
+		signature := &machineryv1tasks.Signature{}
+		if err := json.Unmarshal([]byte(message), signature); err != nil {
+			continue
+		}
+
+		if signature.UUID == uuid {
+			return message, true
+		}
+	}
+
+	return "", false
+}
+
+// matchJobFilter returns whether the state of job matches the filter.
+func matchJobFilter(state, filter string) bool {
+	switch filter {
+	case JobFilterActive:
+		return state == machineryv1tasks.StatePending || state == machineryv1tasks.StateReceived ||
+			state == machineryv1tasks.StateStarted || state == machineryv1tasks.StateRetry
+	case JobFilterFailed:
+		return state == machineryv1tasks.StateFailure
+	default:
+		return true
+	}
+}
+
+func newJobInfo(signature *machineryv1tasks.Signature, state *machineryv1tasks.TaskState) *JobInfo {
+	return &JobInfo{
+		UUID:      signature.UUID,
+		Name:      signature.Name,
+		Queue:     signature.RoutingKey,
+		GroupUUID: signature.GroupUUID,
+		Args:      signature.Args,
+		State:     state.State,
+		Error:     state.Error,
+		CreatedAt: state.CreatedAt,
+	}
+}
+
+func signatureKey(uuid string) string {
+	return fmt.Sprintf("%s:%s", signatureKeyPrefix, uuid)
+}
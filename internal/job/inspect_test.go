@@ -0,0 +1,109 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"encoding/json"
+	"testing"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchJobFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  string
+		filter string
+		expect bool
+	}{
+		{name: "pending job is active", state: machineryv1tasks.StatePending, filter: JobFilterActive, expect: true},
+		{name: "started job is active", state: machineryv1tasks.StateStarted, filter: JobFilterActive, expect: true},
+		{name: "retrying job is active", state: machineryv1tasks.StateRetry, filter: JobFilterActive, expect: true},
+		{name: "failed job is not active", state: machineryv1tasks.StateFailure, filter: JobFilterActive, expect: false},
+		{name: "succeeded job is not active", state: machineryv1tasks.StateSuccess, filter: JobFilterActive, expect: false},
+		{name: "failed job is failed", state: machineryv1tasks.StateFailure, filter: JobFilterFailed, expect: true},
+		{name: "pending job is not failed", state: machineryv1tasks.StatePending, filter: JobFilterFailed, expect: false},
+		{name: "empty filter matches all jobs", state: machineryv1tasks.StateSuccess, filter: "", expect: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expect, matchJobFilter(tc.state, tc.filter))
+		})
+	}
+}
+
+func TestFindQueueMessage(t *testing.T) {
+	marshal := func(signature *machineryv1tasks.Signature) string {
+		b, err := json.Marshal(signature)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(b)
+	}
+
+	foo := marshal(&machineryv1tasks.Signature{UUID: "foo", Name: PreheatJob})
+	bar := marshal(&machineryv1tasks.Signature{UUID: "bar", Name: DeleteTaskJob})
+
+	tests := []struct {
+		name     string
+		messages []string
+		uuid     string
+		expect   func(t *testing.T, message string, ok bool)
+	}{
+		{
+			name:     "find message",
+			messages: []string{foo, bar},
+			uuid:     "bar",
+			expect: func(t *testing.T, message string, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(bar, message)
+			},
+		},
+		{
+			name:     "invalid message is skipped",
+			messages: []string{"foo", foo},
+			uuid:     "foo",
+			expect: func(t *testing.T, message string, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(foo, message)
+			},
+		},
+		{
+			name:     "message not found",
+			messages: []string{foo, bar},
+			uuid:     "baz",
+			expect: func(t *testing.T, message string, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Empty(message)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			message, ok := findQueueMessage(tc.messages, tc.uuid)
+			tc.expect(t, message, ok)
+		})
+	}
+}
@@ -43,6 +43,12 @@ type Job struct {
 	Server *machinery.Server
 	Worker *machinery.Worker
 	Queue  Queue
+
+	// backend is the redis client of result backend, used to store job progress.
+	backend *redis.Client
+
+	// broker is the redis client of broker, used to inspect job queues.
+	broker *redis.Client
 }
 
 func New(cfg *Config, queue Queue) (*Job, error) {
@@ -50,20 +56,22 @@ func New(cfg *Config, queue Queue) (*Job, error) {
 	machineryv1log.Set(&MachineryLogger{})
 
 	broker := fmt.Sprintf("redis://%s@%s:%d/%d", cfg.Password, cfg.Host, cfg.Port, cfg.BrokerDB)
-	if err := ping(&redis.Options{
+	brokerClient := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Password: cfg.Password,
 		DB:       cfg.BrokerDB,
-	}); err != nil {
+	})
+	if err := brokerClient.Ping(context.Background()).Err(); err != nil {
 		return nil, err
 	}
 
 	backend := fmt.Sprintf("redis://%s@%s:%d/%d", cfg.Password, cfg.Host, cfg.Port, cfg.BackendDB)
-	if err := ping(&redis.Options{
+	backendClient := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
 		Password: cfg.Password,
 		DB:       cfg.BackendDB,
-	}); err != nil {
+	})
+	if err := backendClient.Ping(context.Background()).Err(); err != nil {
 		return nil, err
 	}
 
@@ -85,16 +93,13 @@ func New(cfg *Config, queue Queue) (*Job, error) {
 	}
 
 	return &Job{
-		Server: server,
-		Queue:  queue,
+		Server:  server,
+		Queue:   queue,
+		backend: backendClient,
+		broker:  brokerClient,
 	}, nil
 }
 
-func ping(options *redis.Options) error {
-	client := redis.NewClient(options)
-	return client.Ping(context.Background()).Err()
-}
-
 func (t *Job) RegisterJob(namedJobFuncs map[string]any) error {
 	return t.Server.RegisterTasks(namedJobFuncs)
 }
@@ -0,0 +1,102 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+)
+
+// preheatProgressKeyPrefix is the prefix of redis key storing preheat progresses of group job.
+const preheatProgressKeyPrefix = "preheat_progress"
+
+// SetPreheatProgress stores the progress of a preheat job in the group job,
+// progresses expire with the results of group job.
+func (t *Job) SetPreheatProgress(ctx context.Context, groupUUID, jobUUID string, progress *PreheatProgress) error {
+	if t.backend == nil {
+		return errors.New("job backend is not initialized")
+	}
+
+	b, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+
+	key := preheatProgressKey(groupUUID)
+	if err := t.backend.HSet(ctx, key, jobUUID, b).Err(); err != nil {
+		return err
+	}
+
+	return t.backend.Expire(ctx, key, DefaultResultsExpireIn*time.Second).Err()
+}
+
+// GetPreheatProgresses returns the progresses of preheat jobs in the group job, key is job uuid.
+func (t *Job) GetPreheatProgresses(ctx context.Context, groupUUID string) (map[string]*PreheatProgress, error) {
+	if t.backend == nil {
+		return nil, errors.New("job backend is not initialized")
+	}
+
+	values, err := t.backend.HGetAll(ctx, preheatProgressKey(groupUUID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	progresses := make(map[string]*PreheatProgress, len(values))
+	for jobUUID, value := range values {
+		progress := &PreheatProgress{}
+		if err := json.Unmarshal([]byte(value), progress); err != nil {
+			return nil, err
+		}
+
+		progresses[jobUUID] = progress
+	}
+
+	return progresses, nil
+}
+
+// PreheatPercentage returns the completed percentage of preheat jobs,
+// the piece count of job whose total piece count is unknown is not counted.
+func PreheatPercentage(progresses []*PreheatProgress, jobCount int) float64 {
+	if jobCount <= 0 {
+		return 0
+	}
+
+	var percentage float64
+	for _, progress := range progresses {
+		switch {
+		case progress.State == machineryv1tasks.StateSuccess:
+			percentage += 1
+		case progress.TotalPieceCount > 0:
+			finished := float64(progress.FinishedPieceCount) / float64(progress.TotalPieceCount)
+			if finished > 1 {
+				finished = 1
+			}
+			percentage += finished
+		}
+	}
+
+	return percentage / float64(jobCount) * 100
+}
+
+func preheatProgressKey(groupUUID string) string {
+	return fmt.Sprintf("%s:%s", preheatProgressKeyPrefix, groupUUID)
+}
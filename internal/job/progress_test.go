@@ -0,0 +1,84 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"testing"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreheatPercentage(t *testing.T) {
+	tests := []struct {
+		name       string
+		progresses []*PreheatProgress
+		jobCount   int
+		expect     func(t *testing.T, percentage float64)
+	}{
+		{
+			name:     "empty job",
+			jobCount: 0,
+			expect: func(t *testing.T, percentage float64) {
+				assert := assert.New(t)
+				assert.Equal(float64(0), percentage)
+			},
+		},
+		{
+			name: "all jobs succeeded",
+			progresses: []*PreheatProgress{
+				{State: machineryv1tasks.StateSuccess},
+				{State: machineryv1tasks.StateSuccess, FinishedPieceCount: 1, TotalPieceCount: 2},
+			},
+			jobCount: 2,
+			expect: func(t *testing.T, percentage float64) {
+				assert := assert.New(t)
+				assert.Equal(float64(100), percentage)
+			},
+		},
+		{
+			name: "jobs are running",
+			progresses: []*PreheatProgress{
+				{State: machineryv1tasks.StateStarted, FinishedPieceCount: 1, TotalPieceCount: 2},
+				{State: machineryv1tasks.StateStarted, FinishedPieceCount: 3},
+				{State: machineryv1tasks.StateSuccess},
+			},
+			jobCount: 4,
+			expect: func(t *testing.T, percentage float64) {
+				assert := assert.New(t)
+				assert.Equal(37.5, percentage)
+			},
+		},
+		{
+			name: "finished piece count exceeds total piece count",
+			progresses: []*PreheatProgress{
+				{State: machineryv1tasks.StateStarted, FinishedPieceCount: 3, TotalPieceCount: 2},
+			},
+			jobCount: 2,
+			expect: func(t *testing.T, percentage float64) {
+				assert := assert.New(t)
+				assert.Equal(float64(50), percentage)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, PreheatPercentage(tc.progresses, tc.jobCount))
+		})
+	}
+}
@@ -22,7 +22,27 @@ type PreheatRequest struct {
 	Digest  string            `json:"digest" validate:"omitempty"`
 	Filter  string            `json:"filter" validate:"omitempty"`
 	Headers map[string]string `json:"headers" validate:"omitempty"`
+	// PinTTL pins the preheated task in seed peer until ttl, empty means not pinned.
+	PinTTL string `json:"pin_ttl" validate:"omitempty"`
 }
 
 type PreheatResponse struct {
 }
+
+// PreheatProgress is the progress of preheating a file in a seed peer.
+type PreheatProgress struct {
+	URL                string `json:"url"`
+	TaskID             string `json:"task_id"`
+	SchedulerHostname  string `json:"scheduler_hostname"`
+	SeedPeerHostID     string `json:"seed_peer_host_id"`
+	State              string `json:"state"`
+	FinishedPieceCount int32  `json:"finished_piece_count"`
+	TotalPieceCount    int32  `json:"total_piece_count"`
+}
+
+type DeleteTaskRequest struct {
+	TaskID string `json:"task_id" validate:"required"`
+}
+
+type DeleteTaskResponse struct {
+}
@@ -0,0 +1,106 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package uploadauth signs and verifies the tokens authorizing requests to the upload server of peers.
+package uploadauth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// bearerPrefix is the prefix of token in authorization header.
+	bearerPrefix = "Bearer "
+)
+
+const (
+	// AudienceDownload is the audience of token authorizing downloading pieces of task.
+	AudienceDownload = "download"
+
+	// AudiencePin is the audience of token authorizing pinning task in storage.
+	AudiencePin = "pin"
+)
+
+// GenerateToken generates a token which authorizes downloading pieces of task,
+// the token is signed by the secret shared by peers in cluster.
+func GenerateToken(secret, taskID string, ttl time.Duration) (string, error) {
+	return generateToken(secret, taskID, AudienceDownload, ttl)
+}
+
+// GeneratePinToken generates a token which authorizes pinning task in storage,
+// the token can not be used to download pieces of task.
+func GeneratePinToken(secret, taskID string, ttl time.Duration) (string, error) {
+	return generateToken(secret, taskID, AudiencePin, ttl)
+}
+
+// VerifyToken verifies the token is signed by secret and authorizes downloading the task.
+// Tokens without audience are issued by peers of earlier versions for downloading.
+func VerifyToken(secret, token, taskID string) error {
+	return verifyToken(secret, token, taskID, AudienceDownload, false)
+}
+
+// VerifyPinToken verifies the token is signed by secret and authorizes pinning the task.
+func VerifyPinToken(secret, token, taskID string) error {
+	return verifyToken(secret, token, taskID, AudiencePin, true)
+}
+
+func generateToken(secret, taskID, audience string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   taskID,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}).SignedString([]byte(secret))
+}
+
+func verifyToken(secret, token, taskID, audience string, audienceRequired bool) error {
+	claims := &jwt.RegisteredClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()})); err != nil {
+		return err
+	}
+
+	if claims.Subject != taskID {
+		return errors.New("token is not issued for the task")
+	}
+
+	if !claims.VerifyAudience(audience, audienceRequired) {
+		return errors.New("token is not issued for the operation")
+	}
+
+	return nil
+}
+
+// BearerToken returns the value of authorization header.
+func BearerToken(token string) string {
+	return bearerPrefix + token
+}
+
+// ParseBearerToken returns the token in authorization header.
+func ParseBearerToken(authorization string) (string, bool) {
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		return "", false
+	}
+
+	token := strings.TrimPrefix(authorization, bearerPrefix)
+	return token, token != ""
+}
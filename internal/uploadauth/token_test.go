@@ -0,0 +1,151 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package uploadauth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  func(t *testing.T) string
+		verify func(secret, token, taskID string) error
+		taskID string
+		ok     bool
+	}{
+		{
+			name: "download token",
+			token: func(t *testing.T) string {
+				token, err := GenerateToken("secret", "foo", time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyToken,
+			taskID: "foo",
+			ok:     true,
+		},
+		{
+			name: "download token of other task",
+			token: func(t *testing.T) string {
+				token, err := GenerateToken("secret", "foo", time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyToken,
+			taskID: "bar",
+			ok:     false,
+		},
+		{
+			name: "download token signed by other secret",
+			token: func(t *testing.T) string {
+				token, err := GenerateToken("other", "foo", time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyToken,
+			taskID: "foo",
+			ok:     false,
+		},
+		{
+			name: "expired download token",
+			token: func(t *testing.T) string {
+				token, err := GenerateToken("secret", "foo", -time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyToken,
+			taskID: "foo",
+			ok:     false,
+		},
+		{
+			name: "download token without audience",
+			token: func(t *testing.T) string {
+				token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+					Subject:   "foo",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+				}).SignedString([]byte("secret"))
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyToken,
+			taskID: "foo",
+			ok:     true,
+		},
+		{
+			name: "pin token",
+			token: func(t *testing.T) string {
+				token, err := GeneratePinToken("secret", "foo", time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyPinToken,
+			taskID: "foo",
+			ok:     true,
+		},
+		{
+			name: "pin token for downloading",
+			token: func(t *testing.T) string {
+				token, err := GeneratePinToken("secret", "foo", time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyToken,
+			taskID: "foo",
+			ok:     false,
+		},
+		{
+			name: "download token for pinning",
+			token: func(t *testing.T) string {
+				token, err := GenerateToken("secret", "foo", time.Minute)
+				assert.NoError(t, err)
+				return token
+			},
+			verify: VerifyPinToken,
+			taskID: "foo",
+			ok:     false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.verify("secret", tc.token(t), tc.taskID)
+			if tc.ok {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseBearerToken(t *testing.T) {
+	assert := assert.New(t)
+	token, ok := ParseBearerToken(BearerToken("foo"))
+	assert.True(ok)
+	assert.Equal("foo", token)
+
+	_, ok = ParseBearerToken("Bearer ")
+	assert.False(ok)
+
+	_, ok = ParseBearerToken("Basic foo")
+	assert.False(ok)
+}
@@ -24,6 +24,19 @@ const (
 
 	// DefaultPieceSizeLimit 15M
 	DefaultPieceSizeLimit = 15 * 1024 * 1024
+
+	// DefaultMinPieceSize 1M, used by small files for more parallel pieces
+	DefaultMinPieceSize = 1 * 1024 * 1024
+
+	// DefaultMaxPieceSize 64M, used by huge files
+	DefaultMaxPieceSize = 64 * 1024 * 1024
+
+	// DefaultMaxPieceCount is the expected max piece count of huge files,
+	// the piece size grows beyond DefaultPieceSizeLimit to keep piece count under it.
+	DefaultMaxPieceCount = 2048
+
+	// smallFileLengthLimit 16M, files not larger than it use DefaultMinPieceSize
+	smallFileLengthLimit = 16 * 1024 * 1024
 )
 
 // ComputePieceSize computes the piece size with specified fileLength.
@@ -31,16 +44,33 @@ const (
 // If the fileLength<0, which means failed to get fileLength
 // and then use the DefaultPieceSize.
 func ComputePieceSize(length int64) uint32 {
+	if length < 0 {
+		return DefaultPieceSize
+	}
+
+	if length <= smallFileLengthLimit {
+		return DefaultMinPieceSize
+	}
+
 	if length <= 200*1024*1024 {
 		return DefaultPieceSize
 	}
 
 	gapCount := length / int64(100*1024*1024)
 	mpSize := (gapCount-2)*1024*1024 + DefaultPieceSize
-	if mpSize > DefaultPieceSizeLimit {
+	if mpSize < DefaultPieceSizeLimit {
+		return uint32(mpSize)
+	}
+
+	// huge file, keep piece count under DefaultMaxPieceCount, round up to MB
+	hpSize := (length/DefaultMaxPieceCount + DefaultMinPieceSize - 1) / DefaultMinPieceSize * DefaultMinPieceSize
+	if hpSize <= DefaultPieceSizeLimit {
 		return DefaultPieceSizeLimit
 	}
-	return uint32(mpSize)
+	if hpSize > DefaultMaxPieceSize {
+		return DefaultMaxPieceSize
+	}
+	return uint32(hpSize)
 }
 
 // ComputePieceCount returns piece count with given length and pieceSize
@@ -65,6 +65,42 @@ func TestComputePieceSize(t *testing.T) {
 				length: 552562021,
 			},
 			want: DefaultPieceSize + 3*1024*1024,
+		}, {
+			name: "unknown length and get default piece size",
+			args: args{
+				length: -1,
+			},
+			want: DefaultPieceSize,
+		}, {
+			name: "small file length and get min piece size",
+			args: args{
+				length: 16 * 1024 * 1024,
+			},
+			want: DefaultMinPieceSize,
+		}, {
+			name: "length greater than small file length limit",
+			args: args{
+				length: 16*1024*1024 + 1,
+			},
+			want: DefaultPieceSize,
+		}, {
+			name: "30G length and get piece size limit",
+			args: args{
+				length: 30 * 1024 * 1024 * 1024,
+			},
+			want: DefaultPieceSizeLimit,
+		}, {
+			name: "100G length and piece count does not exceed max piece count",
+			args: args{
+				length: 100 * 1024 * 1024 * 1024,
+			},
+			want: 50 * 1024 * 1024,
+		}, {
+			name: "length reach max piece size",
+			args: args{
+				length: 200 * 1024 * 1024 * 1024,
+			},
+			want: DefaultMaxPieceSize,
 		},
 	}
 	for _, tt := range tests {
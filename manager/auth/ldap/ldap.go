@@ -0,0 +1,424 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/ldap_mock.go -source ldap.go -package mocks
+
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	timeout = 30 * time.Second
+)
+
+const (
+	// DefaultUserFilter is the default filter of searching user by name.
+	DefaultUserFilter = "(uid=%s)"
+
+	// DefaultUserNameAttribute is the default attribute of user name.
+	DefaultUserNameAttribute = "uid"
+
+	// DefaultUserEmailAttribute is the default attribute of user email.
+	DefaultUserEmailAttribute = "mail"
+
+	// DefaultGroupFilter is the default filter of searching groups.
+	DefaultGroupFilter = "(objectClass=groupOfNames)"
+
+	// DefaultGroupNameAttribute is the default attribute of group name.
+	DefaultGroupNameAttribute = "cn"
+
+	// DefaultGroupMemberAttribute is the default attribute of group members,
+	// values of attribute are dns of users.
+	DefaultGroupMemberAttribute = "member"
+)
+
+type User struct {
+	DN     string
+	Name   string
+	Email  string
+	Groups []string
+	// Roles are mapped from groups by role mapping.
+	Roles []string
+}
+
+type LDAP interface {
+	// Authenticate binds ldap with dn of user and password,
+	// and returns the user with groups.
+	Authenticate(ctx context.Context, name, password string) (*User, error)
+
+	// AuthenticationEnabled returns whether users can sign in by ldap bind.
+	AuthenticationEnabled() bool
+
+	// ListUsers returns members of the groups in role mapping.
+	ListUsers(ctx context.Context) ([]*User, error)
+
+	// ManagedRoles returns roles in role mapping, they are granted and
+	// revoked by ldap groups.
+	ManagedRoles() []string
+}
+
+type ldapConnector struct {
+	addr                 string
+	bindDN               string
+	bindPassword         string
+	baseDN               string
+	userFilter           string
+	userNameAttribute    string
+	userEmailAttribute   string
+	groupBaseDN          string
+	groupFilter          string
+	groupNameAttribute   string
+	groupMemberAttribute string
+	roleMapping          map[string]string
+	startTLS             bool
+	authenticate         bool
+	dial                 func(addr string) (ldap.Client, error)
+}
+
+// Option is a functional option for configuring the ldap.
+type Option func(l *ldapConnector)
+
+// WithUserFilter sets filter of searching user, %s is replaced by user name.
+func WithUserFilter(filter string) Option {
+	return func(l *ldapConnector) {
+		if filter != "" {
+			l.userFilter = filter
+		}
+	}
+}
+
+// WithUserAttributes sets attributes of user name and email.
+func WithUserAttributes(name, email string) Option {
+	return func(l *ldapConnector) {
+		if name != "" {
+			l.userNameAttribute = name
+		}
+
+		if email != "" {
+			l.userEmailAttribute = email
+		}
+	}
+}
+
+// WithGroupBaseDN sets base dn of searching groups, default is base dn.
+func WithGroupBaseDN(dn string) Option {
+	return func(l *ldapConnector) {
+		if dn != "" {
+			l.groupBaseDN = dn
+		}
+	}
+}
+
+// WithGroupFilter sets filter of searching groups.
+func WithGroupFilter(filter string) Option {
+	return func(l *ldapConnector) {
+		if filter != "" {
+			l.groupFilter = filter
+		}
+	}
+}
+
+// WithGroupAttributes sets attributes of group name and group members.
+func WithGroupAttributes(name, member string) Option {
+	return func(l *ldapConnector) {
+		if name != "" {
+			l.groupNameAttribute = name
+		}
+
+		if member != "" {
+			l.groupMemberAttribute = member
+		}
+	}
+}
+
+// WithRoleMapping sets mapping from groups to roles.
+func WithRoleMapping(mapping map[string]string) Option {
+	return func(l *ldapConnector) {
+		l.roleMapping = mapping
+	}
+}
+
+// WithStartTLS upgrades the connection with StartTLS.
+func WithStartTLS(startTLS bool) Option {
+	return func(l *ldapConnector) {
+		l.startTLS = startTLS
+	}
+}
+
+// WithAuthentication enables users signing in by ldap bind.
+func WithAuthentication(authenticate bool) Option {
+	return func(l *ldapConnector) {
+		l.authenticate = authenticate
+	}
+}
+
+// New returns LDAP instance, addr is url of ldap server
+// such as ldap://127.0.0.1:389 or ldaps://127.0.0.1:636.
+func New(addr, bindDN, bindPassword, baseDN string, options ...Option) (LDAP, error) {
+	if _, err := ldap.ParseDN(baseDN); err != nil {
+		return nil, fmt.Errorf("invalid base dn: %w", err)
+	}
+
+	l := &ldapConnector{
+		addr:                 addr,
+		bindDN:               bindDN,
+		bindPassword:         bindPassword,
+		baseDN:               baseDN,
+		userFilter:           DefaultUserFilter,
+		userNameAttribute:    DefaultUserNameAttribute,
+		userEmailAttribute:   DefaultUserEmailAttribute,
+		groupBaseDN:          baseDN,
+		groupFilter:          DefaultGroupFilter,
+		groupNameAttribute:   DefaultGroupNameAttribute,
+		groupMemberAttribute: DefaultGroupMemberAttribute,
+		dial:                 dial,
+	}
+
+	for _, opt := range options {
+		opt(l)
+	}
+
+	return l, nil
+}
+
+// dial connects to ldap server.
+func dial(addr string) (ldap.Client, error) {
+	return ldap.DialURL(addr, ldap.DialWithDialer(&net.Dialer{Timeout: timeout}))
+}
+
+// Authenticate binds ldap with dn of user and password,
+// and returns the user with groups.
+func (l *ldapConnector) Authenticate(ctx context.Context, name, password string) (*User, error) {
+	if !l.authenticate {
+		return nil, errors.New("ldap authentication is not enabled")
+	}
+
+	// Empty password is unauthenticated bind, which always succeeds.
+	if name == "" || password == "" {
+		return nil, errors.New("name and password are required")
+	}
+
+	conn, err := l.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entries, err := l.search(conn, l.baseDN, ldap.ScopeWholeSubtree, fmt.Sprintf(l.userFilter, ldap.EscapeFilter(name)), 0, l.userNameAttribute, l.userEmailAttribute)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) != 1 {
+		return nil, fmt.Errorf("user %s not found or not unique", name)
+	}
+
+	if err := conn.Bind(entries[0].DN, password); err != nil {
+		return nil, err
+	}
+
+	// Search groups of user with bind dn, user may have no permission of searching.
+	if err := conn.Bind(l.bindDN, l.bindPassword); err != nil {
+		return nil, err
+	}
+
+	user := l.newUser(entries[0])
+	if user.Name == "" {
+		user.Name = name
+	}
+
+	groups, err := l.search(conn, l.groupBaseDN, ldap.ScopeWholeSubtree, fmt.Sprintf("(&%s(%s=%s))", l.groupFilter, l.groupMemberAttribute, ldap.EscapeFilter(user.DN)), 0, l.groupNameAttribute)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		l.addGroup(user, group.GetAttributeValue(l.groupNameAttribute))
+	}
+
+	return user, nil
+}
+
+// AuthenticationEnabled returns whether users can sign in by ldap bind.
+func (l *ldapConnector) AuthenticationEnabled() bool {
+	return l.authenticate
+}
+
+// ListUsers returns members of the groups in role mapping.
+func (l *ldapConnector) ListUsers(ctx context.Context) ([]*User, error) {
+	conn, err := l.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	groups, err := l.search(conn, l.groupBaseDN, ldap.ScopeWholeSubtree, l.groupFilter, 0, l.groupNameAttribute, l.groupMemberAttribute)
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[string]*User{}
+	var dns []string
+	for _, group := range groups {
+		name := group.GetAttributeValue(l.groupNameAttribute)
+		if _, ok := l.roleMapping[name]; !ok {
+			continue
+		}
+
+		for _, dn := range group.GetAttributeValues(l.groupMemberAttribute) {
+			user, ok := users[dn]
+			if !ok {
+				user = &User{DN: dn}
+				users[dn] = user
+				dns = append(dns, dn)
+			}
+
+			l.addGroup(user, name)
+		}
+	}
+
+	var result []*User
+	for _, dn := range dns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		entries, err := l.search(conn, dn, ldap.ScopeBaseObject, "(objectClass=*)", 1, l.userNameAttribute, l.userEmailAttribute)
+		if err != nil {
+			// Member may be deleted but still referenced by group.
+			if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		user := l.newUser(entries[0])
+		if user.Name == "" {
+			continue
+		}
+
+		user.Groups = users[dn].Groups
+		user.Roles = users[dn].Roles
+		result = append(result, user)
+	}
+
+	return result, nil
+}
+
+// ManagedRoles returns roles in role mapping.
+func (l *ldapConnector) ManagedRoles() []string {
+	var roles []string
+	seen := map[string]struct{}{}
+	for _, role := range l.roleMapping {
+		if _, ok := seen[role]; ok {
+			continue
+		}
+
+		seen[role] = struct{}{}
+		roles = append(roles, role)
+	}
+
+	sort.Strings(roles)
+	return roles
+}
+
+// connect dials ldap server and binds with bind dn.
+func (l *ldapConnector) connect() (ldap.Client, error) {
+	conn, err := l.dial(l.addr)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+
+	if l.startTLS {
+		u, err := url.Parse(l.addr)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		if err := conn.StartTLS(&tls.Config{ServerName: u.Hostname()}); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if err := conn.Bind(l.bindDN, l.bindPassword); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// search returns entries matched filter under base dn.
+func (l *ldapConnector) search(conn ldap.Client, baseDN string, scope int, filter string, sizeLimit int, attributes ...string) ([]*ldap.Entry, error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		baseDN, scope, ldap.NeverDerefAliases, sizeLimit, int(timeout.Seconds()), false,
+		filter, attributes, nil,
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Entries, nil
+}
+
+// newUser returns user of entry.
+func (l *ldapConnector) newUser(entry *ldap.Entry) *User {
+	return &User{
+		DN:    entry.DN,
+		Name:  entry.GetAttributeValue(l.userNameAttribute),
+		Email: entry.GetAttributeValue(l.userEmailAttribute),
+	}
+}
+
+// addGroup adds group and the mapped role to user.
+func (l *ldapConnector) addGroup(user *User, group string) {
+	if group == "" {
+		return
+	}
+
+	user.Groups = append(user.Groups, group)
+	role, ok := l.roleMapping[group]
+	if !ok {
+		return
+	}
+
+	for _, r := range user.Roles {
+		if r == role {
+			return
+		}
+	}
+
+	user.Roles = append(user.Roles, role)
+}
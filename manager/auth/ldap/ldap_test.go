@@ -0,0 +1,260 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockAddr         = "ldap://127.0.0.1:389"
+	mockBindDN       = "cn=admin,dc=example,dc=com"
+	mockBindPassword = "foo"
+	mockBaseDN       = "dc=example,dc=com"
+	mockUserDN       = "uid=bar,ou=users,dc=example,dc=com"
+	mockUserPassword = "baz"
+)
+
+// fakeConn serves binds by passwords and searches by base dn and filter.
+type fakeConn struct {
+	ldap.Client
+	passwords map[string]string
+	entries   map[string][]*ldap.Entry
+	bound     string
+}
+
+func (c *fakeConn) SetTimeout(time.Duration) {}
+
+func (c *fakeConn) StartTLS(*tls.Config) error { return nil }
+
+func (c *fakeConn) Close() {}
+
+func (c *fakeConn) Bind(username, password string) error {
+	if p, ok := c.passwords[username]; !ok || p != password {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+
+	c.bound = username
+	return nil
+}
+
+func (c *fakeConn) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	if c.bound != mockBindDN {
+		return nil, ldap.NewError(ldap.LDAPResultInsufficientAccessRights, errors.New("insufficient access rights"))
+	}
+
+	entries, ok := c.entries[req.BaseDN+" "+req.Filter]
+	if !ok && req.Scope == ldap.ScopeBaseObject {
+		return nil, ldap.NewError(ldap.LDAPResultNoSuchObject, errors.New("no such object"))
+	}
+
+	return &ldap.SearchResult{Entries: entries}, nil
+}
+
+func newFakeConn() *fakeConn {
+	user := ldap.NewEntry(mockUserDN, map[string][]string{"uid": {"bar"}, "mail": {"bar@example.com"}})
+	return &fakeConn{
+		passwords: map[string]string{
+			mockBindDN: mockBindPassword,
+			mockUserDN: mockUserPassword,
+		},
+		entries: map[string][]*ldap.Entry{
+			mockBaseDN + " (uid=bar)":       {user},
+			mockBaseDN + " (uid=\\2a)":      nil,
+			mockUserDN + " (objectClass=*)": {user},
+			mockBaseDN + " (&(objectClass=groupOfNames)(member=" + mockUserDN + "))": {
+				ldap.NewEntry("cn=admins,dc=example,dc=com", map[string][]string{"cn": {"admins"}}),
+				ldap.NewEntry("cn=devs,dc=example,dc=com", map[string][]string{"cn": {"devs"}}),
+			},
+			mockBaseDN + " (objectClass=groupOfNames)": {
+				ldap.NewEntry("cn=admins,dc=example,dc=com", map[string][]string{"cn": {"admins"}, "member": {mockUserDN, "uid=deleted,dc=example,dc=com"}}),
+				ldap.NewEntry("cn=devs,dc=example,dc=com", map[string][]string{"cn": {"devs"}, "member": {mockUserDN}}),
+				ldap.NewEntry("cn=others,dc=example,dc=com", map[string][]string{"cn": {"others"}, "member": {"uid=other,dc=example,dc=com"}}),
+			},
+		},
+	}
+}
+
+func newMockLDAP(t *testing.T, conn *fakeConn, options ...Option) LDAP {
+	l, err := New(mockAddr, mockBindDN, mockBindPassword, mockBaseDN, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.(*ldapConnector).dial = func(string) (ldap.Client, error) {
+		return conn, nil
+	}
+
+	return l
+}
+
+func TestLDAP_New(t *testing.T) {
+	tests := []struct {
+		name   string
+		baseDN string
+		expect func(t *testing.T, l LDAP, err error)
+	}{
+		{
+			name:   "new ldap",
+			baseDN: mockBaseDN,
+			expect: func(t *testing.T, l LDAP, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.False(l.AuthenticationEnabled())
+				assert.Empty(l.ManagedRoles())
+			},
+		},
+		{
+			name:   "invalid base dn",
+			baseDN: "foo",
+			expect: func(t *testing.T, l LDAP, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := New(mockAddr, mockBindDN, mockBindPassword, tc.baseDN)
+			tc.expect(t, l, err)
+		})
+	}
+}
+
+func TestLDAP_Authenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		user     string
+		password string
+		expect   func(t *testing.T, user *User, err error)
+	}{
+		{
+			name:     "authenticate user",
+			options:  []Option{WithAuthentication(true), WithRoleMapping(map[string]string{"admins": "root"})},
+			user:     "bar",
+			password: mockUserPassword,
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&User{
+					DN:     mockUserDN,
+					Name:   "bar",
+					Email:  "bar@example.com",
+					Groups: []string{"admins", "devs"},
+					Roles:  []string{"root"},
+				}, user)
+			},
+		},
+		{
+			name:     "authentication is not enabled",
+			user:     "bar",
+			password: mockUserPassword,
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "ldap authentication is not enabled")
+			},
+		},
+		{
+			name:     "empty password",
+			options:  []Option{WithAuthentication(true)},
+			user:     "bar",
+			password: "",
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "name and password are required")
+			},
+		},
+		{
+			name:     "invalid password",
+			options:  []Option{WithAuthentication(true)},
+			user:     "bar",
+			password: "foo",
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.True(ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials))
+			},
+		},
+		{
+			name:     "user name is escaped",
+			options:  []Option{WithAuthentication(true)},
+			user:     "*",
+			password: mockUserPassword,
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "user * not found or not unique")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newMockLDAP(t, newFakeConn(), tc.options...)
+			user, err := l.Authenticate(context.Background(), tc.user, tc.password)
+			tc.expect(t, user, err)
+		})
+	}
+}
+
+func TestLDAP_ListUsers(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		expect  func(t *testing.T, l LDAP, users []*User, err error)
+	}{
+		{
+			name:    "list members of mapped groups",
+			options: []Option{WithRoleMapping(map[string]string{"admins": "root", "devs": "root"})},
+			expect: func(t *testing.T, l LDAP, users []*User, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]*User{{
+					DN:     mockUserDN,
+					Name:   "bar",
+					Email:  "bar@example.com",
+					Groups: []string{"admins", "devs"},
+					Roles:  []string{"root"},
+				}}, users)
+				assert.Equal([]string{"root"}, l.ManagedRoles())
+			},
+		},
+		{
+			name: "without role mapping",
+			expect: func(t *testing.T, l LDAP, users []*User, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(users)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newMockLDAP(t, newFakeConn(), tc.options...)
+			users, err := l.ListUsers(context.Background())
+			tc.expect(t, l, users, err)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"context"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// syncTimeout is the timeout of syncing users once.
+	syncTimeout = 5 * time.Minute
+)
+
+// SyncFunc syncs users of ldap to manager.
+type SyncFunc func(ctx context.Context) error
+
+// Syncer syncs users of ldap periodically.
+type Syncer interface {
+	// Serve starts syncing users.
+	Serve()
+
+	// Stop stops syncing users.
+	Stop()
+}
+
+type syncer struct {
+	sync     SyncFunc
+	interval time.Duration
+	done     chan struct{}
+}
+
+// NewSyncer returns a new Syncer instance.
+func NewSyncer(sync SyncFunc, interval time.Duration) Syncer {
+	return &syncer{
+		sync:     sync,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// Serve starts syncing users.
+func (s *syncer) Serve() {
+	tick := time.NewTicker(s.interval)
+	defer tick.Stop()
+
+	s.run()
+	for {
+		select {
+		case <-tick.C:
+			s.run()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop stops syncing users.
+func (s *syncer) Stop() {
+	close(s.done)
+}
+
+func (s *syncer) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+
+	if err := s.sync(ctx); err != nil {
+		logger.Warnf("sync ldap users failed: %s", err.Error())
+	}
+}
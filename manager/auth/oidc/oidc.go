@@ -0,0 +1,318 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/oidc_mock.go -source oidc.go -package mocks
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+const (
+	timeout = 2 * time.Minute
+)
+
+const (
+	// wellKnownPath is the path of openid provider configuration,
+	// refer to https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfig.
+	wellKnownPath = "/.well-known/openid-configuration"
+
+	// idTokenKey is the key of id token in token response.
+	idTokenKey = "id_token"
+
+	// DefaultGroupsClaim is the default claim name of groups in id token.
+	DefaultGroupsClaim = "groups"
+)
+
+// defaultScopes are the scopes requested when scopes are not configured.
+var defaultScopes = []string{"openid", "profile", "email"}
+
+// supportedSigningMethods are the signing algorithms of id token.
+var supportedSigningMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+type User struct {
+	// Issuer and Subject identify the user in identity provider.
+	Issuer  string
+	Subject string
+	Name    string
+	Email   string
+	Avatar  string
+	Groups  []string
+	// Roles are mapped from groups by role mapping.
+	Roles []string
+}
+
+type OIDC interface {
+	// AuthCodeURL returns url of identity provider for user login,
+	// state is used to protect against CSRF and as nonce of id token.
+	AuthCodeURL(state string) string
+
+	// Exchange converts authorization code into token.
+	Exchange(ctx context.Context, code string) (*oauth2.Token, error)
+
+	// GetUser verifies id token in token and returns the user.
+	GetUser(ctx context.Context, token *oauth2.Token, nonce string) (*User, error)
+
+	// ManagedRoles returns roles in role mapping, they are granted and
+	// revoked by groups of identity provider.
+	ManagedRoles() []string
+}
+
+type oidc struct {
+	config      *oauth2.Config
+	issuer      string
+	groupsClaim string
+	roleMapping map[string]string
+	keySet      *keySet
+}
+
+// Option is a functional option for configuring the oidc.
+type Option func(o *oidc)
+
+// WithScopes sets scopes of oauth2 request.
+func WithScopes(scopes []string) Option {
+	return func(o *oidc) {
+		if len(scopes) > 0 {
+			o.config.Scopes = scopes
+		}
+	}
+}
+
+// WithGroupsClaim sets claim name of groups in id token.
+func WithGroupsClaim(claim string) Option {
+	return func(o *oidc) {
+		if claim != "" {
+			o.groupsClaim = claim
+		}
+	}
+}
+
+// WithRoleMapping sets mapping from groups to roles.
+func WithRoleMapping(mapping map[string]string) Option {
+	return func(o *oidc) {
+		o.roleMapping = mapping
+	}
+}
+
+// providerMetadata is the openid provider configuration.
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// New discovers the identity provider by issuer and returns OIDC instance.
+func New(ctx context.Context, issuer, clientID, clientSecret, redirectURL string, options ...Option) (OIDC, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var metadata providerMetadata
+	if err := getJSON(ctx, strings.TrimSuffix(issuer, "/")+wellKnownPath, &metadata); err != nil {
+		return nil, err
+	}
+
+	// Issuer of provider must be identical to the configured issuer,
+	// refer to https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation.
+	if metadata.Issuer != issuer {
+		return nil, fmt.Errorf("issuer did not match, expected %s got %s", issuer, metadata.Issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("invalid openid provider configuration")
+	}
+
+	o := &oidc{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       defaultScopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  metadata.AuthorizationEndpoint,
+				TokenURL: metadata.TokenEndpoint,
+			},
+		},
+		issuer:      issuer,
+		groupsClaim: DefaultGroupsClaim,
+		keySet:      newKeySet(metadata.JWKSURI),
+	}
+
+	for _, opt := range options {
+		opt(o)
+	}
+
+	return o, nil
+}
+
+// GenerateState returns a random state for authorization request.
+func GenerateState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns url of identity provider for user login.
+func (o *oidc) AuthCodeURL(state string) string {
+	return o.config.AuthCodeURL(state, oauth2.SetAuthURLParam("nonce", state))
+}
+
+// Exchange converts authorization code into token.
+func (o *oidc) Exchange(ctx context.Context, code string) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return o.config.Exchange(ctx, code)
+}
+
+// GetUser verifies id token in token and returns the user.
+func (o *oidc) GetUser(ctx context.Context, token *oauth2.Token, nonce string) (*User, error) {
+	rawIDToken, ok := token.Extra(idTokenKey).(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("id token not found in token response")
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(rawIDToken, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return o.keySet.key(ctx, kid)
+	}, jwt.WithValidMethods(supportedSigningMethods)); err != nil {
+		return nil, err
+	}
+
+	if !claims.VerifyIssuer(o.issuer, true) {
+		return nil, errors.New("invalid issuer of id token")
+	}
+
+	if !claims.VerifyAudience(o.config.ClientID, true) {
+		return nil, errors.New("invalid audience of id token")
+	}
+
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errors.New("id token is expired")
+	}
+
+	if nonce != "" && claimString(claims, "nonce") != nonce {
+		return nil, errors.New("invalid nonce of id token")
+	}
+
+	user := &User{
+		Issuer:  o.issuer,
+		Subject: claimString(claims, "sub"),
+		Name:    claimString(claims, "preferred_username"),
+		Email:   claimString(claims, "email"),
+		Avatar:  claimString(claims, "picture"),
+		Groups:  claimStrings(claims, o.groupsClaim),
+	}
+
+	if user.Subject == "" {
+		return nil, errors.New("subject not found in id token")
+	}
+
+	if user.Name == "" {
+		user.Name = claimString(claims, "name")
+	}
+
+	if user.Name == "" {
+		user.Name = user.Subject
+	}
+
+	for _, group := range user.Groups {
+		if role, ok := o.roleMapping[group]; ok {
+			user.Roles = append(user.Roles, role)
+		}
+	}
+
+	return user, nil
+}
+
+// ManagedRoles returns roles in role mapping.
+func (o *oidc) ManagedRoles() []string {
+	var roles []string
+	seen := map[string]struct{}{}
+	for _, role := range o.roleMapping {
+		if _, ok := seen[role]; ok {
+			continue
+		}
+
+		seen[role] = struct{}{}
+		roles = append(roles, role)
+	}
+
+	sort.Strings(roles)
+	return roles
+}
+
+// claimString returns string value of claim.
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimStrings returns string slice value of claim,
+// a single string is also accepted.
+func claimStrings(claims jwt.MapClaims, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+// getJSON gets url and decodes json response into v.
+func getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("bad response status %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,275 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+const (
+	mockClientID = "foo"
+	mockKeyID    = "bar"
+	mockNonce    = "baz"
+)
+
+func newMockProvider(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc(wellKnownPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(providerMetadata{ // nolint: errcheck
+			Issuer:                server.URL,
+			AuthorizationEndpoint: server.URL + "/auth",
+			TokenEndpoint:         server.URL + "/token",
+			JWKSURI:               server.URL + "/keys",
+		})
+	})
+
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jsonWebKeySet{ // nolint: errcheck
+			Keys: []jsonWebKey{{
+				Kty: "RSA",
+				Kid: mockKeyID,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	return server
+}
+
+func newMockToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) *oauth2.Token {
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = mockKeyID
+	rawIDToken, err := idToken.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]any{idTokenKey: rawIDToken})
+}
+
+func TestOIDC_New(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newMockProvider(t, key)
+
+	tests := []struct {
+		name   string
+		issuer string
+		expect func(t *testing.T, o OIDC, err error)
+	}{
+		{
+			name:   "discover provider",
+			issuer: server.URL,
+			expect: func(t *testing.T, o OIDC, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				u, err := url.Parse(o.AuthCodeURL(mockNonce))
+				assert.NoError(err)
+				assert.Equal("/auth", u.Path)
+				assert.Equal(mockNonce, u.Query().Get("state"))
+				assert.Equal(mockNonce, u.Query().Get("nonce"))
+				assert.Equal(mockClientID, u.Query().Get("client_id"))
+				assert.Equal("openid profile email", u.Query().Get("scope"))
+			},
+		},
+		{
+			name:   "issuer did not match",
+			issuer: server.URL + "/",
+			expect: func(t *testing.T, o OIDC, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := New(context.Background(), tc.issuer, mockClientID, "secret", "http://localhost/callback")
+			tc.expect(t, o, err)
+		})
+	}
+}
+
+func TestOIDC_GetUser(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newMockProvider(t, key)
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                server.URL,
+			"aud":                mockClientID,
+			"sub":                "1",
+			"exp":                time.Now().Add(time.Hour).Unix(),
+			"nonce":              mockNonce,
+			"preferred_username": "alice",
+			"email":              "alice@example.com",
+			"groups":             []string{"admin", "dev"},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		token  func() *oauth2.Token
+		expect func(t *testing.T, user *User, err error)
+	}{
+		{
+			name: "verify id token",
+			token: func() *oauth2.Token {
+				return newMockToken(t, key, validClaims())
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&User{
+					Issuer:  server.URL,
+					Subject: "1",
+					Name:    "alice",
+					Email:   "alice@example.com",
+					Groups:  []string{"admin", "dev"},
+					Roles:   []string{"root"},
+				}, user)
+			},
+		},
+		{
+			name: "id token not found",
+			token: func() *oauth2.Token {
+				return &oauth2.Token{AccessToken: "access"}
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "invalid signature",
+			token: func() *oauth2.Token {
+				return newMockToken(t, otherKey, validClaims())
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "invalid audience",
+			token: func() *oauth2.Token {
+				claims := validClaims()
+				claims["aud"] = "other"
+				return newMockToken(t, key, claims)
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid audience of id token")
+			},
+		},
+		{
+			name: "invalid issuer",
+			token: func() *oauth2.Token {
+				claims := validClaims()
+				claims["iss"] = "other"
+				return newMockToken(t, key, claims)
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid issuer of id token")
+			},
+		},
+		{
+			name: "invalid nonce",
+			token: func() *oauth2.Token {
+				claims := validClaims()
+				claims["nonce"] = "other"
+				return newMockToken(t, key, claims)
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid nonce of id token")
+			},
+		},
+		{
+			name: "id token is expired",
+			token: func() *oauth2.Token {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Hour).Unix()
+				return newMockToken(t, key, claims)
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "use subject as name",
+			token: func() *oauth2.Token {
+				claims := validClaims()
+				delete(claims, "preferred_username")
+				claims["groups"] = "dev"
+				return newMockToken(t, key, claims)
+			},
+			expect: func(t *testing.T, user *User, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("1", user.Name)
+				assert.Equal([]string{"dev"}, user.Groups)
+				assert.Empty(user.Roles)
+			},
+		},
+	}
+
+	o, err := New(context.Background(), server.URL, mockClientID, "secret", "http://localhost/callback",
+		WithRoleMapping(map[string]string{"admin": "root"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"root"}, o.ManagedRoles())
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			user, err := o.GetUser(context.Background(), tc.token(), mockNonce)
+			tc.expect(t, user, err)
+		})
+	}
+}
@@ -17,11 +17,17 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/cache/v8"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/manager/config"
 	"d7y.io/dragonfly/v2/manager/database"
 )
@@ -33,6 +39,9 @@ const (
 	// Peer prefix of cache key.
 	PeerNamespace = "peers"
 
+	// Peer of scheduler cluster prefix of cache key.
+	ClusterPeerNamespace = "cluster-peers"
+
 	// Scheduler prefix of cache key.
 	SchedulerNamespace = "schedulers"
 
@@ -45,10 +54,31 @@ const (
 	PeerCacheTTL = 30 * time.Minute
 )
 
+const (
+	// InvalidationChannel is the redis channel of cache invalidation between manager replicas.
+	InvalidationChannel = "manager:cache:invalidation"
+)
+
 // Cache is cache client.
 type Cache struct {
 	*cache.Cache
 	TTL time.Duration
+
+	// id identifies the manager replica in invalidation messages.
+	id     string
+	rdb    *redis.Client
+	pubsub *redis.PubSub
+	mu     sync.Mutex
+	done   chan struct{}
+}
+
+// invalidation is the message of cache invalidation between manager replicas.
+type invalidation struct {
+	// Source is the id of manager replica which publishes the message.
+	Source string `json:"source"`
+
+	// Key is the cache key to be invalidated.
+	Key string `json:"key"`
 }
 
 // New cache instance.
@@ -70,10 +100,105 @@ func New(cfg *config.Config) (*Cache, error) {
 			Redis:      rdb,
 			LocalCache: localCache,
 		}),
-		TTL: cfg.Cache.Redis.TTL,
+		TTL:  cfg.Cache.Redis.TTL,
+		id:   uuid.NewString(),
+		rdb:  rdb,
+		done: make(chan struct{}),
 	}, nil
 }
 
+// Delete deletes the key from local cache and redis, then notifies
+// other manager replicas to delete the key from their local cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	if err := c.Cache.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	return c.publish(ctx, key)
+}
+
+// publish sends the invalidation of key to other manager replicas.
+func (c *Cache) publish(ctx context.Context, key string) error {
+	if c.rdb == nil {
+		return nil
+	}
+
+	msg, err := json.Marshal(&invalidation{Source: c.id, Key: key})
+	if err != nil {
+		return err
+	}
+
+	return c.rdb.Publish(ctx, InvalidationChannel, msg).Err()
+}
+
+// Serve subscribes the invalidation of other manager replicas and
+// deletes the invalidated keys from local cache.
+func (c *Cache) Serve() {
+	if c.rdb == nil {
+		return
+	}
+
+	c.mu.Lock()
+	select {
+	case <-c.done:
+		c.mu.Unlock()
+		return
+	default:
+	}
+	c.pubsub = c.rdb.Subscribe(context.Background(), InvalidationChannel)
+	ch := c.pubsub.Channel()
+	c.mu.Unlock()
+
+	logger.Infof("cache invalidation subscribed at channel %s", InvalidationChannel)
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			c.invalidate(msg.Payload)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Stop stops subscribing the invalidation.
+func (c *Cache) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	close(c.done)
+
+	if c.pubsub != nil {
+		if err := c.pubsub.Close(); err != nil {
+			logger.Errorf("cache invalidation unsubscribe failed: %v", err)
+		}
+	}
+}
+
+// invalidate deletes the key of invalidation message from local cache,
+// messages published by the replica itself are ignored.
+func (c *Cache) invalidate(payload string) {
+	var msg invalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		logger.Warnf("invalid cache invalidation message %s: %v", payload, err)
+		return
+	}
+
+	if msg.Source == c.id {
+		return
+	}
+
+	logger.Debugf("cache key %s is invalidated by manager %s", msg.Key, msg.Source)
+	c.DeleteFromLocalCache(msg.Key)
+}
+
 // Make cache key.
 func MakeCacheKey(namespace string, id string) string {
 	return fmt.Sprintf("manager:%s:%s", namespace, id)
@@ -94,6 +219,11 @@ func MakePeerCacheKey(hostname, ip string) string {
 	return MakeCacheKey(PeerNamespace, fmt.Sprintf("%s-%s", hostname, ip))
 }
 
+// Make cache key for peer of scheduler cluster.
+func MakeClusterPeerCacheKey(clusterID uint, hostname, ip string) string {
+	return MakeCacheKey(ClusterPeerNamespace, fmt.Sprintf("%d-%s-%s", clusterID, hostname, ip))
+}
+
 // Make schedulers cache key for peer.
 func MakeSchedulersCacheKeyForPeer(hostname, ip string) string {
 	return MakeCacheKey(PeerNamespace, fmt.Sprintf("%s-%s:schedulers", hostname, ip))
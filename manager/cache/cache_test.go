@@ -0,0 +1,94 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/cache/v8"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_invalidate(t *testing.T) {
+	key := MakeSchedulerCacheKey(1, "foo", "127.0.0.1")
+	tests := []struct {
+		name    string
+		payload func(c *Cache) string
+		expect  func(t *testing.T, c *Cache)
+	}{
+		{
+			name: "invalidate key published by other replica",
+			payload: func(c *Cache) string {
+				b, _ := json.Marshal(&invalidation{Source: "other", Key: key})
+				return string(b)
+			},
+			expect: func(t *testing.T, c *Cache) {
+				assert := assert.New(t)
+				assert.False(c.Exists(context.Background(), key))
+			},
+		},
+		{
+			name: "ignore key published by itself",
+			payload: func(c *Cache) string {
+				b, _ := json.Marshal(&invalidation{Source: c.id, Key: key})
+				return string(b)
+			},
+			expect: func(t *testing.T, c *Cache) {
+				assert := assert.New(t)
+				assert.True(c.Exists(context.Background(), key))
+			},
+		},
+		{
+			name: "invalid message",
+			payload: func(c *Cache) string {
+				return "foo"
+			},
+			expect: func(t *testing.T, c *Cache) {
+				assert := assert.New(t)
+				assert.True(c.Exists(context.Background(), key))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Cache{
+				Cache: cache.New(&cache.Options{
+					LocalCache: cache.NewTinyLFU(10, time.Minute),
+				}),
+				id:   "self",
+				done: make(chan struct{}),
+			}
+			if err := c.Set(&cache.Item{Key: key, Value: "bar"}); err != nil {
+				t.Fatal(err)
+			}
+
+			c.invalidate(tc.payload(c))
+			tc.expect(t, c)
+		})
+	}
+}
+
+func TestCache_StopWithoutRedis(t *testing.T) {
+	c := &Cache{done: make(chan struct{})}
+	c.Serve()
+	c.Stop()
+	c.Stop()
+}
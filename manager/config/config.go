@@ -43,6 +43,9 @@ type Config struct {
 	// ObjectStorage configuration.
 	ObjectStorage *ObjectStorageConfig `yaml:"objectStorage" mapstructure:"objectStorage"`
 
+	// Auth configuration.
+	Auth *AuthConfig `yaml:"auth" mapstructure:"auth"`
+
 	// Metrics configuration.
 	Metrics *MetricsConfig `yaml:"metrics" mapstructure:"metrics"`
 }
@@ -207,6 +210,10 @@ type MetricsConfig struct {
 
 	// Enable peer gauge metrics.
 	EnablePeerGauge bool `yaml:"enablePeerGauge" mapstructure:"enablePeerGauge"`
+
+	// PrometheusAddr is the address of prometheus which scrapes scheduler metrics,
+	// it is used to query recent traffic of clusters.
+	PrometheusAddr string `yaml:"prometheusAddr" mapstructure:"prometheusAddr"`
 }
 
 type TCPListenConfig struct {
@@ -215,6 +222,23 @@ type TCPListenConfig struct {
 
 	// PortRange stands listen port.
 	PortRange TCPListenPortRange `yaml:"port" mapstructure:"port"`
+
+	// TLS configuration, the server is plaintext when it is not set.
+	TLS *GRPCTLSServerConfig `yaml:"tls" mapstructure:"tls"`
+}
+
+type GRPCTLSServerConfig struct {
+	// Server certificate file path.
+	Cert string `yaml:"cert" mapstructure:"cert"`
+
+	// Server key file path.
+	Key string `yaml:"key" mapstructure:"key"`
+
+	// CA file path, client certificates are required and verified by CA when it is set.
+	CA string `yaml:"ca" mapstructure:"ca"`
+
+	// ReloadInterval is the interval of checking certificate files for rotation.
+	ReloadInterval time.Duration `yaml:"reloadInterval" mapstructure:"reloadInterval"`
 }
 
 type TCPListenPortRange struct {
@@ -226,7 +250,7 @@ type ObjectStorageConfig struct {
 	// Enable object storage.
 	Enable bool `yaml:"enable" mapstructure:"enable"`
 
-	// Object storage name of type, it can be s3 or oss.
+	// Object storage name of type, it can be s3, oss, azure or gcs.
 	Name string `mapstructure:"name" yaml:"name"`
 
 	// Storage region.
@@ -235,13 +259,104 @@ type ObjectStorageConfig struct {
 	// Datacenter endpoint.
 	Endpoint string `mapstructure:"endpoint" yaml:"endpoint"`
 
-	// Access key ID.
+	// Access key ID, it is the storage account name of azure
+	// and the project id of gcs.
 	AccessKey string `mapstructure:"accessKey" yaml:"accessKey"`
 
-	// Access key secret.
+	// Access key secret, it is the storage account key of azure
+	// and the json credentials of service account of gcs.
 	SecretKey string `mapstructure:"secretKey" yaml:"secretKey"`
 }
 
+type AuthConfig struct {
+	// OIDC configuration.
+	OIDC *OIDCConfig `yaml:"oidc" mapstructure:"oidc"`
+
+	// LDAP configuration.
+	LDAP *LDAPConfig `yaml:"ldap" mapstructure:"ldap"`
+}
+
+type OIDCConfig struct {
+	// Enable console users login via OpenID Connect.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Issuer is the url of identity provider, it is used for discovery.
+	Issuer string `yaml:"issuer" mapstructure:"issuer"`
+
+	// Client ID registered in identity provider.
+	ClientID string `yaml:"clientID" mapstructure:"clientID"`
+
+	// Client secret registered in identity provider.
+	ClientSecret string `yaml:"clientSecret" mapstructure:"clientSecret"`
+
+	// RedirectURL is the authorization callback url of manager,
+	// it is /api/v1/users/signin/oidc/callback of manager.
+	RedirectURL string `yaml:"redirectURL" mapstructure:"redirectURL"`
+
+	// Scopes of authorization request, default is openid, profile and email.
+	Scopes []string `yaml:"scopes" mapstructure:"scopes"`
+
+	// GroupsClaim is the claim name of groups in id token.
+	GroupsClaim string `yaml:"groupsClaim" mapstructure:"groupsClaim"`
+
+	// RoleMapping maps groups of identity provider to roles of manager,
+	// user without mapped roles has guest role.
+	RoleMapping map[string]string `yaml:"roleMapping" mapstructure:"roleMapping"`
+}
+
+type LDAPConfig struct {
+	// Enable syncing groups of ldap to roles of console users.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Addr is url of ldap server, such as ldap://127.0.0.1:389 or ldaps://127.0.0.1:636.
+	Addr string `yaml:"addr" mapstructure:"addr"`
+
+	// StartTLS upgrades the ldap:// connection with StartTLS.
+	StartTLS bool `yaml:"startTLS" mapstructure:"startTLS"`
+
+	// BindDN is dn of service account for searching users and groups.
+	BindDN string `yaml:"bindDN" mapstructure:"bindDN"`
+
+	// BindPassword is password of service account.
+	BindPassword string `yaml:"bindPassword" mapstructure:"bindPassword"`
+
+	// BaseDN is dn for searching users.
+	BaseDN string `yaml:"baseDN" mapstructure:"baseDN"`
+
+	// UserFilter is filter for searching user by name, %s is replaced by name,
+	// default is (uid=%s) and active directory uses (sAMAccountName=%s).
+	UserFilter string `yaml:"userFilter" mapstructure:"userFilter"`
+
+	// UserNameAttribute is attribute of user name, default is uid.
+	UserNameAttribute string `yaml:"userNameAttribute" mapstructure:"userNameAttribute"`
+
+	// UserEmailAttribute is attribute of user email, default is mail.
+	UserEmailAttribute string `yaml:"userEmailAttribute" mapstructure:"userEmailAttribute"`
+
+	// GroupBaseDN is dn for searching groups, default is baseDN.
+	GroupBaseDN string `yaml:"groupBaseDN" mapstructure:"groupBaseDN"`
+
+	// GroupFilter is filter for searching groups, default is (objectClass=groupOfNames)
+	// and active directory uses (objectClass=group).
+	GroupFilter string `yaml:"groupFilter" mapstructure:"groupFilter"`
+
+	// GroupNameAttribute is attribute of group name, default is cn.
+	GroupNameAttribute string `yaml:"groupNameAttribute" mapstructure:"groupNameAttribute"`
+
+	// GroupMemberAttribute is attribute of dns of group members, default is member.
+	GroupMemberAttribute string `yaml:"groupMemberAttribute" mapstructure:"groupMemberAttribute"`
+
+	// RoleMapping maps groups of ldap to roles of manager, the roles are granted to
+	// members of groups and revoked from users without password who left the groups.
+	RoleMapping map[string]string `yaml:"roleMapping" mapstructure:"roleMapping"`
+
+	// SyncInterval is interval of syncing members of groups in role mapping.
+	SyncInterval time.Duration `yaml:"syncInterval" mapstructure:"syncInterval"`
+
+	// Authenticate enables users signing in with password of ldap.
+	Authenticate bool `yaml:"authenticate" mapstructure:"authenticate"`
+}
+
 // New config instance.
 func New() *Config {
 	return &Config{
@@ -289,6 +404,16 @@ func New() *Config {
 		ObjectStorage: &ObjectStorageConfig{
 			Enable: false,
 		},
+		Auth: &AuthConfig{
+			OIDC: &OIDCConfig{
+				Enable:      false,
+				GroupsClaim: DefaultOIDCGroupsClaim,
+			},
+			LDAP: &LDAPConfig{
+				Enable:       false,
+				SyncInterval: DefaultLDAPSyncInterval,
+			},
+		},
 		Metrics: &MetricsConfig{
 			Enable:          false,
 			EnablePeerGauge: true,
@@ -310,6 +435,16 @@ func (cfg *Config) Validate() error {
 		return errors.New("server requires parameter grpc")
 	}
 
+	if cfg.Server.GRPC.TLS != nil {
+		if cfg.Server.GRPC.TLS.Cert == "" {
+			return errors.New("tls requires parameter cert")
+		}
+
+		if cfg.Server.GRPC.TLS.Key == "" {
+			return errors.New("tls requires parameter key")
+		}
+	}
+
 	if cfg.Server.REST == nil {
 		return errors.New("server requires parameter rest")
 	}
@@ -449,7 +584,9 @@ func (cfg *Config) Validate() error {
 			return errors.New("objectStorage requires parameter name")
 		}
 
-		if cfg.ObjectStorage.Name != objectstorage.ServiceNameS3 && cfg.ObjectStorage.Name != objectstorage.ServiceNameOSS {
+		switch cfg.ObjectStorage.Name {
+		case objectstorage.ServiceNameS3, objectstorage.ServiceNameOSS, objectstorage.ServiceNameAzure, objectstorage.ServiceNameGCS:
+		default:
 			return errors.New("objectStorage requires parameter name")
 		}
 
@@ -462,6 +599,34 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Auth != nil && cfg.Auth.OIDC != nil && cfg.Auth.OIDC.Enable {
+		if cfg.Auth.OIDC.Issuer == "" {
+			return errors.New("oidc requires parameter issuer")
+		}
+
+		if cfg.Auth.OIDC.ClientID == "" {
+			return errors.New("oidc requires parameter clientID")
+		}
+
+		if cfg.Auth.OIDC.RedirectURL == "" {
+			return errors.New("oidc requires parameter redirectURL")
+		}
+	}
+
+	if cfg.Auth != nil && cfg.Auth.LDAP != nil && cfg.Auth.LDAP.Enable {
+		if cfg.Auth.LDAP.Addr == "" {
+			return errors.New("ldap requires parameter addr")
+		}
+
+		if cfg.Auth.LDAP.BaseDN == "" {
+			return errors.New("ldap requires parameter baseDN")
+		}
+
+		if cfg.Auth.LDAP.SyncInterval <= 0 {
+			return errors.New("ldap requires parameter syncInterval")
+		}
+	}
+
 	if cfg.Metrics == nil {
 		return errors.New("config requires parameter metrics")
 	}
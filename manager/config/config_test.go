@@ -19,6 +19,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	testifyassert "github.com/stretchr/testify/assert"
@@ -40,6 +41,12 @@ func TestManagerConfig_Load(t *testing.T) {
 					Start: 65003,
 					End:   65003,
 				},
+				TLS: &GRPCTLSServerConfig{
+					Cert:           "server.crt",
+					Key:            "server.key",
+					CA:             "ca.crt",
+					ReloadInterval: 30 * time.Second,
+				},
 			},
 			REST: &RestConfig{
 				Addr: ":8080",
@@ -98,10 +105,45 @@ func TestManagerConfig_Load(t *testing.T) {
 			SecretKey: "bar",
 			Region:    "baz",
 		},
+		Auth: &AuthConfig{
+			OIDC: &OIDCConfig{
+				Enable:       true,
+				Issuer:       "https://idp.example.com",
+				ClientID:     "foo",
+				ClientSecret: "bar",
+				RedirectURL:  "https://manager.example.com/api/v1/users/signin/oidc/callback",
+				Scopes:       []string{"openid", "email", "groups"},
+				GroupsClaim:  "groups",
+				RoleMapping: map[string]string{
+					"admin": "root",
+				},
+			},
+			LDAP: &LDAPConfig{
+				Enable:               true,
+				Addr:                 "ldap://127.0.0.1:389",
+				StartTLS:             true,
+				BindDN:               "cn=admin,dc=example,dc=com",
+				BindPassword:         "foo",
+				BaseDN:               "dc=example,dc=com",
+				UserFilter:           "(uid=%s)",
+				UserNameAttribute:    "uid",
+				UserEmailAttribute:   "mail",
+				GroupBaseDN:          "ou=groups,dc=example,dc=com",
+				GroupFilter:          "(objectClass=groupOfNames)",
+				GroupNameAttribute:   "cn",
+				GroupMemberAttribute: "member",
+				RoleMapping: map[string]string{
+					"admins": "root",
+				},
+				SyncInterval: 1000,
+				Authenticate: true,
+			},
+		},
 		Metrics: &MetricsConfig{
 			Enable:          true,
 			Addr:            ":8000",
 			EnablePeerGauge: false,
+			PrometheusAddr:  "http://127.0.0.1:9090",
 		},
 	}
 
@@ -22,10 +22,12 @@ const (
 	AttributeID          = attribute.Key("d7y.manager.id")
 	AttributePreheatType = attribute.Key("d7y.manager.preheat.type")
 	AttributePreheatURL  = attribute.Key("d7y.manager.preheat.url")
+	AttributeTaskID      = attribute.Key("d7y.manager.task.id")
 )
 
 const (
 	SpanPreheat          = "preheat"
 	SpanGetLayers        = "get-layers"
 	SpanAuthWithRegistry = "auth-with-registry"
+	SpanDeleteTask       = "delete-task"
 )
@@ -83,3 +83,11 @@ const (
 	// DefaultPostgresTimezone is default timezone for postgres.
 	DefaultPostgresTimezone = "UTC"
 )
+
+const (
+	// DefaultOIDCGroupsClaim is default claim name of groups in id token.
+	DefaultOIDCGroupsClaim = "groups"
+
+	// DefaultLDAPSyncInterval is default interval of syncing ldap groups.
+	DefaultLDAPSyncInterval = 10 * time.Minute
+)
@@ -82,8 +82,11 @@ func migrate(db *gorm.DB) error {
 		&model.SecurityGroup{},
 		&model.User{},
 		&model.Oauth{},
+		&model.PersonalAccessToken{},
 		&model.Config{},
 		&model.Application{},
+		&model.Blocklist{},
+		&model.Tenant{},
 	)
 }
 
@@ -0,0 +1,173 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Create Blocklist
+// @Description Create by json config
+// @Tags Blocklist
+// @Accept json
+// @Produce json
+// @Param Blocklist body types.CreateBlocklistRequest true "Blocklist"
+// @Success 200 {object} model.Blocklist
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /blocklists [post]
+func (h *Handlers) CreateBlocklist(ctx *gin.Context) {
+	var json types.CreateBlocklistRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	blocklist, err := h.service.CreateBlocklist(ctx.Request.Context(), json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, blocklist)
+}
+
+// @Summary Destroy Blocklist
+// @Description Destroy by id
+// @Tags Blocklist
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /blocklists/{id} [delete]
+func (h *Handlers) DestroyBlocklist(ctx *gin.Context) {
+	var params types.BlocklistParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.DestroyBlocklist(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Update Blocklist
+// @Description Update by json config
+// @Tags Blocklist
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Blocklist body types.UpdateBlocklistRequest true "Blocklist"
+// @Success 200 {object} model.Blocklist
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /blocklists/{id} [patch]
+func (h *Handlers) UpdateBlocklist(ctx *gin.Context) {
+	var params types.BlocklistParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.UpdateBlocklistRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	blocklist, err := h.service.UpdateBlocklist(ctx.Request.Context(), params.ID, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, blocklist)
+}
+
+// @Summary Get Blocklist
+// @Description Get Blocklist by id
+// @Tags Blocklist
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.Blocklist
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /blocklists/{id} [get]
+func (h *Handlers) GetBlocklist(ctx *gin.Context) {
+	var params types.BlocklistParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	blocklist, err := h.service.GetBlocklist(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, blocklist)
+}
+
+// @Summary Get Blocklists
+// @Description Get Blocklists
+// @Tags Blocklist
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param type query string false "pattern type" Enums(regex, glob)
+// @Param scheduler_cluster_id query int false "scheduler cluster id"
+// @Success 200 {object} []model.Blocklist
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /blocklists [get]
+func (h *Handlers) GetBlocklists(ctx *gin.Context) {
+	var query types.GetBlocklistsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	blocklists, count, err := h.service.GetBlocklists(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, blocklists)
+}
@@ -36,6 +36,21 @@ func New(service service.Service) *Handlers {
 	}
 }
 
+// getUserID returns the id of the authenticated user set by the authentication middlewares.
+func (h *Handlers) getUserID(ctx *gin.Context) (uint, bool) {
+	id, ok := ctx.Get("id")
+	if !ok {
+		return 0, false
+	}
+
+	userID, ok := id.(float64)
+	if !ok {
+		return 0, false
+	}
+
+	return uint(userID), true
+}
+
 func (h *Handlers) setPaginationDefault(page, perPage *int) {
 	if *page == 0 {
 		*page = 1
@@ -0,0 +1,92 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/middlewares"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+)
+
+func TestHandlers_CreatePersonalAccessToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		userID any
+		body   string
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "create personal access token for the signed in user",
+			userID: float64(1),
+			body:   `{"name": "foo", "scopes": ["jobs"], "expired_at": "2030-01-01T00:00:00Z", "user_id": 2}`,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.CreatePersonalAccessToken(gomock.Any(), uint(1), gomock.Any()).Return(&model.PersonalAccessToken{UserID: 1}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+			},
+		},
+		{
+			name: "create personal access token without user",
+			body: `{"name": "foo", "scopes": ["jobs"], "expired_at": "2030-01-01T00:00:00Z"}`,
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, w.Code)
+			},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			tc.mock(svc.EXPECT())
+
+			r := gin.New()
+			r.Use(middlewares.Error())
+			r.Use(func(c *gin.Context) {
+				if tc.userID != nil {
+					c.Set("id", tc.userID)
+				}
+			})
+			r.POST("/personal-access-tokens", New(svc).CreatePersonalAccessToken)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "/personal-access-tokens", strings.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.ServeHTTP(w, req)
+			tc.expect(t, w)
+		})
+	}
+}
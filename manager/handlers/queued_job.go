@@ -0,0 +1,141 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Get Queued Job
+// @Description Get payload and state of job in queue by uuid
+// @Tags Queued Job
+// @Accept json
+// @Produce json
+// @Param uuid path string true "uuid"
+// @Success 200 {object} job.JobInfo
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /queued-jobs/{uuid} [get]
+func (h *Handlers) GetQueuedJob(ctx *gin.Context) {
+	var params types.QueuedJobParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	jobInfo, err := h.service.GetQueuedJob(ctx.Request.Context(), params.UUID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobInfo)
+}
+
+// @Summary Get Queued Jobs
+// @Description Get jobs in queue, newer jobs come first
+// @Tags Queued Job
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param state query string false "job state" Enums(active, failed)
+// @Success 200 {object} []job.JobInfo
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /queued-jobs [get]
+func (h *Handlers) GetQueuedJobs(ctx *gin.Context) {
+	var query types.GetQueuedJobsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	jobInfos, count, err := h.service.GetQueuedJobs(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, jobInfos)
+}
+
+// @Summary Retry Queued Job
+// @Description Send failed job to its queue again by uuid
+// @Tags Queued Job
+// @Accept json
+// @Produce json
+// @Param uuid path string true "uuid"
+// @Success 200 {object} job.JobInfo
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /queued-jobs/{uuid}/retry [post]
+func (h *Handlers) RetryQueuedJob(ctx *gin.Context) {
+	var params types.QueuedJobParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	jobInfo, err := h.service.RetryQueuedJob(ctx.Request.Context(), params.UUID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobInfo)
+}
+
+// @Summary Cancel Queued Job
+// @Description Remove unfinished job from its queue and mark it failed by uuid
+// @Tags Queued Job
+// @Accept json
+// @Produce json
+// @Param uuid path string true "uuid"
+// @Success 200 {object} job.JobInfo
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /queued-jobs/{uuid}/cancel [post]
+func (h *Handlers) CancelQueuedJob(ctx *gin.Context) {
+	var params types.QueuedJobParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	jobInfo, err := h.service.CancelQueuedJob(ctx.Request.Context(), params.UUID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobInfo)
+}
@@ -79,6 +79,60 @@ func (h *Handlers) DestroyScheduler(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// @Summary Restore Scheduler
+// @Description Restore soft deleted scheduler by id
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.Scheduler
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /schedulers/{id}/restore [post]
+func (h *Handlers) RestoreScheduler(ctx *gin.Context) {
+	var params types.SchedulerParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	scheduler, err := h.service.RestoreScheduler(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduler)
+}
+
+// @Summary Purge Scheduler
+// @Description Permanently delete soft deleted scheduler by id
+// @Tags Scheduler
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /schedulers/{id}/purge [delete]
+func (h *Handlers) PurgeScheduler(ctx *gin.Context) {
+	var params types.SchedulerParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.PurgeScheduler(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
 // @Summary Update Scheduler
 // @Description Update by json config
 // @Tags Scheduler
@@ -79,6 +79,60 @@ func (h *Handlers) DestroySchedulerCluster(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// @Summary Restore SchedulerCluster
+// @Description Restore soft deleted scheduler cluster by id
+// @Tags SchedulerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.SchedulerCluster
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/restore [post]
+func (h *Handlers) RestoreSchedulerCluster(ctx *gin.Context) {
+	var params types.SchedulerClusterParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	schedulerCluster, err := h.service.RestoreSchedulerCluster(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, schedulerCluster)
+}
+
+// @Summary Purge SchedulerCluster
+// @Description Permanently delete soft deleted scheduler cluster by id
+// @Tags SchedulerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /scheduler-clusters/{id}/purge [delete]
+func (h *Handlers) PurgeSchedulerCluster(ctx *gin.Context) {
+	var params types.SchedulerClusterParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.PurgeSchedulerCluster(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
 // @Summary Update SchedulerCluster
 // @Description Update by json config
 // @Tags SchedulerCluster
@@ -147,6 +201,7 @@ func (h *Handlers) GetSchedulerCluster(ctx *gin.Context) {
 // @Produce json
 // @Param page query int true "current page" default(0)
 // @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param tenant_id query int false "tenant id"
 // @Success 200 {object} []model.SchedulerCluster
 // @Failure 400
 // @Failure 404
@@ -147,6 +147,7 @@ func (h *Handlers) GetSecurityGroup(ctx *gin.Context) {
 // @Produce json
 // @Param page query int true "current page" default(0)
 // @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param tenant_id query int false "tenant id"
 // @Success 200 {object} []model.SecurityGroup
 // @Failure 400
 // @Failure 404
@@ -169,3 +169,31 @@ func (h *Handlers) GetSecurityRules(ctx *gin.Context) {
 	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
 	ctx.JSON(http.StatusOK, securityRules)
 }
+
+// @Summary Resolve SecurityRule
+// @Description Resolve the security domain of host by SecurityRules
+// @Tags SecurityRule
+// @Accept json
+// @Produce json
+// @Param host_name query string true "host name"
+// @Param security_domain query string false "security domain reported by host"
+// @Success 200 {object} types.ResolvedSecurityRule
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /security-rules/resolve [get]
+func (h *Handlers) ResolveSecurityRule(ctx *gin.Context) {
+	var query types.ResolveSecurityRuleQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	resolved, err := h.service.ResolveSecurityRule(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resolved)
+}
@@ -79,6 +79,60 @@ func (h *Handlers) DestroySeedPeer(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// @Summary Restore SeedPeer
+// @Description Restore soft deleted seed peer by id
+// @Tags SeedPeer
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.SeedPeer
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /seed-peers/{id}/restore [post]
+func (h *Handlers) RestoreSeedPeer(ctx *gin.Context) {
+	var params types.SeedPeerParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	seedPeer, err := h.service.RestoreSeedPeer(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, seedPeer)
+}
+
+// @Summary Purge SeedPeer
+// @Description Permanently delete soft deleted seed peer by id
+// @Tags SeedPeer
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /seed-peers/{id}/purge [delete]
+func (h *Handlers) PurgeSeedPeer(ctx *gin.Context) {
+	var params types.SeedPeerParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.PurgeSeedPeer(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
 // @Summary Update SeedPeer
 // @Description Update by json config
 // @Tags SeedPeer
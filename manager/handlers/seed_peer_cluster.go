@@ -79,6 +79,60 @@ func (h *Handlers) DestroySeedPeerCluster(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// @Summary Restore SeedPeerCluster
+// @Description Restore soft deleted seed peer cluster by id
+// @Tags SeedPeerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.SeedPeerCluster
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /seed-peer-clusters/{id}/restore [post]
+func (h *Handlers) RestoreSeedPeerCluster(ctx *gin.Context) {
+	var params types.SeedPeerClusterParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	seedPeerCluster, err := h.service.RestoreSeedPeerCluster(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, seedPeerCluster)
+}
+
+// @Summary Purge SeedPeerCluster
+// @Description Permanently delete soft deleted seed peer cluster by id
+// @Tags SeedPeerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 409
+// @Failure 500
+// @Router /seed-peer-clusters/{id}/purge [delete]
+func (h *Handlers) PurgeSeedPeerCluster(ctx *gin.Context) {
+	var params types.SeedPeerClusterParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.PurgeSeedPeerCluster(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
 // @Summary Update SeedPeerCluster
 // @Description Update by json config
 // @Tags SeedPeerCluster
@@ -147,6 +201,7 @@ func (h *Handlers) GetSeedPeerCluster(ctx *gin.Context) {
 // @Produce json
 // @Param page query int true "current page" default(0)
 // @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param tenant_id query int false "tenant id"
 // @Success 200 {object} []model.SeedPeerCluster
 // @Failure 400
 // @Failure 404
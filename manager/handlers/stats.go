@@ -0,0 +1,52 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Get Cluster Stats
+// @Description Get aggregated stats of scheduler cluster by id
+// @Tags Stats
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.ClusterStats
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /stats/clusters/{id} [get]
+func (h *Handlers) GetClusterStats(ctx *gin.Context) {
+	var params types.ClusterStatsParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	stats, err := h.service.GetClusterStats(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
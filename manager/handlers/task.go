@@ -0,0 +1,62 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Destroy Task
+// @Description Evict task from schedulers and seed peers by task id
+// @Tags Task
+// @Accept json
+// @Produce json
+// @Param task_id path string true "task id"
+// @Param scheduler_cluster_ids query []uint false "scheduler cluster ids"
+// @Param tenant_id query uint false "tenant id"
+// @Success 200 {object} model.Job
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tasks/{task_id} [delete]
+func (h *Handlers) DestroyTask(ctx *gin.Context) {
+	var params types.TaskParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var query types.DestroyTaskQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	job, err := h.service.DestroyTask(ctx.Request.Context(), params, query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,103 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/middlewares"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestHandlers_DestroyTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "destroy task",
+			url:  "/tasks/foo?scheduler_cluster_ids=1&scheduler_cluster_ids=2",
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.DestroyTask(gomock.Any(), types.TaskParams{TaskID: "foo"}, types.DestroyTaskQuery{SchedulerClusterIDs: []uint{1, 2}}).
+					Return(&model.Job{TaskID: "bar"}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+
+				var job model.Job
+				assert.NoError(json.Unmarshal(w.Body.Bytes(), &job))
+				assert.Equal("bar", job.TaskID)
+			},
+		},
+		{
+			name: "invalid query",
+			url:  "/tasks/foo?scheduler_cluster_ids=bar",
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnprocessableEntity, w.Code)
+			},
+		},
+		{
+			name: "destroy task failed",
+			url:  "/tasks/foo",
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.DestroyTask(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, w.Code)
+			},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			tc.mock(svc.EXPECT())
+
+			r := gin.New()
+			r.Use(middlewares.Error())
+			r.DELETE("/tasks/:task_id", New(svc).DestroyTask)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodDelete, tc.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.ServeHTTP(w, req)
+			tc.expect(t, w)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Create Tenant
+// @Description Create by json config
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param Tenant body types.CreateTenantRequest true "Tenant"
+// @Success 200 {object} model.Tenant
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants [post]
+func (h *Handlers) CreateTenant(ctx *gin.Context) {
+	var json types.CreateTenantRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	tenant, err := h.service.CreateTenant(ctx.Request.Context(), json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tenant)
+}
+
+// @Summary Destroy Tenant
+// @Description Destroy by id
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants/{id} [delete]
+func (h *Handlers) DestroyTenant(ctx *gin.Context) {
+	var params types.TenantParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.DestroyTenant(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Update Tenant
+// @Description Update by json config
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param Tenant body types.UpdateTenantRequest true "Tenant"
+// @Success 200 {object} model.Tenant
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants/{id} [patch]
+func (h *Handlers) UpdateTenant(ctx *gin.Context) {
+	var params types.TenantParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.UpdateTenantRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	tenant, err := h.service.UpdateTenant(ctx.Request.Context(), params.ID, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tenant)
+}
+
+// @Summary Get Tenant
+// @Description Get Tenant by id
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} model.Tenant
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants/{id} [get]
+func (h *Handlers) GetTenant(ctx *gin.Context) {
+	var params types.TenantParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	tenant, err := h.service.GetTenant(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tenant)
+}
+
+// @Summary Get Tenants
+// @Description Get Tenants
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Param name query string false "name"
+// @Success 200 {object} []model.Tenant
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants [get]
+func (h *Handlers) GetTenants(ctx *gin.Context) {
+	var query types.GetTenantsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	tenants, count, err := h.service.GetTenants(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, tenants)
+}
+
+// @Summary Add User to Tenant
+// @Description Add User to Tenant
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param user_id path string true "user id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants/{id}/users/{user_id} [put]
+func (h *Handlers) AddUserToTenant(ctx *gin.Context) {
+	var params types.AddUserToTenantParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.AddUserToTenant(ctx.Request.Context(), params.ID, params.UserID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Delete User to Tenant
+// @Description Delete User to Tenant
+// @Tags Tenant
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param user_id path string true "user id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /tenants/{id}/users/{user_id} [delete]
+func (h *Handlers) DeleteUserToTenant(ctx *gin.Context) {
+	var params types.DeleteUserToTenantParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.DeleteUserToTenant(ctx.Request.Context(), params.ID, params.UserID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -18,15 +18,25 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 
+	manageroidc "d7y.io/dragonfly/v2/manager/auth/oidc"
 	// nolint
 	_ "d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 )
 
+const (
+	// oidcStateCookieName is the cookie name of oidc state.
+	oidcStateCookieName = "oidc_state"
+
+	// oidcStateCookieMaxAge is the max age of oidc state cookie.
+	oidcStateCookieMaxAge = 10 * time.Minute
+)
+
 // @Summary Update User
 // @Description Update by json config
 // @Tags User
@@ -237,6 +247,68 @@ func (h *Handlers) OauthSigninCallback(j *jwt.GinJWTMiddleware) func(*gin.Contex
 	}
 }
 
+// @Summary OIDC Signin
+// @Description oidc signin by json config
+// @Tags User
+// @Success 302
+// @Failure 400
+// @Failure 500
+// @Router /user/signin/oidc [get]
+func (h *Handlers) OIDCSignin(ctx *gin.Context) {
+	state, err := manageroidc.GenerateState()
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	authURL, err := h.service.OIDCSignin(ctx.Request.Context(), state)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	// State is stored in cookie and verified in callback to protect against CSRF.
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookieName, state, int(oidcStateCookieMaxAge.Seconds()), "/", "", ctx.Request.TLS != nil, true)
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary OIDC Signin Callback
+// @Description oidc signin callback by json config
+// @Tags User
+// @Param code query string true "code"
+// @Param state query string true "state"
+// @Success 200
+// @Failure 400
+// @Failure 401
+// @Failure 500
+// @Router /user/signin/oidc/callback [get]
+func (h *Handlers) OIDCSigninCallback(j *jwt.GinJWTMiddleware) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		var query types.OIDCSigninCallbackQuery
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		state, err := ctx.Cookie(oidcStateCookieName)
+		if err != nil || state != query.State {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"errors": "invalid oidc state"})
+			return
+		}
+		ctx.SetCookie(oidcStateCookieName, "", -1, "/", "", ctx.Request.TLS != nil, true)
+
+		user, err := h.service.OIDCSigninCallback(ctx.Request.Context(), query.Code, state)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.Set("user", user)
+		j.LoginHandler(ctx)
+	}
+}
+
 // @Summary Get User Roles
 // @Description get roles by json config
 // @Tags User
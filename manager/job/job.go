@@ -24,6 +24,7 @@ import (
 type Job struct {
 	*internaljob.Job
 	Preheat
+	Task
 }
 
 func New(cfg *config.Config) (*Job, error) {
@@ -43,8 +44,14 @@ func New(cfg *config.Config) (*Job, error) {
 		return nil, err
 	}
 
+	t, err := newTask(j)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Job{
 		Job:     j,
 		Preheat: p,
+		Task:    t,
 	}, nil
 }
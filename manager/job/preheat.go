@@ -95,6 +95,11 @@ func (p *preheat) CreatePreheat(ctx context.Context, schedulers []model.Schedule
 	tag := json.Tag
 	filter := json.Filter
 	rawheader := json.Headers
+	if json.PinTTL != "" {
+		if ttl, err := time.ParseDuration(json.PinTTL); err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid pin ttl %s", json.PinTTL)
+		}
+	}
 
 	// Initialize queues
 	queues := getSchedulerQueues(schedulers)
@@ -127,6 +132,7 @@ func (p *preheat) CreatePreheat(ctx context.Context, schedulers []model.Schedule
 	}
 
 	for _, f := range files {
+		f.PinTTL = json.PinTTL
 		logger.Infof("preheat %s file url: %v queues: %v", json.URL, f.URL, queues)
 	}
 
@@ -160,7 +166,7 @@ func (p *preheat) createGroupJob(ctx context.Context, files []*internaljob.Prehe
 		return nil, err
 	}
 
-	if _, err := p.job.Server.SendGroupWithContext(ctx, group, 0); err != nil {
+	if err := p.job.SendGroup(ctx, group); err != nil {
 		logger.Error("create preheat group job failed", err)
 		return nil, err
 	}
@@ -0,0 +1,93 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/task_mock.go -source task.go -package mocks
+
+package job
+
+import (
+	"context"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"go.opentelemetry.io/otel/trace"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/config"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+type Task interface {
+	CreateDeleteTask(context.Context, []model.Scheduler, types.DeleteTaskArgs) (*internaljob.GroupJobState, error)
+}
+
+type task struct {
+	job *internaljob.Job
+}
+
+func newTask(job *internaljob.Job) (Task, error) {
+	return &task{
+		job: job,
+	}, nil
+}
+
+// CreateDeleteTask sends delete task job to schedulers, then schedulers
+// evict the task from memory and seed peers.
+func (t *task) CreateDeleteTask(ctx context.Context, schedulers []model.Scheduler, json types.DeleteTaskArgs) (*internaljob.GroupJobState, error) {
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, config.SpanDeleteTask, trace.WithSpanKind(trace.SpanKindProducer))
+	span.SetAttributes(config.AttributeTaskID.String(json.TaskID))
+	defer span.End()
+
+	args, err := internaljob.MarshalRequest(&internaljob.DeleteTaskRequest{
+		TaskID: json.TaskID,
+	})
+	if err != nil {
+		logger.Errorf("delete task marshal request: %v, error: %v", json, err)
+		return nil, err
+	}
+
+	// Initialize queues
+	queues := getSchedulerQueues(schedulers)
+
+	var signatures []*machineryv1tasks.Signature
+	for _, queue := range queues {
+		signatures = append(signatures, &machineryv1tasks.Signature{
+			Name:       internaljob.DeleteTaskJob,
+			RoutingKey: queue.String(),
+			Args:       args,
+		})
+	}
+
+	group, err := machineryv1tasks.NewGroup(signatures...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.job.SendGroup(ctx, group); err != nil {
+		logger.Errorf("create delete task group job failed: %s", err.Error())
+		return nil, err
+	}
+
+	logger.Infof("create delete task group job successfully, group uuid: %s, task id: %s, queues: %v", group.GroupUUID, json.TaskID, queues)
+	return &internaljob.GroupJobState{
+		GroupUUID: group.GroupUUID,
+		State:     machineryv1tasks.StatePending,
+		CreatedAt: time.Now(),
+	}, nil
+}
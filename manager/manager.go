@@ -24,10 +24,14 @@ import (
 	"time"
 
 	"github.com/gin-contrib/static"
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	managerldap "d7y.io/dragonfly/v2/manager/auth/ldap"
+	manageroidc "d7y.io/dragonfly/v2/manager/auth/oidc"
 	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/config"
 	"d7y.io/dragonfly/v2/manager/database"
@@ -90,6 +94,15 @@ type Server struct {
 
 	// Metrics server
 	metricsServer *http.Server
+
+	// Metrics collector
+	metricsCollector metrics.Collector
+
+	// LDAP syncer
+	ldapSyncer managerldap.Syncer
+
+	// Cache
+	cache *cache.Cache
 }
 
 func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
@@ -112,6 +125,7 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 	if err != nil {
 		return nil, err
 	}
+	s.cache = cache
 
 	// Initialize searcher
 	searcher := searcher.New(d.PluginDir())
@@ -137,8 +151,59 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 		}
 	}
 
+	// Initialize oidc
+	var oidc manageroidc.OIDC
+	if cfg.Auth != nil && cfg.Auth.OIDC != nil && cfg.Auth.OIDC.Enable {
+		oidc, err = manageroidc.New(
+			context.Background(),
+			cfg.Auth.OIDC.Issuer,
+			cfg.Auth.OIDC.ClientID,
+			cfg.Auth.OIDC.ClientSecret,
+			cfg.Auth.OIDC.RedirectURL,
+			manageroidc.WithScopes(cfg.Auth.OIDC.Scopes),
+			manageroidc.WithGroupsClaim(cfg.Auth.OIDC.GroupsClaim),
+			manageroidc.WithRoleMapping(cfg.Auth.OIDC.RoleMapping),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize ldap
+	var ldap managerldap.LDAP
+	if cfg.Auth != nil && cfg.Auth.LDAP != nil && cfg.Auth.LDAP.Enable {
+		ldap, err = managerldap.New(
+			cfg.Auth.LDAP.Addr,
+			cfg.Auth.LDAP.BindDN,
+			cfg.Auth.LDAP.BindPassword,
+			cfg.Auth.LDAP.BaseDN,
+			managerldap.WithStartTLS(cfg.Auth.LDAP.StartTLS),
+			managerldap.WithUserFilter(cfg.Auth.LDAP.UserFilter),
+			managerldap.WithUserAttributes(cfg.Auth.LDAP.UserNameAttribute, cfg.Auth.LDAP.UserEmailAttribute),
+			managerldap.WithGroupBaseDN(cfg.Auth.LDAP.GroupBaseDN),
+			managerldap.WithGroupFilter(cfg.Auth.LDAP.GroupFilter),
+			managerldap.WithGroupAttributes(cfg.Auth.LDAP.GroupNameAttribute, cfg.Auth.LDAP.GroupMemberAttribute),
+			managerldap.WithRoleMapping(cfg.Auth.LDAP.RoleMapping),
+			managerldap.WithAuthentication(cfg.Auth.LDAP.Authenticate),
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize prometheus api for querying scheduler metrics
+	var prometheusAPI promv1.API
+	if cfg.Metrics.PrometheusAddr != "" {
+		client, err := promapi.NewClient(promapi.Config{Address: cfg.Metrics.PrometheusAddr})
+		if err != nil {
+			return nil, err
+		}
+
+		prometheusAPI = promv1.NewAPI(client)
+	}
+
 	// Initialize REST server
-	restService := service.New(db, cache, job, enforcer, objectStorage)
+	restService := service.New(db, cache, job, enforcer, objectStorage, oidc, ldap, prometheusAPI)
 	router, err := router.Init(cfg, d.LogDir(), restService, enforcer, EmbedFolder(assets, assetsTargetPath))
 	if err != nil {
 		return nil, err
@@ -154,6 +219,11 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 		return nil, err
 	}
 
+	// Initialize ldap syncer after roles are initialized
+	if ldap != nil {
+		s.ldapSyncer = managerldap.NewSyncer(restService.SyncLDAPUsers, cfg.Auth.LDAP.SyncInterval)
+	}
+
 	// Initialize GRPC server
 	var grpcOptions []grpc.ServerOption
 	if s.config.Options.Telemetry.Jaeger != "" {
@@ -162,12 +232,16 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 			grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()),
 		}
 	}
-	grpcServer := rpcserver.New(cfg, db, cache, searcher, objectStorage, cfg.ObjectStorage, grpcOptions...)
+	grpcServer, err := rpcserver.New(cfg, db, cache, searcher, objectStorage, cfg.ObjectStorage, grpcOptions...)
+	if err != nil {
+		return nil, err
+	}
 	s.grpcServer = grpcServer
 
 	// Initialize prometheus
 	if cfg.Metrics.Enable {
 		s.metricsServer = metrics.New(cfg.Metrics, grpcServer)
+		s.metricsCollector = metrics.NewCollector(db, objectStorage, cfg.ObjectStorage.Name, metrics.DefaultCollectInterval)
 	}
 
 	return s, nil
@@ -185,6 +259,19 @@ func (s *Server) Serve() error {
 		}
 	}()
 
+	// Started cache invalidation
+	go s.cache.Serve()
+
+	// Started metrics collector
+	if s.metricsCollector != nil {
+		go s.metricsCollector.Serve()
+	}
+
+	// Started ldap syncer
+	if s.ldapSyncer != nil {
+		go s.ldapSyncer.Serve()
+	}
+
 	// Started metrics server
 	if s.metricsServer != nil {
 		go func() {
@@ -223,6 +310,19 @@ func (s *Server) Stop() {
 		logger.Info("rest server closed under request")
 	}
 
+	// Stop cache invalidation
+	s.cache.Stop()
+
+	// Stop metrics collector
+	if s.metricsCollector != nil {
+		s.metricsCollector.Stop()
+	}
+
+	// Stop ldap syncer
+	if s.ldapSyncer != nil {
+		s.ldapSyncer.Stop()
+	}
+
 	// Stop metrics server
 	if s.metricsServer != nil {
 		if err := s.metricsServer.Shutdown(context.Background()); err != nil {
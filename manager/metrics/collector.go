@@ -0,0 +1,184 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/cache"
+	"d7y.io/dragonfly/v2/manager/database"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
+)
+
+const (
+	// DefaultCollectInterval is the default interval of collecting metrics.
+	DefaultCollectInterval = 30 * time.Second
+
+	// collectTimeout is the timeout of collecting metrics once.
+	collectTimeout = 10 * time.Second
+)
+
+// Collector collects metrics of manager resources periodically,
+// which are not updated by requests.
+type Collector interface {
+	// Serve starts collecting metrics.
+	Serve()
+
+	// Stop stops collecting metrics.
+	Stop()
+}
+
+type collector struct {
+	database          *database.Database
+	objectStorage     objectstorage.ObjectStorage
+	objectStorageName string
+	interval          time.Duration
+	done              chan struct{}
+}
+
+// NewCollector returns a new Collector instance.
+func NewCollector(database *database.Database, objectStorage objectstorage.ObjectStorage, objectStorageName string, interval time.Duration) Collector {
+	return &collector{
+		database:          database,
+		objectStorage:     objectStorage,
+		objectStorageName: objectStorageName,
+		interval:          interval,
+		done:              make(chan struct{}),
+	}
+}
+
+// Serve starts collecting metrics.
+func (c *collector) Serve() {
+	tick := time.NewTicker(c.interval)
+	defer tick.Stop()
+
+	c.collect()
+	for {
+		select {
+		case <-tick.C:
+			c.collect()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Stop stops collecting metrics.
+func (c *collector) Stop() {
+	close(c.done)
+}
+
+func (c *collector) collect() {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	if err := c.collectServices(ctx, &model.Scheduler{}, "scheduler_cluster_id", SchedulerGauge); err != nil {
+		logger.Warnf("collect scheduler metrics failed: %s", err.Error())
+	}
+
+	if err := c.collectServices(ctx, &model.SeedPeer{}, "seed_peer_cluster_id", SeedPeerGauge); err != nil {
+		logger.Warnf("collect seed peer metrics failed: %s", err.Error())
+	}
+
+	if err := c.collectClusterPeers(ctx); err != nil {
+		logger.Warnf("collect cluster peer metrics failed: %s", err.Error())
+	}
+
+	c.collectObjectStorage(ctx)
+}
+
+// collectServices counts services by cluster and state.
+func (c *collector) collectServices(ctx context.Context, m any, clusterColumn string, gauge *prometheus.GaugeVec) error {
+	var rows []struct {
+		ClusterID uint
+		State     string
+		Count     int64
+	}
+	if err := c.database.DB.WithContext(ctx).Model(m).
+		Select(clusterColumn + " AS cluster_id, state, COUNT(*) AS count").
+		Group(clusterColumn + ", state").
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	gauge.Reset()
+	for _, row := range rows {
+		gauge.WithLabelValues(strconv.FormatUint(uint64(row.ClusterID), 10), row.State).Set(float64(row.Count))
+	}
+
+	return nil
+}
+
+// collectClusterPeers counts active peers by scheduler cluster,
+// peers are stored in cache when listing schedulers.
+func (c *collector) collectClusterPeers(ctx context.Context) error {
+	prefix := cache.MakeCacheKey(cache.ClusterPeerNamespace, "")
+	counts := map[string]int{}
+	iter := c.database.RDB.Scan(ctx, 0, prefix+"*", 0).Iterator()
+	for iter.Next(ctx) {
+		if clusterID, ok := parseClusterID(strings.TrimPrefix(iter.Val(), prefix)); ok {
+			counts[clusterID]++
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return err
+	}
+
+	ClusterPeerGauge.Reset()
+	for clusterID, count := range counts {
+		ClusterPeerGauge.WithLabelValues(clusterID).Set(float64(count))
+	}
+
+	return nil
+}
+
+// collectObjectStorage checks health of object storage by listing buckets.
+func (c *collector) collectObjectStorage(ctx context.Context) {
+	if c.objectStorage == nil {
+		return
+	}
+
+	if _, err := c.objectStorage.ListBucketMetadatas(ctx); err != nil {
+		logger.Warnf("object storage %s is unhealthy: %s", c.objectStorageName, err.Error())
+		ObjectStorageHealthGauge.WithLabelValues(c.objectStorageName).Set(0)
+		return
+	}
+
+	ObjectStorageHealthGauge.WithLabelValues(c.objectStorageName).Set(1)
+}
+
+// parseClusterID parses cluster id from id of cache key in format of {clusterID}-{hostname}-{ip}.
+func parseClusterID(id string) (string, bool) {
+	idx := strings.IndexByte(id, '-')
+	if idx <= 0 {
+		return "", false
+	}
+
+	if _, err := strconv.ParseUint(id[:idx], 10, 64); err != nil {
+		return "", false
+	}
+
+	return id[:idx], true
+}
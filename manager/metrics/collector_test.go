@@ -0,0 +1,108 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/objectstorage"
+	"d7y.io/dragonfly/v2/pkg/objectstorage/mocks"
+)
+
+func TestCollector_parseClusterID(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     string
+		expect func(t *testing.T, clusterID string, ok bool)
+	}{
+		{
+			name: "parse cluster id",
+			id:   "1-foo-127.0.0.1",
+			expect: func(t *testing.T, clusterID string, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal("1", clusterID)
+			},
+		},
+		{
+			name: "cluster id is not number",
+			id:   "foo-bar-127.0.0.1",
+			expect: func(t *testing.T, clusterID string, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "cluster id not found",
+			id:   "foo",
+			expect: func(t *testing.T, clusterID string, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clusterID, ok := parseClusterID(tc.id)
+			tc.expect(t, clusterID, ok)
+		})
+	}
+}
+
+func TestCollector_collectObjectStorage(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(m *mocks.MockObjectStorageMockRecorder)
+		expect float64
+	}{
+		{
+			name: "object storage is healthy",
+			mock: func(m *mocks.MockObjectStorageMockRecorder) {
+				m.ListBucketMetadatas(gomock.Any()).Return([]*objectstorage.BucketMetadata{}, nil)
+			},
+			expect: 1,
+		},
+		{
+			name: "object storage is unhealthy",
+			mock: func(m *mocks.MockObjectStorageMockRecorder) {
+				m.ListBucketMetadatas(gomock.Any()).Return(nil, errors.New("foo"))
+			},
+			expect: 0,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			objectStorage := mocks.NewMockObjectStorage(ctl)
+			tc.mock(objectStorage.EXPECT())
+
+			c := NewCollector(nil, objectStorage, "s3", DefaultCollectInterval).(*collector)
+			c.collectObjectStorage(context.Background())
+			assert.Equal(t, tc.expect, testutil.ToFloat64(ObjectStorageHealthGauge.WithLabelValues("s3")))
+		})
+	}
+}
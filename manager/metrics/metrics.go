@@ -48,4 +48,54 @@ var (
 		Name:      "peer_total",
 		Help:      "Gauge of the number of peer.",
 	})
+
+	SchedulerGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.ManagerMetricsName,
+		Name:      "scheduler_total",
+		Help:      "Gauge of the number of registered scheduler.",
+	}, []string{"cluster_id", "state"})
+
+	SeedPeerGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.ManagerMetricsName,
+		Name:      "seed_peer_total",
+		Help:      "Gauge of the number of registered seed peer.",
+	}, []string{"cluster_id", "state"})
+
+	ClusterPeerGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.ManagerMetricsName,
+		Name:      "cluster_peer_total",
+		Help:      "Gauge of the number of active peer in scheduler cluster.",
+	}, []string{"cluster_id"})
+
+	KeepAliveFailureCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.ManagerMetricsName,
+		Name:      "keepalive_failure_total",
+		Help:      "Counter of the number of failed keepalive.",
+	}, []string{"source_type", "cluster_id"})
+
+	SearchSchedulerClusterCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.ManagerMetricsName,
+		Name:      "search_scheduler_cluster_total",
+		Help:      "Counter of the number of searching scheduler cluster.",
+	}, []string{"result"})
+
+	ObjectStorageHealthGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.ManagerMetricsName,
+		Name:      "object_storage_healthy",
+		Help:      "Gauge of the health of object storage, 1 is healthy and 0 is unhealthy.",
+	}, []string{"name"})
+)
+
+const (
+	// SearchResultHit is the result of searching when scheduler cluster is found.
+	SearchResultHit = "hit"
+
+	// SearchResultMiss is the result of searching when scheduler cluster is not found.
+	SearchResultMiss = "miss"
 )
@@ -31,6 +31,8 @@ import (
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 
 	"d7y.io/dragonfly/v2/internal/dferrors"
+	"d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/service"
 )
 
 type ErrorResponse struct {
@@ -120,6 +122,26 @@ func Error() gin.HandlerFunc {
 			return
 		}
 
+		// Job error handler
+		if errors.Is(err.Err, job.ErrInvalidJobState) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Message: http.StatusText(http.StatusConflict),
+				Error:   err.Err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		// Soft delete error handler
+		if errors.Is(err.Err, service.ErrResourceInUse) || errors.Is(err.Err, service.ErrResourceNotDeleted) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Message: http.StatusText(http.StatusConflict),
+				Error:   err.Err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
 		// Unknown error
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Message: err.Err.Error(),
@@ -0,0 +1,66 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/service"
+	pkgstrings "d7y.io/dragonfly/v2/pkg/strings"
+)
+
+// PersonalAccessToken authenticates the request carrying personal access token in
+// authorization header, other requests are handled by next, generally the jwt middleware.
+func PersonalAccessToken(service service.Service, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !strings.HasPrefix(rawToken, model.PersonalAccessTokenPrefix) {
+			next(c)
+			return
+		}
+
+		personalAccessToken, err := service.AuthenticatePersonalAccessToken(c.Request.Context(), rawToken)
+		if err != nil {
+			logger.Errorf("authenticate personal access token error: %s", err)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": http.StatusText(http.StatusUnauthorized),
+			})
+			c.Abort()
+			return
+		}
+
+		// Token can only access the api groups in its scopes.
+		scope, err := rbac.GetAPIGroupName(c.Request.URL.Path)
+		if err != nil || !pkgstrings.Contains(personalAccessToken.Scopes, scope) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "permission deny",
+			})
+			c.Abort()
+			return
+		}
+
+		// Keep the same type of user id as jwt claims for rbac middleware.
+		c.Set("id", float64(personalAccessToken.UserID))
+		c.Next()
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+)
+
+func TestPersonalAccessToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		mock          func(ms *mocks.MockServiceMockRecorder)
+		expect        func(t *testing.T, w *httptest.ResponseRecorder, nextCalled bool)
+	}{
+		{
+			name:          "request without personal access token",
+			authorization: "Bearer foo",
+			mock:          func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, nextCalled bool) {
+				assert := assert.New(t)
+				assert.True(nextCalled)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("", w.Body.String())
+			},
+		},
+		{
+			name:          "authenticate personal access token failed",
+			authorization: "Bearer dfp_foo",
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.AuthenticatePersonalAccessToken(gomock.Any(), "dfp_foo").Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, nextCalled bool) {
+				assert := assert.New(t)
+				assert.False(nextCalled)
+				assert.Equal(http.StatusUnauthorized, w.Code)
+			},
+		},
+		{
+			name:          "personal access token out of scopes",
+			authorization: "Bearer dfp_foo",
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.AuthenticatePersonalAccessToken(gomock.Any(), "dfp_foo").Return(&model.PersonalAccessToken{
+					Scopes: []string{"seed-peer-clusters"},
+					UserID: 1,
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, nextCalled bool) {
+				assert := assert.New(t)
+				assert.False(nextCalled)
+				assert.Equal(http.StatusUnauthorized, w.Code)
+			},
+		},
+		{
+			name:          "personal access token in scopes",
+			authorization: "Bearer dfp_foo",
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.AuthenticatePersonalAccessToken(gomock.Any(), "dfp_foo").Return(&model.PersonalAccessToken{
+					Scopes: []string{"scheduler-clusters"},
+					UserID: 1,
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder, nextCalled bool) {
+				assert := assert.New(t)
+				assert.False(nextCalled)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("1", w.Body.String())
+			},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			tc.mock(svc.EXPECT())
+
+			var nextCalled bool
+			r := gin.New()
+			r.GET("/api/v1/scheduler-clusters", PersonalAccessToken(svc, func(c *gin.Context) {
+				nextCalled = true
+				c.Abort()
+			}), func(c *gin.Context) {
+				id, _ := c.Get("id")
+				c.String(http.StatusOK, "%v", id)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/scheduler-clusters", nil)
+			req.Header.Set("Authorization", tc.authorization)
+			r.ServeHTTP(w, req)
+			tc.expect(t, w, nextCalled)
+		})
+	}
+}
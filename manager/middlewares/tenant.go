@@ -0,0 +1,53 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/service"
+)
+
+// Tenant limits the resources of the request to the tenants of the authenticated user,
+// so users can not view or mutate resources of other tenants.
+// It must be placed after the authentication middlewares, requests without user are rejected.
+func Tenant(s service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := c.Get("id")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "tenant validate error!",
+			})
+			c.Abort()
+			return
+		}
+
+		scope, err := s.GetTenantScope(c.Request.Context(), uint(id.(float64)))
+		if err != nil {
+			logger.Errorf("get tenant scope error: %s", err)
+			c.Error(err) // nolint: errcheck
+			c.Abort()
+			return
+		}
+
+		c.Request = c.Request.WithContext(service.WithTenantScope(c.Request.Context(), scope))
+		c.Next()
+	}
+}
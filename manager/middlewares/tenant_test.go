@@ -0,0 +1,112 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/service"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestTenant(t *testing.T) {
+	tests := []struct {
+		name   string
+		id     any
+		mock   func(ms *mocks.MockServiceMockRecorder)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "request without user",
+			mock: func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, w.Code)
+			},
+		},
+		{
+			name: "get tenant scope failed",
+			id:   float64(1),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.GetTenantScope(gomock.Any(), uint(1)).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusInternalServerError, w.Code)
+			},
+		},
+		{
+			name: "root user",
+			id:   float64(1),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.GetTenantScope(gomock.Any(), uint(1)).Return(&types.TenantScope{All: true}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("&{true []}", w.Body.String())
+			},
+		},
+		{
+			name: "tenant member",
+			id:   float64(2),
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.GetTenantScope(gomock.Any(), uint(2)).Return(&types.TenantScope{TenantIDs: []uint{1, 3}}, nil).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, w.Code)
+				assert.Equal("&{false [1 3]}", w.Body.String())
+			},
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			tc.mock(svc.EXPECT())
+
+			r := gin.New()
+			r.Use(Error())
+			r.GET("/api/v1/scheduler-clusters", func(c *gin.Context) {
+				if tc.id != nil {
+					c.Set("id", tc.id)
+				}
+			}, Tenant(svc), func(c *gin.Context) {
+				scope, _ := service.TenantScopeFromContext(c.Request.Context())
+				c.String(http.StatusOK, fmt.Sprint(scope))
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/scheduler-clusters", nil)
+			r.ServeHTTP(w, req)
+			tc.expect(t, w)
+		})
+	}
+}
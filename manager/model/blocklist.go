@@ -0,0 +1,26 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+type Blocklist struct {
+	Model
+	Type               string           `gorm:"column:type;type:varchar(256);default:'regex';comment:pattern type" json:"type"`
+	Pattern            string           `gorm:"column:pattern;type:varchar(1024);not null;comment:url pattern" json:"pattern"`
+	BIO                string           `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	SchedulerClusterID uint             `gorm:"index;not null;comment:scheduler cluster id" json:"scheduler_cluster_id"`
+	SchedulerCluster   SchedulerCluster `json:"-"`
+}
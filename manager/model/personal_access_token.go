@@ -0,0 +1,41 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import "time"
+
+const (
+	PersonalAccessTokenStateActive   = "active"
+	PersonalAccessTokenStateInactive = "inactive"
+)
+
+// PersonalAccessTokenPrefix is the prefix of personal access tokens,
+// it distinguishes personal access tokens from jwt tokens.
+const PersonalAccessTokenPrefix = "dfp_"
+
+type PersonalAccessToken struct {
+	Model
+	Name      string    `gorm:"column:name;type:varchar(256);index:uk_personal_access_token_name,unique,priority:2;not null;comment:name" json:"name"`
+	BIO       string    `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	Token     string    `gorm:"column:token;type:varchar(256);index:uk_personal_access_token,unique;not null;comment:sha256 of access token" json:"-"`
+	RawToken  string    `gorm:"-" json:"token,omitempty"`
+	Scopes    Array     `gorm:"column:scopes;not null;comment:api groups the token can access" json:"scopes"`
+	State     string    `gorm:"column:state;type:varchar(256);default:'active';comment:service state" json:"state"`
+	ExpiredAt time.Time `gorm:"column:expired_at;type:timestamp;default:current_timestamp;not null;comment:expired at" json:"expired_at"`
+	UserID    uint      `gorm:"column:user_id;index:uk_personal_access_token_name,unique,priority:1;comment:user id" json:"user_id"`
+	User      User      `json:"user"`
+}
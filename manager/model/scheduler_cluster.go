@@ -23,6 +23,7 @@ type SchedulerCluster struct {
 	Config           JSONMap           `gorm:"column:config;not null;comment:configuration" json:"config"`
 	ClientConfig     JSONMap           `gorm:"column:client_config;not null;comment:client configuration" json:"client_config"`
 	Scopes           JSONMap           `gorm:"column:scopes;comment:match scopes" json:"scopes"`
+	Features         JSONMap           `gorm:"column:features;comment:feature flags" json:"features"`
 	IsDefault        bool              `gorm:"column:is_default;not null;default:false;comment:default scheduler cluster" json:"is_default"`
 	SeedPeerClusters []SeedPeerCluster `gorm:"many2many:seed_peer_cluster_scheduler_cluster;" json:"seed_peer_clusters"`
 	Schedulers       []Scheduler       `json:"-"`
@@ -30,5 +31,8 @@ type SchedulerCluster struct {
 	Application      Application       `json:"-"`
 	SecurityGroupID  uint              `gorm:"comment:security group id" json:"security_group_id"`
 	SecurityGroup    SecurityGroup     `json:"-"`
+	TenantID         uint              `gorm:"comment:tenant id" json:"tenant_id"`
+	Tenant           Tenant            `json:"-"`
 	Jobs             []Job             `gorm:"many2many:job_scheduler_cluster;" json:"jobs"`
+	Blocklists       []Blocklist       `json:"-"`
 }
@@ -23,4 +23,6 @@ type SecurityGroup struct {
 	SecurityRules     []SecurityRule     `gorm:"many2many:security_group_security_rule;" json:"security_rules"`
 	SeedPeerClusters  []SeedPeerCluster  `json:"-"`
 	SchedulerClusters []SchedulerCluster `json:"-"`
+	TenantID          uint               `gorm:"comment:tenant id" json:"tenant_id"`
+	Tenant            Tenant             `json:"-"`
 }
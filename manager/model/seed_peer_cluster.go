@@ -29,5 +29,7 @@ type SeedPeerCluster struct {
 	Application       Application        `json:"-"`
 	SecurityGroupID   uint               `gorm:"comment:security group id" json:"security_group_id"`
 	SecurityGroup     SecurityGroup      `json:"-"`
+	TenantID          uint               `gorm:"comment:tenant id" json:"tenant_id"`
+	Tenant            Tenant             `json:"-"`
 	Jobs              []Job              `gorm:"many2many:job_seed_peer_cluster;" json:"jobs"`
 }
@@ -0,0 +1,28 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+type Tenant struct {
+	Model
+	Name              string             `gorm:"column:name;type:varchar(256);index:uk_tenant_name,unique;not null;comment:name" json:"name"`
+	BIO               string             `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	Users             []User             `gorm:"many2many:tenant_user;" json:"users"`
+	SchedulerClusters []SchedulerCluster `json:"-"`
+	SeedPeerClusters  []SeedPeerCluster  `json:"-"`
+	SecurityGroups    []SecurityGroup    `json:"-"`
+	Jobs              []Job              `json:"-"`
+}
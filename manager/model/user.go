@@ -21,6 +21,19 @@ const (
 	UserStateDisabled = "disable"
 )
 
+const (
+	// UserProviderLDAP is the provider of users signed in by ldap.
+	UserProviderLDAP = "ldap"
+
+	// UserProviderOauthPrefix is the prefix of provider of users signed in by oauth,
+	// it is followed by the name of oauth.
+	UserProviderOauthPrefix = "oauth:"
+
+	// UserProviderOIDCPrefix is the prefix of provider of users signed in by openid connect,
+	// it is followed by the issuer of identity provider.
+	UserProviderOIDCPrefix = "oidc:"
+)
+
 type User struct {
 	Model
 	Email             string   `gorm:"column:email;type:varchar(256);index:uk_user_email,unique;not null;comment:email address" json:"email"`
@@ -32,5 +45,7 @@ type User struct {
 	State             string   `gorm:"column:state;type:varchar(256);default:'enable';comment:state" json:"state"`
 	Location          string   `gorm:"column:location;type:varchar(256);comment:location" json:"location"`
 	BIO               string   `gorm:"column:bio;type:varchar(256);comment:biography" json:"bio"`
+	Provider          string   `gorm:"column:provider;type:varchar(256);index:idx_user_provider_subject;comment:identity provider, empty for user signed up with password" json:"-"`
+	Subject           string   `gorm:"column:subject;type:varchar(256);index:idx_user_provider_subject;comment:stable id of user in identity provider" json:"-"`
 	Configs           []Config `json:"-"`
 }
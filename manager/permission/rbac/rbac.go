@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
@@ -30,7 +31,7 @@ import (
 	"gorm.io/gorm"
 
 	managermodel "d7y.io/dragonfly/v2/manager/model"
-	"d7y.io/dragonfly/v2/pkg/strings"
+	pkgstrings "d7y.io/dragonfly/v2/pkg/strings"
 )
 
 // Syntax for models see https://casbin.org/docs/en/syntax-for-models
@@ -143,6 +144,14 @@ func GetPermissions(g *gin.Engine) []Permission {
 	return permissions
 }
 
+const (
+	// v1PreheatPath is the path of v1 preheat api.
+	v1PreheatPath = "/preheats"
+
+	// jobsAPIGroupName is the api group name of jobs.
+	jobsAPIGroupName = "jobs"
+)
+
 func GetAPIGroupNames(g *gin.Engine) []string {
 	apiGroupNames := []string{}
 	for _, route := range g.Routes() {
@@ -151,7 +160,7 @@ func GetAPIGroupNames(g *gin.Engine) []string {
 			continue
 		}
 
-		if !strings.Contains(apiGroupNames, name) {
+		if !pkgstrings.Contains(apiGroupNames, name) {
 			apiGroupNames = append(apiGroupNames, name)
 		}
 	}
@@ -160,6 +169,12 @@ func GetAPIGroupNames(g *gin.Engine) []string {
 }
 
 func GetAPIGroupName(path string) (string, error) {
+	// V1 preheat api is compatible with dragonfly v1,
+	// it is authorized by the permission of jobs.
+	if path == v1PreheatPath || strings.HasPrefix(path, v1PreheatPath+"/") {
+		return jobsAPIGroupName, nil
+	}
+
 	apiGroupRegexp := regexp.MustCompile(`^/api/v[0-9]+/([-_a-zA-Z]*)[/.*]*`)
 	matchs := apiGroupRegexp.FindStringSubmatch(path)
 	if len(matchs) != 2 {
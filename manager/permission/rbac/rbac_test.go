@@ -52,6 +52,14 @@ func TestGetApiGroupName(t *testing.T) {
 				assert.Equal(data, "users")
 			},
 		},
+		{
+			name: `path is /preheats/1`,
+			path: "/preheats/1",
+			expect: func(t *testing.T, data string, err error) {
+				assert := assert.New(t)
+				assert.Equal(data, "jobs")
+			},
+		},
 		{
 			name: `path is /api/user`,
 			path: "/api/user",
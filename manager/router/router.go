@@ -92,6 +92,12 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 		return nil, err
 	}
 
+	// Automation can access resources with personal access tokens instead of jwt.
+	auth := middlewares.PersonalAccessToken(service, jwt.MiddlewareFunc())
+
+	// Resources of tenants are limited to the members of tenants.
+	tenant := middlewares.Tenant(service)
+
 	// Manager view.
 	r.Use(static.Serve("/", assets))
 
@@ -108,6 +114,10 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	u.POST("signup", h.SignUp)
 	u.GET("signin/:name", h.OauthSignin)
 	u.GET("signin/:name/callback", h.OauthSigninCallback(jwt))
+	if cfg.Auth != nil && cfg.Auth.OIDC != nil && cfg.Auth.OIDC.Enable {
+		u.GET("signin/oidc", h.OIDCSignin)
+		u.GET("signin/oidc/callback", h.OIDCSigninCallback(jwt))
+	}
 	u.POST("refresh_token", jwt.RefreshHandler)
 	u.POST(":id/reset_password", h.ResetPassword)
 	u.GET(":id/roles", jwt.MiddlewareFunc(), rbac, h.GetRolesForUser)
@@ -127,6 +137,14 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	pm := apiv1.Group("/permissions", jwt.MiddlewareFunc(), rbac)
 	pm.GET("", h.GetPermissions(r))
 
+	// Personal Access Token, users can only access their own tokens except root.
+	pat := apiv1.Group("/personal-access-tokens", jwt.MiddlewareFunc())
+	pat.POST("", h.CreatePersonalAccessToken)
+	pat.DELETE(":id", h.DestroyPersonalAccessToken)
+	pat.PATCH(":id", h.UpdatePersonalAccessToken)
+	pat.GET(":id", h.GetPersonalAccessToken)
+	pat.GET("", h.GetPersonalAccessTokens)
+
 	// Oauth
 	oa := apiv1.Group("/oauth")
 	oa.POST("", jwt.MiddlewareFunc(), rbac, h.CreateOauth)
@@ -136,18 +154,22 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	oa.GET("", h.GetOauths)
 
 	// Scheduler Cluster
-	sc := apiv1.Group("/scheduler-clusters", jwt.MiddlewareFunc(), rbac)
+	sc := apiv1.Group("/scheduler-clusters", auth, rbac, tenant)
 	sc.POST("", h.CreateSchedulerCluster)
 	sc.DELETE(":id", h.DestroySchedulerCluster)
+	sc.POST(":id/restore", h.RestoreSchedulerCluster)
+	sc.DELETE(":id/purge", h.PurgeSchedulerCluster)
 	sc.PATCH(":id", h.UpdateSchedulerCluster)
 	sc.GET(":id", h.GetSchedulerCluster)
 	sc.GET("", h.GetSchedulerClusters)
 	sc.PUT(":id/schedulers/:scheduler_id", h.AddSchedulerToSchedulerCluster)
 
 	// Scheduler
-	s := apiv1.Group("/schedulers", jwt.MiddlewareFunc(), rbac)
+	s := apiv1.Group("/schedulers", auth, rbac, tenant)
 	s.POST("", h.CreateScheduler)
 	s.DELETE(":id", h.DestroyScheduler)
+	s.POST(":id/restore", h.RestoreScheduler)
+	s.DELETE(":id/purge", h.PurgeScheduler)
 	s.PATCH(":id", h.UpdateScheduler)
 	s.GET(":id", h.GetScheduler)
 	s.GET("", h.GetSchedulers)
@@ -167,7 +189,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	apiv1.GET("/schedulers/:id/models/:model_id/versions", h.GetModelVersions)
 
 	// Application
-	cs := apiv1.Group("/applications", jwt.MiddlewareFunc(), rbac)
+	cs := apiv1.Group("/applications", auth, rbac, tenant)
 	cs.POST("", h.CreateApplication)
 	cs.DELETE(":id", h.DestroyApplication)
 	cs.PATCH(":id", h.UpdateApplication)
@@ -178,10 +200,22 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	cs.PUT(":id/seed-peer-clusters/:seed_peer_cluster_id", h.AddSeedPeerClusterToApplication)
 	cs.DELETE(":id/seed-peer-clusters/:seed_peer_cluster_id", h.DeleteSeedPeerClusterToApplication)
 
+	// Tenant
+	tn := apiv1.Group("/tenants", auth, rbac, tenant)
+	tn.POST("", h.CreateTenant)
+	tn.DELETE(":id", h.DestroyTenant)
+	tn.PATCH(":id", h.UpdateTenant)
+	tn.GET(":id", h.GetTenant)
+	tn.GET("", h.GetTenants)
+	tn.PUT(":id/users/:user_id", h.AddUserToTenant)
+	tn.DELETE(":id/users/:user_id", h.DeleteUserToTenant)
+
 	// Seed Peer Cluster
-	spc := apiv1.Group("/seed-peer-clusters", jwt.MiddlewareFunc(), rbac)
+	spc := apiv1.Group("/seed-peer-clusters", auth, rbac, tenant)
 	spc.POST("", h.CreateSeedPeerCluster)
 	spc.DELETE(":id", h.DestroySeedPeerCluster)
+	spc.POST(":id/restore", h.RestoreSeedPeerCluster)
+	spc.DELETE(":id/purge", h.PurgeSeedPeerCluster)
 	spc.PATCH(":id", h.UpdateSeedPeerCluster)
 	spc.GET(":id", h.GetSeedPeerCluster)
 	spc.GET("", h.GetSeedPeerClusters)
@@ -189,23 +223,34 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	spc.PUT(":id/scheduler-clusters/:scheduler_cluster_id", h.AddSchedulerClusterToSeedPeerCluster)
 
 	// Seed Peer
-	sp := apiv1.Group("/seed-peers", jwt.MiddlewareFunc(), rbac)
+	sp := apiv1.Group("/seed-peers", auth, rbac, tenant)
 	sp.POST("", h.CreateSeedPeer)
 	sp.DELETE(":id", h.DestroySeedPeer)
+	sp.POST(":id/restore", h.RestoreSeedPeer)
+	sp.DELETE(":id/purge", h.PurgeSeedPeer)
 	sp.PATCH(":id", h.UpdateSeedPeer)
 	sp.GET(":id", h.GetSeedPeer)
 	sp.GET("", h.GetSeedPeers)
 
 	// Security Rule
-	sr := apiv1.Group("/security-rules", jwt.MiddlewareFunc(), rbac)
+	sr := apiv1.Group("/security-rules", auth, rbac)
 	sr.POST("", h.CreateSecurityRule)
 	sr.DELETE(":id", h.DestroySecurityRule)
 	sr.PATCH(":id", h.UpdateSecurityRule)
 	sr.GET(":id", h.GetSecurityRule)
 	sr.GET("", h.GetSecurityRules)
+	sr.GET("resolve", h.ResolveSecurityRule)
+
+	// Blocklist
+	bl := apiv1.Group("/blocklists", auth, rbac)
+	bl.POST("", h.CreateBlocklist)
+	bl.DELETE(":id", h.DestroyBlocklist)
+	bl.PATCH(":id", h.UpdateBlocklist)
+	bl.GET(":id", h.GetBlocklist)
+	bl.GET("", h.GetBlocklists)
 
 	// Security Group
-	sg := apiv1.Group("/security-groups", jwt.MiddlewareFunc(), rbac)
+	sg := apiv1.Group("/security-groups", auth, rbac, tenant)
 	sg.POST("", h.CreateSecurityGroup)
 	sg.DELETE(":id", h.DestroySecurityGroup)
 	sg.PATCH(":id", h.UpdateSecurityGroup)
@@ -217,7 +262,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	sg.DELETE(":id/security-rules/:security_rule_id", h.DestroySecurityRuleToSecurityGroup)
 
 	// Bucket
-	bucket := apiv1.Group("/buckets", jwt.MiddlewareFunc(), rbac)
+	bucket := apiv1.Group("/buckets", auth, rbac)
 	bucket.POST("", h.CreateBucket)
 	bucket.DELETE(":id", h.DestroyBucket)
 	bucket.GET(":id", h.GetBucket)
@@ -225,22 +270,37 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 
 	// Config
 	config := apiv1.Group("/configs")
-	config.POST("", jwt.MiddlewareFunc(), rbac, h.CreateConfig)
-	config.DELETE(":id", jwt.MiddlewareFunc(), rbac, h.DestroyConfig)
-	config.PATCH(":id", jwt.MiddlewareFunc(), rbac, h.UpdateConfig)
-	config.GET(":id", jwt.MiddlewareFunc(), rbac, h.GetConfig)
+	config.POST("", auth, rbac, h.CreateConfig)
+	config.DELETE(":id", auth, rbac, h.DestroyConfig)
+	config.PATCH(":id", auth, rbac, h.UpdateConfig)
+	config.GET(":id", auth, rbac, h.GetConfig)
 	config.GET("", h.GetConfigs)
 
 	// Job
-	job := apiv1.Group("/jobs")
+	job := apiv1.Group("/jobs", auth, rbac, tenant)
 	job.POST("", h.CreateJob)
 	job.DELETE(":id", h.DestroyJob)
 	job.PATCH(":id", h.UpdateJob)
 	job.GET(":id", h.GetJob)
 	job.GET("", h.GetJobs)
 
-	// Compatible with the V1 preheat.
-	pv1 := r.Group("/preheats")
+	// Queued Job
+	qj := apiv1.Group("/queued-jobs", auth, rbac, tenant)
+	qj.GET(":uuid", h.GetQueuedJob)
+	qj.GET("", h.GetQueuedJobs)
+	qj.POST(":uuid/retry", h.RetryQueuedJob)
+	qj.POST(":uuid/cancel", h.CancelQueuedJob)
+
+	// Task
+	task := apiv1.Group("/tasks", auth, rbac, tenant)
+	task.DELETE(":task_id", h.DestroyTask)
+
+	// Stats
+	stats := apiv1.Group("/stats", auth, rbac, tenant)
+	stats.GET("clusters/:id", h.GetClusterStats)
+
+	// Compatible with the V1 preheat, it is authorized as jobs.
+	pv1 := r.Group("/preheats", auth, rbac, tenant)
 	r.GET("_ping", h.GetHealth)
 	pv1.POST("", h.CreateV1Preheat)
 	pv1.GET(":id", h.GetV1Preheat)
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"strconv"
 
 	cachev8 "github.com/go-redis/cache/v8"
 	"github.com/go-redis/redis/v8"
@@ -47,6 +48,7 @@ import (
 	"d7y.io/dragonfly/v2/manager/searcher"
 	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/objectstorage"
+	"d7y.io/dragonfly/v2/pkg/rpc"
 )
 
 // Default middlewares for stream.
@@ -93,7 +95,7 @@ type Server struct {
 func New(
 	cfg *config.Config, database *database.Database, cache *cache.Cache, searcher searcher.Searcher,
 	objectStorage objectstorage.ObjectStorage, objectStorageConfig *config.ObjectStorageConfig, opts ...grpc.ServerOption,
-) *grpc.Server {
+) (*grpc.Server, error) {
 	server := &Server{
 		config:              cfg,
 		db:                  database.DB,
@@ -104,6 +106,20 @@ func New(
 		objectStorageConfig: objectStorageConfig,
 	}
 
+	// Serve with tls, certificates are reloaded when files are rotated.
+	if tlsConfig := cfg.Server.GRPC.TLS; tlsConfig != nil {
+		certReloader, err := rpc.NewCertReloader(tlsConfig.Cert, tlsConfig.Key, tlsConfig.CA, tlsConfig.ReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+
+		creds, err := certReloader.ServerCredentials()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
 	grpcServer := grpc.NewServer(append([]grpc.ServerOption{
 		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
 		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()),
@@ -114,7 +130,7 @@ func New(
 	// Register servers on grpc server.
 	managerv1.RegisterManagerServer(grpcServer, server)
 	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
-	return grpcServer
+	return grpcServer, nil
 }
 
 // Get SeedPeer and SeedPeer cluster configuration.
@@ -202,7 +218,7 @@ func (s *Server) GetSeedPeer(ctx context.Context, req *managerv1.GetSeedPeerRequ
 // Update SeedPeer configuration.
 func (s *Server) UpdateSeedPeer(ctx context.Context, req *managerv1.UpdateSeedPeerRequest) (*managerv1.SeedPeer, error) {
 	seedPeer := model.SeedPeer{}
-	if err := s.db.WithContext(ctx).First(&seedPeer, model.SeedPeer{
+	if err := s.db.WithContext(ctx).Unscoped().First(&seedPeer, model.SeedPeer{
 		HostName:          req.HostName,
 		SeedPeerClusterID: uint(req.SeedPeerClusterId),
 	}).Error; err != nil {
@@ -212,6 +228,21 @@ func (s *Server) UpdateSeedPeer(ctx context.Context, req *managerv1.UpdateSeedPe
 		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
+	// Soft deleted seed peer is restored when it registers again,
+	// but it can not be restored into the deleted seed peer cluster.
+	if seedPeer.IsDel != 0 {
+		if err := s.db.WithContext(ctx).First(&model.SeedPeerCluster{}, seedPeer.SeedPeerClusterID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, status.Errorf(codes.FailedPrecondition, "seed peer cluster %d is deleted", seedPeer.SeedPeerClusterID)
+			}
+			return nil, status.Error(codes.Unknown, err.Error())
+		}
+
+		if err := s.db.WithContext(ctx).Unscoped().Model(&seedPeer).Update("is_del", 0).Error; err != nil {
+			return nil, status.Error(codes.Unknown, err.Error())
+		}
+	}
+
 	if err := s.db.WithContext(ctx).Model(&seedPeer).Updates(model.SeedPeer{
 		Type:              req.Type,
 		IDC:               req.Idc,
@@ -298,7 +329,7 @@ func (s *Server) GetScheduler(ctx context.Context, req *managerv1.GetSchedulerRe
 	// Cache miss.
 	logger.Infof("%s cache miss", cacheKey)
 	scheduler := model.Scheduler{}
-	if err := s.db.WithContext(ctx).Preload("SchedulerCluster").Preload("SchedulerCluster.SeedPeerClusters.SeedPeers", &model.SeedPeer{
+	if err := s.db.WithContext(ctx).Preload("SchedulerCluster").Preload("SchedulerCluster.Blocklists").Preload("SchedulerCluster.SeedPeerClusters.SeedPeers", &model.SeedPeer{
 		State: model.SeedPeerStateActive,
 	}).First(&scheduler, &model.Scheduler{
 		HostName:           req.HostName,
@@ -307,8 +338,8 @@ func (s *Server) GetScheduler(ctx context.Context, req *managerv1.GetSchedulerRe
 		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
-	// Marshal config of scheduler.
-	schedulerClusterConfig, err := scheduler.SchedulerCluster.Config.MarshalJSON()
+	// Marshal config of scheduler, features and blocklist are delivered with the config.
+	schedulerClusterConfig, err := marshalSchedulerClusterConfig(&scheduler.SchedulerCluster)
 	if err != nil {
 		return nil, status.Error(codes.DataLoss, err.Error())
 	}
@@ -388,7 +419,7 @@ func (s *Server) GetScheduler(ctx context.Context, req *managerv1.GetSchedulerRe
 // Update scheduler configuration.
 func (s *Server) UpdateScheduler(ctx context.Context, req *managerv1.UpdateSchedulerRequest) (*managerv1.Scheduler, error) {
 	scheduler := model.Scheduler{}
-	if err := s.db.WithContext(ctx).First(&scheduler, model.Scheduler{
+	if err := s.db.WithContext(ctx).Unscoped().First(&scheduler, model.Scheduler{
 		HostName:           req.HostName,
 		SchedulerClusterID: uint(req.SchedulerClusterId),
 	}).Error; err != nil {
@@ -398,6 +429,21 @@ func (s *Server) UpdateScheduler(ctx context.Context, req *managerv1.UpdateSched
 		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
+	// Soft deleted scheduler is restored when it registers again,
+	// but it can not be restored into the deleted scheduler cluster.
+	if scheduler.IsDel != 0 {
+		if err := s.db.WithContext(ctx).First(&model.SchedulerCluster{}, scheduler.SchedulerClusterID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, status.Errorf(codes.FailedPrecondition, "scheduler cluster %d is deleted", scheduler.SchedulerClusterID)
+			}
+			return nil, status.Error(codes.Unknown, err.Error())
+		}
+
+		if err := s.db.WithContext(ctx).Unscoped().Model(&scheduler).Update("is_del", 0).Error; err != nil {
+			return nil, status.Error(codes.Unknown, err.Error())
+		}
+	}
+
 	if err := s.db.WithContext(ctx).Model(&scheduler).Updates(model.Scheduler{
 		IDC:                req.Idc,
 		NetTopology:        req.NetTopology,
@@ -478,6 +524,7 @@ func (s *Server) ListSchedulers(ctx context.Context, req *managerv1.ListSchedule
 	// Cache hit.
 	if err := s.cache.Get(ctx, cacheKey, &pbListSchedulersResponse); err == nil {
 		log.Infof("%s cache hit", cacheKey)
+		s.storeClusterPeer(ctx, req, &pbListSchedulersResponse)
 		return &pbListSchedulersResponse, nil
 	}
 
@@ -493,8 +540,10 @@ func (s *Server) ListSchedulers(ctx context.Context, req *managerv1.ListSchedule
 	schedulerClusters, err := s.searcher.FindSchedulerClusters(ctx, schedulerClusters, req)
 	if err != nil {
 		log.Error(err)
+		metrics.SearchSchedulerClusterCount.WithLabelValues(metrics.SearchResultMiss).Inc()
 		return nil, status.Error(codes.NotFound, "scheduler cluster not found")
 	}
+	metrics.SearchSchedulerClusterCount.WithLabelValues(metrics.SearchResultHit).Inc()
 	log.Infof("find matching scheduler cluster %v", getSchedulerClusterNames(schedulerClusters))
 
 	schedulers := []model.Scheduler{}
@@ -551,9 +600,28 @@ func (s *Server) ListSchedulers(ctx context.Context, req *managerv1.ListSchedule
 		log.Warnf("storage cache failed: %v", err)
 	}
 
+	s.storeClusterPeer(ctx, req, &pbListSchedulersResponse)
 	return &pbListSchedulersResponse, nil
 }
 
+// Store the active peer in the scheduler cluster of the first scheduler,
+// the number of active peers in scheduler cluster is collected by metrics.
+func (s *Server) storeClusterPeer(ctx context.Context, req *managerv1.ListSchedulersRequest, resp *managerv1.ListSchedulersResponse) {
+	if !s.config.Metrics.EnablePeerGauge || req.SourceType != managerv1.SourceType_PEER_SOURCE || len(resp.Schedulers) == 0 {
+		return
+	}
+
+	clusterID := uint(resp.Schedulers[0].SchedulerClusterId)
+	cacheKey := cache.MakeClusterPeerCacheKey(clusterID, req.HostName, req.Ip)
+	if err := s.rdb.Set(ctx, cacheKey, types.Peer{
+		ID:       cacheKey,
+		Hostname: req.HostName,
+		IP:       req.Ip,
+	}, cache.PeerCacheTTL).Err(); err != nil {
+		logger.WithHostnameAndIP(req.HostName, req.Ip).Warnf("store peer of scheduler cluster %d failed: %s", clusterID, err.Error())
+	}
+}
+
 // Get the number of active peers
 func (s *Server) getPeerCount(ctx context.Context, req *managerv1.ListSchedulersRequest) (int, error) {
 	cacheKey := cache.MakePeerCacheKey(req.HostName, req.Ip)
@@ -740,6 +808,7 @@ func (s *Server) KeepAlive(stream managerv1.Manager_KeepAliveServer) error {
 				return nil
 			}
 
+			metrics.KeepAliveFailureCount.WithLabelValues(sourceType.String(), strconv.FormatUint(uint64(clusterID), 10)).Inc()
 			logger.Errorf("%s keepalive failed in cluster %d: %v", hostName, clusterID, err)
 			return status.Error(codes.Unknown, err.Error())
 		}
@@ -757,3 +826,40 @@ func getSchedulerClusterNames(clusters []model.SchedulerCluster) []string {
 
 	return names
 }
+
+const (
+	// schedulerClusterFeaturesKey is the key of features in the config of scheduler cluster.
+	schedulerClusterFeaturesKey = "features"
+
+	// schedulerClusterBlocklistKey is the key of blocklist in the config of scheduler cluster.
+	schedulerClusterBlocklistKey = "blocklist"
+)
+
+// marshalSchedulerClusterConfig marshals the config of scheduler cluster with features and blocklist.
+func marshalSchedulerClusterConfig(schedulerCluster *model.SchedulerCluster) ([]byte, error) {
+	if len(schedulerCluster.Features) == 0 && len(schedulerCluster.Blocklists) == 0 {
+		return schedulerCluster.Config.MarshalJSON()
+	}
+
+	config := model.JSONMap{}
+	for k, v := range schedulerCluster.Config {
+		config[k] = v
+	}
+
+	if len(schedulerCluster.Features) > 0 {
+		config[schedulerClusterFeaturesKey] = schedulerCluster.Features
+	}
+
+	if len(schedulerCluster.Blocklists) > 0 {
+		var blocklist types.Blocklist
+		for _, b := range schedulerCluster.Blocklists {
+			blocklist = append(blocklist, types.BlocklistRule{
+				Type:    b.Type,
+				Pattern: b.Pattern,
+			})
+		}
+		config[schedulerClusterBlocklistKey] = blocklist
+	}
+
+	return config.MarshalJSON()
+}
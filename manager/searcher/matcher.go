@@ -0,0 +1,76 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/matcher_mock.go -source matcher.go -package mocks
+
+package searcher
+
+import (
+	"sync"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	"d7y.io/dragonfly/v2/manager/model"
+)
+
+// Matcher evaluates the affinity between dfdaemon and scheduler cluster,
+// operators can compile in a custom matcher with RegisterMatcher,
+// and its weighted score is added to the score of default searcher.
+type Matcher interface {
+	// Match returns the affinity score 0.0~1.0, larger and better.
+	Match(*managerv1.ListSchedulersRequest, model.SchedulerCluster) float64
+}
+
+// weightedMatcher is the matcher with weight.
+type weightedMatcher struct {
+	matcher Matcher
+	weight  float64
+}
+
+var (
+	matchersMu sync.RWMutex
+	matchers   = make(map[string]weightedMatcher)
+)
+
+// RegisterMatcher registers the matcher with name and weight,
+// the matcher with the same name is replaced.
+func RegisterMatcher(name string, weight float64, matcher Matcher) {
+	matchersMu.Lock()
+	defer matchersMu.Unlock()
+
+	matchers[name] = weightedMatcher{matcher: matcher, weight: weight}
+}
+
+// UnregisterMatcher unregisters the matcher with name.
+func UnregisterMatcher(name string) {
+	matchersMu.Lock()
+	defer matchersMu.Unlock()
+
+	delete(matchers, name)
+}
+
+// evaluateMatchers returns the sum of weighted scores of registered matchers.
+func evaluateMatchers(req *managerv1.ListSchedulersRequest, schedulerCluster model.SchedulerCluster) float64 {
+	matchersMu.RLock()
+	defer matchersMu.RUnlock()
+
+	var score float64
+	for _, m := range matchers {
+		score += m.weight * m.matcher.Match(req, schedulerCluster)
+	}
+
+	return score
+}
@@ -22,6 +22,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"sort"
 	"strings"
 
@@ -49,6 +50,10 @@ const (
 )
 
 const (
+	// CIDR affinity weight, it is larger than the weight of any other scope,
+	// so the cluster whose cidrs contain the ip of dfdaemon is preferred
+	cidrAffinityWeight float64 = 0.5
+
 	// SecurityDomain affinity weight
 	securityDomainAffinityWeight float64 = 0.4
 
@@ -73,13 +78,20 @@ const (
 const (
 	// Maximum number of elements
 	maxElementLen = 5
+
+	// Separator of idc hierarchy, e.g. region/zone/idc
+	idcHierarchySeparator = "/"
 )
 
 // Scheduler cluster scopes
 type Scopes struct {
-	IDC         string `mapstructure:"idc"`
-	Location    string `mapstructure:"location"`
-	NetTopology string `mapstructure:"net_topology"`
+	// IDC is a list of idc separated by "|", and idc can be a hierarchical
+	// path separated by "/", e.g. region-1/zone-1|region-2,
+	// the ancestor matches all idcs in its subtree.
+	IDC         string   `mapstructure:"idc"`
+	Location    string   `mapstructure:"location"`
+	NetTopology string   `mapstructure:"net_topology"`
+	CIDRs       []string `mapstructure:"cidrs"`
 }
 
 type Searcher interface {
@@ -111,32 +123,54 @@ func (s *searcher) FindSchedulerClusters(ctx context.Context, schedulerClusters
 		return nil, errors.New("empty scheduler clusters")
 	}
 
+	// Resolve security domain of dfdaemon by security rules, then the dfdaemon
+	// can only use the scheduler clusters in the same security domain.
+	securityDomain := ResolveSecurityDomain(client.HostName, conditions[ConditionSecurityDomain], SecurityRulesOfSchedulerClusters(schedulerClusters))
+	if securityDomain != conditions[ConditionSecurityDomain] {
+		resolved := make(map[string]string, len(conditions)+1)
+		for k, v := range conditions {
+			resolved[k] = v
+		}
+		resolved[ConditionSecurityDomain] = securityDomain
+		conditions = resolved
+	}
+
 	clusters := FilterSchedulerClusters(conditions, schedulerClusters)
 	if len(clusters) == 0 {
 		return nil, fmt.Errorf("conditions %#v does not match any scheduler cluster", conditions)
 	}
 
-	sort.Slice(
-		clusters,
-		func(i, j int) bool {
-			var si, sj Scopes
-			if err := mapstructure.Decode(clusters[i].Scopes, &si); err != nil {
-				logger.Errorf("cluster %s decode scopes failed: %v", clusters[i].Name, err)
-				return false
-			}
-
-			if err := mapstructure.Decode(clusters[j].Scopes, &sj); err != nil {
-				logger.Errorf("cluster %s decode scopes failed: %v", clusters[i].Name, err)
-				return false
-			}
+	scores := make([]float64, len(clusters))
+	for i, cluster := range clusters {
+		var scopes Scopes
+		if err := mapstructure.Decode(cluster.Scopes, &scopes); err != nil {
+			logger.Errorf("cluster %s decode scopes failed: %v", cluster.Name, err)
+			continue
+		}
 
-			return Evaluate(conditions, si, clusters[i].SecurityGroup.SecurityRules) > Evaluate(conditions, sj, clusters[j].SecurityGroup.SecurityRules)
-		},
-	)
+		scores[i] = Evaluate(client.Ip, conditions, scopes, cluster.SecurityGroup.SecurityRules) +
+			evaluateMatchers(client, cluster)
+	}
 
+	sort.Stable(&scoredSchedulerClusters{clusters, scores})
 	return clusters, nil
 }
 
+// scoredSchedulerClusters sorts scheduler clusters by scores in descending order.
+type scoredSchedulerClusters struct {
+	clusters []model.SchedulerCluster
+	scores   []float64
+}
+
+func (s *scoredSchedulerClusters) Len() int { return len(s.clusters) }
+
+func (s *scoredSchedulerClusters) Less(i, j int) bool { return s.scores[i] > s.scores[j] }
+
+func (s *scoredSchedulerClusters) Swap(i, j int) {
+	s.clusters[i], s.clusters[j] = s.clusters[j], s.clusters[i]
+	s.scores[i], s.scores[j] = s.scores[j], s.scores[i]
+}
+
 // Filter the scheduler clusters that dfdaemon can be used
 func FilterSchedulerClusters(conditions map[string]string, schedulerClusters []model.SchedulerCluster) []model.SchedulerCluster {
 	var clusters []model.SchedulerCluster
@@ -147,31 +181,8 @@ func FilterSchedulerClusters(conditions map[string]string, schedulerClusters []m
 			continue
 		}
 
-		// Dfdaemon security_domain does not exist, matching all scheduler clusters
-		if securityDomain == "" {
+		if MatchSecurityDomain(securityDomain, schedulerCluster) {
 			clusters = append(clusters, schedulerCluster)
-			continue
-		}
-
-		// Scheduler cluster is default, matching all dfdaemons
-		if schedulerCluster.IsDefault {
-			clusters = append(clusters, schedulerCluster)
-			continue
-		}
-
-		// Scheduler cluster SecurityRules does not exist, matching all dfdaemons
-		if len(schedulerCluster.SecurityGroup.SecurityRules) == 0 {
-			clusters = append(clusters, schedulerCluster)
-			continue
-		}
-
-		// If security_domain exists for dfdaemon and
-		// scheduler cluster SecurityRules also exists,
-		// then security_domain and SecurityRules are equal to match.
-		for _, securityRule := range schedulerCluster.SecurityGroup.SecurityRules {
-			if strings.EqualFold(securityRule.Domain, securityDomain) {
-				clusters = append(clusters, schedulerCluster)
-			}
 		}
 	}
 
@@ -179,13 +190,40 @@ func FilterSchedulerClusters(conditions map[string]string, schedulerClusters []m
 }
 
 // Evaluate the degree of matching between scheduler cluster and dfdaemon
-func Evaluate(conditions map[string]string, scopes Scopes, securityRules []model.SecurityRule) float64 {
-	return securityDomainAffinityWeight*calculateSecurityDomainAffinityScore(conditions[ConditionSecurityDomain], securityRules) +
+func Evaluate(ip string, conditions map[string]string, scopes Scopes, securityRules []model.SecurityRule) float64 {
+	return cidrAffinityWeight*calculateCIDRAffinityScore(ip, scopes.CIDRs) +
+		securityDomainAffinityWeight*calculateSecurityDomainAffinityScore(conditions[ConditionSecurityDomain], securityRules) +
 		idcAffinityWeight*calculateIDCAffinityScore(conditions[ConditionIDC], scopes.IDC) +
 		locationAffinityWeight*calculateMultiElementAffinityScore(conditions[ConditionLocation], scopes.Location) +
 		netTopologyAffinityWeight*calculateMultiElementAffinityScore(conditions[ConditionNetTopology], scopes.NetTopology)
 }
 
+// calculateCIDRAffinityScore 0.0~1.0 larger and better
+func calculateCIDRAffinityScore(ip string, cidrs []string) float64 {
+	if ip == "" || len(cidrs) == 0 {
+		return minScore
+	}
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return minScore
+	}
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logger.Warnf("parse cidr %s failed: %v", cidr, err)
+			continue
+		}
+
+		if ipNet.Contains(parsedIP) {
+			return maxScore
+		}
+	}
+
+	return minScore
+}
+
 // calculateSecurityDomainAffinityScore 0.0~1.0 larger and better
 func calculateSecurityDomainAffinityScore(securityDomain string, securityRules []model.SecurityRule) float64 {
 	if securityDomain == "" {
@@ -213,14 +251,31 @@ func calculateIDCAffinityScore(dst, src string) float64 {
 	// Dst has only one element, src has multiple elements separated by "|".
 	// When dst element matches one of the multiple elements of src,
 	// it gets the max score of idc.
+	// When src element is an ancestor of dst element in idc hierarchy,
+	// it gets the score of the depth ratio, the deeper element is preferred.
+	var score float64
+	dstNodes := strings.Split(dst, idcHierarchySeparator)
 	srcElements := strings.Split(src, "|")
 	for _, srcElement := range srcElements {
 		if strings.EqualFold(dst, srcElement) {
 			return maxScore
 		}
+
+		srcNodes := strings.Split(srcElement, idcHierarchySeparator)
+		if len(srcNodes) >= len(dstNodes) {
+			continue
+		}
+
+		if !strings.EqualFold(strings.Join(dstNodes[:len(srcNodes)], idcHierarchySeparator), srcElement) {
+			continue
+		}
+
+		if s := float64(len(srcNodes)) / float64(len(dstNodes)); s > score {
+			score = s
+		}
 	}
 
-	return minScore
+	return score
 }
 
 // calculateMultiElementAffinityScore 0.0~1.0 larger and better
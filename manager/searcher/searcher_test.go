@@ -726,6 +726,92 @@ func TestSchedulerCluster(t *testing.T) {
 				assert.Equal(len(data), 4)
 			},
 		},
+		{
+			name: "match according to cidrs",
+			schedulerClusters: []model.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"idc":   "idc-1",
+						"cidrs": []any{"10.0.0.0/8"},
+					},
+					Schedulers: []model.Scheduler{
+						{
+							HostName: "foo",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "bar",
+					Scopes: map[string]any{
+						"cidrs": []any{"foo", "127.0.0.0/8"},
+					},
+					Schedulers: []model.Scheduler{
+						{
+							HostName: "bar",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{"idc": "idc-1"},
+			expect: func(t *testing.T, data []model.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.Equal(data[0].Name, "bar")
+				assert.Equal(data[1].Name, "foo")
+				assert.Equal(len(data), 2)
+			},
+		},
+		{
+			name: "match according to idc hierarchy",
+			schedulerClusters: []model.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"idc": "region-1",
+					},
+					Schedulers: []model.Scheduler{
+						{
+							HostName: "foo",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "bar",
+					Scopes: map[string]any{
+						"idc": "region-2|region-1/zone-1",
+					},
+					Schedulers: []model.Scheduler{
+						{
+							HostName: "bar",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "baz",
+					Scopes: map[string]any{
+						"idc": "region-2",
+					},
+					Schedulers: []model.Scheduler{
+						{
+							HostName: "baz",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{"idc": "region-1/zone-1/idc-1"},
+			expect: func(t *testing.T, data []model.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.Equal(data[0].Name, "bar")
+				assert.Equal(data[1].Name, "foo")
+				assert.Equal(data[2].Name, "baz")
+				assert.Equal(len(data), 3)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -740,3 +826,52 @@ func TestSchedulerCluster(t *testing.T) {
 		})
 	}
 }
+
+type mockMatcher struct {
+	name string
+}
+
+func (m *mockMatcher) Match(req *managerv1.ListSchedulersRequest, schedulerCluster model.SchedulerCluster) float64 {
+	if schedulerCluster.Name == m.name {
+		return 1
+	}
+
+	return 0
+}
+
+func TestRegisterMatcher(t *testing.T) {
+	assert := assert.New(t)
+	RegisterMatcher("mock", 1, &mockMatcher{name: "bar"})
+	defer UnregisterMatcher("mock")
+
+	clusters, err := New(".").FindSchedulerClusters(context.Background(), []model.SchedulerCluster{
+		{
+			Name: "foo",
+			Scopes: map[string]any{
+				"idc": "idc-1",
+			},
+			Schedulers: []model.Scheduler{
+				{
+					HostName: "foo",
+					State:    "active",
+				},
+			},
+		},
+		{
+			Name: "bar",
+			Schedulers: []model.Scheduler{
+				{
+					HostName: "bar",
+					State:    "active",
+				},
+			},
+		},
+	}, &managerv1.ListSchedulersRequest{
+		HostName: "foo",
+		Ip:       "127.0.0.1",
+		HostInfo: map[string]string{"idc": "idc-1"},
+	})
+	assert.Nil(err)
+	assert.Equal(clusters[0].Name, "bar")
+	assert.Equal(clusters[1].Name, "foo")
+}
@@ -0,0 +1,121 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package searcher
+
+import (
+	"strings"
+
+	"d7y.io/dragonfly/v2/manager/model"
+)
+
+// ResolveSecurityRule resolves the security rule of the host.
+// When the host reports security domain, it matches the rule with the same domain.
+// Otherwise the hostname matches the rule whose domain is the dns suffix of hostname,
+// e.g. domain example.com matches hostname foo.example.com, and the longest domain wins.
+func ResolveSecurityRule(hostname, securityDomain string, securityRules []model.SecurityRule) (*model.SecurityRule, bool) {
+	if securityDomain != "" {
+		for i := range securityRules {
+			if strings.EqualFold(securityRules[i].Domain, securityDomain) {
+				return &securityRules[i], true
+			}
+		}
+
+		return nil, false
+	}
+
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if hostname == "" {
+		return nil, false
+	}
+
+	var matched *model.SecurityRule
+	for i := range securityRules {
+		domain := strings.TrimSuffix(strings.ToLower(securityRules[i].Domain), ".")
+		if domain == "" {
+			continue
+		}
+
+		if hostname != domain && !strings.HasSuffix(hostname, "."+domain) {
+			continue
+		}
+
+		if matched == nil || len(domain) > len(matched.Domain) {
+			matched = &securityRules[i]
+		}
+	}
+
+	return matched, matched != nil
+}
+
+// ResolveSecurityDomain returns the security domain of the host, the reported
+// security domain is kept when no security rule matches.
+func ResolveSecurityDomain(hostname, securityDomain string, securityRules []model.SecurityRule) string {
+	if securityRule, ok := ResolveSecurityRule(hostname, securityDomain, securityRules); ok {
+		return securityRule.Domain
+	}
+
+	return securityDomain
+}
+
+// MatchSecurityDomain returns whether the host in security domain can use the scheduler cluster.
+func MatchSecurityDomain(securityDomain string, schedulerCluster model.SchedulerCluster) bool {
+	// Dfdaemon security_domain does not exist, matching all scheduler clusters
+	if securityDomain == "" {
+		return true
+	}
+
+	// Scheduler cluster is default, matching all dfdaemons
+	if schedulerCluster.IsDefault {
+		return true
+	}
+
+	// Scheduler cluster SecurityRules does not exist, matching all dfdaemons
+	if len(schedulerCluster.SecurityGroup.SecurityRules) == 0 {
+		return true
+	}
+
+	// If security_domain exists for dfdaemon and
+	// scheduler cluster SecurityRules also exists,
+	// then security_domain and SecurityRules are equal to match.
+	for _, securityRule := range schedulerCluster.SecurityGroup.SecurityRules {
+		if strings.EqualFold(securityRule.Domain, securityDomain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SecurityRulesOfSchedulerClusters returns the distinct security rules of scheduler clusters.
+func SecurityRulesOfSchedulerClusters(schedulerClusters []model.SchedulerCluster) []model.SecurityRule {
+	var (
+		securityRules []model.SecurityRule
+		visited       = map[uint]struct{}{}
+	)
+	for _, schedulerCluster := range schedulerClusters {
+		for _, securityRule := range schedulerCluster.SecurityGroup.SecurityRules {
+			if _, ok := visited[securityRule.ID]; ok {
+				continue
+			}
+
+			visited[securityRule.ID] = struct{}{}
+			securityRules = append(securityRules, securityRule)
+		}
+	}
+
+	return securityRules
+}
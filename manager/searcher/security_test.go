@@ -0,0 +1,175 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package searcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+
+	"d7y.io/dragonfly/v2/manager/model"
+)
+
+func TestResolveSecurityRule(t *testing.T) {
+	securityRules := []model.SecurityRule{
+		{Model: model.Model{ID: 1}, Name: "foo", Domain: "example.com"},
+		{Model: model.Model{ID: 2}, Name: "bar", Domain: "bar.example.com"},
+		{Model: model.Model{ID: 3}, Name: "baz", Domain: "domain-1"},
+	}
+
+	tests := []struct {
+		name           string
+		hostname       string
+		securityDomain string
+		expect         func(t *testing.T, securityRule *model.SecurityRule, ok bool)
+	}{
+		{
+			name:           "reported security domain matches rule",
+			hostname:       "foo.example.com",
+			securityDomain: "Domain-1",
+			expect: func(t *testing.T, securityRule *model.SecurityRule, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal("baz", securityRule.Name)
+			},
+		},
+		{
+			name:           "reported security domain does not match rule",
+			hostname:       "foo.example.com",
+			securityDomain: "domain-2",
+			expect: func(t *testing.T, securityRule *model.SecurityRule, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Nil(securityRule)
+			},
+		},
+		{
+			name:     "hostname matches domain suffix",
+			hostname: "foo.example.com",
+			expect: func(t *testing.T, securityRule *model.SecurityRule, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal("foo", securityRule.Name)
+			},
+		},
+		{
+			name:     "hostname matches the longest domain",
+			hostname: "Foo.Bar.Example.com.",
+			expect: func(t *testing.T, securityRule *model.SecurityRule, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal("bar", securityRule.Name)
+			},
+		},
+		{
+			name:     "hostname does not match partial label",
+			hostname: "fooexample.com",
+			expect: func(t *testing.T, securityRule *model.SecurityRule, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+		{
+			name: "hostname is empty",
+			expect: func(t *testing.T, securityRule *model.SecurityRule, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			securityRule, ok := ResolveSecurityRule(tc.hostname, tc.securityDomain, securityRules)
+			tc.expect(t, securityRule, ok)
+		})
+	}
+}
+
+func TestFindSchedulerClustersWithSecurityRules(t *testing.T) {
+	schedulerClusters := []model.SchedulerCluster{
+		{
+			Name: "foo",
+			SecurityGroup: model.SecurityGroup{
+				SecurityRules: []model.SecurityRule{{Model: model.Model{ID: 1}, Domain: "foo.com"}},
+			},
+			Schedulers: []model.Scheduler{{HostName: "foo", State: "active"}},
+		},
+		{
+			Name: "bar",
+			SecurityGroup: model.SecurityGroup{
+				SecurityRules: []model.SecurityRule{{Model: model.Model{ID: 2}, Domain: "bar.com"}},
+			},
+			Schedulers: []model.Scheduler{{HostName: "bar", State: "active"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		hostInfo map[string]string
+		expect   func(t *testing.T, data []model.SchedulerCluster, err error)
+	}{
+		{
+			name:     "security domain is resolved by hostname",
+			hostname: "host-1.bar.com",
+			hostInfo: map[string]string{"idc": "foo"},
+			expect: func(t *testing.T, data []model.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(data, 1)
+				assert.Equal("bar", data[0].Name)
+			},
+		},
+		{
+			name:     "reported security domain takes precedence",
+			hostname: "host-1.bar.com",
+			hostInfo: map[string]string{"security_domain": "foo.com"},
+			expect: func(t *testing.T, data []model.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(data, 1)
+				assert.Equal("foo", data[0].Name)
+			},
+		},
+		{
+			name:     "security domain is not resolved",
+			hostname: "host-1.baz.com",
+			hostInfo: map[string]string{"idc": "foo"},
+			expect: func(t *testing.T, data []model.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(data, 2)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			searcher := New(".")
+			clusters, err := searcher.FindSchedulerClusters(context.Background(), schedulerClusters, &managerv1.ListSchedulersRequest{
+				HostName: tc.hostname,
+				Ip:       "127.0.0.1",
+				HostInfo: tc.hostInfo,
+			})
+			tc.expect(t, clusters, err)
+		})
+	}
+}
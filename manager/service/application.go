@@ -95,7 +95,7 @@ func (s *service) AddSchedulerClusterToApplication(ctx context.Context, id, sche
 	}
 
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
 		return err
 	}
 
@@ -113,7 +113,7 @@ func (s *service) DeleteSchedulerClusterToApplication(ctx context.Context, id, s
 	}
 
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
 		return err
 	}
 
@@ -131,7 +131,7 @@ func (s *service) AddSeedPeerClusterToApplication(ctx context.Context, id, seedP
 	}
 
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, seedPeerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, seedPeerClusterID).Error; err != nil {
 		return err
 	}
 
@@ -149,7 +149,7 @@ func (s *service) DeleteSeedPeerClusterToApplication(ctx context.Context, id, se
 	}
 
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, seedPeerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, seedPeerClusterID).Error; err != nil {
 		return err
 	}
 
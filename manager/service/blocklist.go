@@ -0,0 +1,128 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/internal/dferrors"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func (s *service) CreateBlocklist(ctx context.Context, json types.CreateBlocklistRequest) (*model.Blocklist, error) {
+	if err := validateBlocklistPattern(json.Type, json.Pattern); err != nil {
+		return nil, err
+	}
+
+	blocklist := model.Blocklist{
+		Type:               json.Type,
+		Pattern:            json.Pattern,
+		BIO:                json.BIO,
+		SchedulerClusterID: json.SchedulerClusterID,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&blocklist).Error; err != nil {
+		return nil, err
+	}
+
+	// Schedulers of the cluster refresh blocklist from manager.
+	s.refreshSchedulersCache(ctx, blocklist.SchedulerClusterID)
+
+	return &blocklist, nil
+}
+
+func (s *service) DestroyBlocklist(ctx context.Context, id uint) error {
+	blocklist := model.Blocklist{}
+	if err := s.db.WithContext(ctx).First(&blocklist, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&model.Blocklist{}, id).Error; err != nil {
+		return err
+	}
+
+	s.refreshSchedulersCache(ctx, blocklist.SchedulerClusterID)
+	return nil
+}
+
+func (s *service) UpdateBlocklist(ctx context.Context, id uint, json types.UpdateBlocklistRequest) (*model.Blocklist, error) {
+	blocklist := model.Blocklist{}
+	if err := s.db.WithContext(ctx).First(&blocklist, id).Error; err != nil {
+		return nil, err
+	}
+
+	// Validate the pattern with the type after updated.
+	if json.Type != "" || json.Pattern != "" {
+		blocklistType, pattern := blocklist.Type, blocklist.Pattern
+		if json.Type != "" {
+			blocklistType = json.Type
+		}
+
+		if json.Pattern != "" {
+			pattern = json.Pattern
+		}
+
+		if err := validateBlocklistPattern(blocklistType, pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Model(&blocklist).Updates(model.Blocklist{
+		Type:    json.Type,
+		Pattern: json.Pattern,
+		BIO:     json.BIO,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	s.refreshSchedulersCache(ctx, blocklist.SchedulerClusterID)
+	return &blocklist, nil
+}
+
+func (s *service) GetBlocklist(ctx context.Context, id uint) (*model.Blocklist, error) {
+	blocklist := model.Blocklist{}
+	if err := s.db.WithContext(ctx).First(&blocklist, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &blocklist, nil
+}
+
+func (s *service) GetBlocklists(ctx context.Context, q types.GetBlocklistsQuery) ([]model.Blocklist, int64, error) {
+	var count int64
+	var blocklists []model.Blocklist
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.Blocklist{
+		Type:               q.Type,
+		SchedulerClusterID: q.SchedulerClusterID,
+	}).Find(&blocklists).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return blocklists, count, nil
+}
+
+// validateBlocklistPattern validates the pattern can be compiled by schedulers.
+func validateBlocklistPattern(blocklistType, pattern string) error {
+	if _, err := (types.BlocklistRule{Type: blocklistType, Pattern: pattern}).Compile(); err != nil {
+		return dferrors.Newf(commonv1.Code_InvalidResourceType, "invalid blocklist pattern %s: %s", pattern, err.Error())
+	}
+
+	return nil
+}
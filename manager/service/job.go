@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"gorm.io/gorm"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/manager/model"
@@ -31,45 +32,56 @@ import (
 )
 
 func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheatJobRequest) (*model.Job, error) {
-	var schedulers []model.Scheduler
-	var schedulerClusters []model.SchedulerCluster
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
 
-	if len(json.SchedulerClusterIDs) != 0 {
-		for _, schedulerClusterID := range json.SchedulerClusterIDs {
-			schedulerCluster := model.SchedulerCluster{}
-			if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
-				return nil, err
-			}
-			schedulerClusters = append(schedulerClusters, schedulerCluster)
-
-			scheduler := model.Scheduler{}
-			if err := s.db.WithContext(ctx).First(&scheduler, model.Scheduler{
-				SchedulerClusterID: schedulerCluster.ID,
-				State:              model.SchedulerStateActive,
-			}).Error; err != nil {
-				return nil, err
-			}
-			schedulers = append(schedulers, scheduler)
-		}
-	} else {
-		if err := s.db.WithContext(ctx).Find(&schedulerClusters).Error; err != nil {
-			return nil, err
-		}
+	schedulers, schedulerClusters, err := s.findActiveSchedulers(ctx, json.TenantID, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, schedulerCluster := range schedulerClusters {
-			scheduler := model.Scheduler{}
-			if err := s.db.WithContext(ctx).First(&scheduler, model.Scheduler{
-				SchedulerClusterID: schedulerCluster.ID,
-				State:              model.SchedulerStateActive,
-			}).Error; err != nil {
-				continue
-			}
+	groupJobState, err := s.job.CreatePreheat(ctx, schedulers, json.Args)
+	if err != nil {
+		return nil, err
+	}
 
-			schedulers = append(schedulers, scheduler)
-		}
+	args, err := structure.StructToMap(json.Args)
+	if err != nil {
+		return nil, err
 	}
 
-	groupJobState, err := s.job.CreatePreheat(ctx, schedulers, json.Args)
+	job := model.Job{
+		TaskID:            groupJobState.GroupUUID,
+		BIO:               json.BIO,
+		Type:              json.Type,
+		State:             groupJobState.State,
+		Args:              args,
+		UserID:            json.UserID,
+		TenantID:          json.TenantID,
+		SchedulerClusters: schedulerClusters,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), job.ID, job.TaskID)
+
+	return &job, nil
+}
+
+func (s *service) CreateDeleteTaskJob(ctx context.Context, json types.CreateDeleteTaskJobRequest) (*model.Job, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
+	schedulers, schedulerClusters, err := s.findAllActiveSchedulers(ctx, json.TenantID, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	groupJobState, err := s.job.CreateDeleteTask(ctx, schedulers, json.Args)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +98,7 @@ func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheat
 		State:             groupJobState.State,
 		Args:              args,
 		UserID:            json.UserID,
+		TenantID:          json.TenantID,
 		SchedulerClusters: schedulerClusters,
 	}
 
@@ -98,22 +111,106 @@ func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheat
 	return &job, nil
 }
 
+// findActiveSchedulers finds an active scheduler in each scheduler cluster,
+// if schedulerClusterIDs is empty, finds in all scheduler clusters.
+// Scheduler clusters are limited to the tenant and the shared ones, or only the shared ones if tenantID is zero.
+func (s *service) findActiveSchedulers(ctx context.Context, tenantID uint, schedulerClusterIDs []uint) ([]model.Scheduler, []model.SchedulerCluster, error) {
+	schedulerClusters, err := s.findSchedulerClusters(ctx, tenantID, schedulerClusterIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var schedulers []model.Scheduler
+	for _, schedulerCluster := range schedulerClusters {
+		scheduler := model.Scheduler{}
+		if err := s.db.WithContext(ctx).First(&scheduler, model.Scheduler{
+			SchedulerClusterID: schedulerCluster.ID,
+			State:              model.SchedulerStateActive,
+		}).Error; err != nil {
+			// Specified scheduler cluster must have an active scheduler.
+			if len(schedulerClusterIDs) != 0 {
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		schedulers = append(schedulers, scheduler)
+	}
+
+	return schedulers, schedulerClusters, nil
+}
+
+// findAllActiveSchedulers finds all active schedulers in each scheduler cluster,
+// it is used by jobs which must be processed by every scheduler, e.g. deleting task.
+// Scheduler clusters are found as findActiveSchedulers.
+func (s *service) findAllActiveSchedulers(ctx context.Context, tenantID uint, schedulerClusterIDs []uint) ([]model.Scheduler, []model.SchedulerCluster, error) {
+	schedulerClusters, err := s.findSchedulerClusters(ctx, tenantID, schedulerClusterIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var schedulers []model.Scheduler
+	for _, schedulerCluster := range schedulerClusters {
+		var clusterSchedulers []model.Scheduler
+		if err := s.db.WithContext(ctx).Find(&clusterSchedulers, model.Scheduler{
+			SchedulerClusterID: schedulerCluster.ID,
+			State:              model.SchedulerStateActive,
+		}).Error; err != nil {
+			return nil, nil, err
+		}
+
+		// Specified scheduler cluster must have an active scheduler.
+		if len(clusterSchedulers) == 0 && len(schedulerClusterIDs) != 0 {
+			return nil, nil, gorm.ErrRecordNotFound
+		}
+
+		schedulers = append(schedulers, clusterSchedulers...)
+	}
+
+	return schedulers, schedulerClusters, nil
+}
+
+// findSchedulerClusters finds the scheduler clusters by ids, if schedulerClusterIDs is empty,
+// finds all scheduler clusters of the tenant and the shared ones.
+func (s *service) findSchedulerClusters(ctx context.Context, tenantID uint, schedulerClusterIDs []uint) ([]model.SchedulerCluster, error) {
+	var schedulerClusters []model.SchedulerCluster
+	if len(schedulerClusterIDs) == 0 {
+		if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx), scopeTenant(tenantID)).Find(&schedulerClusters).Error; err != nil {
+			return nil, err
+		}
+
+		return schedulerClusters, nil
+	}
+
+	for _, schedulerClusterID := range schedulerClusterIDs {
+		schedulerCluster := model.SchedulerCluster{}
+		if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx), scopeTenant(tenantID)).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+			return nil, err
+		}
+
+		schedulerClusters = append(schedulerClusters, schedulerCluster)
+	}
+
+	return schedulerClusters, nil
+}
+
 func (s *service) pollingJob(ctx context.Context, id uint, taskID string) {
 	var (
 		job model.Job
 		log = logger.WithTaskAndJobID(taskID, fmt.Sprint(id))
 	)
-	if _, _, err := retry.Run(ctx, 5, 10, 120, func() (any, bool, error) {
+	if _, err := retry.Run(ctx, 5, 10, 120, func(int) (any, error) {
 		groupJob, err := s.job.GetGroupJobState(taskID)
 		if err != nil {
 			log.Errorf("polling job failed: %s", err.Error())
-			return nil, false, err
+			return nil, err
 		}
 
 		result, err := structure.StructToMap(groupJob)
 		if err != nil {
 			log.Errorf("polling job failed: %s", err.Error())
-			return nil, false, err
+			return nil, err
 		}
 
 		if err := s.db.WithContext(ctx).First(&job, id).Updates(model.Job{
@@ -121,13 +218,13 @@ func (s *service) pollingJob(ctx context.Context, id uint, taskID string) {
 			Result: result,
 		}).Error; err != nil {
 			log.Errorf("polling job failed: %s", err.Error())
-			return nil, true, err
+			return nil, retry.Abort(err)
 		}
 
 		switch job.State {
 		case machineryv1tasks.StateSuccess:
 			log.Info("polling job success")
-			return nil, true, nil
+			return nil, nil
 		case machineryv1tasks.StateFailure:
 			var jobStates []machineryv1tasks.TaskState
 			for _, jobState := range groupJob.JobStates {
@@ -135,11 +232,11 @@ func (s *service) pollingJob(ctx context.Context, id uint, taskID string) {
 			}
 
 			log.Errorf("polling job failed: %#v", jobStates)
-			return nil, true, nil
+			return nil, nil
 		default:
 			msg := fmt.Sprintf("unknow state %s", job.State)
 			log.Error(msg)
-			return nil, false, errors.New(msg)
+			return nil, errors.New(msg)
 		}
 	}); err != nil {
 		log.Errorf("polling job failed: %s", err.Error())
@@ -159,7 +256,7 @@ func (s *service) pollingJob(ctx context.Context, id uint, taskID string) {
 
 func (s *service) DestroyJob(ctx context.Context, id uint) error {
 	job := model.Job{}
-	if err := s.db.WithContext(ctx).First(&job, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&job, id).Error; err != nil {
 		return err
 	}
 
@@ -172,7 +269,7 @@ func (s *service) DestroyJob(ctx context.Context, id uint) error {
 
 func (s *service) UpdateJob(ctx context.Context, id uint, json types.UpdateJobRequest) (*model.Job, error) {
 	job := model.Job{}
-	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Updates(model.Job{
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Updates(model.Job{
 		BIO:    json.BIO,
 		UserID: json.UserID,
 	}).Error; err != nil {
@@ -184,7 +281,7 @@ func (s *service) UpdateJob(ctx context.Context, id uint, json types.UpdateJobRe
 
 func (s *service) GetJob(ctx context.Context, id uint) (*model.Job, error) {
 	job := model.Job{}
-	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("SeedPeerClusters").Preload("SchedulerClusters").First(&job, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -194,10 +291,11 @@ func (s *service) GetJob(ctx context.Context, id uint) (*model.Job, error) {
 func (s *service) GetJobs(ctx context.Context, q types.GetJobsQuery) ([]model.Job, int64, error) {
 	var count int64
 	var jobs []model.Job
-	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.Job{
-		Type:   q.Type,
-		State:  q.State,
-		UserID: q.UserID,
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenantResources(ctx)).Where(&model.Job{
+		Type:     q.Type,
+		State:    q.State,
+		UserID:   q.UserID,
+		TenantID: q.TenantID,
 	}).Find(&jobs).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
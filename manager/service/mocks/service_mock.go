@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	job "d7y.io/dragonfly/v2/internal/job"
 	model "d7y.io/dragonfly/v2/manager/model"
 	rbac "d7y.io/dragonfly/v2/manager/permission/rbac"
 	types "d7y.io/dragonfly/v2/manager/types"
@@ -181,6 +182,50 @@ func (mr *MockServiceMockRecorder) AddSeedPeerToSeedPeerCluster(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSeedPeerToSeedPeerCluster", reflect.TypeOf((*MockService)(nil).AddSeedPeerToSeedPeerCluster), arg0, arg1, arg2)
 }
 
+// AddUserToTenant mocks base method.
+func (m *MockService) AddUserToTenant(arg0 context.Context, arg1, arg2 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUserToTenant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddUserToTenant indicates an expected call of AddUserToTenant.
+func (mr *MockServiceMockRecorder) AddUserToTenant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUserToTenant", reflect.TypeOf((*MockService)(nil).AddUserToTenant), arg0, arg1, arg2)
+}
+
+// AuthenticatePersonalAccessToken mocks base method.
+func (m *MockService) AuthenticatePersonalAccessToken(arg0 context.Context, arg1 string) (*model.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticatePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(*model.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticatePersonalAccessToken indicates an expected call of AuthenticatePersonalAccessToken.
+func (mr *MockServiceMockRecorder) AuthenticatePersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticatePersonalAccessToken", reflect.TypeOf((*MockService)(nil).AuthenticatePersonalAccessToken), arg0, arg1)
+}
+
+// CancelQueuedJob mocks base method.
+func (m *MockService) CancelQueuedJob(arg0 context.Context, arg1 string) (*job.JobInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelQueuedJob", arg0, arg1)
+	ret0, _ := ret[0].(*job.JobInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelQueuedJob indicates an expected call of CancelQueuedJob.
+func (mr *MockServiceMockRecorder) CancelQueuedJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelQueuedJob", reflect.TypeOf((*MockService)(nil).CancelQueuedJob), arg0, arg1)
+}
+
 // CreateApplication mocks base method.
 func (m *MockService) CreateApplication(arg0 context.Context, arg1 types.CreateApplicationRequest) (*model.Application, error) {
 	m.ctrl.T.Helper()
@@ -196,6 +241,21 @@ func (mr *MockServiceMockRecorder) CreateApplication(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplication", reflect.TypeOf((*MockService)(nil).CreateApplication), arg0, arg1)
 }
 
+// CreateBlocklist mocks base method.
+func (m *MockService) CreateBlocklist(arg0 context.Context, arg1 types.CreateBlocklistRequest) (*model.Blocklist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlocklist", arg0, arg1)
+	ret0, _ := ret[0].(*model.Blocklist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBlocklist indicates an expected call of CreateBlocklist.
+func (mr *MockServiceMockRecorder) CreateBlocklist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlocklist", reflect.TypeOf((*MockService)(nil).CreateBlocklist), arg0, arg1)
+}
+
 // CreateBucket mocks base method.
 func (m *MockService) CreateBucket(arg0 context.Context, arg1 types.CreateBucketRequest) error {
 	m.ctrl.T.Helper()
@@ -225,6 +285,21 @@ func (mr *MockServiceMockRecorder) CreateConfig(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConfig", reflect.TypeOf((*MockService)(nil).CreateConfig), arg0, arg1)
 }
 
+// CreateDeleteTaskJob mocks base method.
+func (m *MockService) CreateDeleteTaskJob(arg0 context.Context, arg1 types.CreateDeleteTaskJobRequest) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeleteTaskJob", arg0, arg1)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDeleteTaskJob indicates an expected call of CreateDeleteTaskJob.
+func (mr *MockServiceMockRecorder) CreateDeleteTaskJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeleteTaskJob", reflect.TypeOf((*MockService)(nil).CreateDeleteTaskJob), arg0, arg1)
+}
+
 // CreateModel mocks base method.
 func (m *MockService) CreateModel(arg0 context.Context, arg1 types.CreateModelParams, arg2 types.CreateModelRequest) (*types.Model, error) {
 	m.ctrl.T.Helper()
@@ -270,6 +345,21 @@ func (mr *MockServiceMockRecorder) CreateOauth(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOauth", reflect.TypeOf((*MockService)(nil).CreateOauth), arg0, arg1)
 }
 
+// CreatePersonalAccessToken mocks base method.
+func (m *MockService) CreatePersonalAccessToken(arg0 context.Context, arg1 uint, arg2 types.CreatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalAccessToken indicates an expected call of CreatePersonalAccessToken.
+func (mr *MockServiceMockRecorder) CreatePersonalAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalAccessToken", reflect.TypeOf((*MockService)(nil).CreatePersonalAccessToken), arg0, arg1, arg2)
+}
+
 // CreatePreheatJob mocks base method.
 func (m *MockService) CreatePreheatJob(arg0 context.Context, arg1 types.CreatePreheatJobRequest) (*model.Job, error) {
 	m.ctrl.T.Helper()
@@ -389,6 +479,21 @@ func (mr *MockServiceMockRecorder) CreateSeedPeerCluster(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSeedPeerCluster", reflect.TypeOf((*MockService)(nil).CreateSeedPeerCluster), arg0, arg1)
 }
 
+// CreateTenant mocks base method.
+func (m *MockService) CreateTenant(arg0 context.Context, arg1 types.CreateTenantRequest) (*model.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTenant", arg0, arg1)
+	ret0, _ := ret[0].(*model.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTenant indicates an expected call of CreateTenant.
+func (mr *MockServiceMockRecorder) CreateTenant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTenant", reflect.TypeOf((*MockService)(nil).CreateTenant), arg0, arg1)
+}
+
 // CreateV1Preheat mocks base method.
 func (m *MockService) CreateV1Preheat(arg0 context.Context, arg1 types.CreateV1PreheatRequest) (*types.CreateV1PreheatResponse, error) {
 	m.ctrl.T.Helper()
@@ -462,6 +567,20 @@ func (mr *MockServiceMockRecorder) DeleteSeedPeerClusterToApplication(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSeedPeerClusterToApplication", reflect.TypeOf((*MockService)(nil).DeleteSeedPeerClusterToApplication), arg0, arg1, arg2)
 }
 
+// DeleteUserToTenant mocks base method.
+func (m *MockService) DeleteUserToTenant(arg0 context.Context, arg1, arg2 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserToTenant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserToTenant indicates an expected call of DeleteUserToTenant.
+func (mr *MockServiceMockRecorder) DeleteUserToTenant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserToTenant", reflect.TypeOf((*MockService)(nil).DeleteUserToTenant), arg0, arg1, arg2)
+}
+
 // DestroyApplication mocks base method.
 func (m *MockService) DestroyApplication(arg0 context.Context, arg1 uint) error {
 	m.ctrl.T.Helper()
@@ -476,6 +595,20 @@ func (mr *MockServiceMockRecorder) DestroyApplication(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyApplication", reflect.TypeOf((*MockService)(nil).DestroyApplication), arg0, arg1)
 }
 
+// DestroyBlocklist mocks base method.
+func (m *MockService) DestroyBlocklist(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyBlocklist", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyBlocklist indicates an expected call of DestroyBlocklist.
+func (mr *MockServiceMockRecorder) DestroyBlocklist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyBlocklist", reflect.TypeOf((*MockService)(nil).DestroyBlocklist), arg0, arg1)
+}
+
 // DestroyBucket mocks base method.
 func (m *MockService) DestroyBucket(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -560,6 +693,20 @@ func (mr *MockServiceMockRecorder) DestroyOauth(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyOauth", reflect.TypeOf((*MockService)(nil).DestroyOauth), arg0, arg1)
 }
 
+// DestroyPersonalAccessToken mocks base method.
+func (m *MockService) DestroyPersonalAccessToken(arg0 context.Context, arg1, arg2 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyPersonalAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyPersonalAccessToken indicates an expected call of DestroyPersonalAccessToken.
+func (mr *MockServiceMockRecorder) DestroyPersonalAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyPersonalAccessToken", reflect.TypeOf((*MockService)(nil).DestroyPersonalAccessToken), arg0, arg1, arg2)
+}
+
 // DestroyRole mocks base method.
 func (m *MockService) DestroyRole(arg0 context.Context, arg1 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -673,6 +820,35 @@ func (mr *MockServiceMockRecorder) DestroySeedPeerCluster(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroySeedPeerCluster", reflect.TypeOf((*MockService)(nil).DestroySeedPeerCluster), arg0, arg1)
 }
 
+// DestroyTask mocks base method.
+func (m *MockService) DestroyTask(arg0 context.Context, arg1 types.TaskParams, arg2 types.DestroyTaskQuery) (*model.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyTask", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DestroyTask indicates an expected call of DestroyTask.
+func (mr *MockServiceMockRecorder) DestroyTask(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyTask", reflect.TypeOf((*MockService)(nil).DestroyTask), arg0, arg1, arg2)
+}
+
+// DestroyTenant mocks base method.
+func (m *MockService) DestroyTenant(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyTenant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyTenant indicates an expected call of DestroyTenant.
+func (mr *MockServiceMockRecorder) DestroyTenant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyTenant", reflect.TypeOf((*MockService)(nil).DestroyTenant), arg0, arg1)
+}
+
 // GetApplication mocks base method.
 func (m *MockService) GetApplication(arg0 context.Context, arg1 uint) (*model.Application, error) {
 	m.ctrl.T.Helper()
@@ -704,6 +880,37 @@ func (mr *MockServiceMockRecorder) GetApplications(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplications", reflect.TypeOf((*MockService)(nil).GetApplications), arg0, arg1)
 }
 
+// GetBlocklist mocks base method.
+func (m *MockService) GetBlocklist(arg0 context.Context, arg1 uint) (*model.Blocklist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocklist", arg0, arg1)
+	ret0, _ := ret[0].(*model.Blocklist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocklist indicates an expected call of GetBlocklist.
+func (mr *MockServiceMockRecorder) GetBlocklist(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocklist", reflect.TypeOf((*MockService)(nil).GetBlocklist), arg0, arg1)
+}
+
+// GetBlocklists mocks base method.
+func (m *MockService) GetBlocklists(arg0 context.Context, arg1 types.GetBlocklistsQuery) ([]model.Blocklist, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocklists", arg0, arg1)
+	ret0, _ := ret[0].([]model.Blocklist)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetBlocklists indicates an expected call of GetBlocklists.
+func (mr *MockServiceMockRecorder) GetBlocklists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocklists", reflect.TypeOf((*MockService)(nil).GetBlocklists), arg0, arg1)
+}
+
 // GetBucket mocks base method.
 func (m *MockService) GetBucket(arg0 context.Context, arg1 string) (*objectstorage.BucketMetadata, error) {
 	m.ctrl.T.Helper()
@@ -734,6 +941,21 @@ func (mr *MockServiceMockRecorder) GetBuckets(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBuckets", reflect.TypeOf((*MockService)(nil).GetBuckets), arg0)
 }
 
+// GetClusterStats mocks base method.
+func (m *MockService) GetClusterStats(arg0 context.Context, arg1 uint) (*types.ClusterStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterStats", arg0, arg1)
+	ret0, _ := ret[0].(*types.ClusterStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterStats indicates an expected call of GetClusterStats.
+func (mr *MockServiceMockRecorder) GetClusterStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterStats", reflect.TypeOf((*MockService)(nil).GetClusterStats), arg0, arg1)
+}
+
 // GetConfig mocks base method.
 func (m *MockService) GetConfig(arg0 context.Context, arg1 uint) (*model.Config, error) {
 	m.ctrl.T.Helper()
@@ -916,6 +1138,68 @@ func (mr *MockServiceMockRecorder) GetPermissions(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissions", reflect.TypeOf((*MockService)(nil).GetPermissions), arg0, arg1)
 }
 
+// GetPersonalAccessToken mocks base method.
+func (m *MockService) GetPersonalAccessToken(arg0 context.Context, arg1, arg2 uint) (*model.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessToken indicates an expected call of GetPersonalAccessToken.
+func (mr *MockServiceMockRecorder) GetPersonalAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessToken", reflect.TypeOf((*MockService)(nil).GetPersonalAccessToken), arg0, arg1, arg2)
+}
+
+// GetPersonalAccessTokens mocks base method.
+func (m *MockService) GetPersonalAccessTokens(arg0 context.Context, arg1 uint, arg2 types.GetPersonalAccessTokensQuery) ([]model.PersonalAccessToken, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.PersonalAccessToken)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPersonalAccessTokens indicates an expected call of GetPersonalAccessTokens.
+func (mr *MockServiceMockRecorder) GetPersonalAccessTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokens", reflect.TypeOf((*MockService)(nil).GetPersonalAccessTokens), arg0, arg1, arg2)
+}
+
+// GetQueuedJob mocks base method.
+func (m *MockService) GetQueuedJob(arg0 context.Context, arg1 string) (*job.JobInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueuedJob", arg0, arg1)
+	ret0, _ := ret[0].(*job.JobInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueuedJob indicates an expected call of GetQueuedJob.
+func (mr *MockServiceMockRecorder) GetQueuedJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueuedJob", reflect.TypeOf((*MockService)(nil).GetQueuedJob), arg0, arg1)
+}
+
+// GetQueuedJobs mocks base method.
+func (m *MockService) GetQueuedJobs(arg0 context.Context, arg1 types.GetQueuedJobsQuery) ([]*job.JobInfo, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueuedJobs", arg0, arg1)
+	ret0, _ := ret[0].([]*job.JobInfo)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetQueuedJobs indicates an expected call of GetQueuedJobs.
+func (mr *MockServiceMockRecorder) GetQueuedJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueuedJobs", reflect.TypeOf((*MockService)(nil).GetQueuedJobs), arg0, arg1)
+}
+
 // GetRole mocks base method.
 func (m *MockService) GetRole(arg0 context.Context, arg1 string) [][]string {
 	m.ctrl.T.Helper()
@@ -1145,6 +1429,52 @@ func (mr *MockServiceMockRecorder) GetSeedPeers(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeedPeers", reflect.TypeOf((*MockService)(nil).GetSeedPeers), arg0, arg1)
 }
 
+// GetTenant mocks base method.
+func (m *MockService) GetTenant(arg0 context.Context, arg1 uint) (*model.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenant", arg0, arg1)
+	ret0, _ := ret[0].(*model.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenant indicates an expected call of GetTenant.
+func (mr *MockServiceMockRecorder) GetTenant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenant", reflect.TypeOf((*MockService)(nil).GetTenant), arg0, arg1)
+}
+
+// GetTenantScope mocks base method.
+func (m *MockService) GetTenantScope(arg0 context.Context, arg1 uint) (*types.TenantScope, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantScope", arg0, arg1)
+	ret0, _ := ret[0].(*types.TenantScope)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantScope indicates an expected call of GetTenantScope.
+func (mr *MockServiceMockRecorder) GetTenantScope(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantScope", reflect.TypeOf((*MockService)(nil).GetTenantScope), arg0, arg1)
+}
+
+// GetTenants mocks base method.
+func (m *MockService) GetTenants(arg0 context.Context, arg1 types.GetTenantsQuery) ([]model.Tenant, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", arg0, arg1)
+	ret0, _ := ret[0].([]model.Tenant)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockServiceMockRecorder) GetTenants(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockService)(nil).GetTenants), arg0, arg1)
+}
+
 // GetUser mocks base method.
 func (m *MockService) GetUser(arg0 context.Context, arg1 uint) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -1191,6 +1521,36 @@ func (mr *MockServiceMockRecorder) GetV1Preheat(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV1Preheat", reflect.TypeOf((*MockService)(nil).GetV1Preheat), arg0, arg1)
 }
 
+// OIDCSignin mocks base method.
+func (m *MockService) OIDCSignin(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCSignin", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OIDCSignin indicates an expected call of OIDCSignin.
+func (mr *MockServiceMockRecorder) OIDCSignin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCSignin", reflect.TypeOf((*MockService)(nil).OIDCSignin), arg0, arg1)
+}
+
+// OIDCSigninCallback mocks base method.
+func (m *MockService) OIDCSigninCallback(arg0 context.Context, arg1, arg2 string) (*model.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OIDCSigninCallback", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OIDCSigninCallback indicates an expected call of OIDCSigninCallback.
+func (mr *MockServiceMockRecorder) OIDCSigninCallback(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OIDCSigninCallback", reflect.TypeOf((*MockService)(nil).OIDCSigninCallback), arg0, arg1, arg2)
+}
+
 // OauthSignin mocks base method.
 func (m *MockService) OauthSignin(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -1221,6 +1581,62 @@ func (mr *MockServiceMockRecorder) OauthSigninCallback(arg0, arg1, arg2 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OauthSigninCallback", reflect.TypeOf((*MockService)(nil).OauthSigninCallback), arg0, arg1, arg2)
 }
 
+// PurgeScheduler mocks base method.
+func (m *MockService) PurgeScheduler(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeScheduler", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeScheduler indicates an expected call of PurgeScheduler.
+func (mr *MockServiceMockRecorder) PurgeScheduler(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeScheduler", reflect.TypeOf((*MockService)(nil).PurgeScheduler), arg0, arg1)
+}
+
+// PurgeSchedulerCluster mocks base method.
+func (m *MockService) PurgeSchedulerCluster(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSchedulerCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeSchedulerCluster indicates an expected call of PurgeSchedulerCluster.
+func (mr *MockServiceMockRecorder) PurgeSchedulerCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSchedulerCluster", reflect.TypeOf((*MockService)(nil).PurgeSchedulerCluster), arg0, arg1)
+}
+
+// PurgeSeedPeer mocks base method.
+func (m *MockService) PurgeSeedPeer(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSeedPeer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeSeedPeer indicates an expected call of PurgeSeedPeer.
+func (mr *MockServiceMockRecorder) PurgeSeedPeer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSeedPeer", reflect.TypeOf((*MockService)(nil).PurgeSeedPeer), arg0, arg1)
+}
+
+// PurgeSeedPeerCluster mocks base method.
+func (m *MockService) PurgeSeedPeerCluster(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeSeedPeerCluster", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeSeedPeerCluster indicates an expected call of PurgeSeedPeerCluster.
+func (mr *MockServiceMockRecorder) PurgeSeedPeerCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeSeedPeerCluster", reflect.TypeOf((*MockService)(nil).PurgeSeedPeerCluster), arg0, arg1)
+}
+
 // ResetPassword mocks base method.
 func (m *MockService) ResetPassword(arg0 context.Context, arg1 uint, arg2 types.ResetPasswordRequest) error {
 	m.ctrl.T.Helper()
@@ -1235,6 +1651,96 @@ func (mr *MockServiceMockRecorder) ResetPassword(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), arg0, arg1, arg2)
 }
 
+// ResolveSecurityRule mocks base method.
+func (m *MockService) ResolveSecurityRule(arg0 context.Context, arg1 types.ResolveSecurityRuleQuery) (*types.ResolvedSecurityRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveSecurityRule", arg0, arg1)
+	ret0, _ := ret[0].(*types.ResolvedSecurityRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveSecurityRule indicates an expected call of ResolveSecurityRule.
+func (mr *MockServiceMockRecorder) ResolveSecurityRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveSecurityRule", reflect.TypeOf((*MockService)(nil).ResolveSecurityRule), arg0, arg1)
+}
+
+// RestoreScheduler mocks base method.
+func (m *MockService) RestoreScheduler(arg0 context.Context, arg1 uint) (*model.Scheduler, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreScheduler", arg0, arg1)
+	ret0, _ := ret[0].(*model.Scheduler)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreScheduler indicates an expected call of RestoreScheduler.
+func (mr *MockServiceMockRecorder) RestoreScheduler(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreScheduler", reflect.TypeOf((*MockService)(nil).RestoreScheduler), arg0, arg1)
+}
+
+// RestoreSchedulerCluster mocks base method.
+func (m *MockService) RestoreSchedulerCluster(arg0 context.Context, arg1 uint) (*model.SchedulerCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSchedulerCluster", arg0, arg1)
+	ret0, _ := ret[0].(*model.SchedulerCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSchedulerCluster indicates an expected call of RestoreSchedulerCluster.
+func (mr *MockServiceMockRecorder) RestoreSchedulerCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSchedulerCluster", reflect.TypeOf((*MockService)(nil).RestoreSchedulerCluster), arg0, arg1)
+}
+
+// RestoreSeedPeer mocks base method.
+func (m *MockService) RestoreSeedPeer(arg0 context.Context, arg1 uint) (*model.SeedPeer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSeedPeer", arg0, arg1)
+	ret0, _ := ret[0].(*model.SeedPeer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSeedPeer indicates an expected call of RestoreSeedPeer.
+func (mr *MockServiceMockRecorder) RestoreSeedPeer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSeedPeer", reflect.TypeOf((*MockService)(nil).RestoreSeedPeer), arg0, arg1)
+}
+
+// RestoreSeedPeerCluster mocks base method.
+func (m *MockService) RestoreSeedPeerCluster(arg0 context.Context, arg1 uint) (*model.SeedPeerCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreSeedPeerCluster", arg0, arg1)
+	ret0, _ := ret[0].(*model.SeedPeerCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreSeedPeerCluster indicates an expected call of RestoreSeedPeerCluster.
+func (mr *MockServiceMockRecorder) RestoreSeedPeerCluster(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSeedPeerCluster", reflect.TypeOf((*MockService)(nil).RestoreSeedPeerCluster), arg0, arg1)
+}
+
+// RetryQueuedJob mocks base method.
+func (m *MockService) RetryQueuedJob(arg0 context.Context, arg1 string) (*job.JobInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryQueuedJob", arg0, arg1)
+	ret0, _ := ret[0].(*job.JobInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryQueuedJob indicates an expected call of RetryQueuedJob.
+func (mr *MockServiceMockRecorder) RetryQueuedJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryQueuedJob", reflect.TypeOf((*MockService)(nil).RetryQueuedJob), arg0, arg1)
+}
+
 // SignIn mocks base method.
 func (m *MockService) SignIn(arg0 context.Context, arg1 types.SignInRequest) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -1265,6 +1771,20 @@ func (mr *MockServiceMockRecorder) SignUp(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockService)(nil).SignUp), arg0, arg1)
 }
 
+// SyncLDAPUsers mocks base method.
+func (m *MockService) SyncLDAPUsers(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncLDAPUsers", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SyncLDAPUsers indicates an expected call of SyncLDAPUsers.
+func (mr *MockServiceMockRecorder) SyncLDAPUsers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncLDAPUsers", reflect.TypeOf((*MockService)(nil).SyncLDAPUsers), arg0)
+}
+
 // UpdateApplication mocks base method.
 func (m *MockService) UpdateApplication(arg0 context.Context, arg1 uint, arg2 types.UpdateApplicationRequest) (*model.Application, error) {
 	m.ctrl.T.Helper()
@@ -1280,6 +1800,21 @@ func (mr *MockServiceMockRecorder) UpdateApplication(arg0, arg1, arg2 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateApplication", reflect.TypeOf((*MockService)(nil).UpdateApplication), arg0, arg1, arg2)
 }
 
+// UpdateBlocklist mocks base method.
+func (m *MockService) UpdateBlocklist(arg0 context.Context, arg1 uint, arg2 types.UpdateBlocklistRequest) (*model.Blocklist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBlocklist", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Blocklist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateBlocklist indicates an expected call of UpdateBlocklist.
+func (mr *MockServiceMockRecorder) UpdateBlocklist(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBlocklist", reflect.TypeOf((*MockService)(nil).UpdateBlocklist), arg0, arg1, arg2)
+}
+
 // UpdateConfig mocks base method.
 func (m *MockService) UpdateConfig(arg0 context.Context, arg1 uint, arg2 types.UpdateConfigRequest) (*model.Config, error) {
 	m.ctrl.T.Helper()
@@ -1355,6 +1890,21 @@ func (mr *MockServiceMockRecorder) UpdateOauth(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOauth", reflect.TypeOf((*MockService)(nil).UpdateOauth), arg0, arg1, arg2)
 }
 
+// UpdatePersonalAccessToken mocks base method.
+func (m *MockService) UpdatePersonalAccessToken(arg0 context.Context, arg1, arg2 uint, arg3 types.UpdatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePersonalAccessToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*model.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePersonalAccessToken indicates an expected call of UpdatePersonalAccessToken.
+func (mr *MockServiceMockRecorder) UpdatePersonalAccessToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePersonalAccessToken", reflect.TypeOf((*MockService)(nil).UpdatePersonalAccessToken), arg0, arg1, arg2, arg3)
+}
+
 // UpdateScheduler mocks base method.
 func (m *MockService) UpdateScheduler(arg0 context.Context, arg1 uint, arg2 types.UpdateSchedulerRequest) (*model.Scheduler, error) {
 	m.ctrl.T.Helper()
@@ -1445,6 +1995,21 @@ func (mr *MockServiceMockRecorder) UpdateSeedPeerCluster(arg0, arg1, arg2 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeedPeerCluster", reflect.TypeOf((*MockService)(nil).UpdateSeedPeerCluster), arg0, arg1, arg2)
 }
 
+// UpdateTenant mocks base method.
+func (m *MockService) UpdateTenant(arg0 context.Context, arg1 uint, arg2 types.UpdateTenantRequest) (*model.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTenant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*model.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTenant indicates an expected call of UpdateTenant.
+func (mr *MockServiceMockRecorder) UpdateTenant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTenant", reflect.TypeOf((*MockService)(nil).UpdateTenant), arg0, arg1, arg2)
+}
+
 // UpdateUser mocks base method.
 func (m *MockService) UpdateUser(arg0 context.Context, arg1 uint, arg2 types.UpdateUserRequest) (*model.User, error) {
 	m.ctrl.T.Helper()
@@ -0,0 +1,180 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+// personalAccessTokenLength is the random bytes length of personal access token.
+const personalAccessTokenLength = 32
+
+func (s *service) CreatePersonalAccessToken(ctx context.Context, userID uint, json types.CreatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error) {
+	rawToken, err := generatePersonalAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessToken := model.PersonalAccessToken{
+		Name:      json.Name,
+		BIO:       json.BIO,
+		Token:     digest.SHA256FromStrings(rawToken),
+		Scopes:    json.Scopes,
+		State:     model.PersonalAccessTokenStateActive,
+		ExpiredAt: json.ExpiredAt,
+		UserID:    userID,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&personalAccessToken).Error; err != nil {
+		return nil, err
+	}
+
+	// Raw token is only returned when it is created, only the sha256 of token is stored.
+	personalAccessToken.RawToken = rawToken
+	return &personalAccessToken, nil
+}
+
+func (s *service) DestroyPersonalAccessToken(ctx context.Context, userID, id uint) error {
+	scope, err := s.scopePersonalAccessTokens(userID)
+	if err != nil {
+		return err
+	}
+
+	personalAccessToken := model.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).Scopes(scope).First(&personalAccessToken, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&model.PersonalAccessToken{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdatePersonalAccessToken(ctx context.Context, userID, id uint, json types.UpdatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error) {
+	scope, err := s.scopePersonalAccessTokens(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessToken := model.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).Scopes(scope).First(&personalAccessToken, id).Updates(model.PersonalAccessToken{
+		BIO:       json.BIO,
+		Scopes:    json.Scopes,
+		State:     json.State,
+		ExpiredAt: json.ExpiredAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &personalAccessToken, nil
+}
+
+func (s *service) GetPersonalAccessToken(ctx context.Context, userID, id uint) (*model.PersonalAccessToken, error) {
+	scope, err := s.scopePersonalAccessTokens(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessToken := model.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).Scopes(scope).Preload("User").First(&personalAccessToken, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &personalAccessToken, nil
+}
+
+func (s *service) GetPersonalAccessTokens(ctx context.Context, userID uint, q types.GetPersonalAccessTokensQuery) ([]model.PersonalAccessToken, int64, error) {
+	scope, err := s.scopePersonalAccessTokens(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	var personalAccessTokens []model.PersonalAccessToken
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scope).Where(&model.PersonalAccessToken{
+		State:  q.State,
+		UserID: q.UserID,
+	}).Preload("User").Find(&personalAccessTokens).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return personalAccessTokens, count, nil
+}
+
+func (s *service) AuthenticatePersonalAccessToken(ctx context.Context, rawToken string) (*model.PersonalAccessToken, error) {
+	personalAccessToken := model.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).Preload("User").First(&personalAccessToken, model.PersonalAccessToken{
+		Token: digest.SHA256FromStrings(rawToken),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	if personalAccessToken.State != model.PersonalAccessTokenStateActive {
+		return nil, errors.New("personal access token is inactive")
+	}
+
+	if time.Now().After(personalAccessToken.ExpiredAt) {
+		return nil, errors.New("personal access token is expired")
+	}
+
+	if personalAccessToken.User.State != model.UserStateEnabled {
+		return nil, errors.New("user of personal access token is disabled")
+	}
+
+	return &personalAccessToken, nil
+}
+
+// scopePersonalAccessTokens limits the query to the personal access tokens of the user,
+// only root can access the personal access tokens of all users.
+func (s *service) scopePersonalAccessTokens(userID uint) (func(*gorm.DB) *gorm.DB, error) {
+	isRoot, err := s.enforcer.HasRoleForUser(fmt.Sprint(userID), rbac.RootRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if isRoot {
+			return db
+		}
+
+		return db.Where("user_id = ?", userID)
+	}, nil
+}
+
+// generatePersonalAccessToken generates a random personal access token.
+func generatePersonalAccessToken() (string, error) {
+	b := make([]byte, personalAccessTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return model.PersonalAccessTokenPrefix + hex.EncodeToString(b), nil
+}
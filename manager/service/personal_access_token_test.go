@@ -0,0 +1,160 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestService_PersonalAccessToken(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s *service, root, foo, bar *model.User)
+	}{
+		{
+			name: "create personal access token for the user",
+			run: func(t *testing.T, s *service, root, foo, bar *model.User) {
+				assert := assert.New(t)
+				personalAccessToken, err := s.CreatePersonalAccessToken(context.Background(), foo.ID, types.CreatePersonalAccessTokenRequest{
+					Name:      "foo",
+					Scopes:    []string{"jobs"},
+					ExpiredAt: time.Now().Add(time.Hour),
+				})
+				assert.NoError(err)
+				assert.Equal(foo.ID, personalAccessToken.UserID)
+
+				authenticated, err := s.AuthenticatePersonalAccessToken(context.Background(), personalAccessToken.RawToken)
+				assert.NoError(err)
+				assert.Equal(foo.ID, authenticated.UserID)
+			},
+		},
+		{
+			name: "create personal access tokens with the same name",
+			run: func(t *testing.T, s *service, root, foo, bar *model.User) {
+				assert := assert.New(t)
+				request := types.CreatePersonalAccessTokenRequest{
+					Name:      "ci",
+					Scopes:    []string{"jobs"},
+					ExpiredAt: time.Now().Add(time.Hour),
+				}
+
+				_, err := s.CreatePersonalAccessToken(context.Background(), foo.ID, request)
+				assert.NoError(err)
+				_, err = s.CreatePersonalAccessToken(context.Background(), bar.ID, request)
+				assert.NoError(err)
+				_, err = s.CreatePersonalAccessToken(context.Background(), foo.ID, request)
+				assert.Error(err)
+			},
+		},
+		{
+			name: "access personal access token of other user",
+			run: func(t *testing.T, s *service, root, foo, bar *model.User) {
+				assert := assert.New(t)
+				personalAccessToken := createPersonalAccessToken(t, s, foo)
+
+				_, err := s.GetPersonalAccessToken(context.Background(), bar.ID, personalAccessToken.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+				_, err = s.UpdatePersonalAccessToken(context.Background(), bar.ID, personalAccessToken.ID, types.UpdatePersonalAccessTokenRequest{
+					State: model.PersonalAccessTokenStateInactive,
+				})
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+				assert.ErrorIs(s.DestroyPersonalAccessToken(context.Background(), bar.ID, personalAccessToken.ID), gorm.ErrRecordNotFound)
+
+				_, err = s.GetPersonalAccessToken(context.Background(), foo.ID, personalAccessToken.ID)
+				assert.NoError(err)
+				_, err = s.GetPersonalAccessToken(context.Background(), root.ID, personalAccessToken.ID)
+				assert.NoError(err)
+				assert.NoError(s.DestroyPersonalAccessToken(context.Background(), root.ID, personalAccessToken.ID))
+			},
+		},
+		{
+			name: "get personal access tokens",
+			run: func(t *testing.T, s *service, root, foo, bar *model.User) {
+				assert := assert.New(t)
+				createPersonalAccessToken(t, s, foo)
+				createPersonalAccessToken(t, s, bar)
+
+				personalAccessTokens, count, err := s.GetPersonalAccessTokens(context.Background(), foo.ID, types.GetPersonalAccessTokensQuery{Page: 1, PerPage: 10})
+				assert.NoError(err)
+				assert.Equal(int64(1), count)
+				assert.Equal(foo.ID, personalAccessTokens[0].UserID)
+
+				_, count, err = s.GetPersonalAccessTokens(context.Background(), foo.ID, types.GetPersonalAccessTokensQuery{Page: 1, PerPage: 10, UserID: bar.ID})
+				assert.NoError(err)
+				assert.Equal(int64(0), count)
+
+				_, count, err = s.GetPersonalAccessTokens(context.Background(), root.ID, types.GetPersonalAccessTokensQuery{Page: 1, PerPage: 10})
+				assert.NoError(err)
+				assert.Equal(int64(2), count)
+
+				personalAccessTokens, count, err = s.GetPersonalAccessTokens(context.Background(), root.ID, types.GetPersonalAccessTokensQuery{Page: 1, PerPage: 10, UserID: bar.ID})
+				assert.NoError(err)
+				assert.Equal(int64(1), count)
+				assert.Equal(bar.ID, personalAccessTokens[0].UserID)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+
+			users := []*model.User{}
+			for _, name := range []string{"root", "foo", "bar"} {
+				user := model.User{Name: name, Email: fmt.Sprintf("%s@example.com", name)}
+				if err := s.db.Create(&user).Error; err != nil {
+					t.Fatal(err)
+				}
+
+				users = append(users, &user)
+			}
+
+			if _, err := s.enforcer.AddRoleForUser(fmt.Sprint(users[0].ID), rbac.RootRole); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.run(t, s, users[0], users[1], users[2])
+		})
+	}
+}
+
+// createPersonalAccessToken creates an active personal access token of the user.
+func createPersonalAccessToken(t *testing.T, s *service, user *model.User) *model.PersonalAccessToken {
+	personalAccessToken, err := s.CreatePersonalAccessToken(context.Background(), user.ID, types.CreatePersonalAccessTokenRequest{
+		Name:      user.Name,
+		Scopes:    []string{"jobs"},
+		ExpiredAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return personalAccessToken
+}
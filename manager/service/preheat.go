@@ -18,6 +18,7 @@ package service
 
 import (
 	"context"
+	"sort"
 	"strconv"
 
 	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
@@ -70,18 +71,78 @@ func (s *service) GetV1Preheat(ctx context.Context, rawID string) (*types.GetV1P
 	}
 
 	job := model.Job{}
-	if err := s.db.WithContext(ctx).First(&job, uint(id)).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&job, uint(id)).Error; err != nil {
 		return nil, status.Error(codes.Unknown, err.Error())
 	}
 
+	percentage, nodes := s.getV1PreheatProgress(ctx, job)
 	return &types.GetV1PreheatResponse{
 		ID:         strconv.FormatUint(uint64(job.ID), 10),
 		Status:     convertState(job.State),
 		StartTime:  job.CreatedAt.String(),
 		FinishTime: job.UpdatedAt.String(),
+		Percentage: percentage,
+		Nodes:      nodes,
 	}, nil
 }
 
+// getV1PreheatProgress aggregates progresses reported by schedulers of the preheat group job.
+func (s *service) getV1PreheatProgress(ctx context.Context, job model.Job) (float64, []types.V1PreheatNode) {
+	var percentage float64
+	if job.State == machineryv1tasks.StateSuccess {
+		percentage = 100
+	}
+
+	if s.job == nil || s.job.Job == nil {
+		return percentage, nil
+	}
+
+	progresses, err := s.job.GetPreheatProgresses(ctx, job.TaskID)
+	if err != nil {
+		logger.Warnf("get preheat %d progresses error: %s", job.ID, err.Error())
+		return percentage, nil
+	}
+
+	if job.State != machineryv1tasks.StateSuccess {
+		// Jobs not started have no progress, use count of jobs in group.
+		jobCount := len(progresses)
+		if groupJob, err := s.job.GetGroupJobState(job.TaskID); err == nil && len(groupJob.JobStates) > jobCount {
+			jobCount = len(groupJob.JobStates)
+		}
+
+		var values []*internaljob.PreheatProgress
+		for _, progress := range progresses {
+			values = append(values, progress)
+		}
+		percentage = internaljob.PreheatPercentage(values, jobCount)
+	}
+
+	return percentage, convertPreheatNodes(progresses)
+}
+
+func convertPreheatNodes(progresses map[string]*internaljob.PreheatProgress) []types.V1PreheatNode {
+	var nodes []types.V1PreheatNode
+	for _, progress := range progresses {
+		nodes = append(nodes, types.V1PreheatNode{
+			SchedulerHostname:  progress.SchedulerHostname,
+			SeedPeerHostID:     progress.SeedPeerHostID,
+			URL:                progress.URL,
+			Status:             convertState(progress.State),
+			FinishedPieceCount: progress.FinishedPieceCount,
+			TotalPieceCount:    progress.TotalPieceCount,
+		})
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].SchedulerHostname != nodes[j].SchedulerHostname {
+			return nodes[i].SchedulerHostname < nodes[j].SchedulerHostname
+		}
+		return nodes[i].URL < nodes[j].URL
+	})
+
+	return nodes
+}
+
 func convertState(state string) string {
 	switch state {
 	case machineryv1tasks.StatePending, machineryv1tasks.StateReceived, machineryv1tasks.StateRetry:
@@ -0,0 +1,162 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"gorm.io/gorm"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func (s *service) GetQueuedJob(ctx context.Context, uuid string) (*internaljob.JobInfo, error) {
+	jobInfo, err := s.job.GetJob(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkQueuedJob(ctx, jobInfo); err != nil {
+		return nil, err
+	}
+
+	return jobInfo, nil
+}
+
+func (s *service) GetQueuedJobs(ctx context.Context, q types.GetQueuedJobsQuery) ([]*internaljob.JobInfo, int64, error) {
+	jobInfos, err := s.job.ListJobs(ctx, q.State)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobInfos, err = s.scopeQueuedJobs(ctx, jobInfos)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count := int64(len(jobInfos))
+	offset := (q.Page - 1) * q.PerPage
+	if offset >= len(jobInfos) {
+		return []*internaljob.JobInfo{}, count, nil
+	}
+
+	end := offset + q.PerPage
+	if end > len(jobInfos) {
+		end = len(jobInfos)
+	}
+
+	return jobInfos[offset:end], count, nil
+}
+
+func (s *service) RetryQueuedJob(ctx context.Context, uuid string) (*internaljob.JobInfo, error) {
+	if _, err := s.GetQueuedJob(ctx, uuid); err != nil {
+		return nil, err
+	}
+
+	jobInfo, err := s.job.RetryJob(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if jobInfo.GroupUUID == "" {
+		return jobInfo, nil
+	}
+
+	// Group job is pending again after the job is retried,
+	// restart polling the state of group job.
+	job := model.Job{}
+	if err := s.db.WithContext(ctx).First(&job, model.Job{TaskID: jobInfo.GroupUUID}).Updates(model.Job{
+		State: machineryv1tasks.StatePending,
+	}).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return jobInfo, nil
+		}
+
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), job.ID, job.TaskID)
+
+	return jobInfo, nil
+}
+
+func (s *service) CancelQueuedJob(ctx context.Context, uuid string) (*internaljob.JobInfo, error) {
+	if _, err := s.GetQueuedJob(ctx, uuid); err != nil {
+		return nil, err
+	}
+
+	return s.job.CancelJob(ctx, uuid)
+}
+
+// checkQueuedJob returns an error if the queued job is not in the tenant scope of ctx,
+// queued jobs belong to the tenant of their group jobs, and queued jobs without
+// group jobs are only accessible by requests which can access all tenants.
+func (s *service) checkQueuedJob(ctx context.Context, jobInfo *internaljob.JobInfo) error {
+	scope, ok := TenantScopeFromContext(ctx)
+	if !ok || scope.All {
+		return nil
+	}
+
+	if jobInfo.GroupUUID == "" {
+		return gorm.ErrRecordNotFound
+	}
+
+	return s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&model.Job{}, model.Job{TaskID: jobInfo.GroupUUID}).Error
+}
+
+// scopeQueuedJobs returns the queued jobs in the tenant scope of ctx.
+func (s *service) scopeQueuedJobs(ctx context.Context, jobInfos []*internaljob.JobInfo) ([]*internaljob.JobInfo, error) {
+	scope, ok := TenantScopeFromContext(ctx)
+	if !ok || scope.All {
+		return jobInfos, nil
+	}
+
+	var groupUUIDs []string
+	for _, jobInfo := range jobInfos {
+		if jobInfo.GroupUUID != "" {
+			groupUUIDs = append(groupUUIDs, jobInfo.GroupUUID)
+		}
+	}
+
+	if len(groupUUIDs) == 0 {
+		return []*internaljob.JobInfo{}, nil
+	}
+
+	var taskIDs []string
+	if err := s.db.WithContext(ctx).Model(&model.Job{}).Scopes(scopeTenantResources(ctx)).
+		Where("task_id IN ?", groupUUIDs).Pluck("task_id", &taskIDs).Error; err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		allowed[taskID] = true
+	}
+
+	scopedJobInfos := []*internaljob.JobInfo{}
+	for _, jobInfo := range jobInfos {
+		if allowed[jobInfo.GroupUUID] {
+			scopedJobInfos = append(scopedJobInfos, jobInfo)
+		}
+	}
+
+	return scopedJobInfos, nil
+}
@@ -18,7 +18,12 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"gorm.io/gorm"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 )
@@ -34,6 +39,34 @@ func (s *service) CreateScheduler(ctx context.Context, json types.CreateSchedule
 		SchedulerClusterID: json.SchedulerClusterID,
 	}
 
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&model.SchedulerCluster{}, json.SchedulerClusterID).Error; err != nil {
+		return nil, err
+	}
+
+	// Soft deleted scheduler still holds the unique key of host name in the scheduler cluster,
+	// so it is restored with the attributes of request instead of created.
+	deleted := model.Scheduler{}
+	err := s.db.WithContext(ctx).Unscoped().Where("is_del = ?", 1).First(&deleted, model.Scheduler{
+		HostName:           json.HostName,
+		SchedulerClusterID: json.SchedulerClusterID,
+	}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err == nil {
+		restored, err := s.RestoreScheduler(ctx, deleted.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.db.WithContext(ctx).Model(restored).Updates(scheduler).Error; err != nil {
+			return nil, err
+		}
+
+		return restored, nil
+	}
+
 	if err := s.db.WithContext(ctx).Create(&scheduler).Error; err != nil {
 		return nil, err
 	}
@@ -43,10 +76,63 @@ func (s *service) CreateScheduler(ctx context.Context, json types.CreateSchedule
 
 func (s *service) DestroyScheduler(ctx context.Context, id uint) error {
 	scheduler := model.Scheduler{}
-	if err := s.db.WithContext(ctx).First(&scheduler, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSchedulers(ctx)).First(&scheduler, id).Error; err != nil {
 		return err
 	}
 
+	if err := s.db.WithContext(ctx).Delete(&model.Scheduler{}, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.cache.Delete(
+		ctx,
+		cache.MakeSchedulerCacheKey(scheduler.SchedulerClusterID, scheduler.HostName, scheduler.IP),
+	); err != nil {
+		logger.Warnf("%s refresh cache failed in scheduler cluster %d: %s", scheduler.HostName, scheduler.SchedulerClusterID, err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) RestoreScheduler(ctx context.Context, id uint) (*model.Scheduler, error) {
+	scheduler := model.Scheduler{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantSchedulers(ctx)).First(&scheduler, id).Error; err != nil {
+		return nil, err
+	}
+
+	if scheduler.IsDel == 0 {
+		return &scheduler, nil
+	}
+
+	// Scheduler can not be restored into the deleted scheduler cluster.
+	if err := s.db.WithContext(ctx).First(&model.SchedulerCluster{}, scheduler.SchedulerClusterID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Model(&scheduler).Update("is_del", 0).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Delete(
+		ctx,
+		cache.MakeSchedulerCacheKey(scheduler.SchedulerClusterID, scheduler.HostName, scheduler.IP),
+	); err != nil {
+		logger.Warnf("%s refresh cache failed in scheduler cluster %d: %s", scheduler.HostName, scheduler.SchedulerClusterID, err.Error())
+	}
+
+	return &scheduler, nil
+}
+
+func (s *service) PurgeScheduler(ctx context.Context, id uint) error {
+	scheduler := model.Scheduler{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantSchedulers(ctx)).First(&scheduler, id).Error; err != nil {
+		return err
+	}
+
+	if scheduler.IsDel == 0 {
+		return ErrResourceNotDeleted
+	}
+
 	if err := s.db.WithContext(ctx).Unscoped().Delete(&model.Scheduler{}, id).Error; err != nil {
 		return err
 	}
@@ -56,7 +142,19 @@ func (s *service) DestroyScheduler(ctx context.Context, id uint) error {
 
 func (s *service) UpdateScheduler(ctx context.Context, id uint, json types.UpdateSchedulerRequest) (*model.Scheduler, error) {
 	scheduler := model.Scheduler{}
-	if err := s.db.WithContext(ctx).First(&scheduler, id).Updates(model.Scheduler{
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSchedulers(ctx)).First(&scheduler, id).Error; err != nil {
+		return nil, err
+	}
+
+	if json.SchedulerClusterID != 0 {
+		if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&model.SchedulerCluster{}, json.SchedulerClusterID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Cache key is made by the attributes before updating.
+	cacheKey := cache.MakeSchedulerCacheKey(scheduler.SchedulerClusterID, scheduler.HostName, scheduler.IP)
+	if err := s.db.WithContext(ctx).Model(&scheduler).Updates(model.Scheduler{
 		IDC:                json.IDC,
 		NetTopology:        json.NetTopology,
 		Location:           json.Location,
@@ -67,12 +165,16 @@ func (s *service) UpdateScheduler(ctx context.Context, id uint, json types.Updat
 		return nil, err
 	}
 
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		logger.Warnf("%s refresh cache failed in scheduler cluster %d: %s", scheduler.HostName, scheduler.SchedulerClusterID, err.Error())
+	}
+
 	return &scheduler, nil
 }
 
 func (s *service) GetScheduler(ctx context.Context, id uint) (*model.Scheduler, error) {
 	scheduler := model.Scheduler{}
-	if err := s.db.WithContext(ctx).First(&scheduler, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSchedulers(ctx)).First(&scheduler, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -82,7 +184,7 @@ func (s *service) GetScheduler(ctx context.Context, id uint) (*model.Scheduler,
 func (s *service) GetSchedulers(ctx context.Context, q types.GetSchedulersQuery) ([]model.Scheduler, int64, error) {
 	var count int64
 	var schedulers []model.Scheduler
-	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.Scheduler{
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenantSchedulers(ctx)).Where(&model.Scheduler{
 		HostName:           q.HostName,
 		IDC:                q.IDC,
 		Location:           q.Location,
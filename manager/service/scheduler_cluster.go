@@ -18,14 +18,20 @@ package service
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/structure"
 )
 
 func (s *service) CreateSchedulerCluster(ctx context.Context, json types.CreateSchedulerClusterRequest) (*model.SchedulerCluster, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	config, err := structure.StructToMap(json.Config)
 	if err != nil {
 		return nil, err
@@ -41,13 +47,20 @@ func (s *service) CreateSchedulerCluster(ctx context.Context, json types.CreateS
 		return nil, err
 	}
 
+	features, err := structure.StructToMap(json.Features)
+	if err != nil {
+		return nil, err
+	}
+
 	schedulerCluster := model.SchedulerCluster{
 		Name:         json.Name,
 		BIO:          json.BIO,
 		Config:       config,
 		ClientConfig: clientConfig,
 		Scopes:       scopes,
+		Features:     features,
 		IsDefault:    json.IsDefault,
+		TenantID:     json.TenantID,
 	}
 
 	if err := s.db.WithContext(ctx).Create(&schedulerCluster).Error; err != nil {
@@ -71,12 +84,12 @@ func (s *service) CreateSchedulerCluster(ctx context.Context, json types.CreateS
 
 func (s *service) DestroySchedulerCluster(ctx context.Context, id uint) error {
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).Preload("Schedulers").First(&schedulerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("Schedulers").First(&schedulerCluster, id).Error; err != nil {
 		return err
 	}
 
 	if len(schedulerCluster.Schedulers) != 0 {
-		return errors.New("scheduler cluster exists scheduler")
+		return fmt.Errorf("scheduler cluster exists scheduler: %w", ErrResourceInUse)
 	}
 
 	if err := s.db.WithContext(ctx).Delete(&model.SchedulerCluster{}, id).Error; err != nil {
@@ -86,7 +99,55 @@ func (s *service) DestroySchedulerCluster(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (s *service) RestoreSchedulerCluster(ctx context.Context, id uint) (*model.SchedulerCluster, error) {
+	schedulerCluster := model.SchedulerCluster{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, id).Error; err != nil {
+		return nil, err
+	}
+
+	if schedulerCluster.IsDel == 0 {
+		return &schedulerCluster, nil
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Model(&schedulerCluster).Update("is_del", 0).Error; err != nil {
+		return nil, err
+	}
+
+	return &schedulerCluster, nil
+}
+
+func (s *service) PurgeSchedulerCluster(ctx context.Context, id uint) error {
+	schedulerCluster := model.SchedulerCluster{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, id).Error; err != nil {
+		return err
+	}
+
+	if schedulerCluster.IsDel == 0 {
+		return ErrResourceNotDeleted
+	}
+
+	// Soft deleted schedulers need to be purged first, they may be restored into the cluster.
+	var count int64
+	if err := s.db.WithContext(ctx).Unscoped().Model(&model.Scheduler{}).Where("scheduler_cluster_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count != 0 {
+		return fmt.Errorf("scheduler cluster exists scheduler: %w", ErrResourceInUse)
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Select("SeedPeerClusters", "Jobs").Delete(&schedulerCluster).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json types.UpdateSchedulerClusterRequest) (*model.SchedulerCluster, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	config, err := structure.StructToMap(json.Config)
 	if err != nil {
 		return nil, err
@@ -102,14 +163,21 @@ func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json type
 		return nil, err
 	}
 
+	features, err := structure.StructToMap(json.Features)
+	if err != nil {
+		return nil, err
+	}
+
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, id).Updates(model.SchedulerCluster{
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, id).Updates(model.SchedulerCluster{
 		Name:         json.Name,
 		BIO:          json.BIO,
 		Config:       config,
 		ClientConfig: clientConfig,
 		Scopes:       scopes,
+		Features:     features,
 		IsDefault:    json.IsDefault,
+		TenantID:     json.TenantID,
 	}).Error; err != nil {
 		return nil, err
 	}
@@ -126,12 +194,33 @@ func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json type
 		}
 	}
 
+	// Schedulers of the cluster refresh config and features from manager.
+	s.refreshSchedulersCache(ctx, schedulerCluster.ID)
+
 	return &schedulerCluster, nil
 }
 
+// refreshSchedulersCache deletes the cache of schedulers in the scheduler cluster.
+func (s *service) refreshSchedulersCache(ctx context.Context, schedulerClusterID uint) {
+	var schedulers []model.Scheduler
+	if err := s.db.WithContext(ctx).Find(&schedulers, &model.Scheduler{SchedulerClusterID: schedulerClusterID}).Error; err != nil {
+		logger.Warnf("find schedulers in scheduler cluster %d failed: %s", schedulerClusterID, err.Error())
+		return
+	}
+
+	for _, scheduler := range schedulers {
+		if err := s.cache.Delete(
+			ctx,
+			cache.MakeSchedulerCacheKey(scheduler.SchedulerClusterID, scheduler.HostName, scheduler.IP),
+		); err != nil {
+			logger.Warnf("%s refresh cache failed in scheduler cluster %d: %s", scheduler.HostName, scheduler.SchedulerClusterID, err.Error())
+		}
+	}
+}
+
 func (s *service) GetSchedulerCluster(ctx context.Context, id uint) (*model.SchedulerCluster, error) {
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters").Preload("SecurityGroup").First(&schedulerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("SeedPeerClusters").Preload("SecurityGroup").First(&schedulerCluster, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -141,8 +230,9 @@ func (s *service) GetSchedulerCluster(ctx context.Context, id uint) (*model.Sche
 func (s *service) GetSchedulerClusters(ctx context.Context, q types.GetSchedulerClustersQuery) ([]model.SchedulerCluster, int64, error) {
 	var count int64
 	var schedulerClusters []model.SchedulerCluster
-	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.SchedulerCluster{
-		Name: q.Name,
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenantResources(ctx)).Where(&model.SchedulerCluster{
+		Name:     q.Name,
+		TenantID: q.TenantID,
 	}).Preload("SeedPeerClusters").Preload("SecurityGroup").Find(&schedulerClusters).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
@@ -152,12 +242,12 @@ func (s *service) GetSchedulerClusters(ctx context.Context, q types.GetScheduler
 
 func (s *service) AddSchedulerToSchedulerCluster(ctx context.Context, id, schedulerID uint) error {
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, id).Error; err != nil {
 		return err
 	}
 
 	scheduler := model.Scheduler{}
-	if err := s.db.WithContext(ctx).First(&scheduler, schedulerID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSchedulers(ctx)).First(&scheduler, schedulerID).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,192 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestService_SoftDeleteScheduler(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s *service, scheduler *model.Scheduler)
+	}{
+		{
+			name: "destroy scheduler",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				_, err := s.GetScheduler(context.Background(), scheduler.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+				deleted := model.Scheduler{}
+				assert.NoError(s.db.Unscoped().First(&deleted, scheduler.ID).Error)
+				assert.NotZero(deleted.IsDel)
+			},
+		},
+		{
+			name: "restore scheduler",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				_, err := s.RestoreScheduler(context.Background(), scheduler.ID)
+				assert.NoError(err)
+				_, err = s.GetScheduler(context.Background(), scheduler.ID)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "restore scheduler into deleted scheduler cluster",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				assert.NoError(s.DestroySchedulerCluster(context.Background(), scheduler.SchedulerClusterID))
+				_, err := s.RestoreScheduler(context.Background(), scheduler.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "restore scheduler of other tenant",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				ctx := WithTenantScope(context.Background(), &types.TenantScope{TenantIDs: []uint{2}})
+				_, err := s.RestoreScheduler(ctx, scheduler.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "purge scheduler",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.ErrorIs(s.PurgeScheduler(context.Background(), scheduler.ID), ErrResourceNotDeleted)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				assert.NoError(s.PurgeScheduler(context.Background(), scheduler.ID))
+				assert.ErrorIs(s.db.Unscoped().First(&model.Scheduler{}, scheduler.ID).Error, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "purge scheduler of other tenant",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				ctx := WithTenantScope(context.Background(), &types.TenantScope{TenantIDs: []uint{2}})
+				assert.ErrorIs(s.PurgeScheduler(ctx, scheduler.ID), gorm.ErrRecordNotFound)
+				assert.NoError(s.db.Unscoped().First(&model.Scheduler{}, scheduler.ID).Error)
+			},
+		},
+		{
+			name: "create scheduler after destroyed",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				created, err := s.CreateScheduler(context.Background(), types.CreateSchedulerRequest{
+					HostName:           scheduler.HostName,
+					IP:                 "127.0.0.2",
+					Port:               8002,
+					SchedulerClusterID: scheduler.SchedulerClusterID,
+				})
+				assert.NoError(err)
+				assert.Equal(scheduler.ID, created.ID)
+
+				restored, err := s.GetScheduler(context.Background(), scheduler.ID)
+				assert.NoError(err)
+				assert.Equal("127.0.0.2", restored.IP)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			schedulers := createTenantClusters(t, s.db)
+
+			// Scheduler of tenant 1.
+			tc.run(t, s, &schedulers[1])
+		})
+	}
+}
+
+func TestService_SoftDeleteSchedulerCluster(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s *service, scheduler *model.Scheduler)
+	}{
+		{
+			name: "destroy scheduler cluster in use",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.ErrorIs(s.DestroySchedulerCluster(context.Background(), scheduler.SchedulerClusterID), ErrResourceInUse)
+			},
+		},
+		{
+			name: "restore scheduler cluster",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				assert.NoError(s.DestroySchedulerCluster(context.Background(), scheduler.SchedulerClusterID))
+				_, err := s.GetSchedulerCluster(context.Background(), scheduler.SchedulerClusterID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+				_, err = s.RestoreSchedulerCluster(context.Background(), scheduler.SchedulerClusterID)
+				assert.NoError(err)
+				_, err = s.RestoreScheduler(context.Background(), scheduler.ID)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "purge scheduler cluster not deleted",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.ErrorIs(s.PurgeSchedulerCluster(context.Background(), scheduler.SchedulerClusterID), ErrResourceNotDeleted)
+			},
+		},
+		{
+			name: "purge scheduler cluster with deleted scheduler",
+			run: func(t *testing.T, s *service, scheduler *model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroyScheduler(context.Background(), scheduler.ID))
+				assert.NoError(s.DestroySchedulerCluster(context.Background(), scheduler.SchedulerClusterID))
+				assert.ErrorIs(s.PurgeSchedulerCluster(context.Background(), scheduler.SchedulerClusterID), ErrResourceInUse)
+
+				assert.NoError(s.PurgeScheduler(context.Background(), scheduler.ID))
+				assert.NoError(s.PurgeSchedulerCluster(context.Background(), scheduler.SchedulerClusterID))
+				assert.ErrorIs(s.db.Unscoped().First(&model.SchedulerCluster{}, scheduler.SchedulerClusterID).Error, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			schedulers := createTenantClusters(t, s.db)
+			tc.run(t, s, &schedulers[1])
+		})
+	}
+}
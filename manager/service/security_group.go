@@ -24,9 +24,14 @@ import (
 )
 
 func (s *service) CreateSecurityGroup(ctx context.Context, json types.CreateSecurityGroupRequest) (*model.SecurityGroup, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	securityGroup := model.SecurityGroup{
-		Name: json.Name,
-		BIO:  json.BIO,
+		Name:     json.Name,
+		BIO:      json.BIO,
+		TenantID: json.TenantID,
 	}
 
 	if err := s.db.WithContext(ctx).Create(&securityGroup).Error; err != nil {
@@ -38,7 +43,7 @@ func (s *service) CreateSecurityGroup(ctx context.Context, json types.CreateSecu
 
 func (s *service) DestroySecurityGroup(ctx context.Context, id uint) error {
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).First(&securityGroup, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&securityGroup, id).Error; err != nil {
 		return err
 	}
 
@@ -50,10 +55,15 @@ func (s *service) DestroySecurityGroup(ctx context.Context, id uint) error {
 }
 
 func (s *service) UpdateSecurityGroup(ctx context.Context, id uint, json types.UpdateSecurityGroupRequest) (*model.SecurityGroup, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).First(&securityGroup, id).Updates(model.SecurityGroup{
-		Name: json.Name,
-		BIO:  json.BIO,
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&securityGroup, id).Updates(model.SecurityGroup{
+		Name:     json.Name,
+		BIO:      json.BIO,
+		TenantID: json.TenantID,
 	}).Error; err != nil {
 		return nil, err
 	}
@@ -63,7 +73,7 @@ func (s *service) UpdateSecurityGroup(ctx context.Context, id uint, json types.U
 
 func (s *service) GetSecurityGroup(ctx context.Context, id uint) (*model.SecurityGroup, error) {
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).Preload("SecurityRules").First(&securityGroup, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("SecurityRules").First(&securityGroup, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -73,8 +83,9 @@ func (s *service) GetSecurityGroup(ctx context.Context, id uint) (*model.Securit
 func (s *service) GetSecurityGroups(ctx context.Context, q types.GetSecurityGroupsQuery) ([]model.SecurityGroup, int64, error) {
 	var count int64
 	var securityGroups []model.SecurityGroup
-	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.SecurityGroup{
-		Name: q.Name,
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenantResources(ctx)).Where(&model.SecurityGroup{
+		Name:     q.Name,
+		TenantID: q.TenantID,
 	}).Preload("SecurityRules").Find(&securityGroups).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
@@ -84,12 +95,12 @@ func (s *service) GetSecurityGroups(ctx context.Context, q types.GetSecurityGrou
 
 func (s *service) AddSchedulerClusterToSecurityGroup(ctx context.Context, id, schedulerClusterID uint) error {
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).First(&securityGroup, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&securityGroup, id).Error; err != nil {
 		return err
 	}
 
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
 		return err
 	}
 
@@ -102,12 +113,12 @@ func (s *service) AddSchedulerClusterToSecurityGroup(ctx context.Context, id, sc
 
 func (s *service) AddSeedPeerClusterToSecurityGroup(ctx context.Context, id, seedPeerClusterID uint) error {
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).First(&securityGroup, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&securityGroup, id).Error; err != nil {
 		return err
 	}
 
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, seedPeerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, seedPeerClusterID).Error; err != nil {
 		return err
 	}
 
@@ -120,7 +131,7 @@ func (s *service) AddSeedPeerClusterToSecurityGroup(ctx context.Context, id, see
 
 func (s *service) AddSecurityRuleToSecurityGroup(ctx context.Context, id, securityRuleID uint) error {
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).First(&securityGroup, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&securityGroup, id).Error; err != nil {
 		return err
 	}
 
@@ -138,7 +149,7 @@ func (s *service) AddSecurityRuleToSecurityGroup(ctx context.Context, id, securi
 
 func (s *service) DestroySecurityRuleToSecurityGroup(ctx context.Context, id, securityRuleID uint) error {
 	securityGroup := model.SecurityGroup{}
-	if err := s.db.WithContext(ctx).First(&securityGroup, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&securityGroup, id).Error; err != nil {
 		return err
 	}
 
@@ -20,6 +20,7 @@ import (
 	"context"
 
 	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/searcher"
 	"d7y.io/dragonfly/v2/manager/types"
 )
 
@@ -85,3 +86,26 @@ func (s *service) GetSecurityRules(ctx context.Context, q types.GetSecurityRules
 
 	return securityRules, count, nil
 }
+
+func (s *service) ResolveSecurityRule(ctx context.Context, q types.ResolveSecurityRuleQuery) (*types.ResolvedSecurityRule, error) {
+	var schedulerClusters []model.SchedulerCluster
+	if err := s.db.WithContext(ctx).Preload("SecurityGroup.SecurityRules").Preload("Schedulers", "state = ?", model.SchedulerStateActive).Find(&schedulerClusters).Error; err != nil {
+		return nil, err
+	}
+
+	// Resolve in the same way as searching scheduler clusters for dfdaemon.
+	resolved := types.ResolvedSecurityRule{SecurityDomain: q.SecurityDomain}
+	if securityRule, ok := searcher.ResolveSecurityRule(q.HostName, q.SecurityDomain, searcher.SecurityRulesOfSchedulerClusters(schedulerClusters)); ok {
+		resolved.SecurityDomain = securityRule.Domain
+		resolved.SecurityRuleID = securityRule.ID
+		resolved.SecurityRuleName = securityRule.Name
+	}
+
+	for _, schedulerCluster := range searcher.FilterSchedulerClusters(map[string]string{
+		searcher.ConditionSecurityDomain: resolved.SecurityDomain,
+	}, schedulerClusters) {
+		resolved.SchedulerClusterIDs = append(resolved.SchedulerClusterIDs, schedulerCluster.ID)
+	}
+
+	return &resolved, nil
+}
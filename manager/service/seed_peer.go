@@ -18,7 +18,12 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"gorm.io/gorm"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
 )
@@ -37,6 +42,34 @@ func (s *service) CreateSeedPeer(ctx context.Context, json types.CreateSeedPeerR
 		SeedPeerClusterID: json.SeedPeerClusterID,
 	}
 
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&model.SeedPeerCluster{}, json.SeedPeerClusterID).Error; err != nil {
+		return nil, err
+	}
+
+	// Soft deleted seed peer still holds the unique key of host name in the seed peer cluster,
+	// so it is restored with the attributes of request instead of created.
+	deleted := model.SeedPeer{}
+	err := s.db.WithContext(ctx).Unscoped().Where("is_del = ?", 1).First(&deleted, model.SeedPeer{
+		HostName:          json.HostName,
+		SeedPeerClusterID: json.SeedPeerClusterID,
+	}).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err == nil {
+		restored, err := s.RestoreSeedPeer(ctx, deleted.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.db.WithContext(ctx).Model(restored).Updates(seedPeer).Error; err != nil {
+			return nil, err
+		}
+
+		return restored, nil
+	}
+
 	if err := s.db.WithContext(ctx).Create(&seedPeer).Error; err != nil {
 		return nil, err
 	}
@@ -46,10 +79,63 @@ func (s *service) CreateSeedPeer(ctx context.Context, json types.CreateSeedPeerR
 
 func (s *service) DestroySeedPeer(ctx context.Context, id uint) error {
 	seedPeer := model.SeedPeer{}
-	if err := s.db.WithContext(ctx).First(&seedPeer, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSeedPeers(ctx)).First(&seedPeer, id).Error; err != nil {
 		return err
 	}
 
+	if err := s.db.WithContext(ctx).Delete(&model.SeedPeer{}, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.cache.Delete(
+		ctx,
+		cache.MakeSeedPeerCacheKey(seedPeer.SeedPeerClusterID, seedPeer.HostName, seedPeer.IP),
+	); err != nil {
+		logger.Warnf("%s refresh cache failed in seed peer cluster %d: %s", seedPeer.HostName, seedPeer.SeedPeerClusterID, err.Error())
+	}
+
+	return nil
+}
+
+func (s *service) RestoreSeedPeer(ctx context.Context, id uint) (*model.SeedPeer, error) {
+	seedPeer := model.SeedPeer{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantSeedPeers(ctx)).First(&seedPeer, id).Error; err != nil {
+		return nil, err
+	}
+
+	if seedPeer.IsDel == 0 {
+		return &seedPeer, nil
+	}
+
+	// Seed peer can not be restored into the deleted seed peer cluster.
+	if err := s.db.WithContext(ctx).First(&model.SeedPeerCluster{}, seedPeer.SeedPeerClusterID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Model(&seedPeer).Update("is_del", 0).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.Delete(
+		ctx,
+		cache.MakeSeedPeerCacheKey(seedPeer.SeedPeerClusterID, seedPeer.HostName, seedPeer.IP),
+	); err != nil {
+		logger.Warnf("%s refresh cache failed in seed peer cluster %d: %s", seedPeer.HostName, seedPeer.SeedPeerClusterID, err.Error())
+	}
+
+	return &seedPeer, nil
+}
+
+func (s *service) PurgeSeedPeer(ctx context.Context, id uint) error {
+	seedPeer := model.SeedPeer{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantSeedPeers(ctx)).First(&seedPeer, id).Error; err != nil {
+		return err
+	}
+
+	if seedPeer.IsDel == 0 {
+		return ErrResourceNotDeleted
+	}
+
 	if err := s.db.WithContext(ctx).Unscoped().Delete(&model.SeedPeer{}, id).Error; err != nil {
 		return err
 	}
@@ -59,7 +145,19 @@ func (s *service) DestroySeedPeer(ctx context.Context, id uint) error {
 
 func (s *service) UpdateSeedPeer(ctx context.Context, id uint, json types.UpdateSeedPeerRequest) (*model.SeedPeer, error) {
 	seedPeer := model.SeedPeer{}
-	if err := s.db.WithContext(ctx).First(&seedPeer, id).Updates(model.SeedPeer{
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSeedPeers(ctx)).First(&seedPeer, id).Error; err != nil {
+		return nil, err
+	}
+
+	if json.SeedPeerClusterID != 0 {
+		if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&model.SeedPeerCluster{}, json.SeedPeerClusterID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Cache key is made by the attributes before updating.
+	cacheKey := cache.MakeSeedPeerCacheKey(seedPeer.SeedPeerClusterID, seedPeer.HostName, seedPeer.IP)
+	if err := s.db.WithContext(ctx).Model(&seedPeer).Updates(model.SeedPeer{
 		Type:              json.Type,
 		IDC:               json.IDC,
 		NetTopology:       json.NetTopology,
@@ -73,12 +171,16 @@ func (s *service) UpdateSeedPeer(ctx context.Context, id uint, json types.Update
 		return nil, err
 	}
 
+	if err := s.cache.Delete(ctx, cacheKey); err != nil {
+		logger.Warnf("%s refresh cache failed in seed peer cluster %d: %s", seedPeer.HostName, seedPeer.SeedPeerClusterID, err.Error())
+	}
+
 	return &seedPeer, nil
 }
 
 func (s *service) GetSeedPeer(ctx context.Context, id uint) (*model.SeedPeer, error) {
 	seedPeer := model.SeedPeer{}
-	if err := s.db.WithContext(ctx).First(&seedPeer, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSeedPeers(ctx)).First(&seedPeer, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -88,7 +190,7 @@ func (s *service) GetSeedPeer(ctx context.Context, id uint) (*model.SeedPeer, er
 func (s *service) GetSeedPeers(ctx context.Context, q types.GetSeedPeersQuery) ([]model.SeedPeer, int64, error) {
 	var count int64
 	var seedPeers []model.SeedPeer
-	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.SeedPeer{
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenantSeedPeers(ctx)).Where(&model.SeedPeer{
 		Type:              q.Type,
 		HostName:          q.HostName,
 		IDC:               q.IDC,
@@ -18,7 +18,7 @@ package service
 
 import (
 	"context"
-	"errors"
+	"fmt"
 
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/types"
@@ -26,6 +26,10 @@ import (
 )
 
 func (s *service) CreateSeedPeerCluster(ctx context.Context, json types.CreateSeedPeerClusterRequest) (*model.SeedPeerCluster, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	config, err := structure.StructToMap(json.Config)
 	if err != nil {
 		return nil, err
@@ -42,6 +46,7 @@ func (s *service) CreateSeedPeerCluster(ctx context.Context, json types.CreateSe
 		Config:    config,
 		Scopes:    scopes,
 		IsDefault: json.IsDefault,
+		TenantID:  json.TenantID,
 	}
 
 	if err := s.db.WithContext(ctx).Create(&seedPeerCluster).Error; err != nil {
@@ -53,12 +58,12 @@ func (s *service) CreateSeedPeerCluster(ctx context.Context, json types.CreateSe
 
 func (s *service) DestroySeedPeerCluster(ctx context.Context, id uint) error {
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).Preload("SeedPeers").First(&seedPeerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("SeedPeers").First(&seedPeerCluster, id).Error; err != nil {
 		return err
 	}
 
 	if len(seedPeerCluster.SeedPeers) != 0 {
-		return errors.New("seedPeer cluster exists seedPeer")
+		return fmt.Errorf("seedPeer cluster exists seedPeer: %w", ErrResourceInUse)
 	}
 
 	if err := s.db.WithContext(ctx).Delete(&model.SeedPeerCluster{}, id).Error; err != nil {
@@ -68,7 +73,55 @@ func (s *service) DestroySeedPeerCluster(ctx context.Context, id uint) error {
 	return nil
 }
 
+func (s *service) RestoreSeedPeerCluster(ctx context.Context, id uint) (*model.SeedPeerCluster, error) {
+	seedPeerCluster := model.SeedPeerCluster{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, id).Error; err != nil {
+		return nil, err
+	}
+
+	if seedPeerCluster.IsDel == 0 {
+		return &seedPeerCluster, nil
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Model(&seedPeerCluster).Update("is_del", 0).Error; err != nil {
+		return nil, err
+	}
+
+	return &seedPeerCluster, nil
+}
+
+func (s *service) PurgeSeedPeerCluster(ctx context.Context, id uint) error {
+	seedPeerCluster := model.SeedPeerCluster{}
+	if err := s.db.WithContext(ctx).Unscoped().Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, id).Error; err != nil {
+		return err
+	}
+
+	if seedPeerCluster.IsDel == 0 {
+		return ErrResourceNotDeleted
+	}
+
+	// Soft deleted seed peers need to be purged first, they may be restored into the cluster.
+	var count int64
+	if err := s.db.WithContext(ctx).Unscoped().Model(&model.SeedPeer{}).Where("seed_peer_cluster_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count != 0 {
+		return fmt.Errorf("seedPeer cluster exists seedPeer: %w", ErrResourceInUse)
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Select("SchedulerClusters", "Jobs").Delete(&seedPeerCluster).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *service) UpdateSeedPeerCluster(ctx context.Context, id uint, json types.UpdateSeedPeerClusterRequest) (*model.SeedPeerCluster, error) {
+	if err := s.checkTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	config, err := structure.StructToMap(json.Config)
 	if err != nil {
 		return nil, err
@@ -80,12 +133,13 @@ func (s *service) UpdateSeedPeerCluster(ctx context.Context, id uint, json types
 	}
 
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, id).Updates(model.SeedPeerCluster{
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, id).Updates(model.SeedPeerCluster{
 		Name:      json.Name,
 		BIO:       json.BIO,
 		Config:    config,
 		Scopes:    scopes,
 		IsDefault: json.IsDefault,
+		TenantID:  json.TenantID,
 	}).Error; err != nil {
 		return nil, err
 	}
@@ -95,7 +149,7 @@ func (s *service) UpdateSeedPeerCluster(ctx context.Context, id uint, json types
 
 func (s *service) GetSeedPeerCluster(ctx context.Context, id uint) (*model.SeedPeerCluster, error) {
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -105,8 +159,9 @@ func (s *service) GetSeedPeerCluster(ctx context.Context, id uint) (*model.SeedP
 func (s *service) GetSeedPeerClusters(ctx context.Context, q types.GetSeedPeerClustersQuery) ([]model.SeedPeerCluster, int64, error) {
 	var count int64
 	var seedPeerClusters []model.SeedPeerCluster
-	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage)).Where(&model.SeedPeerCluster{
-		Name: q.Name,
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenantResources(ctx)).Where(&model.SeedPeerCluster{
+		Name:     q.Name,
+		TenantID: q.TenantID,
 	}).Find(&seedPeerClusters).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
@@ -116,12 +171,12 @@ func (s *service) GetSeedPeerClusters(ctx context.Context, q types.GetSeedPeerCl
 
 func (s *service) AddSeedPeerToSeedPeerCluster(ctx context.Context, id, seedPeerID uint) error {
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, id).Error; err != nil {
 		return err
 	}
 
 	seedPeer := model.SeedPeer{}
-	if err := s.db.WithContext(ctx).First(&seedPeer, seedPeerID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantSeedPeers(ctx)).First(&seedPeer, seedPeerID).Error; err != nil {
 		return err
 	}
 
@@ -134,12 +189,12 @@ func (s *service) AddSeedPeerToSeedPeerCluster(ctx context.Context, id, seedPeer
 
 func (s *service) AddSchedulerClusterToSeedPeerCluster(ctx context.Context, id, schedulerClusterID uint) error {
 	seedPeerCluster := model.SeedPeerCluster{}
-	if err := s.db.WithContext(ctx).First(&seedPeerCluster, id).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&seedPeerCluster, id).Error; err != nil {
 		return err
 	}
 
 	schedulerCluster := model.SchedulerCluster{}
-	if err := s.db.WithContext(ctx).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).First(&schedulerCluster, schedulerClusterID).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,141 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestService_SoftDeleteSeedPeer(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, s *service, seedPeer *model.SeedPeer)
+	}{
+		{
+			name: "destroy seed peer cluster in use",
+			run: func(t *testing.T, s *service, seedPeer *model.SeedPeer) {
+				assert := assert.New(t)
+				assert.ErrorIs(s.DestroySeedPeerCluster(context.Background(), seedPeer.SeedPeerClusterID), ErrResourceInUse)
+			},
+		},
+		{
+			name: "restore seed peer",
+			run: func(t *testing.T, s *service, seedPeer *model.SeedPeer) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroySeedPeer(context.Background(), seedPeer.ID))
+				_, err := s.GetSeedPeer(context.Background(), seedPeer.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+
+				_, err = s.RestoreSeedPeer(context.Background(), seedPeer.ID)
+				assert.NoError(err)
+				_, err = s.GetSeedPeer(context.Background(), seedPeer.ID)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "restore seed peer into deleted seed peer cluster",
+			run: func(t *testing.T, s *service, seedPeer *model.SeedPeer) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroySeedPeer(context.Background(), seedPeer.ID))
+				assert.NoError(s.DestroySeedPeerCluster(context.Background(), seedPeer.SeedPeerClusterID))
+				_, err := s.RestoreSeedPeer(context.Background(), seedPeer.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "restore and purge seed peer of other tenant",
+			run: func(t *testing.T, s *service, seedPeer *model.SeedPeer) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroySeedPeer(context.Background(), seedPeer.ID))
+				ctx := WithTenantScope(context.Background(), &types.TenantScope{TenantIDs: []uint{2}})
+				_, err := s.RestoreSeedPeer(ctx, seedPeer.ID)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+				assert.ErrorIs(s.PurgeSeedPeer(ctx, seedPeer.ID), gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "purge seed peer and seed peer cluster",
+			run: func(t *testing.T, s *service, seedPeer *model.SeedPeer) {
+				assert := assert.New(t)
+				assert.ErrorIs(s.PurgeSeedPeer(context.Background(), seedPeer.ID), ErrResourceNotDeleted)
+				assert.NoError(s.DestroySeedPeer(context.Background(), seedPeer.ID))
+				assert.NoError(s.DestroySeedPeerCluster(context.Background(), seedPeer.SeedPeerClusterID))
+				assert.ErrorIs(s.PurgeSeedPeerCluster(context.Background(), seedPeer.SeedPeerClusterID), ErrResourceInUse)
+
+				assert.NoError(s.PurgeSeedPeer(context.Background(), seedPeer.ID))
+				assert.NoError(s.PurgeSeedPeerCluster(context.Background(), seedPeer.SeedPeerClusterID))
+				assert.ErrorIs(s.db.Unscoped().First(&model.SeedPeerCluster{}, seedPeer.SeedPeerClusterID).Error, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "create seed peer after destroyed",
+			run: func(t *testing.T, s *service, seedPeer *model.SeedPeer) {
+				assert := assert.New(t)
+				assert.NoError(s.DestroySeedPeer(context.Background(), seedPeer.ID))
+				created, err := s.CreateSeedPeer(context.Background(), types.CreateSeedPeerRequest{
+					HostName:          seedPeer.HostName,
+					Type:              model.SeedPeerTypeSuperSeed,
+					IP:                "127.0.0.2",
+					Port:              65006,
+					DownloadPort:      65002,
+					SeedPeerClusterID: seedPeer.SeedPeerClusterID,
+				})
+				assert.NoError(err)
+				assert.Equal(seedPeer.ID, created.ID)
+
+				restored, err := s.GetSeedPeer(context.Background(), seedPeer.ID)
+				assert.NoError(err)
+				assert.Equal("127.0.0.2", restored.IP)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+
+			seedPeerCluster := model.SeedPeerCluster{Name: "foo", Config: model.JSONMap{}, TenantID: 1}
+			if err := s.db.Create(&seedPeerCluster).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			seedPeer := model.SeedPeer{
+				HostName:          "bar",
+				Type:              model.SeedPeerTypeSuperSeed,
+				IP:                "127.0.0.1",
+				Port:              65006,
+				DownloadPort:      65002,
+				SeedPeerClusterID: seedPeerCluster.ID,
+			}
+			if err := s.db.Create(&seedPeer).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			tc.run(t, s, &seedPeer)
+		})
+	}
+}
@@ -24,8 +24,12 @@ import (
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"gorm.io/gorm"
 
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	managerldap "d7y.io/dragonfly/v2/manager/auth/ldap"
+	manageroidc "d7y.io/dragonfly/v2/manager/auth/oidc"
 	"d7y.io/dragonfly/v2/manager/cache"
 	"d7y.io/dragonfly/v2/manager/database"
 	"d7y.io/dragonfly/v2/manager/job"
@@ -43,6 +47,9 @@ type Service interface {
 	SignUp(context.Context, types.SignUpRequest) (*model.User, error)
 	OauthSignin(context.Context, string) (string, error)
 	OauthSigninCallback(context.Context, string, string) (*model.User, error)
+	OIDCSignin(context.Context, string) (string, error)
+	OIDCSigninCallback(context.Context, string, string) (*model.User, error)
+	SyncLDAPUsers(context.Context) error
 	ResetPassword(context.Context, uint, types.ResetPasswordRequest) error
 	GetRolesForUser(context.Context, uint) ([]string, error)
 	AddRoleForUser(context.Context, types.AddRoleForUserParams) (bool, error)
@@ -63,8 +70,17 @@ type Service interface {
 	GetOauth(context.Context, uint) (*model.Oauth, error)
 	GetOauths(context.Context, types.GetOauthsQuery) ([]model.Oauth, int64, error)
 
+	CreatePersonalAccessToken(context.Context, uint, types.CreatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error)
+	DestroyPersonalAccessToken(context.Context, uint, uint) error
+	UpdatePersonalAccessToken(context.Context, uint, uint, types.UpdatePersonalAccessTokenRequest) (*model.PersonalAccessToken, error)
+	GetPersonalAccessToken(context.Context, uint, uint) (*model.PersonalAccessToken, error)
+	GetPersonalAccessTokens(context.Context, uint, types.GetPersonalAccessTokensQuery) ([]model.PersonalAccessToken, int64, error)
+	AuthenticatePersonalAccessToken(context.Context, string) (*model.PersonalAccessToken, error)
+
 	CreateSeedPeerCluster(context.Context, types.CreateSeedPeerClusterRequest) (*model.SeedPeerCluster, error)
 	DestroySeedPeerCluster(context.Context, uint) error
+	RestoreSeedPeerCluster(context.Context, uint) (*model.SeedPeerCluster, error)
+	PurgeSeedPeerCluster(context.Context, uint) error
 	UpdateSeedPeerCluster(context.Context, uint, types.UpdateSeedPeerClusterRequest) (*model.SeedPeerCluster, error)
 	GetSeedPeerCluster(context.Context, uint) (*model.SeedPeerCluster, error)
 	GetSeedPeerClusters(context.Context, types.GetSeedPeerClustersQuery) ([]model.SeedPeerCluster, int64, error)
@@ -73,14 +89,20 @@ type Service interface {
 
 	CreateSeedPeer(context.Context, types.CreateSeedPeerRequest) (*model.SeedPeer, error)
 	DestroySeedPeer(context.Context, uint) error
+	RestoreSeedPeer(context.Context, uint) (*model.SeedPeer, error)
+	PurgeSeedPeer(context.Context, uint) error
 	UpdateSeedPeer(context.Context, uint, types.UpdateSeedPeerRequest) (*model.SeedPeer, error)
 	GetSeedPeer(context.Context, uint) (*model.SeedPeer, error)
 	GetSeedPeers(context.Context, types.GetSeedPeersQuery) ([]model.SeedPeer, int64, error)
 
 	GetPeers(context.Context) ([]string, error)
 
+	GetClusterStats(context.Context, uint) (*types.ClusterStats, error)
+
 	CreateSchedulerCluster(context.Context, types.CreateSchedulerClusterRequest) (*model.SchedulerCluster, error)
 	DestroySchedulerCluster(context.Context, uint) error
+	RestoreSchedulerCluster(context.Context, uint) (*model.SchedulerCluster, error)
+	PurgeSchedulerCluster(context.Context, uint) error
 	UpdateSchedulerCluster(context.Context, uint, types.UpdateSchedulerClusterRequest) (*model.SchedulerCluster, error)
 	GetSchedulerCluster(context.Context, uint) (*model.SchedulerCluster, error)
 	GetSchedulerClusters(context.Context, types.GetSchedulerClustersQuery) ([]model.SchedulerCluster, int64, error)
@@ -88,6 +110,8 @@ type Service interface {
 
 	CreateScheduler(context.Context, types.CreateSchedulerRequest) (*model.Scheduler, error)
 	DestroyScheduler(context.Context, uint) error
+	RestoreScheduler(context.Context, uint) (*model.Scheduler, error)
+	PurgeScheduler(context.Context, uint) error
 	UpdateScheduler(context.Context, uint, types.UpdateSchedulerRequest) (*model.Scheduler, error)
 	GetScheduler(context.Context, uint) (*model.Scheduler, error)
 	GetSchedulers(context.Context, types.GetSchedulersQuery) ([]model.Scheduler, int64, error)
@@ -97,6 +121,13 @@ type Service interface {
 	UpdateSecurityRule(context.Context, uint, types.UpdateSecurityRuleRequest) (*model.SecurityRule, error)
 	GetSecurityRule(context.Context, uint) (*model.SecurityRule, error)
 	GetSecurityRules(context.Context, types.GetSecurityRulesQuery) ([]model.SecurityRule, int64, error)
+	ResolveSecurityRule(context.Context, types.ResolveSecurityRuleQuery) (*types.ResolvedSecurityRule, error)
+
+	CreateBlocklist(context.Context, types.CreateBlocklistRequest) (*model.Blocklist, error)
+	DestroyBlocklist(context.Context, uint) error
+	UpdateBlocklist(context.Context, uint, types.UpdateBlocklistRequest) (*model.Blocklist, error)
+	GetBlocklist(context.Context, uint) (*model.Blocklist, error)
+	GetBlocklists(context.Context, types.GetBlocklistsQuery) ([]model.Blocklist, int64, error)
 
 	CreateSecurityGroup(context.Context, types.CreateSecurityGroupRequest) (*model.SecurityGroup, error)
 	DestroySecurityGroup(context.Context, uint) error
@@ -120,14 +151,31 @@ type Service interface {
 	GetConfigs(context.Context, types.GetConfigsQuery) ([]model.Config, int64, error)
 
 	CreatePreheatJob(context.Context, types.CreatePreheatJobRequest) (*model.Job, error)
+	CreateDeleteTaskJob(context.Context, types.CreateDeleteTaskJobRequest) (*model.Job, error)
 	DestroyJob(context.Context, uint) error
 	UpdateJob(context.Context, uint, types.UpdateJobRequest) (*model.Job, error)
 	GetJob(context.Context, uint) (*model.Job, error)
 	GetJobs(context.Context, types.GetJobsQuery) ([]model.Job, int64, error)
 
+	GetQueuedJob(context.Context, string) (*internaljob.JobInfo, error)
+	GetQueuedJobs(context.Context, types.GetQueuedJobsQuery) ([]*internaljob.JobInfo, int64, error)
+	RetryQueuedJob(context.Context, string) (*internaljob.JobInfo, error)
+	CancelQueuedJob(context.Context, string) (*internaljob.JobInfo, error)
+
 	CreateV1Preheat(context.Context, types.CreateV1PreheatRequest) (*types.CreateV1PreheatResponse, error)
 	GetV1Preheat(context.Context, string) (*types.GetV1PreheatResponse, error)
 
+	DestroyTask(context.Context, types.TaskParams, types.DestroyTaskQuery) (*model.Job, error)
+
+	CreateTenant(context.Context, types.CreateTenantRequest) (*model.Tenant, error)
+	DestroyTenant(context.Context, uint) error
+	UpdateTenant(context.Context, uint, types.UpdateTenantRequest) (*model.Tenant, error)
+	GetTenant(context.Context, uint) (*model.Tenant, error)
+	GetTenants(context.Context, types.GetTenantsQuery) ([]model.Tenant, int64, error)
+	AddUserToTenant(context.Context, uint, uint) error
+	DeleteUserToTenant(context.Context, uint, uint) error
+	GetTenantScope(context.Context, uint) (*types.TenantScope, error)
+
 	CreateApplication(context.Context, types.CreateApplicationRequest) (*model.Application, error)
 	DestroyApplication(context.Context, uint) error
 	UpdateApplication(context.Context, uint, types.UpdateApplicationRequest) (*model.Application, error)
@@ -158,10 +206,13 @@ type service struct {
 	job           *job.Job
 	enforcer      *casbin.Enforcer
 	objectStorage objectstorage.ObjectStorage
+	oidc          manageroidc.OIDC
+	ldap          managerldap.LDAP
+	prometheus    promv1.API
 }
 
 // NewREST returns a new REST instence
-func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer *casbin.Enforcer, objectStorage objectstorage.ObjectStorage) Service {
+func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer *casbin.Enforcer, objectStorage objectstorage.ObjectStorage, oidc manageroidc.OIDC, ldap managerldap.LDAP, prometheus promv1.API) Service {
 	return &service{
 		db:            database.DB,
 		rdb:           database.RDB,
@@ -169,5 +220,8 @@ func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer
 		job:           job,
 		enforcer:      enforcer,
 		objectStorage: objectStorage,
+		oidc:          oidc,
+		ldap:          ldap,
+		prometheus:    prometheus,
 	}
 }
@@ -0,0 +1,99 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	machineryv1config "github.com/RichardKnop/machinery/v1/config"
+	gocache "github.com/go-redis/cache/v8"
+	"github.com/golang/mock/gomock"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/cache"
+	"d7y.io/dragonfly/v2/manager/job"
+	jobmocks "d7y.io/dragonfly/v2/manager/job/mocks"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+)
+
+// newTestService returns the service with in-memory database and mock jobs.
+func newTestService(t *testing.T, ctl *gomock.Controller) (*service, *jobmocks.MockTask) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// In-memory database is shared by the only connection.
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(
+		&model.Job{},
+		&model.SeedPeerCluster{},
+		&model.SeedPeer{},
+		&model.SchedulerCluster{},
+		&model.Scheduler{},
+		&model.SecurityRule{},
+		&model.SecurityGroup{},
+		&model.User{},
+		&model.Oauth{},
+		&model.PersonalAccessToken{},
+		&model.Config{},
+		&model.Application{},
+		&model.Blocklist{},
+		&model.Tenant{},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	enforcer, err := rbac.NewEnforcer(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, err := machinery.NewServer(&machineryv1config.Config{
+		Broker:        "eager",
+		DefaultQueue:  internaljob.GlobalQueue.String(),
+		ResultBackend: "eager",
+		Lock:          "eager",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task := jobmocks.NewMockTask(ctl)
+	return &service{
+		db:       db,
+		cache:    &cache.Cache{Cache: gocache.New(&gocache.Options{LocalCache: gocache.NewTinyLFU(100, time.Minute)})},
+		enforcer: enforcer,
+		job: &job.Job{
+			Job:     &internaljob.Job{Server: server},
+			Preheat: jobmocks.NewMockPreheat(ctl),
+			Task:    task,
+		},
+	}, task
+}
@@ -0,0 +1,140 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	prommodel "github.com/prometheus/common/model"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/cache"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+const (
+	// clusterTrafficPeriod is the time range of recent traffic in cluster stats.
+	clusterTrafficPeriod = time.Hour
+
+	// trafficTypeLabel is the label of traffic type in scheduler traffic metrics.
+	trafficTypeLabel = "type"
+
+	// trafficP2PType is the p2p type of scheduler traffic metrics.
+	trafficP2PType = "p2p"
+
+	// trafficBackToSourceType is the back-to-source type of scheduler traffic metrics.
+	trafficBackToSourceType = "back_to_source"
+)
+
+func (s *service) GetClusterStats(ctx context.Context, id uint) (*types.ClusterStats, error) {
+	schedulerCluster := model.SchedulerCluster{}
+	if err := s.db.WithContext(ctx).Scopes(scopeTenantResources(ctx)).Preload("SeedPeerClusters").Preload("Schedulers").First(&schedulerCluster, id).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &types.ClusterStats{ID: schedulerCluster.ID}
+	var schedulerIPs []string
+	for _, scheduler := range schedulerCluster.Schedulers {
+		stats.SchedulerCount++
+		if scheduler.State == model.SchedulerStateActive {
+			stats.ActiveSchedulerCount++
+			schedulerIPs = append(schedulerIPs, scheduler.IP)
+		}
+	}
+
+	if len(schedulerCluster.SeedPeerClusters) > 0 {
+		var seedPeerClusterIDs []uint
+		for _, seedPeerCluster := range schedulerCluster.SeedPeerClusters {
+			seedPeerClusterIDs = append(seedPeerClusterIDs, seedPeerCluster.ID)
+		}
+
+		var seedPeers []model.SeedPeer
+		if err := s.db.WithContext(ctx).Select("state").Where("seed_peer_cluster_id IN ?", seedPeerClusterIDs).Find(&seedPeers).Error; err != nil {
+			return nil, err
+		}
+
+		for _, seedPeer := range seedPeers {
+			stats.SeedPeerCount++
+			if seedPeer.State == model.SeedPeerStateActive {
+				stats.ActiveSeedPeerCount++
+			}
+		}
+	}
+
+	// Active peers are stored in cache by scheduler cluster when listing schedulers.
+	iter := s.rdb.Scan(ctx, 0, cache.MakeCacheKey(cache.ClusterPeerNamespace, fmt.Sprintf("%d-*", schedulerCluster.ID)), 0).Iterator()
+	for iter.Next(ctx) {
+		stats.PeerCount++
+	}
+
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.prometheus != nil && len(schedulerIPs) > 0 {
+		traffic, err := s.getClusterTraffic(ctx, schedulerIPs)
+		if err != nil {
+			logger.Warnf("get traffic of scheduler cluster %d failed: %s", schedulerCluster.ID, err.Error())
+		} else {
+			stats.Traffic = traffic
+		}
+	}
+
+	return stats, nil
+}
+
+// getClusterTraffic queries recent traffic reported by schedulers from prometheus,
+// scheduler metrics are matched by ip of the scrape instance.
+func (s *service) getClusterTraffic(ctx context.Context, schedulerIPs []string) (*types.ClusterTraffic, error) {
+	value, _, err := s.prometheus.Query(ctx, makeClusterTrafficQuery(schedulerIPs, clusterTrafficPeriod), time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	vector, ok := value.(prommodel.Vector)
+	if !ok {
+		return nil, fmt.Errorf("invalid query result type %s", value.Type())
+	}
+
+	traffic := &types.ClusterTraffic{Period: clusterTrafficPeriod.String()}
+	for _, sample := range vector {
+		switch sample.Metric[trafficTypeLabel] {
+		case trafficP2PType:
+			traffic.P2P = float64(sample.Value)
+		case trafficBackToSourceType:
+			traffic.BackToSource = float64(sample.Value)
+		}
+	}
+
+	return traffic, nil
+}
+
+// makeClusterTrafficQuery makes the promql of traffic increase of schedulers in period.
+func makeClusterTrafficQuery(schedulerIPs []string, period time.Duration) string {
+	var ips []string
+	for _, ip := range schedulerIPs {
+		ips = append(ips, regexp.QuoteMeta(ip))
+	}
+
+	return fmt.Sprintf("sum by (%s) (increase(dragonfly_scheduler_traffic{instance=~`(%s):.*`}[%s]))",
+		trafficTypeLabel, strings.Join(ips, "|"), prommodel.Duration(period))
+}
@@ -0,0 +1,37 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// DestroyTask creates a job to evict the task from schedulers and seed peers.
+func (s *service) DestroyTask(ctx context.Context, params types.TaskParams, query types.DestroyTaskQuery) (*model.Job, error) {
+	return s.CreateDeleteTaskJob(ctx, types.CreateDeleteTaskJobRequest{
+		Type: internaljob.DeleteTaskJob,
+		Args: types.DeleteTaskArgs{
+			TaskID: params.TaskID,
+		},
+		SchedulerClusterIDs: query.SchedulerClusterIDs,
+		TenantID:            query.TenantID,
+	})
+}
@@ -0,0 +1,120 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	jobmocks "d7y.io/dragonfly/v2/manager/job/mocks"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestService_DestroyTask(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  types.DestroyTaskQuery
+		mock   func(mt *jobmocks.MockTaskMockRecorder)
+		expect func(t *testing.T, s *service, job *model.Job, err error)
+	}{
+		{
+			name:  "destroy task in all active schedulers of all scheduler clusters",
+			query: types.DestroyTaskQuery{},
+			mock: func(mt *jobmocks.MockTaskMockRecorder) {
+				mt.CreateDeleteTask(gomock.Any(), gomock.Any(), types.DeleteTaskArgs{TaskID: "foo"}).DoAndReturn(
+					func(ctx context.Context, schedulers []model.Scheduler, args types.DeleteTaskArgs) (*internaljob.GroupJobState, error) {
+						if len(schedulers) != 2 || schedulers[0].HostName != "bar" || schedulers[1].HostName != "qux" {
+							return nil, errors.New("unexpected schedulers")
+						}
+
+						return &internaljob.GroupJobState{GroupUUID: "baz", State: machineryv1tasks.StatePending}, nil
+					}).Times(1)
+			},
+			expect: func(t *testing.T, s *service, job *model.Job, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("baz", job.TaskID)
+				assert.Equal(internaljob.DeleteTaskJob, job.Type)
+				assert.Equal(machineryv1tasks.StatePending, job.State)
+				assert.Len(job.SchedulerClusters, 1)
+				assert.NoError(s.db.First(&model.Job{}, job.ID).Error)
+			},
+		},
+		{
+			name:  "scheduler cluster not found",
+			query: types.DestroyTaskQuery{SchedulerClusterIDs: []uint{100}},
+			mock:  func(mt *jobmocks.MockTaskMockRecorder) {},
+			expect: func(t *testing.T, s *service, job *model.Job, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "create delete task job failed",
+			query: types.DestroyTaskQuery{SchedulerClusterIDs: []uint{1}},
+			mock: func(mt *jobmocks.MockTaskMockRecorder) {
+				mt.CreateDeleteTask(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, s *service, job *model.Job, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+
+				var count int64
+				assert.NoError(s.db.Model(&model.Job{}).Count(&count).Error)
+				assert.Equal(int64(0), count)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, task := newTestService(t, ctl)
+
+			schedulerCluster := model.SchedulerCluster{Name: "foo", Config: model.JSONMap{}, ClientConfig: model.JSONMap{}}
+			if err := s.db.Create(&schedulerCluster).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			for _, scheduler := range []model.Scheduler{
+				{HostName: "bar", State: model.SchedulerStateActive},
+				{HostName: "baz", State: model.SchedulerStateInactive},
+				{HostName: "qux", State: model.SchedulerStateActive},
+			} {
+				scheduler.IP = "127.0.0.1"
+				scheduler.Port = 8002
+				scheduler.SchedulerClusterID = schedulerCluster.ID
+				if err := s.db.Create(&scheduler).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tc.mock(task.EXPECT())
+			job, err := s.DestroyTask(context.Background(), types.TaskParams{TaskID: "foo"}, tc.query)
+			tc.expect(t, s, job, err)
+		})
+	}
+}
@@ -0,0 +1,251 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// tenantScopeContextKey is the context key of tenant scope.
+type tenantScopeContextKey struct{}
+
+// WithTenantScope returns a copy of ctx carrying the tenant scope of the request user,
+// resources of the service are limited to the scope.
+func WithTenantScope(ctx context.Context, scope *types.TenantScope) context.Context {
+	return context.WithValue(ctx, tenantScopeContextKey{}, scope)
+}
+
+// TenantScopeFromContext returns the tenant scope carried by ctx.
+func TenantScopeFromContext(ctx context.Context) (*types.TenantScope, bool) {
+	scope, ok := ctx.Value(tenantScopeContextKey{}).(*types.TenantScope)
+	if !ok || scope == nil {
+		return nil, false
+	}
+
+	return scope, true
+}
+
+// scopeTenantResources limits the query to resources of the tenants in the scope of ctx.
+// Resources not assigned to any tenant are shared by all tenants,
+// and requests without scope, e.g. rpc requests, can access all resources.
+func scopeTenantResources(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, ok := TenantScopeFromContext(ctx)
+		if !ok || scope.All {
+			return db
+		}
+
+		return db.Where("tenant_id = ? OR tenant_id IN ?", 0, scope.TenantIDs)
+	}
+}
+
+// scopeTenantSchedulers limits the query of schedulers to the scheduler clusters in the scope of ctx.
+func scopeTenantSchedulers(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return scopeTenantClusterResources(ctx, "scheduler_cluster_id", &model.SchedulerCluster{})
+}
+
+// scopeTenantSeedPeers limits the query of seed peers to the seed peer clusters in the scope of ctx.
+func scopeTenantSeedPeers(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return scopeTenantClusterResources(ctx, "seed_peer_cluster_id", &model.SeedPeerCluster{})
+}
+
+// scopeTenantClusterResources limits the query of cluster members to the clusters in the scope of ctx,
+// members of deleted clusters are in the scope of the deleted clusters.
+func scopeTenantClusterResources(ctx context.Context, column string, cluster any) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, ok := TenantScopeFromContext(ctx)
+		if !ok || scope.All {
+			return db
+		}
+
+		clusterIDs := db.Session(&gorm.Session{NewDB: true}).Unscoped().Model(cluster).
+			Where("tenant_id = ? OR tenant_id IN ?", 0, scope.TenantIDs).Select("id")
+		return db.Where(fmt.Sprintf("%s IN (?)", column), clusterIDs)
+	}
+}
+
+// scopeTenant limits the query to resources of the tenant and the shared ones,
+// zero tenant id limits the query to the shared resources.
+func scopeTenant(tenantID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if tenantID == 0 {
+			return db.Where("tenant_id = ?", 0)
+		}
+
+		return db.Where("tenant_id IN ?", []uint{0, tenantID})
+	}
+}
+
+// scopeTenants limits the query to tenants in the scope of ctx.
+func scopeTenants(ctx context.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope, ok := TenantScopeFromContext(ctx)
+		if !ok || scope.All {
+			return db
+		}
+
+		return db.Where("id IN ?", scope.TenantIDs)
+	}
+}
+
+// checkTenant returns an error if resources can not be assigned to the tenant in the scope of ctx.
+func (s *service) checkTenant(ctx context.Context, tenantID uint) error {
+	if tenantID == 0 {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).Scopes(scopeTenants(ctx)).First(&model.Tenant{}, tenantID).Error
+}
+
+func (s *service) GetTenantScope(ctx context.Context, userID uint) (*types.TenantScope, error) {
+	isRoot, err := s.enforcer.HasRoleForUser(fmt.Sprint(userID), rbac.RootRole)
+	if err != nil {
+		return nil, err
+	}
+
+	if isRoot {
+		return &types.TenantScope{All: true}, nil
+	}
+
+	tenantIDs := []uint{}
+	if err := s.db.WithContext(ctx).Table("tenant_user").Where("user_id = ?", userID).Pluck("tenant_id", &tenantIDs).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.TenantScope{TenantIDs: tenantIDs}, nil
+}
+
+func (s *service) CreateTenant(ctx context.Context, json types.CreateTenantRequest) (*model.Tenant, error) {
+	tenant := model.Tenant{
+		Name: json.Name,
+		BIO:  json.BIO,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&tenant).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *service) DestroyTenant(ctx context.Context, id uint) error {
+	tenant := model.Tenant{}
+	if err := s.db.WithContext(ctx).Scopes(scopeTenants(ctx)).First(&tenant, id).Error; err != nil {
+		return err
+	}
+
+	for name, m := range map[string]any{
+		"scheduler cluster": &model.SchedulerCluster{},
+		"seed peer cluster": &model.SeedPeerCluster{},
+		"security group":    &model.SecurityGroup{},
+	} {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(m).Where("tenant_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count != 0 {
+			return fmt.Errorf("tenant exists %s", name)
+		}
+	}
+
+	if err := s.db.WithContext(ctx).Model(&tenant).Association("Users").Clear(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&model.Tenant{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdateTenant(ctx context.Context, id uint, json types.UpdateTenantRequest) (*model.Tenant, error) {
+	tenant := model.Tenant{}
+	if err := s.db.WithContext(ctx).Scopes(scopeTenants(ctx)).Preload("Users").First(&tenant, id).Updates(model.Tenant{
+		Name: json.Name,
+		BIO:  json.BIO,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *service) GetTenant(ctx context.Context, id uint) (*model.Tenant, error) {
+	tenant := model.Tenant{}
+	if err := s.db.WithContext(ctx).Scopes(scopeTenants(ctx)).Preload("Users").First(&tenant, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *service) GetTenants(ctx context.Context, q types.GetTenantsQuery) ([]model.Tenant, int64, error) {
+	var count int64
+	var tenants []model.Tenant
+	if err := s.db.WithContext(ctx).Scopes(model.Paginate(q.Page, q.PerPage), scopeTenants(ctx)).Where(&model.Tenant{
+		Name: q.Name,
+	}).Preload("Users").Find(&tenants).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tenants, count, nil
+}
+
+func (s *service) AddUserToTenant(ctx context.Context, id, userID uint) error {
+	tenant := model.Tenant{}
+	if err := s.db.WithContext(ctx).Scopes(scopeTenants(ctx)).First(&tenant, id).Error; err != nil {
+		return err
+	}
+
+	user := model.User{}
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&tenant).Association("Users").Append(&user); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) DeleteUserToTenant(ctx context.Context, id, userID uint) error {
+	tenant := model.Tenant{}
+	if err := s.db.WithContext(ctx).Scopes(scopeTenants(ctx)).First(&tenant, id).Error; err != nil {
+		return err
+	}
+
+	user := model.User{}
+	if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&tenant).Association("Users").Delete(&user); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// createTenantClusters creates a shared scheduler cluster and scheduler clusters of tenant 1 and 2,
+// each cluster has an active scheduler.
+func createTenantClusters(t *testing.T, db *gorm.DB) []model.Scheduler {
+	var schedulers []model.Scheduler
+	for _, tenantID := range []uint{0, 1, 2} {
+		schedulerCluster := model.SchedulerCluster{
+			Name:         fmt.Sprintf("cluster-%d", tenantID),
+			Config:       model.JSONMap{},
+			ClientConfig: model.JSONMap{},
+			TenantID:     tenantID,
+		}
+		if err := db.Create(&schedulerCluster).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		scheduler := model.Scheduler{
+			HostName:           schedulerCluster.Name,
+			IP:                 "127.0.0.1",
+			Port:               8002,
+			State:              model.SchedulerStateActive,
+			SchedulerClusterID: schedulerCluster.ID,
+		}
+		if err := db.Create(&scheduler).Error; err != nil {
+			t.Fatal(err)
+		}
+		schedulers = append(schedulers, scheduler)
+	}
+
+	return schedulers
+}
+
+func TestService_AddSchedulerToSchedulerCluster(t *testing.T) {
+	tests := []struct {
+		name   string
+		scope  *types.TenantScope
+		expect func(t *testing.T, s *service, ctx context.Context, schedulers []model.Scheduler)
+	}{
+		{
+			name:  "add scheduler of shared cluster to tenant cluster",
+			scope: &types.TenantScope{TenantIDs: []uint{1}},
+			expect: func(t *testing.T, s *service, ctx context.Context, schedulers []model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.AddSchedulerToSchedulerCluster(ctx, schedulers[1].SchedulerClusterID, schedulers[0].ID))
+
+				scheduler := model.Scheduler{}
+				assert.NoError(s.db.First(&scheduler, schedulers[0].ID).Error)
+				assert.Equal(schedulers[1].SchedulerClusterID, scheduler.SchedulerClusterID)
+			},
+		},
+		{
+			name:  "add scheduler of other tenant to tenant cluster",
+			scope: &types.TenantScope{TenantIDs: []uint{1}},
+			expect: func(t *testing.T, s *service, ctx context.Context, schedulers []model.Scheduler) {
+				assert := assert.New(t)
+				assert.ErrorIs(s.AddSchedulerToSchedulerCluster(ctx, schedulers[1].SchedulerClusterID, schedulers[2].ID), gorm.ErrRecordNotFound)
+
+				scheduler := model.Scheduler{}
+				assert.NoError(s.db.First(&scheduler, schedulers[2].ID).Error)
+				assert.Equal(schedulers[2].SchedulerClusterID, scheduler.SchedulerClusterID)
+			},
+		},
+		{
+			name:  "root adds scheduler of any tenant",
+			scope: &types.TenantScope{All: true},
+			expect: func(t *testing.T, s *service, ctx context.Context, schedulers []model.Scheduler) {
+				assert := assert.New(t)
+				assert.NoError(s.AddSchedulerToSchedulerCluster(ctx, schedulers[1].SchedulerClusterID, schedulers[2].ID))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			schedulers := createTenantClusters(t, s.db)
+			tc.expect(t, s, WithTenantScope(context.Background(), tc.scope), schedulers)
+		})
+	}
+}
+
+func TestService_GetSchedulers(t *testing.T) {
+	tests := []struct {
+		name   string
+		scope  *types.TenantScope
+		expect func(t *testing.T, schedulers []model.Scheduler, count int64, err error)
+	}{
+		{
+			name:  "tenant user gets schedulers of tenant and shared clusters",
+			scope: &types.TenantScope{TenantIDs: []uint{1}},
+			expect: func(t *testing.T, schedulers []model.Scheduler, count int64, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(int64(2), count)
+				assert.Equal("cluster-0", schedulers[0].HostName)
+				assert.Equal("cluster-1", schedulers[1].HostName)
+			},
+		},
+		{
+			name:  "root gets all schedulers",
+			scope: &types.TenantScope{All: true},
+			expect: func(t *testing.T, schedulers []model.Scheduler, count int64, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(int64(3), count)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			createTenantClusters(t, s.db)
+			schedulers, count, err := s.GetSchedulers(WithTenantScope(context.Background(), tc.scope), types.GetSchedulersQuery{Page: 1, PerPage: 10})
+			tc.expect(t, schedulers, count, err)
+		})
+	}
+}
+
+func TestService_findActiveSchedulers(t *testing.T) {
+	tests := []struct {
+		name                string
+		tenantID            uint
+		schedulerClusterIDs []uint
+		expect              func(t *testing.T, schedulers []model.Scheduler, err error)
+	}{
+		{
+			name:     "shared job finds schedulers of shared clusters",
+			tenantID: 0,
+			expect: func(t *testing.T, schedulers []model.Scheduler, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(schedulers, 1)
+				assert.Equal("cluster-0", schedulers[0].HostName)
+			},
+		},
+		{
+			name:     "tenant job finds schedulers of tenant and shared clusters",
+			tenantID: 2,
+			expect: func(t *testing.T, schedulers []model.Scheduler, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(schedulers, 2)
+				assert.Equal("cluster-0", schedulers[0].HostName)
+				assert.Equal("cluster-2", schedulers[1].HostName)
+			},
+		},
+		{
+			name:                "shared job can not target tenant cluster",
+			tenantID:            0,
+			schedulerClusterIDs: []uint{2},
+			expect: func(t *testing.T, schedulers []model.Scheduler, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, gorm.ErrRecordNotFound)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			createTenantClusters(t, s.db)
+			schedulers, _, err := s.findActiveSchedulers(context.Background(), tc.tenantID, tc.schedulerClusterIDs)
+			tc.expect(t, schedulers, err)
+		})
+	}
+}
+
+func TestService_scopeQueuedJobs(t *testing.T) {
+	jobInfos := []*internaljob.JobInfo{
+		{UUID: "shared", GroupUUID: "group-0"},
+		{UUID: "tenant-1", GroupUUID: "group-1"},
+		{UUID: "tenant-2", GroupUUID: "group-2"},
+		{UUID: "without-group"},
+	}
+
+	tests := []struct {
+		name   string
+		scope  *types.TenantScope
+		expect func(t *testing.T, s *service, ctx context.Context)
+	}{
+		{
+			name:  "tenant user accesses queued jobs of tenant and shared jobs",
+			scope: &types.TenantScope{TenantIDs: []uint{1}},
+			expect: func(t *testing.T, s *service, ctx context.Context) {
+				assert := assert.New(t)
+				scopedJobInfos, err := s.scopeQueuedJobs(ctx, jobInfos)
+				assert.NoError(err)
+				assert.Equal(jobInfos[:2], scopedJobInfos)
+
+				assert.NoError(s.checkQueuedJob(ctx, jobInfos[0]))
+				assert.NoError(s.checkQueuedJob(ctx, jobInfos[1]))
+				assert.ErrorIs(s.checkQueuedJob(ctx, jobInfos[2]), gorm.ErrRecordNotFound)
+				assert.ErrorIs(s.checkQueuedJob(ctx, jobInfos[3]), gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "root accesses all queued jobs",
+			scope: &types.TenantScope{All: true},
+			expect: func(t *testing.T, s *service, ctx context.Context) {
+				assert := assert.New(t)
+				scopedJobInfos, err := s.scopeQueuedJobs(ctx, jobInfos)
+				assert.NoError(err)
+				assert.Equal(jobInfos, scopedJobInfos)
+
+				for _, jobInfo := range jobInfos {
+					assert.NoError(s.checkQueuedJob(ctx, jobInfo))
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			for _, tenantID := range []uint{0, 1, 2} {
+				if err := s.db.Create(&model.Job{
+					TaskID:   fmt.Sprintf("group-%d", tenantID),
+					Type:     internaljob.DeleteTaskJob,
+					Args:     model.JSONMap{},
+					TenantID: tenantID,
+				}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tc.expect(t, s, WithTenantScope(context.Background(), tc.scope))
+		})
+	}
+}
@@ -20,12 +20,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/VividCortex/mysqlerr"
 	"github.com/go-sql-driver/mysql"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	managerldap "d7y.io/dragonfly/v2/manager/auth/ldap"
 	manageroauth "d7y.io/dragonfly/v2/manager/auth/oauth"
+	manageroidc "d7y.io/dragonfly/v2/manager/auth/oidc"
 	"d7y.io/dragonfly/v2/manager/model"
 	"d7y.io/dragonfly/v2/manager/permission/rbac"
 	"d7y.io/dragonfly/v2/manager/types"
@@ -75,9 +80,23 @@ func (s *service) SignIn(ctx context.Context, json types.SignInRequest) (*model.
 	if err := s.db.WithContext(ctx).First(&user, model.User{
 		Name: json.Name,
 	}).Error; err != nil {
+		// User of ldap is created when it signs in first time.
+		if errors.Is(err, gorm.ErrRecordNotFound) && s.ldap != nil && s.ldap.AuthenticationEnabled() {
+			return s.signinLDAPUser(ctx, json.Name, json.Password)
+		}
+
 		return nil, err
 	}
 
+	// User of ldap signs in with password of ldap.
+	if user.Provider == model.UserProviderLDAP {
+		if s.ldap == nil || !s.ldap.AuthenticationEnabled() {
+			return nil, errors.New("ldap authentication is not enabled")
+		}
+
+		return s.signinLDAPUser(ctx, json.Name, json.Password)
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.EncryptedPassword), []byte(json.Password)); err != nil {
 		return nil, err
 	}
@@ -172,16 +191,54 @@ func (s *service) OauthSigninCallback(ctx context.Context, name, code string) (*
 		return nil, err
 	}
 
-	user := model.User{
-		Name:   oauthUser.Name,
-		Email:  oauthUser.Email,
-		Avatar: oauthUser.Avatar,
-		State:  model.UserStateEnabled,
+	return s.signinOauthUser(ctx, oauth.Name, oauthUser)
+}
+
+// signinOauthUser returns the user of oauth provider, and creates the user if the user signs in the first time.
+func (s *service) signinOauthUser(ctx context.Context, oauthName string, oauthUser *manageroauth.User) (*model.User, error) {
+	provider := model.UserProviderOauthPrefix + oauthName
+	user := model.User{}
+	if err := s.db.WithContext(ctx).First(&user, model.User{
+		Provider: provider,
+		Subject:  oauthUser.Name,
+	}).Error; err == nil {
+		return &user, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// Users signed in by oauth before the provider is recorded have neither provider nor password,
+	// they are identified by name, and the provider and subject are backfilled.
+	if err := s.db.WithContext(ctx).
+		Where("name = ?", oauthUser.Name).
+		Where("provider = ? OR provider IS NULL", "").
+		Where("encrypted_password = ? OR encrypted_password IS NULL", "").
+		First(&user).Error; err == nil {
+		if err := s.db.WithContext(ctx).Model(&user).Updates(model.User{
+			Provider: provider,
+			Subject:  oauthUser.Name,
+		}).Error; err != nil {
+			return nil, err
+		}
+
+		return &user, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user = model.User{
+		Name:     oauthUser.Name,
+		Email:    oauthUser.Email,
+		Avatar:   oauthUser.Avatar,
+		State:    model.UserStateEnabled,
+		Provider: provider,
+		Subject:  oauthUser.Name,
 	}
 	if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
+		// User of other identity provider or signed up with password can not be taken over.
 		var merr *mysql.MySQLError
 		if errors.As(err, &merr) && merr.Number == mysqlerr.ER_DUP_ENTRY {
-			return &user, nil
+			return nil, fmt.Errorf("user %s already exists", user.Name)
 		}
 
 		return nil, err
@@ -194,6 +251,240 @@ func (s *service) OauthSigninCallback(ctx context.Context, name, code string) (*
 	return &user, nil
 }
 
+func (s *service) OIDCSignin(ctx context.Context, state string) (string, error) {
+	if s.oidc == nil {
+		return "", errors.New("oidc is not enabled")
+	}
+
+	return s.oidc.AuthCodeURL(state), nil
+}
+
+func (s *service) OIDCSigninCallback(ctx context.Context, code, nonce string) (*model.User, error) {
+	if s.oidc == nil {
+		return nil, errors.New("oidc is not enabled")
+	}
+
+	token, err := s.oidc.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	oidcUser, err := s.oidc.GetUser(ctx, token, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signinOIDCUser(ctx, oidcUser)
+}
+
+// signinOIDCUser creates user of identity provider when it signs in first time,
+// and syncs roles mapped from groups of user. Users are identified by the issuer
+// and subject of identity provider, the name of user is only used for display.
+func (s *service) signinOIDCUser(ctx context.Context, oidcUser *manageroidc.User) (*model.User, error) {
+	provider := model.UserProviderOIDCPrefix + oidcUser.Issuer
+	user := model.User{}
+	if err := s.db.WithContext(ctx).First(&user, model.User{
+		Provider: provider,
+		Subject:  oidcUser.Subject,
+	}).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		// User of other identity provider or signed up with password can not be taken over.
+		if err := s.db.WithContext(ctx).First(&model.User{}, model.User{Name: oidcUser.Name}).Error; err == nil {
+			return nil, fmt.Errorf("user %s already exists", oidcUser.Name)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		user = model.User{
+			Name:     oidcUser.Name,
+			Email:    oidcUser.Email,
+			Avatar:   oidcUser.Avatar,
+			State:    model.UserStateEnabled,
+			Provider: provider,
+			Subject:  oidcUser.Subject,
+		}
+		if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if user.State != model.UserStateEnabled {
+		return nil, fmt.Errorf("user %s is disabled", user.Name)
+	}
+
+	if err := s.syncManagedRoles(&user, s.oidc.ManagedRoles(), oidcUser.Roles); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// signinLDAPUser authenticates user by ldap bind, and syncs roles mapped from groups of user.
+func (s *service) signinLDAPUser(ctx context.Context, name, password string) (*model.User, error) {
+	ldapUser, err := s.ldap.Authenticate(ctx, name, password)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.createLDAPUser(ctx, ldapUser)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.State != model.UserStateEnabled {
+		return nil, fmt.Errorf("user %s is disabled", user.Name)
+	}
+
+	if err := s.syncManagedRoles(user, s.ldap.ManagedRoles(), ldapUser.Roles); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SyncLDAPUsers creates members of groups in role mapping of ldap, grants the mapped roles to them
+// and revokes the managed roles from users who left the groups.
+func (s *service) SyncLDAPUsers(ctx context.Context) error {
+	if s.ldap == nil {
+		return errors.New("ldap is not enabled")
+	}
+
+	ldapUsers, err := s.ldap.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	synced := map[uint]struct{}{}
+	for _, ldapUser := range ldapUsers {
+		user, err := s.createLDAPUser(ctx, ldapUser)
+		if err != nil {
+			logger.Warnf("sync ldap user %s failed: %s", ldapUser.Name, err.Error())
+			continue
+		}
+
+		if err := s.syncManagedRoles(user, s.ldap.ManagedRoles(), ldapUser.Roles); err != nil {
+			return err
+		}
+
+		synced[user.ID] = struct{}{}
+	}
+
+	for _, role := range s.ldap.ManagedRoles() {
+		ids, err := s.enforcer.GetUsersForRole(role)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			userID, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			if _, ok := synced[uint(userID)]; ok {
+				continue
+			}
+
+			user := model.User{}
+			if err := s.db.WithContext(ctx).First(&user, userID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+
+				return err
+			}
+
+			// Roles of users not from ldap are not managed by ldap.
+			if user.Provider != model.UserProviderLDAP {
+				continue
+			}
+
+			if err := s.syncManagedRoles(&user, s.ldap.ManagedRoles(), nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createLDAPUser creates user of ldap when it does not exist,
+// users of ldap are identified by their dn.
+func (s *service) createLDAPUser(ctx context.Context, ldapUser *managerldap.User) (*model.User, error) {
+	user := model.User{}
+	if err := s.db.WithContext(ctx).First(&user, model.User{
+		Provider: model.UserProviderLDAP,
+		Subject:  ldapUser.DN,
+	}).Error; err == nil {
+		return &user, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// User of other identity provider or signed up with password is not managed by ldap.
+	if err := s.db.WithContext(ctx).First(&model.User{}, model.User{Name: ldapUser.Name}).Error; err == nil {
+		return nil, fmt.Errorf("user %s already exists", ldapUser.Name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user = model.User{
+		Name:     ldapUser.Name,
+		Email:    ldapUser.Email,
+		State:    model.UserStateEnabled,
+		Provider: model.UserProviderLDAP,
+		Subject:  ldapUser.DN,
+	}
+	if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// syncManagedRoles grants roles to user and revokes other managed roles of identity provider,
+// user without roles has guest role.
+func (s *service) syncManagedRoles(user *model.User, managedRoles, roles []string) error {
+	id := fmt.Sprint(user.ID)
+	for _, role := range managedRoles {
+		granted := false
+		for _, r := range roles {
+			if r == role {
+				granted = true
+				break
+			}
+		}
+
+		if granted {
+			if _, err := s.enforcer.AddRoleForUser(id, role); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if _, err := s.enforcer.DeleteRoleForUser(id, role); err != nil {
+			return err
+		}
+	}
+
+	currentRoles, err := s.enforcer.GetRolesForUser(id)
+	if err != nil {
+		return err
+	}
+
+	if len(currentRoles) == 0 {
+		if _, err := s.enforcer.AddRoleForUser(id, rbac.GuestRole); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *service) GetRolesForUser(ctx context.Context, id uint) ([]string, error) {
 	return s.enforcer.GetRolesForUser(fmt.Sprint(id))
 }
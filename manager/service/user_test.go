@@ -0,0 +1,252 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+
+	managerldap "d7y.io/dragonfly/v2/manager/auth/ldap"
+	ldapmocks "d7y.io/dragonfly/v2/manager/auth/ldap/mocks"
+	manageroauth "d7y.io/dragonfly/v2/manager/auth/oauth"
+	manageroidc "d7y.io/dragonfly/v2/manager/auth/oidc"
+	oidcmocks "d7y.io/dragonfly/v2/manager/auth/oidc/mocks"
+	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestService_signinOIDCUser(t *testing.T) {
+	const issuer = "https://idp.example.com"
+
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, s *service)
+	}{
+		{
+			name: "user is identified by issuer and subject",
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				user, err := s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: issuer, Subject: "1", Name: "alice", Roles: []string{"root"}})
+				assert.NoError(err)
+				assert.Equal(model.UserProviderOIDCPrefix+issuer, user.Provider)
+				assert.Equal("1", user.Subject)
+
+				// Display name is changed in identity provider.
+				renamed, err := s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: issuer, Subject: "1", Name: "alice2", Roles: []string{"root"}})
+				assert.NoError(err)
+				assert.Equal(user.ID, renamed.ID)
+
+				// Other user of identity provider with the same name can not take over the user.
+				_, err = s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: issuer, Subject: "2", Name: "alice"})
+				assert.EqualError(err, "user alice already exists")
+
+				// User of other issuer with the same subject is a different user.
+				_, err = s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: "https://other.example.com", Subject: "1", Name: "alice"})
+				assert.EqualError(err, "user alice already exists")
+			},
+		},
+		{
+			name: "user signed up with password can not be taken over",
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				assert.NoError(s.db.Create(&model.User{Name: "bob", Email: "bob@example.com", EncryptedPassword: "foo"}).Error)
+
+				_, err := s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: issuer, Subject: "1", Name: "bob"})
+				assert.EqualError(err, "user bob already exists")
+			},
+		},
+		{
+			name: "mapped roles are revoked when user leaves groups",
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				user, err := s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: issuer, Subject: "1", Name: "alice", Roles: []string{"root"}})
+				assert.NoError(err)
+				roles, err := s.enforcer.GetRolesForUser(fmt.Sprint(user.ID))
+				assert.NoError(err)
+				assert.Equal([]string{"root"}, roles)
+
+				_, err = s.signinOIDCUser(context.Background(), &manageroidc.User{Issuer: issuer, Subject: "1", Name: "alice"})
+				assert.NoError(err)
+				roles, err = s.enforcer.GetRolesForUser(fmt.Sprint(user.ID))
+				assert.NoError(err)
+				assert.Equal([]string{rbac.GuestRole}, roles)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			oidc := oidcmocks.NewMockOIDC(ctl)
+			oidc.EXPECT().ManagedRoles().Return([]string{"root"}).AnyTimes()
+			s.oidc = oidc
+			tc.expect(t, s)
+		})
+	}
+}
+
+func TestService_signinOauthUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, s *service)
+	}{
+		{
+			name: "user is identified by provider and subject",
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				user, err := s.signinOauthUser(context.Background(), "github", &manageroauth.User{Name: "alice", Email: "alice@example.com"})
+				assert.NoError(err)
+				assert.Equal(model.UserProviderOauthPrefix+"github", user.Provider)
+				assert.Equal("alice", user.Subject)
+
+				signedIn, err := s.signinOauthUser(context.Background(), "github", &manageroauth.User{Name: "alice", Email: "alice@example.com"})
+				assert.NoError(err)
+				assert.Equal(user.ID, signedIn.ID)
+			},
+		},
+		{
+			name: "user signed in before provider is recorded is backfilled",
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				legacy := model.User{Name: "alice", Email: "alice@example.com"}
+				assert.NoError(s.db.Create(&legacy).Error)
+				assert.NoError(s.db.Model(&legacy).Update("provider", nil).Error)
+
+				user, err := s.signinOauthUser(context.Background(), "github", &manageroauth.User{Name: "alice", Email: "alice@example.com"})
+				assert.NoError(err)
+				assert.Equal(legacy.ID, user.ID)
+
+				backfilled := model.User{}
+				assert.NoError(s.db.First(&backfilled, legacy.ID).Error)
+				assert.Equal(model.UserProviderOauthPrefix+"github", backfilled.Provider)
+				assert.Equal("alice", backfilled.Subject)
+			},
+		},
+		{
+			name: "user signed up with password can not be taken over",
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				assert.NoError(s.db.Create(&model.User{Name: "bob", Email: "bob@example.com", EncryptedPassword: "foo"}).Error)
+
+				_, err := s.signinOauthUser(context.Background(), "github", &manageroauth.User{Name: "bob", Email: "bob@example.com"})
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			tc.expect(t, s)
+		})
+	}
+}
+
+func TestService_LDAPUsers(t *testing.T) {
+	alice := &managerldap.User{DN: "uid=alice,dc=example,dc=com", Name: "alice", Roles: []string{"root"}}
+
+	tests := []struct {
+		name   string
+		mock   func(ml *ldapmocks.MockLDAPMockRecorder)
+		expect func(t *testing.T, s *service)
+	}{
+		{
+			name: "ldap user signs in and is identified by dn",
+			mock: func(ml *ldapmocks.MockLDAPMockRecorder) {
+				ml.Authenticate(gomock.Any(), "alice", "foo").Return(alice, nil).Times(2)
+			},
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				user, err := s.SignIn(context.Background(), types.SignInRequest{Name: "alice", Password: "foo"})
+				assert.NoError(err)
+				assert.Equal(model.UserProviderLDAP, user.Provider)
+				assert.Equal(alice.DN, user.Subject)
+
+				signedIn, err := s.SignIn(context.Background(), types.SignInRequest{Name: "alice", Password: "foo"})
+				assert.NoError(err)
+				assert.Equal(user.ID, signedIn.ID)
+			},
+		},
+		{
+			name: "ldap user can not sign in as user of other identity provider",
+			mock: func(ml *ldapmocks.MockLDAPMockRecorder) {
+				ml.Authenticate(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				assert.NoError(s.db.Create(&model.User{Name: "alice", Email: "alice@example.com", Provider: model.UserProviderOIDCPrefix + "https://idp.example.com", Subject: "1"}).Error)
+
+				_, err := s.SignIn(context.Background(), types.SignInRequest{Name: "alice", Password: "foo"})
+				assert.ErrorIs(err, bcrypt.ErrHashTooShort)
+
+				_, err = s.createLDAPUser(context.Background(), alice)
+				assert.EqualError(err, "user alice already exists")
+			},
+		},
+		{
+			name: "sync revokes managed roles only from ldap users",
+			mock: func(ml *ldapmocks.MockLDAPMockRecorder) {
+				ml.ListUsers(gomock.Any()).Return([]*managerldap.User{}, nil).Times(1)
+			},
+			expect: func(t *testing.T, s *service) {
+				assert := assert.New(t)
+				ldapUser, err := s.createLDAPUser(context.Background(), alice)
+				assert.NoError(err)
+				oidcUser := model.User{Name: "bob", Email: "bob@example.com", Provider: model.UserProviderOIDCPrefix + "https://idp.example.com", Subject: "2"}
+				assert.NoError(s.db.Create(&oidcUser).Error)
+				for _, id := range []uint{ldapUser.ID, oidcUser.ID} {
+					_, err := s.enforcer.AddRoleForUser(fmt.Sprint(id), "root")
+					assert.NoError(err)
+				}
+
+				assert.NoError(s.SyncLDAPUsers(context.Background()))
+
+				roles, err := s.enforcer.GetRolesForUser(fmt.Sprint(ldapUser.ID))
+				assert.NoError(err)
+				assert.Equal([]string{rbac.GuestRole}, roles)
+
+				roles, err = s.enforcer.GetRolesForUser(fmt.Sprint(oidcUser.ID))
+				assert.NoError(err)
+				assert.Equal([]string{"root"}, roles)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			s, _ := newTestService(t, ctl)
+			ldap := ldapmocks.NewMockLDAP(ctl)
+			ldap.EXPECT().AuthenticationEnabled().Return(true).AnyTimes()
+			ldap.EXPECT().ManagedRoles().Return([]string{"root"}).AnyTimes()
+			tc.mock(ldap.EXPECT())
+			s.ldap = ldap
+			tc.expect(t, s)
+		})
+	}
+}
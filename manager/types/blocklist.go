@@ -0,0 +1,97 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// BlocklistTypeRegex matches urls by regular expression,
+	// the url is blocked when any part of it matches the pattern.
+	BlocklistTypeRegex = "regex"
+
+	// BlocklistTypeGlob matches urls by glob, the whole url must match the pattern,
+	// "*" matches any sequence of characters and "?" matches any single character.
+	BlocklistTypeGlob = "glob"
+)
+
+type BlocklistParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreateBlocklistRequest struct {
+	Type               string `json:"type" binding:"omitempty,oneof=regex glob"`
+	Pattern            string `json:"pattern" binding:"required,max=1024"`
+	BIO                string `json:"bio" binding:"omitempty"`
+	SchedulerClusterID uint   `json:"scheduler_cluster_id" binding:"required"`
+}
+
+type UpdateBlocklistRequest struct {
+	Type    string `json:"type" binding:"omitempty,oneof=regex glob"`
+	Pattern string `json:"pattern" binding:"omitempty,max=1024"`
+	BIO     string `json:"bio" binding:"omitempty"`
+}
+
+type GetBlocklistsQuery struct {
+	Type               string `form:"type" binding:"omitempty,oneof=regex glob"`
+	SchedulerClusterID uint   `form:"scheduler_cluster_id" binding:"omitempty"`
+	Page               int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage            int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
+
+// BlocklistRule is the url pattern delivered to schedulers with the config of scheduler cluster.
+type BlocklistRule struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+}
+
+// Compile compiles the pattern of rule to regular expression.
+func (r BlocklistRule) Compile() (*regexp.Regexp, error) {
+	switch r.Type {
+	case BlocklistTypeRegex, "":
+		return regexp.Compile(r.Pattern)
+	case BlocklistTypeGlob:
+		expr := regexp.QuoteMeta(r.Pattern)
+		expr = strings.ReplaceAll(expr, `\*`, ".*")
+		expr = strings.ReplaceAll(expr, `\?`, ".")
+		return regexp.Compile("^" + expr + "$")
+	default:
+		return nil, fmt.Errorf("invalid blocklist type %s", r.Type)
+	}
+}
+
+// Blocklist is the url patterns of scheduler cluster.
+type Blocklist []BlocklistRule
+
+// Match returns the first rule matching the url, invalid rules are skipped.
+func (b Blocklist) Match(url string) (BlocklistRule, bool) {
+	for _, rule := range b {
+		re, err := rule.Compile()
+		if err != nil {
+			continue
+		}
+
+		if re.MatchString(url) {
+			return rule, true
+		}
+	}
+
+	return BlocklistRule{}, false
+}
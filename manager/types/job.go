@@ -22,6 +22,7 @@ type CreateJobRequest struct {
 	Args                map[string]any `json:"args" binding:"omitempty"`
 	Result              map[string]any `json:"result" binding:"omitempty"`
 	UserID              uint           `json:"user_id" binding:"omitempty"`
+	TenantID            uint           `json:"tenant_id" binding:"omitempty"`
 	SeedPeerClusterIDs  []uint         `json:"seed_peer_cluster_ids" binding:"omitempty"`
 	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
 }
@@ -36,9 +37,20 @@ type JobParams struct {
 }
 
 type GetJobsQuery struct {
-	Type    string `form:"type" binding:"omitempty"`
-	State   string `form:"state" binding:"omitempty,oneof=PENDING RECEIVED STARTED RETRY SUCCESS FAILURE"`
-	UserID  uint   `form:"user_id" binding:"omitempty"`
+	Type     string `form:"type" binding:"omitempty"`
+	State    string `form:"state" binding:"omitempty,oneof=PENDING RECEIVED STARTED RETRY SUCCESS FAILURE"`
+	UserID   uint   `form:"user_id" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
+
+type QueuedJobParams struct {
+	UUID string `uri:"uuid" binding:"required"`
+}
+
+type GetQueuedJobsQuery struct {
+	State   string `form:"state" binding:"omitempty,oneof=active failed"`
 	Page    int    `form:"page" binding:"omitempty,gte=1"`
 	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
@@ -49,6 +61,7 @@ type CreatePreheatJobRequest struct {
 	Args                PreheatArgs    `json:"args" binding:"omitempty"`
 	Result              map[string]any `json:"result" binding:"omitempty"`
 	UserID              uint           `json:"user_id" binding:"omitempty"`
+	TenantID            uint           `json:"tenant_id" binding:"omitempty"`
 	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
 }
 
@@ -58,4 +71,20 @@ type PreheatArgs struct {
 	Tag     string            `json:"tag" binding:"omitempty"`
 	Filter  string            `json:"filter" binding:"omitempty"`
 	Headers map[string]string `json:"headers" binding:"omitempty"`
+	// PinTTL pins the preheated task in seed peers so it is not reclaimed by gc until ttl, e.g. 24h.
+	PinTTL string `json:"pin_ttl" binding:"omitempty"`
+}
+
+type CreateDeleteTaskJobRequest struct {
+	BIO                 string         `json:"bio" binding:"omitempty"`
+	Type                string         `json:"type" binding:"required"`
+	Args                DeleteTaskArgs `json:"args" binding:"omitempty"`
+	Result              map[string]any `json:"result" binding:"omitempty"`
+	UserID              uint           `json:"user_id" binding:"omitempty"`
+	TenantID            uint           `json:"tenant_id" binding:"omitempty"`
+	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
+}
+
+type DeleteTaskArgs struct {
+	TaskID string `json:"task_id" binding:"required"`
 }
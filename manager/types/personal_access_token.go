@@ -0,0 +1,44 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import "time"
+
+type PersonalAccessTokenParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name      string    `json:"name" binding:"required"`
+	BIO       string    `json:"bio" binding:"omitempty"`
+	Scopes    []string  `json:"scopes" binding:"required,min=1"`
+	ExpiredAt time.Time `json:"expired_at" binding:"required"`
+}
+
+type UpdatePersonalAccessTokenRequest struct {
+	BIO       string    `json:"bio" binding:"omitempty"`
+	Scopes    []string  `json:"scopes" binding:"omitempty"`
+	State     string    `json:"state" binding:"omitempty,oneof=active inactive"`
+	ExpiredAt time.Time `json:"expired_at" binding:"omitempty"`
+}
+
+type GetPersonalAccessTokensQuery struct {
+	Page    int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	State   string `form:"state" binding:"omitempty,oneof=active inactive"`
+	UserID  uint   `form:"user_id" binding:"omitempty"`
+}
@@ -28,10 +28,21 @@ type CreateV1PreheatResponse struct {
 }
 
 type GetV1PreheatResponse struct {
-	ID         string `json:"id"`
-	Status     string `json:"status"`
-	StartTime  string `json:"startTime,omitempty"`
-	FinishTime string `json:"finishTime,omitempty"`
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	StartTime  string          `json:"startTime,omitempty"`
+	FinishTime string          `json:"finishTime,omitempty"`
+	Percentage float64         `json:"percentage"`
+	Nodes      []V1PreheatNode `json:"nodes,omitempty"`
+}
+
+type V1PreheatNode struct {
+	SchedulerHostname  string `json:"schedulerHostname"`
+	SeedPeerHostID     string `json:"seedPeerHostID,omitempty"`
+	URL                string `json:"url"`
+	Status             string `json:"status"`
+	FinishedPieceCount int32  `json:"finishedPieceCount"`
+	TotalPieceCount    int32  `json:"totalPieceCount"`
 }
 
 type V1PreheatParams struct {
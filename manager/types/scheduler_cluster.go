@@ -31,9 +31,11 @@ type CreateSchedulerClusterRequest struct {
 	Config            *SchedulerClusterConfig       `json:"config" binding:"required"`
 	ClientConfig      *SchedulerClusterClientConfig `json:"client_config" binding:"required"`
 	Scopes            *SchedulerClusterScopes       `json:"scopes" binding:"omitempty"`
+	Features          SchedulerClusterFeatures      `json:"features" binding:"omitempty"`
 	IsDefault         bool                          `json:"is_default" binding:"omitempty"`
 	SeedPeerClusterID uint                          `json:"seed_peer_cluster_id" binding:"omitempty"`
 	SecurityGroupID   uint                          `json:"security_group_id" binding:"omitempty"`
+	TenantID          uint                          `json:"tenant_id" binding:"omitempty"`
 }
 
 type UpdateSchedulerClusterRequest struct {
@@ -42,20 +44,35 @@ type UpdateSchedulerClusterRequest struct {
 	Config            *SchedulerClusterConfig       `json:"config" binding:"omitempty"`
 	ClientConfig      *SchedulerClusterClientConfig `json:"client_config" binding:"omitempty"`
 	Scopes            *SchedulerClusterScopes       `json:"scopes" binding:"omitempty"`
+	Features          SchedulerClusterFeatures      `json:"features" binding:"omitempty"`
 	IsDefault         bool                          `json:"is_default" binding:"omitempty"`
 	SeedPeerClusterID uint                          `json:"seed_peer_cluster_id" binding:"omitempty"`
 	SecurityGroupID   uint                          `json:"security_group_id" binding:"omitempty"`
+	TenantID          uint                          `json:"tenant_id" binding:"omitempty"`
 }
 
 type GetSchedulerClustersQuery struct {
-	Name    string `form:"name" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Name     string `form:"name" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
 
 type SchedulerClusterConfig struct {
-	FilterParentLimit      uint32 `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=1,lte=100"`
-	FilterParentRangeLimit uint32 `yaml:"filterParentRangeLimit" mapstructure:"filterParentRangeLimit" json:"filter_parent_range_limit" binding:"omitempty,gte=1,lte=1000"`
+	FilterParentLimit      uint32                    `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=1,lte=100"`
+	FilterParentRangeLimit uint32                    `yaml:"filterParentRangeLimit" mapstructure:"filterParentRangeLimit" json:"filter_parent_range_limit" binding:"omitempty,gte=1,lte=1000"`
+	GC                     *SchedulerClusterGCConfig `yaml:"gc" mapstructure:"gc" json:"gc" binding:"omitempty"`
+}
+
+// SchedulerClusterGCConfig is reloaded by schedulers of the cluster without restarting,
+// durations are in seconds and zero values fall back to the gc config of scheduler.
+type SchedulerClusterGCConfig struct {
+	PeerGCInterval uint32 `yaml:"peerGCInterval" mapstructure:"peerGCInterval" json:"peer_gc_interval" binding:"omitempty,gte=1"`
+	PeerTTL        uint32 `yaml:"peerTTL" mapstructure:"peerTTL" json:"peer_ttl" binding:"omitempty,gte=1"`
+	TaskGCInterval uint32 `yaml:"taskGCInterval" mapstructure:"taskGCInterval" json:"task_gc_interval" binding:"omitempty,gte=1"`
+	TaskTTL        uint32 `yaml:"taskTTL" mapstructure:"taskTTL" json:"task_ttl" binding:"omitempty,gte=1"`
+	HostGCInterval uint32 `yaml:"hostGCInterval" mapstructure:"hostGCInterval" json:"host_gc_interval" binding:"omitempty,gte=1"`
+	HostTTL        uint32 `yaml:"hostTTL" mapstructure:"hostTTL" json:"host_ttl" binding:"omitempty,gte=1"`
 }
 
 type SchedulerClusterClientConfig struct {
@@ -64,7 +81,28 @@ type SchedulerClusterClientConfig struct {
 }
 
 type SchedulerClusterScopes struct {
-	IDC         string `yaml:"idc" mapstructure:"idc" json:"idc" binding:"omitempty"`
-	NetTopology string `yaml:"net_topology" mapstructure:"net_topology" json:"net_topology" binding:"omitempty"`
-	Location    string `yaml:"location" mapstructure:"location" json:"location" binding:"omitempty"`
+	IDC         string   `yaml:"idc" mapstructure:"idc" json:"idc" binding:"omitempty"`
+	NetTopology string   `yaml:"net_topology" mapstructure:"net_topology" json:"net_topology" binding:"omitempty"`
+	Location    string   `yaml:"location" mapstructure:"location" json:"location" binding:"omitempty"`
+	CIDRs       []string `yaml:"cidrs" mapstructure:"cidrs" json:"cidrs" binding:"omitempty,dive,cidr"`
+}
+
+const (
+	// SchedulerFeatureSeedPeer toggles triggering seed peers to download tasks,
+	// it takes effect when seed peer is enabled in scheduler config.
+	SchedulerFeatureSeedPeer = "seed-peer"
+)
+
+// SchedulerClusterFeatures toggles scheduler behaviors of the cluster by feature name,
+// the features are delivered to schedulers with the config of scheduler cluster.
+type SchedulerClusterFeatures map[string]bool
+
+// Enabled returns whether the feature is enabled, defaultValue is returned
+// when the feature is not set.
+func (f SchedulerClusterFeatures) Enabled(name string, defaultValue bool) bool {
+	if enabled, ok := f[name]; ok {
+		return enabled
+	}
+
+	return defaultValue
 }
@@ -36,17 +36,20 @@ type AddSecurityRuleToSecurityGroupParams struct {
 }
 
 type CreateSecurityGroupRequest struct {
-	Name string `json:"name" binding:"required"`
-	BIO  string `json:"bio" binding:"omitempty"`
+	Name     string `json:"name" binding:"required"`
+	BIO      string `json:"bio" binding:"omitempty"`
+	TenantID uint   `json:"tenant_id" binding:"omitempty"`
 }
 
 type UpdateSecurityGroupRequest struct {
-	Name string `json:"name" binding:"omitempty"`
-	BIO  string `json:"bio" binding:"omitempty"`
+	Name     string `json:"name" binding:"omitempty"`
+	BIO      string `json:"bio" binding:"omitempty"`
+	TenantID uint   `json:"tenant_id" binding:"omitempty"`
 }
 
 type GetSecurityGroupsQuery struct {
-	Name    string `form:"name" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Name     string `form:"name" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
@@ -41,3 +41,15 @@ type GetSecurityRulesQuery struct {
 	Domain      string `form:"domain" binding:"omitempty"`
 	ProxyDomain string `form:"proxy_domain" binding:"omitempty"`
 }
+
+type ResolveSecurityRuleQuery struct {
+	HostName       string `form:"host_name" binding:"required"`
+	SecurityDomain string `form:"security_domain" binding:"omitempty"`
+}
+
+type ResolvedSecurityRule struct {
+	SecurityDomain      string `json:"security_domain"`
+	SecurityRuleID      uint   `json:"security_rule_id"`
+	SecurityRuleName    string `json:"security_rule_name"`
+	SchedulerClusterIDs []uint `json:"scheduler_cluster_ids"`
+}
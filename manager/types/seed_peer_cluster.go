@@ -36,6 +36,7 @@ type CreateSeedPeerClusterRequest struct {
 	Config    *SeedPeerClusterConfig `json:"config" binding:"required"`
 	Scopes    *SeedPeerClusterScopes `json:"scopes" binding:"omitempty"`
 	IsDefault bool                   `json:"is_default" binding:"omitempty"`
+	TenantID  uint                   `json:"tenant_id" binding:"omitempty"`
 }
 
 type UpdateSeedPeerClusterRequest struct {
@@ -44,12 +45,14 @@ type UpdateSeedPeerClusterRequest struct {
 	Config    *SeedPeerClusterConfig `json:"config" binding:"omitempty"`
 	Scopes    *SeedPeerClusterScopes `json:"scopes" binding:"omitempty"`
 	IsDefault bool                   `json:"is_default" binding:"omitempty"`
+	TenantID  uint                   `json:"tenant_id" binding:"omitempty"`
 }
 
 type GetSeedPeerClustersQuery struct {
-	Name    string `form:"name" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Name     string `form:"name" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
 
 type SeedPeerClusterConfig struct {
@@ -0,0 +1,56 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+type ClusterStatsParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type ClusterStats struct {
+	// ID is the scheduler cluster id.
+	ID uint `json:"id"`
+
+	// SchedulerCount is the number of schedulers in the cluster.
+	SchedulerCount int64 `json:"scheduler_count"`
+
+	// ActiveSchedulerCount is the number of active schedulers in the cluster.
+	ActiveSchedulerCount int64 `json:"active_scheduler_count"`
+
+	// SeedPeerCount is the number of seed peers in seed peer clusters of the cluster.
+	SeedPeerCount int64 `json:"seed_peer_count"`
+
+	// ActiveSeedPeerCount is the number of active seed peers in seed peer clusters of the cluster.
+	ActiveSeedPeerCount int64 `json:"active_seed_peer_count"`
+
+	// PeerCount is the number of active peers in the cluster.
+	PeerCount int64 `json:"peer_count"`
+
+	// Traffic is the recent traffic reported by schedulers of the cluster,
+	// it is empty when prometheus is not configured.
+	Traffic *ClusterTraffic `json:"traffic,omitempty"`
+}
+
+type ClusterTraffic struct {
+	// Period is the time range of traffic.
+	Period string `json:"period"`
+
+	// P2P is the bytes of p2p traffic.
+	P2P float64 `json:"p2p"`
+
+	// BackToSource is the bytes of back-to-source traffic.
+	BackToSource float64 `json:"back_to_source"`
+}
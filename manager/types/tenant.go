@@ -0,0 +1,56 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+type TenantParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type AddUserToTenantParams struct {
+	ID     uint `uri:"id" binding:"required"`
+	UserID uint `uri:"user_id" binding:"required"`
+}
+
+type DeleteUserToTenantParams struct {
+	ID     uint `uri:"id" binding:"required"`
+	UserID uint `uri:"user_id" binding:"required"`
+}
+
+type CreateTenantRequest struct {
+	Name string `json:"name" binding:"required"`
+	BIO  string `json:"bio" binding:"omitempty"`
+}
+
+type UpdateTenantRequest struct {
+	Name string `json:"name" binding:"omitempty"`
+	BIO  string `json:"bio" binding:"omitempty"`
+}
+
+type GetTenantsQuery struct {
+	Name    string `form:"name" binding:"omitempty"`
+	Page    int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
+
+// TenantScope is the range of tenants whose resources a user can access.
+type TenantScope struct {
+	// All is true when the user can access resources of all tenants, e.g. the root user.
+	All bool
+
+	// TenantIDs is the tenants that the user is a member of.
+	TenantIDs []uint
+}
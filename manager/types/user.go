@@ -54,6 +54,11 @@ type OauthSigninCallbackQuery struct {
 	Code string `form:"code" binding:"required"`
 }
 
+type OIDCSigninCallbackQuery struct {
+	Code  string `form:"code" binding:"required"`
+	State string `form:"state" binding:"required"`
+}
+
 type ResetPasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required,min=8,max=20"`
 	NewPassword string `json:"new_password" binding:"required,min=8,max=20"`
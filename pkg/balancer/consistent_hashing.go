@@ -17,10 +17,17 @@
 package balancer
 
 import (
+	"sync"
+	"time"
+
 	"google.golang.org/grpc/balancer"
 	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/status"
 	"stathat.com/c/consistent"
+
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
 )
 
 type ContextKeyType string
@@ -36,6 +43,10 @@ const (
 	// ContextKey is the key for the grpc request's context.Context which points to
 	// the key to hash for the request.
 	ContextKey = ContextKeyType("consistent-hashing-key")
+
+	// FailoverCooldown is the duration of skipping the target which responds unavailable or busy,
+	// requests of its keys fail over to the next target in the hash ring during cooldown.
+	FailoverCooldown = 30 * time.Second
 )
 
 var logger = grpclog.Component("consistenthashing")
@@ -44,12 +55,17 @@ var logger = grpclog.Component("consistenthashing")
 func NewConsistentHashingBuilder() balancer.Builder {
 	return base.NewBalancerBuilder(
 		BalancerName,
-		&consistentHashingPickerBuilder{},
+		&consistentHashingPickerBuilder{
+			unavailable: newUnavailableTargets(FailoverCooldown),
+		},
 		base.Config{HealthCheck: true},
 	)
 }
 
-type consistentHashingPickerBuilder struct{}
+type consistentHashingPickerBuilder struct {
+	// unavailable is shared by pickers, the targets are still skipped after picker rebuilt.
+	unavailable *unavailableTargets
+}
 
 func (b *consistentHashingPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
 	logger.Infof("consistentHashingPicker: newPicker called with info: %v", info)
@@ -67,23 +83,83 @@ func (b *consistentHashingPickerBuilder) Build(info base.PickerBuildInfo) balanc
 	}
 
 	return &consistentHashingPicker{
-		subConns: scs,
-		hashring: hashring,
+		subConns:    scs,
+		hashring:    hashring,
+		unavailable: b.unavailable,
 	}
 }
 
 type consistentHashingPicker struct {
-	subConns map[string]balancer.SubConn
-	hashring *consistent.Consistent
+	subConns    map[string]balancer.SubConn
+	hashring    *consistent.Consistent
+	unavailable *unavailableTargets
 }
 
+// Pick picks the first available target in the failover chain of key, the chain is the order
+// of targets in the hash ring, so all requests of the same key land on the same target.
 func (p *consistentHashingPicker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
-	element, err := p.hashring.Get(info.Ctx.Value(ContextKey).(string))
+	key, _ := info.Ctx.Value(ContextKey).(string)
+	chain, err := p.hashring.GetN(key, len(p.subConns))
 	if err != nil {
 		return balancer.PickResult{}, err
 	}
 
+	element := chain[0]
+	for _, e := range chain {
+		if !p.unavailable.contains(e) {
+			element = e
+			break
+		}
+	}
+
+	if element != chain[0] {
+		logger.Infof("consistentHashingPicker: key %s fails over from %s to %s", key, chain[0], element)
+		FailoverCount.WithLabelValues(chain[0], element).Inc()
+	}
+
 	return balancer.PickResult{
 		SubConn: p.subConns[element],
+		Done: func(info balancer.DoneInfo) {
+			if status.Code(info.Err) == codes.Unavailable || common.IsSeedPeerBusy(info.Err) {
+				p.unavailable.add(element)
+			}
+		},
 	}, nil
 }
+
+// unavailableTargets records the targets responding unavailable, e.g. the scheduler is draining
+// or the seed peer is above the watermark.
+type unavailableTargets struct {
+	mu       sync.Mutex
+	targets  map[string]time.Time
+	cooldown time.Duration
+}
+
+func newUnavailableTargets(cooldown time.Duration) *unavailableTargets {
+	return &unavailableTargets{
+		targets:  map[string]time.Time{},
+		cooldown: cooldown,
+	}
+}
+
+func (u *unavailableTargets) add(target string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.targets[target] = time.Now().Add(u.cooldown)
+}
+
+func (u *unavailableTargets) contains(target string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	expireAt, ok := u.targets[target]
+	if !ok {
+		return false
+	}
+
+	if time.Now().After(expireAt) {
+		delete(u.targets, target)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,147 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package balancer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
+)
+
+type mockSubConn struct {
+	addr string
+}
+
+func (sc *mockSubConn) UpdateAddresses([]resolver.Address) {}
+
+func (sc *mockSubConn) Connect() {}
+
+func TestConsistentHashingPicker_Pick(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, pick func(key string) (string, func(balancer.DoneInfo)))
+	}{
+		{
+			name: "requests of the same key land on the same target",
+			expect: func(t *testing.T, pick func(key string) (string, func(balancer.DoneInfo))) {
+				assert := assert.New(t)
+				target, done := pick("foo")
+				done(balancer.DoneInfo{})
+				for i := 0; i < 10; i++ {
+					addr, done := pick("foo")
+					assert.Equal(target, addr)
+					done(balancer.DoneInfo{Err: errors.New("foo")})
+				}
+			},
+		},
+		{
+			name: "fail over to the next target when target responds unavailable",
+			expect: func(t *testing.T, pick func(key string) (string, func(balancer.DoneInfo))) {
+				assert := assert.New(t)
+				primary, done := pick("foo")
+				done(balancer.DoneInfo{Err: status.Error(codes.Unavailable, "draining")})
+
+				secondary, done := pick("foo")
+				assert.NotEqual(primary, secondary)
+				done(balancer.DoneInfo{})
+
+				addr, _ := pick("foo")
+				assert.Equal(secondary, addr)
+			},
+		},
+		{
+			name: "fail over to the next target when seed peer is busy",
+			expect: func(t *testing.T, pick func(key string) (string, func(balancer.DoneInfo))) {
+				assert := assert.New(t)
+				primary, done := pick("foo")
+				done(balancer.DoneInfo{Err: common.NewSeedPeerBusyError("disk is above watermark")})
+
+				secondary, _ := pick("foo")
+				assert.NotEqual(primary, secondary)
+			},
+		},
+		{
+			name: "do not fail over when target is rate limited",
+			expect: func(t *testing.T, pick func(key string) (string, func(balancer.DoneInfo))) {
+				assert := assert.New(t)
+				primary, done := pick("foo")
+				done(balancer.DoneInfo{Err: common.NewRetryAfterError("rate limited", time.Second)})
+
+				addr, _ := pick("foo")
+				assert.Equal(primary, addr)
+			},
+		},
+		{
+			name: "pick primary target when all targets are unavailable",
+			expect: func(t *testing.T, pick func(key string) (string, func(balancer.DoneInfo))) {
+				assert := assert.New(t)
+				primary, done := pick("foo")
+				for i := 0; i < 3; i++ {
+					done(balancer.DoneInfo{Err: status.Error(codes.Unavailable, "draining")})
+					_, done = pick("foo")
+				}
+				done(balancer.DoneInfo{Err: status.Error(codes.Unavailable, "draining")})
+
+				addr, _ := pick("foo")
+				assert.Equal(primary, addr)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			info := base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{}}
+			for _, addr := range []string{"127.0.0.1:8002", "127.0.0.2:8002", "127.0.0.3:8002"} {
+				info.ReadySCs[&mockSubConn{addr: addr}] = base.SubConnInfo{Address: resolver.Address{Addr: addr}}
+			}
+
+			builder := &consistentHashingPickerBuilder{unavailable: newUnavailableTargets(time.Minute)}
+			picker := builder.Build(info)
+			tc.expect(t, func(key string) (string, func(balancer.DoneInfo)) {
+				result, err := picker.Pick(balancer.PickInfo{Ctx: context.WithValue(context.Background(), ContextKey, key)})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return result.SubConn.(*mockSubConn).addr, result.Done
+			})
+		})
+	}
+}
+
+func TestUnavailableTargets(t *testing.T) {
+	assert := assert.New(t)
+	unavailable := newUnavailableTargets(10 * time.Millisecond)
+	assert.False(unavailable.contains("foo"))
+
+	unavailable.add("foo")
+	assert.True(unavailable.contains("foo"))
+	assert.False(unavailable.contains("bar"))
+
+	time.Sleep(20 * time.Millisecond)
+	assert.False(unavailable.contains("foo"))
+}
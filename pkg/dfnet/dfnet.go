@@ -19,6 +19,7 @@ package dfnet
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -30,9 +31,13 @@ const (
 	UNIX  NetworkType = "unix"
 	VSOCK NetworkType = "vsock"
 
-	TCPEndpointPrefix   string = "dns:///"
-	UnixEndpointPrefix  string = "unix://"
-	VsockEndpointPrefix string = "vsock://"
+	TCPEndpointPrefix          string = "dns:///"
+	UnixEndpointPrefix         string = "unix://"
+	UnixAbstractEndpointPrefix string = "unix-abstract:"
+	VsockEndpointPrefix        string = "vsock://"
+
+	// UnixAbstractPrefix is the prefix of unix socket address in abstract namespace.
+	UnixAbstractPrefix = "@"
 )
 
 type NetAddr struct {
@@ -44,6 +49,9 @@ type NetAddr struct {
 func (n NetAddr) GetEndpoint() string {
 	switch n.Type {
 	case UNIX:
+		if strings.HasPrefix(n.Addr, UnixAbstractPrefix) {
+			return UnixAbstractEndpointPrefix + strings.TrimPrefix(n.Addr, UnixAbstractPrefix)
+		}
 		return UnixEndpointPrefix + n.Addr
 	case VSOCK:
 		return VsockEndpointPrefix + n.Addr
@@ -67,6 +67,22 @@ func New(algorithm, encoded string) *Digest {
 	}
 }
 
+// HashFromAlgorithm returns hash instance corresponding to algorithm.
+func HashFromAlgorithm(algorithm string) (hash.Hash, error) {
+	switch algorithm {
+	case AlgorithmSHA1:
+		return sha1.New(), nil
+	case AlgorithmSHA256:
+		return sha256.New(), nil
+	case AlgorithmSHA512:
+		return sha512.New(), nil
+	case AlgorithmMD5:
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupport digest method: %s", algorithm)
+	}
+}
+
 // HashFile computes hash value corresponding to algorithm.
 func HashFile(path string, algorithm string) (string, error) {
 	f, err := os.Open(path)
@@ -75,18 +91,9 @@ func HashFile(path string, algorithm string) (string, error) {
 	}
 	defer f.Close()
 
-	var h hash.Hash
-	switch algorithm {
-	case AlgorithmSHA1:
-		h = sha1.New()
-	case AlgorithmSHA256:
-		h = sha256.New()
-	case AlgorithmSHA512:
-		h = sha512.New()
-	case AlgorithmMD5:
-		h = md5.New()
-	default:
-		return "", fmt.Errorf("unsupport digest method: %s", algorithm)
+	h, err := HashFromAlgorithm(algorithm)
+	if err != nil {
+		return "", err
 	}
 
 	r := bufio.NewReader(f)
@@ -98,8 +105,10 @@ func HashFile(path string, algorithm string) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-// Parse uses to parse digest string to algorithm and encoded.
+// Parse uses to parse digest string to algorithm and encoded,
+// range digests are ignored, use ParseRange to parse them.
 func Parse(digest string) (*Digest, error) {
+	digest, _, _ = strings.Cut(digest, RangeSeparator)
 	values := strings.Split(digest, ":")
 	if len(values) == 2 {
 		return &Digest{
@@ -98,3 +98,55 @@ func TestNewReader(t *testing.T) {
 		})
 	}
 }
+
+func TestReader_Digest(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	data := []byte("hello world")
+	testCases := []struct {
+		name      string
+		algorithm string
+		expect    func(data []byte) string
+	}{
+		{
+			name:      "md5",
+			algorithm: AlgorithmMD5,
+			expect: func(data []byte) string {
+				hash := md5.New()
+				hash.Write(data)
+				return hex.EncodeToString(hash.Sum(nil))
+			},
+		},
+		{
+			name:      "sha256",
+			algorithm: AlgorithmSHA256,
+			expect: func(data []byte) string {
+				hash := sha256.New()
+				hash.Write(data)
+				return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+			},
+		},
+		{
+			name:      "sha512",
+			algorithm: AlgorithmSHA512,
+			expect: func(data []byte) string {
+				hash := sha512.New()
+				hash.Write(data)
+				return "sha512:" + hex.EncodeToString(hash.Sum(nil))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewBuffer(data), WithAlgorithm(tc.algorithm))
+			assert.Nil(err)
+			_, err = io.ReadAll(reader)
+			assert.Nil(err)
+			assert.Equal(tc.expect(data), reader.(Reader).Digest())
+		})
+	}
+
+	_, err := NewReader(bytes.NewBuffer(data), WithAlgorithm("foo"))
+	assert.Error(err)
+}
@@ -0,0 +1,88 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package digest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RangeSeparator separates the digest of content and the digests of its ranges.
+const RangeSeparator = ";"
+
+// RangeDigest is the digest layout of content split into ranges with the same size,
+// its string form is "<algorithm>:<encoded>;<range size>:<range encoded>,<range encoded>,...",
+// e.g. "sha256:aaa;4194304:bbb,ccc" and the last range may be shorter than the others.
+// Ranges are hashed with the algorithm of the content digest.
+type RangeDigest struct {
+	// Digest is the digest of the whole content.
+	*Digest
+
+	// RangeSize is the size of each range.
+	RangeSize int64
+
+	// Ranges is the encoded digests of ranges in order.
+	Ranges []string
+}
+
+// String returns range digest string.
+func (d *RangeDigest) String() string {
+	if len(d.Ranges) == 0 {
+		return d.Digest.String()
+	}
+
+	return fmt.Sprintf("%s%s%d:%s", d.Digest.String(), RangeSeparator, d.RangeSize, strings.Join(d.Ranges, ","))
+}
+
+// ParseRange parses digest string with optional range digests,
+// digest without ranges returns range digest with empty ranges.
+func ParseRange(digest string) (*RangeDigest, error) {
+	content, ranges, found := strings.Cut(digest, RangeSeparator)
+	d, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return &RangeDigest{Digest: d}, nil
+	}
+
+	values := strings.Split(ranges, ":")
+	if len(values) != 2 {
+		return nil, errors.New("invalid range digest")
+	}
+
+	rangeSize, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || rangeSize <= 0 {
+		return nil, fmt.Errorf("invalid range size %s", values[0])
+	}
+
+	encodeds := strings.Split(values[1], ",")
+	for _, encoded := range encodeds {
+		if encoded == "" {
+			return nil, errors.New("empty range digest")
+		}
+	}
+
+	return &RangeDigest{
+		Digest:    d,
+		RangeSize: rangeSize,
+		Ranges:    encodeds,
+	}, nil
+}
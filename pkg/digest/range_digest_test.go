@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package digest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		digest string
+		expect func(t *testing.T, d *RangeDigest, err error)
+	}{
+		{
+			name:   "digest without ranges",
+			digest: "sha256:foo",
+			expect: func(t *testing.T, d *RangeDigest, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(AlgorithmSHA256, d.Algorithm)
+				assert.Equal("foo", d.Encoded)
+				assert.Empty(d.Ranges)
+				assert.Equal("sha256:foo", d.String())
+			},
+		},
+		{
+			name:   "digest with ranges",
+			digest: "sha256:foo;1024:bar,baz",
+			expect: func(t *testing.T, d *RangeDigest, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(AlgorithmSHA256, d.Algorithm)
+				assert.Equal("foo", d.Encoded)
+				assert.Equal(int64(1024), d.RangeSize)
+				assert.Equal([]string{"bar", "baz"}, d.Ranges)
+				assert.Equal("sha256:foo;1024:bar,baz", d.String())
+			},
+		},
+		{
+			name:   "invalid range size",
+			digest: "sha256:foo;0:bar",
+			expect: func(t *testing.T, d *RangeDigest, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:   "invalid ranges",
+			digest: "sha256:foo;1024",
+			expect: func(t *testing.T, d *RangeDigest, err error) {
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:   "empty range",
+			digest: "sha256:foo;1024:bar,",
+			expect: func(t *testing.T, d *RangeDigest, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := ParseRange(tc.digest)
+			tc.expect(t, d, err)
+		})
+	}
+}
+
+func TestParseIgnoresRanges(t *testing.T) {
+	d, err := Parse("sha256:foo;1024:bar,baz")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:foo", d.String())
+}
@@ -29,6 +29,10 @@ type GC interface {
 	// Add adds GC task.
 	Add(Task) error
 
+	// Update updates interval and timeout of the added GC task,
+	// the running GC task is rescheduled with new interval.
+	Update(Task) error
+
 	// Run GC task.
 	Run(string) error
 
@@ -44,9 +48,11 @@ type GC interface {
 
 // GC provides task release function.
 type gc struct {
-	tasks  *sync.Map
-	logger Logger
-	done   chan bool
+	tasks *sync.Map
+	// updates notifies the running GC tasks that they are updated
+	updates *sync.Map
+	logger  Logger
+	done    chan bool
 }
 
 // Option is a functional option for configuring the GC.
@@ -62,9 +68,10 @@ func WithLogger(logger Logger) Option {
 // New returns a new GC instence.
 func New(options ...Option) GC {
 	g := &gc{
-		tasks:  &sync.Map{},
-		logger: &gcLogger{},
-		done:   make(chan bool),
+		tasks:   &sync.Map{},
+		updates: &sync.Map{},
+		logger:  &gcLogger{},
+		done:    make(chan bool),
 	}
 
 	for _, opt := range options {
@@ -83,6 +90,27 @@ func (g gc) Add(t Task) error {
 	return nil
 }
 
+func (g gc) Update(t Task) error {
+	if err := t.validate(); err != nil {
+		return err
+	}
+
+	if _, ok := g.tasks.Load(t.ID); !ok {
+		return fmt.Errorf("can not find task %s", t.ID)
+	}
+
+	g.tasks.Store(t.ID, t)
+	if update, ok := g.updates.Load(t.ID); ok {
+		// Pending notification is enough, the latest task is loaded when it is received.
+		select {
+		case update.(chan struct{}) <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
 func (g gc) Run(id string) error {
 	v, ok := g.tasks.Load(id)
 	if !ok {
@@ -99,13 +127,24 @@ func (g gc) RunAll() {
 
 func (g gc) Serve() {
 	g.tasks.Range(func(k, v any) bool {
+		update := make(chan struct{}, 1)
+		g.updates.Store(k, update)
+
 		go func() {
 			task := v.(Task)
 			tick := time.NewTicker(task.Interval)
+			defer tick.Stop()
+
 			for {
 				select {
 				case <-tick.C:
 					g.run(task)
+				case <-update:
+					if v, ok := g.tasks.Load(k); ok {
+						task = v.(Task)
+						tick.Reset(task.Interval)
+						g.logger.Infof("%s GC interval is updated to %s", k, task.Interval)
+					}
 				case <-g.done:
 					g.logger.Infof("%s GC stop", k)
 					return
@@ -300,3 +300,109 @@ func TestGCServe(t *testing.T) {
 	gc.Serve()
 	gc.Stop()
 }
+
+func TestGCUpdate(t *testing.T) {
+	tests := []struct {
+		name   string
+		task   Task
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "update GC task",
+			task: Task{
+				ID:       "foo",
+				Interval: 4 * time.Second,
+				Timeout:  2 * time.Second,
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "update GC task without interval",
+			task: Task{
+				ID:       "foo",
+				Interval: 0,
+				Timeout:  2 * time.Second,
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "Interval value is greater than 0")
+			},
+		},
+		{
+			name: "update GC task which can not be found",
+			task: Task{
+				ID:       "bar",
+				Interval: 4 * time.Second,
+				Timeout:  2 * time.Second,
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "can not find task bar")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			mockLogger := NewMockLogger(ctl)
+			mockRunner := NewMockRunner(ctl)
+
+			gc := New(WithLogger(mockLogger))
+			if err := gc.Add(Task{
+				ID:       "foo",
+				Interval: 2 * time.Second,
+				Timeout:  1 * time.Second,
+				Runner:   mockRunner,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.task.Runner = mockRunner
+			tc.expect(t, gc.Update(tc.task))
+		})
+	}
+}
+
+func TestGCServeUpdate(t *testing.T) {
+	ctl := gomock.NewController(t)
+	mockLogger := NewMockLogger(ctl)
+	mockRunner := NewMockRunner(ctl)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	gc := New(WithLogger(mockLogger))
+	if err := gc.Add(Task{
+		ID:       "foo",
+		Interval: 2 * time.Hour,
+		Timeout:  1 * time.Hour,
+		Runner:   mockRunner,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var once sync.Once
+	mockLogger.EXPECT().Infof(gomock.Any(), gomock.Any()).AnyTimes()
+	mockRunner.EXPECT().RunGC().Do(func() {
+		once.Do(wg.Done)
+	}).Return(nil).MinTimes(1)
+
+	gc.Serve()
+	defer gc.Stop()
+
+	// Running GC task is rescheduled with the updated interval.
+	if err := gc.Update(Task{
+		ID:       "foo",
+		Interval: 10 * time.Millisecond,
+		Timeout:  10 * time.Millisecond,
+		Runner:   mockRunner,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Wait()
+}
@@ -45,6 +45,8 @@ func ParentTaskID(url string, meta *commonv1.UrlMeta) string {
 // taskID generates a task id.
 // filter is separated by & character.
 func taskID(url string, meta *commonv1.UrlMeta, ignoreRange bool) string {
+	opts := taskIDOptions.Load().(*TaskIDOptions)
+	url = opts.canonicalize(url)
 	if meta == nil {
 		return digest.SHA256FromStrings(url)
 	}
@@ -77,6 +79,10 @@ func taskID(url string, meta *commonv1.UrlMeta, ignoreRange bool) string {
 		data = append(data, meta.Application)
 	}
 
+	if version := opts.version(meta); version != "" {
+		data = append(data, version)
+	}
+
 	return digest.SHA256FromStrings(data...)
 }
 
@@ -0,0 +1,172 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package idgen
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+)
+
+// DefaultTrackingQueryParams is the common tracking query params which do not change the content.
+var DefaultTrackingQueryParams = []string{"utm_*", "gclid", "fbclid", "msclkid", "spm"}
+
+// Canonicalizer rewrites the url in place, urls which are equivalent after canonicalization
+// generate the same task id.
+type Canonicalizer func(u *url.URL)
+
+// TaskIDOptions is the options of task id generation, the zero value keeps the url as it is.
+type TaskIDOptions struct {
+	// Canonicalizers are applied to the url in order before the task id is generated.
+	Canonicalizers []Canonicalizer
+
+	// VersionHeader is the header in url meta whose value salts the task id,
+	// bumping the version of content generates a new task id.
+	VersionHeader string
+}
+
+// TaskIDConfig is the configuration of task id generation, it must be the same
+// among the daemons and schedulers serving the same tasks.
+type TaskIDConfig struct {
+	// SortQuery sorts the query params of url, urls only different in the order of query params share one task
+	SortQuery bool `mapstructure:"sortQuery" yaml:"sortQuery"`
+	// LowercaseHost lowercases the scheme and host of url
+	LowercaseHost bool `mapstructure:"lowercaseHost" yaml:"lowercaseHost"`
+	// StripTrackingQuery removes the common tracking query params, like utm_source and gclid
+	StripTrackingQuery bool `mapstructure:"stripTrackingQuery" yaml:"stripTrackingQuery"`
+	// StripQuery removes the query params matched by the patterns, like session or x-*
+	StripQuery []string `mapstructure:"stripQuery" yaml:"stripQuery"`
+	// VersionHeader is the header of request whose value salts the task id,
+	// bumping the content version downloads a new task instead of the cached one
+	VersionHeader string `mapstructure:"versionHeader" yaml:"versionHeader"`
+}
+
+// Options converts the configuration to the options of task id generation.
+func (c TaskIDConfig) Options() *TaskIDOptions {
+	var canonicalizers []Canonicalizer
+	if c.LowercaseHost {
+		canonicalizers = append(canonicalizers, LowercaseHost)
+	}
+
+	var patterns []string
+	if c.StripTrackingQuery {
+		patterns = append(patterns, DefaultTrackingQueryParams...)
+	}
+	patterns = append(patterns, c.StripQuery...)
+	if len(patterns) > 0 {
+		canonicalizers = append(canonicalizers, StripQuery(patterns...))
+	}
+
+	if c.SortQuery {
+		canonicalizers = append(canonicalizers, SortQuery)
+	}
+
+	return &TaskIDOptions{
+		Canonicalizers: canonicalizers,
+		VersionHeader:  c.VersionHeader,
+	}
+}
+
+var taskIDOptions atomic.Value
+
+func init() {
+	taskIDOptions.Store(&TaskIDOptions{})
+}
+
+// SetTaskIDOptions sets the options of task id generation for the process,
+// the options must be the same among the peers downloading the same tasks.
+func SetTaskIDOptions(opts *TaskIDOptions) {
+	if opts == nil {
+		opts = &TaskIDOptions{}
+	}
+
+	taskIDOptions.Store(opts)
+}
+
+// SortQuery sorts the query params of url by key.
+func SortQuery(u *url.URL) {
+	if u.RawQuery == "" {
+		return
+	}
+
+	u.RawQuery = u.Query().Encode()
+}
+
+// LowercaseHost lowercases the scheme and host of url, which are case insensitive.
+func LowercaseHost(u *url.URL) {
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+}
+
+// StripQuery returns a canonicalizer which removes the query params matched by patterns,
+// pattern supports shell wildcards like utm_*.
+func StripQuery(patterns ...string) Canonicalizer {
+	return func(u *url.URL) {
+		if u.RawQuery == "" {
+			return
+		}
+
+		values := u.Query()
+		for key := range values {
+			for _, pattern := range patterns {
+				if matched, _ := path.Match(pattern, key); matched {
+					values.Del(key)
+					break
+				}
+			}
+		}
+
+		u.RawQuery = values.Encode()
+	}
+}
+
+// canonicalize applies the canonicalizers in options to url,
+// url is returned as it is when it can not be parsed.
+func (o *TaskIDOptions) canonicalize(rawURL string) string {
+	if len(o.Canonicalizers) == 0 {
+		return rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	for _, canonicalize := range o.Canonicalizers {
+		canonicalize(u)
+	}
+
+	return u.String()
+}
+
+// version returns the content version in url meta.
+func (o *TaskIDOptions) version(meta *commonv1.UrlMeta) string {
+	if o.VersionHeader == "" || meta == nil {
+		return ""
+	}
+
+	for key, value := range meta.Header {
+		if strings.EqualFold(key, o.VersionHeader) {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -105,3 +105,76 @@ func TestTaskID(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskID_Options(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *TaskIDOptions
+		url    string
+		meta   *commonv1.UrlMeta
+		expect func(t *testing.T, taskID string)
+	}{
+		{
+			name: "url is kept as it is by default",
+			opts: &TaskIDOptions{},
+			url:  "https://Example.com/foo?b=2&a=1",
+			meta: &commonv1.UrlMeta{},
+			expect: func(t *testing.T, taskID string) {
+				assert := assert.New(t)
+				assert.NotEqual(TaskID("https://example.com/foo?a=1&b=2", &commonv1.UrlMeta{}), taskID)
+			},
+		},
+		{
+			name: "sort query and lowercase host",
+			opts: &TaskIDOptions{Canonicalizers: []Canonicalizer{LowercaseHost, SortQuery}},
+			url:  "HTTPS://Example.com/Foo?b=2&a=1",
+			meta: &commonv1.UrlMeta{},
+			expect: func(t *testing.T, taskID string) {
+				assert := assert.New(t)
+				assert.Equal(TaskID("https://example.com/Foo?a=1&b=2", &commonv1.UrlMeta{}), taskID)
+				assert.NotEqual(TaskID("https://example.com/foo?a=1&b=2", &commonv1.UrlMeta{}), taskID)
+			},
+		},
+		{
+			name: "strip tracking query",
+			opts: &TaskIDOptions{Canonicalizers: []Canonicalizer{StripQuery(DefaultTrackingQueryParams...)}},
+			url:  "https://example.com/foo?utm_source=bar&utm_medium=baz&gclid=1&a=1",
+			meta: nil,
+			expect: func(t *testing.T, taskID string) {
+				assert := assert.New(t)
+				assert.Equal(TaskID("https://example.com/foo?a=1", nil), taskID)
+			},
+		},
+		{
+			name: "salt with content version",
+			opts: &TaskIDOptions{VersionHeader: "X-Content-Version"},
+			url:  "https://example.com/foo",
+			meta: &commonv1.UrlMeta{Header: map[string]string{"x-content-version": "v2"}},
+			expect: func(t *testing.T, taskID string) {
+				assert := assert.New(t)
+				assert.NotEqual(TaskID("https://example.com/foo", &commonv1.UrlMeta{}), taskID)
+				assert.NotEqual(TaskID("https://example.com/foo", &commonv1.UrlMeta{Header: map[string]string{"X-Content-Version": "v1"}}), taskID)
+				assert.Equal(TaskID("https://example.com/foo", &commonv1.UrlMeta{Header: map[string]string{"X-Content-Version": "v2"}}), taskID)
+			},
+		},
+		{
+			name: "invalid url is kept as it is",
+			opts: &TaskIDOptions{Canonicalizers: []Canonicalizer{LowercaseHost}},
+			url:  "://Example.com",
+			meta: nil,
+			expect: func(t *testing.T, taskID string) {
+				assert := assert.New(t)
+				SetTaskIDOptions(nil)
+				assert.Equal(TaskID("://Example.com", nil), taskID)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer SetTaskIDOptions(nil)
+			SetTaskIDOptions(tc.opts)
+			tc.expect(t, TaskID(tc.url, tc.meta))
+		})
+	}
+}
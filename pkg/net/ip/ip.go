@@ -24,10 +24,14 @@ import (
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
 
-var IPv4 string
+var (
+	IPv4 string
+	IPv6 string
+)
 
 const (
 	internalIPv4 = "127.0.0.1"
+	internalIPv6 = "::1"
 )
 
 func init() {
@@ -39,6 +43,80 @@ func init() {
 	} else {
 		IPv4 = ip
 	}
+
+	ip, err = externalIPv6()
+	if err != nil {
+		logger.Infof("Failed to get IPv6 address: %s, use %s as IPv6 addr", err.Error(), internalIPv6)
+		IPv6 = internalIPv6
+	} else {
+		IPv6 = ip
+	}
+}
+
+// Advertise returns the ip advertised to others, IPv6 is returned when preferIPv6 is set
+// or there is no external IPv4 in IPv6 only network.
+func Advertise(preferIPv6 bool) string {
+	if IPv6 != internalIPv6 && (preferIPv6 || IPv4 == internalIPv4) {
+		return IPv6
+	}
+
+	return IPv4
+}
+
+// IsIPv6 returns whether ip is an IPv6 address.
+func IsIPv6(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() == nil
+}
+
+// InterfaceIP returns the ip of the network interface, like eth1 or a SR-IOV VF,
+// the global unicast IPv6 is returned when preferIPv6 is set and the interface has one.
+func InterfaceIP(name string, preferIPv6 bool) (string, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return "", err
+	}
+
+	if iface.Flags&net.FlagUp == 0 {
+		return "", fmt.Errorf("interface %s is down", name)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", err
+	}
+
+	var ipv4, ipv6 net.IP
+	for _, addr := range addrs {
+		var ip net.IP
+		switch v := addr.(type) {
+		case *net.IPNet:
+			ip = v.IP
+		case *net.IPAddr:
+			ip = v.IP
+		}
+		if ip == nil || ip.IsLinkLocalUnicast() {
+			continue
+		}
+
+		if ip.To4() != nil {
+			if ipv4 == nil {
+				ipv4 = ip
+			}
+		} else if ipv6 == nil && (ip.IsGlobalUnicast() || ip.IsLoopback()) {
+			ipv6 = ip
+		}
+	}
+
+	if ipv6 != nil && (preferIPv6 || ipv4 == nil) {
+		return ipv6.String(), nil
+	}
+
+	if ipv4 == nil {
+		return "", fmt.Errorf("can not found ip of interface %s", name)
+	}
+
+	return ipv4.String(), nil
 }
 
 // externalIPv4 returns the available IPv4.
@@ -64,6 +142,23 @@ func externalIPv4() (string, error) {
 	return externalIPs[0], nil
 }
 
+// externalIPv6 returns the available global unicast IPv6.
+func externalIPv6() (string, error) {
+	ips, err := ipAddrs()
+	if err != nil {
+		return "", err
+	}
+
+	for _, ip := range ips {
+		if ip.To4() != nil || !ip.IsGlobalUnicast() {
+			continue // not an ipv6 address or not routable
+		}
+		return ip.String(), nil
+	}
+
+	return "", fmt.Errorf("can not found external ipv6")
+}
+
 // ipAddrs returns all the valid IPs available.
 // refer to https://github.com/dragonflyoss/Dragonfly2/pull/652
 func ipAddrs() ([]net.IP, error) {
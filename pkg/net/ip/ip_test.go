@@ -17,6 +17,7 @@
 package ip
 
 import (
+	"net"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,3 +28,78 @@ func TestExternalIPv4(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEmpty(t, ip)
 }
+
+func TestInterfaceIP(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	assert.Nil(t, err)
+
+	var loopback string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 && iface.Flags&net.FlagUp != 0 {
+			loopback = iface.Name
+			break
+		}
+	}
+	if loopback == "" {
+		t.Skip("no loopback interface")
+	}
+
+	ip, err := InterfaceIP(loopback, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", ip)
+
+	_, err = InterfaceIP("d7y-not-exist", false)
+	assert.NotNil(t, err)
+}
+
+func TestIsIPv6(t *testing.T) {
+	tests := []struct {
+		name   string
+		ip     string
+		expect bool
+	}{
+		{
+			name:   "ipv4",
+			ip:     "192.168.1.1",
+			expect: false,
+		},
+		{
+			name:   "ipv6",
+			ip:     "fd00::1",
+			expect: true,
+		},
+		{
+			name:   "ipv4 mapped ipv6",
+			ip:     "::ffff:192.168.1.1",
+			expect: false,
+		},
+		{
+			name:   "invalid ip",
+			ip:     "foo",
+			expect: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, IsIPv6(tc.ip))
+		})
+	}
+}
+
+func TestAdvertise(t *testing.T) {
+	ipv4, ipv6 := IPv4, IPv6
+	defer func() {
+		IPv4, IPv6 = ipv4, ipv6
+	}()
+
+	IPv4, IPv6 = "192.168.1.1", "fd00::1"
+	assert.Equal(t, "192.168.1.1", Advertise(false))
+	assert.Equal(t, "fd00::1", Advertise(true))
+
+	IPv4, IPv6 = internalIPv4, "fd00::1"
+	assert.Equal(t, "fd00::1", Advertise(false))
+
+	IPv4, IPv6 = "192.168.1.1", internalIPv6
+	assert.Equal(t, "192.168.1.1", Advertise(true))
+}
@@ -0,0 +1,51 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package netns runs network operations in linux net namespaces and binds sockets to network interfaces.
+package netns
+
+import (
+	"runtime"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// Do runs fn in the net namespace, like /proc/1/ns/net, fn runs in the current
+// net namespace when namespace is empty. Sockets created in fn stay in the namespace.
+func Do(namespace string, fn func() error) error {
+	if namespace == "" {
+		return fn()
+	}
+
+	runtime.LockOSThread()
+	recoverFunc, err := Switch(namespace)
+	if err != nil {
+		runtime.UnlockOSThread()
+		logger.Errorf("failed to change net namespace: %v", err)
+		return err
+	}
+
+	defer func() {
+		// the thread is terminated with the goroutine when the net namespace is not recovered
+		if err := recoverFunc(); err != nil {
+			logger.Errorf("failed to recover net namespace: %v", err)
+			return
+		}
+		runtime.UnlockOSThread()
+	}()
+
+	return fn()
+}
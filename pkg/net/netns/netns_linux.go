@@ -17,18 +17,21 @@
  * limitations under the License.
  */
 
-package daemon
+package netns
 
 import (
 	"fmt"
 	"os"
+	"syscall"
 
 	"golang.org/x/sys/unix"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
 
-func switchNetNamespace(target string) (func() error, error) {
+// Switch switches the net namespace of current thread to target, the returned
+// function recovers the original net namespace. The caller should lock the os thread.
+func Switch(target string) (func() error, error) {
 	fd, err := unix.Open(target, unix.O_RDONLY, 0)
 	if err != nil {
 		return nil, err
@@ -62,3 +65,21 @@ func switchNetNamespace(target string) (func() error, error) {
 		return nil
 	}, nil
 }
+
+// BindToDevice returns the control function of net.Dialer and net.ListenConfig,
+// which binds the socket to the network interface by SO_BINDTODEVICE.
+func BindToDevice(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		if err := c.Control(func(fd uintptr) {
+			sockErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, name)
+		}); err != nil {
+			return err
+		}
+
+		if sockErr != nil {
+			return fmt.Errorf("bind to interface %s: %w", name, sockErr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,39 @@
+//go:build !linux
+// +build !linux
+
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package netns
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Switch is a no-op, net namespace is only supported in linux.
+func Switch(target string) (func() error, error) {
+	return func() error {
+		return nil
+	}, nil
+}
+
+// BindToDevice returns the control function which always fails, binding to network interface is only supported in linux.
+func BindToDevice(name string) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		return errors.New("binding to network interface is only supported in linux")
+	}
+}
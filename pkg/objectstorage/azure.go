@@ -0,0 +1,305 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+type azure struct {
+	// Azure blob service client.
+	client *azblob.ServiceClient
+}
+
+// New azure instance, access key is the storage account name and
+// secret key is the storage account key.
+func newAzure(region, endpoint, accessKey, secretKey string) (ObjectStorage, error) {
+	cred, err := azblob.NewSharedKeyCredential(accessKey, secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("new azure shared key credential failed: %s", err)
+	}
+
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net/", accessKey)
+	}
+
+	client, err := azblob.NewServiceClientWithSharedKey(endpoint, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new azure client failed: %s", err)
+	}
+
+	return &azure{
+		client: client,
+	}, nil
+}
+
+// GetBucketMetadata returns metadata of bucket.
+func (a *azure) GetBucketMetadata(ctx context.Context, bucketName string) (*BucketMetadata, error) {
+	containerClient, err := a.client.NewContainerClient(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := containerClient.GetProperties(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Azure container has no creation time, last modified time is used instead.
+	metadata := &BucketMetadata{
+		Name: bucketName,
+	}
+	if resp.LastModified != nil {
+		metadata.CreateAt = *resp.LastModified
+	}
+
+	return metadata, nil
+}
+
+// CreateBucket creates bucket of object storage.
+func (a *azure) CreateBucket(ctx context.Context, bucketName string) error {
+	_, err := a.client.CreateContainer(ctx, bucketName, nil)
+	return err
+}
+
+// DeleteBucket deletes bucket of object storage.
+func (a *azure) DeleteBucket(ctx context.Context, bucketName string) error {
+	_, err := a.client.DeleteContainer(ctx, bucketName, nil)
+	return err
+}
+
+// ListBucketMetadatas returns metadata of buckets.
+func (a *azure) ListBucketMetadatas(ctx context.Context) ([]*BucketMetadata, error) {
+	var metadatas []*BucketMetadata
+	pager := a.client.ListContainers(nil)
+	for pager.NextPage(ctx) {
+		for _, container := range pager.PageResponse().ContainerItems {
+			metadata := &BucketMetadata{}
+			if container.Name != nil {
+				metadata.Name = *container.Name
+			}
+
+			if container.Properties != nil && container.Properties.LastModified != nil {
+				metadata.CreateAt = *container.Properties.LastModified
+			}
+
+			metadatas = append(metadatas, metadata)
+		}
+	}
+
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+
+	return metadatas, nil
+}
+
+// GetObjectMetadata returns metadata of object.
+func (a *azure) GetObjectMetadata(ctx context.Context, bucketName, objectKey string) (*ObjectMetadata, bool, error) {
+	blobClient, err := a.newBlobClient(bucketName, objectKey)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resp, err := blobClient.GetProperties(ctx, nil)
+	if err != nil {
+		var serr *azblob.StorageError
+		if errors.As(err, &serr) && serr.StatusCode() == http.StatusNotFound {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return &ObjectMetadata{
+		Key:                objectKey,
+		ContentDisposition: stringValue(resp.ContentDisposition),
+		ContentEncoding:    stringValue(resp.ContentEncoding),
+		ContentLanguage:    stringValue(resp.ContentLanguage),
+		ContentLength:      int64Value(resp.ContentLength),
+		ContentType:        stringValue(resp.ContentType),
+		ETag:               stringValue(resp.ETag),
+		Digest:             resp.Metadata[MetaDigest],
+	}, true, nil
+}
+
+// GetOject returns data of object.
+func (a *azure) GetOject(ctx context.Context, bucketName, objectKey string) (io.ReadCloser, error) {
+	blobClient, err := a.newBlobClient(bucketName, objectKey)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := blobClient.Download(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body(nil), nil
+}
+
+// PutObject puts data of object.
+func (a *azure) PutObject(ctx context.Context, bucketName, objectKey, digest string, reader io.Reader) error {
+	containerClient, err := a.client.NewContainerClient(bucketName)
+	if err != nil {
+		return err
+	}
+
+	blockBlobClient, err := containerClient.NewBlockBlobClient(objectKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = blockBlobClient.UploadStream(ctx, reader, azblob.UploadStreamOptions{
+		Metadata: map[string]string{MetaDigest: digest},
+	})
+
+	return err
+}
+
+// DeleteObject deletes data of object.
+func (a *azure) DeleteObject(ctx context.Context, bucketName, objectKey string) error {
+	blobClient, err := a.newBlobClient(bucketName, objectKey)
+	if err != nil {
+		return err
+	}
+
+	_, err = blobClient.Delete(ctx, nil)
+	return err
+}
+
+// ListObjectMetadatas returns metadata of objects.
+func (a *azure) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*ObjectMetadata, error) {
+	containerClient, err := a.client.NewContainerClient(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &azblob.ContainerListBlobsFlatOptions{
+		Prefix:     &prefix,
+		MaxResults: to.Ptr(int32(limit)),
+	}
+	if marker != "" {
+		options.Marker = &marker
+	}
+
+	pager := containerClient.ListBlobsFlat(options)
+	if !pager.NextPage(ctx) {
+		return nil, pager.Err()
+	}
+
+	var metadatas []*ObjectMetadata
+	segment := pager.PageResponse().Segment
+	if segment == nil {
+		return metadatas, nil
+	}
+
+	for _, blob := range segment.BlobItems {
+		metadata := &ObjectMetadata{
+			Key: stringValue(blob.Name),
+		}
+
+		if blob.Properties != nil {
+			metadata.ETag = stringValue(blob.Properties.Etag)
+		}
+
+		metadatas = append(metadatas, metadata)
+	}
+
+	return metadatas, nil
+}
+
+// IsObjectExist returns whether the object exists.
+func (a *azure) IsObjectExist(ctx context.Context, bucketName, objectKey string) (bool, error) {
+	_, isExist, err := a.GetObjectMetadata(ctx, bucketName, objectKey)
+	if err != nil {
+		return false, err
+	}
+
+	return isExist, nil
+}
+
+// GetSignURL returns sign url of object.
+func (a *azure) GetSignURL(ctx context.Context, bucketName, objectKey string, method Method, expire time.Duration) (string, error) {
+	var permissions azblob.BlobSASPermissions
+	switch method {
+	case MethodGet, MethodHead:
+		permissions.Read = true
+	case MethodPut:
+		permissions.Create = true
+		permissions.Write = true
+	case MethodDelete:
+		permissions.Delete = true
+	case MethodList:
+		containerClient, err := a.client.NewContainerClient(bucketName)
+		if err != nil {
+			return "", err
+		}
+
+		return containerClient.GetSASURL(azblob.ContainerSASPermissions{List: true}, time.Now(), time.Now().Add(expire))
+	default:
+		return "", fmt.Errorf("not support method %s", method)
+	}
+
+	blobClient, err := a.newBlobClient(bucketName, objectKey)
+	if err != nil {
+		return "", err
+	}
+
+	sas, err := blobClient.GetSASToken(permissions, time.Now(), time.Now().Add(expire))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s?%s", blobClient.URL(), sas.Encode()), nil
+}
+
+// newBlobClient returns blob client of object.
+func (a *azure) newBlobClient(bucketName, objectKey string) (*azblob.BlobClient, error) {
+	containerClient, err := a.client.NewContainerClient(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return containerClient.NewBlobClient(objectKey)
+}
+
+// stringValue returns the value of string pointer.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// int64Value returns the value of int64 pointer.
+func int64Value(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+
+	return *i
+}
@@ -22,6 +22,12 @@ const (
 
 	// ServiceNameOSS is name of oss storage.
 	ServiceNameOSS = "oss"
+
+	// ServiceNameAzure is name of azure blob storage.
+	ServiceNameAzure = "azure"
+
+	// ServiceNameGCS is name of google cloud storage.
+	ServiceNameGCS = "gcs"
 )
 
 const (
@@ -0,0 +1,223 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+type gcs struct {
+	// GCS client.
+	client *storage.Client
+
+	// Project ID of buckets.
+	projectID string
+
+	// Location of created buckets.
+	location string
+}
+
+// New gcs instance, access key is the project id and
+// secret key is the json credentials of service account.
+func newGCS(region, endpoint, accessKey, secretKey string) (ObjectStorage, error) {
+	options := []option.ClientOption{option.WithCredentialsJSON([]byte(secretKey))}
+	if endpoint != "" {
+		options = append(options, option.WithEndpoint(endpoint))
+	}
+
+	client, err := storage.NewClient(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("new gcs client failed: %s", err)
+	}
+
+	return &gcs{
+		client:    client,
+		projectID: accessKey,
+		location:  region,
+	}, nil
+}
+
+// GetBucketMetadata returns metadata of bucket.
+func (g *gcs) GetBucketMetadata(ctx context.Context, bucketName string) (*BucketMetadata, error) {
+	attrs, err := g.client.Bucket(bucketName).Attrs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BucketMetadata{
+		Name:     attrs.Name,
+		CreateAt: attrs.Created,
+	}, nil
+}
+
+// CreateBucket creates bucket of object storage.
+func (g *gcs) CreateBucket(ctx context.Context, bucketName string) error {
+	return g.client.Bucket(bucketName).Create(ctx, g.projectID, &storage.BucketAttrs{Location: g.location})
+}
+
+// DeleteBucket deletes bucket of object storage.
+func (g *gcs) DeleteBucket(ctx context.Context, bucketName string) error {
+	return g.client.Bucket(bucketName).Delete(ctx)
+}
+
+// ListBucketMetadatas returns metadata of buckets.
+func (g *gcs) ListBucketMetadatas(ctx context.Context) ([]*BucketMetadata, error) {
+	var metadatas []*BucketMetadata
+	it := g.client.Buckets(ctx, g.projectID)
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		metadatas = append(metadatas, &BucketMetadata{
+			Name:     attrs.Name,
+			CreateAt: attrs.Created,
+		})
+	}
+
+	return metadatas, nil
+}
+
+// GetObjectMetadata returns metadata of object.
+func (g *gcs) GetObjectMetadata(ctx context.Context, bucketName, objectKey string) (*ObjectMetadata, bool, error) {
+	attrs, err := g.client.Bucket(bucketName).Object(objectKey).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	return &ObjectMetadata{
+		Key:                objectKey,
+		ContentDisposition: attrs.ContentDisposition,
+		ContentEncoding:    attrs.ContentEncoding,
+		ContentLanguage:    attrs.ContentLanguage,
+		ContentLength:      attrs.Size,
+		ContentType:        attrs.ContentType,
+		ETag:               attrs.Etag,
+		Digest:             attrs.Metadata[MetaDigest],
+	}, true, nil
+}
+
+// GetOject returns data of object.
+func (g *gcs) GetOject(ctx context.Context, bucketName, objectKey string) (io.ReadCloser, error) {
+	return g.client.Bucket(bucketName).Object(objectKey).NewReader(ctx)
+}
+
+// PutObject puts data of object.
+func (g *gcs) PutObject(ctx context.Context, bucketName, objectKey, digest string, reader io.Reader) error {
+	writer := g.client.Bucket(bucketName).Object(objectKey).NewWriter(ctx)
+	writer.Metadata = map[string]string{MetaDigest: digest}
+	if _, err := io.Copy(writer, reader); err != nil {
+		writer.Close()
+		return err
+	}
+
+	return writer.Close()
+}
+
+// DeleteObject deletes data of object.
+func (g *gcs) DeleteObject(ctx context.Context, bucketName, objectKey string) error {
+	return g.client.Bucket(bucketName).Object(objectKey).Delete(ctx)
+}
+
+// ListObjectMetadatas returns metadata of objects.
+func (g *gcs) ListObjectMetadatas(ctx context.Context, bucketName, prefix, marker string, limit int64) ([]*ObjectMetadata, error) {
+	// StartOffset is inclusive, so the object of marker is skipped.
+	it := g.client.Bucket(bucketName).Objects(ctx, &storage.Query{
+		Prefix:      prefix,
+		StartOffset: marker,
+	})
+
+	var metadatas []*ObjectMetadata
+	for limit <= 0 || int64(len(metadatas)) < limit {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if marker != "" && attrs.Name == marker {
+			continue
+		}
+
+		metadatas = append(metadatas, &ObjectMetadata{
+			Key:  attrs.Name,
+			ETag: attrs.Etag,
+		})
+	}
+
+	return metadatas, nil
+}
+
+// IsObjectExist returns whether the object exists.
+func (g *gcs) IsObjectExist(ctx context.Context, bucketName, objectKey string) (bool, error) {
+	_, isExist, err := g.GetObjectMetadata(ctx, bucketName, objectKey)
+	if err != nil {
+		return false, err
+	}
+
+	return isExist, nil
+}
+
+// GetSignURL returns sign url of object.
+func (g *gcs) GetSignURL(ctx context.Context, bucketName, objectKey string, method Method, expire time.Duration) (string, error) {
+	var httpMethod string
+	switch method {
+	case MethodGet:
+		httpMethod = http.MethodGet
+	case MethodPut:
+		httpMethod = http.MethodPut
+	case MethodHead:
+		httpMethod = http.MethodHead
+	case MethodPost:
+		httpMethod = http.MethodPost
+	case MethodDelete:
+		httpMethod = http.MethodDelete
+	case MethodList:
+		// Sign url of bucket lists the objects.
+		httpMethod = http.MethodGet
+		objectKey = ""
+	default:
+		return "", fmt.Errorf("not support method %s", method)
+	}
+
+	return g.client.Bucket(bucketName).SignedURL(objectKey, &storage.SignedURLOptions{
+		Method:  httpMethod,
+		Expires: time.Now().Add(expire),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
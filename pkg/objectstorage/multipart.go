@@ -0,0 +1,167 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	_ MultipartUploader = (*s3)(nil)
+	_ MultipartUploader = (*oss)(nil)
+)
+
+const (
+	// MinPartSize is the min size of parts except the last one.
+	MinPartSize = 5 * 1024 * 1024
+
+	// MaxPartCount is the max count of parts in a multipart upload.
+	MaxPartCount = 10000
+)
+
+// Part is the uploaded part of multipart upload.
+type Part struct {
+	// Number is part number, it starts from 1.
+	Number int
+
+	// ETag is etag of the uploaded part.
+	ETag string
+}
+
+// MultipartUploader is implemented by object storages supporting multipart upload, s3 and oss.
+type MultipartUploader interface {
+	// CreateMultipartUpload initiates a multipart upload and returns the upload id.
+	CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error)
+
+	// UploadPart uploads the part, contentMD5 is the base64 encoded md5 of the part verified by object storage.
+	UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, number int, contentMD5 string, reader io.ReadSeeker, size int64) (*Part, error)
+
+	// CompleteMultipartUpload assembles the uploaded parts into the object.
+	CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*Part) error
+
+	// AbortMultipartUpload aborts the multipart upload and deletes the uploaded parts.
+	AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error
+}
+
+// MultipartUpload uploads the object in parts concurrently, every part is verified
+// by object storage with its md5, and the multipart upload is aborted when any part failed.
+func MultipartUpload(ctx context.Context, uploader MultipartUploader, bucketName, objectKey, digest string,
+	reader io.ReaderAt, size, partSize int64, concurrency int) error {
+	if size <= 0 {
+		return errors.New("invalid size")
+	}
+
+	if partSize < MinPartSize {
+		partSize = MinPartSize
+	}
+
+	// Enlarge part size to keep part count under limit.
+	if count := (size + partSize - 1) / partSize; count > MaxPartCount {
+		partSize = (size + MaxPartCount - 1) / MaxPartCount
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	uploadID, err := uploader.CreateMultipartUpload(ctx, bucketName, objectKey, digest)
+	if err != nil {
+		return err
+	}
+
+	var (
+		count = int((size + partSize - 1) / partSize)
+		parts = make([]*Part, count)
+		sem   = make(chan struct{}, concurrency)
+	)
+	eg, egCtx := errgroup.WithContext(ctx)
+	for i := 0; i < count; i++ {
+		number := i + 1
+		offset := int64(i) * partSize
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-egCtx.Done():
+		}
+		if egCtx.Err() != nil {
+			break
+		}
+
+		eg.Go(func() error {
+			defer func() { <-sem }()
+			section := io.NewSectionReader(reader, offset, length)
+			hash := md5.New()
+			if _, err := io.Copy(hash, section); err != nil {
+				return err
+			}
+
+			if _, err := section.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+
+			part, err := uploader.UploadPart(egCtx, bucketName, objectKey, uploadID, number,
+				base64.StdEncoding.EncodeToString(hash.Sum(nil)), section, length)
+			if err != nil {
+				return fmt.Errorf("upload part %d failed: %w", number, err)
+			}
+
+			parts[number-1] = part
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		return abortMultipartUpload(uploader, bucketName, objectKey, uploadID, err)
+	}
+
+	// The loop may be broken by canceled context without error of parts.
+	if err := ctx.Err(); err != nil {
+		return abortMultipartUpload(uploader, bucketName, objectKey, uploadID, err)
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].Number < parts[j].Number
+	})
+
+	if err := uploader.CompleteMultipartUpload(ctx, bucketName, objectKey, uploadID, parts); err != nil {
+		return abortMultipartUpload(uploader, bucketName, objectKey, uploadID, err)
+	}
+
+	return nil
+}
+
+// abortMultipartUpload aborts the multipart upload and returns the cause.
+func abortMultipartUpload(uploader MultipartUploader, bucketName, objectKey, uploadID string, cause error) error {
+	// Use background context, the context of upload may be canceled.
+	if err := uploader.AbortMultipartUpload(context.Background(), bucketName, objectKey, uploadID); err != nil {
+		return fmt.Errorf("%s, abort multipart upload failed: %w", cause, err)
+	}
+
+	return cause
+}
@@ -0,0 +1,130 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package objectstorage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryUploader uploads parts in memory and verifies md5 of parts like object storage.
+type memoryUploader struct {
+	mu        sync.Mutex
+	parts     map[int][]byte
+	object    []byte
+	aborted   bool
+	failPart  int
+	completed bool
+}
+
+func (m *memoryUploader) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	m.parts = map[int][]byte{}
+	return "upload", nil
+}
+
+func (m *memoryUploader) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, number int, contentMD5 string, reader io.ReadSeeker, size int64) (*Part, error) {
+	if number == m.failPart {
+		return nil, errors.New("foo")
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("invalid size %d", len(data))
+	}
+
+	sum := md5.Sum(data)
+	if base64.StdEncoding.EncodeToString(sum[:]) != contentMD5 {
+		return nil, errors.New("bad digest")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.parts[number] = data
+	return &Part{Number: number, ETag: fmt.Sprint(number)}, nil
+}
+
+func (m *memoryUploader) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*Part) error {
+	if !sort.SliceIsSorted(parts, func(i, j int) bool { return parts[i].Number < parts[j].Number }) {
+		return errors.New("parts are not sorted")
+	}
+
+	for _, part := range parts {
+		m.object = append(m.object, m.parts[part.Number]...)
+	}
+	m.completed = true
+	return nil
+}
+
+func (m *memoryUploader) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	m.aborted = true
+	return nil
+}
+
+func TestMultipartUpload(t *testing.T) {
+	data := bytes.Repeat([]byte("dragonfly"), 2*MinPartSize)
+
+	tests := []struct {
+		name     string
+		failPart int
+		expect   func(t *testing.T, uploader *memoryUploader, err error)
+	}{
+		{
+			name: "upload parts concurrently",
+			expect: func(t *testing.T, uploader *memoryUploader, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.True(uploader.completed)
+				assert.False(uploader.aborted)
+				assert.Len(uploader.parts, (len(data)+MinPartSize-1)/MinPartSize)
+				assert.Equal(data, uploader.object)
+			},
+		},
+		{
+			name:     "upload part failed",
+			failPart: 2,
+			expect: func(t *testing.T, uploader *memoryUploader, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "upload part 2 failed: foo")
+				assert.False(uploader.completed)
+				assert.True(uploader.aborted)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			uploader := &memoryUploader{failPart: tc.failPart}
+			err := MultipartUpload(context.Background(), uploader, "bucket", "key", "digest",
+				bytes.NewReader(data), int64(len(data)), 1, 4)
+			tc.expect(t, uploader, err)
+		})
+	}
+}
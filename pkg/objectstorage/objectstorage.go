@@ -101,6 +101,10 @@ func New(name, region, endpoint, accessKey, secretKey string) (ObjectStorage, er
 		return newS3(region, endpoint, accessKey, secretKey)
 	case ServiceNameOSS:
 		return newOSS(region, endpoint, accessKey, secretKey)
+	case ServiceNameAzure:
+		return newAzure(region, endpoint, accessKey, secretKey)
+	case ServiceNameGCS:
+		return newGCS(region, endpoint, accessKey, secretKey)
 	}
 
 	return nil, fmt.Errorf("unknow service name %s", name)
@@ -142,6 +142,77 @@ func (o *oss) PutObject(ctx context.Context, bucketName, objectKey, digest strin
 	return bucket.PutObject(objectKey, reader, meta)
 }
 
+// CreateMultipartUpload initiates a multipart upload and returns the upload id.
+func (o *oss) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return "", err
+	}
+
+	imur, err := bucket.InitiateMultipartUpload(objectKey, aliyunoss.Meta(MetaDigest, digest))
+	if err != nil {
+		return "", err
+	}
+
+	return imur.UploadID, nil
+}
+
+// UploadPart uploads the part, contentMD5 is the base64 encoded md5 of the part verified by object storage.
+func (o *oss) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, number int, contentMD5 string, reader io.ReadSeeker, size int64) (*Part, error) {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	part, err := bucket.UploadPart(o.multipartUploadResult(bucketName, objectKey, uploadID), reader, size, number, aliyunoss.ContentMD5(contentMD5))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Part{
+		Number: part.PartNumber,
+		ETag:   part.ETag,
+	}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the object.
+func (o *oss) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*Part) error {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return err
+	}
+
+	var uploadParts []aliyunoss.UploadPart
+	for _, part := range parts {
+		uploadParts = append(uploadParts, aliyunoss.UploadPart{
+			PartNumber: part.Number,
+			ETag:       part.ETag,
+		})
+	}
+
+	_, err = bucket.CompleteMultipartUpload(o.multipartUploadResult(bucketName, objectKey, uploadID), uploadParts)
+	return err
+}
+
+// AbortMultipartUpload aborts the multipart upload and deletes the uploaded parts.
+func (o *oss) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	bucket, err := o.client.Bucket(bucketName)
+	if err != nil {
+		return err
+	}
+
+	return bucket.AbortMultipartUpload(o.multipartUploadResult(bucketName, objectKey, uploadID))
+}
+
+// multipartUploadResult returns the initiated result of multipart upload.
+func (o *oss) multipartUploadResult(bucketName, objectKey, uploadID string) aliyunoss.InitiateMultipartUploadResult {
+	return aliyunoss.InitiateMultipartUploadResult{
+		Bucket:   bucketName,
+		Key:      objectKey,
+		UploadID: uploadID,
+	}
+}
+
 // DeleteObject deletes data of object.
 func (o *oss) DeleteObject(ctx context.Context, bucketName, objectKey string) error {
 	bucket, err := o.client.Bucket(bucketName)
@@ -145,6 +145,75 @@ func (s *s3) PutObject(ctx context.Context, bucketName, objectKey, digest string
 	return err
 }
 
+// CreateMultipartUpload initiates a multipart upload and returns the upload id.
+func (s *s3) CreateMultipartUpload(ctx context.Context, bucketName, objectKey, digest string) (string, error) {
+	meta := map[string]string{}
+	meta[MetaDigest] = digest
+
+	resp, err := s.client.CreateMultipartUploadWithContext(ctx, &awss3.CreateMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		Metadata: aws.StringMap(meta),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.StringValue(resp.UploadId), nil
+}
+
+// UploadPart uploads the part, contentMD5 is the base64 encoded md5 of the part verified by object storage.
+func (s *s3) UploadPart(ctx context.Context, bucketName, objectKey, uploadID string, number int, contentMD5 string, reader io.ReadSeeker, size int64) (*Part, error) {
+	resp, err := s.client.UploadPartWithContext(ctx, &awss3.UploadPartInput{
+		Bucket:        aws.String(bucketName),
+		Key:           aws.String(objectKey),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int64(int64(number)),
+		ContentMD5:    aws.String(contentMD5),
+		ContentLength: aws.Int64(size),
+		Body:          reader,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Part{
+		Number: number,
+		ETag:   aws.StringValue(resp.ETag),
+	}, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the object.
+func (s *s3) CompleteMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string, parts []*Part) error {
+	var completedParts []*awss3.CompletedPart
+	for _, part := range parts {
+		completedParts = append(completedParts, &awss3.CompletedPart{
+			PartNumber: aws.Int64(int64(part.Number)),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+
+	_, err := s.client.CompleteMultipartUploadWithContext(ctx, &awss3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucketName),
+		Key:             aws.String(objectKey),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &awss3.CompletedMultipartUpload{Parts: completedParts},
+	})
+
+	return err
+}
+
+// AbortMultipartUpload aborts the multipart upload and deletes the uploaded parts.
+func (s *s3) AbortMultipartUpload(ctx context.Context, bucketName, objectKey, uploadID string) error {
+	_, err := s.client.AbortMultipartUploadWithContext(ctx, &awss3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucketName),
+		Key:      aws.String(objectKey),
+		UploadId: aws.String(uploadID),
+	})
+
+	return err
+}
+
 // DeleteObject deletes data of object.
 func (s *s3) DeleteObject(ctx context.Context, bucketName, objectKey string) error {
 	_, err := s.client.DeleteObjectWithContext(ctx, &awss3.DeleteObjectInput{
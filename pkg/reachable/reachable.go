@@ -19,7 +19,6 @@
 package reachable
 
 import (
-	"fmt"
 	"net"
 	"strings"
 	"time"
@@ -71,8 +70,8 @@ func New(r *Config) Reachable {
 }
 
 func (r *reachable) Check() error {
-	if !strings.Contains(r.address, ":") {
-		r.address = fmt.Sprintf("%s:%s", r.address, DefaultPort)
+	if _, _, err := net.SplitHostPort(r.address); err != nil {
+		r.address = net.JoinHostPort(strings.TrimSuffix(strings.TrimPrefix(r.address, "["), "]"), DefaultPort)
 	}
 
 	conn, err := net.DialTimeout(r.network, r.address, r.timeout)
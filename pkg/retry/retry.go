@@ -18,36 +18,150 @@ package retry
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"d7y.io/dragonfly/v2/pkg/math"
 )
 
+const (
+	// DefaultMultiplier is the default exponential base of backoff.
+	DefaultMultiplier = 2.0
+)
+
+// ExhaustedError is returned by Run when all attempts failed,
+// it wraps the error of the last attempt.
+type ExhaustedError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry exhausted after %d attempts: %s", e.Attempts, e.Err)
+}
+
+func (e *ExhaustedError) Unwrap() error {
+	return e.Err
+}
+
+// IsExhausted returns whether the error is returned after all attempts failed.
+func IsExhausted(err error) bool {
+	var e *ExhaustedError
+	return errors.As(err, &e)
+}
+
+// abortError stops retrying immediately.
+type abortError struct {
+	err error
+}
+
+func (e *abortError) Error() string {
+	return e.err.Error()
+}
+
+func (e *abortError) Unwrap() error {
+	return e.err
+}
+
+// Abort wraps a fatal error which stops retrying immediately,
+// Run returns the fatal error as it is.
+func Abort(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &abortError{err: err}
+}
+
+// AttemptFunc is called after every attempt with the attempt number starting from 0,
+// err is nil when the attempt succeeded, backoff is the wait time before the next attempt.
+type AttemptFunc func(attempt int, err error, backoff time.Duration)
+
+type options struct {
+	multiplier float64
+	onAttempt  AttemptFunc
+}
+
+// Option is a functional option for configuring the retry.
+type Option func(o *options)
+
+// WithMultiplier sets the exponential base of backoff.
+func WithMultiplier(multiplier float64) Option {
+	return func(o *options) {
+		o.multiplier = multiplier
+	}
+}
+
+// WithOnAttempt sets the callback of every attempt, it is used for metrics and logging.
+func WithOnAttempt(f AttemptFunc) Option {
+	return func(o *options) {
+		o.onAttempt = f
+	}
+}
+
+// Run calls f until it succeeds, returns an error wrapped by Abort or maxAttempts is reached.
+// The backoff in seconds between attempts grows exponentially with jitter from initBackoff to maxBackoff,
+// and waiting is interrupted when ctx is done.
+//
+// When all attempts failed, the error is an *ExhaustedError wrapping the last error.
+// When f aborts, the error is the fatal error. When ctx is done, the error is ctx.Err().
 func Run(ctx context.Context,
 	initBackoff float64,
 	maxBackoff float64,
 	maxAttempts int,
-	f func() (data any, cancel bool, err error)) (any, bool, error) {
-	var (
-		res    any
-		cancel bool
-		cause  error
-	)
+	f func(attempt int) (any, error),
+	opts ...Option) (any, error) {
+	o := &options{
+		multiplier: DefaultMultiplier,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var cause error
 	for i := 0; i < maxAttempts; i++ {
-		if i > 0 {
-			time.Sleep(math.RandBackoffSeconds(initBackoff, maxBackoff, 2.0, i))
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		res, err := f(i)
+		if err == nil {
+			if o.onAttempt != nil {
+				o.onAttempt(i, nil, 0)
+			}
+			return res, nil
 		}
 
-		res, cancel, cause = f()
-		if cause == nil || cancel {
+		var abort *abortError
+		if errors.As(err, &abort) {
+			if o.onAttempt != nil {
+				o.onAttempt(i, abort.err, 0)
+			}
+			return res, abort.err
+		}
+
+		cause = err
+		if i == maxAttempts-1 {
+			if o.onAttempt != nil {
+				o.onAttempt(i, err, 0)
+			}
 			break
 		}
+
+		backoff := math.RandBackoffSeconds(initBackoff, maxBackoff, o.multiplier, i+1)
+		if o.onAttempt != nil {
+			o.onAttempt(i, err, backoff)
+		}
+
+		timer := time.NewTimer(backoff)
 		select {
 		case <-ctx.Done():
-			return nil, cancel, ctx.Err()
-		default:
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
 		}
 	}
 
-	return res, cancel, cause
+	return nil, &ExhaustedError{Attempts: maxAttempts, Err: cause}
 }
@@ -0,0 +1,157 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	errFoo := errors.New("foo")
+
+	tests := []struct {
+		name   string
+		ctx    func() context.Context
+		f      func(attempt int) (any, error)
+		expect func(t *testing.T, res any, err error, attempts []int, errs []error)
+	}{
+		{
+			name: "succeed at first attempt",
+			ctx:  context.Background,
+			f: func(int) (any, error) {
+				return "bar", nil
+			},
+			expect: func(t *testing.T, res any, err error, attempts []int, errs []error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("bar", res)
+				assert.Equal([]int{0}, attempts)
+				assert.Equal([]error{nil}, errs)
+			},
+		},
+		{
+			name: "succeed after retry",
+			ctx:  context.Background,
+			f: func(attempt int) (any, error) {
+				if attempt < 2 {
+					return nil, errFoo
+				}
+				return attempt, nil
+			},
+			expect: func(t *testing.T, res any, err error, attempts []int, errs []error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(2, res)
+				assert.Equal([]int{0, 1, 2}, attempts)
+				assert.Equal([]error{errFoo, errFoo, nil}, errs)
+			},
+		},
+		{
+			name: "retry exhausted",
+			ctx:  context.Background,
+			f: func(int) (any, error) {
+				return nil, errFoo
+			},
+			expect: func(t *testing.T, res any, err error, attempts []int, errs []error) {
+				assert := assert.New(t)
+				assert.True(IsExhausted(err))
+				assert.ErrorIs(err, errFoo)
+				assert.Nil(res)
+				assert.Equal([]int{0, 1, 2}, attempts)
+
+				var e *ExhaustedError
+				assert.True(errors.As(err, &e))
+				assert.Equal(3, e.Attempts)
+			},
+		},
+		{
+			name: "abort with fatal error",
+			ctx:  context.Background,
+			f: func(attempt int) (any, error) {
+				if attempt == 1 {
+					return nil, Abort(errFoo)
+				}
+				return nil, errors.New("bar")
+			},
+			expect: func(t *testing.T, res any, err error, attempts []int, errs []error) {
+				assert := assert.New(t)
+				assert.Equal(errFoo, err)
+				assert.False(IsExhausted(err))
+				assert.Equal([]int{0, 1}, attempts)
+			},
+		},
+		{
+			name: "abort without error",
+			ctx:  context.Background,
+			f: func(int) (any, error) {
+				return nil, Abort(nil)
+			},
+			expect: func(t *testing.T, res any, err error, attempts []int, errs []error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]int{0}, attempts)
+			},
+		},
+		{
+			name: "context canceled",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			f: func(int) (any, error) {
+				return nil, errFoo
+			},
+			expect: func(t *testing.T, res any, err error, attempts []int, errs []error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, context.Canceled)
+				assert.Empty(attempts)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				attempts []int
+				errs     []error
+			)
+			res, err := Run(tc.ctx(), 0.001, 0.002, 3, tc.f, WithOnAttempt(func(attempt int, err error, backoff time.Duration) {
+				attempts = append(attempts, attempt)
+				errs = append(errs, err)
+			}))
+			tc.expect(t, res, err, attempts, errs)
+		})
+	}
+}
+
+func TestRun_ContextDoneInBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := Run(ctx, 10, 10, 3, func(int) (any, error) {
+		return nil, errors.New("foo")
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -47,6 +47,10 @@ const (
 	defaultConnExpireTime = 2 * time.Minute
 
 	defaultDialTimeout = 10 * time.Second
+
+	defaultHealthCheckInterval = 30 * time.Second
+
+	defaultHealthCheckTimeout = 5 * time.Second
 )
 
 type Closer interface {
@@ -64,14 +68,21 @@ type Connection struct {
 	key2NodeMap    sync.Map // key -> node(many to one)
 	node2ClientMap sync.Map // node -> clientConn(one to one)
 	accessNodeMap  sync.Map // clientConn access time
+	limiterMap     sync.Map // clientConn -> limiter(one to one)
 	connExpireTime time.Duration
 	gcConnTimeout  time.Duration
 	gcConnInterval time.Duration
 	dialTimeout    time.Duration
-	name           string
-	hashRing       *hashring.HashRing // server hash ring
-	serverNodes    []dfnet.NetAddr
-	status         ConnStatus
+	// healthCheckInterval is the interval of evicting broken client conns
+	healthCheckInterval time.Duration
+	// healthCheckTimeout is the timeout of waiting client conn to be ready
+	healthCheckTimeout time.Duration
+	// maxConcurrencyPerTarget caps the running requests and streams of every target, 0 means no limit
+	maxConcurrencyPerTarget int
+	name                    string
+	hashRing                *hashring.HashRing // server hash ring
+	serverNodes             []dfnet.NetAddr
+	status                  ConnStatus
 }
 
 func newDefaultConnection(ctx context.Context) *Connection {
@@ -84,6 +95,9 @@ func newDefaultConnection(ctx context.Context) *Connection {
 		gcConnTimeout:  defaultGcConnTimeout,
 		gcConnInterval: defaultGcConnInterval,
 		dialTimeout:    defaultDialTimeout,
+
+		healthCheckInterval: defaultHealthCheckInterval,
+		healthCheckTimeout:  defaultHealthCheckTimeout,
 	}
 }
 
@@ -149,6 +163,24 @@ func WithDialTimeout(dialTimeout time.Duration) ConnOption {
 	})
 }
 
+func WithHealthCheckInterval(healthCheckInterval time.Duration) ConnOption {
+	return newFuncConnOption(func(conn *Connection) {
+		conn.healthCheckInterval = healthCheckInterval
+	})
+}
+
+func WithHealthCheckTimeout(healthCheckTimeout time.Duration) ConnOption {
+	return newFuncConnOption(func(conn *Connection) {
+		conn.healthCheckTimeout = healthCheckTimeout
+	})
+}
+
+func WithMaxConcurrencyPerTarget(maxConcurrency int) ConnOption {
+	return newFuncConnOption(func(conn *Connection) {
+		conn.maxConcurrencyPerTarget = maxConcurrency
+	})
+}
+
 func NewConnection(ctx context.Context, name string, addrs []dfnet.NetAddr, connOpts []ConnOption) *Connection {
 	conn := newDefaultConnection(ctx)
 	conn.name = name
@@ -271,7 +303,49 @@ type candidateClient struct {
 func (conn *Connection) createClient(target string, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), conn.dialTimeout)
 	defer cancel()
-	return grpc.DialContext(ctx, target, opts...)
+
+	limiter := newConnLimiter(conn.name, conn.maxConcurrencyPerTarget)
+	clientConn, err := grpc.DialContext(ctx, target, append(opts, limiter.dialOptions()...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	conn.limiterMap.Store(clientConn, limiter)
+	return clientConn, nil
+}
+
+// storeClientConn binds client conn to node, if another client conn of node
+// is created concurrently, the stored one is returned and clientConn is closed.
+func (conn *Connection) storeClientConn(node string, clientConn *grpc.ClientConn) *grpc.ClientConn {
+	actual, loaded := conn.node2ClientMap.LoadOrStore(node, clientConn)
+	if !loaded {
+		ClientConnCount.WithLabelValues(conn.name).Inc()
+		return clientConn
+	}
+
+	if actual != clientConn {
+		conn.closeClientConn(clientConn)
+	}
+
+	return actual.(*grpc.ClientConn)
+}
+
+// closeClientConn closes client conn and releases its limiter.
+func (conn *Connection) closeClientConn(clientConn *grpc.ClientConn) {
+	conn.limiterMap.Delete(clientConn)
+	if err := clientConn.Close(); err != nil {
+		logger.GrpcLogger.With("conn", conn.name).Warnf("failed to close clientConn: %s: %v", clientConn.Target(), err)
+	}
+}
+
+// runningCount returns the count of running requests and streams of client conn.
+func (conn *Connection) runningCount(clientConn *grpc.ClientConn) int64 {
+	value, ok := conn.limiterMap.Load(clientConn)
+	if !ok {
+		return 0
+	}
+
+	return value.(*connLimiter).running()
 }
 
 // GetServerNode
@@ -316,8 +390,7 @@ func (conn *Connection) loadOrCreateClientConnByNode(node string) (clientConn *g
 	if err == nil {
 		logger.GrpcLogger.With("conn", conn.name).Infof("success connect to node %s", node)
 		// bind
-		conn.node2ClientMap.Store(node, clientConn)
-		return clientConn, nil
+		return conn.storeClientConn(node, clientConn), nil
 	}
 
 	return nil, fmt.Errorf("cannot found clientConn associated with node %s and create client conn failed: %w", node, err)
@@ -357,9 +430,9 @@ func (conn *Connection) GetClientConn(hashKey string, stick bool) (clientConn *g
 		return nil, fmt.Errorf("prob candidate client conn for hash key %s: %w", hashKey, err)
 	}
 	conn.key2NodeMap.Store(hashKey, client.node)
-	conn.node2ClientMap.Store(client.node, client.Ref)
+	clientConn = conn.storeClientConn(client.node, client.Ref.(*grpc.ClientConn))
 	conn.accessNodeMap.Store(client.node, time.Now())
-	return client.Ref.(*grpc.ClientConn), nil
+	return clientConn, nil
 }
 
 // TryMigrate migrate key to another hash node other than exclusiveNodes
@@ -392,7 +465,7 @@ func (conn *Connection) TryMigrate(key string, cause error, exclusiveNodes []str
 	}
 	logger.GrpcLogger.With("conn", conn.name).Infof("successfully migrate hash key %s from server node %s to %s", key, currentNode, client.node)
 	conn.key2NodeMap.Store(key, client.node)
-	conn.node2ClientMap.Store(client.node, client.Ref)
+	conn.storeClientConn(client.node, client.Ref.(*grpc.ClientConn))
 	conn.accessNodeMap.Store(client.node, time.Now())
 	return
 }
@@ -403,15 +476,10 @@ func (conn *Connection) Close() error {
 	for i := range conn.serverNodes {
 		serverNode := conn.serverNodes[i].GetEndpoint()
 		conn.hashRing.RemoveNode(serverNode)
-		value, ok := conn.node2ClientMap.Load(serverNode)
+		value, ok := conn.node2ClientMap.LoadAndDelete(serverNode)
 		if ok {
-			clientCon := value.(*grpc.ClientConn)
-			err := clientCon.Close()
-			if err == nil {
-				conn.node2ClientMap.Delete(serverNode)
-			} else {
-				logger.GrpcLogger.With("conn", conn.name).Warnf("failed to close clientConn: %s: %v", serverNode, err)
-			}
+			conn.closeClientConn(value.(*grpc.ClientConn))
+			ClientConnCount.WithLabelValues(conn.name).Dec()
 		}
 		// gc hash keys
 		conn.key2NodeMap.Range(func(key, value any) bool {
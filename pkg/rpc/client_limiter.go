@@ -0,0 +1,110 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+
+	"go.uber.org/atomic"
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// connLimiter tracks the running requests and streams of a client conn,
+// and caps the concurrency of them when maxConcurrency is greater than 0.
+type connLimiter struct {
+	name     string
+	sem      *semaphore.Weighted
+	inflight *atomic.Int64
+}
+
+func newConnLimiter(name string, maxConcurrency int) *connLimiter {
+	l := &connLimiter{
+		name:     name,
+		inflight: atomic.NewInt64(0),
+	}
+
+	if maxConcurrency > 0 {
+		l.sem = semaphore.NewWeighted(int64(maxConcurrency))
+	}
+
+	return l
+}
+
+// dialOptions returns the interceptors of limiter.
+func (l *connLimiter) dialOptions() []grpc.DialOption {
+	return []grpc.DialOption{
+		grpc.WithChainUnaryInterceptor(l.unaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(l.streamClientInterceptor),
+	}
+}
+
+// running returns the count of running requests and streams.
+func (l *connLimiter) running() int64 {
+	return l.inflight.Load()
+}
+
+func (l *connLimiter) acquire(ctx context.Context) error {
+	if l.sem != nil && !l.sem.TryAcquire(1) {
+		ClientRequestLimitedCount.WithLabelValues(l.name).Inc()
+		if err := l.sem.Acquire(ctx, 1); err != nil {
+			return status.FromContextError(err).Err()
+		}
+	}
+
+	l.inflight.Inc()
+	ClientRequestRunningCount.WithLabelValues(l.name).Inc()
+	return nil
+}
+
+func (l *connLimiter) release() {
+	l.inflight.Dec()
+	ClientRequestRunningCount.WithLabelValues(l.name).Dec()
+	if l.sem != nil {
+		l.sem.Release(1)
+	}
+}
+
+func (l *connLimiter) unaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := l.acquire(ctx); err != nil {
+		return err
+	}
+	defer l.release()
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (l *connLimiter) streamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	s, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		l.release()
+		return nil, err
+	}
+
+	// Context of client stream is canceled when the stream is finished.
+	go func() {
+		<-s.Context().Done()
+		l.release()
+	}()
+
+	return s, nil
+}
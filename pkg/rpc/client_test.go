@@ -0,0 +1,117 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"d7y.io/dragonfly/v2/pkg/dfnet"
+)
+
+func newTestServer(t *testing.T) (*grpc.Server, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	go server.Serve(lis) // nolint: errcheck
+	return server, lis.Addr().String()
+}
+
+func TestConnection_evictUnhealthyConns(t *testing.T) {
+	tests := []struct {
+		name   string
+		stop   bool
+		expect func(t *testing.T, conn *Connection, target string)
+	}{
+		{
+			name: "keep healthy client conn",
+			stop: false,
+			expect: func(t *testing.T, conn *Connection, target string) {
+				assert := assert.New(t)
+				_, ok := conn.node2ClientMap.Load(target)
+				assert.True(ok)
+			},
+		},
+		{
+			name: "evict client conn of departed server",
+			stop: true,
+			expect: func(t *testing.T, conn *Connection, target string) {
+				assert := assert.New(t)
+				_, ok := conn.node2ClientMap.Load(target)
+				assert.False(ok)
+				_, ok = conn.accessNodeMap.Load(target)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, target := newTestServer(t)
+			defer server.Stop()
+
+			conn := NewConnection(context.Background(), "test", []dfnet.NetAddr{{Type: dfnet.TCP, Addr: target}}, []ConnOption{
+				WithHealthCheckInterval(time.Hour),
+				WithHealthCheckTimeout(time.Second),
+			})
+			defer conn.Close()
+
+			clientConn, err := conn.GetClientConnByTarget(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.stop {
+				server.Stop()
+				clientConn.WaitForStateChange(context.Background(), clientConn.GetState())
+			}
+
+			conn.evictUnhealthyConns()
+			tc.expect(t, conn, target)
+		})
+	}
+}
+
+func TestConnLimiter(t *testing.T) {
+	assert := assert.New(t)
+	limiter := newConnLimiter("test", 1)
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		assert.Equal(int64(1), limiter.running())
+
+		// the second request is blocked by concurrency limit
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		err := limiter.unaryClientInterceptor(ctx, method, req, reply, cc, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return nil
+		})
+		assert.Equal(codes.DeadlineExceeded, status.Code(err))
+		return nil
+	}
+
+	assert.NoError(limiter.unaryClientInterceptor(context.Background(), "foo", nil, nil, nil, invoker))
+	assert.Equal(int64(0), limiter.running())
+}
@@ -19,12 +19,14 @@ package rpc
 import (
 	"context"
 	"io"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
@@ -49,11 +51,17 @@ func (conn *Connection) startGC() {
 	logger.GrpcLogger.With("conn", conn.name).Debugf("start the gc connections job")
 	// execute the GC by fixed delay
 	ticker := time.NewTicker(conn.gcConnInterval)
+	defer ticker.Stop()
+
+	healthCheckTicker := time.NewTicker(conn.healthCheckInterval)
+	defer healthCheckTicker.Stop()
 	for {
 		select {
 		case <-conn.ctx.Done():
 			logger.GrpcLogger.With("conn", conn.name).Info("conn close, exit gc")
 			return
+		case <-healthCheckTicker.C:
+			conn.evictUnhealthyConns()
 		case <-ticker.C:
 			removedConnCount := 0
 			totalNodeSize := 0
@@ -69,7 +77,13 @@ func (conn *Connection) startGC() {
 				if conn.connExpireTime == 0 || time.Since(atime) < conn.connExpireTime {
 					return true
 				}
-				conn.gcConn(serverNode)
+
+				// client conn is still in use by long-lived streams
+				if client, ok := conn.node2ClientMap.Load(serverNode); ok && conn.runningCount(client.(*grpc.ClientConn)) > 0 {
+					conn.accessNodeMap.Store(serverNode, time.Now())
+					return true
+				}
+				conn.gcConn(serverNode, EvictReasonExpired)
 				removedConnCount++
 				return true
 			})
@@ -92,10 +106,79 @@ func (conn *Connection) startGC() {
 	}
 }
 
+// evictUnhealthyConns checks health of client conns concurrently and evicts the broken ones,
+// so client conns of departed server nodes are not kept in the pool.
+func (conn *Connection) evictUnhealthyConns() {
+	clientConns := map[string]*grpc.ClientConn{}
+	conn.node2ClientMap.Range(func(node, client any) bool {
+		clientConns[node.(string)] = client.(*grpc.ClientConn)
+		return true
+	})
+
+	var (
+		wg             sync.WaitGroup
+		mu             sync.Mutex
+		unhealthyConns = map[string]*grpc.ClientConn{}
+	)
+	for node, clientConn := range clientConns {
+		wg.Add(1)
+		go func(node string, clientConn *grpc.ClientConn) {
+			defer wg.Done()
+			if conn.isHealthy(clientConn) {
+				return
+			}
+
+			mu.Lock()
+			unhealthyConns[node] = clientConn
+			mu.Unlock()
+		}(node, clientConn)
+	}
+	wg.Wait()
+
+	conn.rwMutex.Lock()
+	defer conn.rwMutex.Unlock()
+	for node, clientConn := range unhealthyConns {
+		// client conn may be replaced during health check
+		if client, ok := conn.node2ClientMap.Load(node); !ok || client != clientConn {
+			continue
+		}
+
+		logger.GrpcLogger.With("conn", conn.name).Warnf("clientConn of server node %s is unhealthy, state: %s", node, clientConn.GetState())
+		conn.gcConn(node, EvictReasonUnhealthy)
+	}
+}
+
+// isHealthy waits client conn to be ready in health check timeout,
+// idle client conn is connected actively to detect departed server node.
+func (conn *Connection) isHealthy(clientConn *grpc.ClientConn) bool {
+	ctx, cancel := context.WithTimeout(conn.ctx, conn.healthCheckTimeout)
+	defer cancel()
+
+	for {
+		state := clientConn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return true
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return false
+		case connectivity.Idle:
+			clientConn.Connect()
+		}
+
+		if !clientConn.WaitForStateChange(ctx, state) {
+			return false
+		}
+	}
+}
+
 // gcConn gc keys and clients associated with server node
-func (conn *Connection) gcConn(node string) {
+func (conn *Connection) gcConn(node string, reason string) {
 	logger.GrpcLogger.With("conn", conn.name).Infof("gc keys and clients associated with server node: %s starting", node)
-	conn.node2ClientMap.Delete(node)
+	if client, ok := conn.node2ClientMap.LoadAndDelete(node); ok {
+		conn.closeClientConn(client.(*grpc.ClientConn))
+		ClientConnCount.WithLabelValues(conn.name).Dec()
+		ClientConnEvictedCount.WithLabelValues(conn.name, reason).Inc()
+	}
 	logger.GrpcLogger.With("conn", conn.name).Infof("success gc clientConn: %s", node)
 	// gc hash keys
 	conn.key2NodeMap.Range(func(key, value any) bool {
@@ -0,0 +1,73 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"strings"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+)
+
+// ExcludePeersHeader is the extend attribute header of a piece result which carries
+// the peer ids recently failed or slow for the reporting peer, separated by comma,
+// scheduler excludes them temporarily when scheduling parents for the peer.
+const ExcludePeersHeader = "X-Dragonfly-Exclude-Peers"
+
+// SetExcludePeers sets the excluded peer ids in the extend attribute of piece result.
+func SetExcludePeers(result *schedulerv1.PieceResult, peerIDs []string) {
+	if len(peerIDs) == 0 {
+		return
+	}
+
+	if result.ExtendAttribute == nil {
+		result.ExtendAttribute = &commonv1.ExtendAttribute{}
+	}
+
+	if result.ExtendAttribute.Header == nil {
+		result.ExtendAttribute.Header = map[string]string{}
+	}
+
+	result.ExtendAttribute.Header[ExcludePeersHeader] = strings.Join(peerIDs, ",")
+}
+
+// ExtractExcludePeers returns the excluded peer ids in the extend attribute of piece result,
+// the header is stripped from the piece result.
+func ExtractExcludePeers(result *schedulerv1.PieceResult) []string {
+	if result.ExtendAttribute == nil {
+		return nil
+	}
+
+	data, ok := result.ExtendAttribute.Header[ExcludePeersHeader]
+	if !ok {
+		return nil
+	}
+
+	delete(result.ExtendAttribute.Header, ExcludePeersHeader)
+	if len(result.ExtendAttribute.Header) == 0 && result.ExtendAttribute.StatusCode == 0 && result.ExtendAttribute.Status == "" {
+		result.ExtendAttribute = nil
+	}
+
+	var peerIDs []string
+	for _, peerID := range strings.Split(data, ",") {
+		if peerID != "" {
+			peerIDs = append(peerIDs, peerID)
+		}
+	}
+
+	return peerIDs
+}
@@ -0,0 +1,122 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"encoding/json"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+)
+
+// PieceBatchHeader is the extend attribute header of a piece result
+// which carries the pieces reported ahead of it in the same batch.
+const PieceBatchHeader = "X-Dragonfly-Piece-Batch"
+
+// batchedPiece is the compact form of a successful piece result in a batch,
+// the fields shared by the batch are taken from the carrier piece result.
+type batchedPiece struct {
+	DstPid    string              `json:"d,omitempty"`
+	PieceInfo *commonv1.PieceInfo `json:"p"`
+	BeginTime uint64              `json:"b"`
+	EndTime   uint64              `json:"e"`
+}
+
+// PackPieceResults packs successful piece results of the same peer into one piece result,
+// the last piece result is the carrier and the others are encoded in its extend attribute.
+func PackPieceResults(results []*schedulerv1.PieceResult) (*schedulerv1.PieceResult, error) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	carrier := results[len(results)-1]
+	if len(results) == 1 {
+		return carrier, nil
+	}
+
+	pieces := make([]batchedPiece, 0, len(results)-1)
+	for _, result := range results[:len(results)-1] {
+		pieces = append(pieces, batchedPiece{
+			DstPid:    result.DstPid,
+			PieceInfo: result.PieceInfo,
+			BeginTime: result.BeginTime,
+			EndTime:   result.EndTime,
+		})
+	}
+
+	data, err := json.Marshal(pieces)
+	if err != nil {
+		return nil, err
+	}
+
+	packed := &schedulerv1.PieceResult{
+		TaskId:        carrier.TaskId,
+		SrcPid:        carrier.SrcPid,
+		DstPid:        carrier.DstPid,
+		PieceInfo:     carrier.PieceInfo,
+		BeginTime:     carrier.BeginTime,
+		EndTime:       carrier.EndTime,
+		Success:       carrier.Success,
+		Code:          carrier.Code,
+		HostLoad:      carrier.HostLoad,
+		FinishedCount: carrier.FinishedCount,
+		ExtendAttribute: &commonv1.ExtendAttribute{
+			Header: map[string]string{PieceBatchHeader: string(data)},
+		},
+	}
+	return packed, nil
+}
+
+// UnpackPieceResults expands a piece result packed by PackPieceResults in report order,
+// piece results without batch are returned as they are. The batch header is
+// stripped from the carrier piece result.
+func UnpackPieceResults(result *schedulerv1.PieceResult) ([]*schedulerv1.PieceResult, error) {
+	if result.ExtendAttribute == nil {
+		return []*schedulerv1.PieceResult{result}, nil
+	}
+
+	data, ok := result.ExtendAttribute.Header[PieceBatchHeader]
+	if !ok {
+		return []*schedulerv1.PieceResult{result}, nil
+	}
+
+	var pieces []batchedPiece
+	if err := json.Unmarshal([]byte(data), &pieces); err != nil {
+		return nil, err
+	}
+
+	results := make([]*schedulerv1.PieceResult, 0, len(pieces)+1)
+	for _, piece := range pieces {
+		results = append(results, &schedulerv1.PieceResult{
+			TaskId:        result.TaskId,
+			SrcPid:        result.SrcPid,
+			DstPid:        piece.DstPid,
+			PieceInfo:     piece.PieceInfo,
+			BeginTime:     piece.BeginTime,
+			EndTime:       piece.EndTime,
+			Success:       true,
+			Code:          commonv1.Code_Success,
+			FinishedCount: result.FinishedCount,
+		})
+	}
+
+	delete(result.ExtendAttribute.Header, PieceBatchHeader)
+	if len(result.ExtendAttribute.Header) == 0 {
+		result.ExtendAttribute = nil
+	}
+	return append(results, result), nil
+}
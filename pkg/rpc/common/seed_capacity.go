@@ -0,0 +1,101 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+)
+
+const (
+	// SeedFreeBandwidthHeader is the grpc header of ObtainSeeds carrying the free inbound bandwidth
+	// of seed peer in bytes per second.
+	SeedFreeBandwidthHeader = "x-dragonfly-seed-free-bandwidth"
+
+	// SeedDiskUsedPercentHeader is the grpc header of ObtainSeeds carrying the used percent
+	// of the disk which seed peer stores data in.
+	SeedDiskUsedPercentHeader = "x-dragonfly-seed-disk-used-percent"
+)
+
+// SeedCapacity is the capacity advertised by seed peer when triggering seed task.
+type SeedCapacity struct {
+	// FreeBandwidth is the free inbound bandwidth in bytes per second.
+	FreeBandwidth int64
+
+	// DiskUsedPercent is the used percent of the disk which data is stored in.
+	DiskUsedPercent float64
+}
+
+// Metadata returns the grpc header carrying the seed capacity.
+func (c SeedCapacity) Metadata() metadata.MD {
+	return metadata.Pairs(
+		SeedFreeBandwidthHeader, strconv.FormatInt(c.FreeBandwidth, 10),
+		SeedDiskUsedPercentHeader, strconv.FormatFloat(c.DiskUsedPercent, 'f', 2, 64),
+	)
+}
+
+// ParseSeedCapacity parses the seed capacity from the grpc header, ok is false when
+// the seed peer does not advertise capacity.
+func ParseSeedCapacity(md metadata.MD) (capacity SeedCapacity, ok bool) {
+	bandwidth, disk := md.Get(SeedFreeBandwidthHeader), md.Get(SeedDiskUsedPercentHeader)
+	if len(bandwidth) == 0 || len(disk) == 0 {
+		return capacity, false
+	}
+
+	var err error
+	if capacity.FreeBandwidth, err = strconv.ParseInt(bandwidth[0], 10, 64); err != nil {
+		return SeedCapacity{}, false
+	}
+
+	if capacity.DiskUsedPercent, err = strconv.ParseFloat(disk[0], 64); err != nil {
+		return SeedCapacity{}, false
+	}
+
+	return capacity, true
+}
+
+// NewSeedPeerBusyError returns the resource exhausted error telling the scheduler that
+// seed peer is above the watermark, the seed task should be triggered in another seed peer.
+func NewSeedPeerBusyError(msg string) error {
+	st := status.New(codes.ResourceExhausted, msg)
+	if detailed, err := st.WithDetails(NewGrpcDfError(commonv1.Code_ResourceLacked, msg)); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+// IsSeedPeerBusy returns whether the error is returned by the busy seed peer.
+func IsSeedPeerBusy(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.ResourceExhausted {
+		return false
+	}
+
+	for _, detail := range st.Details() {
+		if dfError, ok := detail.(*commonv1.GrpcDfError); ok && dfError.Code == commonv1.Code_ResourceLacked {
+			return true
+		}
+	}
+
+	return false
+}
@@ -22,12 +22,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
@@ -36,6 +38,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/rpc"
+	"d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 )
 
 var _ DaemonClient = (*daemonClient)(nil)
@@ -95,6 +98,18 @@ type DaemonClient interface {
 
 	DeleteTask(ctx context.Context, req *dfdaemonv1.DeleteTaskRequest, opts ...grpc.CallOption) error
 
+	Prefetch(ctx context.Context, reqs []*dfdaemonv1.StatTaskRequest, opts ...grpc.CallOption) error
+
+	Status(ctx context.Context, opts ...grpc.CallOption) (*server.Status, error)
+
+	CheckCache(ctx context.Context, req *dfdaemonv1.StatTaskRequest, opts ...grpc.CallOption) (*server.CacheStatus, error)
+
+	CancelTask(ctx context.Context, req *server.CancelTaskRequest, opts ...grpc.CallOption) error
+
+	// StreamTask downloads the task and returns the reader of content in order,
+	// the output of request is ignored, closing the reader before EOF cancels the task in daemon
+	StreamTask(ctx context.Context, req *dfdaemonv1.DownRequest, opts ...grpc.CallOption) (io.ReadCloser, error)
+
 	Close() error
 }
 
@@ -205,3 +220,106 @@ func (dc *daemonClient) DeleteTask(ctx context.Context, req *dfdaemonv1.DeleteTa
 	_, err = client.DeleteTask(ctx, req, opts...)
 	return err
 }
+
+func (dc *daemonClient) Prefetch(ctx context.Context, reqs []*dfdaemonv1.StatTaskRequest, opts ...grpc.CallOption) error {
+	if len(reqs) == 0 {
+		return errors.New("prefetch requests are empty")
+	}
+
+	taskID := idgen.TaskID(reqs[0].Url, reqs[0].UrlMeta)
+	clientConn, err := dc.Connection.GetClientConn(taskID, false)
+	if err != nil {
+		return err
+	}
+
+	stream, err := clientConn.NewStream(ctx, &server.PrefetcherServiceDesc.Streams[0], server.PrefetchMethod, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, req := range reqs {
+		if err := stream.SendMsg(req); err != nil {
+			return err
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	return stream.RecvMsg(new(emptypb.Empty))
+}
+
+func (dc *daemonClient) Status(ctx context.Context, opts ...grpc.CallOption) (*server.Status, error) {
+	clientConn, err := dc.Connection.GetClientConn(server.StatusServiceName, false)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(structpb.Struct)
+	if err := clientConn.Invoke(ctx, server.GetStatusMethod, new(emptypb.Empty), msg, opts...); err != nil {
+		return nil, err
+	}
+
+	return server.DecodeStatus(msg)
+}
+
+func (dc *daemonClient) CheckCache(ctx context.Context, req *dfdaemonv1.StatTaskRequest, opts ...grpc.CallOption) (*server.CacheStatus, error) {
+	taskID := idgen.TaskID(req.Url, req.UrlMeta)
+	clientConn, err := dc.Connection.GetClientConn(taskID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(structpb.Struct)
+	if err := clientConn.Invoke(ctx, server.CheckCacheMethod, req, msg, opts...); err != nil {
+		return nil, err
+	}
+
+	return server.DecodeCacheStatus(msg)
+}
+
+func (dc *daemonClient) CancelTask(ctx context.Context, req *server.CancelTaskRequest, opts ...grpc.CallOption) error {
+	clientConn, err := dc.Connection.GetClientConn(req.TaskID, false)
+	if err != nil {
+		return err
+	}
+
+	msg, err := server.EncodeCancelTaskRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return clientConn.Invoke(ctx, server.CancelTaskMethod, msg, new(emptypb.Empty), opts...)
+}
+
+func (dc *daemonClient) StreamTask(ctx context.Context, req *dfdaemonv1.DownRequest, opts ...grpc.CallOption) (io.ReadCloser, error) {
+	req.Uuid = uuid.New().String()
+	taskID := idgen.TaskID(req.Url, req.UrlMeta)
+	clientConn, err := dc.Connection.GetClientConn(taskID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := clientConn.NewStream(ctx, &server.StreamerServiceDesc.Streams[0], server.StreamTaskMethod, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if err := stream.SendMsg(req); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &streamTaskReader{
+		stream: stream,
+		cancel: cancel,
+	}, nil
+}
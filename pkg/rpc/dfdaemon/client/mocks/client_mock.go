@@ -6,12 +6,14 @@ package mocks
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	v1 "d7y.io/api/pkg/apis/common/v1"
 	v10 "d7y.io/api/pkg/apis/dfdaemon/v1"
 	dfnet "d7y.io/dragonfly/v2/pkg/dfnet"
 	client "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
+	server "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/server"
 	gomock "github.com/golang/mock/gomock"
 	grpc "google.golang.org/grpc"
 )
@@ -39,6 +41,45 @@ func (m *MockDaemonClient) EXPECT() *MockDaemonClientMockRecorder {
 	return m.recorder
 }
 
+// CancelTask mocks base method.
+func (m *MockDaemonClient) CancelTask(ctx context.Context, req *server.CancelTaskRequest, opts ...grpc.CallOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, req}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CancelTask", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelTask indicates an expected call of CancelTask.
+func (mr *MockDaemonClientMockRecorder) CancelTask(ctx, req interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, req}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelTask", reflect.TypeOf((*MockDaemonClient)(nil).CancelTask), varargs...)
+}
+
+// CheckCache mocks base method.
+func (m *MockDaemonClient) CheckCache(ctx context.Context, req *v10.StatTaskRequest, opts ...grpc.CallOption) (*server.CacheStatus, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, req}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CheckCache", varargs...)
+	ret0, _ := ret[0].(*server.CacheStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CheckCache indicates an expected call of CheckCache.
+func (mr *MockDaemonClientMockRecorder) CheckCache(ctx, req interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, req}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckCache", reflect.TypeOf((*MockDaemonClient)(nil).CheckCache), varargs...)
+}
+
 // CheckHealth mocks base method.
 func (m *MockDaemonClient) CheckHealth(ctx context.Context, target dfnet.NetAddr, opts ...grpc.CallOption) error {
 	m.ctrl.T.Helper()
@@ -169,6 +210,25 @@ func (mr *MockDaemonClientMockRecorder) ImportTask(ctx, req interface{}, opts ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportTask", reflect.TypeOf((*MockDaemonClient)(nil).ImportTask), varargs...)
 }
 
+// Prefetch mocks base method.
+func (m *MockDaemonClient) Prefetch(ctx context.Context, reqs []*v10.StatTaskRequest, opts ...grpc.CallOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, reqs}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Prefetch", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prefetch indicates an expected call of Prefetch.
+func (mr *MockDaemonClientMockRecorder) Prefetch(ctx, reqs interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, reqs}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prefetch", reflect.TypeOf((*MockDaemonClient)(nil).Prefetch), varargs...)
+}
+
 // StatTask mocks base method.
 func (m *MockDaemonClient) StatTask(ctx context.Context, req *v10.StatTaskRequest, opts ...grpc.CallOption) error {
 	m.ctrl.T.Helper()
@@ -188,6 +248,46 @@ func (mr *MockDaemonClientMockRecorder) StatTask(ctx, req interface{}, opts ...i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatTask", reflect.TypeOf((*MockDaemonClient)(nil).StatTask), varargs...)
 }
 
+// Status mocks base method.
+func (m *MockDaemonClient) Status(ctx context.Context, opts ...grpc.CallOption) (*server.Status, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Status", varargs...)
+	ret0, _ := ret[0].(*server.Status)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockDaemonClientMockRecorder) Status(ctx interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockDaemonClient)(nil).Status), varargs...)
+}
+
+// StreamTask mocks base method.
+func (m *MockDaemonClient) StreamTask(ctx context.Context, req *v10.DownRequest, opts ...grpc.CallOption) (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, req}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "StreamTask", varargs...)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StreamTask indicates an expected call of StreamTask.
+func (mr *MockDaemonClientMockRecorder) StreamTask(ctx, req interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, req}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTask", reflect.TypeOf((*MockDaemonClient)(nil).StreamTask), varargs...)
+}
+
 // SyncPieceTasks mocks base method.
 func (m *MockDaemonClient) SyncPieceTasks(ctx context.Context, addr dfnet.NetAddr, ptr *v1.PieceTaskRequest, opts ...grpc.CallOption) (v10.Daemon_SyncPieceTasksClient, error) {
 	m.ctrl.T.Helper()
@@ -18,7 +18,8 @@ package client
 
 import (
 	"context"
-	"fmt"
+	"net"
+	"strconv"
 
 	"google.golang.org/grpc"
 
@@ -35,9 +36,17 @@ func GetPieceTasks(ctx context.Context,
 	opts ...grpc.CallOption) (*commonv1.PiecePacket, error) {
 	netAddr := dfnet.NetAddr{
 		Type: dfnet.TCP,
-		Addr: fmt.Sprintf("%s:%d", dstPeer.Ip, dstPeer.RpcPort),
+		Addr: net.JoinHostPort(dstPeer.Ip, strconv.Itoa(int(dstPeer.RpcPort))),
 	}
 
+	return GetPieceTasksByAddr(ctx, netAddr, ptr, opts...)
+}
+
+// GetPieceTasksByAddr gets piece tasks from the peer grpc of netAddr, like unix socket of peer on the same host.
+func GetPieceTasksByAddr(ctx context.Context,
+	netAddr dfnet.NetAddr,
+	ptr *commonv1.PieceTaskRequest,
+	opts ...grpc.CallOption) (*commonv1.PiecePacket, error) {
 	client, err := GetElasticClientByAddrs([]dfnet.NetAddr{netAddr})
 	if err != nil {
 		return nil, err
@@ -52,9 +61,17 @@ func SyncPieceTasks(ctx context.Context,
 	opts ...grpc.CallOption) (dfdaemonv1.Daemon_SyncPieceTasksClient, error) {
 	netAddr := dfnet.NetAddr{
 		Type: dfnet.TCP,
-		Addr: fmt.Sprintf("%s:%d", destPeer.Ip, destPeer.RpcPort),
+		Addr: net.JoinHostPort(destPeer.Ip, strconv.Itoa(int(destPeer.RpcPort))),
 	}
 
+	return SyncPieceTasksByAddr(ctx, netAddr, ptr, opts...)
+}
+
+// SyncPieceTasksByAddr syncs piece tasks from the peer grpc of netAddr, like unix socket of peer on the same host.
+func SyncPieceTasksByAddr(ctx context.Context,
+	netAddr dfnet.NetAddr,
+	ptr *commonv1.PieceTaskRequest,
+	opts ...grpc.CallOption) (dfdaemonv1.Daemon_SyncPieceTasksClient, error) {
 	client, err := GetElasticClientByAddrs([]dfnet.NetAddr{netAddr})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,54 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// streamTaskReader reads the content chunks streamed by daemon in order,
+// io.EOF is returned after all content is read.
+type streamTaskReader struct {
+	stream grpc.ClientStream
+	cancel context.CancelFunc
+	// buf is the unread content of the current chunk
+	buf []byte
+}
+
+func (r *streamTaskReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		chunk := new(wrapperspb.BytesValue)
+		if err := r.stream.RecvMsg(chunk); err != nil {
+			return 0, err
+		}
+
+		r.buf = chunk.Value
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close cancels the stream, daemon cancels the task when the content is not read completely.
+func (r *streamTaskReader) Close() error {
+	r.cancel()
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/cache_mock.go -source cache.go -package mocks
+
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+)
+
+const (
+	// CacheServiceName is the grpc service name of daemon local cache.
+	CacheServiceName = "dfdaemon.v1.Cache"
+
+	// CheckCacheMethod is the full method name of checking local cache.
+	CheckCacheMethod = "/" + CacheServiceName + "/CheckCache"
+)
+
+// CacheState is the state of task in local cache.
+type CacheState string
+
+const (
+	// CacheStateHit means the task is fully cached and its digests are valid.
+	CacheStateHit CacheState = "hit"
+
+	// CacheStatePartial means some pieces of the task are cached,
+	// and the task can be resumed from them.
+	CacheStatePartial CacheState = "partial"
+
+	// CacheStateMiss means the task is not cached or the cached data is invalid.
+	CacheStateMiss CacheState = "miss"
+)
+
+// CacheStatus is the result of checking local cache.
+type CacheStatus struct {
+	TaskID        string     `json:"taskID"`
+	State         CacheState `json:"state"`
+	ContentLength int64      `json:"contentLength"`
+	TotalPieces   int32      `json:"totalPieces"`
+	// Reason explains why the task is not hit.
+	Reason string `json:"reason,omitempty"`
+}
+
+// CacheServer checks the local cache of daemon without downloading,
+// the service is not defined in d7y.io/api, so the service descriptor is maintained here
+// like StatusServer, and the request is the same as StatTask.
+type CacheServer interface {
+	// CheckCache returns the state of task in local cache and validates the digests of cached task
+	CheckCache(context.Context, *dfdaemonv1.StatTaskRequest) (*CacheStatus, error)
+}
+
+// CacheServiceDesc is the grpc service descriptor of daemon local cache.
+var CacheServiceDesc = grpc.ServiceDesc{
+	ServiceName: CacheServiceName,
+	HandlerType: (*CacheServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CheckCache",
+			Handler:    checkCacheHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/rpc/dfdaemon/server/cache.go",
+}
+
+// RegisterCacheServer registers cache server to grpc server.
+func RegisterCacheServer(s *grpc.Server, srv CacheServer) {
+	s.RegisterService(&CacheServiceDesc, srv)
+}
+
+func checkCacheHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(dfdaemonv1.StatTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req any) (any, error) {
+		status, err := srv.(CacheServer).CheckCache(ctx, req.(*dfdaemonv1.StatTaskRequest))
+		if err != nil {
+			return nil, err
+		}
+
+		return EncodeCacheStatus(status)
+	}
+
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CheckCacheMethod,
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EncodeCacheStatus encodes cache status to the message on the wire.
+func EncodeCacheStatus(status *CacheStatus) (*structpb.Struct, error) {
+	return encodeStruct(status)
+}
+
+// DecodeCacheStatus decodes cache status from the message on the wire.
+func DecodeCacheStatus(msg *structpb.Struct) (*CacheStatus, error) {
+	status := new(CacheStatus)
+	if err := decodeStruct(msg, status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
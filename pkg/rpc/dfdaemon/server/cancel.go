@@ -0,0 +1,114 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/cancel_mock.go -source cancel.go -package mocks
+
+package server
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// CancelerServiceName is the grpc service name of canceling tasks.
+	CancelerServiceName = "dfdaemon.v1.Canceler"
+
+	// CancelTaskMethod is the full method name of canceling a task.
+	CancelTaskMethod = "/" + CancelerServiceName + "/CancelTask"
+)
+
+// CancelTaskRequest is the request of canceling an in-flight task.
+type CancelTaskRequest struct {
+	TaskID string `json:"taskID"`
+	// Force cancels the task even if it is shared by other requests.
+	Force bool `json:"force"`
+}
+
+// CancelServer aborts in-flight tasks and reclaims their resources,
+// the service is not defined in d7y.io/api, so the service descriptor is maintained here
+// like StatusServer, and the request is carried in google.protobuf.Struct.
+type CancelServer interface {
+	// CancelTask cancels the running task, the peer leaves the task and the local storage is reclaimed
+	CancelTask(context.Context, *CancelTaskRequest) error
+}
+
+// CancelerServiceDesc is the grpc service descriptor of canceling tasks.
+var CancelerServiceDesc = grpc.ServiceDesc{
+	ServiceName: CancelerServiceName,
+	HandlerType: (*CancelServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CancelTask",
+			Handler:    cancelTaskHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/rpc/dfdaemon/server/cancel.go",
+}
+
+// RegisterCancelServer registers cancel server to grpc server.
+func RegisterCancelServer(s *grpc.Server, srv CancelServer) {
+	s.RegisterService(&CancelerServiceDesc, srv)
+}
+
+func cancelTaskHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(structpb.Struct)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, msg any) (any, error) {
+		req, err := DecodeCancelTaskRequest(msg.(*structpb.Struct))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := srv.(CancelServer).CancelTask(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return new(emptypb.Empty), nil
+	}
+
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CancelTaskMethod,
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EncodeCancelTaskRequest encodes cancel task request to the message on the wire.
+func EncodeCancelTaskRequest(req *CancelTaskRequest) (*structpb.Struct, error) {
+	return encodeStruct(req)
+}
+
+// DecodeCancelTaskRequest decodes cancel task request from the message on the wire.
+func DecodeCancelTaskRequest(msg *structpb.Struct) (*CancelTaskRequest, error) {
+	req := new(CancelTaskRequest)
+	if err := decodeStruct(msg, req); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
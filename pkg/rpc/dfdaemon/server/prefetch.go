@@ -0,0 +1,98 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/prefetch_mock.go -source prefetch.go -package mocks
+
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+)
+
+const (
+	// PrefetcherServiceName is the grpc service name of prefetcher.
+	PrefetcherServiceName = "dfdaemon.v1.Prefetcher"
+
+	// PrefetchMethod is the full method name of prefetching tasks.
+	PrefetchMethod = "/" + PrefetcherServiceName + "/Prefetch"
+
+	// MaxPrefetchBatchSize is the max count of tasks in one prefetch call.
+	MaxPrefetchBatchSize = 1024
+)
+
+// PrefetchServer warms tasks into local storage in the background,
+// the service is not defined in d7y.io/api, so the service descriptor is maintained here
+// and reuses dfdaemonv1.StatTaskRequest as the message of every task, which carries url and url meta.
+type PrefetchServer interface {
+	// Prefetch accepts tasks and returns without waiting for tasks done
+	Prefetch(context.Context, []*dfdaemonv1.StatTaskRequest) error
+}
+
+// PrefetcherServiceDesc is the grpc service descriptor of prefetcher,
+// client streams the tasks and server replies empty message after all tasks accepted.
+var PrefetcherServiceDesc = grpc.ServiceDesc{
+	ServiceName: PrefetcherServiceName,
+	HandlerType: (*PrefetchServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Prefetch",
+			Handler:       prefetchHandler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/rpc/dfdaemon/server/prefetch.go",
+}
+
+// RegisterPrefetchServer registers prefetch server to grpc server.
+func RegisterPrefetchServer(s *grpc.Server, srv PrefetchServer) {
+	s.RegisterService(&PrefetcherServiceDesc, srv)
+}
+
+func prefetchHandler(srv any, stream grpc.ServerStream) error {
+	var reqs []*dfdaemonv1.StatTaskRequest
+	for {
+		req := new(dfdaemonv1.StatTaskRequest)
+		if err := stream.RecvMsg(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return err
+		}
+
+		if len(reqs) >= MaxPrefetchBatchSize {
+			return status.Errorf(codes.InvalidArgument, "prefetch tasks exceed the max batch size %d", MaxPrefetchBatchSize)
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	if err := srv.(PrefetchServer).Prefetch(stream.Context(), reqs); err != nil {
+		return err
+	}
+
+	return stream.SendMsg(new(emptypb.Empty))
+}
@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/status_mock.go -source status.go -package mocks
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// StatusServiceName is the grpc service name of daemon status.
+	StatusServiceName = "dfdaemon.v1.Status"
+
+	// GetStatusMethod is the full method name of getting daemon status.
+	GetStatusMethod = "/" + StatusServiceName + "/GetStatus"
+)
+
+// Status is the runtime status of daemon.
+type Status struct {
+	Tasks      []*TaskStatus   `json:"tasks"`
+	Storage    StorageStatus   `json:"storage"`
+	RateLimit  RateLimitStatus `json:"rateLimit"`
+	Schedulers []string        `json:"schedulers"`
+}
+
+// TaskStatus is the progress of a running task.
+type TaskStatus struct {
+	TaskID          string        `json:"taskID"`
+	PeerID          string        `json:"peerID"`
+	URL             string        `json:"url"`
+	Seed            bool          `json:"seed"`
+	ContentLength   int64         `json:"contentLength"`
+	CompletedLength int64         `json:"completedLength"`
+	TotalPieces     int32         `json:"totalPieces"`
+	CompletedPieces int32         `json:"completedPieces"`
+	Subscribers     int32         `json:"subscribers"`
+	ETA             time.Duration `json:"eta"`
+	StartTime       time.Time     `json:"startTime"`
+}
+
+// StorageStatus is the usage of local storage.
+type StorageStatus struct {
+	DataPath        string  `json:"dataPath"`
+	TaskCount       int     `json:"taskCount"`
+	UsedBytes       int64   `json:"usedBytes"`
+	QuotaBytes      int64   `json:"quotaBytes"`
+	DiskUsedPercent float64 `json:"diskUsedPercent"`
+}
+
+// RateLimitStatus is the current rate limits in bytes per second, negative means unlimited.
+type RateLimitStatus struct {
+	Download int64 `json:"download"`
+	Upload   int64 `json:"upload"`
+	PerPeer  int64 `json:"perPeer"`
+}
+
+// StatusServer reports the runtime status of daemon,
+// the service is not defined in d7y.io/api, so the service descriptor is maintained here
+// and the status is carried in google.protobuf.Struct, which keeps it readable by reflection clients.
+type StatusServer interface {
+	// GetStatus returns the runtime status of daemon
+	GetStatus(context.Context) (*Status, error)
+}
+
+// StatusServiceDesc is the grpc service descriptor of daemon status.
+var StatusServiceDesc = grpc.ServiceDesc{
+	ServiceName: StatusServiceName,
+	HandlerType: (*StatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    getStatusHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/rpc/dfdaemon/server/status.go",
+}
+
+// RegisterStatusServer registers status server to grpc server.
+func RegisterStatusServer(s *grpc.Server, srv StatusServer) {
+	s.RegisterService(&StatusServiceDesc, srv)
+}
+
+func getStatusHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, _ any) (any, error) {
+		status, err := srv.(StatusServer).GetStatus(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return EncodeStatus(status)
+	}
+
+	if interceptor == nil {
+		return handler(ctx, in)
+	}
+
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GetStatusMethod,
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EncodeStatus encodes status to the message on the wire.
+func EncodeStatus(status *Status) (*structpb.Struct, error) {
+	return encodeStruct(status)
+}
+
+// DecodeStatus decodes status from the message on the wire.
+func DecodeStatus(msg *structpb.Struct) (*Status, error) {
+	status := new(Status)
+	if err := decodeStruct(msg, status); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// encodeStruct encodes v to google.protobuf.Struct through json.
+func encodeStruct(v any) (*structpb.Struct, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := new(structpb.Struct)
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// decodeStruct decodes google.protobuf.Struct to v through json.
+func decodeStruct(msg *structpb.Struct, v any) error {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,103 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/stream_mock.go -source stream.go -package mocks
+
+package server
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+)
+
+const (
+	// StreamerServiceName is the grpc service name of streamer.
+	StreamerServiceName = "dfdaemon.v1.Streamer"
+
+	// StreamTaskMethod is the full method name of streaming the content of task.
+	StreamTaskMethod = "/" + StreamerServiceName + "/StreamTask"
+
+	// maxStreamChunkSize is the max size of content in one message, which is
+	// less than the default max receive message size of grpc client.
+	maxStreamChunkSize = 1024 * 1024
+)
+
+// StreamServer streams the content of task in order while the task is downloading, so the
+// content can be piped to other programs, the service is not defined in d7y.io/api, so the
+// service descriptor is maintained here and reuses dfdaemonv1.DownRequest as the request,
+// the output of request is ignored.
+type StreamServer interface {
+	// StreamTask downloads the task and writes the content to w in order
+	StreamTask(context.Context, *dfdaemonv1.DownRequest, io.Writer) error
+}
+
+// StreamerServiceDesc is the grpc service descriptor of streamer,
+// server streams the content in chunks of wrapperspb.BytesValue.
+var StreamerServiceDesc = grpc.ServiceDesc{
+	ServiceName: StreamerServiceName,
+	HandlerType: (*StreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTask",
+			Handler:       streamTaskHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/rpc/dfdaemon/server/stream.go",
+}
+
+// RegisterStreamServer registers stream server to grpc server.
+func RegisterStreamServer(s *grpc.Server, srv StreamServer) {
+	s.RegisterService(&StreamerServiceDesc, srv)
+}
+
+func streamTaskHandler(srv any, stream grpc.ServerStream) error {
+	req := new(dfdaemonv1.DownRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+
+	return srv.(StreamServer).StreamTask(stream.Context(), req, &chunkWriter{stream: stream})
+}
+
+// chunkWriter sends the written content in chunks.
+type chunkWriter struct {
+	stream grpc.ServerStream
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	var n int
+	for n < len(p) {
+		size := len(p) - n
+		if size > maxStreamChunkSize {
+			size = maxStreamChunkSize
+		}
+
+		// the message is serialized in SendMsg, so p can be reused after returning
+		if err := w.stream.SendMsg(wrapperspb.Bytes(p[n : n+size])); err != nil {
+			return n, err
+		}
+		n += size
+	}
+
+	return n, nil
+}
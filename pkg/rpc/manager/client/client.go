@@ -37,6 +37,7 @@ import (
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/reachable"
+	"d7y.io/dragonfly/v2/pkg/retry"
 )
 
 const (
@@ -49,6 +50,16 @@ const (
 
 	// perRetryTimeout is GRPC timeout per call (including initial call) on this call.
 	perRetryTimeout = 5 * time.Second
+
+	// registerInitBackoff is initial backoff in seconds of registering again.
+	registerInitBackoff = 1
+
+	// registerMaxBackoff is maximum backoff in seconds of registering again.
+	registerMaxBackoff = 30
+
+	// registerMaxAttempts is maximum attempts of registering again
+	// before rebuilding keepalive stream.
+	registerMaxAttempts = 5
 )
 
 // defaultDialOptions is default dial options of manager client.
@@ -120,12 +131,41 @@ type Client interface {
 	ListBuckets(context.Context, *managerv1.ListBucketsRequest) (*managerv1.ListBucketsResponse, error)
 
 	// KeepAlive with manager.
-	KeepAlive(time.Duration, *managerv1.KeepAliveRequest)
+	KeepAlive(time.Duration, *managerv1.KeepAliveRequest, ...KeepAliveOption)
 
 	// Close client connect.
 	Close() error
 }
 
+// keepAliveOptions is options of keepalive.
+type keepAliveOptions struct {
+	// register is called before rebuilding the terminated keepalive stream.
+	register func(context.Context) error
+
+	// ctx stops keepalive when it is done.
+	ctx context.Context
+}
+
+// KeepAliveOption is a functional option for configuring the keepalive.
+type KeepAliveOption func(*keepAliveOptions)
+
+// WithRegister sets the function to register again and resync config
+// when the keepalive stream is terminated, e.g. manager restarted
+// and the instance is marked inactive.
+func WithRegister(register func(context.Context) error) KeepAliveOption {
+	return func(o *keepAliveOptions) {
+		o.register = register
+	}
+}
+
+// WithContext sets the context of keepalive, the keepalive stream is closed
+// when the context is done, then manager marks the instance inactive.
+func WithContext(ctx context.Context) KeepAliveOption {
+	return func(o *keepAliveOptions) {
+		o.ctx = ctx
+	}
+}
+
 // client provides manager grpc function.
 type client struct {
 	managerv1.ManagerClient
@@ -162,9 +202,26 @@ func (c *client) ListBuckets(ctx context.Context, req *managerv1.ListBucketsRequ
 	return c.ManagerClient.ListBuckets(ctx, req)
 }
 
-// List acitve schedulers configuration.
-func (c *client) KeepAlive(interval time.Duration, keepalive *managerv1.KeepAliveRequest) {
+// KeepAlive with manager.
+func (c *client) KeepAlive(interval time.Duration, keepalive *managerv1.KeepAliveRequest, options ...KeepAliveOption) {
+	opts := &keepAliveOptions{ctx: context.Background()}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	var terminated bool
 retry:
+	if opts.ctx.Err() != nil {
+		logger.Infof("hostname %s ip %s cluster id %d stop keepalive", keepalive.HostName, keepalive.Ip, keepalive.ClusterId)
+		return
+	}
+
+	// Register again when keepalive stream is terminated, because manager
+	// may be restarted and marks the instance inactive.
+	if terminated && opts.register != nil {
+		c.register(keepalive, opts.register)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	stream, err := c.ManagerClient.KeepAlive(ctx)
 	if err != nil {
@@ -176,12 +233,22 @@ retry:
 
 		time.Sleep(interval)
 		cancel()
+		terminated = true
 		goto retry
 	}
 
 	tick := time.NewTicker(interval)
 	for {
 		select {
+		case <-opts.ctx.Done():
+			if _, err := stream.CloseAndRecv(); err != nil {
+				logger.Errorf("hostname %s ip %s cluster id %d close and recv stream failed: %v", keepalive.HostName, keepalive.Ip, keepalive.ClusterId, err)
+			}
+
+			logger.Infof("hostname %s ip %s cluster id %d stop keepalive", keepalive.HostName, keepalive.Ip, keepalive.ClusterId)
+			tick.Stop()
+			cancel()
+			return
 		case <-tick.C:
 			if err := stream.Send(&managerv1.KeepAliveRequest{
 				SourceType: keepalive.SourceType,
@@ -193,13 +260,35 @@ retry:
 					logger.Errorf("hostname %s ip %s cluster id %d close and recv stream failed: %v", keepalive.HostName, keepalive.Ip, keepalive.ClusterId, err)
 				}
 
+				tick.Stop()
 				cancel()
+				terminated = true
 				goto retry
 			}
 		}
 	}
 }
 
+// register calls register function with exponential backoff.
+func (c *client) register(keepalive *managerv1.KeepAliveRequest, register func(context.Context) error) {
+	if _, err := retry.Run(context.Background(), registerInitBackoff, registerMaxBackoff, registerMaxAttempts, func(int) (any, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), perRetryTimeout)
+		defer cancel()
+
+		if err := register(ctx); err != nil {
+			logger.Warnf("hostname %s ip %s cluster id %d register again failed: %v", keepalive.HostName, keepalive.Ip, keepalive.ClusterId, err)
+			return nil, err
+		}
+
+		return nil, nil
+	}); err != nil {
+		logger.Errorf("hostname %s ip %s cluster id %d register again failed: %v", keepalive.HostName, keepalive.Ip, keepalive.ClusterId, err)
+		return
+	}
+
+	logger.Infof("hostname %s ip %s cluster id %d register again successfully", keepalive.HostName, keepalive.Ip, keepalive.ClusterId)
+}
+
 // Close grpc service.
 func (c *client) Close() error {
 	return c.conn.Close()
@@ -10,6 +10,7 @@ import (
 	time "time"
 
 	v1 "d7y.io/api/pkg/apis/manager/v1"
+	client "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -81,15 +82,20 @@ func (mr *MockClientMockRecorder) GetScheduler(arg0, arg1 interface{}) *gomock.C
 }
 
 // KeepAlive mocks base method.
-func (m *MockClient) KeepAlive(arg0 time.Duration, arg1 *v1.KeepAliveRequest) {
+func (m *MockClient) KeepAlive(arg0 time.Duration, arg1 *v1.KeepAliveRequest, arg2 ...client.KeepAliveOption) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "KeepAlive", arg0, arg1)
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "KeepAlive", varargs...)
 }
 
 // KeepAlive indicates an expected call of KeepAlive.
-func (mr *MockClientMockRecorder) KeepAlive(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockClientMockRecorder) KeepAlive(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepAlive", reflect.TypeOf((*MockClient)(nil).KeepAlive), arg0, arg1)
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KeepAlive", reflect.TypeOf((*MockClient)(nil).KeepAlive), varargs...)
 }
 
 // ListBuckets mocks base method.
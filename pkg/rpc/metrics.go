@@ -0,0 +1,62 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"d7y.io/dragonfly/v2/internal/constants"
+)
+
+const (
+	// Client conn is evicted because it is not accessed for a long time.
+	EvictReasonExpired = "expired"
+
+	// Client conn is evicted because it is broken.
+	EvictReasonUnhealthy = "unhealthy"
+)
+
+var (
+	ClientConnCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.RPCClientMetricsName,
+		Name:      "conn_total",
+		Help:      "Current count of client conns in connection pool.",
+	}, []string{"name"})
+
+	ClientConnEvictedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.RPCClientMetricsName,
+		Name:      "conn_evicted_total",
+		Help:      "Counter of the evicted client conns in connection pool.",
+	}, []string{"name", "reason"})
+
+	ClientRequestRunningCount = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.RPCClientMetricsName,
+		Name:      "request_running_total",
+		Help:      "Current running count of requests and streams in connection pool.",
+	}, []string{"name"})
+
+	ClientRequestLimitedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.RPCClientMetricsName,
+		Name:      "request_limited_total",
+		Help:      "Counter of the requests waiting for the concurrency limit of target.",
+	}, []string{"name"})
+)
@@ -28,6 +28,7 @@ import (
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
@@ -36,6 +37,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/balancer"
 	"d7y.io/dragonfly/v2/pkg/resolver"
 	"d7y.io/dragonfly/v2/pkg/rpc/common"
+	"d7y.io/dragonfly/v2/pkg/rpc/scheduler/server"
 )
 
 const (
@@ -70,6 +72,8 @@ var defaultDialOptions = []grpc.DialOption{
 }
 
 // GetClient get scheduler clients using resolver and balancer,
+// requests of the same task are sent to the same scheduler by consistent hashing of task id,
+// and fail over to the next scheduler in the hash ring when the scheduler responds unavailable.
 func GetClient(options ...grpc.DialOption) (Client, error) {
 	conn, err := grpc.Dial(
 		resolver.SchedulerVirtualTarget,
@@ -128,10 +132,19 @@ type Client interface {
 	// A peer announces that it has the announced task to other peers.
 	AnnounceTask(context.Context, *schedulerv1.AnnounceTaskRequest, ...grpc.CallOption) error
 
+	// WatchPeerETA watches the estimated remaining time of downloading task pushed by scheduler.
+	WatchPeerETA(context.Context, *schedulerv1.PeerTarget, ...grpc.CallOption) (PeerETAStream, error)
+
 	// Close grpc service.
 	Close() error
 }
 
+// PeerETAStream receives the estimated remaining time of downloading task pushed by scheduler.
+type PeerETAStream interface {
+	// Recv returns the latest eta, io.EOF is returned when the peer finishes downloading.
+	Recv() (time.Duration, error)
+}
+
 // client provides scheduler grpc function.
 type client struct {
 	*grpc.ClientConn
@@ -207,3 +220,40 @@ func (c *client) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTask
 
 	return nil
 }
+
+// WatchPeerETA watches the estimated remaining time of downloading task pushed by scheduler.
+func (c *client) WatchPeerETA(ctx context.Context, req *schedulerv1.PeerTarget, options ...grpc.CallOption) (PeerETAStream, error) {
+	stream, err := c.ClientConn.NewStream(
+		context.WithValue(ctx, balancer.ContextKey, req.TaskId),
+		&server.EstimatorServiceDesc.Streams[0],
+		server.WatchPeerETAMethod,
+		options...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	return &peerETAStream{stream: stream}, nil
+}
+
+// peerETAStream receives the eta in durationpb.Duration.
+type peerETAStream struct {
+	stream grpc.ClientStream
+}
+
+func (s *peerETAStream) Recv() (time.Duration, error) {
+	eta := new(durationpb.Duration)
+	if err := s.stream.RecvMsg(eta); err != nil {
+		return 0, err
+	}
+
+	return eta.AsDuration(), nil
+}
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	v1 "d7y.io/api/pkg/apis/scheduler/v1"
+	client "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
 	gomock "github.com/golang/mock/gomock"
 	grpc "google.golang.org/grpc"
 )
@@ -166,3 +168,61 @@ func (mr *MockClientMockRecorder) StatTask(arg0, arg1 interface{}, arg2 ...inter
 	varargs := append([]interface{}{arg0, arg1}, arg2...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatTask", reflect.TypeOf((*MockClient)(nil).StatTask), varargs...)
 }
+
+// WatchPeerETA mocks base method.
+func (m *MockClient) WatchPeerETA(arg0 context.Context, arg1 *v1.PeerTarget, arg2 ...grpc.CallOption) (client.PeerETAStream, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WatchPeerETA", varargs...)
+	ret0, _ := ret[0].(client.PeerETAStream)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchPeerETA indicates an expected call of WatchPeerETA.
+func (mr *MockClientMockRecorder) WatchPeerETA(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchPeerETA", reflect.TypeOf((*MockClient)(nil).WatchPeerETA), varargs...)
+}
+
+// MockPeerETAStream is a mock of PeerETAStream interface.
+type MockPeerETAStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockPeerETAStreamMockRecorder
+}
+
+// MockPeerETAStreamMockRecorder is the mock recorder for MockPeerETAStream.
+type MockPeerETAStreamMockRecorder struct {
+	mock *MockPeerETAStream
+}
+
+// NewMockPeerETAStream creates a new mock instance.
+func NewMockPeerETAStream(ctrl *gomock.Controller) *MockPeerETAStream {
+	mock := &MockPeerETAStream{ctrl: ctrl}
+	mock.recorder = &MockPeerETAStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPeerETAStream) EXPECT() *MockPeerETAStreamMockRecorder {
+	return m.recorder
+}
+
+// Recv mocks base method.
+func (m *MockPeerETAStream) Recv() (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recv")
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recv indicates an expected call of Recv.
+func (mr *MockPeerETAStreamMockRecorder) Recv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recv", reflect.TypeOf((*MockPeerETAStream)(nil).Recv))
+}
@@ -0,0 +1,77 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/eta_mock.go -source eta.go -package mocks
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+)
+
+const (
+	// EstimatorServiceName is the grpc service name of estimator.
+	EstimatorServiceName = "scheduler.v1.Estimator"
+
+	// WatchPeerETAMethod is the full method name of watching the eta of peer.
+	WatchPeerETAMethod = "/" + EstimatorServiceName + "/WatchPeerETA"
+)
+
+// EstimatorServer pushes the estimated remaining time of downloading task to peers,
+// the service is not defined in d7y.io/api, so the service descriptor is maintained here
+// and reuses schedulerv1.PeerTarget as the request.
+type EstimatorServer interface {
+	// WatchPeerETA sends the eta of peer by send until the peer finishes downloading
+	WatchPeerETA(ctx context.Context, req *schedulerv1.PeerTarget, send func(time.Duration) error) error
+}
+
+// EstimatorServiceDesc is the grpc service descriptor of estimator,
+// server streams the eta in durationpb.Duration.
+var EstimatorServiceDesc = grpc.ServiceDesc{
+	ServiceName: EstimatorServiceName,
+	HandlerType: (*EstimatorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPeerETA",
+			Handler:       watchPeerETAHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/rpc/scheduler/server/eta.go",
+}
+
+// RegisterEstimatorServer registers estimator server to grpc server.
+func RegisterEstimatorServer(s *grpc.Server, srv EstimatorServer) {
+	s.RegisterService(&EstimatorServiceDesc, srv)
+}
+
+func watchPeerETAHandler(srv any, stream grpc.ServerStream) error {
+	req := new(schedulerv1.PeerTarget)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+
+	return srv.(EstimatorServer).WatchPeerETA(stream.Context(), req, func(eta time.Duration) error {
+		return stream.SendMsg(durationpb.New(eta))
+	})
+}
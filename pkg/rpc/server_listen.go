@@ -17,9 +17,11 @@
 package rpc
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"os"
+	"strconv"
 	"syscall"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
@@ -40,12 +42,18 @@ func Listen(netAddr dfnet.NetAddr) (net.Listener, error) {
 //    ListenWithPortRange("192.168.0.1", 12345, 23456)
 //    ListenWithPortRange("192.168.0.1", 0, 0) // random port
 func ListenWithPortRange(listen string, startPort, endPort int) (net.Listener, int, error) {
+	return ListenWithPortRangeAndConfig(net.ListenConfig{}, listen, startPort, endPort)
+}
+
+// ListenWithPortRangeAndConfig is same with ListenWithPortRange, but listens with lc,
+// it's useful to set socket options before binding, like binding to a network interface.
+func ListenWithPortRangeAndConfig(lc net.ListenConfig, listen string, startPort, endPort int) (net.Listener, int, error) {
 	if endPort < startPort {
 		endPort = startPort
 	}
 	for port := startPort; port <= endPort; port++ {
 		logger.Debugf("start to listen port: %s:%d", listen, port)
-		listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", listen, port))
+		listener, err := lc.Listen(context.Background(), "tcp", net.JoinHostPort(listen, strconv.Itoa(port)))
 		if err == nil && listener != nil {
 			return listener, listener.Addr().(*net.TCPAddr).Port, nil
 		}
@@ -0,0 +1,223 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// DefaultCertReloadInterval is the default interval of checking certificate files for rotation.
+const DefaultCertReloadInterval = time.Minute
+
+// CertReloader reloads the key pair and CA when their files are modified, the files are checked
+// in handshakes at most once per interval, so certificates are rotated without restart.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	interval time.Duration
+
+	mu        sync.Mutex
+	checkedAt time.Time
+	modTimes  []time.Time
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+}
+
+// NewCertReloader returns a new CertReloader, cert and key can be empty for clients without
+// certificate, and CA can be empty when the peer certificate is verified by system roots.
+func NewCertReloader(certFile, keyFile, caFile string, interval time.Duration) (*CertReloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("cert and key must be specified together")
+	}
+
+	if interval <= 0 {
+		interval = DefaultCertReloadInterval
+	}
+
+	r := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		interval: interval,
+	}
+
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// ServerCredentials returns grpc server credentials, client certificates are required
+// and verified by CA when CA is specified.
+func (r *CertReloader) ServerCredentials() (credentials.TransportCredentials, error) {
+	if r.certFile == "" {
+		return nil, errors.New("server requires cert and key")
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+			}
+
+			if pool != nil {
+				config.ClientCAs = pool
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+
+			return config, nil
+		},
+	}), nil
+}
+
+// ClientCredentials returns grpc client credentials, the server certificate is verified by CA,
+// and the client certificate is sent when cert and key are specified.
+func (r *CertReloader) ClientCredentials(serverName string) credentials.TransportCredentials {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	// Verify the server certificate by the current CA instead of RootCAs,
+	// which is fixed once credentials are created.
+	if r.caFile != "" {
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server certificate is not provided")
+			}
+
+			_, pool := r.current()
+			intermediates := x509.NewCertPool()
+			for _, cert := range cs.PeerCertificates[1:] {
+				intermediates.AddCert(cert)
+			}
+
+			_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+				Roots:         pool,
+				Intermediates: intermediates,
+				DNSName:       cs.ServerName,
+			})
+			return err
+		}
+	}
+
+	if r.certFile != "" {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		}
+	}
+
+	return credentials.NewTLS(config)
+}
+
+// current returns the certificate and CA, they are reloaded when files are modified.
+func (r *CertReloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checkedAt) < r.interval {
+		return r.cert, r.pool
+	}
+	r.checkedAt = time.Now()
+
+	modTimes, err := r.stat()
+	if err != nil {
+		logger.Warnf("stat certificate files error: %s, keep the previous certificates", err)
+		return r.cert, r.pool
+	}
+
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			if err := r.load(modTimes); err != nil {
+				logger.Warnf("reload certificates error: %s, keep the previous certificates", err)
+			} else {
+				logger.Infof("certificates reloaded, cert: %s, ca: %s", r.certFile, r.caFile)
+			}
+			break
+		}
+	}
+
+	return r.cert, r.pool
+}
+
+func (r *CertReloader) stat() ([]time.Time, error) {
+	var modTimes []time.Time
+	for _, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			modTimes = append(modTimes, time.Time{})
+			continue
+		}
+
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+
+	return modTimes, nil
+}
+
+func (r *CertReloader) load(modTimes []time.Time) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return err
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		ca, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return err
+		}
+
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return fmt.Errorf("invalid ca file %s", r.caFile)
+		}
+	}
+
+	r.cert = cert
+	r.pool = pool
+	r.modTimes = modTimes
+	return nil
+}
@@ -0,0 +1,283 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-1 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testCA{cert: cert, key: key}
+}
+
+// writeCA writes the CA certificate to path.
+func (ca *testCA) writeCA(t *testing.T, path string) {
+	writePEM(t, path, "CERTIFICATE", ca.cert.Raw)
+}
+
+// writeKeyPair issues a certificate signed by the CA and writes it to the cert and key paths.
+func (ca *testCA) writeKeyPair(t *testing.T, certPath, keyPath string, usage x509.ExtKeyUsage) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestTLSServer(t *testing.T, creds grpc.ServerOption) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(creds)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis) // nolint: errcheck
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func checkHealth(addr string, creds grpc.DialOption) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, creds)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestNewCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, "ca")
+	ca.writeCA(t, filepath.Join(dir, "ca.crt"))
+	ca.writeKeyPair(t, filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), x509.ExtKeyUsageServerAuth)
+
+	tests := []struct {
+		name     string
+		certFile string
+		keyFile  string
+		caFile   string
+		expect   func(t *testing.T, r *CertReloader, err error)
+	}{
+		{
+			name:     "load key pair and ca",
+			certFile: filepath.Join(dir, "server.crt"),
+			keyFile:  filepath.Join(dir, "server.key"),
+			caFile:   filepath.Join(dir, "ca.crt"),
+			expect: func(t *testing.T, r *CertReloader, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NotNil(r.cert)
+				assert.NotNil(r.pool)
+			},
+		},
+		{
+			name:   "load ca only",
+			caFile: filepath.Join(dir, "ca.crt"),
+			expect: func(t *testing.T, r *CertReloader, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Nil(r.cert)
+				assert.NotNil(r.pool)
+			},
+		},
+		{
+			name:     "cert without key",
+			certFile: filepath.Join(dir, "server.crt"),
+			expect: func(t *testing.T, r *CertReloader, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "cert and key must be specified together")
+			},
+		},
+		{
+			name:     "cert file not found",
+			certFile: filepath.Join(dir, "foo.crt"),
+			keyFile:  filepath.Join(dir, "server.key"),
+			expect: func(t *testing.T, r *CertReloader, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name:   "invalid ca file",
+			caFile: filepath.Join(dir, "server.key"),
+			expect: func(t *testing.T, r *CertReloader, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := NewCertReloader(tc.certFile, tc.keyFile, tc.caFile, 0)
+			tc.expect(t, r, err)
+		})
+	}
+}
+
+func TestCertReloader_MutualTLS(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	ca := newTestCA(t, "ca")
+	ca.writeCA(t, filepath.Join(dir, "ca.crt"))
+	ca.writeKeyPair(t, filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), x509.ExtKeyUsageServerAuth)
+	ca.writeKeyPair(t, filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), x509.ExtKeyUsageClientAuth)
+
+	serverReloader, err := NewCertReloader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverCreds, err := serverReloader.ServerCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := newTestTLSServer(t, grpc.Creds(serverCreds))
+
+	clientReloader, err := NewCertReloader(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(checkHealth(addr, grpc.WithTransportCredentials(clientReloader.ClientCredentials("localhost"))))
+
+	// Client without certificate is rejected.
+	caReloader, err := NewCertReloader("", "", filepath.Join(dir, "ca.crt"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(checkHealth(addr, grpc.WithTransportCredentials(caReloader.ClientCredentials("localhost"))))
+
+	// Server name mismatch is rejected.
+	assert.Error(checkHealth(addr, grpc.WithTransportCredentials(clientReloader.ClientCredentials("foo"))))
+}
+
+func TestCertReloader_Rotation(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	oldCA := newTestCA(t, "old ca")
+	oldCA.writeCA(t, filepath.Join(dir, "ca.crt"))
+	oldCA.writeKeyPair(t, filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), x509.ExtKeyUsageServerAuth)
+
+	serverReloader, err := NewCertReloader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), "", time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverCreds, err := serverReloader.ServerCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := newTestTLSServer(t, grpc.Creds(serverCreds))
+
+	clientReloader, err := NewCertReloader("", "", filepath.Join(dir, "ca.crt"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := grpc.WithTransportCredentials(clientReloader.ClientCredentials("localhost"))
+	assert.NoError(checkHealth(addr, creds))
+
+	// Server certificate issued by the new CA is rejected before the client trusts the new CA.
+	newCA := newTestCA(t, "new ca")
+	newCA.writeKeyPair(t, filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), x509.ExtKeyUsageServerAuth)
+	time.Sleep(10 * time.Millisecond)
+	assert.Error(checkHealth(addr, creds))
+
+	// Both sides pick up the rotated files without restart.
+	newCA.writeCA(t, filepath.Join(dir, "ca.crt"))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(checkHealth(addr, creds))
+}
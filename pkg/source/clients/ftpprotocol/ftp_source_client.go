@@ -0,0 +1,486 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ftpprotocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	FTPClient = "ftp"
+)
+
+const (
+	// username and password are used when they are not in url, default is anonymous
+	username = "username"
+	password = "password"
+	// timeout is the timeout of dialing and replies of control connection
+	timeout = "timeout"
+)
+
+const (
+	defaultPort       = "21"
+	defaultTimeout    = 30 * time.Second
+	anonymousUsername = "anonymous"
+	anonymousPassword = "anonymous@"
+
+	// mdtmLayout is the time layout in reply of MDTM, see RFC 3659
+	mdtmLayout = "20060102150405"
+)
+
+func init() {
+	if err := source.Register(FTPClient, NewFTPSourceClient(), adapter); err != nil {
+		panic(err)
+	}
+}
+
+func adapter(request *source.Request) *source.Request {
+	clonedRequest := request.Clone(request.Context())
+	return clonedRequest
+}
+
+// ftpOptions is the options of ftp source client from config.
+type ftpOptions struct {
+	username string
+	password string
+	timeout  time.Duration
+}
+
+// ftpSourceClient is an implementation of the interface of source.ResourceClient,
+// every request uses a new control connection in passive mode.
+type ftpSourceClient struct {
+	options atomic.Value // *ftpOptions
+}
+
+type FTPSourceClientOption func(p *ftpSourceClient)
+
+func NewFTPSourceClient(opts ...FTPSourceClientOption) source.ResourceClient {
+	return newFTPSourceClient(opts...)
+}
+
+func newFTPSourceClient(opts ...FTPSourceClientOption) *ftpSourceClient {
+	sourceClient := &ftpSourceClient{}
+	sourceClient.options.Store(&ftpOptions{
+		username: anonymousUsername,
+		password: anonymousPassword,
+		timeout:  defaultTimeout,
+	})
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+var (
+	_ source.ResourceClient = (*ftpSourceClient)(nil)
+	_ source.Configurer     = (*ftpSourceClient)(nil)
+)
+
+// Configure sets the default username, password and timeout.
+func (f *ftpSourceClient) Configure(options map[string]string) error {
+	opts := &ftpOptions{
+		username: anonymousUsername,
+		password: anonymousPassword,
+		timeout:  defaultTimeout,
+	}
+	for key, value := range options {
+		switch key {
+		case username:
+			opts.username = value
+		case password:
+			opts.password = value
+		case timeout:
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("parse ftp source client option %s: %w", key, err)
+			}
+			opts.timeout = d
+		default:
+			return fmt.Errorf("unknown ftp source client option %s", key)
+		}
+	}
+
+	f.options.Store(opts)
+	return nil
+}
+
+func (f *ftpSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	conn, err := f.dial(request.Context(), request.URL)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+	defer conn.Close()
+
+	size, err := conn.size(request.URL.Path)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+	return size, nil
+}
+
+func (f *ftpSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	conn, err := f.dial(request.Context(), request.URL)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.size(request.URL.Path); err != nil {
+		return false, err
+	}
+
+	// the server restarts transfer at given offset when REST is supported
+	if _, _, err := conn.cmd(3, "REST 0"); err != nil {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (f *ftpSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	conn, err := f.dial(request.Context(), request.URL)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	modTime, err := conn.modTime(request.URL.Path)
+	if err != nil {
+		return false, err
+	}
+	return modTime.Format(source.TimeFormat) != info.LastModified, nil
+}
+
+func (f *ftpSourceClient) Download(request *source.Request) (*source.Response, error) {
+	conn, err := f.dial(request.Context(), request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := conn.size(request.URL.Path)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var expireInfo source.ExpireInfo
+	if modTime, err := conn.modTime(request.URL.Path); err == nil {
+		expireInfo.LastModified = modTime.Format(source.TimeFormat)
+	}
+
+	var (
+		offset int64
+		length = size
+	)
+	if request.Header.Get(source.Range) != "" {
+		rg, err := http.ParseRange(request.Header.Get(source.Range), uint64(size))
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		offset, length = int64(rg.StartIndex), int64(rg.Length())
+	}
+
+	body, err := conn.retrieve(request.URL.Path, offset, length)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		body,
+		source.WithContentLength(length),
+		source.WithExpireInfo(expireInfo),
+	), nil
+}
+
+func (f *ftpSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	conn, err := f.dial(request.Context(), request.URL)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	modTime, err := conn.modTime(request.URL.Path)
+	if err != nil {
+		return -1, err
+	}
+	return modTime.UnixNano() / time.Millisecond.Nanoseconds(), nil
+}
+
+// dial connects to the ftp server of url and logs in.
+func (f *ftpSourceClient) dial(ctx context.Context, u *url.URL) (*ftpConn, error) {
+	opts := f.options.Load().(*ftpOptions)
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	dialer := &net.Dialer{Timeout: opts.timeout}
+	nc, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(u.Hostname(), port))
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &ftpConn{
+		Conn:    textproto.NewConn(nc),
+		nc:      nc,
+		host:    u.Hostname(),
+		dialer:  dialer,
+		timeout: opts.timeout,
+		done:    make(chan struct{}),
+	}
+
+	// abort the blocking reads and writes when context is done
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.abort()
+		case <-conn.done:
+		}
+	}()
+
+	if err := conn.login(u, opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// ftpConn is the control connection of ftp.
+type ftpConn struct {
+	*textproto.Conn
+	nc      net.Conn
+	host    string
+	dialer  *net.Dialer
+	timeout time.Duration
+
+	mu        sync.Mutex
+	data      net.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// cmd sends command and reads the reply, expectCode of one digit matches the reply class.
+func (c *ftpConn) cmd(expectCode int, format string, args ...any) (int, string, error) {
+	if err := c.nc.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, "", err
+	}
+
+	id, err := c.Cmd(format, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	c.StartResponse(id)
+	defer c.EndResponse(id)
+
+	return c.ReadResponse(expectCode)
+}
+
+func (c *ftpConn) login(u *url.URL, opts *ftpOptions) error {
+	if err := c.nc.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+
+	if _, _, err := c.ReadResponse(220); err != nil {
+		return err
+	}
+
+	user, pass := opts.username, opts.password
+	if u.User != nil {
+		user = u.User.Username()
+		pass, _ = u.User.Password()
+	}
+
+	code, msg, err := c.cmd(0, "USER %s", user)
+	if err != nil {
+		return err
+	}
+
+	switch code {
+	case 230:
+	case 331:
+		if _, _, err := c.cmd(230, "PASS %s", pass); err != nil {
+			return err
+		}
+	default:
+		return &textproto.Error{Code: code, Msg: msg}
+	}
+
+	_, _, err = c.cmd(200, "TYPE I")
+	return err
+}
+
+func (c *ftpConn) size(path string) (int64, error) {
+	_, msg, err := c.cmd(213, "SIZE %s", path)
+	if err != nil {
+		return -1, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+}
+
+func (c *ftpConn) modTime(path string) (time.Time, error) {
+	_, msg, err := c.cmd(213, "MDTM %s", path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// the reply may carry fractions of second, like 20220101120000.123
+	msg = strings.TrimSpace(msg)
+	if len(msg) < len(mdtmLayout) {
+		return time.Time{}, fmt.Errorf("invalid MDTM reply %q", msg)
+	}
+	return time.ParseInLocation(mdtmLayout, msg[:len(mdtmLayout)], time.UTC)
+}
+
+// openData opens the data connection in passive mode, EPSV is preferred and PASV is the fallback.
+// The data connection always goes to the host of control connection, the address in PASV reply
+// is ignored because it is usually wrong behind NAT.
+func (c *ftpConn) openData() (net.Conn, error) {
+	var port int
+	if _, msg, err := c.cmd(229, "EPSV"); err == nil {
+		// Entering Extended Passive Mode (|||port|)
+		start, end := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+		if start < 0 || end < start+4 {
+			return nil, fmt.Errorf("invalid EPSV reply %q", msg)
+		}
+		if port, err = strconv.Atoi(msg[start+4 : end]); err != nil {
+			return nil, fmt.Errorf("invalid EPSV reply %q", msg)
+		}
+	} else {
+		_, msg, err := c.cmd(227, "PASV")
+		if err != nil {
+			return nil, err
+		}
+
+		// Entering Passive Mode (h1,h2,h3,h4,p1,p2)
+		start, end := strings.Index(msg, "("), strings.LastIndex(msg, ")")
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("invalid PASV reply %q", msg)
+		}
+		fields := strings.Split(msg[start+1:end], ",")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid PASV reply %q", msg)
+		}
+		p1, err1 := strconv.Atoi(fields[4])
+		p2, err2 := strconv.Atoi(fields[5])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid PASV reply %q", msg)
+		}
+		port = p1<<8 + p2
+	}
+
+	return c.dialer.Dial("tcp", net.JoinHostPort(c.host, strconv.Itoa(port)))
+}
+
+// retrieve starts transfer of the file from offset, the returned body reads length bytes
+// and closes the control connection when it is closed.
+func (c *ftpConn) retrieve(path string, offset, length int64) (io.ReadCloser, error) {
+	data, err := c.openData()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.data = data
+	c.mu.Unlock()
+
+	if offset > 0 {
+		if _, _, err := c.cmd(350, "REST %d", offset); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, _, err := c.cmd(1, "RETR %s", path); err != nil {
+		return nil, err
+	}
+
+	// transfer may take long time, the deadline is only for control connection replies
+	if err := c.nc.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return &ftpBody{
+		Reader: io.LimitReader(data, length),
+		conn:   c,
+	}, nil
+}
+
+// abort closes connections to interrupt the blocking operations.
+func (c *ftpConn) abort() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.data != nil {
+		c.data.Close()
+	}
+	c.nc.Close()
+}
+
+// Close quits and closes the connections.
+func (c *ftpConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+
+		c.mu.Lock()
+		if c.data != nil {
+			c.data.Close()
+			c.data = nil
+			// the reply of transfer is 226 when transfer completed, or 426 when it is aborted
+			if e := c.nc.SetDeadline(time.Now().Add(c.timeout)); e == nil {
+				_, _, _ = c.ReadResponse(0)
+			}
+		}
+		c.mu.Unlock()
+
+		_, _, _ = c.cmd(2, "QUIT")
+		err = c.Conn.Close()
+	})
+	return err
+}
+
+// ftpBody is the body of ftp response.
+type ftpBody struct {
+	io.Reader
+	conn *ftpConn
+}
+
+func (b *ftpBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if errors.Is(err, net.ErrClosed) {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (b *ftpBody) Close() error {
+	return b.conn.Close()
+}
@@ -0,0 +1,242 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ftpprotocol
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	testContent = "hello dragonfly ftp source client"
+	testPath    = "/pub/hello.txt"
+	testMDTM    = "20220801120000"
+)
+
+// serveFTP serves a minimal ftp server for tests, it only accepts user foo with password bar.
+func serveFTP(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			nc, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go handleFTP(nc)
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+func handleFTP(nc net.Conn) {
+	conn := textproto.NewConn(nc)
+	defer conn.Close()
+
+	var (
+		dataLn net.Listener
+		offset int
+	)
+	_ = conn.PrintfLine("220 ready")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch cmd {
+		case "USER":
+			_ = conn.PrintfLine("331 password required")
+		case "PASS":
+			if arg != "bar" {
+				_ = conn.PrintfLine("530 login incorrect")
+				continue
+			}
+			_ = conn.PrintfLine("230 logged in")
+		case "TYPE":
+			_ = conn.PrintfLine("200 type set")
+		case "SIZE", "MDTM":
+			if arg != testPath {
+				_ = conn.PrintfLine("550 file not found")
+				continue
+			}
+			if cmd == "SIZE" {
+				_ = conn.PrintfLine("213 %d", len(testContent))
+			} else {
+				_ = conn.PrintfLine("213 %s", testMDTM)
+			}
+		case "EPSV":
+			if dataLn, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				_ = conn.PrintfLine("425 can not open data connection")
+				continue
+			}
+			_ = conn.PrintfLine("229 Entering Extended Passive Mode (|||%d|)", dataLn.Addr().(*net.TCPAddr).Port)
+		case "REST":
+			offset, _ = strconv.Atoi(arg)
+			_ = conn.PrintfLine("350 restarting at %d", offset)
+		case "RETR":
+			data, err := dataLn.Accept()
+			dataLn.Close()
+			if err != nil {
+				_ = conn.PrintfLine("425 can not open data connection")
+				continue
+			}
+			_ = conn.PrintfLine("150 opening data connection")
+			_, _ = io.WriteString(data, testContent[offset:])
+			data.Close()
+			_ = conn.PrintfLine("226 transfer complete")
+		case "QUIT":
+			_ = conn.PrintfLine("221 bye")
+			return
+		default:
+			_ = conn.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func TestFTPSourceClient(t *testing.T) {
+	addr := serveFTP(t)
+	lastModified, _ := time.ParseInLocation(mdtmLayout, testMDTM, time.UTC)
+
+	tests := []struct {
+		name    string
+		url     string
+		header  map[string]string
+		options map[string]string
+		expect  func(t *testing.T, client *ftpSourceClient, request *source.Request)
+	}{
+		{
+			name:    "get content length with user in options",
+			url:     fmt.Sprintf("ftp://%s%s", addr, testPath),
+			options: map[string]string{username: "foo", password: "bar"},
+			expect: func(t *testing.T, client *ftpSourceClient, request *source.Request) {
+				assert := assert.New(t)
+				length, err := client.GetContentLength(request)
+				assert.NoError(err)
+				assert.Equal(int64(len(testContent)), length)
+
+				support, err := client.IsSupportRange(request)
+				assert.NoError(err)
+				assert.True(support)
+			},
+		},
+		{
+			name: "get last modified with user in url",
+			url:  fmt.Sprintf("ftp://foo:bar@%s%s", addr, testPath),
+			expect: func(t *testing.T, client *ftpSourceClient, request *source.Request) {
+				assert := assert.New(t)
+				modified, err := client.GetLastModified(request)
+				assert.NoError(err)
+				assert.Equal(lastModified.UnixNano()/time.Millisecond.Nanoseconds(), modified)
+
+				expired, err := client.IsExpired(request, &source.ExpireInfo{LastModified: lastModified.Format(source.TimeFormat)})
+				assert.NoError(err)
+				assert.False(expired)
+			},
+		},
+		{
+			name:    "download whole file",
+			url:     fmt.Sprintf("ftp://%s%s", addr, testPath),
+			options: map[string]string{username: "foo", password: "bar"},
+			expect: func(t *testing.T, client *ftpSourceClient, request *source.Request) {
+				assert := assert.New(t)
+				resp, err := client.Download(request)
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+				assert.Equal(int64(len(testContent)), resp.ContentLength)
+				assert.Equal(lastModified.Format(source.TimeFormat), resp.ExpireInfo().LastModified)
+			},
+		},
+		{
+			name:    "download range",
+			url:     fmt.Sprintf("ftp://%s%s", addr, testPath),
+			header:  map[string]string{source.Range: "6-14"},
+			options: map[string]string{username: "foo", password: "bar"},
+			expect: func(t *testing.T, client *ftpSourceClient, request *source.Request) {
+				assert := assert.New(t)
+				resp, err := client.Download(request)
+				assert.NoError(err)
+				defer resp.Body.Close()
+
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal("dragonfly", string(data))
+			},
+		},
+		{
+			name:    "login failed",
+			url:     fmt.Sprintf("ftp://%s%s", addr, testPath),
+			options: map[string]string{username: "foo", password: "baz"},
+			expect: func(t *testing.T, client *ftpSourceClient, request *source.Request) {
+				_, err := client.GetContentLength(request)
+				assert.Error(t, err)
+			},
+		},
+		{
+			name:    "file not found",
+			url:     fmt.Sprintf("ftp://%s/foo", addr),
+			options: map[string]string{username: "foo", password: "bar"},
+			expect: func(t *testing.T, client *ftpSourceClient, request *source.Request) {
+				_, err := client.Download(request)
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := newFTPSourceClient()
+			if err := client.Configure(tc.options); err != nil {
+				t.Fatal(err)
+			}
+
+			request, err := source.NewRequestWithHeader(tc.url, tc.header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.expect(t, client, request)
+		})
+	}
+}
+
+func TestFTPSourceClient_Configure(t *testing.T) {
+	client := newFTPSourceClient()
+	assert.NoError(t, client.Configure(map[string]string{username: "foo", password: "bar", timeout: "10s"}))
+	assert.Equal(t, &ftpOptions{username: "foo", password: "bar", timeout: 10 * time.Second}, client.options.Load())
+	assert.Error(t, client.Configure(map[string]string{timeout: "foo"}))
+	assert.Error(t, client.Configure(map[string]string{"foo": "bar"}))
+}
@@ -0,0 +1,363 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gitlfsprotocol
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-http-utils/headers"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	// GitLFSClient is the scheme of git lfs objects, the url is like
+	// git-lfs://github.com/owner/repo.git?oid=<sha256>&size=<size>,
+	// the lfs server is https://github.com/owner/repo.git/info/lfs.
+	GitLFSClient = "git-lfs"
+)
+
+const (
+	// username and password are the basic auth of lfs server
+	username = "username"
+	password = "password"
+	// insecure uses http instead of https to access lfs server
+	insecure = "insecure"
+	// timeout is the timeout of batch requests
+	timeout = "timeout"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+
+	// mediaType is the media type of lfs batch api
+	mediaType = "application/vnd.git-lfs+json"
+
+	oidQuery  = "oid"
+	sizeQuery = "size"
+)
+
+func init() {
+	if err := source.Register(GitLFSClient, NewGitLFSSourceClient(), adapter); err != nil {
+		panic(err)
+	}
+}
+
+func adapter(request *source.Request) *source.Request {
+	clonedRequest := request.Clone(request.Context())
+	if request.Header.Get(source.Range) != "" {
+		clonedRequest.Header.Set(headers.Range, fmt.Sprintf("bytes=%s", request.Header.Get(source.Range)))
+		clonedRequest.Header.Del(source.Range)
+	}
+	return clonedRequest
+}
+
+// gitLFSOptions is the options of git lfs source client from config.
+type gitLFSOptions struct {
+	username string
+	password string
+	insecure bool
+	timeout  time.Duration
+}
+
+// gitLFSSourceClient is an implementation of the interface of source.ResourceClient,
+// objects are located by lfs batch api and downloaded from the returned href.
+// The objects are addressed by content, so they never expire.
+type gitLFSSourceClient struct {
+	httpClient *http.Client
+	options    atomic.Value // *gitLFSOptions
+}
+
+type GitLFSSourceClientOption func(p *gitLFSSourceClient)
+
+// WithHTTPClient sets the http client of batch requests and downloading.
+func WithHTTPClient(client *http.Client) GitLFSSourceClientOption {
+	return func(p *gitLFSSourceClient) {
+		p.httpClient = client
+	}
+}
+
+func NewGitLFSSourceClient(opts ...GitLFSSourceClientOption) source.ResourceClient {
+	return newGitLFSSourceClient(opts...)
+}
+
+func newGitLFSSourceClient(opts ...GitLFSSourceClientOption) *gitLFSSourceClient {
+	sourceClient := &gitLFSSourceClient{
+		httpClient: http.DefaultClient,
+	}
+	sourceClient.options.Store(&gitLFSOptions{timeout: defaultTimeout})
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+var (
+	_ source.ResourceClient = (*gitLFSSourceClient)(nil)
+	_ source.Configurer     = (*gitLFSSourceClient)(nil)
+)
+
+// Configure sets the basic auth, scheme and timeout of lfs server.
+func (g *gitLFSSourceClient) Configure(options map[string]string) error {
+	opts := &gitLFSOptions{timeout: defaultTimeout}
+	for key, value := range options {
+		switch key {
+		case username:
+			opts.username = value
+		case password:
+			opts.password = value
+		case insecure:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("parse git lfs source client option %s: %w", key, err)
+			}
+			opts.insecure = b
+		case timeout:
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("parse git lfs source client option %s: %w", key, err)
+			}
+			opts.timeout = d
+		default:
+			return fmt.Errorf("unknown git lfs source client option %s", key)
+		}
+	}
+
+	g.options.Store(opts)
+	return nil
+}
+
+func (g *gitLFSSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	obj, err := parseObject(request.URL)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+
+	// the object is checked in lfs server, the size is same with the pointer
+	if _, err := g.locate(request, obj); err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+	return obj.Size, nil
+}
+
+func (g *gitLFSSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if request.Header.Get(headers.Range) == "" {
+		request.Header.Set(headers.Range, "bytes=0-0")
+	}
+
+	resp, err := g.download(request)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusPartialContent, nil
+}
+
+func (g *gitLFSSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	return false, nil
+}
+
+func (g *gitLFSSourceClient) Download(request *source.Request) (*source.Response, error) {
+	resp, err := g.download(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK, http.StatusPartialContent}); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		resp.Body,
+		source.WithStatus(resp.StatusCode, resp.Status),
+		source.WithContentLength(resp.ContentLength),
+	), nil
+}
+
+func (g *gitLFSSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	return -1, nil
+}
+
+// download gets the object from the href returned by lfs server.
+func (g *gitLFSSourceClient) download(request *source.Request) (*http.Response, error) {
+	obj, err := parseObject(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := g.locate(request, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), http.MethodGet, action.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range action.Header {
+		req.Header.Set(key, value)
+	}
+	if rg := request.Header.Get(headers.Range); rg != "" {
+		req.Header.Set(headers.Range, rg)
+	}
+
+	return g.httpClient.Do(req)
+}
+
+// lfsObject is the object in lfs batch api.
+type lfsObject struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+// lfsAction is the action to download object.
+type lfsAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type lfsBatchRequest struct {
+	Operation string      `json:"operation"`
+	Transfers []string    `json:"transfers"`
+	Objects   []lfsObject `json:"objects"`
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		lfsObject
+		Actions struct {
+			Download *lfsAction `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+	Message string `json:"message"`
+}
+
+// locate requests lfs batch api for the download action of object.
+func (g *gitLFSSourceClient) locate(request *source.Request, obj *lfsObject) (*lfsAction, error) {
+	opts := g.options.Load().(*gitLFSOptions)
+	body, err := json.Marshal(&lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsObject{*obj},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), opts.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, batchURL(request.URL, opts.insecure), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(headers.Accept, mediaType)
+	req.Header.Set(headers.ContentType, mediaType)
+	// the basic auth in url takes precedence over options
+	if request.URL.User == nil && (opts.username != "" || opts.password != "") {
+		req.SetBasicAuth(opts.username, opts.password)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decode lfs batch response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lfs batch request failed with status %s: %s", resp.Status, batch.Message)
+	}
+
+	for _, o := range batch.Objects {
+		if o.Oid != obj.Oid {
+			continue
+		}
+
+		if o.Error != nil {
+			if o.Error.Code == http.StatusNotFound {
+				return nil, source.ErrResourceNotReachable
+			}
+			return nil, fmt.Errorf("lfs object %s error %d: %s", obj.Oid, o.Error.Code, o.Error.Message)
+		}
+
+		if o.Actions.Download == nil {
+			return nil, fmt.Errorf("lfs object %s has no download action", obj.Oid)
+		}
+		return o.Actions.Download, nil
+	}
+
+	return nil, fmt.Errorf("lfs object %s is not in batch response", obj.Oid)
+}
+
+// parseObject parses the oid and size of object in url.
+func parseObject(u *url.URL) (*lfsObject, error) {
+	query := u.Query()
+	oid := query.Get(oidQuery)
+	if b, err := hex.DecodeString(oid); err != nil || len(b) != 32 {
+		return nil, fmt.Errorf("invalid lfs object oid %q, it must be sha256 in hex", oid)
+	}
+
+	size, err := strconv.ParseInt(query.Get(sizeQuery), 10, 64)
+	if err != nil || size < 0 {
+		return nil, errors.New("invalid lfs object size")
+	}
+
+	return &lfsObject{Oid: oid, Size: size}, nil
+}
+
+// batchURL returns the batch api url of lfs server, see
+// https://github.com/git-lfs/git-lfs/blob/main/docs/api/server-discovery.md.
+func batchURL(u *url.URL, insecure bool) string {
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	if !strings.HasSuffix(path, ".git") {
+		path += ".git"
+	}
+
+	return (&url.URL{
+		Scheme: scheme,
+		User:   u.User,
+		Host:   u.Host,
+		Path:   path + "/info/lfs/objects/batch",
+	}).String()
+}
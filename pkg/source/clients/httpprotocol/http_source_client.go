@@ -17,13 +17,9 @@
 package httpprotocol
 
 import (
-	"crypto/tls"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
-	"net/url"
-	"os"
 	"regexp"
 	"strconv"
 	"time"
@@ -43,6 +39,7 @@ const (
 var (
 	_defaultHTTPClient *http.Client
 	_                  source.ResourceClient = (*httpSourceClient)(nil)
+	_                  source.Configurer     = (*httpSourceClient)(nil)
 
 	// Syntax:
 	//   Content-Range: <unit> <range-start>-<range-end>/<size> -> Done
@@ -55,34 +52,14 @@ var (
 )
 
 func init() {
-	// TODO support customize source client
-	var (
-		proxy *url.URL
-		err   error
-	)
-	if proxyEnv := os.Getenv(ProxyEnv); len(proxyEnv) > 0 {
-		proxy, err = url.Parse(proxyEnv)
-		if err != nil {
-			fmt.Printf("Back source proxy parse error: %s\n", err)
-		}
-	}
-
-	transport := &http.Transport{
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-			DualStack: true,
-		}).DialContext,
-		IdleConnTimeout:       90 * time.Second,
-		ResponseHeaderTimeout: 5 * time.Second,
-		ExpectContinueTimeout: 2 * time.Second,
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: true,
-		},
+	opts, err := defaultTransportOptions()
+	if err != nil {
+		fmt.Printf("Back source proxy parse error: %s\n", err)
 	}
 
-	if proxy != nil {
-		transport.Proxy = http.ProxyURL(proxy)
+	transport, err := newTransport(opts)
+	if err != nil {
+		panic(err)
 	}
 
 	_defaultHTTPClient = &http.Client{
@@ -144,6 +121,25 @@ func WithHTTPClient(client *http.Client) HTTPSourceClientOption {
 	}
 }
 
+// Configure replaces the http client with the transport built from options,
+// http and https share the same client, so options of either scheme are applied to both.
+func (client *httpSourceClient) Configure(options map[string]string) error {
+	opts, err := parseTransportOptions(options)
+	if err != nil {
+		return err
+	}
+
+	transport, err := newTransport(opts)
+	if err != nil {
+		return fmt.Errorf("new http source client transport: %w", err)
+	}
+
+	client.httpClient = &http.Client{
+		Transport: transport,
+	}
+	return nil
+}
+
 func (client *httpSourceClient) GetContentLength(request *source.Request) (int64, error) {
 	resp, err := client.doRequest(http.MethodGet, request)
 	if err != nil {
@@ -208,6 +204,12 @@ func (client *httpSourceClient) GetMetadata(request *source.Request) (*source.Me
 	for k, v := range exportPassThroughHeader(resp.Header) {
 		hdr.Set(k, v)
 	}
+	if lastModified := resp.Header.Get(headers.LastModified); lastModified != "" {
+		hdr.Set(source.LastModified, lastModified)
+	}
+	if etag := resp.Header.Get(headers.ETag); etag != "" {
+		hdr.Set(source.ETag, etag)
+	}
 	return &source.Metadata{
 		Header:             hdr,
 		Status:             resp.Status,
@@ -245,8 +247,22 @@ func (client *httpSourceClient) IsExpired(request *source.Request, info *source.
 		return false, err
 	}
 	defer resp.Body.Close()
-	return !(resp.StatusCode == http.StatusNotModified || (resp.Header.Get(headers.ETag) == info.ETag || resp.Header.Get(headers.LastModified) == info.
-		LastModified)), nil
+
+	if resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if info == nil {
+		return true, nil
+	}
+
+	// Empty validators never match, ETag is the stronger validator.
+	if info.ETag != "" {
+		return resp.Header.Get(headers.ETag) != info.ETag, nil
+	}
+	if info.LastModified != "" {
+		return resp.Header.Get(headers.LastModified) != info.LastModified, nil
+	}
+	return true, nil
 }
 
 func (client *httpSourceClient) Download(request *source.Request) (*source.Response, error) {
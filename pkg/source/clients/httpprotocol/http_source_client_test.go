@@ -267,6 +267,15 @@ func (suite *HTTPSourceClientTestSuite) TestHttpSourceClientIsExpired() {
 			LastModified: expireLastModified,
 			ETag:         expireEtag,
 		}, want: true, wantErr: false},
+		{name: "expired with etag mismatch", request: expireRequest, expireInfo: &source.ExpireInfo{
+			LastModified: lastModified,
+			ETag:         expireEtag,
+		}, want: true, wantErr: false},
+		{name: "not expire with last modified", request: expireRequest, expireInfo: &source.ExpireInfo{
+			LastModified: lastModified,
+		}, want: false, wantErr: false},
+		{name: "expired without validators", request: expireRequest, expireInfo: &source.ExpireInfo{},
+			want: true, wantErr: false},
 	}
 	for _, tt := range tests {
 		suite.Run(tt.name, func() {
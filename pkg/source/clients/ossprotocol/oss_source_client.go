@@ -26,6 +26,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aliyun/aliyun-oss-go-sdk/oss"
@@ -91,6 +92,40 @@ type ossSourceClient struct {
 	// endpoint_accessKeyID_accessKeySecret -> ossClient
 	clientMap sync.Map
 	accessMap sync.Map
+
+	// defaults are the endpoint and credential from config,
+	// they are used when the request header does not carry them
+	defaults atomic.Value // map[string]string
+}
+
+var _ source.Configurer = (*ossSourceClient)(nil)
+
+// Configure sets the default endpoint, accessKeyID and accessKeySecret.
+func (osc *ossSourceClient) Configure(options map[string]string) error {
+	defaults := map[string]string{}
+	for key, value := range options {
+		switch key {
+		case endpoint, accessKeyID, accessKeySecret:
+			defaults[key] = value
+		default:
+			return fmt.Errorf("unknown oss source client option %s", key)
+		}
+	}
+
+	osc.defaults.Store(defaults)
+	return nil
+}
+
+// getOption returns the value in request header, or the default value in config.
+func (osc *ossSourceClient) getOption(header source.Header, key string) string {
+	if value := header.Get(key); !pkgstrings.IsBlank(value) {
+		return value
+	}
+
+	if defaults, ok := osc.defaults.Load().(map[string]string); ok {
+		return defaults[key]
+	}
+	return ""
 }
 
 func (osc *ossSourceClient) GetContentLength(request *source.Request) (int64, error) {
@@ -272,15 +307,15 @@ func (osc *ossSourceClient) isDirectory(bucket *oss.Bucket, request *source.Requ
 }
 
 func (osc *ossSourceClient) getClient(header source.Header) (*oss.Client, error) {
-	endpoint := header.Get(endpoint)
+	endpoint := osc.getOption(header, endpoint)
 	if pkgstrings.IsBlank(endpoint) {
 		return nil, errors.New("endpoint is empty")
 	}
-	accessKeyID := header.Get(accessKeyID)
+	accessKeyID := osc.getOption(header, accessKeyID)
 	if pkgstrings.IsBlank(accessKeyID) {
 		return nil, errors.New("accessKeyID is empty")
 	}
-	accessKeySecret := header.Get(accessKeySecret)
+	accessKeySecret := osc.getOption(header, accessKeySecret)
 	if pkgstrings.IsBlank(accessKeySecret) {
 		return nil, errors.New("accessKeySecret is empty")
 	}
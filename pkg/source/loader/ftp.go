@@ -1,8 +1,5 @@
-//go:build !linux
-// +build !linux
-
 /*
- *     Copyright 2020 The Dragonfly Authors
+ *     Copyright 2022 The Dragonfly Authors
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
  * you may not use this file except in compliance with the License.
@@ -17,10 +14,8 @@
  * limitations under the License.
  */
 
-package daemon
+package loader
 
-func switchNetNamespace(target string) (func() error, error) {
-	return func() error {
-		return nil
-	}, nil
-}
+import (
+	_ "d7y.io/dragonfly/v2/pkg/source/clients/ftpprotocol" // Register ftp client
+)
@@ -33,3 +33,10 @@ type Metadata struct {
 	Validate  func() error
 	Temporary func() bool
 }
+
+func (m *Metadata) ExpireInfo() ExpireInfo {
+	return ExpireInfo{
+		LastModified: m.Header.Get(LastModified),
+		ETag:         m.Header.Get(ETag),
+	}
+}
@@ -208,6 +208,20 @@ func (m *MockClientManager) EXPECT() *MockClientManagerMockRecorder {
 	return m.recorder
 }
 
+// Configure mocks base method.
+func (m *MockClientManager) Configure(scheme string, options map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Configure", scheme, options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Configure indicates an expected call of Configure.
+func (mr *MockClientManagerMockRecorder) Configure(scheme, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configure", reflect.TypeOf((*MockClientManager)(nil).Configure), scheme, options)
+}
+
 // GetClient mocks base method.
 func (m *MockClientManager) GetClient(scheme string, options ...source.Option) (source.ResourceClient, bool) {
 	m.ctrl.T.Helper()
@@ -273,6 +287,43 @@ func (mr *MockClientManagerMockRecorder) UnRegister(scheme interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnRegister", reflect.TypeOf((*MockClientManager)(nil).UnRegister), scheme)
 }
 
+// MockConfigurer is a mock of Configurer interface.
+type MockConfigurer struct {
+	ctrl     *gomock.Controller
+	recorder *MockConfigurerMockRecorder
+}
+
+// MockConfigurerMockRecorder is the mock recorder for MockConfigurer.
+type MockConfigurerMockRecorder struct {
+	mock *MockConfigurer
+}
+
+// NewMockConfigurer creates a new mock instance.
+func NewMockConfigurer(ctrl *gomock.Controller) *MockConfigurer {
+	mock := &MockConfigurer{ctrl: ctrl}
+	mock.recorder = &MockConfigurerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConfigurer) EXPECT() *MockConfigurerMockRecorder {
+	return m.recorder
+}
+
+// Configure mocks base method.
+func (m *MockConfigurer) Configure(options map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Configure", options)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Configure indicates an expected call of Configure.
+func (mr *MockConfigurerMockRecorder) Configure(options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Configure", reflect.TypeOf((*MockConfigurer)(nil).Configure), options)
+}
+
 // MockHook is a mock of Hook interface.
 type MockHook struct {
 	ctrl     *gomock.Controller
@@ -28,9 +28,13 @@ const (
 )
 
 func LoadPlugin(dir, scheme string) (ResourceClient, error) {
-	// TODO init option
+	return loadPlugin(dir, scheme, map[string]string{})
+}
+
+// loadPlugin loads the source plugin of scheme, options are passed to the plugin when it is initialized.
+func loadPlugin(dir, scheme string, options map[string]string) (ResourceClient, error) {
 	logger.Debugf("try to load source plugin: %s", scheme)
-	client, meta, err := dfplugin.Load(dir, dfplugin.PluginTypeResource, scheme, map[string]string{})
+	client, meta, err := dfplugin.Load(dir, dfplugin.PluginTypeResource, scheme, options)
 	if err != nil {
 		logger.Errorf("load source plugin error: %s", err)
 		return nil, err
@@ -0,0 +1,75 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// RequestSigner signs the back source request for protected origins,
+// so credentials are kept in the peer which downloads from source
+// and not passed in url meta by clients.
+type RequestSigner interface {
+	// Sign adds authentication info to the request.
+	Sign(request *Request) error
+}
+
+// signerRule signs the requests whose url matches regx.
+type signerRule struct {
+	regx   *regexp.Regexp
+	signer RequestSigner
+}
+
+var (
+	signersMu sync.RWMutex
+	signers   []signerRule
+)
+
+// RegisterSigner registers signer for the requests whose url matches regx,
+// the first matched signer is used when multiple rules match.
+func RegisterSigner(regx *regexp.Regexp, signer RequestSigner) {
+	signersMu.Lock()
+	defer signersMu.Unlock()
+	signers = append(signers, signerRule{regx: regx, signer: signer})
+}
+
+// UnRegisterSigners revokes all signers.
+func UnRegisterSigners() {
+	signersMu.Lock()
+	defer signersMu.Unlock()
+	signers = nil
+}
+
+// signRequest signs request with the matched signer.
+func signRequest(request *Request) error {
+	signersMu.RLock()
+	defer signersMu.RUnlock()
+
+	url := request.URL.String()
+	for _, rule := range signers {
+		if rule.regx.MatchString(url) {
+			if err := rule.signer.Sign(request); err != nil {
+				return fmt.Errorf("sign request: %w", err)
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
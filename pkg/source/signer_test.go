@@ -0,0 +1,115 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package source
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signFunc func(request *Request) error
+
+func (f signFunc) Sign(request *Request) error {
+	return f(request)
+}
+
+func TestSigner_signRequest(t *testing.T) {
+	headerSigner := func(value string) RequestSigner {
+		return signFunc(func(request *Request) error {
+			request.Header.Set("Authorization", value)
+			return nil
+		})
+	}
+
+	tests := []struct {
+		name   string
+		url    string
+		rules  []signerRule
+		expect func(t *testing.T, request *Request, err error)
+	}{
+		{
+			name: "sign request with matched signer",
+			url:  "https://example.com/private/foo",
+			rules: []signerRule{
+				{regexp.MustCompile("public"), headerSigner("public")},
+				{regexp.MustCompile("private"), headerSigner("private")},
+			},
+			expect: func(t *testing.T, request *Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("private", request.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "use the first matched signer",
+			url:  "https://example.com/private/foo",
+			rules: []signerRule{
+				{regexp.MustCompile("example.com"), headerSigner("foo")},
+				{regexp.MustCompile("private"), headerSigner("bar")},
+			},
+			expect: func(t *testing.T, request *Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo", request.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "signer not found",
+			url:  "https://example.com/foo",
+			rules: []signerRule{
+				{regexp.MustCompile("private"), headerSigner("private")},
+			},
+			expect: func(t *testing.T, request *Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(request.Header.Get("Authorization"))
+			},
+		},
+		{
+			name: "sign request failed",
+			url:  "https://example.com/private/foo",
+			rules: []signerRule{
+				{regexp.MustCompile("private"), signFunc(func(request *Request) error {
+					return errors.New("foo")
+				})},
+			},
+			expect: func(t *testing.T, request *Request, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "sign request: foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer UnRegisterSigners()
+			for _, rule := range tc.rules {
+				RegisterSigner(rule.regx, rule.signer)
+			}
+
+			request, err := NewRequest(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, request, signRequest(request))
+		})
+	}
+}
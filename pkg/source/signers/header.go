@@ -0,0 +1,42 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signers
+
+import (
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+// headerSigner sets a static header, such as the api key of artifactory
+// or the bearer token of private registry.
+type headerSigner struct {
+	key   string
+	value string
+}
+
+// NewHeaderSigner returns a signer which sets header key to value.
+func NewHeaderSigner(key, value string) source.RequestSigner {
+	return &headerSigner{
+		key:   key,
+		value: value,
+	}
+}
+
+// Sign sets the header of request.
+func (h *headerSigner) Sign(request *source.Request) error {
+	request.Header.Set(h.key, h.value)
+	return nil
+}
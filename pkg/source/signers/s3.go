@@ -0,0 +1,100 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	// s3Service is the service name in signature.
+	s3Service = "s3"
+
+	// defaultS3Region is used when region is not configured.
+	defaultS3Region = "us-east-1"
+)
+
+// signedS3Headers are the headers generated by signature version 4.
+var signedS3Headers = []string{"Authorization", "X-Amz-Date", "X-Amz-Content-Sha256", "X-Amz-Security-Token"}
+
+// Credential is the access key of s3 compatible object storage.
+type Credential struct {
+	AccessKey string
+	SecretKey string
+	Region    string
+}
+
+// CredentialFunc returns the latest credential,
+// it is called on every request so credentials can be rotated.
+type CredentialFunc func() (*Credential, error)
+
+// s3Signer signs request with aws signature version 4, it also works
+// with gcs in interoperability mode and other s3 compatible storages.
+type s3Signer struct {
+	region     string
+	credential CredentialFunc
+}
+
+// NewS3Signer returns a signer of aws signature version 4,
+// region of credential is used when region is empty.
+func NewS3Signer(region string, credential CredentialFunc) source.RequestSigner {
+	return &s3Signer{
+		region:     region,
+		credential: credential,
+	}
+}
+
+// Sign adds the signature headers to request.
+func (s *s3Signer) Sign(request *source.Request) error {
+	credential, err := s.credential()
+	if err != nil {
+		return err
+	}
+
+	region := s.region
+	if region == "" {
+		region = credential.Region
+	}
+
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), http.MethodGet, request.URL.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	signer := v4.NewSigner(credentials.NewStaticCredentials(credential.AccessKey, credential.SecretKey, ""))
+	if _, err := signer.Sign(req, nil, s3Service, region, time.Now()); err != nil {
+		return err
+	}
+
+	for _, key := range signedS3Headers {
+		if value := req.Header.Get(key); value != "" {
+			request.Header.Set(key, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+func TestHeaderSigner_Sign(t *testing.T) {
+	assert := assert.New(t)
+	request, err := source.NewRequest("https://example.com/foo")
+	assert.NoError(err)
+
+	assert.NoError(NewHeaderSigner("X-JFrog-Art-Api", "foo").Sign(request))
+	assert.Equal("foo", request.Header.Get("X-JFrog-Art-Api"))
+}
+
+func TestS3Signer_Sign(t *testing.T) {
+	tests := []struct {
+		name       string
+		region     string
+		credential CredentialFunc
+		expect     func(t *testing.T, request *source.Request, err error)
+	}{
+		{
+			name: "sign with region of credential",
+			credential: func() (*Credential, error) {
+				return &Credential{AccessKey: "foo", SecretKey: "bar", Region: "cn-north-1"}, nil
+			},
+			expect: func(t *testing.T, request *source.Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				authorization := request.Header.Get("Authorization")
+				assert.True(strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=foo/"))
+				assert.Contains(authorization, "/cn-north-1/s3/aws4_request")
+				assert.NotEmpty(request.Header.Get("X-Amz-Date"))
+				assert.NotEmpty(request.Header.Get("X-Amz-Content-Sha256"))
+			},
+		},
+		{
+			name:   "sign with configured region",
+			region: "us-west-2",
+			credential: func() (*Credential, error) {
+				return &Credential{AccessKey: "foo", SecretKey: "bar", Region: "cn-north-1"}, nil
+			},
+			expect: func(t *testing.T, request *source.Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Contains(request.Header.Get("Authorization"), "/us-west-2/s3/aws4_request")
+			},
+		},
+		{
+			name: "sign with default region",
+			credential: func() (*Credential, error) {
+				return &Credential{AccessKey: "foo", SecretKey: "bar"}, nil
+			},
+			expect: func(t *testing.T, request *source.Request, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Contains(request.Header.Get("Authorization"), "/us-east-1/s3/aws4_request")
+			},
+		},
+		{
+			name: "get credential failed",
+			credential: func() (*Credential, error) {
+				return nil, errors.New("foo")
+			},
+			expect: func(t *testing.T, request *source.Request, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+				assert.Empty(request.Header.Get("Authorization"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := source.NewRequest("https://bucket.s3.amazonaws.com/foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, request, NewS3Signer(tc.region, tc.credential).Sign(request))
+		})
+	}
+}
@@ -159,12 +159,23 @@ type ClientManager interface {
 
 	// ListClients lists all supported client scheme
 	ListClients() []string
+
+	// Configure applies options to the source client of scheme,
+	// the options of unregistered scheme are passed to the plugin when it is loaded
+	Configure(scheme string, options map[string]string) error
+}
+
+// Configurer is implemented by the source clients which accept options from config.
+type Configurer interface {
+	// Configure applies options to source client, it is called before the client is used
+	Configure(options map[string]string) error
 }
 
 // clientManager implements the interface ClientManager
 type clientManager struct {
 	mu        sync.RWMutex
 	clients   map[string]ResourceClient
+	options   map[string]map[string]string
 	pluginDir string
 }
 
@@ -175,6 +186,7 @@ var _defaultManager = NewManager()
 func NewManager() ClientManager {
 	return &clientManager{
 		clients: make(map[string]ResourceClient),
+		options: make(map[string]map[string]string),
 	}
 }
 
@@ -227,6 +239,30 @@ func (m *clientManager) ListClients() []string {
 	return clients
 }
 
+func (m *clientManager) Configure(scheme string, options map[string]string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	scheme = strings.ToLower(scheme)
+	m.options[scheme] = options
+
+	client, ok := m.clients[scheme]
+	if !ok {
+		// not registered yet, options are used when plugin is loaded
+		return nil
+	}
+
+	if wrapper, ok := client.(*clientWrapper); ok {
+		client = wrapper.rc
+	}
+
+	configurer, ok := client.(Configurer)
+	if !ok {
+		return fmt.Errorf("client with scheme %s does not support options", scheme)
+	}
+
+	return configurer.Configure(options)
+}
+
 func (m *clientManager) GetClient(scheme string, options ...Option) (ResourceClient, bool) {
 	logger.Debugf("current clients: %#v", m.clients)
 	m.mu.RLock()
@@ -248,7 +284,12 @@ func (m *clientManager) GetClient(scheme string, options ...Option) (ResourceCli
 		opt(m)
 	}
 
-	client, err := LoadPlugin(m.pluginDir, scheme)
+	pluginOptions := m.options[scheme]
+	if pluginOptions == nil {
+		pluginOptions = map[string]string{}
+	}
+
+	client, err := loadPlugin(m.pluginDir, scheme, pluginOptions)
 	if err != nil {
 		logger.Errorf("failed to load source plugin for scheme %s: %v", scheme, err)
 		m.mu.Unlock()
@@ -271,6 +312,10 @@ func ListClients() []string {
 	return _defaultManager.ListClients()
 }
 
+func Configure(scheme string, options map[string]string) error {
+	return _defaultManager.Configure(scheme, options)
+}
+
 type RequestAdapter func(request *Request) *Request
 
 // Hook TODO hook
@@ -309,6 +354,9 @@ func GetContentLength(request *Request) (int64, error) {
 	if !ok {
 		return UnknownSourceFileLen, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return UnknownSourceFileLen, err
+	}
 	if _, ok := request.Context().Deadline(); !ok {
 		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
 		request = request.WithContext(ctx)
@@ -322,6 +370,9 @@ func IsSupportRange(request *Request) (bool, error) {
 	if !ok {
 		return false, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return false, err
+	}
 	if _, ok := request.Context().Deadline(); !ok {
 		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
 		request = request.WithContext(ctx)
@@ -338,6 +389,9 @@ func IsExpired(request *Request, info *ExpireInfo) (bool, error) {
 	if !ok {
 		return false, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return false, err
+	}
 	if _, ok := request.Context().Deadline(); !ok {
 		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
 		request = request.WithContext(ctx)
@@ -351,6 +405,9 @@ func GetLastModified(request *Request) (int64, error) {
 	if !ok {
 		return -1, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return -1, err
+	}
 	if _, ok := request.Context().Deadline(); !ok {
 		ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
 		request = request.WithContext(ctx)
@@ -364,6 +421,9 @@ func Download(request *Request) (*Response, error) {
 	if !ok {
 		return nil, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return nil, err
+	}
 	return client.Download(request)
 }
 
@@ -372,6 +432,9 @@ func List(request *Request) ([]URLEntry, error) {
 	if !ok {
 		return nil, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return nil, err
+	}
 	if wrap, ok := client.(*clientWrapper); ok {
 		if rc, ok := wrap.rc.(ResourceLister); ok {
 			return rc.List(wrap.adapter(request))
@@ -389,6 +452,9 @@ func GetMetadata(request *Request) (*Metadata, error) {
 	if !ok {
 		return nil, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrNoClientFound)
 	}
+	if err := signRequest(request); err != nil {
+		return nil, err
+	}
 	getter, ok := client.(*clientWrapper).rc.(ResourceMetadataGetter)
 	if !ok {
 		return nil, fmt.Errorf("scheme %s: %w", request.URL.Scheme, ErrClientNotSupportGetMetadata)
@@ -0,0 +1,57 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package source
+
+import (
+	"errors"
+	"testing"
+
+	testifyassert "github.com/stretchr/testify/assert"
+)
+
+type configurableClient struct {
+	ResourceClient
+	options map[string]string
+}
+
+func (c *configurableClient) Configure(options map[string]string) error {
+	if _, ok := options["invalid"]; ok {
+		return errors.New("invalid option")
+	}
+	c.options = options
+	return nil
+}
+
+func Test_clientManager_Configure(t *testing.T) {
+	assert := testifyassert.New(t)
+	m := NewManager().(*clientManager)
+
+	configurable := &configurableClient{}
+	assert.Nil(m.Register("foo", configurable, nil))
+	assert.Nil(m.Register("bar", &configurableClient{}, nil))
+	// clients registered without adapter are also supported
+	m.doRegister("baz", struct{ ResourceClient }{})
+
+	assert.Nil(m.Configure("FOO", map[string]string{"key": "value"}))
+	assert.Equal(map[string]string{"key": "value"}, configurable.options)
+	assert.NotNil(m.Configure("bar", map[string]string{"invalid": ""}))
+	assert.NotNil(m.Configure("baz", map[string]string{"key": "value"}))
+
+	// options of unregistered scheme are kept for plugin
+	assert.Nil(m.Configure("qux", map[string]string{"key": "value"}))
+	assert.Equal(map[string]string{"key": "value"}, m.options["qux"])
+}
@@ -18,9 +18,11 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"d7y.io/dragonfly/v2/cmd/dependency/base"
+	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/net/fqdn"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/scheduler/storage"
@@ -54,18 +56,25 @@ type Config struct {
 	// Storage configuration.
 	Storage *StorageConfig `yaml:"storage" mapstructure:"storage"`
 
+	// Persistence configuration.
+	Persistence *PersistenceConfig `yaml:"persistence" mapstructure:"persistence"`
+
 	// Metrics configuration.
 	Metrics *MetricsConfig `yaml:"metrics" mapstructure:"metrics"`
+
+	// Debug configuration.
+	Debug *DebugConfig `yaml:"debug" mapstructure:"debug"`
 }
 
 // New default configuration.
 func New() *Config {
 	return &Config{
 		Server: &ServerConfig{
-			IP:     ip.IPv4,
-			Host:   fqdn.FQDNHostname,
-			Listen: DefaultServerListen,
-			Port:   DefaultServerPort,
+			IP:           ip.IPv4,
+			Host:         fqdn.FQDNHostname,
+			Listen:       DefaultServerListen,
+			Port:         DefaultServerPort,
+			DrainTimeout: DefaultServerDrainTimeout,
 		},
 		Scheduler: &SchedulerConfig{
 			Algorithm:            DefaultSchedulerAlgorithm,
@@ -73,6 +82,7 @@ func New() *Config {
 			RetryBackSourceLimit: DefaultSchedulerRetryBackSourceLimit,
 			RetryLimit:           DefaultSchedulerRetryLimit,
 			RetryInterval:        DefaultSchedulerRetryInterval,
+			ETAInterval:          DefaultSchedulerETAInterval,
 			GC: &GCConfig{
 				PeerGCInterval: DefaultSchedulerPeerGCInterval,
 				PeerTTL:        DefaultSchedulerPeerTTL,
@@ -87,9 +97,42 @@ func New() *Config {
 				RefreshModelInterval: DefaultRefreshModelInterval,
 				CPU:                  DefaultCPU,
 			},
+			GRPCEvaluator: &GRPCEvaluatorConfig{
+				Timeout:          DefaultSchedulerGRPCEvaluatorTimeout,
+				CacheTTL:         DefaultSchedulerGRPCEvaluatorCacheTTL,
+				FailureThreshold: DefaultSchedulerGRPCEvaluatorFailureThreshold,
+				Cooldown:         DefaultSchedulerGRPCEvaluatorCooldown,
+			},
+			MLEvaluator: &MLEvaluatorConfig{
+				ReloadInterval: DefaultSchedulerMLEvaluatorReloadInterval,
+			},
+			SuperNode: &SuperNodeConfig{
+				Enable:             false,
+				PeerCountThreshold: DefaultSchedulerSuperNodePeerCountThreshold,
+				Limit:              DefaultSchedulerSuperNodeLimit,
+				MaxDepth:           DefaultSchedulerSuperNodeMaxDepth,
+			},
+			AntiAffinity: &AntiAffinityConfig{
+				Enable:     false,
+				MinParents: DefaultSchedulerAntiAffinityMinParents,
+			},
+			ParentAdjustment: &ParentAdjustmentConfig{
+				Enable:          false,
+				DegradedWindows: DefaultSchedulerParentAdjustmentDegradedWindows,
+				Cooldown:        DefaultSchedulerParentAdjustmentCooldown,
+			},
+			Admission: &AdmissionConfig{
+				Enable:           false,
+				IPRate:           DefaultSchedulerAdmissionIPRate,
+				IPBurst:          DefaultSchedulerAdmissionIPBurst,
+				ApplicationRate:  DefaultSchedulerAdmissionApplicationRate,
+				ApplicationBurst: DefaultSchedulerAdmissionApplicationBurst,
+				BucketTTL:        DefaultSchedulerAdmissionBucketTTL,
+			},
 		},
 		DynConfig: &DynConfig{
-			RefreshInterval: DefaultDynConfigRefreshInterval,
+			RefreshInterval:       DefaultDynConfigRefreshInterval,
+			SeedPeerWatchInterval: DefaultDynConfigSeedPeerWatchInterval,
 		},
 		Host: &HostConfig{},
 		Manager: &ManagerConfig{
@@ -100,6 +143,12 @@ func New() *Config {
 		},
 		SeedPeer: &SeedPeerConfig{
 			Enable: true,
+			HealthProbe: SeedPeerHealthProbeConfig{
+				Enable:           false,
+				Interval:         DefaultSeedPeerHealthProbeInterval,
+				Timeout:          DefaultSeedPeerHealthProbeTimeout,
+				FailureThreshold: DefaultSeedPeerHealthProbeFailureThreshold,
+			},
 		},
 		Job: &JobConfig{
 			Enable:             true,
@@ -121,6 +170,15 @@ func New() *Config {
 			Enable:         false,
 			EnablePeerHost: false,
 		},
+		Debug: &DebugConfig{
+			Enable: false,
+		},
+		Persistence: &PersistenceConfig{
+			Enable:   false,
+			Interval: DefaultPersistenceInterval,
+			TTL:      DefaultPersistenceTTL,
+			DB:       DefaultPersistenceRedisDB,
+		},
 	}
 }
 
@@ -146,6 +204,10 @@ func (cfg *Config) Validate() error {
 		return errors.New("server requires parameter listen")
 	}
 
+	if cfg.Server.DrainTimeout < 0 {
+		return errors.New("server requires parameter drainTimeout")
+	}
+
 	if cfg.Scheduler.Algorithm == "" {
 		return errors.New("scheduler requires parameter algorithm")
 	}
@@ -158,6 +220,10 @@ func (cfg *Config) Validate() error {
 		return errors.New("scheduler requires parameter retryInterval")
 	}
 
+	if cfg.Scheduler.ETAInterval <= 0 {
+		return errors.New("scheduler requires parameter etaInterval")
+	}
+
 	if cfg.Scheduler.GC == nil {
 		return errors.New("scheduler requires parameter gc")
 	}
@@ -188,10 +254,126 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Scheduler.Algorithm == GRPCEvaluatorAlgorithm {
+		if cfg.Scheduler.GRPCEvaluator == nil || cfg.Scheduler.GRPCEvaluator.Addr == "" {
+			return errors.New("grpcEvaluator requires parameter addr")
+		}
+
+		if cfg.Scheduler.GRPCEvaluator.Timeout <= 0 {
+			return errors.New("grpcEvaluator requires parameter timeout")
+		}
+
+		if cfg.Scheduler.GRPCEvaluator.FailureThreshold > 0 && cfg.Scheduler.GRPCEvaluator.Cooldown <= 0 {
+			return errors.New("grpcEvaluator requires parameter cooldown")
+		}
+	}
+
+	if cfg.Scheduler.Algorithm == MLEvaluatorAlgorithm {
+		if cfg.Scheduler.MLEvaluator == nil || cfg.Scheduler.MLEvaluator.ModelPath == "" {
+			return errors.New("mlEvaluator requires parameter modelPath")
+		}
+
+		if cfg.Scheduler.MLEvaluator.ReloadInterval < 0 {
+			return errors.New("mlEvaluator requires parameter reloadInterval")
+		}
+	}
+
+	if cfg.Scheduler.SuperNode != nil && cfg.Scheduler.SuperNode.Enable {
+		if cfg.Scheduler.SuperNode.PeerCountThreshold <= 0 {
+			return errors.New("superNode requires parameter peerCountThreshold")
+		}
+
+		if cfg.Scheduler.SuperNode.Limit <= 0 {
+			return errors.New("superNode requires parameter limit")
+		}
+
+		if cfg.Scheduler.SuperNode.MaxDepth <= 0 {
+			return errors.New("superNode requires parameter maxDepth")
+		}
+	}
+
+	if cfg.Scheduler.AntiAffinity != nil && cfg.Scheduler.AntiAffinity.Enable {
+		if cfg.Scheduler.AntiAffinity.MinParents <= 0 {
+			return errors.New("antiAffinity requires parameter minParents")
+		}
+	}
+
+	if cfg.Scheduler.ParentAdjustment != nil && cfg.Scheduler.ParentAdjustment.Enable {
+		if cfg.Scheduler.ParentAdjustment.DegradedWindows <= 0 {
+			return errors.New("parentAdjustment requires parameter degradedWindows")
+		}
+
+		if cfg.Scheduler.ParentAdjustment.Cooldown < 0 {
+			return errors.New("parentAdjustment requires parameter cooldown")
+		}
+	}
+
+	if cfg.Scheduler.Admission != nil && cfg.Scheduler.Admission.Enable {
+		if cfg.Scheduler.Admission.IPRate < 0 || (cfg.Scheduler.Admission.IPRate > 0 && cfg.Scheduler.Admission.IPBurst <= 0) {
+			return errors.New("admission requires parameter ipRate and ipBurst")
+		}
+
+		if cfg.Scheduler.Admission.ApplicationRate < 0 || (cfg.Scheduler.Admission.ApplicationRate > 0 && cfg.Scheduler.Admission.ApplicationBurst <= 0) {
+			return errors.New("admission requires parameter applicationRate and applicationBurst")
+		}
+
+		if cfg.Scheduler.Admission.BucketTTL <= 0 {
+			return errors.New("admission requires parameter bucketTTL")
+		}
+	}
+
+	taskClasses := map[string]struct{}{}
+	for _, class := range cfg.Scheduler.TaskClasses {
+		if class.Name == "" {
+			return errors.New("taskClasses requires parameter name")
+		}
+
+		if _, ok := taskClasses[class.Name]; ok {
+			return fmt.Errorf("taskClasses has duplicate name %s", class.Name)
+		}
+		taskClasses[class.Name] = struct{}{}
+
+		if class.FilterParentLimit < 0 {
+			return fmt.Errorf("task class %s requires parameter filterParentLimit", class.Name)
+		}
+
+		if class.BackSourceCount < 0 {
+			return fmt.Errorf("task class %s requires parameter backSourceCount", class.Name)
+		}
+	}
+
+	for name, ttl := range cfg.Scheduler.GC.TaskClassTTL {
+		if _, ok := taskClasses[name]; !ok {
+			return fmt.Errorf("taskClassTTL has unknown task class %s", name)
+		}
+
+		if ttl <= 0 {
+			return fmt.Errorf("taskClassTTL requires positive ttl of task class %s", name)
+		}
+	}
+
 	if cfg.DynConfig.RefreshInterval <= 0 {
 		return errors.New("dynconfig requires parameter refreshInterval")
 	}
 
+	if cfg.DynConfig.SeedPeerWatchInterval < 0 {
+		return errors.New("dynconfig requires parameter seedPeerWatchInterval")
+	}
+
+	if cfg.SeedPeer != nil && cfg.SeedPeer.HealthProbe.Enable {
+		if cfg.SeedPeer.HealthProbe.Interval <= 0 {
+			return errors.New("seedPeer healthProbe requires parameter interval")
+		}
+
+		if cfg.SeedPeer.HealthProbe.Timeout <= 0 {
+			return errors.New("seedPeer healthProbe requires parameter timeout")
+		}
+
+		if cfg.SeedPeer.HealthProbe.FailureThreshold <= 0 {
+			return errors.New("seedPeer healthProbe requires parameter failureThreshold")
+		}
+	}
+
 	if cfg.Manager.Addr == "" {
 		return errors.New("manager requires parameter addr")
 	}
@@ -204,6 +386,10 @@ func (cfg *Config) Validate() error {
 		return errors.New("manager requires parameter keepAlive interval")
 	}
 
+	if cfg.Manager.TLS != nil && (cfg.Manager.TLS.Cert == "") != (cfg.Manager.TLS.Key == "") {
+		return errors.New("manager tls requires parameter cert and key together")
+	}
+
 	if cfg.Job != nil && cfg.Job.Enable {
 		if cfg.Job.GlobalWorkerNum == 0 {
 			return errors.New("job requires parameter globalWorkerNum")
@@ -238,6 +424,24 @@ func (cfg *Config) Validate() error {
 		return errors.New("server requires parameter storage")
 	}
 
+	if cfg.Persistence != nil && cfg.Persistence.Enable {
+		if cfg.Job == nil || cfg.Job.Redis == nil || cfg.Job.Redis.Host == "" {
+			return errors.New("persistence requires parameter job redis host")
+		}
+
+		if cfg.Persistence.Interval <= 0 {
+			return errors.New("persistence requires parameter interval")
+		}
+
+		if cfg.Persistence.TTL < cfg.Persistence.Interval {
+			return errors.New("persistence requires parameter ttl greater than interval")
+		}
+
+		if cfg.Persistence.DB <= 0 {
+			return errors.New("persistence requires parameter db")
+		}
+	}
+
 	if cfg.Storage.MaxSize <= 0 {
 		return errors.New("storage requires parameter maxSize")
 	}
@@ -256,6 +460,12 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Debug != nil && cfg.Debug.Enable {
+		if cfg.Debug.Addr == "" {
+			return errors.New("debug requires parameter addr")
+		}
+	}
+
 	return nil
 }
 
@@ -283,6 +493,11 @@ type ServerConfig struct {
 
 	// Server storage data directory.
 	DataDir string `yaml:"dataDir" mapstructure:"dataDir"`
+
+	// DrainTimeout is the grace period of draining when scheduler receives the stop signal,
+	// new peers are rejected and existing streams are still served until they are done
+	// or the period is over.
+	DrainTimeout time.Duration `yaml:"drainTimeout" mapstructure:"drainTimeout"`
 }
 
 type SchedulerConfig struct {
@@ -301,11 +516,193 @@ type SchedulerConfig struct {
 	// Retry scheduling interval.
 	RetryInterval time.Duration `yaml:"retryInterval" mapstructure:"retryInterval"`
 
-	// Task and peer gc configuration.
+	// ETAInterval is the interval of pushing the estimated remaining time of downloading to peers.
+	ETAInterval time.Duration `yaml:"etaInterval" mapstructure:"etaInterval"`
+
+	// Task and peer gc configuration, intervals and time to live are
+	// overridden by the gc config of scheduler cluster in manager at runtime.
 	GC *GCConfig `yaml:"gc" mapstructure:"gc"`
 
 	// Training configuration.
 	Training *TrainingConfig `yaml:"training" mapstructure:"training"`
+
+	// GRPCEvaluator configuration, it is used when algorithm is grpc.
+	GRPCEvaluator *GRPCEvaluatorConfig `yaml:"grpcEvaluator" mapstructure:"grpcEvaluator"`
+
+	// MLEvaluator configuration, it is used when algorithm is ml.
+	MLEvaluator *MLEvaluatorConfig `yaml:"mlEvaluator" mapstructure:"mlEvaluator"`
+
+	// SuperNode configuration for large fan-out tasks.
+	SuperNode *SuperNodeConfig `yaml:"superNode" mapstructure:"superNode"`
+
+	// AntiAffinity configuration for spreading parents across failure domains.
+	AntiAffinity *AntiAffinityConfig `yaml:"antiAffinity" mapstructure:"antiAffinity"`
+
+	// ParentAdjustment configuration for switching parent when downloading from parent is degraded.
+	ParentAdjustment *ParentAdjustmentConfig `yaml:"parentAdjustment" mapstructure:"parentAdjustment"`
+
+	// TaskClasses classify tasks by url meta, tasks of a class are scheduled with its limits.
+	TaskClasses []*TaskClassConfig `yaml:"taskClasses" mapstructure:"taskClasses"`
+
+	// Admission configuration for throttling peer registrations.
+	Admission *AdmissionConfig `yaml:"admission" mapstructure:"admission"`
+
+	// TaskID configuration canonicalizes the url and salts the task id with content version,
+	// it must be the same as the task id configuration of daemons.
+	TaskID idgen.TaskIDConfig `yaml:"taskID" mapstructure:"taskID"`
+}
+
+type TaskClassConfig struct {
+	// Name is the unique name of task class.
+	Name string `yaml:"name" mapstructure:"name"`
+
+	// Priority of task class, the class with the highest priority is used
+	// when the task matches multiple classes.
+	Priority int `yaml:"priority" mapstructure:"priority"`
+
+	// Tags are the url meta tags of tasks in the class.
+	Tags []string `yaml:"tags" mapstructure:"tags"`
+
+	// Applications are the url meta applications of tasks in the class.
+	Applications []string `yaml:"applications" mapstructure:"applications"`
+
+	// FilterParentLimit is the limit of candidate parents for peers of the task,
+	// the limit of scheduler cluster is used if it is zero.
+	FilterParentLimit int `yaml:"filterParentLimit" mapstructure:"filterParentLimit"`
+
+	// BackSourceCount is the back-to-source count of the task,
+	// scheduler backSourceCount is used if it is zero.
+	BackSourceCount int `yaml:"backSourceCount" mapstructure:"backSourceCount"`
+}
+
+// Match returns whether the task with tag and application is in the class.
+func (c *TaskClassConfig) Match(tag, application string) bool {
+	for _, t := range c.Tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	for _, a := range c.Applications {
+		if a == application {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchTaskClass returns the task class with the highest priority matched by tag and application.
+func (c *SchedulerConfig) MatchTaskClass(tag, application string) (*TaskClassConfig, bool) {
+	var matched *TaskClassConfig
+	for _, class := range c.TaskClasses {
+		if class.Match(tag, application) && (matched == nil || class.Priority > matched.Priority) {
+			matched = class
+		}
+	}
+
+	return matched, matched != nil
+}
+
+// GetTaskClass returns the task class by name.
+func (c *SchedulerConfig) GetTaskClass(name string) (*TaskClassConfig, bool) {
+	for _, class := range c.TaskClasses {
+		if class.Name == name {
+			return class, true
+		}
+	}
+
+	return nil, false
+}
+
+type AdmissionConfig struct {
+	// Enable throttling peer registrations with token buckets.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// IPRate is the registrations per second allowed from a source ip,
+	// registrations are not throttled by source ip if it is zero.
+	IPRate float64 `yaml:"ipRate" mapstructure:"ipRate"`
+
+	// IPBurst is the bucket size of a source ip.
+	IPBurst int `yaml:"ipBurst" mapstructure:"ipBurst"`
+
+	// ApplicationRate is the registrations per second allowed from an application,
+	// registrations are not throttled by application if it is zero.
+	ApplicationRate float64 `yaml:"applicationRate" mapstructure:"applicationRate"`
+
+	// ApplicationBurst is the bucket size of an application.
+	ApplicationBurst int `yaml:"applicationBurst" mapstructure:"applicationBurst"`
+
+	// BucketTTL is the idle time after which the bucket is reclaimed.
+	BucketTTL time.Duration `yaml:"bucketTTL" mapstructure:"bucketTTL"`
+}
+
+type ParentAdjustmentConfig struct {
+	// Enable switching parent when downloading from parent is degraded.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// DegradedWindows is the count of consecutive evaluation windows in which downloading is degraded
+	// before the parent is switched, a window is evaluated when a piece is downloaded from parent.
+	DegradedWindows int `yaml:"degradedWindows" mapstructure:"degradedWindows"`
+
+	// Cooldown is the min interval after the parent of peer is switched,
+	// the parent is not switched again for degradation during the interval.
+	Cooldown time.Duration `yaml:"cooldown" mapstructure:"cooldown"`
+}
+
+type AntiAffinityConfig struct {
+	// Enable anti-affinity scheduling.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// MinParents is the min count of parents from a failure domain other than the peer's,
+	// failure domain is made up of idc and the rack switch which is the first element of net topology.
+	// Parents in the same failure domain are still preferred when it is satisfied.
+	MinParents int `yaml:"minParents" mapstructure:"minParents"`
+}
+
+type SuperNodeConfig struct {
+	// Enable super node hinting.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// PeerCountThreshold is the peer count of task to enable super node hinting,
+	// tasks with fewer peers are scheduled as usual.
+	PeerCountThreshold int `yaml:"peerCountThreshold" mapstructure:"peerCountThreshold"`
+
+	// Limit is the max count of super nodes in a task.
+	Limit int `yaml:"limit" mapstructure:"limit"`
+
+	// MaxDepth is the max depth of peer tree under super nodes,
+	// peers deeper than it are not candidate parents.
+	MaxDepth int `yaml:"maxDepth" mapstructure:"maxDepth"`
+}
+
+type GRPCEvaluatorConfig struct {
+	// Addr is the address of external grpc evaluator service.
+	Addr string `yaml:"addr" mapstructure:"addr"`
+
+	// Timeout is the timeout of calling external grpc evaluator service,
+	// built-in evaluator will be used when calling timeout.
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+
+	// CacheTTL is the ttl of cached evaluation results.
+	CacheTTL time.Duration `yaml:"cacheTTL" mapstructure:"cacheTTL"`
+
+	// FailureThreshold is the count of consecutive failed calls, after that
+	// built-in evaluator is used without calling grpc evaluator until cooldown passed.
+	FailureThreshold int `yaml:"failureThreshold" mapstructure:"failureThreshold"`
+
+	// Cooldown is the duration of using built-in evaluator after grpc evaluator failed consecutively.
+	Cooldown time.Duration `yaml:"cooldown" mapstructure:"cooldown"`
+}
+
+type MLEvaluatorConfig struct {
+	// ModelPath is the path of model file trained offline,
+	// the model contains the feature extraction pipeline and the weights of features.
+	ModelPath string `yaml:"modelPath" mapstructure:"modelPath"`
+
+	// ReloadInterval is the interval for checking whether the model file is changed,
+	// the changed model is reloaded without restarting scheduler, zero disables reloading.
+	ReloadInterval time.Duration `yaml:"reloadInterval" mapstructure:"reloadInterval"`
 }
 
 type TrainingConfig struct {
@@ -340,11 +737,20 @@ type GCConfig struct {
 
 	// Host time to live.
 	HostTTL time.Duration `yaml:"hostTTL" mapstructure:"hostTTL"`
+
+	// TaskClassTTL is task time to live of task classes by name,
+	// taskTTL is used for tasks without class or classes not in it.
+	TaskClassTTL map[string]time.Duration `yaml:"taskClassTTL" mapstructure:"taskClassTTL"`
 }
 
 type DynConfig struct {
 	// RefreshInterval is refresh interval for manager cache.
 	RefreshInterval time.Duration `yaml:"refreshInterval" mapstructure:"refreshInterval"`
+
+	// SeedPeerWatchInterval is interval for watching seed peers in manager,
+	// observers are notified immediately when seed peers change.
+	// Seed peers are not watched if it is zero.
+	SeedPeerWatchInterval time.Duration `yaml:"seedPeerWatchInterval" mapstructure:"seedPeerWatchInterval"`
 }
 
 type HostConfig struct {
@@ -367,11 +773,54 @@ type ManagerConfig struct {
 
 	// KeepAlive configuration.
 	KeepAlive KeepAliveConfig `yaml:"keepAlive" mapstructure:"keepAlive"`
+
+	// TLS configuration, the connection is plaintext when it is not set.
+	TLS *ManagerTLSConfig `yaml:"tls" mapstructure:"tls"`
+}
+
+type ManagerTLSConfig struct {
+	// CA file path to verify manager certificate, system roots are used when it is not set.
+	CA string `yaml:"ca" mapstructure:"ca"`
+
+	// Client certificate file path for mutual tls.
+	Cert string `yaml:"cert" mapstructure:"cert"`
+
+	// Client key file path for mutual tls.
+	Key string `yaml:"key" mapstructure:"key"`
+
+	// ServerName overrides the server name to verify manager certificate.
+	ServerName string `yaml:"serverName" mapstructure:"serverName"`
+
+	// ReloadInterval is the interval of checking certificate files for rotation.
+	ReloadInterval time.Duration `yaml:"reloadInterval" mapstructure:"reloadInterval"`
 }
 
 type SeedPeerConfig struct {
 	// Enable is to enable seed peer as P2P peer.
 	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// HealthProbe configuration.
+	HealthProbe SeedPeerHealthProbeConfig `yaml:"healthProbe" mapstructure:"healthProbe"`
+
+	// UploadAuthSecret is the secret of upload auth of seed peers,
+	// it signs the tokens of pinning preheated tasks in seed peers.
+	UploadAuthSecret string `yaml:"uploadAuthSecret" mapstructure:"uploadAuthSecret"`
+}
+
+type SeedPeerHealthProbeConfig struct {
+	// Enable is to enable probing health of seed peers,
+	// unhealthy seed peers are demoted and not scheduled.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Interval is the interval of probing seed peers.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+
+	// Timeout is the timeout of probing a seed peer.
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+
+	// FailureThreshold is the number of consecutive failed probes to demote a seed peer,
+	// the demoted seed peer is restored after a successful probe.
+	FailureThreshold int `yaml:"failureThreshold" mapstructure:"failureThreshold"`
 }
 
 type KeepAliveConfig struct {
@@ -408,6 +857,21 @@ type StorageConfig struct {
 	BufferSize int `yaml:"bufferSize" mapstructure:"bufferSize"`
 }
 
+type PersistenceConfig struct {
+	// Enable persists hosts, tasks and peers to redis of job,
+	// and restores them when scheduler restarts.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Interval of snapshot.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+
+	// TTL is the expiration of snapshot, expired snapshot is not restored.
+	TTL time.Duration `yaml:"ttl" mapstructure:"ttl"`
+
+	// Redis database of snapshot.
+	DB int `yaml:"db" mapstructure:"db"`
+}
+
 type RedisConfig struct {
 	// Server hostname.
 	Host string `yaml:"host" mapstructure:"host"`
@@ -435,3 +899,11 @@ type MetricsConfig struct {
 	// Enable peer host metrics.
 	EnablePeerHost bool `yaml:"enablePeerHost" mapstructure:"enablePeerHost"`
 }
+
+type DebugConfig struct {
+	// Enable debug service, which dumps peer trees of tasks.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Debug service address.
+	Addr string `yaml:"addr" mapstructure:"addr"`
+}
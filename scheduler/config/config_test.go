@@ -25,6 +25,7 @@ import (
 	testifyassert "github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
 
+	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/net/fqdn"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/scheduler/storage"
@@ -40,6 +41,7 @@ func TestConfig_Load(t *testing.T) {
 			RetryBackSourceLimit: 2,
 			RetryLimit:           10,
 			RetryInterval:        1 * time.Second,
+			ETAInterval:          5 * time.Second,
 			GC: &GCConfig{
 				PeerGCInterval: 1 * time.Minute,
 				PeerTTL:        5 * time.Minute,
@@ -47,6 +49,9 @@ func TestConfig_Load(t *testing.T) {
 				TaskTTL:        10 * time.Minute,
 				HostGCInterval: 1 * time.Minute,
 				HostTTL:        10 * time.Minute,
+				TaskClassTTL: map[string]time.Duration{
+					"production": time.Hour,
+				},
 			},
 			Training: &TrainingConfig{
 				Enable:               true,
@@ -54,19 +59,70 @@ func TestConfig_Load(t *testing.T) {
 				RefreshModelInterval: 1 * time.Second,
 				CPU:                  2,
 			},
+			GRPCEvaluator: &GRPCEvaluatorConfig{
+				Addr:             "127.0.0.1:65002",
+				Timeout:          100 * time.Millisecond,
+				CacheTTL:         5 * time.Second,
+				FailureThreshold: 5,
+				Cooldown:         time.Minute,
+			},
+			MLEvaluator: &MLEvaluatorConfig{
+				ModelPath:      "/var/lib/dragonfly/model.json",
+				ReloadInterval: 30 * time.Second,
+			},
+			SuperNode: &SuperNodeConfig{
+				Enable:             true,
+				PeerCountThreshold: 1000,
+				Limit:              10,
+				MaxDepth:           3,
+			},
+			AntiAffinity: &AntiAffinityConfig{
+				Enable:     true,
+				MinParents: 2,
+			},
+			ParentAdjustment: &ParentAdjustmentConfig{
+				Enable:          true,
+				DegradedWindows: 5,
+				Cooldown:        time.Minute,
+			},
+			Admission: &AdmissionConfig{
+				Enable:           true,
+				IPRate:           5,
+				IPBurst:          10,
+				ApplicationRate:  100,
+				ApplicationBurst: 200,
+				BucketTTL:        5 * time.Minute,
+			},
+			TaskID: idgen.TaskIDConfig{
+				SortQuery:     true,
+				StripQuery:    []string{"session"},
+				VersionHeader: "X-Version",
+			},
+			TaskClasses: []*TaskClassConfig{
+				{
+					Name:              "production",
+					Priority:          10,
+					Tags:              []string{"production"},
+					Applications:      []string{"registry"},
+					FilterParentLimit: 8,
+					BackSourceCount:   5,
+				},
+			},
 		},
 		Server: &ServerConfig{
-			IP:       "127.0.0.1",
-			Host:     "foo",
-			Listen:   "0.0.0.0",
-			Port:     8002,
-			WorkHome: "home",
-			CacheDir: "foo",
-			LogDir:   "bar",
-			DataDir:  "baz",
+			IP:           "127.0.0.1",
+			Host:         "foo",
+			Listen:       "0.0.0.0",
+			Port:         8002,
+			WorkHome:     "home",
+			CacheDir:     "foo",
+			LogDir:       "bar",
+			DataDir:      "baz",
+			DrainTimeout: 30 * time.Second,
 		},
 		DynConfig: &DynConfig{
-			RefreshInterval: 5 * time.Minute,
+			RefreshInterval:       5 * time.Minute,
+			SeedPeerWatchInterval: 5 * time.Second,
 		},
 		Manager: &ManagerConfig{
 			Addr:               "127.0.0.1:65003",
@@ -74,9 +130,22 @@ func TestConfig_Load(t *testing.T) {
 			KeepAlive: KeepAliveConfig{
 				Interval: 5 * time.Second,
 			},
+			TLS: &ManagerTLSConfig{
+				CA:             "ca.crt",
+				Cert:           "client.crt",
+				Key:            "client.key",
+				ServerName:     "manager",
+				ReloadInterval: 30 * time.Second,
+			},
 		},
 		SeedPeer: &SeedPeerConfig{
 			Enable: true,
+			HealthProbe: SeedPeerHealthProbeConfig{
+				Enable:           true,
+				Interval:         10 * time.Second,
+				Timeout:          3 * time.Second,
+				FailureThreshold: 3,
+			},
 		},
 		Host: &HostConfig{
 			IDC:         "foo",
@@ -106,6 +175,16 @@ func TestConfig_Load(t *testing.T) {
 			Addr:           ":8000",
 			EnablePeerHost: false,
 		},
+		Debug: &DebugConfig{
+			Enable: true,
+			Addr:   ":8004",
+		},
+		Persistence: &PersistenceConfig{
+			Enable:   true,
+			Interval: 10 * time.Second,
+			TTL:      time.Minute,
+			DB:       3,
+		},
 	}
 
 	schedulerConfigYAML := &Config{}
@@ -128,10 +207,11 @@ func TestConfig_New(t *testing.T) {
 
 	assert.EqualValues(config, &Config{
 		Server: &ServerConfig{
-			IP:     ip.IPv4,
-			Host:   fqdn.FQDNHostname,
-			Listen: "0.0.0.0",
-			Port:   8002,
+			IP:           ip.IPv4,
+			Host:         fqdn.FQDNHostname,
+			Listen:       "0.0.0.0",
+			Port:         8002,
+			DrainTimeout: 20 * time.Second,
 		},
 		Scheduler: &SchedulerConfig{
 			Algorithm:            "default",
@@ -139,6 +219,7 @@ func TestConfig_New(t *testing.T) {
 			RetryBackSourceLimit: 5,
 			RetryLimit:           10,
 			RetryInterval:        50 * time.Millisecond,
+			ETAInterval:          3 * time.Second,
 			GC: &GCConfig{
 				PeerGCInterval: 10 * time.Minute,
 				PeerTTL:        24 * time.Hour,
@@ -153,9 +234,42 @@ func TestConfig_New(t *testing.T) {
 				RefreshModelInterval: 168 * time.Hour,
 				CPU:                  1,
 			},
+			GRPCEvaluator: &GRPCEvaluatorConfig{
+				Timeout:          100 * time.Millisecond,
+				CacheTTL:         5 * time.Second,
+				FailureThreshold: 3,
+				Cooldown:         30 * time.Second,
+			},
+			MLEvaluator: &MLEvaluatorConfig{
+				ReloadInterval: 30 * time.Second,
+			},
+			SuperNode: &SuperNodeConfig{
+				Enable:             false,
+				PeerCountThreshold: 1000,
+				Limit:              10,
+				MaxDepth:           3,
+			},
+			AntiAffinity: &AntiAffinityConfig{
+				Enable:     false,
+				MinParents: 1,
+			},
+			ParentAdjustment: &ParentAdjustmentConfig{
+				Enable:          false,
+				DegradedWindows: 3,
+				Cooldown:        30 * time.Second,
+			},
+			Admission: &AdmissionConfig{
+				Enable:           false,
+				IPRate:           10,
+				IPBurst:          50,
+				ApplicationRate:  500,
+				ApplicationBurst: 1000,
+				BucketTTL:        10 * time.Minute,
+			},
 		},
 		DynConfig: &DynConfig{
-			RefreshInterval: 10 * time.Second,
+			RefreshInterval:       10 * time.Second,
+			SeedPeerWatchInterval: 3 * time.Second,
 		},
 		Host: &HostConfig{},
 		Manager: &ManagerConfig{
@@ -166,6 +280,12 @@ func TestConfig_New(t *testing.T) {
 		},
 		SeedPeer: &SeedPeerConfig{
 			Enable: true,
+			HealthProbe: SeedPeerHealthProbeConfig{
+				Enable:           false,
+				Interval:         DefaultSeedPeerHealthProbeInterval,
+				Timeout:          DefaultSeedPeerHealthProbeTimeout,
+				FailureThreshold: DefaultSeedPeerHealthProbeFailureThreshold,
+			},
 		},
 		Job: &JobConfig{
 			Enable:             true,
@@ -187,5 +307,75 @@ func TestConfig_New(t *testing.T) {
 			Enable:         false,
 			EnablePeerHost: false,
 		},
+		Debug: &DebugConfig{
+			Enable: false,
+		},
+		Persistence: &PersistenceConfig{
+			Enable:   false,
+			Interval: DefaultPersistenceInterval,
+			TTL:      DefaultPersistenceTTL,
+			DB:       DefaultPersistenceRedisDB,
+		},
 	})
 }
+
+func TestSchedulerConfig_MatchTaskClass(t *testing.T) {
+	config := &SchedulerConfig{
+		TaskClasses: []*TaskClassConfig{
+			{Name: "bulk", Priority: 1, Tags: []string{"bulk", "image"}},
+			{Name: "production", Priority: 10, Tags: []string{"image"}, Applications: []string{"registry"}},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		tag         string
+		application string
+		expect      func(t *testing.T, class *TaskClassConfig, ok bool)
+	}{
+		{
+			name: "match by tag",
+			tag:  "bulk",
+			expect: func(t *testing.T, class *TaskClassConfig, ok bool) {
+				assert := testifyassert.New(t)
+				assert.True(ok)
+				assert.Equal("bulk", class.Name)
+			},
+		},
+		{
+			name:        "match by application",
+			application: "registry",
+			expect: func(t *testing.T, class *TaskClassConfig, ok bool) {
+				assert := testifyassert.New(t)
+				assert.True(ok)
+				assert.Equal("production", class.Name)
+			},
+		},
+		{
+			name: "class with the highest priority is matched",
+			tag:  "image",
+			expect: func(t *testing.T, class *TaskClassConfig, ok bool) {
+				assert := testifyassert.New(t)
+				assert.True(ok)
+				assert.Equal("production", class.Name)
+			},
+		},
+		{
+			name:        "no class is matched",
+			tag:         "foo",
+			application: "bar",
+			expect: func(t *testing.T, class *TaskClassConfig, ok bool) {
+				assert := testifyassert.New(t)
+				assert.False(ok)
+				assert.Nil(class)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			class, ok := config.MatchTaskClass(tc.tag, tc.application)
+			tc.expect(t, class, ok)
+		})
+	}
+}
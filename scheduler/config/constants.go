@@ -25,6 +25,15 @@ const (
 	// DefaultSeedPeerLoadLimit is default number for seed peer load limit.
 	DefaultSeedPeerLoadLimit = 300
 
+	// DefaultSeedPeerHealthProbeInterval is default interval for probing health of seed peers.
+	DefaultSeedPeerHealthProbeInterval = 10 * time.Second
+
+	// DefaultSeedPeerHealthProbeTimeout is default timeout for probing health of a seed peer.
+	DefaultSeedPeerHealthProbeTimeout = 3 * time.Second
+
+	// DefaultSeedPeerHealthProbeFailureThreshold is default number of consecutive failed probes to demote a seed peer.
+	DefaultSeedPeerHealthProbeFailureThreshold = 3
+
 	// DefaultClientLoadLimit is default number for client load limit.
 	DefaultClientLoadLimit = 50
 
@@ -44,6 +53,9 @@ var DefaultServerListen = net.IPv4zero.String()
 const (
 	// DefaultServerPort is default port for server.
 	DefaultServerPort = 8002
+
+	// DefaultServerDrainTimeout is default drain timeout for server.
+	DefaultServerDrainTimeout = 20 * time.Second
 )
 
 const (
@@ -62,6 +74,9 @@ const (
 	// DefaultSchedulerRetryInterval is default retry interval for scheduler.
 	DefaultSchedulerRetryInterval = 50 * time.Millisecond
 
+	// DefaultSchedulerETAInterval is default interval for pushing eta to peers.
+	DefaultSchedulerETAInterval = 3 * time.Second
+
 	// DefaultSchedulerPeerGCInterval is default interval for peer gc.
 	DefaultSchedulerPeerGCInterval = 10 * time.Minute
 
@@ -85,11 +100,73 @@ const (
 
 	// DefaultCPU is default cpu usage.
 	DefaultCPU = 1
+
+	// GRPCEvaluatorAlgorithm is the algorithm delegating evaluation to external grpc service.
+	GRPCEvaluatorAlgorithm = "grpc"
+
+	// DefaultSchedulerGRPCEvaluatorTimeout is default timeout for calling grpc evaluator.
+	DefaultSchedulerGRPCEvaluatorTimeout = 100 * time.Millisecond
+
+	// DefaultSchedulerGRPCEvaluatorCacheTTL is default ttl for cached evaluation results of grpc evaluator.
+	DefaultSchedulerGRPCEvaluatorCacheTTL = 5 * time.Second
+
+	// DefaultSchedulerGRPCEvaluatorFailureThreshold is default count of consecutive failed calls
+	// before falling back to built-in evaluator.
+	DefaultSchedulerGRPCEvaluatorFailureThreshold = 3
+
+	// DefaultSchedulerGRPCEvaluatorCooldown is default duration of using built-in evaluator
+	// after grpc evaluator failed consecutively.
+	DefaultSchedulerGRPCEvaluatorCooldown = 30 * time.Second
+
+	// MLEvaluatorAlgorithm is the algorithm scoring parents with the machine-learned model.
+	MLEvaluatorAlgorithm = "ml"
+
+	// DefaultSchedulerMLEvaluatorReloadInterval is default interval for checking whether the model file of ml evaluator is changed.
+	DefaultSchedulerMLEvaluatorReloadInterval = 30 * time.Second
+
+	// DefaultSchedulerSuperNodePeerCountThreshold is default peer count of task to enable super node hinting.
+	DefaultSchedulerSuperNodePeerCountThreshold = 1000
+
+	// DefaultSchedulerSuperNodeLimit is default max count of super nodes in a task.
+	DefaultSchedulerSuperNodeLimit = 10
+
+	// DefaultSchedulerSuperNodeMaxDepth is default max depth of peer tree for large fan-out tasks.
+	DefaultSchedulerSuperNodeMaxDepth = 3
+
+	// DefaultSchedulerAntiAffinityMinParents is default min count of parents from another failure domain.
+	DefaultSchedulerAntiAffinityMinParents = 1
+
+	// DefaultSchedulerParentAdjustmentDegradedWindows is default count of degraded evaluation windows before switching parent.
+	DefaultSchedulerParentAdjustmentDegradedWindows = 3
+
+	// DefaultSchedulerParentAdjustmentCooldown is default cooldown after the parent of peer is switched.
+	DefaultSchedulerParentAdjustmentCooldown = 30 * time.Second
+
+	// DefaultSchedulerExcludedParentTTL is default ttl of parents excluded by hints of dfdaemon.
+	DefaultSchedulerExcludedParentTTL = 1 * time.Minute
+
+	// DefaultSchedulerAdmissionIPRate is default registrations per second allowed from a source ip.
+	DefaultSchedulerAdmissionIPRate = 10
+
+	// DefaultSchedulerAdmissionIPBurst is default bucket size of a source ip.
+	DefaultSchedulerAdmissionIPBurst = 50
+
+	// DefaultSchedulerAdmissionApplicationRate is default registrations per second allowed from an application.
+	DefaultSchedulerAdmissionApplicationRate = 500
+
+	// DefaultSchedulerAdmissionApplicationBurst is default bucket size of an application.
+	DefaultSchedulerAdmissionApplicationBurst = 1000
+
+	// DefaultSchedulerAdmissionBucketTTL is default idle time after which the bucket is reclaimed.
+	DefaultSchedulerAdmissionBucketTTL = 10 * time.Minute
 )
 
 const (
 	// DefaultDynConfigRefreshInterval is default refresh interval for dynamic configuration.
 	DefaultDynConfigRefreshInterval = 10 * time.Second
+
+	// DefaultDynConfigSeedPeerWatchInterval is default interval for watching seed peers in manager.
+	DefaultDynConfigSeedPeerWatchInterval = 3 * time.Second
 )
 
 const (
@@ -119,3 +196,14 @@ const (
 	// DefaultJobRedisBackendDB is default db for redis backend.
 	DefaultJobRedisBackendDB = 2
 )
+
+const (
+	// DefaultPersistenceInterval is default interval for snapshot of resource.
+	DefaultPersistenceInterval = 30 * time.Second
+
+	// DefaultPersistenceTTL is default expiration for snapshot of resource.
+	DefaultPersistenceTTL = 10 * time.Minute
+
+	// DefaultPersistenceRedisDB is default db for snapshot of resource.
+	DefaultPersistenceRedisDB = 3
+)
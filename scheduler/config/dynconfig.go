@@ -21,9 +21,12 @@ package config
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc/resolver"
@@ -111,12 +114,24 @@ type DynconfigInterface interface {
 	// Get the client config.
 	GetSchedulerClusterClientConfig() (types.SchedulerClusterClientConfig, bool)
 
+	// Get the features of scheduler cluster.
+	GetSchedulerClusterFeatures() (types.SchedulerClusterFeatures, bool)
+
+	// Get the blocklist of scheduler cluster.
+	GetSchedulerClusterBlocklist() (types.Blocklist, bool)
+
 	// Get the dynamic config from manager.
 	Get() (*DynconfigData, error)
 
 	// Refresh refreshes dynconfig in cache.
 	Refresh() error
 
+	// DemoteSeedPeer excludes the seed peer from the dynamic config until it is restored.
+	DemoteSeedPeer(uint)
+
+	// RestoreSeedPeer includes the demoted seed peer in the dynamic config again.
+	RestoreSeedPeer(uint)
+
 	// Register allows an instance to register itself to listen/observe events.
 	Register(Observer)
 
@@ -143,15 +158,27 @@ type dynconfig struct {
 	observers map[Observer]struct{}
 	done      chan bool
 	cachePath string
+
+	// seedPeerWatchInterval is interval for watching seed peers in manager.
+	seedPeerWatchInterval time.Duration
+
+	// seedPeers is the seed peers notified to observers last time.
+	seedPeers []*SeedPeer
+
+	// demotedSeedPeers is the ids of seed peers excluded from the dynamic config.
+	demotedSeedPeers map[uint]struct{}
+	mu               sync.RWMutex
 }
 
 // NewDynconfig returns a new dynconfig instence.
 func NewDynconfig(rawManagerClient managerclient.Client, cacheDir string, cfg *Config) (DynconfigInterface, error) {
 	cachePath := filepath.Join(cacheDir, cacheFileName)
 	d := &dynconfig{
-		observers: map[Observer]struct{}{},
-		done:      make(chan bool),
-		cachePath: cachePath,
+		observers:             map[Observer]struct{}{},
+		done:                  make(chan bool),
+		cachePath:             cachePath,
+		seedPeerWatchInterval: cfg.DynConfig.SeedPeerWatchInterval,
+		demotedSeedPeers:      map[uint]struct{}{},
 	}
 
 	if rawManagerClient != nil {
@@ -179,7 +206,7 @@ func (d *dynconfig) GetResolveSeedPeerAddrs() ([]resolver.Address, error) {
 
 	addrs := []string{}
 	for _, seedPeer := range seedPeers {
-		addr := fmt.Sprintf("%s:%d", seedPeer.IP, seedPeer.Port)
+		addr := net.JoinHostPort(seedPeer.IP, strconv.Itoa(int(seedPeer.Port)))
 		r := reachable.New(&reachable.Config{Address: addr})
 		if err := r.Check(); err != nil {
 			logger.Warnf("seed peer address %s is unreachable", addr)
@@ -247,6 +274,48 @@ func (d *dynconfig) GetSchedulerClusterClientConfig() (types.SchedulerClusterCli
 	return config, true
 }
 
+// Get the features of scheduler cluster, they are delivered with the scheduler cluster config.
+func (d *dynconfig) GetSchedulerClusterFeatures() (types.SchedulerClusterFeatures, bool) {
+	data, err := d.Get()
+	if err != nil {
+		return nil, false
+	}
+
+	if data.SchedulerCluster == nil {
+		return nil, false
+	}
+
+	var config struct {
+		Features types.SchedulerClusterFeatures `json:"features"`
+	}
+	if err := json.Unmarshal(data.SchedulerCluster.Config, &config); err != nil {
+		return nil, false
+	}
+
+	return config.Features, config.Features != nil
+}
+
+// Get the blocklist of scheduler cluster, it is delivered with the scheduler cluster config.
+func (d *dynconfig) GetSchedulerClusterBlocklist() (types.Blocklist, bool) {
+	data, err := d.Get()
+	if err != nil {
+		return nil, false
+	}
+
+	if data.SchedulerCluster == nil {
+		return nil, false
+	}
+
+	var config struct {
+		Blocklist types.Blocklist `json:"blocklist"`
+	}
+	if err := json.Unmarshal(data.SchedulerCluster.Config, &config); err != nil {
+		return nil, false
+	}
+
+	return config.Blocklist, len(config.Blocklist) > 0
+}
+
 // Get the dynamic config from manager.
 func (d *dynconfig) Get() (*DynconfigData, error) {
 	var config DynconfigData
@@ -254,6 +323,21 @@ func (d *dynconfig) Get() (*DynconfigData, error) {
 		return nil, err
 	}
 
+	// Demoted seed peers are not scheduled.
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if len(d.demotedSeedPeers) > 0 {
+		seedPeers := make([]*SeedPeer, 0, len(config.SeedPeers))
+		for _, seedPeer := range config.SeedPeers {
+			if _, ok := d.demotedSeedPeers[seedPeer.ID]; ok {
+				continue
+			}
+
+			seedPeers = append(seedPeers, seedPeer)
+		}
+		config.SeedPeers = seedPeers
+	}
+
 	return &config, nil
 }
 
@@ -262,6 +346,20 @@ func (d *dynconfig) Refresh() error {
 	return d.Dynconfig.Refresh()
 }
 
+// DemoteSeedPeer excludes the seed peer from the dynamic config until it is restored.
+func (d *dynconfig) DemoteSeedPeer(id uint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.demotedSeedPeers[id] = struct{}{}
+}
+
+// RestoreSeedPeer includes the demoted seed peer in the dynamic config again.
+func (d *dynconfig) RestoreSeedPeer(id uint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.demotedSeedPeers, id)
+}
+
 // Register allows an instance to register itself to listen/observe events.
 func (d *dynconfig) Register(l Observer) {
 	d.observers[l] = struct{}{}
@@ -283,6 +381,7 @@ func (d *dynconfig) Notify() error {
 		o.OnNotify(config)
 	}
 
+	d.seedPeers = config.SeedPeers
 	return nil
 }
 
@@ -300,18 +399,51 @@ func (d *dynconfig) Serve() error {
 func (d *dynconfig) watch() {
 	tick := time.NewTicker(watchInterval)
 
+	// Seed peers are not watched if the interval is zero,
+	// receiving from nil channel blocks forever.
+	var seedPeerTickC <-chan time.Time
+	if d.seedPeerWatchInterval > 0 {
+		seedPeerTick := time.NewTicker(d.seedPeerWatchInterval)
+		defer seedPeerTick.Stop()
+		seedPeerTickC = seedPeerTick.C
+	}
+
 	for {
 		select {
 		case <-tick.C:
 			if err := d.Notify(); err != nil {
 				logger.Error("dynconfig notify failed", err)
 			}
+		case <-seedPeerTickC:
+			if err := d.watchSeedPeers(); err != nil {
+				logger.Error("dynconfig watch seed peers failed", err)
+			}
 		case <-d.done:
 			return
 		}
 	}
 }
 
+// watchSeedPeers refreshes dynconfig from manager and notifies observers
+// immediately when seed peers change, rather than waiting for the cache expiration.
+func (d *dynconfig) watchSeedPeers() error {
+	if err := d.Refresh(); err != nil {
+		return err
+	}
+
+	seedPeers, err := d.GetSeedPeers()
+	if err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(d.seedPeers, seedPeers) {
+		return nil
+	}
+
+	logger.Infof("seed peers have been changed: %#v", seedPeers)
+	return d.Notify()
+}
+
 // Stop the dynconfig listening service.
 func (d *dynconfig) Stop() error {
 	close(d.done)
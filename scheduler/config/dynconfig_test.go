@@ -29,6 +29,7 @@ import (
 	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 
 	"d7y.io/dragonfly/v2/manager/model"
+	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
 )
 
@@ -246,3 +247,291 @@ func TestDynconfig_GetManagerSourceType(t *testing.T) {
 		})
 	}
 }
+
+func TestDynconfig_GetSchedulerClusterFeatures(t *testing.T) {
+	mockCacheDir := t.TempDir()
+	mockConfig := &Config{
+		DynConfig: &DynConfig{
+			RefreshInterval: 10 * time.Second,
+		},
+		Server: &ServerConfig{
+			Host: "localhost",
+		},
+		Manager: &ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config []byte
+		expect func(t *testing.T, features types.SchedulerClusterFeatures, ok bool)
+	}{
+		{
+			name:   "features in scheduler cluster config",
+			config: []byte(`{"filter_parent_limit":4,"features":{"seed-peer":false}}`),
+			expect: func(t *testing.T, features types.SchedulerClusterFeatures, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.False(features.Enabled(types.SchedulerFeatureSeedPeer, true))
+				assert.True(features.Enabled("foo", true))
+			},
+		},
+		{
+			name:   "scheduler cluster config without features",
+			config: []byte(`{"filter_parent_limit":4}`),
+			expect: func(t *testing.T, features types.SchedulerClusterFeatures, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.True(features.Enabled(types.SchedulerFeatureSeedPeer, true))
+			},
+		},
+		{
+			name:   "invalid scheduler cluster config",
+			config: []byte{1},
+			expect: func(t *testing.T, features types.SchedulerClusterFeatures, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := mocks.NewMockClient(ctl)
+			mockManagerClient.EXPECT().GetScheduler(gomock.Any(), gomock.Any()).Return(&managerv1.Scheduler{
+				Id: 1,
+				SchedulerCluster: &managerv1.SchedulerCluster{
+					Id:     1,
+					Config: tc.config,
+				},
+			}, nil).Times(1)
+
+			d, err := NewDynconfig(mockManagerClient, mockCacheDir, mockConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			features, ok := d.GetSchedulerClusterFeatures()
+			tc.expect(t, features, ok)
+			if err := os.Remove(filepath.Join(mockCacheDir, cacheFileName)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDynconfig_GetSchedulerClusterBlocklist(t *testing.T) {
+	mockCacheDir := t.TempDir()
+	mockConfig := &Config{
+		DynConfig: &DynConfig{
+			RefreshInterval: 10 * time.Second,
+		},
+		Server: &ServerConfig{
+			Host: "localhost",
+		},
+		Manager: &ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		config []byte
+		expect func(t *testing.T, blocklist types.Blocklist, ok bool)
+	}{
+		{
+			name:   "blocklist in scheduler cluster config",
+			config: []byte(`{"filter_parent_limit":4,"blocklist":[{"type":"glob","pattern":"https://example.com/*.iso"},{"type":"regex","pattern":"^http://foo\\.com/"}]}`),
+			expect: func(t *testing.T, blocklist types.Blocklist, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Len(blocklist, 2)
+
+				rule, ok := blocklist.Match("https://example.com/images/bar.iso")
+				assert.True(ok)
+				assert.Equal(types.BlocklistTypeGlob, rule.Type)
+
+				rule, ok = blocklist.Match("http://foo.com/bar")
+				assert.True(ok)
+				assert.Equal(types.BlocklistTypeRegex, rule.Type)
+
+				_, ok = blocklist.Match("https://example.com/bar.tar")
+				assert.False(ok)
+			},
+		},
+		{
+			name:   "scheduler cluster config without blocklist",
+			config: []byte(`{"filter_parent_limit":4}`),
+			expect: func(t *testing.T, blocklist types.Blocklist, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				_, ok = blocklist.Match("https://example.com/bar.iso")
+				assert.False(ok)
+			},
+		},
+		{
+			name:   "invalid scheduler cluster config",
+			config: []byte{1},
+			expect: func(t *testing.T, blocklist types.Blocklist, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := mocks.NewMockClient(ctl)
+			mockManagerClient.EXPECT().GetScheduler(gomock.Any(), gomock.Any()).Return(&managerv1.Scheduler{
+				Id: 1,
+				SchedulerCluster: &managerv1.SchedulerCluster{
+					Id:     1,
+					Config: tc.config,
+				},
+			}, nil).Times(1)
+
+			d, err := NewDynconfig(mockManagerClient, mockCacheDir, mockConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			blocklist, ok := d.GetSchedulerClusterBlocklist()
+			tc.expect(t, blocklist, ok)
+			if err := os.Remove(filepath.Join(mockCacheDir, cacheFileName)); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+type mockObserver struct {
+	data []*DynconfigData
+}
+
+func (o *mockObserver) OnNotify(data *DynconfigData) {
+	o.data = append(o.data, data)
+}
+
+func TestDynconfig_WatchSeedPeers(t *testing.T) {
+	mockConfig := &Config{
+		DynConfig: &DynConfig{
+			RefreshInterval:       10 * time.Second,
+			SeedPeerWatchInterval: time.Second,
+		},
+		Server: &ServerConfig{
+			Host: "localhost",
+		},
+		Manager: &ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+	}
+
+	mockScheduler := func(seedPeerIP string) *managerv1.Scheduler {
+		return &managerv1.Scheduler{
+			Id:       1,
+			HostName: "foo",
+			Ip:       "127.0.0.1",
+			Port:     8002,
+			SeedPeers: []*managerv1.SeedPeer{
+				{
+					Id:       1,
+					HostName: "bar",
+					Ip:       seedPeerIP,
+					Port:     8001,
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mock   func(m *mocks.MockClientMockRecorder)
+		expect func(t *testing.T, d *dynconfig, o *mockObserver)
+	}{
+		{
+			name: "seed peers are changed",
+			mock: func(m *mocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					m.GetScheduler(gomock.Any(), gomock.Any()).Return(mockScheduler("127.0.0.1"), nil).Times(1),
+					m.GetScheduler(gomock.Any(), gomock.Any()).Return(mockScheduler("127.0.0.2"), nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, d *dynconfig, o *mockObserver) {
+				assert := assert.New(t)
+				assert.NoError(d.watchSeedPeers())
+				assert.Len(o.data, 2)
+				assert.Equal("127.0.0.2", o.data[1].SeedPeers[0].IP)
+			},
+		},
+		{
+			name: "seed peers are not changed",
+			mock: func(m *mocks.MockClientMockRecorder) {
+				m.GetScheduler(gomock.Any(), gomock.Any()).Return(mockScheduler("127.0.0.1"), nil).Times(2)
+			},
+			expect: func(t *testing.T, d *dynconfig, o *mockObserver) {
+				assert := assert.New(t)
+				assert.NoError(d.watchSeedPeers())
+				assert.Len(o.data, 1)
+			},
+		},
+		{
+			name: "seed peer is demoted and restored",
+			mock: func(m *mocks.MockClientMockRecorder) {
+				m.GetScheduler(gomock.Any(), gomock.Any()).Return(mockScheduler("127.0.0.1"), nil).Times(1)
+			},
+			expect: func(t *testing.T, d *dynconfig, o *mockObserver) {
+				assert := assert.New(t)
+				d.DemoteSeedPeer(1)
+				assert.NoError(d.Notify())
+				assert.Len(o.data, 2)
+				assert.Len(o.data[1].SeedPeers, 0)
+
+				d.RestoreSeedPeer(1)
+				assert.NoError(d.Notify())
+				assert.Len(o.data, 3)
+				assert.Equal("127.0.0.1", o.data[2].SeedPeers[0].IP)
+			},
+		},
+		{
+			name: "refresh dynconfig failed",
+			mock: func(m *mocks.MockClientMockRecorder) {
+				gomock.InOrder(
+					m.GetScheduler(gomock.Any(), gomock.Any()).Return(mockScheduler("127.0.0.1"), nil).Times(1),
+					m.GetScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, d *dynconfig, o *mockObserver) {
+				assert := assert.New(t)
+				assert.EqualError(d.watchSeedPeers(), "foo")
+				assert.Len(o.data, 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := mocks.NewMockClient(ctl)
+			tc.mock(mockManagerClient.EXPECT())
+
+			d, err := NewDynconfig(mockManagerClient, t.TempDir(), mockConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			o := &mockObserver{}
+			d.Register(o)
+			if err := d.Notify(); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, d.(*dynconfig), o)
+		})
+	}
+}
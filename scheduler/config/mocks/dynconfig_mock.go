@@ -36,6 +36,18 @@ func (m *MockDynconfigInterface) EXPECT() *MockDynconfigInterfaceMockRecorder {
 	return m.recorder
 }
 
+// DemoteSeedPeer mocks base method.
+func (m *MockDynconfigInterface) DemoteSeedPeer(arg0 uint) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "DemoteSeedPeer", arg0)
+}
+
+// DemoteSeedPeer indicates an expected call of DemoteSeedPeer.
+func (mr *MockDynconfigInterfaceMockRecorder) DemoteSeedPeer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DemoteSeedPeer", reflect.TypeOf((*MockDynconfigInterface)(nil).DemoteSeedPeer), arg0)
+}
+
 // Deregister mocks base method.
 func (m *MockDynconfigInterface) Deregister(arg0 config.Observer) {
 	m.ctrl.T.Helper()
@@ -78,6 +90,21 @@ func (mr *MockDynconfigInterfaceMockRecorder) GetResolveSeedPeerAddrs() *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResolveSeedPeerAddrs", reflect.TypeOf((*MockDynconfigInterface)(nil).GetResolveSeedPeerAddrs))
 }
 
+// GetSchedulerClusterBlocklist mocks base method.
+func (m *MockDynconfigInterface) GetSchedulerClusterBlocklist() (types.Blocklist, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedulerClusterBlocklist")
+	ret0, _ := ret[0].(types.Blocklist)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetSchedulerClusterBlocklist indicates an expected call of GetSchedulerClusterBlocklist.
+func (mr *MockDynconfigInterfaceMockRecorder) GetSchedulerClusterBlocklist() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulerClusterBlocklist", reflect.TypeOf((*MockDynconfigInterface)(nil).GetSchedulerClusterBlocklist))
+}
+
 // GetSchedulerClusterClientConfig mocks base method.
 func (m *MockDynconfigInterface) GetSchedulerClusterClientConfig() (types.SchedulerClusterClientConfig, bool) {
 	m.ctrl.T.Helper()
@@ -108,6 +135,21 @@ func (mr *MockDynconfigInterfaceMockRecorder) GetSchedulerClusterConfig() *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulerClusterConfig", reflect.TypeOf((*MockDynconfigInterface)(nil).GetSchedulerClusterConfig))
 }
 
+// GetSchedulerClusterFeatures mocks base method.
+func (m *MockDynconfigInterface) GetSchedulerClusterFeatures() (types.SchedulerClusterFeatures, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchedulerClusterFeatures")
+	ret0, _ := ret[0].(types.SchedulerClusterFeatures)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// GetSchedulerClusterFeatures indicates an expected call of GetSchedulerClusterFeatures.
+func (mr *MockDynconfigInterfaceMockRecorder) GetSchedulerClusterFeatures() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulerClusterFeatures", reflect.TypeOf((*MockDynconfigInterface)(nil).GetSchedulerClusterFeatures))
+}
+
 // GetSeedPeers mocks base method.
 func (m *MockDynconfigInterface) GetSeedPeers() ([]*config.SeedPeer, error) {
 	m.ctrl.T.Helper()
@@ -163,6 +205,18 @@ func (mr *MockDynconfigInterfaceMockRecorder) Register(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockDynconfigInterface)(nil).Register), arg0)
 }
 
+// RestoreSeedPeer mocks base method.
+func (m *MockDynconfigInterface) RestoreSeedPeer(arg0 uint) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RestoreSeedPeer", arg0)
+}
+
+// RestoreSeedPeer indicates an expected call of RestoreSeedPeer.
+func (mr *MockDynconfigInterfaceMockRecorder) RestoreSeedPeer(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreSeedPeer", reflect.TypeOf((*MockDynconfigInterface)(nil).RestoreSeedPeer), arg0)
+}
+
 // Serve mocks base method.
 func (m *MockDynconfigInterface) Serve() error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,272 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+const (
+	// TasksPath is the path prefix of task debug api,
+	// the peer tree of task is served at TasksPath + "<task id>/tree".
+	TasksPath = "/debug/tasks/"
+
+	// treeSuffix is the path suffix of peer tree api.
+	treeSuffix = "/tree"
+)
+
+const (
+	// FormatJSON dumps peer tree in json.
+	FormatJSON = "json"
+
+	// FormatDOT dumps peer tree in graphviz dot.
+	FormatDOT = "dot"
+)
+
+// Tree is the snapshot of peer tree of task.
+type Tree struct {
+	// TaskID is task id.
+	TaskID string `json:"task_id"`
+
+	// URL is task download url.
+	URL string `json:"url"`
+
+	// State is task state.
+	State string `json:"state"`
+
+	// ContentLength is task total content length.
+	ContentLength int64 `json:"content_length"`
+
+	// TotalPieceCount is task total piece count.
+	TotalPieceCount int32 `json:"total_piece_count"`
+
+	// UpdateAt is task update time.
+	UpdateAt time.Time `json:"update_at"`
+
+	// Peers is the peers of task ordered by depth.
+	Peers []*Node `json:"peers"`
+}
+
+// Node is the peer in peer tree.
+type Node struct {
+	// ID is peer id.
+	ID string `json:"id"`
+
+	// State is peer state.
+	State string `json:"state"`
+
+	// Depth is the depth of peer in peer tree.
+	Depth int `json:"depth"`
+
+	// FinishedPieceCount is the count of finished pieces.
+	FinishedPieceCount uint `json:"finished_piece_count"`
+
+	// IsBackToSource is whether peer downloads from source.
+	IsBackToSource bool `json:"is_back_to_source"`
+
+	// Parents is the parent ids of peer.
+	Parents []string `json:"parents"`
+
+	// UpdateAt is peer update time.
+	UpdateAt time.Time `json:"update_at"`
+
+	// Host is the host of peer.
+	Host *HostLoad `json:"host"`
+}
+
+// HostLoad is the load of host.
+type HostLoad struct {
+	// ID is host id.
+	ID string `json:"id"`
+
+	// Hostname is host name.
+	Hostname string `json:"hostname"`
+
+	// IP is host ip.
+	IP string `json:"ip"`
+
+	// IsSeed is whether host is seed peer.
+	IsSeed bool `json:"is_seed"`
+
+	// UploadPeerCount is upload peer count.
+	UploadPeerCount int32 `json:"upload_peer_count"`
+
+	// UploadLoadLimit is upload load limit count.
+	UploadLoadLimit int32 `json:"upload_load_limit"`
+
+	// CPURatio is cpu usage ratio reported by host.
+	CPURatio float64 `json:"cpu_ratio"`
+
+	// MemRatio is memory usage ratio reported by host.
+	MemRatio float64 `json:"mem_ratio"`
+
+	// DiskRatio is disk usage ratio reported by host.
+	DiskRatio float64 `json:"disk_ratio"`
+}
+
+// NewTree takes the snapshot of peer tree of task.
+func NewTree(task *resource.Task) *Tree {
+	tree := &Tree{
+		TaskID:          task.ID,
+		URL:             task.URL,
+		State:           task.FSM.Current(),
+		ContentLength:   task.ContentLength.Load(),
+		TotalPieceCount: task.TotalPieceCount.Load(),
+		UpdateAt:        task.UpdateAt.Load(),
+		Peers:           []*Node{},
+	}
+
+	for _, vertex := range task.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer == nil {
+			continue
+		}
+
+		parents := []string{}
+		for _, parent := range peer.Parents() {
+			parents = append(parents, parent.ID)
+		}
+		sort.Strings(parents)
+
+		node := &Node{
+			ID:                 peer.ID,
+			State:              peer.FSM.Current(),
+			Depth:              peer.Depth(),
+			FinishedPieceCount: peer.FinishedPieces.Count(),
+			IsBackToSource:     peer.IsBackToSource.Load(),
+			Parents:            parents,
+			UpdateAt:           peer.UpdateAt.Load(),
+		}
+
+		if host := peer.Host; host != nil {
+			node.Host = &HostLoad{
+				ID:              host.ID,
+				Hostname:        host.Hostname,
+				IP:              host.IP,
+				IsSeed:          host.Type != resource.HostTypeNormal,
+				UploadPeerCount: host.UploadPeerCount.Load(),
+				UploadLoadLimit: host.UploadLoadLimit.Load(),
+				CPURatio:        host.CPURatio.Load(),
+				MemRatio:        host.MemRatio.Load(),
+				DiskRatio:       host.DiskRatio.Load(),
+			}
+		}
+
+		tree.Peers = append(tree.Peers, node)
+	}
+
+	sort.Slice(tree.Peers, func(i, j int) bool {
+		if tree.Peers[i].Depth != tree.Peers[j].Depth {
+			return tree.Peers[i].Depth < tree.Peers[j].Depth
+		}
+
+		return tree.Peers[i].ID < tree.Peers[j].ID
+	})
+
+	return tree
+}
+
+// DOT renders peer tree in graphviz dot, edges point from parent to child.
+func (t *Tree) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %q {\n", t.TaskID)
+	fmt.Fprintf(&b, "  label=%q;\n", fmt.Sprintf("%s %s %d pieces", t.TaskID, t.State, t.TotalPieceCount))
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range t.Peers {
+		label := fmt.Sprintf("%s\\n%s depth=%d pieces=%d", node.ID, node.State, node.Depth, node.FinishedPieceCount)
+		if node.Host != nil {
+			label += fmt.Sprintf("\\n%s upload=%d/%d cpu=%.2f mem=%.2f",
+				node.Host.IP, node.Host.UploadPeerCount, node.Host.UploadLoadLimit, node.Host.CPURatio, node.Host.MemRatio)
+		}
+
+		fmt.Fprintf(&b, "  %q [label=\"%s\"];\n", node.ID, label)
+	}
+
+	for _, node := range t.Peers {
+		for _, parent := range node.Parents {
+			fmt.Fprintf(&b, "  %q -> %q;\n", parent, node.ID)
+		}
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// New returns the debug server which dumps peer trees of tasks.
+func New(cfg *config.DebugConfig, taskManager resource.TaskManager) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(TasksPath, func(w http.ResponseWriter, r *http.Request) {
+		handleTree(w, r, taskManager)
+	})
+
+	return &http.Server{
+		Addr:    cfg.Addr,
+		Handler: mux,
+	}
+}
+
+// handleTree serves the peer tree of task.
+func handleTree(w http.ResponseWriter, r *http.Request, taskManager resource.TaskManager) {
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, TasksPath)
+	if !strings.HasSuffix(path, treeSuffix) {
+		http.NotFound(w, r)
+		return
+	}
+
+	taskID := strings.TrimSuffix(path, treeSuffix)
+	if taskID == "" || strings.Contains(taskID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	task, ok := taskManager.Load(taskID)
+	if !ok {
+		http.Error(w, fmt.Sprintf("task %s not found", taskID), http.StatusNotFound)
+		return
+	}
+
+	tree := NewTree(task)
+	switch format := r.URL.Query().Get("format"); format {
+	case "", FormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(tree); err != nil {
+			logger.Errorf("encode peer tree of task %s failed: %s", taskID, err.Error())
+		}
+	case FormatDOT:
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+		if _, err := w.Write([]byte(tree.DOT())); err != nil {
+			logger.Errorf("write peer tree of task %s failed: %s", taskID, err.Error())
+		}
+	default:
+		http.Error(w, fmt.Sprintf("unsupported format %s", format), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+var (
+	mockRawHost = &schedulerv1.PeerHost{
+		Id:       idgen.HostID("hostname", 8003),
+		Ip:       "127.0.0.1",
+		RpcPort:  8003,
+		DownPort: 8001,
+		HostName: "hostname",
+	}
+
+	mockTaskURLMeta = &commonv1.UrlMeta{
+		Digest: "digest",
+		Tag:    "tag",
+	}
+
+	mockTaskURL = "http://example.com/foo"
+	mockTaskID  = idgen.TaskID(mockTaskURL, mockTaskURLMeta)
+)
+
+func newMockTask(t *testing.T) *resource.Task {
+	mockHost := resource.NewHost(mockRawHost)
+	mockHost.CPURatio.Store(0.5)
+
+	mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+	mockTask.TotalPieceCount.Store(4)
+
+	parent := resource.NewPeer("parent", mockTask, mockHost)
+	parent.FSM.SetState(resource.PeerStateSucceeded)
+	parent.FinishedPieces.Set(0).Set(1).Set(2).Set(3)
+	child := resource.NewPeer("child", mockTask, mockHost)
+	child.FSM.SetState(resource.PeerStateRunning)
+	child.FinishedPieces.Set(0)
+
+	mockTask.StorePeer(parent)
+	mockTask.StorePeer(child)
+	if err := mockTask.AddPeerEdge(parent, child); err != nil {
+		t.Fatal(err)
+	}
+
+	return mockTask
+}
+
+func TestTree_NewTree(t *testing.T) {
+	assert := assert.New(t)
+	tree := NewTree(newMockTask(t))
+	assert.Equal(tree.TaskID, mockTaskID)
+	assert.Equal(tree.TotalPieceCount, int32(4))
+	assert.Len(tree.Peers, 2)
+
+	parent := tree.Peers[0]
+	assert.Equal(parent.ID, "parent")
+	assert.Equal(parent.State, resource.PeerStateSucceeded)
+	assert.Equal(parent.Depth, 1)
+	assert.Equal(parent.FinishedPieceCount, uint(4))
+	assert.Empty(parent.Parents)
+	assert.Equal(parent.Host.IP, "127.0.0.1")
+	assert.Equal(parent.Host.UploadPeerCount, int32(1))
+	assert.Equal(parent.Host.CPURatio, 0.5)
+
+	child := tree.Peers[1]
+	assert.Equal(child.ID, "child")
+	assert.Equal(child.State, resource.PeerStateRunning)
+	assert.Equal(child.Depth, 2)
+	assert.Equal(child.FinishedPieceCount, uint(1))
+	assert.Equal(child.Parents, []string{"parent"})
+}
+
+func TestTree_DOT(t *testing.T) {
+	assert := assert.New(t)
+	dot := NewTree(newMockTask(t)).DOT()
+	assert.True(strings.HasPrefix(dot, "digraph "))
+	assert.Contains(dot, `"parent" -> "child";`)
+	assert.Contains(dot, "depth=2 pieces=1")
+	assert.Contains(dot, "upload=1/")
+}
+
+func TestDebug_New(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		mock   func(m *resource.MockTaskManagerMockRecorder, task *resource.Task)
+		expect func(t *testing.T, w *httptest.ResponseRecorder)
+	}{
+		{
+			name: "dump peer tree in json",
+			path: TasksPath + mockTaskID + "/tree",
+			mock: func(m *resource.MockTaskManagerMockRecorder, task *resource.Task) {
+				m.Load(gomock.Eq(mockTaskID)).Return(task, true).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(w.Code, http.StatusOK)
+
+				var tree Tree
+				assert.NoError(json.Unmarshal(w.Body.Bytes(), &tree))
+				assert.Equal(tree.TaskID, mockTaskID)
+				assert.Len(tree.Peers, 2)
+			},
+		},
+		{
+			name: "dump peer tree in dot",
+			path: TasksPath + mockTaskID + "/tree?format=dot",
+			mock: func(m *resource.MockTaskManagerMockRecorder, task *resource.Task) {
+				m.Load(gomock.Eq(mockTaskID)).Return(task, true).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(w.Code, http.StatusOK)
+				assert.Contains(w.Body.String(), `"parent" -> "child";`)
+			},
+		},
+		{
+			name: "dump peer tree in unsupported format",
+			path: TasksPath + mockTaskID + "/tree?format=yaml",
+			mock: func(m *resource.MockTaskManagerMockRecorder, task *resource.Task) {
+				m.Load(gomock.Eq(mockTaskID)).Return(task, true).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(w.Code, http.StatusBadRequest)
+			},
+		},
+		{
+			name: "task not found",
+			path: TasksPath + mockTaskID + "/tree",
+			mock: func(m *resource.MockTaskManagerMockRecorder, task *resource.Task) {
+				m.Load(gomock.Eq(mockTaskID)).Return(nil, false).Times(1)
+			},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(w.Code, http.StatusNotFound)
+			},
+		},
+		{
+			name: "invalid path",
+			path: TasksPath + mockTaskID,
+			mock: func(m *resource.MockTaskManagerMockRecorder, task *resource.Task) {},
+			expect: func(t *testing.T, w *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(w.Code, http.StatusNotFound)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			taskManager := resource.NewMockTaskManager(ctl)
+			tc.mock(taskManager.EXPECT(), newMockTask(t))
+
+			svr := New(&config.DebugConfig{Enable: true, Addr: ":8004"}, taskManager)
+			w := httptest.NewRecorder()
+			svr.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			tc.expect(t, w)
+		})
+	}
+}
@@ -21,21 +21,41 @@ package job
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
 	"github.com/go-http-utils/headers"
 	"github.com/go-playground/validator/v10"
 
 	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	dfdaemonv1 "d7y.io/api/pkg/apis/dfdaemon/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/internal/uploadauth"
+	"d7y.io/dragonfly/v2/pkg/dfnet"
 	"d7y.io/dragonfly/v2/pkg/idgen"
+	dfdaemonclient "d7y.io/dragonfly/v2/pkg/rpc/dfdaemon/client"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
+const (
+	// preheatProgressInterval is the min interval of reporting preheat progress.
+	preheatProgressInterval = 1 * time.Second
+
+	// pinTokenTTL is the ttl of token pinning the preheated task in seed peer.
+	pinTokenTTL = 1 * time.Minute
+)
+
 type Job interface {
 	Serve()
 	Stop()
@@ -94,11 +114,12 @@ func New(cfg *config.Config, resource resource.Resource) (Job, error) {
 	}
 
 	namedJobFuncs := map[string]any{
-		internaljob.PreheatJob: t.preheat,
+		internaljob.PreheatJob:    t.preheat,
+		internaljob.DeleteTaskJob: t.deleteTask,
 	}
 
 	if err := localJob.RegisterJob(namedJobFuncs); err != nil {
-		logger.Errorf("register preheat job to local queue error: %s", err.Error())
+		logger.Errorf("register jobs to local queue error: %s", err.Error())
 		return nil, err
 	}
 
@@ -179,16 +200,186 @@ func (j *job) preheat(ctx context.Context, req string) error {
 		return err
 	}
 
+	progress := &internaljob.PreheatProgress{
+		URL:               request.URL,
+		TaskID:            taskID,
+		SchedulerHostname: j.config.Server.Host,
+		State:             machineryv1tasks.StateStarted,
+	}
+	j.reportPreheatProgress(ctx, progress)
+
+	var reportedAt time.Time
 	for {
 		piece, err := stream.Recv()
 		if err != nil {
 			log.Errorf("preheat recive piece failed: %s", err.Error())
+			progress.State = machineryv1tasks.StateFailure
+			j.reportPreheatProgress(ctx, progress)
 			return err
 		}
 
+		if piece.HostId != "" {
+			progress.SeedPeerHostID = piece.HostId
+		}
+		if piece.TotalPieceCount > 0 {
+			progress.TotalPieceCount = piece.TotalPieceCount
+		}
+		if piece.PieceInfo != nil && piece.PieceInfo.PieceNum >= 0 {
+			progress.FinishedPieceCount++
+		}
+
 		if piece.Done == true {
+			if request.PinTTL != "" {
+				if err := j.pinTask(ctx, progress.SeedPeerHostID, taskID, request.PinTTL); err != nil {
+					log.Errorf("preheat pin task failed: %s", err.Error())
+					progress.State = machineryv1tasks.StateFailure
+					j.reportPreheatProgress(ctx, progress)
+					return err
+				}
+			}
+
 			log.Info("preheat succeeded")
+			progress.State = machineryv1tasks.StateSuccess
+			j.reportPreheatProgress(ctx, progress)
 			return nil
 		}
+
+		if time.Since(reportedAt) >= preheatProgressInterval {
+			j.reportPreheatProgress(ctx, progress)
+			reportedAt = time.Now()
+		}
+	}
+}
+
+// pinTask pins the preheated task in the upload server of seed peer,
+// so the task is not reclaimed by storage gc until ttl.
+func (j *job) pinTask(ctx context.Context, hostID, taskID, ttl string) error {
+	if j.config.SeedPeer.UploadAuthSecret == "" {
+		return errors.New("upload auth secret of seed peer is not configured")
+	}
+
+	host, ok := j.resource.HostManager().Load(hostID)
+	if !ok {
+		return fmt.Errorf("seed peer host %s not found", hostID)
+	}
+
+	token, err := uploadauth.GeneratePinToken(j.config.SeedPeer.UploadAuthSecret, taskID, pinTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	u := url.URL{
+		Scheme:   "http",
+		Host:     net.JoinHostPort(host.IP, strconv.Itoa(int(host.DownloadPort))),
+		Path:     fmt.Sprintf("/tasks/%s/pin", taskID),
+		RawQuery: url.Values{"ttl": []string{ttl}}.Encode(),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(headers.Authorization, uploadauth.BearerToken(token))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("pin task in seed peer %s failed: %s", hostID, resp.Status)
+	}
+
+	return nil
+}
+
+// reportPreheatProgress stores preheat progress of seed peer in the backend of local job queue,
+// then manager aggregates progresses of the group job.
+func (j *job) reportPreheatProgress(ctx context.Context, progress *internaljob.PreheatProgress) {
+	signature := machineryv1tasks.SignatureFromContext(ctx)
+	if signature == nil || signature.GroupUUID == "" {
+		return
+	}
+
+	if err := j.localJob.SetPreheatProgress(ctx, signature.GroupUUID, signature.UUID, progress); err != nil {
+		logger.WithTaskIDAndURL(progress.TaskID, progress.URL).Warnf("report preheat progress failed: %s", err.Error())
+	}
+}
+
+func (j *job) deleteTask(ctx context.Context, req string) error {
+	request := &internaljob.DeleteTaskRequest{}
+	if err := internaljob.UnmarshalRequest(req, request); err != nil {
+		logger.Errorf("unmarshal request err: %s, request body: %s", err.Error(), req)
+		return err
+	}
+
+	if err := validator.New().Struct(request); err != nil {
+		logger.Errorf("task %s validate failed: %s", request.TaskID, err.Error())
+		return err
+	}
+
+	log := logger.WithTaskID(request.TaskID)
+	task, ok := j.resource.TaskManager().Load(request.TaskID)
+	if !ok {
+		log.Info("task not found, skip delete")
+		return nil
+	}
+
+	// Evict the task from seed peers, then delete peers and task in scheduler.
+	var errs []string
+	for _, vertex := range task.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer.Host.Type != resource.HostTypeNormal {
+			if err := deleteTaskInSeedPeer(ctx, task, peer.Host); err != nil {
+				peer.Log.Errorf("delete task in seed peer failed: %s", err.Error())
+				errs = append(errs, err.Error())
+			}
+		}
+
+		// Downloading peers are failed and notified before deleted,
+		// otherwise they keep downloading the deleted task.
+		if peer.FSM.Is(resource.PeerStateRunning) || peer.FSM.Is(resource.PeerStateBackToSource) {
+			failPeer(peer)
+		}
+
+		j.resource.PeerManager().Delete(peer.ID)
+	}
+	j.resource.TaskManager().Delete(task.ID)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("delete task in seed peers failed: %s", strings.Join(errs, "; "))
+	}
+
+	log.Info("delete task succeeded")
+	return nil
+}
+
+// failPeer notifies the downloading peer that the task is deleted and fails the peer.
+func failPeer(peer *resource.Peer) {
+	if stream, ok := peer.LoadStream(); ok {
+		if err := stream.Send(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedTaskStatusError}); err != nil {
+			peer.Log.Errorf("send packet failed: %s", err.Error())
+		}
 	}
+
+	if err := peer.FSM.Event(resource.PeerEventDownloadFailed); err != nil {
+		peer.Log.Errorf("peer fsm event failed: %s", err.Error())
+	}
+}
+
+// deleteTaskInSeedPeer deletes task in the storage of seed peer.
+func deleteTaskInSeedPeer(ctx context.Context, task *resource.Task, host *resource.Host) error {
+	client, err := dfdaemonclient.GetClientByAddr([]dfnet.NetAddr{{
+		Type: dfnet.TCP,
+		Addr: net.JoinHostPort(host.IP, strconv.Itoa(int(host.Port))),
+	}})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.DeleteTask(ctx, &dfdaemonv1.DeleteTaskRequest{
+		Url:     task.URL,
+		UrlMeta: task.URLMeta,
+	})
 }
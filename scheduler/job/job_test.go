@@ -0,0 +1,269 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	"d7y.io/api/pkg/apis/scheduler/v1/mocks"
+
+	"d7y.io/dragonfly/v2/internal/uploadauth"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+var (
+	mockRawHost = &schedulerv1.PeerHost{
+		Id:             idgen.HostID("hostname", 8003),
+		Ip:             "127.0.0.1",
+		RpcPort:        8003,
+		DownPort:       8001,
+		HostName:       "hostname",
+		SecurityDomain: "security_domain",
+		Location:       "location",
+		Idc:            "idc",
+		NetTopology:    "net_topology",
+	}
+
+	mockRawSeedHost = &schedulerv1.PeerHost{
+		Id:             idgen.HostID("hostname_seed", 8003),
+		Ip:             "127.0.0.1",
+		RpcPort:        8003,
+		DownPort:       8001,
+		HostName:       "hostname",
+		SecurityDomain: "security_domain",
+		Location:       "location",
+		Idc:            "idc",
+		NetTopology:    "net_topology",
+	}
+
+	mockTaskURLMeta = &commonv1.UrlMeta{
+		Digest: "digest",
+		Tag:    "tag",
+		Range:  "range",
+		Filter: "filter",
+		Header: map[string]string{
+			"content-length": "100",
+		},
+	}
+
+	mockTaskURL    = "http://example.com/foo"
+	mockTaskID     = idgen.TaskID(mockTaskURL, mockTaskURLMeta)
+	mockPeerID     = idgen.PeerID("127.0.0.1")
+	mockSeedPeerID = idgen.SeedPeerID("127.0.0.1")
+)
+
+func TestJob_deleteTask(t *testing.T) {
+	tests := []struct {
+		name string
+		req  string
+		run  func(t *testing.T, j *job, peer, seedPeer *resource.Peer, stream *mocks.MockScheduler_ReportPieceResultServerMockRecorder, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, taskManager resource.TaskManager, peerManager resource.PeerManager)
+	}{
+		{
+			name: "invalid request",
+			run: func(t *testing.T, j *job, peer, seedPeer *resource.Peer, stream *mocks.MockScheduler_ReportPieceResultServerMockRecorder, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, taskManager resource.TaskManager, peerManager resource.PeerManager) {
+				assert := assert.New(t)
+				assert.Error(j.deleteTask(context.Background(), `{"task_id": ""}`))
+			},
+		},
+		{
+			name: "task not found",
+			run: func(t *testing.T, j *job, peer, seedPeer *resource.Peer, stream *mocks.MockScheduler_ReportPieceResultServerMockRecorder, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, taskManager resource.TaskManager, peerManager resource.PeerManager) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(nil, false).Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(j.deleteTask(context.Background(), `{"task_id": "`+mockTaskID+`"}`))
+			},
+		},
+		{
+			name: "fail and notify running peer before deleting task",
+			run: func(t *testing.T, j *job, peer, seedPeer *resource.Peer, stream *mocks.MockScheduler_ReportPieceResultServerMockRecorder, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, taskManager resource.TaskManager, peerManager resource.PeerManager) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(peer.Task, true).Times(1),
+					stream.Send(gomock.Eq(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedTaskStatusError})).Return(nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Delete(gomock.Eq(peer.ID)).Return().Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Delete(gomock.Eq(mockTaskID)).Return().Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(j.deleteTask(context.Background(), `{"task_id": "`+mockTaskID+`"}`))
+				assert.True(peer.FSM.Is(resource.PeerStateFailed))
+			},
+		},
+		{
+			name: "delete finished peer without notifying",
+			run: func(t *testing.T, j *job, peer, seedPeer *resource.Peer, stream *mocks.MockScheduler_ReportPieceResultServerMockRecorder, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, taskManager resource.TaskManager, peerManager resource.PeerManager) {
+				peer.FSM.SetState(resource.PeerStateSucceeded)
+				peer.Task.StorePeer(peer)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(peer.Task, true).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Delete(gomock.Eq(peer.ID)).Return().Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Delete(gomock.Eq(mockTaskID)).Return().Times(1),
+				)
+
+				assert := assert.New(t)
+				assert.NoError(j.deleteTask(context.Background(), `{"task_id": "`+mockTaskID+`"}`))
+				assert.True(peer.FSM.Is(resource.PeerStateSucceeded))
+			},
+		},
+		{
+			name: "delete task in seed peer failed",
+			run: func(t *testing.T, j *job, peer, seedPeer *resource.Peer, stream *mocks.MockScheduler_ReportPieceResultServerMockRecorder, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mp *resource.MockPeerManagerMockRecorder, taskManager resource.TaskManager, peerManager resource.PeerManager) {
+				seedPeer.FSM.SetState(resource.PeerStateSucceeded)
+				seedPeer.Task.StorePeer(seedPeer)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(seedPeer.Task, true).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Delete(gomock.Eq(seedPeer.ID)).Return().Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Delete(gomock.Eq(mockTaskID)).Return().Times(1),
+				)
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				assert := assert.New(t)
+				assert.Error(j.deleteTask(ctx, `{"task_id": "`+mockTaskID+`"}`))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			res := resource.NewMockResource(ctl)
+			taskManager := resource.NewMockTaskManager(ctl)
+			peerManager := resource.NewMockPeerManager(ctl)
+			stream := mocks.NewMockScheduler_ReportPieceResultServer(ctl)
+
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+			peer := resource.NewPeer(mockPeerID, mockTask, resource.NewHost(mockRawHost))
+			peer.StoreStream(stream)
+			seedPeer := resource.NewPeer(mockSeedPeerID, mockTask, resource.NewHost(mockRawSeedHost, resource.WithHostType(resource.HostTypeSuperSeed)))
+
+			tc.run(t, &job{resource: res}, peer, seedPeer, stream.EXPECT(), res.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT(), taskManager, peerManager)
+		})
+	}
+}
+
+func TestJob_pinTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		status  int
+		hostOK  bool
+		wantErr bool
+	}{
+		{
+			name:   "pin task succeeded",
+			secret: "secret",
+			status: http.StatusOK,
+			hostOK: true,
+		},
+		{
+			name:    "upload auth secret is not configured",
+			hostOK:  true,
+			wantErr: true,
+		},
+		{
+			name:    "seed peer host not found",
+			secret:  "secret",
+			wantErr: true,
+		},
+		{
+			name:    "seed peer rejects pinning",
+			secret:  "secret",
+			status:  http.StatusForbidden,
+			hostOK:  true,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			res := resource.NewMockResource(ctl)
+			hostManager := resource.NewMockHostManager(ctl)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert := assert.New(t)
+				assert.Equal(http.MethodPut, r.Method)
+				assert.Equal("/tasks/"+mockTaskID+"/pin", r.URL.Path)
+				assert.Equal("24h", r.URL.Query().Get("ttl"))
+
+				token, ok := uploadauth.ParseBearerToken(r.Header.Get("Authorization"))
+				assert.True(ok)
+				assert.NoError(uploadauth.VerifyPinToken(tc.secret, token, mockTaskID))
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			ip, port, err := net.SplitHostPort(server.Listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			downloadPort, err := strconv.Atoi(port)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			host := resource.NewHost(mockRawSeedHost, resource.WithHostType(resource.HostTypeSuperSeed))
+			host.IP = ip
+			host.DownloadPort = int32(downloadPort)
+			if tc.secret != "" {
+				res.EXPECT().HostManager().Return(hostManager).Times(1)
+				hostManager.EXPECT().Load(gomock.Eq(host.ID)).Return(host, tc.hostOK).Times(1)
+			}
+
+			j := &job{
+				resource: res,
+				config:   &config.Config{SeedPeer: &config.SeedPeerConfig{UploadAuthSecret: tc.secret}},
+			}
+			err = j.pinTask(context.Background(), host.ID, mockTaskID, "24h")
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -65,6 +65,51 @@ var (
 		Help:      "Counter of the number of failed of the register peer task.",
 	}, []string{"tag", "app"})
 
+	RegisterPeerTaskThrottledCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "register_peer_task_throttled_total",
+		Help:      "Counter of the number of the register peer task throttled by admission control.",
+	}, []string{"tag", "app"})
+
+	RegisterPeerTaskDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "register_peer_task_duration_milliseconds",
+		Help:      "Histogram of the time each peer task registering.",
+		Buckets:   []float64{1, 5, 10, 20, 50, 100, 200, 500, 1000, 2 * 1000, 5 * 1000},
+	}, []string{"tag", "app"})
+
+	ScheduleDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "schedule_duration_milliseconds",
+		Help:      "Histogram of the time each scheduling decision.",
+		Buckets:   []float64{0.1, 0.5, 1, 2, 5, 10, 20, 50, 100, 200, 500},
+	}, []string{"tag", "app"})
+
+	RescheduleCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "reschedule_total",
+		Help:      "Counter of the number of rescheduling after scheduling parent failed.",
+	}, []string{"tag", "app"})
+
+	ScheduleBackToSourceCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "schedule_back_to_source_total",
+		Help:      "Counter of the number of peers scheduled to download back-to-source.",
+	}, []string{"tag", "app"})
+
+	PeerDepth = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "peer_depth",
+		Help:      "Histogram of the depth of peer in the tree after scheduling parent successfully.",
+		Buckets:   []float64{1, 2, 3, 4, 5, 6, 8, 10, 15, 20},
+	}, []string{"tag", "app"})
+
 	DownloadCount = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: constants.MetricsNamespace,
 		Subsystem: constants.SchedulerMetricsName,
@@ -156,6 +201,34 @@ var (
 		Name:      "concurrent_schedule_total",
 		Help:      "Gauge of the number of concurrent of the scheduling.",
 	})
+
+	SeedPeerDemotedCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "seed_peer_demoted_total",
+		Help:      "Counter of the number of seed peers demoted by failed health probes.",
+	})
+
+	DemotedSeedPeerGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "demoted_seed_peer_total",
+		Help:      "Gauge of the number of demoted seed peers.",
+	})
+
+	TaskGCDeferredCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "task_gc_deferred_total",
+		Help:      "Counter of the number of expired tasks whose reclamation is deferred by active peers.",
+	})
+
+	PeerGCDeferredCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: constants.MetricsNamespace,
+		Subsystem: constants.SchedulerMetricsName,
+		Name:      "peer_gc_deferred_total",
+		Help:      "Counter of the number of expired peers whose reclamation is deferred by active children.",
+	})
 )
 
 func New(cfg *config.MetricsConfig, svr *grpc.Server) *http.Server {
@@ -22,12 +22,19 @@ import (
 
 	"go.uber.org/atomic"
 
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
 
+const (
+	// hostLoadTTL is the duration in which the reported load of host is valid,
+	// host reports load with piece results when it is downloading.
+	hostLoadTTL = time.Minute
+)
+
 type HostType int
 
 const (
@@ -102,6 +109,18 @@ type Host struct {
 	// UploadPeerCount is upload peer count.
 	UploadPeerCount *atomic.Int32
 
+	// CPURatio is cpu usage ratio reported by host.
+	CPURatio *atomic.Float64
+
+	// MemRatio is memory usage ratio reported by host.
+	MemRatio *atomic.Float64
+
+	// DiskRatio is disk usage ratio reported by host.
+	DiskRatio *atomic.Float64
+
+	// LoadUpdateAt is the time when host reported load.
+	LoadUpdateAt *atomic.Time
+
 	// Peer sync map.
 	Peers *sync.Map
 
@@ -133,6 +152,10 @@ func NewHost(rawHost *schedulerv1.PeerHost, options ...HostOption) *Host {
 		Location:        rawHost.Location,
 		UploadLoadLimit: atomic.NewInt32(config.DefaultClientLoadLimit),
 		UploadPeerCount: atomic.NewInt32(0),
+		CPURatio:        atomic.NewFloat64(0),
+		MemRatio:        atomic.NewFloat64(0),
+		DiskRatio:       atomic.NewFloat64(0),
+		LoadUpdateAt:    atomic.NewTime(time.Time{}),
 		Peers:           &sync.Map{},
 		PeerCount:       atomic.NewInt32(0),
 		CreateAt:        atomic.NewTime(time.Now()),
@@ -195,3 +218,38 @@ func (h *Host) LeavePeers() {
 func (h *Host) FreeUploadLoad() int32 {
 	return h.UploadLoadLimit.Load() - h.UploadPeerCount.Load()
 }
+
+// StoreLoad stores the load reported by host.
+func (h *Host) StoreLoad(load *commonv1.HostLoad) {
+	if load == nil {
+		return
+	}
+
+	h.CPURatio.Store(float64(load.CpuRatio))
+	h.MemRatio.Store(float64(load.MemRatio))
+	h.DiskRatio.Store(float64(load.DiskRatio))
+	h.LoadUpdateAt.Store(time.Now())
+}
+
+// LoadRatio returns the max usage ratio of cpu and memory in 0.0~1.0,
+// it returns 0 when the load is not reported or expired.
+func (h *Host) LoadRatio() float64 {
+	if time.Since(h.LoadUpdateAt.Load()) > hostLoadTTL {
+		return 0
+	}
+
+	ratio := h.CPURatio.Load()
+	if memRatio := h.MemRatio.Load(); memRatio > ratio {
+		ratio = memRatio
+	}
+
+	if ratio < 0 {
+		return 0
+	}
+
+	if ratio > 1 {
+		return 1
+	}
+
+	return ratio
+}
@@ -22,6 +22,8 @@ import (
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	pkggc "d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
@@ -46,8 +48,15 @@ type HostManager interface {
 	// Delete deletes host for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and host present in the map.
+	// If f returns false, range stops the iteration.
+	Range(f func(key, value any) bool)
+
 	// Try to reclaim host.
 	RunGC() error
+
+	// Reload applies host time to live and gc interval of gc config to the running host manager.
+	Reload(*config.GCConfig) error
 }
 
 type hostManager struct {
@@ -55,14 +64,18 @@ type hostManager struct {
 	*sync.Map
 
 	// Host time to live.
-	ttl time.Duration
+	ttl *atomic.Duration
+
+	// gc reclaims hosts periodically.
+	gc pkggc.GC
 }
 
 // New host manager interface.
 func newHostManager(cfg *config.GCConfig, gc pkggc.GC) (HostManager, error) {
 	h := &hostManager{
 		Map: &sync.Map{},
-		ttl: cfg.HostTTL,
+		ttl: atomic.NewDuration(cfg.HostTTL),
+		gc:  gc,
 	}
 
 	if err := gc.Add(pkggc.Task{
@@ -77,6 +90,21 @@ func newHostManager(cfg *config.GCConfig, gc pkggc.GC) (HostManager, error) {
 	return h, nil
 }
 
+// Reload applies host time to live and gc interval of gc config to the running host manager.
+func (h *hostManager) Reload(cfg *config.GCConfig) error {
+	if err := h.gc.Update(pkggc.Task{
+		ID:       GCHostID,
+		Interval: cfg.HostGCInterval,
+		Timeout:  cfg.HostGCInterval,
+		Runner:   h,
+	}); err != nil {
+		return err
+	}
+
+	h.ttl.Store(cfg.HostTTL)
+	return nil
+}
+
 func (h *hostManager) Load(key string) (*Host, bool) {
 	rawHost, ok := h.Map.Load(key)
 	if !ok {
@@ -104,7 +132,7 @@ func (h *hostManager) RunGC() error {
 		host := value.(*Host)
 		elapsed := time.Since(host.UpdateAt.Load())
 
-		if elapsed > h.ttl &&
+		if elapsed > h.ttl.Load() &&
 			host.PeerCount.Load() == 0 &&
 			host.UploadPeerCount.Load() == 0 &&
 			host.Type == HostTypeNormal {
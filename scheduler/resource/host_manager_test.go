@@ -395,3 +395,64 @@ func TestHostManager_RunGC(t *testing.T) {
 		})
 	}
 }
+
+func TestHostManager_Reload(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(t *testing.T, m *gc.MockGCMockRecorder)
+		expect func(t *testing.T, manager *hostManager, err error)
+	}{
+		{
+			name: "reload gc config",
+			mock: func(t *testing.T, m *gc.MockGCMockRecorder) {
+				gomock.InOrder(
+					m.Add(gomock.Any()).Return(nil).Times(1),
+					m.Update(gomock.Any()).Do(func(task gc.Task) {
+						assert := assert.New(t)
+						assert.Equal(GCHostID, task.ID)
+						assert.Equal(2*time.Second, task.Interval)
+					}).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, manager *hostManager, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(time.Minute, manager.ttl.Load())
+			},
+		},
+		{
+			name: "reload gc config failed because of gc error",
+			mock: func(t *testing.T, m *gc.MockGCMockRecorder) {
+				gomock.InOrder(
+					m.Add(gomock.Any()).Return(nil).Times(1),
+					m.Update(gomock.Any()).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, manager *hostManager, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+				assert.Equal(mockHostGCConfig.HostTTL, manager.ttl.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			tc.mock(t, gc.EXPECT())
+
+			manager, err := newHostManager(mockHostGCConfig, gc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = manager.Reload(&config.GCConfig{
+				HostGCInterval: 2 * time.Second,
+				HostTTL:        time.Minute,
+			})
+			tc.expect(t, manager.(*hostManager), err)
+		})
+	}
+}
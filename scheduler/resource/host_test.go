@@ -18,6 +18,7 @@ package resource
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -373,3 +374,62 @@ func TestHost_FreeUploadLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestHost_LoadRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		load   *commonv1.HostLoad
+		expect func(t *testing.T, host *Host)
+	}{
+		{
+			name: "load is not reported",
+			load: nil,
+			expect: func(t *testing.T, host *Host) {
+				assert := assert.New(t)
+				assert.Equal(float64(0), host.LoadRatio())
+			},
+		},
+		{
+			name: "cpu ratio is greater than memory ratio",
+			load: &commonv1.HostLoad{CpuRatio: 0.5, MemRatio: 0.25, DiskRatio: 0.75},
+			expect: func(t *testing.T, host *Host) {
+				assert := assert.New(t)
+				assert.Equal(float64(0.5), host.LoadRatio())
+				assert.Equal(float64(0.75), host.DiskRatio.Load())
+			},
+		},
+		{
+			name: "memory ratio is greater than cpu ratio",
+			load: &commonv1.HostLoad{CpuRatio: 0.25, MemRatio: 0.5},
+			expect: func(t *testing.T, host *Host) {
+				assert := assert.New(t)
+				assert.Equal(float64(0.5), host.LoadRatio())
+			},
+		},
+		{
+			name: "ratio is out of range",
+			load: &commonv1.HostLoad{CpuRatio: 2},
+			expect: func(t *testing.T, host *Host) {
+				assert := assert.New(t)
+				assert.Equal(float64(1), host.LoadRatio())
+			},
+		},
+		{
+			name: "load is expired",
+			load: &commonv1.HostLoad{CpuRatio: 0.5},
+			expect: func(t *testing.T, host *Host) {
+				assert := assert.New(t)
+				host.LoadUpdateAt.Store(time.Now().Add(-2 * hostLoadTTL))
+				assert.Equal(float64(0), host.LoadRatio())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := NewHost(mockRawHost)
+			host.StoreLoad(tc.load)
+			tc.expect(t, host)
+		})
+	}
+}
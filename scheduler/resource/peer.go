@@ -21,8 +21,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/bits-and-blooms/bitset"
@@ -34,6 +37,7 @@ import (
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/container/set"
+	"d7y.io/dragonfly/v2/pkg/dag"
 )
 
 const (
@@ -45,6 +49,9 @@ const (
 
 	// Download tiny file timeout.
 	downloadTinyFileContextTimeout = 30 * time.Second
+
+	// Depth limit of ancestors used to estimate the eta of peer.
+	etaDepthLimit = 4
 )
 
 const (
@@ -155,6 +162,10 @@ type Peer struct {
 	// BlockPeers is bad peer ids.
 	BlockPeers set.SafeSet[string]
 
+	// excludedParents is the expiration of parent ids excluded by hints of dfdaemon,
+	// key is parent id and value is expiration time.
+	excludedParents *sync.Map
+
 	// NeedBackToSource needs downloaded from source.
 	//
 	// When peer is registering, at the same time,
@@ -169,6 +180,13 @@ type Peer struct {
 	// IsBackToSource is set to true.
 	IsBackToSource *atomic.Bool
 
+	// DegradedCount is the count of consecutive evaluation windows
+	// in which downloading from parent is degraded.
+	DegradedCount *atomic.Int32
+
+	// ParentSwitchAt is the time when the parent of peer is switched.
+	ParentSwitchAt *atomic.Time
+
 	// CreateAt is peer create time.
 	CreateAt *atomic.Time
 
@@ -192,8 +210,11 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 		Task:             task,
 		Host:             host,
 		BlockPeers:       set.NewSafeSet[string](),
+		excludedParents:  &sync.Map{},
 		NeedBackToSource: atomic.NewBool(false),
 		IsBackToSource:   atomic.NewBool(false),
+		DegradedCount:    atomic.NewInt32(0),
+		ParentSwitchAt:   atomic.NewTime(time.Time{}),
 		CreateAt:         atomic.NewTime(time.Now()),
 		UpdateAt:         atomic.NewTime(time.Now()),
 		Log:              logger.WithTaskAndPeerID(task.ID, id),
@@ -254,7 +275,7 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 			},
 			PeerEventDownloadSucceeded: func(e *fsm.Event) {
 				if e.Src == PeerStateBackToSource {
-					p.Task.BackToSourcePeers.Delete(p.ID)
+					p.Task.ReleaseBackToSource(p.ID)
 				}
 
 				if err := p.Task.DeletePeerInEdges(p.ID); err != nil {
@@ -269,7 +290,7 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 			PeerEventDownloadFailed: func(e *fsm.Event) {
 				if e.Src == PeerStateBackToSource {
 					p.Task.PeerFailedCount.Inc()
-					p.Task.BackToSourcePeers.Delete(p.ID)
+					p.Task.ReleaseBackToSource(p.ID)
 				}
 
 				if err := p.Task.DeletePeerInEdges(p.ID); err != nil {
@@ -281,6 +302,10 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 				p.Log.Infof("peer state is %s", e.FSM.Current())
 			},
 			PeerEventLeave: func(e *fsm.Event) {
+				if e.Src == PeerStateBackToSource {
+					p.Task.ReleaseBackToSource(p.ID)
+				}
+
 				if err := p.Task.DeletePeerInEdges(p.ID); err != nil {
 					p.Log.Errorf("delete peer inedges failed: %s", err.Error())
 				}
@@ -298,6 +323,17 @@ func NewPeer(id string, task *Task, host *Host, options ...PeerOption) *Peer {
 	return p
 }
 
+// FinishPiece marks the piece finished and counts it as a replica of the task,
+// a piece which has been finished is not counted twice.
+func (p *Peer) FinishPiece(number int32) {
+	if p.FinishedPieces.Test(uint(number)) {
+		return
+	}
+
+	p.FinishedPieces.Set(uint(number))
+	p.Task.AddPieceReplica(number)
+}
+
 // AppendPieceCost append piece cost to costs slice.
 func (p *Peer) AppendPieceCost(cost int64) {
 	p.pieceCosts = append(p.pieceCosts, cost)
@@ -308,6 +344,34 @@ func (p *Peer) PieceCosts() []int64 {
 	return p.pieceCosts
 }
 
+// ExcludeParents excludes parents by ids until ttl expires.
+func (p *Peer) ExcludeParents(ids []string, ttl time.Duration) {
+	expireAt := time.Now().Add(ttl)
+	for _, id := range ids {
+		// Peer itself can not be its parent.
+		if id == p.ID {
+			continue
+		}
+
+		p.excludedParents.Store(id, expireAt)
+	}
+}
+
+// IsParentExcluded returns whether the parent is excluded and not expired.
+func (p *Peer) IsParentExcluded(id string) bool {
+	rawExpireAt, ok := p.excludedParents.Load(id)
+	if !ok {
+		return false
+	}
+
+	if time.Now().After(rawExpireAt.(time.Time)) {
+		p.excludedParents.Delete(id)
+		return false
+	}
+
+	return true
+}
+
 // LoadStream return grpc stream.
 func (p *Peer) LoadStream() (schedulerv1.Scheduler_ReportPieceResultServer, bool) {
 	rawStream := p.Stream.Load()
@@ -400,6 +464,98 @@ func (p *Peer) Children() []*Peer {
 	return children
 }
 
+// IsActive returns whether the peer is downloading, it is not done or left.
+func (p *Peer) IsActive() bool {
+	return !p.FSM.Is(PeerStateSucceeded) && !p.FSM.Is(PeerStateFailed) && !p.FSM.Is(PeerStateLeave)
+}
+
+// HasActiveChild returns whether any child of peer is downloading from it.
+func (p *Peer) HasActiveChild() bool {
+	for _, child := range p.Children() {
+		if child.IsActive() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Depth returns the depth of peer in the dag,
+// it is the number of vertices in the longest path from root to peer.
+func (p *Peer) Depth() int {
+	vertex, err := p.Task.DAG.GetVertex(p.ID)
+	if err != nil {
+		p.Log.Warn("can not find vertex in dag")
+		return 0
+	}
+
+	var depth int
+	vertices := map[string]*dag.Vertex[*Peer]{vertex.ID: vertex}
+	for len(vertices) > 0 {
+		depth++
+
+		parents := map[string]*dag.Vertex[*Peer]{}
+		for _, vertex := range vertices {
+			for _, parent := range vertex.Parents.Values() {
+				parents[parent.ID] = parent
+			}
+		}
+
+		vertices = parents
+	}
+
+	return depth
+}
+
+// ETA estimates the remaining time of downloading task. It is computed from the piece
+// completion rate of peer, and peer can not finish earlier than all of its parents, so it is
+// not earlier than the earliest eta of parents. ok is false when there is no enough information.
+func (p *Peer) ETA() (time.Duration, bool) {
+	return p.eta(etaDepthLimit)
+}
+
+func (p *Peer) eta(depth int) (time.Duration, bool) {
+	if p.FSM.Is(PeerStateSucceeded) {
+		return 0, true
+	}
+
+	var (
+		eta         time.Duration
+		ok          bool
+		totalPieces = int64(p.Task.TotalPieceCount.Load())
+		finished    = int64(p.FinishedPieces.Count())
+	)
+	if totalPieces > 0 && finished >= totalPieces {
+		return 0, true
+	}
+
+	if totalPieces > 0 && finished > 0 {
+		elapsed := time.Since(p.CreateAt.Load())
+		eta, ok = time.Duration(float64(elapsed)*float64(totalPieces-finished)/float64(finished)), true
+	}
+
+	// Peer downloading back-to-source does not depend on parents.
+	if depth <= 1 || p.FSM.Is(PeerStateBackToSource) {
+		return eta, ok
+	}
+
+	var (
+		parentETA time.Duration
+		parentOK  bool
+	)
+	for _, parent := range p.Parents() {
+		if e, ok := parent.eta(depth - 1); ok && (!parentOK || e < parentETA) {
+			parentETA, parentOK = e, true
+		}
+	}
+
+	if parentOK && (!ok || parentETA > eta) {
+		return parentETA, true
+	}
+
+	return eta, ok
+}
+
 // DownloadTinyFile downloads tiny file from peer.
 func (p *Peer) DownloadTinyFile() ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), downloadTinyFileContextTimeout)
@@ -408,7 +564,7 @@ func (p *Peer) DownloadTinyFile() ([]byte, error) {
 	// Download url: http://${host}:${port}/download/${taskIndex}/${taskID}?peerId=${peerID}
 	targetURL := url.URL{
 		Scheme:   "http",
-		Host:     fmt.Sprintf("%s:%d", p.Host.IP, p.Host.DownloadPort),
+		Host:     net.JoinHostPort(p.Host.IP, strconv.Itoa(int(p.Host.DownloadPort))),
 		Path:     fmt.Sprintf("download/%s/%s", p.Task.ID[:3], p.Task.ID),
 		RawQuery: fmt.Sprintf("peerId=%s", p.ID),
 	}
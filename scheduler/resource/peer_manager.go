@@ -22,8 +22,11 @@ import (
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	pkggc "d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
 )
 
 const (
@@ -46,8 +49,15 @@ type PeerManager interface {
 	// Delete deletes peer for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and peer present in the map.
+	// If f returns false, range stops the iteration.
+	Range(f func(key, value any) bool)
+
 	// Try to reclaim peer.
 	RunGC() error
+
+	// Reload applies peer time to live and gc interval of gc config to the running peer manager.
+	Reload(*config.GCConfig) error
 }
 
 type peerManager struct {
@@ -55,7 +65,10 @@ type peerManager struct {
 	*sync.Map
 
 	// Peer time to live.
-	ttl time.Duration
+	ttl *atomic.Duration
+
+	// gc reclaims peers periodically.
+	gc pkggc.GC
 
 	// Peer mutex.
 	mu *sync.Mutex
@@ -65,7 +78,8 @@ type peerManager struct {
 func newPeerManager(cfg *config.GCConfig, gc pkggc.GC) (PeerManager, error) {
 	p := &peerManager{
 		Map: &sync.Map{},
-		ttl: cfg.PeerTTL,
+		ttl: atomic.NewDuration(cfg.PeerTTL),
+		gc:  gc,
 		mu:  &sync.Mutex{},
 	}
 
@@ -81,6 +95,21 @@ func newPeerManager(cfg *config.GCConfig, gc pkggc.GC) (PeerManager, error) {
 	return p, nil
 }
 
+// Reload applies peer time to live and gc interval of gc config to the running peer manager.
+func (p *peerManager) Reload(cfg *config.GCConfig) error {
+	if err := p.gc.Update(pkggc.Task{
+		ID:       GCPeerID,
+		Interval: cfg.PeerGCInterval,
+		Timeout:  cfg.PeerGCInterval,
+		Runner:   p,
+	}); err != nil {
+		return err
+	}
+
+	p.ttl.Store(cfg.PeerTTL)
+	return nil
+}
+
 func (p *peerManager) Load(key string) (*Peer, bool) {
 	rawPeer, ok := p.Map.Load(key)
 	if !ok {
@@ -130,7 +159,7 @@ func (p *peerManager) RunGC() error {
 
 		// If the peer's elapsed exceeds the ttl,
 		// first set the peer state to PeerStateLeave and then delete peer.
-		if elapsed > p.ttl {
+		if elapsed > p.ttl.Load() {
 			// If the status is PeerStateLeave,
 			// clear peer information.
 			if peer.FSM.Is(PeerStateLeave) {
@@ -139,6 +168,14 @@ func (p *peerManager) RunGC() error {
 				return true
 			}
 
+			// If children are downloading from the peer,
+			// defer leaving to prevent evicting their parent mid-download.
+			if peer.HasActiveChild() {
+				metrics.PeerGCDeferredCount.Inc()
+				peer.Log.Debug("peer reclamation is deferred by active children")
+				return true
+			}
+
 			// If the peer is not leave,
 			// first change the state to PeerEventLeave.
 			if err := peer.FSM.Event(PeerEventLeave); err != nil {
@@ -411,6 +411,38 @@ func TestPeerManager_RunGC(t *testing.T) {
 				assert.Equal(ok, false)
 			},
 		},
+		{
+			name: "peer leave is deferred by active children",
+			gcConfig: &config.GCConfig{
+				PeerGCInterval: 1 * time.Second,
+				PeerTTL:        1 * time.Microsecond,
+			},
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, peerManager PeerManager, mockHost *Host, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				peerManager.Store(mockPeer)
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+				child := NewPeer(idgen.PeerID("127.0.0.1"), mockTask, mockHost)
+				child.FSM.SetState(PeerStateRunning)
+				mockTask.StorePeer(child)
+				assert.NoError(mockTask.AddPeerEdge(mockPeer, child))
+
+				err := peerManager.RunGC()
+				assert.NoError(err)
+				peer, ok := peerManager.Load(mockPeer.ID)
+				assert.Equal(ok, true)
+				assert.Equal(peer.FSM.Current(), PeerStateSucceeded)
+
+				child.FSM.SetState(PeerStateSucceeded)
+				err = peerManager.RunGC()
+				assert.NoError(err)
+				peer, ok = peerManager.Load(mockPeer.ID)
+				assert.Equal(ok, true)
+				assert.Equal(peer.FSM.Current(), PeerStateLeave)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -432,3 +464,64 @@ func TestPeerManager_RunGC(t *testing.T) {
 		})
 	}
 }
+
+func TestPeerManager_Reload(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(t *testing.T, m *gc.MockGCMockRecorder)
+		expect func(t *testing.T, manager *peerManager, err error)
+	}{
+		{
+			name: "reload gc config",
+			mock: func(t *testing.T, m *gc.MockGCMockRecorder) {
+				gomock.InOrder(
+					m.Add(gomock.Any()).Return(nil).Times(1),
+					m.Update(gomock.Any()).Do(func(task gc.Task) {
+						assert := assert.New(t)
+						assert.Equal(GCPeerID, task.ID)
+						assert.Equal(2*time.Second, task.Interval)
+					}).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, manager *peerManager, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(time.Minute, manager.ttl.Load())
+			},
+		},
+		{
+			name: "reload gc config failed because of gc error",
+			mock: func(t *testing.T, m *gc.MockGCMockRecorder) {
+				gomock.InOrder(
+					m.Add(gomock.Any()).Return(nil).Times(1),
+					m.Update(gomock.Any()).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, manager *peerManager, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+				assert.Equal(mockPeerGCConfig.PeerTTL, manager.ttl.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			tc.mock(t, gc.EXPECT())
+
+			manager, err := newPeerManager(mockPeerGCConfig, gc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = manager.Reload(&config.GCConfig{
+				PeerGCInterval: 2 * time.Second,
+				PeerTTL:        time.Minute,
+			})
+			tc.expect(t, manager.(*peerManager), err)
+		})
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"net/url"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/go-http-utils/headers"
 	"github.com/golang/mock/gomock"
@@ -169,6 +170,50 @@ func TestPeer_PieceCosts(t *testing.T) {
 	}
 }
 
+func TestPeer_ExcludeParents(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, peer *Peer)
+	}{
+		{
+			name: "exclude parents",
+			expect: func(t *testing.T, peer *Peer) {
+				assert := assert.New(t)
+				peer.ExcludeParents([]string{"foo", "bar"}, time.Minute)
+				assert.True(peer.IsParentExcluded("foo"))
+				assert.True(peer.IsParentExcluded("bar"))
+				assert.False(peer.IsParentExcluded("baz"))
+			},
+		},
+		{
+			name: "peer itself is not excluded",
+			expect: func(t *testing.T, peer *Peer) {
+				assert := assert.New(t)
+				peer.ExcludeParents([]string{peer.ID}, time.Minute)
+				assert.False(peer.IsParentExcluded(peer.ID))
+			},
+		},
+		{
+			name: "excluded parents expire",
+			expect: func(t *testing.T, peer *Peer) {
+				assert := assert.New(t)
+				peer.ExcludeParents([]string{"foo"}, -time.Second)
+				assert.False(peer.IsParentExcluded("foo"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHost := NewHost(mockRawHost)
+			mockTask := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := NewPeer(mockPeerID, mockTask, mockHost)
+
+			tc.expect(t, peer)
+		})
+	}
+}
+
 func TestPeer_LoadStream(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -426,6 +471,172 @@ func TestPeer_Children(t *testing.T) {
 	}
 }
 
+func TestPeer_Depth(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, peer *Peer, seedPeer *Peer)
+	}{
+		{
+			name: "peer is not in dag",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				assert.Equal(peer.Depth(), 0)
+			},
+		},
+		{
+			name: "peer has no parents",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.StorePeer(peer)
+				assert.Equal(peer.Depth(), 1)
+			},
+		},
+		{
+			name: "peer has parents",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(seedPeer)
+				if err := peer.Task.AddPeerEdge(seedPeer, peer); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(peer.Depth(), 2)
+				assert.Equal(seedPeer.Depth(), 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHost := NewHost(mockRawHost)
+			mockTask := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := NewPeer(mockPeerID, mockTask, mockHost)
+			seedPeer := NewPeer(mockSeedPeerID, mockTask, mockHost)
+			tc.expect(t, peer, seedPeer)
+		})
+	}
+}
+
+func TestPeer_ETA(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, peer *Peer, seedPeer *Peer)
+	}{
+		{
+			name: "peer has succeeded",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.FSM.SetState(PeerStateSucceeded)
+				eta, ok := peer.ETA()
+				assert.True(ok)
+				assert.Equal(time.Duration(0), eta)
+			},
+		},
+		{
+			name: "peer has no finished pieces",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.TotalPieceCount.Store(4)
+				_, ok := peer.ETA()
+				assert.False(ok)
+			},
+		},
+		{
+			name: "peer has finished all pieces",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.TotalPieceCount.Store(2)
+				peer.FinishedPieces.Set(0).Set(1)
+				eta, ok := peer.ETA()
+				assert.True(ok)
+				assert.Equal(time.Duration(0), eta)
+			},
+		},
+		{
+			name: "estimate by piece completion rate",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.TotalPieceCount.Store(4)
+				peer.FinishedPieces.Set(0).Set(1)
+				peer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				eta, ok := peer.ETA()
+				assert.True(ok)
+				assert.InDelta(10*time.Second, eta, float64(time.Second))
+			},
+		},
+		{
+			name: "peer is limited by slower parent",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(seedPeer)
+				if err := peer.Task.AddPeerEdge(seedPeer, peer); err != nil {
+					t.Fatal(err)
+				}
+
+				peer.Task.TotalPieceCount.Store(4)
+				peer.FinishedPieces.Set(0).Set(1)
+				peer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				seedPeer.FinishedPieces.Set(0)
+				seedPeer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				eta, ok := peer.ETA()
+				assert.True(ok)
+				assert.InDelta(30*time.Second, eta, float64(time.Second))
+			},
+		},
+		{
+			name: "peer without finished pieces is estimated by parent",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(seedPeer)
+				if err := peer.Task.AddPeerEdge(seedPeer, peer); err != nil {
+					t.Fatal(err)
+				}
+
+				peer.Task.TotalPieceCount.Store(4)
+				seedPeer.FinishedPieces.Set(0).Set(1)
+				seedPeer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				eta, ok := peer.ETA()
+				assert.True(ok)
+				assert.InDelta(10*time.Second, eta, float64(time.Second))
+			},
+		},
+		{
+			name: "peer downloading back-to-source does not depend on parents",
+			expect: func(t *testing.T, peer *Peer, seedPeer *Peer) {
+				assert := assert.New(t)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(seedPeer)
+				if err := peer.Task.AddPeerEdge(seedPeer, peer); err != nil {
+					t.Fatal(err)
+				}
+
+				peer.FSM.SetState(PeerStateBackToSource)
+				peer.Task.TotalPieceCount.Store(4)
+				peer.FinishedPieces.Set(0).Set(1)
+				peer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				seedPeer.FinishedPieces.Set(0)
+				seedPeer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				eta, ok := peer.ETA()
+				assert.True(ok)
+				assert.InDelta(10*time.Second, eta, float64(time.Second))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHost := NewHost(mockRawHost)
+			mockTask := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := NewPeer(mockPeerID, mockTask, mockHost)
+			seedPeer := NewPeer(mockSeedPeerID, mockTask, mockHost)
+			tc.expect(t, peer, seedPeer)
+		})
+	}
+}
+
 func TestPeer_DownloadTinyFile(t *testing.T) {
 	testData := []byte("./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz" +
 		"./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
@@ -0,0 +1,406 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination persistence_mock.go -source persistence.go -package resource
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/go-redis/redis/v8"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+const (
+	// persistenceTimeout is the timeout of snapshot and restore.
+	persistenceTimeout = 30 * time.Second
+)
+
+type Persistence interface {
+	// Snapshot saves hosts, tasks and peers to redis.
+	Snapshot(context.Context) error
+
+	// Restore loads hosts, tasks and peers from redis,
+	// it is called before scheduler serving.
+	Restore(context.Context) error
+
+	// Serve snapshots resource periodically.
+	Serve()
+
+	// Stop snapshots resource and stops serving.
+	Stop()
+}
+
+type persistence struct {
+	// Persistence configuration.
+	config *config.PersistenceConfig
+
+	// Redis key of snapshot.
+	key string
+
+	// Redis client.
+	rdb *redis.Client
+
+	// Resource interface.
+	resource Resource
+
+	// done channel.
+	done chan struct{}
+}
+
+// snapshot is the persisted metadata of hosts, tasks and peers,
+// grpc streams are not persisted and peers report them again after restart.
+type snapshot struct {
+	Hosts []*hostSnapshot `json:"hosts"`
+	Tasks []*taskSnapshot `json:"tasks"`
+	Peers []*peerSnapshot `json:"peers"`
+}
+
+type hostSnapshot struct {
+	ID              string    `json:"id"`
+	Type            HostType  `json:"type"`
+	IP              string    `json:"ip"`
+	Hostname        string    `json:"hostname"`
+	Port            int32     `json:"port"`
+	DownloadPort    int32     `json:"downloadPort"`
+	SecurityDomain  string    `json:"securityDomain"`
+	IDC             string    `json:"idc"`
+	NetTopology     string    `json:"netTopology"`
+	Location        string    `json:"location"`
+	UploadLoadLimit int32     `json:"uploadLoadLimit"`
+	CreateAt        time.Time `json:"createAt"`
+	UpdateAt        time.Time `json:"updateAt"`
+}
+
+type taskSnapshot struct {
+	ID                string                `json:"id"`
+	URL               string                `json:"url"`
+	Type              commonv1.TaskType     `json:"type"`
+	URLMeta           *commonv1.UrlMeta     `json:"urlMeta"`
+	Class             string                `json:"class"`
+	DirectPiece       []byte                `json:"directPiece"`
+	ContentLength     int64                 `json:"contentLength"`
+	TotalPieceCount   int32                 `json:"totalPieceCount"`
+	BackToSourceLimit int32                 `json:"backToSourceLimit"`
+	BackToSourcePeers []string              `json:"backToSourcePeers"`
+	Applications      []string              `json:"applications"`
+	State             string                `json:"state"`
+	Pieces            []*commonv1.PieceInfo `json:"pieces"`
+	PeerFailedCount   int32                 `json:"peerFailedCount"`
+	CreateAt          time.Time             `json:"createAt"`
+	UpdateAt          time.Time             `json:"updateAt"`
+}
+
+type peerSnapshot struct {
+	ID               string         `json:"id"`
+	Tag              string         `json:"tag"`
+	Application      string         `json:"application"`
+	TaskID           string         `json:"taskID"`
+	HostID           string         `json:"hostID"`
+	State            string         `json:"state"`
+	FinishedPieces   *bitset.BitSet `json:"finishedPieces"`
+	Parents          []string       `json:"parents"`
+	NeedBackToSource bool           `json:"needBackToSource"`
+	IsBackToSource   bool           `json:"isBackToSource"`
+	CreateAt         time.Time      `json:"createAt"`
+	UpdateAt         time.Time      `json:"updateAt"`
+}
+
+// NewPersistence returns persistence instance which stores snapshot in redis of job.
+func NewPersistence(cfg *config.Config, resource Resource) (Persistence, error) {
+	if cfg.Job == nil || cfg.Job.Redis == nil {
+		return nil, errors.New("persistence requires redis of job")
+	}
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Job.Redis.Host, cfg.Job.Redis.Port),
+		Password: cfg.Job.Redis.Password,
+		DB:       cfg.Persistence.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistenceTimeout)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	return &persistence{
+		config:   cfg.Persistence,
+		key:      makeSnapshotKey(cfg.Manager.SchedulerClusterID, cfg.Server.Host, cfg.Server.IP),
+		rdb:      rdb,
+		resource: resource,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Snapshot saves hosts, tasks and peers to redis.
+func (p *persistence) Snapshot(ctx context.Context) error {
+	b, err := json.Marshal(newSnapshot(p.resource))
+	if err != nil {
+		return err
+	}
+
+	return p.rdb.Set(ctx, p.key, b, p.config.TTL).Err()
+}
+
+// Restore loads hosts, tasks and peers from redis.
+func (p *persistence) Restore(ctx context.Context) error {
+	b, err := p.rdb.Get(ctx, p.key).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			logger.Info("snapshot of resource not found")
+			return nil
+		}
+
+		return err
+	}
+
+	s := &snapshot{}
+	if err := json.Unmarshal(b, s); err != nil {
+		return err
+	}
+
+	s.restore(p.resource)
+	logger.Infof("restore %d hosts, %d tasks and %d peers from snapshot", len(s.Hosts), len(s.Tasks), len(s.Peers))
+	return nil
+}
+
+// Serve snapshots resource periodically.
+func (p *persistence) Serve() {
+	tick := time.NewTicker(p.config.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			ctx, cancel := context.WithTimeout(context.Background(), persistenceTimeout)
+			if err := p.Snapshot(ctx); err != nil {
+				logger.Errorf("snapshot resource failed: %s", err.Error())
+			}
+			cancel()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// Stop snapshots resource and stops serving.
+func (p *persistence) Stop() {
+	close(p.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), persistenceTimeout)
+	defer cancel()
+	if err := p.Snapshot(ctx); err != nil {
+		logger.Errorf("snapshot resource failed: %s", err.Error())
+	}
+
+	if err := p.rdb.Close(); err != nil {
+		logger.Errorf("close redis client failed: %s", err.Error())
+	}
+}
+
+// newSnapshot returns snapshot of resource, peers which have left are not persisted.
+func newSnapshot(resource Resource) *snapshot {
+	s := &snapshot{}
+	resource.HostManager().Range(func(_, value any) bool {
+		host := value.(*Host)
+		s.Hosts = append(s.Hosts, &hostSnapshot{
+			ID:              host.ID,
+			Type:            host.Type,
+			IP:              host.IP,
+			Hostname:        host.Hostname,
+			Port:            host.Port,
+			DownloadPort:    host.DownloadPort,
+			SecurityDomain:  host.SecurityDomain,
+			IDC:             host.IDC,
+			NetTopology:     host.NetTopology,
+			Location:        host.Location,
+			UploadLoadLimit: host.UploadLoadLimit.Load(),
+			CreateAt:        host.CreateAt.Load(),
+			UpdateAt:        host.UpdateAt.Load(),
+		})
+
+		return true
+	})
+
+	resource.TaskManager().Range(func(_, value any) bool {
+		task := value.(*Task)
+		t := &taskSnapshot{
+			ID:                task.ID,
+			URL:               task.URL,
+			Type:              task.Type,
+			URLMeta:           task.URLMeta,
+			Class:             task.Class,
+			DirectPiece:       task.DirectPiece,
+			ContentLength:     task.ContentLength.Load(),
+			TotalPieceCount:   task.TotalPieceCount.Load(),
+			BackToSourceLimit: task.BackToSourceLimit.Load(),
+			BackToSourcePeers: task.BackToSourcePeers.Values(),
+			Applications:      task.Applications.Values(),
+			State:             task.FSM.Current(),
+			PeerFailedCount:   task.PeerFailedCount.Load(),
+			CreateAt:          task.CreateAt.Load(),
+			UpdateAt:          task.UpdateAt.Load(),
+		}
+
+		task.Pieces.Range(func(_, value any) bool {
+			t.Pieces = append(t.Pieces, value.(*commonv1.PieceInfo))
+			return true
+		})
+
+		s.Tasks = append(s.Tasks, t)
+		return true
+	})
+
+	resource.PeerManager().Range(func(_, value any) bool {
+		peer := value.(*Peer)
+		if peer.FSM.Is(PeerStateLeave) {
+			return true
+		}
+
+		p := &peerSnapshot{
+			ID:               peer.ID,
+			Tag:              peer.Tag,
+			Application:      peer.Application,
+			TaskID:           peer.Task.ID,
+			HostID:           peer.Host.ID,
+			State:            peer.FSM.Current(),
+			FinishedPieces:   peer.FinishedPieces.Clone(),
+			NeedBackToSource: peer.NeedBackToSource.Load(),
+			IsBackToSource:   peer.IsBackToSource.Load(),
+			CreateAt:         peer.CreateAt.Load(),
+			UpdateAt:         peer.UpdateAt.Load(),
+		}
+
+		for _, parent := range peer.Parents() {
+			p.Parents = append(p.Parents, parent.ID)
+		}
+
+		s.Peers = append(s.Peers, p)
+		return true
+	})
+
+	return s
+}
+
+// restore stores hosts, tasks and peers of snapshot into resource,
+// edges between peers are restored after all peers are stored.
+func (s *snapshot) restore(resource Resource) {
+	for _, h := range s.Hosts {
+		host := NewHost(&schedulerv1.PeerHost{
+			Id:             h.ID,
+			Ip:             h.IP,
+			HostName:       h.Hostname,
+			RpcPort:        h.Port,
+			DownPort:       h.DownloadPort,
+			SecurityDomain: h.SecurityDomain,
+			Idc:            h.IDC,
+			NetTopology:    h.NetTopology,
+			Location:       h.Location,
+		}, WithHostType(h.Type), WithUploadLoadLimit(h.UploadLoadLimit))
+		host.CreateAt.Store(h.CreateAt)
+		host.UpdateAt.Store(h.UpdateAt)
+		resource.HostManager().Store(host)
+	}
+
+	for _, t := range s.Tasks {
+		task := NewTask(t.ID, t.URL, t.Type, t.URLMeta, WithBackToSourceLimit(t.BackToSourceLimit), WithClass(t.Class))
+		task.DirectPiece = t.DirectPiece
+		task.ContentLength.Store(t.ContentLength)
+		task.TotalPieceCount.Store(t.TotalPieceCount)
+		task.PeerFailedCount.Store(t.PeerFailedCount)
+		task.FSM.SetState(t.State)
+		for _, peerID := range t.BackToSourcePeers {
+			task.BackToSourcePeers.Add(peerID)
+		}
+
+		for _, application := range t.Applications {
+			task.Applications.Add(application)
+		}
+
+		for _, piece := range t.Pieces {
+			task.StorePiece(piece)
+		}
+
+		task.CreateAt.Store(t.CreateAt)
+		task.UpdateAt.Store(t.UpdateAt)
+		resource.TaskManager().Store(task)
+	}
+
+	var restoredPeers []*peerSnapshot
+	for _, p := range s.Peers {
+		task, ok := resource.TaskManager().Load(p.TaskID)
+		if !ok {
+			logger.Warnf("task %s of peer %s not found in snapshot", p.TaskID, p.ID)
+			continue
+		}
+
+		host, ok := resource.HostManager().Load(p.HostID)
+		if !ok {
+			logger.Warnf("host %s of peer %s not found in snapshot", p.HostID, p.ID)
+			continue
+		}
+
+		peer := NewPeer(p.ID, task, host, WithTag(p.Tag), WithApplication(p.Application))
+		peer.FSM.SetState(p.State)
+		if p.FinishedPieces != nil {
+			for number, ok := p.FinishedPieces.NextSet(0); ok; number, ok = p.FinishedPieces.NextSet(number + 1) {
+				peer.FinishPiece(int32(number))
+			}
+		}
+		peer.NeedBackToSource.Store(p.NeedBackToSource)
+		peer.IsBackToSource.Store(p.IsBackToSource)
+		peer.CreateAt.Store(p.CreateAt)
+		peer.UpdateAt.Store(p.UpdateAt)
+
+		resource.PeerManager().Store(peer)
+		restoredPeers = append(restoredPeers, p)
+	}
+
+	for _, p := range restoredPeers {
+		peer, ok := resource.PeerManager().Load(p.ID)
+		if !ok {
+			continue
+		}
+
+		for _, parentID := range p.Parents {
+			parent, ok := resource.PeerManager().Load(parentID)
+			if !ok {
+				continue
+			}
+
+			if err := peer.Task.AddPeerEdge(parent, peer); err != nil {
+				peer.Log.Warnf("restore edge from parent %s failed: %s", parentID, err.Error())
+			}
+		}
+	}
+}
+
+// makeSnapshotKey returns redis key of snapshot for the scheduler.
+func makeSnapshotKey(clusterID uint, hostname, ip string) string {
+	return fmt.Sprintf("scheduler:%d-%s-%s:snapshot", clusterID, hostname, ip)
+}
@@ -0,0 +1,171 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"encoding/json"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/pkg/gc"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+)
+
+func newMockPersistenceResource(t *testing.T, ctl *gomock.Controller) Resource {
+	gc := gc.NewMockGC(ctl)
+	gc.EXPECT().Add(gomock.Any()).Return(nil).AnyTimes()
+
+	hostManager, err := newHostManager(mockHostGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &resource{
+		hostManager: hostManager,
+		taskManager: taskManager,
+		peerManager: peerManager,
+	}
+}
+
+func TestPersistence_Snapshot(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(r Resource)
+		expect func(t *testing.T, r Resource)
+	}{
+		{
+			name: "restore hosts, tasks and peers",
+			mock: func(r Resource) {
+				host := NewHost(mockRawHost)
+				task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
+				task.FSM.SetState(TaskStateSucceeded)
+				task.TotalPieceCount.Store(2)
+				task.StorePiece(&commonv1.PieceInfo{PieceNum: 1, RangeSize: 1024})
+
+				parent := NewPeer(mockSeedPeerID, task, host)
+				parent.FSM.SetState(PeerStateSucceeded)
+				parent.FinishedPieces.Set(0).Set(1)
+				peer := NewPeer(mockPeerID, task, host)
+				peer.FSM.SetState(PeerStateRunning)
+				peer.FinishedPieces.Set(1)
+				task.StorePeer(parent)
+				task.StorePeer(peer)
+				if err := task.AddPeerEdge(parent, peer); err != nil {
+					t.Fatal(err)
+				}
+
+				r.HostManager().Store(host)
+				r.TaskManager().Store(task)
+				r.PeerManager().Store(parent)
+				r.PeerManager().Store(peer)
+			},
+			expect: func(t *testing.T, r Resource) {
+				assert := assert.New(t)
+				host, ok := r.HostManager().Load(mockRawHost.Id)
+				assert.True(ok)
+				assert.Equal(mockRawHost.Ip, host.IP)
+				assert.Equal(int32(2), host.PeerCount.Load())
+
+				task, ok := r.TaskManager().Load(mockTaskID)
+				assert.True(ok)
+				assert.True(task.FSM.Is(TaskStateSucceeded))
+				assert.Equal(int32(2), task.TotalPieceCount.Load())
+				assert.Equal(mockTaskBackToSourceLimit, task.BackToSourceLimit.Load())
+				piece, ok := task.LoadPiece(1)
+				assert.True(ok)
+				assert.Equal(uint32(1024), piece.RangeSize)
+
+				peer, ok := r.PeerManager().Load(mockPeerID)
+				assert.True(ok)
+				assert.True(peer.FSM.Is(PeerStateRunning))
+				assert.True(peer.FinishedPieces.Test(1))
+				assert.False(peer.FinishedPieces.Test(0))
+				parents := peer.Parents()
+				assert.Len(parents, 1)
+				assert.Equal(mockSeedPeerID, parents[0].ID)
+			},
+		},
+		{
+			name: "peer has left",
+			mock: func(r Resource) {
+				host := NewHost(mockRawHost)
+				task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+				peer := NewPeer(idgen.PeerID("127.0.0.1"), task, host)
+				peer.FSM.SetState(PeerStateLeave)
+
+				r.HostManager().Store(host)
+				r.TaskManager().Store(task)
+				r.PeerManager().Store(peer)
+			},
+			expect: func(t *testing.T, r Resource) {
+				assert := assert.New(t)
+				_, ok := r.TaskManager().Load(mockTaskID)
+				assert.True(ok)
+
+				count := 0
+				r.PeerManager().Range(func(_, _ any) bool {
+					count++
+					return true
+				})
+				assert.Equal(0, count)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			src := newMockPersistenceResource(t, ctl)
+			tc.mock(src)
+
+			b, err := json.Marshal(newSnapshot(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			s := &snapshot{}
+			if err := json.Unmarshal(b, s); err != nil {
+				t.Fatal(err)
+			}
+
+			dst := newMockPersistenceResource(t, ctl)
+			s.restore(dst)
+			tc.expect(t, dst)
+		})
+	}
+}
+
+func TestPersistence_MakeSnapshotKey(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("scheduler:1-foo-127.0.0.1:snapshot", makeSnapshotKey(1, "foo", "127.0.0.1"))
+}
@@ -19,8 +19,13 @@
 package resource
 
 import (
+	"reflect"
+	"time"
+
 	"google.golang.org/grpc"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
@@ -37,6 +42,9 @@ type Resource interface {
 
 	// Task manager interface.
 	TaskManager() TaskManager
+
+	// OnNotify reloads gc config and client load limit of scheduler cluster in dynconfig.
+	OnNotify(*config.DynconfigData)
 }
 
 type resource struct {
@@ -51,10 +59,27 @@ type resource struct {
 
 	// Task manager interface.
 	taskManager TaskManager
+
+	// Scheduler dynamic configuration.
+	dynconfig config.DynconfigInterface
+
+	// gcConfig is the gc config of scheduler, it is overridden by scheduler cluster.
+	gcConfig config.GCConfig
+
+	// reloadedGCConfig is the gc config applied to managers last time.
+	reloadedGCConfig config.GCConfig
+
+	// clientLoadLimit is the upload load limit applied to normal hosts last time.
+	clientLoadLimit int32
 }
 
 func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, opts ...grpc.DialOption) (Resource, error) {
-	resource := &resource{}
+	resource := &resource{
+		dynconfig:        dynconfig,
+		gcConfig:         *cfg.Scheduler.GC,
+		reloadedGCConfig: *cfg.Scheduler.GC,
+		clientLoadLimit:  config.DefaultClientLoadLimit,
+	}
 
 	// Initialize host manager interface.
 	hostManager, err := newHostManager(cfg.Scheduler.GC, gc)
@@ -85,6 +110,13 @@ func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, opts
 		}
 
 		resource.seedPeer = newSeedPeer(client, peerManager, hostManager)
+
+		// Initialize seed peer prober, unhealthy seed peers are demoted.
+		if cfg.SeedPeer.HealthProbe.Enable {
+			if _, err := newSeedPeerProber(cfg.SeedPeer.HealthProbe, dynconfig, gc); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return resource, nil
@@ -105,3 +137,68 @@ func (r *resource) TaskManager() TaskManager {
 func (r *resource) PeerManager() PeerManager {
 	return r.peerManager
 }
+
+// OnNotify reloads gc config and client load limit of scheduler cluster in dynconfig,
+// the changes are applied to the running managers and hosts without restarting.
+func (r *resource) OnNotify(data *config.DynconfigData) {
+	gcConfig := r.gcConfig
+	if clusterConfig, ok := r.dynconfig.GetSchedulerClusterConfig(); ok && clusterConfig.GC != nil {
+		overrideGCConfig(&gcConfig, clusterConfig.GC)
+	}
+
+	if !reflect.DeepEqual(gcConfig, r.reloadedGCConfig) {
+		if err := r.reloadGCConfig(&gcConfig); err != nil {
+			logger.Errorf("reload gc config failed: %s", err.Error())
+		} else {
+			logger.Infof("gc config is reloaded: %#v", gcConfig)
+			r.reloadedGCConfig = gcConfig
+		}
+	}
+
+	clientLoadLimit := int32(config.DefaultClientLoadLimit)
+	if clientConfig, ok := r.dynconfig.GetSchedulerClusterClientConfig(); ok && clientConfig.LoadLimit > 0 {
+		clientLoadLimit = int32(clientConfig.LoadLimit)
+	}
+
+	if clientLoadLimit != r.clientLoadLimit {
+		r.hostManager.Range(func(_, value any) bool {
+			if host, ok := value.(*Host); ok && host.Type == HostTypeNormal {
+				host.UploadLoadLimit.Store(clientLoadLimit)
+			}
+
+			return true
+		})
+
+		logger.Infof("client load limit is reloaded: %d", clientLoadLimit)
+		r.clientLoadLimit = clientLoadLimit
+	}
+}
+
+// reloadGCConfig applies gc config to managers.
+func (r *resource) reloadGCConfig(cfg *config.GCConfig) error {
+	if err := r.hostManager.Reload(cfg); err != nil {
+		return err
+	}
+
+	if err := r.taskManager.Reload(cfg); err != nil {
+		return err
+	}
+
+	return r.peerManager.Reload(cfg)
+}
+
+// overrideGCConfig overrides gc config with non-zero values of scheduler cluster gc config.
+func overrideGCConfig(cfg *config.GCConfig, clusterConfig *types.SchedulerClusterGCConfig) {
+	override := func(d *time.Duration, seconds uint32) {
+		if seconds > 0 {
+			*d = time.Duration(seconds) * time.Second
+		}
+	}
+
+	override(&cfg.PeerGCInterval, clusterConfig.PeerGCInterval)
+	override(&cfg.PeerTTL, clusterConfig.PeerTTL)
+	override(&cfg.TaskGCInterval, clusterConfig.TaskGCInterval)
+	override(&cfg.TaskTTL, clusterConfig.TaskTTL)
+	override(&cfg.HostGCInterval, clusterConfig.HostGCInterval)
+	override(&cfg.HostTTL, clusterConfig.HostTTL)
+}
@@ -7,6 +7,7 @@ package resource
 import (
 	reflect "reflect"
 
+	config "d7y.io/dragonfly/v2/scheduler/config"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -47,6 +48,18 @@ func (mr *MockResourceMockRecorder) HostManager() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HostManager", reflect.TypeOf((*MockResource)(nil).HostManager))
 }
 
+// OnNotify mocks base method.
+func (m *MockResource) OnNotify(arg0 *config.DynconfigData) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnNotify", arg0)
+}
+
+// OnNotify indicates an expected call of OnNotify.
+func (mr *MockResourceMockRecorder) OnNotify(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnNotify", reflect.TypeOf((*MockResource)(nil).OnNotify), arg0)
+}
+
 // PeerManager mocks base method.
 func (m *MockResource) PeerManager() PeerManager {
 	m.ctrl.T.Helper()
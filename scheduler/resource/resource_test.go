@@ -20,10 +20,12 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
+	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
@@ -169,3 +171,89 @@ func TestResource_New(t *testing.T) {
 		})
 	}
 }
+
+func TestResource_OnNotify(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(gc *gc.MockGCMockRecorder, dynconfig *configmocks.MockDynconfigInterfaceMockRecorder)
+		expect func(t *testing.T, resource *resource, host *Host, seedHost *Host)
+	}{
+		{
+			name: "reload gc config and client load limit",
+			mock: func(gc *gc.MockGCMockRecorder, dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				gomock.InOrder(
+					dynconfig.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+						GC: &types.SchedulerClusterGCConfig{PeerTTL: 60, TaskGCInterval: 120},
+					}, true).Times(1),
+					gc.Update(gomock.Any()).Return(nil).Times(3),
+					dynconfig.GetSchedulerClusterClientConfig().Return(types.SchedulerClusterClientConfig{LoadLimit: 10}, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resource *resource, host *Host, seedHost *Host) {
+				assert := assert.New(t)
+				assert.Equal(time.Minute, resource.reloadedGCConfig.PeerTTL)
+				assert.Equal(2*time.Minute, resource.reloadedGCConfig.TaskGCInterval)
+				assert.Equal(resource.gcConfig.HostTTL, resource.reloadedGCConfig.HostTTL)
+				assert.Equal(time.Minute, resource.peerManager.(*peerManager).ttl.Load())
+				assert.Equal(int32(10), host.UploadLoadLimit.Load())
+				assert.Equal(int32(config.DefaultSeedPeerLoadLimit), seedHost.UploadLoadLimit.Load())
+			},
+		},
+		{
+			name: "scheduler cluster does not change",
+			mock: func(gc *gc.MockGCMockRecorder, dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				gomock.InOrder(
+					dynconfig.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, true).Times(1),
+					dynconfig.GetSchedulerClusterClientConfig().Return(types.SchedulerClusterClientConfig{}, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resource *resource, host *Host, seedHost *Host) {
+				assert := assert.New(t)
+				assert.Equal(resource.gcConfig, resource.reloadedGCConfig)
+				assert.Equal(int32(config.DefaultClientLoadLimit), host.UploadLoadLimit.Load())
+			},
+		},
+		{
+			name: "reload gc config failed because of gc error",
+			mock: func(gc *gc.MockGCMockRecorder, dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				gomock.InOrder(
+					dynconfig.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+						GC: &types.SchedulerClusterGCConfig{HostTTL: 60},
+					}, true).Times(1),
+					gc.Update(gomock.Any()).Return(errors.New("foo")).Times(1),
+					dynconfig.GetSchedulerClusterClientConfig().Return(types.SchedulerClusterClientConfig{}, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, resource *resource, host *Host, seedHost *Host) {
+				assert := assert.New(t)
+				assert.Equal(resource.gcConfig, resource.reloadedGCConfig)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+
+			cfg := config.New()
+			cfg.SeedPeer.Enable = false
+			gc.EXPECT().Add(gomock.Any()).Return(nil).Times(3)
+			res, err := New(cfg, gc, dynconfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			host := NewHost(mockRawHost)
+			seedHost := NewHost(mockRawSeedHost, WithHostType(HostTypeSuperSeed), WithUploadLoadLimit(config.DefaultSeedPeerLoadLimit))
+			res.HostManager().Store(host)
+			res.HostManager().Store(seedHost)
+
+			tc.mock(gc.EXPECT(), dynconfig.EXPECT())
+			res.OnNotify(&config.DynconfigData{})
+			tc.expect(t, res.(*resource), host, seedHost)
+		})
+	}
+}
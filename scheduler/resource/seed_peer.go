@@ -41,6 +41,10 @@ const (
 const (
 	// Default value of seed peer failed timeout.
 	SeedPeerFailedTimeout = 30 * time.Minute
+
+	// Default value of retry limit when seed peer is busy,
+	// the retried seed task is triggered in the next seed peer.
+	SeedPeerBusyRetryLimit = 3
 )
 
 type SeedPeer interface {
@@ -74,11 +78,12 @@ func (s *seedPeer) TriggerTask(ctx context.Context, task *Task) (*Peer, *schedul
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	stream, err := s.client.ObtainSeeds(ctx, &cdnsystemv1.SeedRequest{
+	req := &cdnsystemv1.SeedRequest{
 		TaskId:  task.ID,
 		Url:     task.URL,
 		UrlMeta: task.URLMeta,
-	})
+	}
+	stream, err := s.client.ObtainSeeds(ctx, req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -86,11 +91,31 @@ func (s *seedPeer) TriggerTask(ctx context.Context, task *Task) (*Peer, *schedul
 	var (
 		peer        *Peer
 		initialized bool
+		busyRetries int
 	)
 
 	for {
 		piece, err := stream.Recv()
 		if err != nil {
+			// Seed peer above the watermark rejects the task before any piece is sent, the balancer
+			// skips the busy seed peer, so the retried task is triggered in another seed peer.
+			if !initialized && common.IsSeedPeerBusy(err) && busyRetries < SeedPeerBusyRetryLimit {
+				busyRetries++
+				if md, e := stream.Header(); e == nil {
+					if capacity, ok := common.ParseSeedCapacity(md); ok {
+						task.Log.Warnf("seed peer is busy with free bandwidth %d and disk used percent %.2f",
+							capacity.FreeBandwidth, capacity.DiskUsedPercent)
+					}
+				}
+
+				task.Log.Warnf("seed peer is busy, retry %d times: %s", busyRetries, err.Error())
+				if stream, err = s.client.ObtainSeeds(ctx, req); err != nil {
+					return nil, nil, err
+				}
+
+				continue
+			}
+
 			// If the peer initialization succeeds and the download fails,
 			// set peer status is PeerStateFailed.
 			if peer != nil {
@@ -110,6 +135,13 @@ func (s *seedPeer) TriggerTask(ctx context.Context, task *Task) (*Peer, *schedul
 			if err != nil {
 				return nil, nil, err
 			}
+
+			if md, err := stream.Header(); err == nil {
+				if capacity, ok := common.ParseSeedCapacity(md); ok {
+					peer.Log.Infof("seed peer free bandwidth %d and disk used percent %.2f",
+						capacity.FreeBandwidth, capacity.DiskUsedPercent)
+				}
+			}
 		}
 
 		// Handle begin of piece.
@@ -133,7 +165,7 @@ func (s *seedPeer) TriggerTask(ctx context.Context, task *Task) (*Peer, *schedul
 			PieceInfo:       piece.PieceInfo,
 			ExtendAttribute: piece.ExtendAttribute,
 		})
-		peer.FinishedPieces.Set(uint(piece.PieceInfo.PieceNum))
+		peer.FinishPiece(piece.PieceInfo.PieceNum)
 		peer.AppendPieceCost(pkgtime.SubNano(int64(piece.EndTime), int64(piece.BeginTime)).Milliseconds())
 		task.StorePiece(piece.PieceInfo)
 
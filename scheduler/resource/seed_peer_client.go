@@ -19,8 +19,9 @@
 package resource
 
 import (
-	"fmt"
+	"net"
 	reflect "reflect"
+	"strconv"
 
 	"google.golang.org/grpc"
 
@@ -156,7 +157,7 @@ func seedPeersToNetAddrs(seedPeers []*config.SeedPeer) []dfnet.NetAddr {
 	for _, seedPeer := range seedPeers {
 		netAddrs = append(netAddrs, dfnet.NetAddr{
 			Type: dfnet.TCP,
-			Addr: fmt.Sprintf("%s:%d", seedPeer.IP, seedPeer.Port),
+			Addr: net.JoinHostPort(seedPeer.IP, strconv.Itoa(int(seedPeer.Port))),
 		})
 	}
 
@@ -0,0 +1,160 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	pkggc "d7y.io/dragonfly/v2/pkg/gc"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+)
+
+const (
+	// GC seed peer prober id.
+	GCSeedPeerProberID = "seed-peer-prober"
+)
+
+// seedPeerProber probes health of seed peers periodically,
+// demotes the seed peers failed consecutively and restores them after they recover.
+type seedPeerProber struct {
+	// config is health probe configuration.
+	config config.SeedPeerHealthProbeConfig
+
+	// dynconfig is dynamic config of scheduler.
+	dynconfig config.DynconfigInterface
+
+	// probe checks health of the seed peer address.
+	probe func(ctx context.Context, addr string) error
+
+	// failures is the number of consecutive failed probes of seed peers.
+	failures map[uint]int
+
+	// demoted is the demoted seed peers, they are still probed for restoring.
+	demoted map[uint]*config.SeedPeer
+}
+
+// newSeedPeerProber returns a seed peer prober runs in gc.
+func newSeedPeerProber(cfg config.SeedPeerHealthProbeConfig, dynconfig config.DynconfigInterface, gc pkggc.GC) (*seedPeerProber, error) {
+	p := &seedPeerProber{
+		config:    cfg,
+		dynconfig: dynconfig,
+		probe:     probeSeedPeer,
+		failures:  map[uint]int{},
+		demoted:   map[uint]*config.SeedPeer{},
+	}
+
+	if err := gc.Add(pkggc.Task{
+		ID:       GCSeedPeerProberID,
+		Interval: cfg.Interval,
+		Timeout:  cfg.Interval,
+		Runner:   p,
+	}); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// RunGC probes all seed peers and notifies observers when seed peers are demoted or restored.
+func (p *seedPeerProber) RunGC() error {
+	seedPeers, err := p.dynconfig.GetSeedPeers()
+	if err != nil {
+		return err
+	}
+
+	for _, seedPeer := range p.demoted {
+		seedPeers = append(seedPeers, seedPeer)
+	}
+
+	// Probe seed peers concurrently, slow seed peers do not delay others.
+	errs := make([]error, len(seedPeers))
+	var wg sync.WaitGroup
+	for i, seedPeer := range seedPeers {
+		wg.Add(1)
+		go func(i int, seedPeer *config.SeedPeer) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+			defer cancel()
+			errs[i] = p.probe(ctx, net.JoinHostPort(seedPeer.IP, strconv.Itoa(int(seedPeer.Port))))
+		}(i, seedPeer)
+	}
+	wg.Wait()
+
+	var changed bool
+	for i, seedPeer := range seedPeers {
+		_, demoted := p.demoted[seedPeer.ID]
+		if errs[i] == nil {
+			delete(p.failures, seedPeer.ID)
+			if demoted {
+				logger.Infof("seed peer %s %s:%d recovered and is restored", seedPeer.Hostname, seedPeer.IP, seedPeer.Port)
+				p.dynconfig.RestoreSeedPeer(seedPeer.ID)
+				delete(p.demoted, seedPeer.ID)
+				changed = true
+			}
+
+			continue
+		}
+
+		p.failures[seedPeer.ID]++
+		logger.Warnf("probe seed peer %s %s:%d failed %d times: %s",
+			seedPeer.Hostname, seedPeer.IP, seedPeer.Port, p.failures[seedPeer.ID], errs[i].Error())
+		if !demoted && p.failures[seedPeer.ID] >= p.config.FailureThreshold {
+			logger.Errorf("seed peer %s %s:%d is unhealthy and is demoted", seedPeer.Hostname, seedPeer.IP, seedPeer.Port)
+			p.dynconfig.DemoteSeedPeer(seedPeer.ID)
+			p.demoted[seedPeer.ID] = seedPeer
+			metrics.SeedPeerDemotedCount.Inc()
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	metrics.DemotedSeedPeerGauge.Set(float64(len(p.demoted)))
+	return p.dynconfig.Notify()
+}
+
+// probeSeedPeer checks health of the seed peer by grpc health checking protocol.
+func probeSeedPeer(ctx context.Context, addr string) error {
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("seed peer is %s", resp.Status.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/gc"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	configmocks "d7y.io/dragonfly/v2/scheduler/config/mocks"
+)
+
+func TestSeedPeerProber_RunGC(t *testing.T) {
+	mockSeedPeer := &config.SeedPeer{
+		ID:       1,
+		Hostname: "foo",
+		IP:       "127.0.0.1",
+		Port:     65001,
+	}
+
+	tests := []struct {
+		name   string
+		probes []error
+		mock   func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder)
+		expect func(t *testing.T, p *seedPeerProber, errs []error)
+	}{
+		{
+			name:   "seed peer is healthy",
+			probes: []error{nil, nil},
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				dynconfig.GetSeedPeers().Return([]*config.SeedPeer{mockSeedPeer}, nil).Times(2)
+			},
+			expect: func(t *testing.T, p *seedPeerProber, errs []error) {
+				assert := assert.New(t)
+				assert.Len(p.demoted, 0)
+				assert.Len(p.failures, 0)
+			},
+		},
+		{
+			name:   "seed peer failed less than threshold",
+			probes: []error{errors.New("foo"), nil, errors.New("foo")},
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				dynconfig.GetSeedPeers().Return([]*config.SeedPeer{mockSeedPeer}, nil).Times(3)
+			},
+			expect: func(t *testing.T, p *seedPeerProber, errs []error) {
+				assert := assert.New(t)
+				assert.Len(p.demoted, 0)
+				assert.Equal(1, p.failures[mockSeedPeer.ID])
+			},
+		},
+		{
+			name:   "seed peer is demoted and restored",
+			probes: []error{errors.New("foo"), errors.New("foo"), errors.New("foo"), nil},
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				gomock.InOrder(
+					dynconfig.GetSeedPeers().Return([]*config.SeedPeer{mockSeedPeer}, nil).Times(2),
+					dynconfig.GetSeedPeers().Return([]*config.SeedPeer{mockSeedPeer}, nil).Times(1),
+					dynconfig.DemoteSeedPeer(gomock.Eq(mockSeedPeer.ID)).Return().Times(1),
+					dynconfig.Notify().Return(nil).Times(1),
+					dynconfig.GetSeedPeers().Return([]*config.SeedPeer{}, nil).Times(1),
+					dynconfig.RestoreSeedPeer(gomock.Eq(mockSeedPeer.ID)).Return().Times(1),
+					dynconfig.Notify().Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, p *seedPeerProber, errs []error) {
+				assert := assert.New(t)
+				assert.NoError(errs[3])
+				assert.Len(p.demoted, 0)
+				assert.Len(p.failures, 0)
+			},
+		},
+		{
+			name:   "get seed peers failed",
+			probes: []error{nil},
+			mock: func(dynconfig *configmocks.MockDynconfigInterfaceMockRecorder) {
+				dynconfig.GetSeedPeers().Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, p *seedPeerProber, errs []error) {
+				assert := assert.New(t)
+				assert.EqualError(errs[0], "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			gc.EXPECT().Add(gomock.Any()).Return(nil).Times(1)
+			tc.mock(dynconfig.EXPECT())
+
+			p, err := newSeedPeerProber(config.SeedPeerHealthProbeConfig{
+				Enable:           true,
+				Interval:         time.Second,
+				Timeout:          time.Second,
+				FailureThreshold: 3,
+			}, dynconfig, gc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var errs []error
+			for _, probe := range tc.probes {
+				probe := probe
+				p.probe = func(ctx context.Context, addr string) error {
+					assert.Equal(t, "127.0.0.1:65001", addr)
+					return probe
+				}
+				errs = append(errs, p.RunGC())
+			}
+			tc.expect(t, p, errs)
+		})
+	}
+}
@@ -24,9 +24,14 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 
+	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
+	cdnsystemv1mocks "d7y.io/api/pkg/apis/cdnsystem/v1/mocks"
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
 )
 
 func TestSeedPeer_newSeedPeer(t *testing.T) {
@@ -59,12 +64,12 @@ func TestSeedPeer_newSeedPeer(t *testing.T) {
 func TestSeedPeer_TriggerTask(t *testing.T) {
 	tests := []struct {
 		name   string
-		mock   func(mc *MockSeedPeerClientMockRecorder)
+		mock   func(ctl *gomock.Controller, mc *MockSeedPeerClientMockRecorder)
 		expect func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error)
 	}{
 		{
 			name: "start obtain seed stream failed",
-			mock: func(mc *MockSeedPeerClientMockRecorder) {
+			mock: func(ctl *gomock.Controller, mc *MockSeedPeerClientMockRecorder) {
 				mc.ObtainSeeds(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
 			},
 			expect: func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error) {
@@ -72,6 +77,41 @@ func TestSeedPeer_TriggerTask(t *testing.T) {
 				assert.EqualError(err, "foo")
 			},
 		},
+		{
+			name: "seed peer is busy",
+			mock: func(ctl *gomock.Controller, mc *MockSeedPeerClientMockRecorder) {
+				busy := func() *cdnsystemv1mocks.MockSeeder_ObtainSeedsClient {
+					stream := cdnsystemv1mocks.NewMockSeeder_ObtainSeedsClient(ctl)
+					stream.EXPECT().Recv().Return(nil, common.NewSeedPeerBusyError("seed task queue is full")).Times(1)
+					stream.EXPECT().Header().Return(common.SeedCapacity{DiskUsedPercent: 50}.Metadata(), nil).AnyTimes()
+					return stream
+				}
+				mc.ObtainSeeds(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *cdnsystemv1.SeedRequest, opts ...grpc.CallOption) (cdnsystemv1.Seeder_ObtainSeedsClient, error) {
+						return busy(), nil
+					}).Times(SeedPeerBusyRetryLimit + 1)
+			},
+			expect: func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error) {
+				assert := assert.New(t)
+				assert.True(common.IsSeedPeerBusy(err))
+			},
+		},
+		{
+			name: "seed peer is busy and retry failed",
+			mock: func(ctl *gomock.Controller, mc *MockSeedPeerClientMockRecorder) {
+				stream := cdnsystemv1mocks.NewMockSeeder_ObtainSeedsClient(ctl)
+				stream.EXPECT().Recv().Return(nil, common.NewSeedPeerBusyError("disk is above watermark")).Times(1)
+				stream.EXPECT().Header().Return(nil, errors.New("bar")).Times(1)
+				gomock.InOrder(
+					mc.ObtainSeeds(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1),
+					mc.ObtainSeeds(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -81,7 +121,7 @@ func TestSeedPeer_TriggerTask(t *testing.T) {
 			hostManager := NewMockHostManager(ctl)
 			peerManager := NewMockPeerManager(ctl)
 			client := NewMockSeedPeerClient(ctl)
-			tc.mock(client.EXPECT())
+			tc.mock(ctl, client.EXPECT())
 
 			seedPeer := newSeedPeer(client, peerManager, hostManager)
 			mockTask := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(mockTaskBackToSourceLimit))
@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bits-and-blooms/bitset"
 	"github.com/looplab/fsm"
 	"go.uber.org/atomic"
 
@@ -79,6 +80,13 @@ func WithBackToSourceLimit(limit int32) Option {
 	}
 }
 
+// WithClass set Class for task.
+func WithClass(class string) Option {
+	return func(task *Task) {
+		task.Class = class
+	}
+}
+
 type Task struct {
 	// ID is task id.
 	ID string
@@ -92,6 +100,10 @@ type Task struct {
 	// URLMeta is task download url meta.
 	URLMeta *commonv1.UrlMeta
 
+	// Class is the name of task class matched by url meta,
+	// it is empty if the task does not match any class.
+	Class string
+
 	// DirectPiece is tiny piece data.
 	DirectPiece []byte
 
@@ -107,6 +119,32 @@ type Task struct {
 	// BackToSourcePeers is back-to-source sync map.
 	BackToSourcePeers set.SafeSet[string]
 
+	// backToSourceMu guards back-to-source reservation and queue.
+	backToSourceMu sync.Mutex
+
+	// backToSourceQueue is the queue of peer ids waiting for back-to-source,
+	// when back-to-source peers reach the limit.
+	backToSourceQueue []string
+
+	// Applications is the set of applications downloading the task.
+	Applications set.SafeSet[string]
+
+	// SuperPeers is the set of peer ids preferred as parents
+	// for large fan-out tasks.
+	SuperPeers set.SafeSet[string]
+
+	// superPeersMu guards promotion of super peers.
+	superPeersMu sync.Mutex
+
+	// pieceReplicas is the count of peers which have finished the piece.
+	pieceReplicas map[int32]int32
+
+	// pieceRequests is the count of the piece requested from parents.
+	pieceRequests map[int32]int64
+
+	// pieceStatsMu guards piece replicas and piece requests.
+	pieceStatsMu sync.RWMutex
+
 	// Task state machine.
 	FSM *fsm.FSM
 
@@ -141,6 +179,10 @@ func NewTask(id, url string, taskType commonv1.TaskType, meta *commonv1.UrlMeta,
 		TotalPieceCount:   atomic.NewInt32(0),
 		BackToSourceLimit: atomic.NewInt32(0),
 		BackToSourcePeers: set.NewSafeSet[string](),
+		SuperPeers:        set.NewSafeSet[string](),
+		Applications:      set.NewSafeSet[string](),
+		pieceReplicas:     map[int32]int32{},
+		pieceRequests:     map[int32]int64{},
 		Pieces:            &sync.Map{},
 		DAG:               dag.NewDAG[*Peer](),
 		PeerFailedCount:   atomic.NewInt32(0),
@@ -216,6 +258,11 @@ func (t *Task) DeletePeer(key string) {
 		t.Log.Error(err)
 	}
 
+	if vertex, err := t.DAG.GetVertex(key); err == nil && vertex.Value != nil {
+		t.DeletePieceReplicas(vertex.Value.FinishedPieces)
+	}
+
+	t.SuperPeers.Delete(key)
 	t.DAG.DeleteVertex(key)
 }
 
@@ -323,6 +370,22 @@ func (t *Task) HasAvailablePeer() bool {
 	return hasAvailablePeer
 }
 
+// HasActivePeer returns whether there is any peer which is downloading the task.
+func (t *Task) HasActivePeer() bool {
+	for _, vertex := range t.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer == nil {
+			continue
+		}
+
+		if peer.IsActive() {
+			return true
+		}
+	}
+
+	return false
+}
+
 // LoadSeedPeer return latest seed peer in peers sync map.
 func (t *Task) LoadSeedPeer() (*Peer, bool) {
 	var peers []*Peer
@@ -403,6 +466,191 @@ func (t *Task) CanBackToSource() bool {
 	return int32(t.BackToSourcePeers.Len()) < t.BackToSourceLimit.Load() && (t.Type == commonv1.TaskType_Normal || t.Type == commonv1.TaskType_DfStore)
 }
 
+// ReserveBackToSource reserves a back-to-source slot for the peer,
+// the check and reservation are atomic, so the limit can not be exceeded
+// by concurrent scheduling.
+func (t *Task) ReserveBackToSource(peerID string) bool {
+	t.backToSourceMu.Lock()
+	defer t.backToSourceMu.Unlock()
+
+	if t.BackToSourcePeers.Contains(peerID) {
+		return true
+	}
+
+	if !t.CanBackToSource() {
+		return false
+	}
+
+	t.BackToSourcePeers.Add(peerID)
+	return true
+}
+
+// ReleaseBackToSource releases the back-to-source slot of the peer.
+func (t *Task) ReleaseBackToSource(peerID string) {
+	t.backToSourceMu.Lock()
+	defer t.backToSourceMu.Unlock()
+
+	t.BackToSourcePeers.Delete(peerID)
+}
+
+// PromoteSuperPeer promotes the peer to super peer if the count of
+// super peers does not reach the limit.
+func (t *Task) PromoteSuperPeer(peerID string, limit int) bool {
+	t.superPeersMu.Lock()
+	defer t.superPeersMu.Unlock()
+
+	if t.SuperPeers.Contains(peerID) {
+		return true
+	}
+
+	if t.SuperPeers.Len() >= uint(limit) {
+		return false
+	}
+
+	t.SuperPeers.Add(peerID)
+	return true
+}
+
+// DemoteSuperPeer removes the peer from super peers,
+// returns false if the peer is not super peer.
+func (t *Task) DemoteSuperPeer(peerID string) bool {
+	t.superPeersMu.Lock()
+	defer t.superPeersMu.Unlock()
+
+	if !t.SuperPeers.Contains(peerID) {
+		return false
+	}
+
+	t.SuperPeers.Delete(peerID)
+	return true
+}
+
+// AddPieceReplica increases the replica count of the piece.
+func (t *Task) AddPieceReplica(number int32) {
+	t.pieceStatsMu.Lock()
+	defer t.pieceStatsMu.Unlock()
+
+	t.pieceReplicas[number]++
+}
+
+// DeletePieceReplicas decreases the replica count of the finished pieces,
+// when the peer holding them leaves the task.
+func (t *Task) DeletePieceReplicas(pieces *bitset.BitSet) {
+	if pieces == nil {
+		return
+	}
+
+	t.pieceStatsMu.Lock()
+	defer t.pieceStatsMu.Unlock()
+
+	for number, ok := pieces.NextSet(0); ok; number, ok = pieces.NextSet(number + 1) {
+		count, loaded := t.pieceReplicas[int32(number)]
+		if !loaded {
+			continue
+		}
+
+		if count <= 1 {
+			delete(t.pieceReplicas, int32(number))
+			continue
+		}
+
+		t.pieceReplicas[int32(number)] = count - 1
+	}
+}
+
+// PieceReplicaCount returns the count of peers which have finished the piece.
+func (t *Task) PieceReplicaCount(number int32) int32 {
+	t.pieceStatsMu.RLock()
+	defer t.pieceStatsMu.RUnlock()
+
+	return t.pieceReplicas[number]
+}
+
+// AddPieceRequest increases the request count of the piece.
+func (t *Task) AddPieceRequest(number int32) {
+	t.pieceStatsMu.Lock()
+	defer t.pieceStatsMu.Unlock()
+
+	t.pieceRequests[number]++
+}
+
+// PieceRequestCount returns the count of the piece requested from parents.
+func (t *Task) PieceRequestCount(number int32) int64 {
+	t.pieceStatsMu.RLock()
+	defer t.pieceStatsMu.RUnlock()
+
+	return t.pieceRequests[number]
+}
+
+// RarestPieceReplicaCount returns the minimum replica count of the pieces
+// which are not in excluded, zero is returned if no such piece is tracked.
+func (t *Task) RarestPieceReplicaCount(pieces, excluded *bitset.BitSet) int32 {
+	if pieces == nil {
+		return 0
+	}
+
+	t.pieceStatsMu.RLock()
+	defer t.pieceStatsMu.RUnlock()
+
+	var rarest int32
+	for number, ok := pieces.NextSet(0); ok; number, ok = pieces.NextSet(number + 1) {
+		if excluded != nil && excluded.Test(number) {
+			continue
+		}
+
+		count := t.pieceReplicas[int32(number)]
+		if count <= 0 {
+			continue
+		}
+
+		if rarest == 0 || count < rarest {
+			rarest = count
+		}
+	}
+
+	return rarest
+}
+
+// EnqueueBackToSource queues the peer waiting for back-to-source.
+func (t *Task) EnqueueBackToSource(peerID string) {
+	t.backToSourceMu.Lock()
+	defer t.backToSourceMu.Unlock()
+
+	for _, id := range t.backToSourceQueue {
+		if id == peerID {
+			return
+		}
+	}
+
+	t.backToSourceQueue = append(t.backToSourceQueue, peerID)
+}
+
+// DequeueBackToSource pops the first peer waiting for back-to-source
+// which is still in the task.
+func (t *Task) DequeueBackToSource() (*Peer, bool) {
+	t.backToSourceMu.Lock()
+	defer t.backToSourceMu.Unlock()
+
+	for len(t.backToSourceQueue) > 0 {
+		peerID := t.backToSourceQueue[0]
+		t.backToSourceQueue = t.backToSourceQueue[1:]
+
+		if peer, ok := t.LoadPeer(peerID); ok {
+			return peer, true
+		}
+	}
+
+	return nil, false
+}
+
+// BackToSourceQueueLen returns the length of back-to-source queue.
+func (t *Task) BackToSourceQueueLen() int {
+	t.backToSourceMu.Lock()
+	defer t.backToSourceMu.Unlock()
+
+	return len(t.backToSourceQueue)
+}
+
 // NotifyPeers notify all peers in the task with the state code.
 func (t *Task) NotifyPeers(peerPacket *schedulerv1.PeerPacket, event string) {
 	for _, vertex := range t.DAG.GetVertices() {
@@ -22,8 +22,11 @@ import (
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
+
 	pkggc "d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
 )
 
 const (
@@ -46,23 +49,42 @@ type TaskManager interface {
 	// Delete deletes task for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and task present in the map.
+	// If f returns false, range stops the iteration.
+	Range(f func(key, value any) bool)
+
 	// Try to reclaim task.
 	RunGC() error
+
+	// Reload applies task time to live and gc interval of gc config to the running task manager.
+	Reload(*config.GCConfig) error
 }
 
 type taskManager struct {
 	// Task sync map.
 	*sync.Map
 
+	// mu guards loading and reclaiming tasks, so that a task loaded by
+	// registration is not reclaimed before its peer is stored.
+	mu sync.Mutex
+
 	// Task time to live.
-	ttl time.Duration
+	ttl *atomic.Duration
+
+	// gc reclaims tasks periodically.
+	gc pkggc.GC
+
+	// Task time to live of task classes.
+	classTTL map[string]time.Duration
 }
 
 // New task manager interface.
 func newTaskManager(cfg *config.GCConfig, gc pkggc.GC) (TaskManager, error) {
 	t := &taskManager{
-		Map: &sync.Map{},
-		ttl: cfg.TaskTTL,
+		Map:      &sync.Map{},
+		ttl:      atomic.NewDuration(cfg.TaskTTL),
+		gc:       gc,
+		classTTL: cfg.TaskClassTTL,
 	}
 
 	if err := gc.Add(pkggc.Task{
@@ -77,6 +99,21 @@ func newTaskManager(cfg *config.GCConfig, gc pkggc.GC) (TaskManager, error) {
 	return t, nil
 }
 
+// Reload applies task time to live and gc interval of gc config to the running task manager.
+func (t *taskManager) Reload(cfg *config.GCConfig) error {
+	if err := t.gc.Update(pkggc.Task{
+		ID:       GCTaskID,
+		Interval: cfg.TaskGCInterval,
+		Timeout:  cfg.TaskGCInterval,
+		Runner:   t,
+	}); err != nil {
+		return err
+	}
+
+	t.ttl.Store(cfg.TaskTTL)
+	return nil
+}
+
 func (t *taskManager) Load(key string) (*Task, bool) {
 	rawTask, ok := t.Map.Load(key)
 	if !ok {
@@ -91,7 +128,15 @@ func (t *taskManager) Store(task *Task) {
 }
 
 func (t *taskManager) LoadOrStore(task *Task) (*Task, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	rawTask, loaded := t.Map.LoadOrStore(task.ID, task)
+	if loaded {
+		// Refresh the loaded task, gc does not reclaim it before its peer is stored.
+		rawTask.(*Task).UpdateAt.Store(time.Now())
+	}
+
 	return rawTask.(*Task), loaded
 }
 
@@ -101,16 +146,41 @@ func (t *taskManager) Delete(key string) {
 
 func (t *taskManager) RunGC() error {
 	t.Map.Range(func(_, value any) bool {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
 		task := value.(*Task)
 		elapsed := time.Since(task.UpdateAt.Load())
+		if elapsed <= t.taskTTL(task) {
+			return true
+		}
 
-		if elapsed > t.ttl && task.PeerCount() == 0 && !task.FSM.Is(TaskStateRunning) {
-			task.Log.Info("task has been reclaimed")
-			t.Delete(task.ID)
+		// Task is expired, but peers are still downloading it,
+		// defer reclaiming until the swarm is inactive.
+		if task.FSM.Is(TaskStateRunning) || task.HasActivePeer() {
+			metrics.TaskGCDeferredCount.Inc()
+			task.Log.Debug("task reclamation is deferred by active peers")
+			return true
 		}
 
+		// Peers of task are reclaimed by peer gc first.
+		if task.PeerCount() != 0 {
+			return true
+		}
+
+		task.Log.Info("task has been reclaimed")
+		t.Map.Delete(task.ID)
 		return true
 	})
 
 	return nil
 }
+
+// taskTTL returns time to live of the task class, or the default time to live.
+func (t *taskManager) taskTTL(task *Task) time.Duration {
+	if ttl, ok := t.classTTL[task.Class]; ok {
+		return ttl
+	}
+
+	return t.ttl.Load()
+}
@@ -35,6 +35,9 @@ var (
 	mockTaskGCConfig = &config.GCConfig{
 		TaskGCInterval: 1 * time.Second,
 		TaskTTL:        1 * time.Microsecond,
+		TaskClassTTL: map[string]time.Duration{
+			"production": time.Hour,
+		},
 	}
 )
 
@@ -338,6 +341,39 @@ func TestTaskManager_RunGC(t *testing.T) {
 				assert.Equal(task.ID, mockTask.ID)
 			},
 		},
+		{
+			name: "task of class is not reclaimed within class ttl",
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				mockTask.Class = "production"
+				taskManager.Store(mockTask)
+				err := taskManager.RunGC()
+				assert.NoError(err)
+
+				task, ok := taskManager.Load(mockTask.ID)
+				assert.Equal(ok, true)
+				assert.Equal(task.ID, mockTask.ID)
+			},
+		},
+		{
+			name: "task of class without class ttl is reclaimed",
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				mockTask.Class = "bulk"
+				taskManager.Store(mockTask)
+				err := taskManager.RunGC()
+				assert.NoError(err)
+
+				_, ok := taskManager.Load(mockTask.ID)
+				assert.Equal(ok, false)
+			},
+		},
 		{
 			name: "task state is TaskStateRunning",
 			mock: func(m *gc.MockGCMockRecorder) {
@@ -355,6 +391,52 @@ func TestTaskManager_RunGC(t *testing.T) {
 				assert.Equal(task.ID, mockTask.ID)
 			},
 		},
+		{
+			name: "task has active peers",
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				taskManager.Store(mockTask)
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateRunning)
+				err := taskManager.RunGC()
+				assert.NoError(err)
+				assert.True(mockTask.HasActivePeer())
+
+				_, ok := taskManager.Load(mockTask.ID)
+				assert.Equal(ok, true)
+
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+				assert.False(mockTask.HasActivePeer())
+				mockTask.DeletePeer(mockPeer.ID)
+				err = taskManager.RunGC()
+				assert.NoError(err)
+
+				_, ok = taskManager.Load(mockTask.ID)
+				assert.Equal(ok, false)
+			},
+		},
+		{
+			name: "task loaded by registration is not reclaimed",
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				mockTask.Class = "production"
+				mockTask.UpdateAt.Store(time.Now().Add(-2 * time.Hour))
+				taskManager.Store(mockTask)
+				_, loaded := taskManager.LoadOrStore(mockTask)
+				assert.True(loaded)
+				err := taskManager.RunGC()
+				assert.NoError(err)
+
+				_, ok := taskManager.Load(mockTask.ID)
+				assert.Equal(ok, true)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -376,3 +458,64 @@ func TestTaskManager_RunGC(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskManager_Reload(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(t *testing.T, m *gc.MockGCMockRecorder)
+		expect func(t *testing.T, manager *taskManager, err error)
+	}{
+		{
+			name: "reload gc config",
+			mock: func(t *testing.T, m *gc.MockGCMockRecorder) {
+				gomock.InOrder(
+					m.Add(gomock.Any()).Return(nil).Times(1),
+					m.Update(gomock.Any()).Do(func(task gc.Task) {
+						assert := assert.New(t)
+						assert.Equal(GCTaskID, task.ID)
+						assert.Equal(2*time.Second, task.Interval)
+					}).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, manager *taskManager, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(time.Minute, manager.ttl.Load())
+			},
+		},
+		{
+			name: "reload gc config failed because of gc error",
+			mock: func(t *testing.T, m *gc.MockGCMockRecorder) {
+				gomock.InOrder(
+					m.Add(gomock.Any()).Return(nil).Times(1),
+					m.Update(gomock.Any()).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, manager *taskManager, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+				assert.Equal(mockTaskGCConfig.TaskTTL, manager.ttl.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			tc.mock(t, gc.EXPECT())
+
+			manager, err := newTaskManager(mockTaskGCConfig, gc)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = manager.Reload(&config.GCConfig{
+				TaskGCInterval: 2 * time.Second,
+				TaskTTL:        time.Minute,
+			})
+			tc.expect(t, manager.(*taskManager), err)
+		})
+	}
+}
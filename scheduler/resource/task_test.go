@@ -18,11 +18,14 @@ package resource
 
 import (
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
@@ -1387,6 +1390,166 @@ func TestTask_CanBackToSource(t *testing.T) {
 	}
 }
 
+func TestTask_ReserveBackToSource(t *testing.T) {
+	tests := []struct {
+		name              string
+		backToSourceLimit int32
+		expect            func(t *testing.T, task *Task)
+	}{
+		{
+			name:              "reserve back-to-source slot",
+			backToSourceLimit: 1,
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				assert.True(task.ReserveBackToSource("foo"))
+				assert.True(task.ReserveBackToSource("foo"))
+				assert.False(task.ReserveBackToSource("bar"))
+				assert.Equal(task.BackToSourcePeers.Len(), uint(1))
+			},
+		},
+		{
+			name:              "reserve back-to-source slot after releasing",
+			backToSourceLimit: 1,
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				assert.True(task.ReserveBackToSource("foo"))
+				task.ReleaseBackToSource("foo")
+				assert.True(task.ReserveBackToSource("bar"))
+			},
+		},
+		{
+			name:              "reserve back-to-source slot concurrently",
+			backToSourceLimit: 3,
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				var (
+					wg       sync.WaitGroup
+					reserved = atomic.NewInt32(0)
+				)
+				for i := 0; i < 10; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						if task.ReserveBackToSource(fmt.Sprintf("peer-%d", i)) {
+							reserved.Inc()
+						}
+					}(i)
+				}
+				wg.Wait()
+				assert.Equal(reserved.Load(), int32(3))
+			},
+		},
+		{
+			name:              "task can not back-to-source",
+			backToSourceLimit: 0,
+			expect: func(t *testing.T, task *Task) {
+				assert := assert.New(t)
+				assert.False(task.ReserveBackToSource("foo"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(tc.backToSourceLimit))
+			tc.expect(t, task)
+		})
+	}
+}
+
+func TestTask_BackToSourceQueue(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, task *Task, host *Host)
+	}{
+		{
+			name: "dequeue peers in order",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				task.StorePeer(NewPeer("foo", task, host))
+				task.StorePeer(NewPeer("bar", task, host))
+				task.EnqueueBackToSource("foo")
+				task.EnqueueBackToSource("bar")
+				task.EnqueueBackToSource("foo")
+				assert.Equal(task.BackToSourceQueueLen(), 2)
+
+				peer, ok := task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "foo")
+				peer, ok = task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "bar")
+				_, ok = task.DequeueBackToSource()
+				assert.False(ok)
+			},
+		},
+		{
+			name: "skip peers which have been deleted",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				task.StorePeer(NewPeer("bar", task, host))
+				task.EnqueueBackToSource("foo")
+				task.EnqueueBackToSource("bar")
+
+				peer, ok := task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "bar")
+				assert.Equal(task.BackToSourceQueueLen(), 0)
+			},
+		},
+		{
+			name: "enqueue peer again after dequeued",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				task.StorePeer(NewPeer("foo", task, host))
+				task.StorePeer(NewPeer("bar", task, host))
+				task.EnqueueBackToSource("foo")
+				task.EnqueueBackToSource("bar")
+
+				peer, ok := task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "foo")
+				task.EnqueueBackToSource("foo")
+
+				peer, ok = task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "bar")
+				peer, ok = task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "foo")
+			},
+		},
+		{
+			name: "promote queued peer when back-to-source slot is released",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				task.StorePeer(NewPeer("foo", task, host))
+				task.StorePeer(NewPeer("bar", task, host))
+				assert.True(task.ReserveBackToSource("baz"))
+				assert.False(task.ReserveBackToSource("foo"))
+				task.EnqueueBackToSource("foo")
+				assert.False(task.ReserveBackToSource("bar"))
+				task.EnqueueBackToSource("bar")
+
+				task.ReleaseBackToSource("baz")
+				peer, ok := task.DequeueBackToSource()
+				assert.True(ok)
+				assert.Equal(peer.ID, "foo")
+				assert.True(task.ReserveBackToSource(peer.ID))
+				assert.False(task.ReserveBackToSource("bar"))
+				assert.Equal(task.BackToSourceQueueLen(), 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, WithBackToSourceLimit(1))
+			tc.expect(t, task, NewHost(mockRawHost))
+		})
+	}
+}
+
 func TestTask_NotifyPeers(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1467,3 +1630,118 @@ func TestTask_NotifyPeers(t *testing.T) {
 		})
 	}
 }
+
+func TestTask_SuperPeer(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, task *Task, host *Host)
+	}{
+		{
+			name: "promote super peers up to the limit",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				assert.True(task.PromoteSuperPeer("foo", 1))
+				assert.True(task.PromoteSuperPeer("foo", 1))
+				assert.False(task.PromoteSuperPeer("bar", 1))
+				assert.Equal(task.SuperPeers.Len(), uint(1))
+			},
+		},
+		{
+			name: "promote super peer after demoting",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				assert.True(task.PromoteSuperPeer("foo", 1))
+				assert.True(task.DemoteSuperPeer("foo"))
+				assert.False(task.DemoteSuperPeer("foo"))
+				assert.True(task.PromoteSuperPeer("bar", 1))
+			},
+		},
+		{
+			name: "super peer is removed when peer is deleted",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				task.StorePeer(NewPeer("foo", task, host))
+				assert.True(task.PromoteSuperPeer("foo", 1))
+				task.DeletePeer("foo")
+				assert.False(task.SuperPeers.Contains("foo"))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := NewHost(mockRawHost)
+			task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+			tc.expect(t, task, host)
+		})
+	}
+}
+
+func TestTask_PieceStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, task *Task, host *Host)
+	}{
+		{
+			name: "finished piece is counted once",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				peer := NewPeer("foo", task, host)
+				peer.FinishPiece(0)
+				peer.FinishPiece(0)
+				NewPeer("bar", task, host).FinishPiece(0)
+				assert.Equal(task.PieceReplicaCount(0), int32(2))
+				assert.Equal(task.PieceReplicaCount(1), int32(0))
+			},
+		},
+		{
+			name: "piece replicas are removed when peer is deleted",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				foo := NewPeer("foo", task, host)
+				bar := NewPeer("bar", task, host)
+				task.StorePeer(foo)
+				task.StorePeer(bar)
+				foo.FinishPiece(0)
+				foo.FinishPiece(1)
+				bar.FinishPiece(1)
+				task.DeletePeer("foo")
+				assert.Equal(task.PieceReplicaCount(0), int32(0))
+				assert.Equal(task.PieceReplicaCount(1), int32(1))
+			},
+		},
+		{
+			name: "rarest piece replica count",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				foo := NewPeer("foo", task, host)
+				bar := NewPeer("bar", task, host)
+				foo.FinishPiece(0)
+				foo.FinishPiece(1)
+				bar.FinishPiece(0)
+				assert.Equal(task.RarestPieceReplicaCount(foo.FinishedPieces, nil), int32(1))
+				assert.Equal(task.RarestPieceReplicaCount(foo.FinishedPieces, foo.FinishedPieces), int32(0))
+				assert.Equal(task.RarestPieceReplicaCount(bar.FinishedPieces, nil), int32(2))
+				assert.Equal(task.RarestPieceReplicaCount(nil, nil), int32(0))
+			},
+		},
+		{
+			name: "piece requests",
+			expect: func(t *testing.T, task *Task, host *Host) {
+				assert := assert.New(t)
+				task.AddPieceRequest(0)
+				task.AddPieceRequest(0)
+				assert.Equal(task.PieceRequestCount(0), int64(2))
+				assert.Equal(task.PieceRequestCount(1), int64(0))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host := NewHost(mockRawHost)
+			task := NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta)
+			tc.expect(t, task, host)
+		})
+	}
+}
@@ -18,6 +18,7 @@ package rpcserver
 
 import (
 	"context"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
@@ -28,6 +29,7 @@ import (
 
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/rpc"
+	schedulerserver "d7y.io/dragonfly/v2/pkg/rpc/scheduler/server"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/service"
@@ -49,6 +51,7 @@ func New(service *service.Service, opts ...grpc.ServerOption) *grpc.Server {
 
 	// Register servers on grpc server.
 	schedulerv1.RegisterSchedulerServer(grpcServer, svr)
+	schedulerserver.RegisterEstimatorServer(grpcServer, svr)
 	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
 	return grpcServer
 }
@@ -71,7 +74,9 @@ func (s *Server) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTask
 	}
 	metrics.RegisterPeerTaskCount.WithLabelValues(tag, application).Inc()
 
+	start := time.Now()
 	resp, err := s.service.RegisterPeerTask(ctx, req)
+	metrics.RegisterPeerTaskDuration.WithLabelValues(tag, application).Observe(float64(time.Since(start)) / float64(time.Millisecond))
 	if err != nil {
 		metrics.RegisterPeerTaskFailureCount.WithLabelValues(tag, application).Inc()
 	} else {
@@ -121,3 +126,8 @@ func (s *Server) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTask
 func (s *Server) LeaveTask(ctx context.Context, req *schedulerv1.PeerTarget) (*empty.Empty, error) {
 	return new(empty.Empty), s.service.LeaveTask(ctx, req)
 }
+
+// WatchPeerETA pushes the estimated remaining time of downloading to peer.
+func (s *Server) WatchPeerETA(ctx context.Context, req *schedulerv1.PeerTarget, send func(time.Duration) error) error {
+	return s.service.WatchPeerETA(ctx, req, send)
+}
@@ -18,9 +18,9 @@ package scheduler
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -33,9 +33,12 @@ import (
 	pkgbalancer "d7y.io/dragonfly/v2/pkg/balancer"
 	"d7y.io/dragonfly/v2/pkg/dfpath"
 	"d7y.io/dragonfly/v2/pkg/gc"
+	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/resolver"
+	"d7y.io/dragonfly/v2/pkg/rpc"
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/debug"
 	"d7y.io/dragonfly/v2/scheduler/job"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
@@ -49,6 +52,9 @@ const (
 	// gracefulStopTimeout specifies a time limit for
 	// grpc server to complete a graceful shutdown.
 	gracefulStopTimeout = 10 * time.Minute
+
+	// drainCheckInterval is the interval of checking active streams when draining.
+	drainCheckInterval = 500 * time.Millisecond
 )
 
 type Server struct {
@@ -61,6 +67,9 @@ type Server struct {
 	// Metrics server.
 	metricsServer *http.Server
 
+	// Debug server.
+	debugServer *http.Server
+
 	// Manager client.
 	managerClient managerclient.Client
 
@@ -73,13 +82,26 @@ type Server struct {
 	// Storage service.
 	storage storage.Storage
 
+	// Persistence of resource.
+	persistence resource.Persistence
+
 	// GC server.
 	gc gc.GC
+
+	// Scheduler service.
+	service *service.Service
+
+	// stopKeepAlive closes the keepalive stream with manager,
+	// then manager marks scheduler inactive.
+	stopKeepAlive context.CancelFunc
 }
 
 func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 	s := &Server{config: cfg}
 
+	// task id is generated with the canonicalized url and content version like daemons
+	idgen.SetTaskIDOptions(cfg.Scheduler.TaskID.Options())
+
 	// Initialize manager client.
 	var managerClientOptions []grpc.DialOption
 	if s.config.Options.Telemetry.Jaeger != "" {
@@ -89,6 +111,14 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		)
 	}
 
+	if tlsConfig := cfg.Manager.TLS; tlsConfig != nil {
+		certReloader, err := rpc.NewCertReloader(tlsConfig.Cert, tlsConfig.Key, tlsConfig.CA, tlsConfig.ReloadInterval)
+		if err != nil {
+			return nil, err
+		}
+		managerClientOptions = append(managerClientOptions, grpc.WithTransportCredentials(certReloader.ClientCredentials(tlsConfig.ServerName)))
+	}
+
 	managerClient, err := managerclient.GetClient(cfg.Manager.Addr, managerClientOptions...)
 	if err != nil {
 		return nil, err
@@ -96,15 +126,7 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 	s.managerClient = managerClient
 
 	// Register to manager.
-	if _, err := s.managerClient.UpdateScheduler(context.Background(), &managerv1.UpdateSchedulerRequest{
-		SourceType:         managerv1.SourceType_SCHEDULER_SOURCE,
-		HostName:           s.config.Server.Host,
-		Ip:                 s.config.Server.IP,
-		Port:               int32(s.config.Server.Port),
-		Idc:                s.config.Host.IDC,
-		Location:           s.config.Host.Location,
-		SchedulerClusterId: uint64(s.config.Manager.SchedulerClusterID),
-	}); err != nil {
+	if err := s.register(context.Background()); err != nil {
 		logger.Fatalf("register to manager failed %s", err.Error())
 	}
 
@@ -132,11 +154,27 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		)
 	}
 
-	resource, err := resource.New(cfg, s.gc, dynconfig, seedPeerDialOptions...)
+	res, err := resource.New(cfg, s.gc, dynconfig, seedPeerDialOptions...)
 	if err != nil {
 		return nil, err
 	}
 
+	// Reload gc config and client load limit when scheduler cluster changes in dynconfig.
+	dynconfig.Register(res)
+
+	// Initialize persistence and restore resource from snapshot,
+	// so peers are still scheduled to each other after restart.
+	if cfg.Persistence != nil && cfg.Persistence.Enable {
+		s.persistence, err = resource.NewPersistence(cfg, res)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.persistence.Restore(ctx); err != nil {
+			logger.Errorf("restore resource failed: %s", err.Error())
+		}
+	}
+
 	// Initialize scheduler.
 	scheduler := scheduler.New(cfg.Scheduler, dynconfig, d.PluginDir())
 
@@ -148,7 +186,12 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 	s.storage = storage
 
 	// Initialize scheduler service.
-	service := service.New(cfg, resource, scheduler, dynconfig, s.storage)
+	service := service.New(cfg, res, scheduler, dynconfig, s.storage)
+
+	s.service = service
+
+	// Reschedule children of seed peers when seed peers change in dynconfig.
+	dynconfig.Register(service)
 
 	// Initialize grpc service.
 	var schedulerServerOptions []grpc.ServerOption
@@ -165,7 +208,7 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 
 	// Initialize job service.
 	if cfg.Job.Enable {
-		s.job, err = job.New(cfg, resource)
+		s.job, err = job.New(cfg, res)
 		if err != nil {
 			return nil, err
 		}
@@ -176,6 +219,11 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		s.metricsServer = metrics.New(cfg.Metrics, s.grpcServer)
 	}
 
+	// Initialize debug server.
+	if cfg.Debug != nil && cfg.Debug.Enable {
+		s.debugServer = debug.New(cfg.Debug, res.TaskManager())
+	}
+
 	return s, nil
 }
 
@@ -198,6 +246,12 @@ func (s *Server) Serve() error {
 		logger.Info("job start successfully")
 	}
 
+	// Serve persistence.
+	if s.persistence != nil {
+		go s.persistence.Serve()
+		logger.Info("persistence start successfully")
+	}
+
 	// Started metrics server.
 	if s.metricsServer != nil {
 		go func() {
@@ -211,8 +265,23 @@ func (s *Server) Serve() error {
 		}()
 	}
 
+	// Started debug server.
+	if s.debugServer != nil {
+		go func() {
+			logger.Infof("started debug server at %s", s.debugServer.Addr)
+			if err := s.debugServer.ListenAndServe(); err != nil {
+				if err == http.ErrServerClosed {
+					return
+				}
+				logger.Fatalf("debug server closed unexpect: %s", err.Error())
+			}
+		}()
+	}
+
 	if s.managerClient != nil {
 		// scheduler keepalive with manager.
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopKeepAlive = cancel
 		go func() {
 			logger.Info("start keepalive to manager")
 			s.managerClient.KeepAlive(s.config.Manager.KeepAlive.Interval, &managerv1.KeepAliveRequest{
@@ -220,12 +289,20 @@ func (s *Server) Serve() error {
 				HostName:   s.config.Server.Host,
 				Ip:         s.config.Server.IP,
 				ClusterId:  uint64(s.config.Manager.SchedulerClusterID),
-			})
+			}, managerclient.WithRegister(func(ctx context.Context) error {
+				// Register to manager again and resync dynconfig,
+				// when manager is restarted.
+				if err := s.register(ctx); err != nil {
+					return err
+				}
+
+				return s.dynconfig.Refresh()
+			}), managerclient.WithContext(ctx))
 		}()
 	}
 
 	// Generate GRPC limit listener.
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", s.config.Server.Listen, s.config.Server.Port))
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.config.Server.Listen, strconv.Itoa(s.config.Server.Port)))
 	if err != nil {
 		logger.Fatalf("net listener failed to start: %s", err.Error())
 	}
@@ -242,6 +319,9 @@ func (s *Server) Serve() error {
 }
 
 func (s *Server) Stop() {
+	// Drain scheduler before stopping.
+	s.drain()
+
 	// Stop dynconfig server.
 	if err := s.dynconfig.Stop(); err != nil {
 		logger.Errorf("dynconfig client closed failed %s", err.Error())
@@ -258,6 +338,12 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop persistence and snapshot resource.
+	if s.persistence != nil {
+		s.persistence.Stop()
+		logger.Info("persistence closed")
+	}
+
 	// Clean storage.
 	if err := s.storage.Clear(); err != nil {
 		logger.Errorf("clean storage failed %s", err.Error())
@@ -278,6 +364,15 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop debug server.
+	if s.debugServer != nil {
+		if err := s.debugServer.Shutdown(context.Background()); err != nil {
+			logger.Errorf("debug server failed to stop: %s", err.Error())
+		} else {
+			logger.Info("debug server closed under request")
+		}
+	}
+
 	// Stop GRPC server.
 	stopped := make(chan struct{})
 	go func() {
@@ -294,3 +389,48 @@ func (s *Server) Stop() {
 		t.Stop()
 	}
 }
+
+// drain rejects new peers and marks scheduler inactive in manager,
+// so manager does not return scheduler to new peers. Then existing streams
+// are still served until they are done or the drain timeout.
+func (s *Server) drain() {
+	if s.config.Server.DrainTimeout <= 0 {
+		return
+	}
+
+	logger.Infof("scheduler is draining in %s", s.config.Server.DrainTimeout)
+	s.service.Drain()
+	if s.stopKeepAlive != nil {
+		s.stopKeepAlive()
+		logger.Info("keepalive to manager stopped")
+	}
+
+	timer := time.NewTimer(s.config.Server.DrainTimeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	for s.service.ActiveStreams() > 0 {
+		select {
+		case <-timer.C:
+			logger.Warnf("scheduler drain timeout with %d active streams", s.service.ActiveStreams())
+			return
+		case <-ticker.C:
+		}
+	}
+
+	logger.Info("scheduler drained")
+}
+
+// register registers scheduler to manager.
+func (s *Server) register(ctx context.Context) error {
+	_, err := s.managerClient.UpdateScheduler(ctx, &managerv1.UpdateSchedulerRequest{
+		SourceType:         managerv1.SourceType_SCHEDULER_SOURCE,
+		HostName:           s.config.Server.Host,
+		Ip:                 s.config.Server.IP,
+		Port:               int32(s.config.Server.Port),
+		Idc:                s.config.Host.IDC,
+		Location:           s.config.Host.Location,
+		SchedulerClusterId: uint64(s.config.Manager.SchedulerClusterID),
+	})
+	return err
+}
@@ -17,6 +17,10 @@
 package evaluator
 
 import (
+	"strings"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
@@ -29,6 +33,9 @@ const (
 
 	// PluginAlgorithm is a scheduling algorithm based on plugin extension.
 	PluginAlgorithm = "plugin"
+
+	// GRPCAlgorithm is a scheduling algorithm delegating to external grpc service.
+	GRPCAlgorithm = "grpc"
 )
 
 type Evaluator interface {
@@ -39,14 +46,108 @@ type Evaluator interface {
 	IsBadNode(peer *resource.Peer) bool
 }
 
-func New(algorithm string, pluginDir string) Evaluator {
+// ParentsEvaluator evaluates the candidate parents of peer in one call,
+// evaluators calling remote services implement it to avoid one call per parent.
+type ParentsEvaluator interface {
+	// EvaluateParents returns the scores of parents in order.
+	EvaluateParents(parents []*resource.Peer, child *resource.Peer, taskPieceCount int32) []float64
+}
+
+// EvaluateParents returns the scores of parents in order, parents are evaluated
+// in one call if the evaluator implements ParentsEvaluator.
+func EvaluateParents(e Evaluator, parents []*resource.Peer, child *resource.Peer, taskPieceCount int32) []float64 {
+	if pe, ok := e.(ParentsEvaluator); ok {
+		return pe.EvaluateParents(parents, child, taskPieceCount)
+	}
+
+	scores := make([]float64, len(parents))
+	for i, parent := range parents {
+		scores[i] = e.Evaluate(parent, child, taskPieceCount)
+	}
+
+	return scores
+}
+
+// IsSameFailureDomain returns whether hosts are in the same failure domain, failure domain is made up of
+// idc and the rack switch which is the first element of net topology. Hosts without the information are
+// considered to be in the same failure domain.
+func IsSameFailureDomain(dst, src *resource.Host) bool {
+	if dst.IDC != "" && src.IDC != "" && dst.IDC != src.IDC {
+		return false
+	}
+
+	if dst.NetTopology == "" || src.NetTopology == "" {
+		return true
+	}
+
+	return strings.SplitN(dst.NetTopology, "|", 2)[0] == strings.SplitN(src.NetTopology, "|", 2)[0]
+}
+
+// IsSameSecurityDomain returns whether hosts are in the same security domain,
+// hosts without security domain can be matched with any hosts.
+func IsSameSecurityDomain(dst, src *resource.Host) bool {
+	if dst.SecurityDomain == "" || src.SecurityDomain == "" {
+		return true
+	}
+
+	return strings.EqualFold(dst.SecurityDomain, src.SecurityDomain)
+}
+
+// Option is a functional option for configuring the evaluator.
+type Option func(o *options)
+
+type options struct {
+	// grpcEvaluatorConfig is the config of external grpc evaluator.
+	grpcEvaluatorConfig *config.GRPCEvaluatorConfig
+
+	// mlEvaluatorConfig is the config of ml evaluator.
+	mlEvaluatorConfig *config.MLEvaluatorConfig
+}
+
+// WithGRPCEvaluatorConfig sets the config of external grpc evaluator.
+func WithGRPCEvaluatorConfig(cfg *config.GRPCEvaluatorConfig) Option {
+	return func(o *options) {
+		o.grpcEvaluatorConfig = cfg
+	}
+}
+
+// WithMLEvaluatorConfig sets the config of ml evaluator.
+func WithMLEvaluatorConfig(cfg *config.MLEvaluatorConfig) Option {
+	return func(o *options) {
+		o.mlEvaluatorConfig = cfg
+	}
+}
+
+func New(algorithm string, pluginDir string, opts ...Option) Evaluator {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	switch algorithm {
 	case PluginAlgorithm:
 		if plugin, err := LoadPlugin(pluginDir); err == nil {
 			return plugin
 		}
-	// TODO Implement MLAlgorithm.
-	case MLAlgorithm, DefaultAlgorithm:
+	case GRPCAlgorithm:
+		if o.grpcEvaluatorConfig != nil {
+			evaluator, err := NewEvaluatorGRPC(o.grpcEvaluatorConfig, NewEvaluatorBase())
+			if err == nil {
+				return evaluator
+			}
+
+			logger.Errorf("create grpc evaluator failed, fallback to default evaluator: %s", err.Error())
+		}
+	case MLAlgorithm:
+		if o.mlEvaluatorConfig != nil {
+			evaluator, err := NewEvaluatorML(o.mlEvaluatorConfig, NewEvaluatorBase())
+			if err == nil {
+				return evaluator
+			}
+
+			logger.Errorf("create ml evaluator failed, fallback to default evaluator: %s", err.Error())
+		}
+	case DefaultAlgorithm:
 		return NewEvaluatorBase()
 	}
 
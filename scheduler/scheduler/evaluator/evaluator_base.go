@@ -31,6 +31,9 @@ const (
 	// Finished piece weight.
 	finishedPieceWeight float64 = 0.3
 
+	// Piece rarity weight, it is added on top of the other weights.
+	pieceRarityWeight = 0.1
+
 	// Free load weight.
 	freeLoadWeight = 0.2
 
@@ -78,13 +81,12 @@ func NewEvaluatorBase() Evaluator {
 func (eb *evaluatorBase) Evaluate(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) float64 {
 	// If the SecurityDomain of hosts exists but is not equal,
 	// it cannot be scheduled as a parent.
-	if parent.Host.SecurityDomain != "" &&
-		child.Host.SecurityDomain != "" &&
-		parent.Host.SecurityDomain != child.Host.SecurityDomain {
+	if !IsSameSecurityDomain(parent.Host, child.Host) {
 		return minScore
 	}
 
 	return finishedPieceWeight*calculatePieceScore(parent, child, totalPieceCount) +
+		pieceRarityWeight*calculatePieceRarityScore(parent, child) +
 		freeLoadWeight*calculateFreeLoadScore(parent.Host) +
 		hostTypeAffinityWeight*calculateHostTypeAffinityScore(parent) +
 		idcAffinityWeight*calculateIDCAffinityScore(parent.Host, child.Host) +
@@ -108,12 +110,26 @@ func calculatePieceScore(parent *resource.Peer, child *resource.Peer, totalPiece
 	return float64(parentFinishedPieceCount) - float64(childFinishedPieceCount)
 }
 
+// calculatePieceRarityScore 0.0~1.0 larger and better.
+func calculatePieceRarityScore(parent *resource.Peer, child *resource.Peer) float64 {
+	// Parent holding the rarest piece which child lacks gets the higher score,
+	// so that rare pieces are replicated first.
+	replicaCount := parent.Task.RarestPieceReplicaCount(parent.FinishedPieces, child.FinishedPieces)
+	if replicaCount <= 0 {
+		return minScore
+	}
+
+	return maxScore / float64(replicaCount)
+}
+
 // calculateFreeLoadScore 0.0~1.0 larger and better.
 func calculateFreeLoadScore(host *resource.Host) float64 {
 	uploadLoadLimit := host.UploadLoadLimit.Load()
 	freeUploadLoad := host.FreeUploadLoad()
 	if uploadLoadLimit > 0 && freeUploadLoad > 0 {
-		return float64(freeUploadLoad) / float64(uploadLoadLimit)
+		// Host reports its cpu and memory usage with piece results,
+		// the busier host gets the lower score.
+		return float64(freeUploadLoad) / float64(uploadLoadLimit) * (maxScore - host.LoadRatio())
 	}
 
 	return minScore
@@ -302,6 +302,26 @@ func TestEvaluatorBase_calculateFreeLoadScore(t *testing.T) {
 				assert.Equal(score, float64(1))
 			},
 		},
+		{
+			name: "host reports load",
+			mock: func(host *resource.Host, mockPeer *resource.Peer) {
+				host.StoreLoad(&commonv1.HostLoad{CpuRatio: 0.25, MemRatio: 0.5})
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.5))
+			},
+		},
+		{
+			name: "host is fully loaded",
+			mock: func(host *resource.Host, mockPeer *resource.Peer) {
+				host.StoreLoad(&commonv1.HostLoad{CpuRatio: 1})
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -315,6 +335,85 @@ func TestEvaluatorBase_calculateFreeLoadScore(t *testing.T) {
 	}
 }
 
+func TestEvaluatorBase_calculatePieceRarityScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(parent *resource.Peer, child *resource.Peer, peer *resource.Peer)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name: "piece replicas are not tracked",
+			mock: func(parent *resource.Peer, child *resource.Peer, peer *resource.Peer) {
+				parent.FinishedPieces.Set(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+		{
+			name: "parent has the only replica of piece",
+			mock: func(parent *resource.Peer, child *resource.Peer, peer *resource.Peer) {
+				parent.FinishPiece(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(1))
+			},
+		},
+		{
+			name: "parent has the rarest piece of two replicas",
+			mock: func(parent *resource.Peer, child *resource.Peer, peer *resource.Peer) {
+				parent.FinishPiece(0)
+				parent.FinishPiece(1)
+				peer.FinishPiece(0)
+				peer.FinishPiece(1)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.5))
+			},
+		},
+		{
+			name: "child has finished the rarest piece",
+			mock: func(parent *resource.Peer, child *resource.Peer, peer *resource.Peer) {
+				parent.FinishPiece(0)
+				parent.FinishPiece(1)
+				child.FinishPiece(0)
+				peer.FinishPiece(1)
+				peer.FinishPiece(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0.5))
+			},
+		},
+		{
+			name: "child has finished all pieces of parent",
+			mock: func(parent *resource.Peer, child *resource.Peer, peer *resource.Peer) {
+				parent.FinishPiece(0)
+				child.FinishPiece(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(score, float64(0))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			parent := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, mockHost)
+			child := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, mockHost)
+			peer := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, mockHost)
+			tc.mock(parent, child, peer)
+			tc.expect(t, calculatePieceRarityScore(parent, child))
+		})
+	}
+}
+
 func TestEvaluatorBase_calculateHostTypeAffinityScore(t *testing.T) {
 	tests := []struct {
 		name   string
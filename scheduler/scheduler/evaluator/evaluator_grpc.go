@@ -0,0 +1,386 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/cache"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+const (
+	// grpcEvaluateMethod is the full method name of evaluating parent.
+	grpcEvaluateMethod = "/scheduler.evaluator.v1.Evaluator/Evaluate"
+
+	// grpcEvaluateParentsMethod is the full method name of evaluating candidate parents in batch.
+	grpcEvaluateParentsMethod = "/scheduler.evaluator.v1.Evaluator/EvaluateParents"
+
+	// grpcIsBadNodeMethod is the full method name of determining bad node.
+	grpcIsBadNodeMethod = "/scheduler.evaluator.v1.Evaluator/IsBadNode"
+
+	// grpcCodecName is the name of codec used by grpc evaluator,
+	// the content-type of request is application/grpc+json.
+	grpcCodecName = "json"
+)
+
+// PeerFeature is the features of peer sent to the external grpc evaluator.
+type PeerFeature struct {
+	// ID is peer id.
+	ID string `json:"id"`
+
+	// TaskID is task id.
+	TaskID string `json:"taskID"`
+
+	// State is peer state.
+	State string `json:"state"`
+
+	// FinishedPieceCount is the count of finished pieces.
+	FinishedPieceCount uint `json:"finishedPieceCount"`
+
+	// PieceCosts is the costs of downloaded pieces.
+	PieceCosts []int64 `json:"pieceCosts"`
+
+	// HostID is host id.
+	HostID string `json:"hostID"`
+
+	// HostType is host type.
+	HostType int `json:"hostType"`
+
+	// SecurityDomain is security domain of host.
+	SecurityDomain string `json:"securityDomain"`
+
+	// IDC is internet data center of host.
+	IDC string `json:"idc"`
+
+	// NetTopology is network topology of host.
+	NetTopology string `json:"netTopology"`
+
+	// Location is location of host.
+	Location string `json:"location"`
+
+	// UploadLoadLimit is upload load limit count of host.
+	UploadLoadLimit int32 `json:"uploadLoadLimit"`
+
+	// FreeUploadLoad is free upload load count of host.
+	FreeUploadLoad int32 `json:"freeUploadLoad"`
+}
+
+// EvaluateRequest is the request of evaluating parent.
+type EvaluateRequest struct {
+	Parent          *PeerFeature `json:"parent"`
+	Child           *PeerFeature `json:"child"`
+	TotalPieceCount int32        `json:"totalPieceCount"`
+}
+
+// EvaluateResponse is the response of evaluating parent.
+type EvaluateResponse struct {
+	Score float64 `json:"score"`
+}
+
+// EvaluateParentsRequest is the request of evaluating candidate parents in batch.
+type EvaluateParentsRequest struct {
+	Parents         []*PeerFeature `json:"parents"`
+	Child           *PeerFeature   `json:"child"`
+	TotalPieceCount int32          `json:"totalPieceCount"`
+}
+
+// EvaluateParentsResponse is the response of evaluating candidate parents in batch,
+// scores are in the order of parents in request.
+type EvaluateParentsResponse struct {
+	Scores []float64 `json:"scores"`
+}
+
+// IsBadNodeRequest is the request of determining bad node.
+type IsBadNodeRequest struct {
+	Peer *PeerFeature `json:"peer"`
+}
+
+// IsBadNodeResponse is the response of determining bad node.
+type IsBadNodeResponse struct {
+	IsBadNode bool `json:"isBadNode"`
+}
+
+// jsonCodec encodes grpc messages with json, so the external evaluator
+// can be implemented in any language without generated protobuf code.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return grpcCodecName
+}
+
+type evaluatorGRPC struct {
+	// conn is the connection of external grpc evaluator.
+	conn *grpc.ClientConn
+
+	// fallback evaluator is used when calling external grpc evaluator failed.
+	fallback Evaluator
+
+	// cache stores evaluation results.
+	cache cache.Cache
+
+	// timeout is the timeout of calling external grpc evaluator.
+	timeout time.Duration
+
+	// cacheTTL is the ttl of evaluation results.
+	cacheTTL time.Duration
+
+	// breaker stops calling external grpc evaluator after consecutive failures.
+	breaker *circuitBreaker
+}
+
+// NewEvaluatorGRPC returns an evaluator delegating to external grpc service.
+func NewEvaluatorGRPC(cfg *config.GRPCEvaluatorConfig, fallback Evaluator) (Evaluator, error) {
+	conn, err := grpc.Dial(
+		cfg.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &evaluatorGRPC{
+		conn:     conn,
+		fallback: fallback,
+		cache:    cache.New(cfg.CacheTTL, 2*cfg.CacheTTL),
+		timeout:  cfg.Timeout,
+		cacheTTL: cfg.CacheTTL,
+		breaker: &circuitBreaker{
+			failureThreshold: cfg.FailureThreshold,
+			cooldown:         cfg.Cooldown,
+		},
+	}, nil
+}
+
+// Evaluate delegates evaluation to external grpc evaluator,
+// if calling failed, use fallback evaluator.
+func (eg *evaluatorGRPC) Evaluate(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) float64 {
+	key := evaluateCacheKey(parent, child, totalPieceCount)
+	if score, ok := eg.getCache(key); ok {
+		return score.(float64)
+	}
+
+	resp := &EvaluateResponse{}
+	if err := eg.invoke(grpcEvaluateMethod, &EvaluateRequest{
+		Parent:          newPeerFeature(parent),
+		Child:           newPeerFeature(child),
+		TotalPieceCount: totalPieceCount,
+	}, resp, nil); err != nil {
+		if !errors.Is(err, errCircuitOpen) {
+			logger.Warnf("grpc evaluator evaluates parent %s failed, fallback to built-in evaluator: %s", parent.ID, err.Error())
+		}
+		return eg.fallback.Evaluate(parent, child, totalPieceCount)
+	}
+
+	eg.setCache(key, resp.Score)
+	return resp.Score
+}
+
+// EvaluateParents delegates evaluation of the uncached parents to external grpc evaluator
+// in one call, if calling failed, use fallback evaluator.
+func (eg *evaluatorGRPC) EvaluateParents(parents []*resource.Peer, child *resource.Peer, totalPieceCount int32) []float64 {
+	scores := make([]float64, len(parents))
+	var (
+		uncached []int
+		features []*PeerFeature
+	)
+	for i, parent := range parents {
+		if score, ok := eg.getCache(evaluateCacheKey(parent, child, totalPieceCount)); ok {
+			scores[i] = score.(float64)
+			continue
+		}
+
+		uncached = append(uncached, i)
+		features = append(features, newPeerFeature(parent))
+	}
+
+	if len(uncached) == 0 {
+		return scores
+	}
+
+	resp := &EvaluateParentsResponse{}
+	if err := eg.invoke(grpcEvaluateParentsMethod, &EvaluateParentsRequest{
+		Parents:         features,
+		Child:           newPeerFeature(child),
+		TotalPieceCount: totalPieceCount,
+	}, resp, func() error {
+		if len(resp.Scores) != len(features) {
+			return fmt.Errorf("got %d scores of %d parents", len(resp.Scores), len(features))
+		}
+
+		return nil
+	}); err != nil {
+		if !errors.Is(err, errCircuitOpen) {
+			child.Log.Warnf("grpc evaluator evaluates %d parents failed, fallback to built-in evaluator: %s", len(features), err.Error())
+		}
+		for _, i := range uncached {
+			scores[i] = eg.fallback.Evaluate(parents[i], child, totalPieceCount)
+		}
+
+		return scores
+	}
+
+	for j, i := range uncached {
+		scores[i] = resp.Scores[j]
+		eg.setCache(evaluateCacheKey(parents[i], child, totalPieceCount), resp.Scores[j])
+	}
+
+	return scores
+}
+
+// IsBadNode delegates determining bad node to external grpc evaluator,
+// if calling failed, use fallback evaluator.
+func (eg *evaluatorGRPC) IsBadNode(peer *resource.Peer) bool {
+	key := fmt.Sprintf("isBadNode:%s", peer.ID)
+	if isBadNode, ok := eg.getCache(key); ok {
+		return isBadNode.(bool)
+	}
+
+	resp := &IsBadNodeResponse{}
+	if err := eg.invoke(grpcIsBadNodeMethod, &IsBadNodeRequest{
+		Peer: newPeerFeature(peer),
+	}, resp, nil); err != nil {
+		if !errors.Is(err, errCircuitOpen) {
+			peer.Log.Warnf("grpc evaluator determines bad node failed, fallback to built-in evaluator: %s", err.Error())
+		}
+		return eg.fallback.IsBadNode(peer)
+	}
+
+	eg.setCache(key, resp.IsBadNode)
+	return resp.IsBadNode
+}
+
+// invoke calls method of external grpc evaluator, validate checks the response if it is not nil.
+// Calls fail fast when the circuit breaker is open.
+func (eg *evaluatorGRPC) invoke(method string, req, resp any, validate func() error) error {
+	if !eg.breaker.allow() {
+		return errCircuitOpen
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eg.timeout)
+	defer cancel()
+
+	err := eg.conn.Invoke(ctx, method, req, resp)
+	if err == nil && validate != nil {
+		err = validate()
+	}
+
+	eg.breaker.done(err)
+	return err
+}
+
+// getCache returns cached evaluation result.
+func (eg *evaluatorGRPC) getCache(key string) (any, bool) {
+	if eg.cacheTTL <= 0 {
+		return nil, false
+	}
+
+	return eg.cache.Get(key)
+}
+
+// setCache stores evaluation result.
+func (eg *evaluatorGRPC) setCache(key string, value any) {
+	if eg.cacheTTL <= 0 {
+		return
+	}
+
+	eg.cache.SetDefault(key, value)
+}
+
+// evaluateCacheKey returns the cache key of evaluation result.
+func evaluateCacheKey(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) string {
+	return fmt.Sprintf("evaluate:%s:%s:%d", parent.ID, child.ID, totalPieceCount)
+}
+
+// errCircuitOpen is returned when external grpc evaluator is not called during cooldown.
+var errCircuitOpen = errors.New("grpc evaluator is in cooldown after consecutive failures")
+
+// circuitBreaker opens after consecutive failed calls, calls are rejected until cooldown passed,
+// then the next failed call opens it again and a succeeded call closes it.
+type circuitBreaker struct {
+	// failureThreshold is the count of consecutive failures opening the breaker, 0 disables breaker.
+	failureThreshold int
+
+	// cooldown is the duration of breaker keeping open.
+	cooldown time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// allow returns whether the call is allowed.
+func (cb *circuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return !time.Now().Before(cb.openUntil)
+}
+
+// done records the result of call.
+func (cb *circuitBreaker) done(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err == nil {
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	if cb.failureThreshold > 0 && cb.failures >= cb.failureThreshold {
+		logger.Warnf("grpc evaluator failed %d times, fallback to built-in evaluator for %s: %s", cb.failures, cb.cooldown, err.Error())
+		cb.openUntil = time.Now().Add(cb.cooldown)
+	}
+}
+
+// newPeerFeature generates features of peer.
+func newPeerFeature(peer *resource.Peer) *PeerFeature {
+	return &PeerFeature{
+		ID:                 peer.ID,
+		TaskID:             peer.Task.ID,
+		State:              peer.FSM.Current(),
+		FinishedPieceCount: peer.FinishedPieces.Count(),
+		PieceCosts:         peer.PieceCosts(),
+		HostID:             peer.Host.ID,
+		HostType:           int(peer.Host.Type),
+		SecurityDomain:     peer.Host.SecurityDomain,
+		IDC:                peer.Host.IDC,
+		NetTopology:        peer.Host.NetTopology,
+		Location:           peer.Host.Location,
+		UploadLoadLimit:    peer.Host.UploadLoadLimit.Load(),
+		FreeUploadLoad:     peer.Host.FreeUploadLoad(),
+	}
+}
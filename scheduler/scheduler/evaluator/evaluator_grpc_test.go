@@ -0,0 +1,205 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+// newMockGRPCEvaluatorServer starts a grpc server handling evaluator methods with json codec.
+func newMockGRPCEvaluatorServer(t *testing.T, score float64, isBadNode bool, calls *atomic.Int32) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnknownServiceHandler(func(srv any, stream grpc.ServerStream) error {
+			calls.Inc()
+			method, _ := grpc.MethodFromServerStream(stream)
+			switch method {
+			case grpcEvaluateMethod:
+				req := &EvaluateRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+
+				return stream.SendMsg(&EvaluateResponse{Score: score})
+			case grpcEvaluateParentsMethod:
+				req := &EvaluateParentsRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+
+				resp := &EvaluateParentsResponse{}
+				for range req.Parents {
+					resp.Scores = append(resp.Scores, score)
+				}
+				return stream.SendMsg(resp)
+			default:
+				req := &IsBadNodeRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+
+				return stream.SendMsg(&IsBadNodeResponse{IsBadNode: isBadNode})
+			}
+		}),
+	)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+// newFailedGRPCEvaluatorServer starts a grpc server failing all evaluator methods.
+func newFailedGRPCEvaluatorServer(t *testing.T, calls *atomic.Int32) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnknownServiceHandler(func(srv any, stream grpc.ServerStream) error {
+			calls.Inc()
+			return status.Error(codes.Unavailable, "unavailable")
+		}),
+	)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lis.Addr().String()
+}
+
+func TestEvaluatorGRPC_Evaluate(t *testing.T) {
+	mockHost := resource.NewHost(mockRawHost)
+	mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, parent *resource.Peer, child *resource.Peer)
+	}{
+		{
+			name: "evaluate by external grpc evaluator and cache result",
+			run: func(t *testing.T, parent *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				calls := atomic.NewInt32(0)
+				addr := newMockGRPCEvaluatorServer(t, 0.5, true, calls)
+				e, err := NewEvaluatorGRPC(&config.GRPCEvaluatorConfig{
+					Addr:     addr,
+					Timeout:  5 * time.Second,
+					CacheTTL: time.Minute,
+				}, NewEvaluatorBase())
+				assert.NoError(err)
+
+				assert.Equal(e.Evaluate(parent, child, 1), float64(0.5))
+				assert.Equal(e.Evaluate(parent, child, 1), float64(0.5))
+				assert.True(e.IsBadNode(parent))
+				assert.True(e.IsBadNode(parent))
+				assert.Equal(calls.Load(), int32(2))
+			},
+		},
+		{
+			name: "external grpc evaluator is unavailable",
+			run: func(t *testing.T, parent *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				e, err := NewEvaluatorGRPC(&config.GRPCEvaluatorConfig{
+					Addr:     "127.0.0.1:1",
+					Timeout:  100 * time.Millisecond,
+					CacheTTL: time.Minute,
+				}, NewEvaluatorBase())
+				assert.NoError(err)
+
+				parent.FinishedPieces.Set(0)
+				eb := NewEvaluatorBase()
+				assert.Equal(e.Evaluate(parent, child, 1), eb.Evaluate(parent, child, 1))
+				assert.Equal(e.IsBadNode(parent), eb.IsBadNode(parent))
+			},
+		},
+		{
+			name: "evaluate parents in one call",
+			run: func(t *testing.T, parent *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				calls := atomic.NewInt32(0)
+				addr := newMockGRPCEvaluatorServer(t, 0.5, false, calls)
+				e, err := NewEvaluatorGRPC(&config.GRPCEvaluatorConfig{
+					Addr:     addr,
+					Timeout:  5 * time.Second,
+					CacheTTL: time.Minute,
+				}, NewEvaluatorBase())
+				assert.NoError(err)
+
+				parents := []*resource.Peer{
+					parent,
+					resource.NewPeer(idgen.PeerID("127.0.0.2"), mockTask, mockHost),
+					resource.NewPeer(idgen.PeerID("127.0.0.3"), mockTask, mockHost),
+				}
+				assert.Equal([]float64{0.5, 0.5, 0.5}, EvaluateParents(e, parents, child, 1))
+				assert.Equal([]float64{0.5, 0.5, 0.5}, EvaluateParents(e, parents, child, 1))
+				assert.Equal(float64(0.5), e.Evaluate(parent, child, 1))
+				assert.Equal(int32(1), calls.Load())
+			},
+		},
+		{
+			name: "fallback to built-in evaluator during cooldown after consecutive failures",
+			run: func(t *testing.T, parent *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				calls := atomic.NewInt32(0)
+				addr := newFailedGRPCEvaluatorServer(t, calls)
+				e, err := NewEvaluatorGRPC(&config.GRPCEvaluatorConfig{
+					Addr:             addr,
+					Timeout:          5 * time.Second,
+					FailureThreshold: 2,
+					Cooldown:         time.Minute,
+				}, NewEvaluatorBase())
+				assert.NoError(err)
+
+				eb := NewEvaluatorBase()
+				parents := []*resource.Peer{parent}
+				for i := 0; i < 4; i++ {
+					assert.Equal(EvaluateParents(eb, parents, child, 1), EvaluateParents(e, parents, child, 1))
+					assert.Equal(eb.IsBadNode(parent), e.IsBadNode(parent))
+				}
+				assert.Equal(int32(2), calls.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parent := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, mockHost)
+			child := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, mockHost)
+			tc.run(t, parent, child)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+const (
+	// MLModelTypeLinear is the linear model, the score is the weighted sum of features.
+	MLModelTypeLinear = "linear"
+
+	// MLModelTypeLogistic is the logistic model, the weighted sum of features is squashed by sigmoid.
+	MLModelTypeLogistic = "logistic"
+)
+
+// FeatureExtractor extracts a feature of parent for child from peer, host and task attributes.
+type FeatureExtractor func(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) float64
+
+var (
+	featureExtractorsMu sync.RWMutex
+
+	// featureExtractors are the extractors referenced by the features of model.
+	featureExtractors = map[string]FeatureExtractor{
+		"finishedPieceScore": calculatePieceScore,
+		"pieceRarityScore": func(parent *resource.Peer, child *resource.Peer, _ int32) float64 {
+			return calculatePieceRarityScore(parent, child)
+		},
+		"freeLoadScore": func(parent *resource.Peer, _ *resource.Peer, _ int32) float64 {
+			return calculateFreeLoadScore(parent.Host)
+		},
+		"hostTypeAffinityScore": func(parent *resource.Peer, _ *resource.Peer, _ int32) float64 {
+			return calculateHostTypeAffinityScore(parent)
+		},
+		"idcAffinityScore": func(parent *resource.Peer, child *resource.Peer, _ int32) float64 {
+			return calculateIDCAffinityScore(parent.Host, child.Host)
+		},
+		"netTopologyAffinityScore": func(parent *resource.Peer, child *resource.Peer, _ int32) float64 {
+			return calculateMultiElementAffinityScore(parent.Host.NetTopology, child.Host.NetTopology)
+		},
+		"locationAffinityScore": func(parent *resource.Peer, child *resource.Peer, _ int32) float64 {
+			return calculateMultiElementAffinityScore(parent.Host.Location, child.Host.Location)
+		},
+		"sameFailureDomain": func(parent *resource.Peer, child *resource.Peer, _ int32) float64 {
+			if IsSameFailureDomain(parent.Host, child.Host) {
+				return maxScore
+			}
+
+			return minScore
+		},
+		"parentLoadRatio": func(parent *resource.Peer, _ *resource.Peer, _ int32) float64 {
+			return parent.Host.LoadRatio()
+		},
+	}
+)
+
+// RegisterFeatureExtractor registers the feature extractor with name, so the features
+// of model can reference it, the extractor registered with the same name is replaced.
+func RegisterFeatureExtractor(name string, extractor FeatureExtractor) {
+	featureExtractorsMu.Lock()
+	defer featureExtractorsMu.Unlock()
+
+	featureExtractors[name] = extractor
+}
+
+// getFeatureExtractor returns the feature extractor registered with name.
+func getFeatureExtractor(name string) (FeatureExtractor, bool) {
+	featureExtractorsMu.RLock()
+	defer featureExtractorsMu.RUnlock()
+
+	extractor, ok := featureExtractors[name]
+	return extractor, ok
+}
+
+// MLModel is the model trained offline for ml evaluator, it is serialized in json.
+type MLModel struct {
+	// Version of model, it is logged when the model is loaded.
+	Version string `json:"version"`
+
+	// Type of model, linear or logistic.
+	Type string `json:"type"`
+
+	// Bias is added to the weighted sum of features.
+	Bias float64 `json:"bias"`
+
+	// Features is the feature extraction pipeline, features are extracted in order.
+	Features []*MLFeature `json:"features"`
+}
+
+// MLFeature is a feature of model.
+type MLFeature struct {
+	// Name of feature extractor.
+	Name string `json:"name"`
+
+	// Weight of feature.
+	Weight float64 `json:"weight"`
+
+	// Mean of feature in training data, the feature is standardized with mean and std.
+	Mean float64 `json:"mean"`
+
+	// Std of feature in training data, zero disables standardization.
+	Std float64 `json:"std"`
+}
+
+// mlModel is the model with resolved feature extractors.
+type mlModel struct {
+	*MLModel
+	extractors []FeatureExtractor
+}
+
+// loadMLModel loads model from file and resolves feature extractors of model.
+func loadMLModel(path string) (*mlModel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &MLModel{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, err
+	}
+
+	switch model.Type {
+	case MLModelTypeLinear, MLModelTypeLogistic:
+	default:
+		return nil, fmt.Errorf("invalid model type %q", model.Type)
+	}
+
+	if len(model.Features) == 0 {
+		return nil, errors.New("model has no features")
+	}
+
+	extractors := make([]FeatureExtractor, 0, len(model.Features))
+	for _, feature := range model.Features {
+		extractor, ok := getFeatureExtractor(feature.Name)
+		if !ok {
+			return nil, fmt.Errorf("feature extractor %q is not registered", feature.Name)
+		}
+
+		extractors = append(extractors, extractor)
+	}
+
+	return &mlModel{
+		MLModel:    model,
+		extractors: extractors,
+	}, nil
+}
+
+// score returns the score of parent for child.
+func (m *mlModel) score(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) float64 {
+	sum := m.Bias
+	for i, feature := range m.Features {
+		value := m.extractors[i](parent, child, totalPieceCount)
+		if feature.Std > 0 {
+			value = (value - feature.Mean) / feature.Std
+		}
+
+		sum += feature.Weight * value
+	}
+
+	if m.Type == MLModelTypeLogistic {
+		return 1 / (1 + math.Exp(-sum))
+	}
+
+	return sum
+}
+
+type evaluatorML struct {
+	// modelPath is the path of model file.
+	modelPath string
+
+	// reloadInterval is the interval for checking whether the model file is changed.
+	reloadInterval time.Duration
+
+	// model is the model in use.
+	model *atomic.Value
+
+	// modTime is the modification time of loaded model file in nanoseconds.
+	modTime *atomic.Int64
+
+	// nextCheck is the time to check the model file in nanoseconds.
+	nextCheck *atomic.Int64
+
+	// fallback evaluator determines bad node, which is not scored by model.
+	fallback Evaluator
+}
+
+// NewEvaluatorML returns an evaluator scoring parents with the model trained offline.
+func NewEvaluatorML(cfg *config.MLEvaluatorConfig, fallback Evaluator) (Evaluator, error) {
+	info, err := os.Stat(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := loadMLModel(cfg.ModelPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("ml evaluator loads model %s, version: %s", cfg.ModelPath, model.Version)
+
+	em := &evaluatorML{
+		modelPath:      cfg.ModelPath,
+		reloadInterval: cfg.ReloadInterval,
+		model:          &atomic.Value{},
+		modTime:        atomic.NewInt64(info.ModTime().UnixNano()),
+		nextCheck:      atomic.NewInt64(time.Now().Add(cfg.ReloadInterval).UnixNano()),
+		fallback:       fallback,
+	}
+	em.model.Store(model)
+
+	return em, nil
+}
+
+// Evaluate scores parent with the model, the larger the value after evaluation, the higher the priority.
+func (em *evaluatorML) Evaluate(parent *resource.Peer, child *resource.Peer, totalPieceCount int32) float64 {
+	// If the SecurityDomain of hosts exists but is not equal,
+	// it cannot be scheduled as a parent.
+	if !IsSameSecurityDomain(parent.Host, child.Host) {
+		return minScore
+	}
+
+	em.reloadIfChanged()
+	return em.model.Load().(*mlModel).score(parent, child, totalPieceCount)
+}
+
+// IsBadNode determines bad node with fallback evaluator.
+func (em *evaluatorML) IsBadNode(peer *resource.Peer) bool {
+	return em.fallback.IsBadNode(peer)
+}
+
+// reloadIfChanged reloads the model when the model file is changed, the file is checked
+// at most once every reload interval, and the former model is kept when reloading failed.
+func (em *evaluatorML) reloadIfChanged() {
+	if em.reloadInterval <= 0 {
+		return
+	}
+
+	now := time.Now().UnixNano()
+	nextCheck := em.nextCheck.Load()
+	if now < nextCheck || !em.nextCheck.CAS(nextCheck, now+int64(em.reloadInterval)) {
+		return
+	}
+
+	info, err := os.Stat(em.modelPath)
+	if err != nil {
+		logger.Warnf("ml evaluator stats model %s failed: %s", em.modelPath, err.Error())
+		return
+	}
+
+	if info.ModTime().UnixNano() == em.modTime.Load() {
+		return
+	}
+
+	model, err := loadMLModel(em.modelPath)
+	if err != nil {
+		logger.Errorf("ml evaluator reloads model %s failed, keep the former model: %s", em.modelPath, err.Error())
+		return
+	}
+
+	em.model.Store(model)
+	em.modTime.Store(info.ModTime().UnixNano())
+	logger.Infof("ml evaluator reloads model %s, version: %s", em.modelPath, model.Version)
+}
@@ -0,0 +1,212 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package evaluator
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+// writeMLModel writes model file and sets its modification time.
+func writeMLModel(t *testing.T, path string, model string, modTime time.Time) {
+	if err := os.WriteFile(path, []byte(model), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvaluatorML_NewEvaluatorML(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  string
+		expect func(t *testing.T, e Evaluator, err error)
+	}{
+		{
+			name:  "load linear model",
+			model: `{"version": "v1", "type": "linear", "bias": 0.1, "features": [{"name": "idcAffinityScore", "weight": 0.5}]}`,
+			expect: func(t *testing.T, e Evaluator, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("v1", e.(*evaluatorML).model.Load().(*mlModel).Version)
+			},
+		},
+		{
+			name:  "invalid json",
+			model: `{"version": "v1"`,
+			expect: func(t *testing.T, e Evaluator, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+		{
+			name:  "invalid model type",
+			model: `{"version": "v1", "type": "tree", "features": [{"name": "idcAffinityScore", "weight": 0.5}]}`,
+			expect: func(t *testing.T, e Evaluator, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, `invalid model type "tree"`)
+			},
+		},
+		{
+			name:  "model has no features",
+			model: `{"version": "v1", "type": "linear"}`,
+			expect: func(t *testing.T, e Evaluator, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "model has no features")
+			},
+		},
+		{
+			name:  "feature extractor is not registered",
+			model: `{"version": "v1", "type": "linear", "features": [{"name": "foo", "weight": 0.5}]}`,
+			expect: func(t *testing.T, e Evaluator, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, `feature extractor "foo" is not registered`)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "model.json")
+			writeMLModel(t, path, tc.model, time.Now())
+			e, err := NewEvaluatorML(&config.MLEvaluatorConfig{ModelPath: path}, NewEvaluatorBase())
+			tc.expect(t, e, err)
+		})
+	}
+
+	t.Run("model file not found", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := NewEvaluatorML(&config.MLEvaluatorConfig{ModelPath: filepath.Join(t.TempDir(), "model.json")}, NewEvaluatorBase())
+		assert.ErrorIs(err, os.ErrNotExist)
+	})
+}
+
+func TestEvaluatorML_Evaluate(t *testing.T) {
+	RegisterFeatureExtractor("constant", func(_ *resource.Peer, _ *resource.Peer, _ int32) float64 {
+		return 3
+	})
+
+	tests := []struct {
+		name   string
+		model  string
+		mock   func(parent *resource.Peer, child *resource.Peer)
+		expect func(t *testing.T, score float64)
+	}{
+		{
+			name:  "linear model",
+			model: `{"type": "linear", "bias": 0.1, "features": [{"name": "idcAffinityScore", "weight": 0.5}, {"name": "finishedPieceScore", "weight": 0.2}]}`,
+			mock: func(parent *resource.Peer, child *resource.Peer) {
+				parent.Host.IDC = "foo"
+				child.Host.IDC = "foo"
+				parent.FinishedPieces.Set(0)
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.InDelta(0.1+0.5*1+0.2*0.5, score, 1e-9)
+			},
+		},
+		{
+			name:  "logistic model with standardized feature",
+			model: `{"type": "logistic", "features": [{"name": "constant", "weight": 2, "mean": 1, "std": 2}]}`,
+			mock:  func(parent *resource.Peer, child *resource.Peer) {},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.InDelta(1/(1+math.Exp(-2)), score, 1e-9)
+			},
+		},
+		{
+			name:  "security domains are not the same",
+			model: `{"type": "linear", "bias": 1, "features": [{"name": "constant", "weight": 1}]}`,
+			mock: func(parent *resource.Peer, child *resource.Peer) {
+				parent.Host.SecurityDomain = "foo"
+				child.Host.SecurityDomain = "bar"
+			},
+			expect: func(t *testing.T, score float64) {
+				assert := assert.New(t)
+				assert.Equal(float64(minScore), score)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			parent := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, resource.NewHost(mockRawHost))
+			child := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, resource.NewHost(mockRawHost))
+			tc.mock(parent, child)
+
+			path := filepath.Join(t.TempDir(), "model.json")
+			writeMLModel(t, path, tc.model, time.Now())
+			e, err := NewEvaluatorML(&config.MLEvaluatorConfig{ModelPath: path}, NewEvaluatorBase())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, e.Evaluate(parent, child, 2))
+		})
+	}
+}
+
+func TestEvaluatorML_Reload(t *testing.T) {
+	assert := assert.New(t)
+	mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+	parent := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, resource.NewHost(mockRawHost))
+	child := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, resource.NewHost(mockRawHost))
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeMLModel(t, path, `{"version": "v1", "type": "linear", "bias": 1, "features": [{"name": "idcAffinityScore", "weight": 0}]}`, modTime)
+	e, err := NewEvaluatorML(&config.MLEvaluatorConfig{ModelPath: path, ReloadInterval: time.Millisecond}, NewEvaluatorBase())
+	assert.NoError(err)
+	assert.Equal(float64(1), e.Evaluate(parent, child, 1))
+
+	// changed model is reloaded after reload interval
+	writeMLModel(t, path, `{"version": "v2", "type": "linear", "bias": 2, "features": [{"name": "idcAffinityScore", "weight": 0}]}`, modTime.Add(time.Minute))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(float64(2), e.Evaluate(parent, child, 1))
+
+	// former model is kept when the changed model is invalid
+	writeMLModel(t, path, `{"version": "v3", "type": "tree"}`, modTime.Add(2*time.Minute))
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(float64(2), e.Evaluate(parent, child, 1))
+	assert.Equal("v2", e.(*evaluatorML).model.Load().(*mlModel).Version)
+}
+
+func TestEvaluatorML_IsBadNode(t *testing.T) {
+	assert := assert.New(t)
+	mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+	peer := resource.NewPeer(idgen.PeerID("127.0.0.1"), mockTask, resource.NewHost(mockRawHost))
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	writeMLModel(t, path, `{"type": "linear", "features": [{"name": "idcAffinityScore", "weight": 1}]}`, time.Now())
+	e, err := NewEvaluatorML(&config.MLEvaluatorConfig{ModelPath: path}, NewEvaluatorBase())
+	assert.NoError(err)
+	assert.Equal(NewEvaluatorBase().IsBadNode(peer), e.IsBadNode(peer))
+}
@@ -19,8 +19,12 @@ package evaluator
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
 func TestEvaluator_New(t *testing.T) {
@@ -28,6 +32,7 @@ func TestEvaluator_New(t *testing.T) {
 	tests := []struct {
 		name      string
 		algorithm string
+		options   []Option
 		expect    func(t *testing.T, e any)
 	}{
 		{
@@ -54,6 +59,50 @@ func TestEvaluator_New(t *testing.T) {
 				assert.Equal(reflect.TypeOf(e).Elem().Name(), "evaluatorBase")
 			},
 		},
+		{
+			name:      "new evaluator with grpc",
+			algorithm: "grpc",
+			options: []Option{WithGRPCEvaluatorConfig(&config.GRPCEvaluatorConfig{
+				Addr:     "127.0.0.1:65002",
+				Timeout:  100 * time.Millisecond,
+				CacheTTL: 5 * time.Second,
+			})},
+			expect: func(t *testing.T, e any) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(e).Elem().Name(), "evaluatorGRPC")
+			},
+		},
+		{
+			name:      "new evaluator with grpc but without config",
+			algorithm: "grpc",
+			expect: func(t *testing.T, e any) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(e).Elem().Name(), "evaluatorBase")
+			},
+		},
+		{
+			name:      "new evaluator with ml model",
+			algorithm: "ml",
+			options: []Option{WithMLEvaluatorConfig(&config.MLEvaluatorConfig{
+				ModelPath:      "testdata/ml_model.json",
+				ReloadInterval: 30 * time.Second,
+			})},
+			expect: func(t *testing.T, e any) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(e).Elem().Name(), "evaluatorML")
+			},
+		},
+		{
+			name:      "new evaluator with ml but model not found",
+			algorithm: "ml",
+			options: []Option{WithMLEvaluatorConfig(&config.MLEvaluatorConfig{
+				ModelPath: "testdata/foo.json",
+			})},
+			expect: func(t *testing.T, e any) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(e).Elem().Name(), "evaluatorBase")
+			},
+		},
 		{
 			name:      "new evaluator with empty string",
 			algorithm: "",
@@ -66,7 +115,83 @@ func TestEvaluator_New(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			tc.expect(t, New(tc.algorithm, pluginDir))
+			tc.expect(t, New(tc.algorithm, pluginDir, tc.options...))
+		})
+	}
+}
+
+func TestEvaluator_IsSameFailureDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		dst    *resource.Host
+		src    *resource.Host
+		expect bool
+	}{
+		{
+			name:   "idc and rack are the same",
+			dst:    &resource.Host{IDC: "foo", NetTopology: "rack1|switch1"},
+			src:    &resource.Host{IDC: "foo", NetTopology: "rack1|switch2"},
+			expect: true,
+		},
+		{
+			name:   "idc is different",
+			dst:    &resource.Host{IDC: "foo", NetTopology: "rack1"},
+			src:    &resource.Host{IDC: "bar", NetTopology: "rack1"},
+			expect: false,
+		},
+		{
+			name:   "rack is different",
+			dst:    &resource.Host{IDC: "foo", NetTopology: "rack1|switch1"},
+			src:    &resource.Host{IDC: "foo", NetTopology: "rack2|switch1"},
+			expect: false,
+		},
+		{
+			name:   "failure domain is unknown",
+			dst:    &resource.Host{},
+			src:    &resource.Host{IDC: "foo", NetTopology: "rack1"},
+			expect: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expect, IsSameFailureDomain(tc.dst, tc.src))
+		})
+	}
+}
+
+func TestEvaluator_IsSameSecurityDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		dst    *resource.Host
+		src    *resource.Host
+		expect bool
+	}{
+		{
+			name:   "security domains are the same",
+			dst:    &resource.Host{SecurityDomain: "foo"},
+			src:    &resource.Host{SecurityDomain: "FOO"},
+			expect: true,
+		},
+		{
+			name:   "security domains are different",
+			dst:    &resource.Host{SecurityDomain: "foo"},
+			src:    &resource.Host{SecurityDomain: "bar"},
+			expect: false,
+		},
+		{
+			name:   "security domain is empty",
+			dst:    &resource.Host{},
+			src:    &resource.Host{SecurityDomain: "bar"},
+			expect: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(tc.expect, IsSameSecurityDomain(tc.dst, tc.src))
 		})
 	}
 }
@@ -51,6 +51,20 @@ func (mr *MockSchedulerMockRecorder) FindParent(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindParent", reflect.TypeOf((*MockScheduler)(nil).FindParent), arg0, arg1, arg2)
 }
 
+// NeedAdjustParent mocks base method.
+func (m *MockScheduler) NeedAdjustParent(arg0 *resource.Peer) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedAdjustParent", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedAdjustParent indicates an expected call of NeedAdjustParent.
+func (mr *MockSchedulerMockRecorder) NeedAdjustParent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedAdjustParent", reflect.TypeOf((*MockScheduler)(nil).NeedAdjustParent), arg0)
+}
+
 // NotifyAndFindParent mocks base method.
 func (m *MockScheduler) NotifyAndFindParent(arg0 context.Context, arg1 *resource.Peer, arg2 set.SafeSet[string]) ([]*resource.Peer, bool) {
 	m.ctrl.T.Helper()
@@ -20,6 +20,8 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sort"
 	"time"
 
@@ -27,7 +29,10 @@ import (
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/pkg/container/set"
+	"d7y.io/dragonfly/v2/pkg/math"
+	"d7y.io/dragonfly/v2/pkg/net/ip"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/scheduler/evaluator"
 )
@@ -41,6 +46,10 @@ type Scheduler interface {
 
 	// Find the parent that best matches the evaluation.
 	FindParent(context.Context, *resource.Peer, set.SafeSet[string]) (*resource.Peer, bool)
+
+	// NeedAdjustParent determines whether the peer needs to switch parent
+	// because downloading from parent is degraded.
+	NeedAdjustParent(*resource.Peer) bool
 }
 
 type scheduler struct {
@@ -56,7 +65,12 @@ type scheduler struct {
 
 func New(cfg *config.SchedulerConfig, dynconfig config.DynconfigInterface, pluginDir string) Scheduler {
 	return &scheduler{
-		evaluator: evaluator.New(cfg.Algorithm, pluginDir),
+		evaluator: evaluator.New(
+			cfg.Algorithm,
+			pluginDir,
+			evaluator.WithGRPCEvaluatorConfig(cfg.GRPCEvaluator),
+			evaluator.WithMLEvaluatorConfig(cfg.MLEvaluator),
+		),
 		config:    cfg,
 		dynconfig: dynconfig,
 	}
@@ -74,40 +88,22 @@ func (s *scheduler) ScheduleParent(ctx context.Context, peer *resource.Peer, blo
 		}
 
 		// If the scheduling exceeds the RetryBackSourceLimit or peer needs back-to-source,
-		// peer will download the task back-to-source.
+		// peer will download the task back-to-source when the back-to-source slot of task is reserved,
+		// otherwise peer is queued and will be promoted when a back-to-source peer failed.
 		needBackToSource := peer.NeedBackToSource.Load()
-		if (n >= s.config.RetryBackSourceLimit || needBackToSource) &&
-			peer.Task.CanBackToSource() {
-			stream, ok := peer.LoadStream()
-			if !ok {
-				peer.Log.Error("load stream failed")
-				return
-			}
-
-			peer.Log.Infof("peer downloads back-to-source, scheduling %d times, peer need back-to-source %t",
-				n, needBackToSource)
-
-			// Notify peer back-to-source.
-			if err := stream.Send(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedNeedBackSource}); err != nil {
-				peer.Log.Errorf("send packet failed: %s", err.Error())
-				return
-			}
+		if n >= s.config.RetryBackSourceLimit || needBackToSource {
+			if peer.Task.ReserveBackToSource(peer.ID) {
+				peer.Log.Infof("peer downloads back-to-source, scheduling %d times, peer need back-to-source %t",
+					n, needBackToSource)
+				if err := s.notifyBackToSource(peer); err != nil {
+					peer.Log.Error(err)
+				}
 
-			if err := peer.FSM.Event(resource.PeerEventDownloadFromBackToSource); err != nil {
-				peer.Log.Errorf("peer fsm event failed: %s", err.Error())
 				return
 			}
 
-			// If the task state is TaskStateFailed,
-			// peer back-to-source and reset task state to TaskStateRunning.
-			if peer.Task.FSM.Is(resource.TaskStateFailed) {
-				if err := peer.Task.FSM.Event(resource.TaskEventDownload); err != nil {
-					peer.Task.Log.Errorf("task fsm event failed: %s", err.Error())
-					return
-				}
-			}
-
-			return
+			peer.Log.Infof("back-to-source peers of task reach the limit %d, peer is queued", peer.Task.BackToSourceLimit.Load())
+			peer.Task.EnqueueBackToSource(peer.ID)
 		}
 
 		// Handle peer schedule failed.
@@ -129,6 +125,7 @@ func (s *scheduler) ScheduleParent(ctx context.Context, peer *resource.Peer, blo
 
 		if _, ok := s.NotifyAndFindParent(ctx, peer, blocklist); !ok {
 			n++
+			metrics.RescheduleCount.WithLabelValues(peer.Tag, peer.Application).Inc()
 			peer.Log.Infof("schedule parent %d times failed", n)
 
 			// Sleep to avoid hot looping.
@@ -141,8 +138,48 @@ func (s *scheduler) ScheduleParent(ctx context.Context, peer *resource.Peer, blo
 	}
 }
 
+// notifyBackToSource notifies peer to download back-to-source,
+// the reserved back-to-source slot is released only if peer is not notified.
+func (s *scheduler) notifyBackToSource(peer *resource.Peer) error {
+	stream, ok := peer.LoadStream()
+	if !ok {
+		peer.Task.ReleaseBackToSource(peer.ID)
+		return errors.New("load stream failed")
+	}
+
+	metrics.ScheduleBackToSourceCount.WithLabelValues(peer.Tag, peer.Application).Inc()
+
+	// Notify peer back-to-source.
+	if err := stream.Send(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedNeedBackSource}); err != nil {
+		peer.Task.ReleaseBackToSource(peer.ID)
+		return fmt.Errorf("send packet failed: %w", err)
+	}
+
+	// Peer fsm does not enter PeerStateBackToSource when the event failed,
+	// so the slot will never be released by leaving the state.
+	if err := peer.FSM.Event(resource.PeerEventDownloadFromBackToSource); err != nil {
+		peer.Task.ReleaseBackToSource(peer.ID)
+		return fmt.Errorf("peer fsm event failed: %w", err)
+	}
+
+	// If the task state is TaskStateFailed,
+	// peer back-to-source and reset task state to TaskStateRunning.
+	if peer.Task.FSM.Is(resource.TaskStateFailed) {
+		if err := peer.Task.FSM.Event(resource.TaskEventDownload); err != nil {
+			peer.Task.Log.Errorf("task fsm event failed: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
 // NotifyAndFindParent finds parent that best matches the evaluation and notify peer.
 func (s *scheduler) NotifyAndFindParent(ctx context.Context, peer *resource.Peer, blocklist set.SafeSet[string]) ([]*resource.Peer, bool) {
+	start := time.Now()
+	defer func() {
+		metrics.ScheduleDuration.WithLabelValues(peer.Tag, peer.Application).Observe(float64(time.Since(start)) / float64(time.Millisecond))
+	}()
+
 	// Only PeerStateRunning peers need to be rescheduled,
 	// and other states including the PeerStateBackToSource indicate that
 	// they have been scheduled.
@@ -165,13 +202,7 @@ func (s *scheduler) NotifyAndFindParent(ctx context.Context, peer *resource.Peer
 	}
 
 	// Sort candidate parents by evaluation score.
-	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	sort.Slice(
-		candidateParents,
-		func(i, j int) bool {
-			return s.evaluator.Evaluate(candidateParents[i], peer, taskTotalPieceCount) > s.evaluator.Evaluate(candidateParents[j], peer, taskTotalPieceCount)
-		},
-	)
+	s.sortCandidateParents(peer, candidateParents)
 
 	// Add edges between candidate parent and peer.
 	var (
@@ -206,6 +237,9 @@ func (s *scheduler) NotifyAndFindParent(ctx context.Context, peer *resource.Peer
 
 	peer.Log.Infof("schedule parent successful, replace parent to %s and candidate parents is %v",
 		parentIDs[0], parentIDs[1:])
+	peer.DegradedCount.Store(0)
+	peer.ParentSwitchAt.Store(time.Now())
+	metrics.PeerDepth.WithLabelValues(peer.Tag, peer.Application).Observe(float64(peer.Depth()))
 	return candidateParents, true
 }
 
@@ -219,18 +253,41 @@ func (s *scheduler) FindParent(ctx context.Context, peer *resource.Peer, blockli
 	}
 
 	// Sort candidate parents by evaluation score.
-	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
-	sort.Slice(
-		candidateParents,
-		func(i, j int) bool {
-			return s.evaluator.Evaluate(candidateParents[i], peer, taskTotalPieceCount) > s.evaluator.Evaluate(candidateParents[j], peer, taskTotalPieceCount)
-		},
-	)
+	s.sortCandidateParents(peer, candidateParents)
 
 	peer.Log.Infof("find parent %s successful", candidateParents[0].ID)
 	return candidateParents[0], true
 }
 
+// NeedAdjustParent determines whether the peer needs to switch parent because downloading from parent is degraded.
+// Parent is switched only when the degradation lasts the configured evaluation windows and
+// the peer is out of the cooldown of the last switch, which prevents parents flapping on jittery networks.
+func (s *scheduler) NeedAdjustParent(peer *resource.Peer) bool {
+	if s.config.ParentAdjustment == nil || !s.config.ParentAdjustment.Enable {
+		return false
+	}
+
+	// Evaluation window is passed when the last piece cost of peer is normal.
+	if !s.evaluator.IsBadNode(peer) {
+		peer.DegradedCount.Store(0)
+		return false
+	}
+
+	degradedCount := peer.DegradedCount.Inc()
+	if int(degradedCount) < s.config.ParentAdjustment.DegradedWindows {
+		peer.Log.Debugf("downloading from parent is degraded %d times, it is less than %d",
+			degradedCount, s.config.ParentAdjustment.DegradedWindows)
+		return false
+	}
+
+	if elapsed := time.Since(peer.ParentSwitchAt.Load()); elapsed < s.config.ParentAdjustment.Cooldown {
+		peer.Log.Debugf("parent is switched %s ago, peer is in cooldown %s", elapsed, s.config.ParentAdjustment.Cooldown)
+		return false
+	}
+
+	return true
+}
+
 // Filter the candidate parent that can be scheduled.
 func (s *scheduler) filterCandidateParents(peer *resource.Peer, blocklist set.SafeSet[string]) []*resource.Peer {
 	filterParentLimit := config.DefaultSchedulerFilterParentLimit
@@ -245,22 +302,55 @@ func (s *scheduler) filterCandidateParents(peer *resource.Peer, blocklist set.Sa
 		}
 	}
 
+	// Limit of task class overrides the limit of scheduler cluster.
+	if class, ok := s.config.GetTaskClass(peer.Task.Class); ok && class.FilterParentLimit > 0 {
+		filterParentLimit = class.FilterParentLimit
+	}
+
+	// When anti-affinity is enabled, parents from another failure domain are looked for
+	// after the parent length limit is reached, and replace parents in the same failure domain.
+	var minCrossDomainParents int
+	if s.config.AntiAffinity != nil && s.config.AntiAffinity.Enable {
+		minCrossDomainParents = math.Min(s.config.AntiAffinity.MinParents, filterParentLimit)
+	}
+
 	var (
 		candidateParents   []*resource.Peer
 		candidateParentIDs []string
+		crossDomainParents int
 	)
+	isLargeFanOut := s.isLargeFanOut(peer.Task)
 	for _, candidateParent := range peer.Task.LoadRandomPeers(uint(filterParentRangeLimit)) {
 		// Parent length limit after filtering.
-		if len(candidateParents) >= filterParentLimit {
+		isFull := len(candidateParents) >= filterParentLimit
+		if isFull && crossDomainParents >= minCrossDomainParents {
 			break
 		}
 
+		isCrossDomain := !evaluator.IsSameFailureDomain(candidateParent.Host, peer.Host)
+		if isFull && !isCrossDomain {
+			continue
+		}
+
 		// Candidate parent is in blocklist.
 		if blocklist.Contains(candidateParent.ID) {
 			peer.Log.Debugf("candidate parent %s is not selected because it is in blocklist", candidateParent.ID)
 			continue
 		}
 
+		// Candidate parent is recently failed or slow for peer, reported by dfdaemon.
+		if peer.IsParentExcluded(candidateParent.ID) {
+			peer.Log.Debugf("candidate parent %s is not selected because it is excluded by peer", candidateParent.ID)
+			continue
+		}
+
+		// Candidate parent in another security domain is not allowed to be matched.
+		if !evaluator.IsSameSecurityDomain(candidateParent.Host, peer.Host) {
+			peer.Log.Debugf("candidate parent %s is not selected because its security domain %s is different from %s",
+				candidateParent.ID, candidateParent.Host.SecurityDomain, peer.Host.SecurityDomain)
+			continue
+		}
+
 		// Candidate parent can add edge with peer.
 		if !peer.Task.CanAddPeerEdge(candidateParent.ID, peer.ID) {
 			peer.Log.Debugf("can not add edge with candidate parent %s", candidateParent.ID)
@@ -308,6 +398,42 @@ func (s *scheduler) filterCandidateParents(peer *resource.Peer, blocklist set.Sa
 			continue
 		}
 
+		// For large fan-out tasks, succeeded peers are promoted to super peers,
+		// and other peers are not selected when the tree is too deep.
+		if isLargeFanOut && candidateParent.Host.Type == resource.HostTypeNormal {
+			isSuperPeer := peer.Task.SuperPeers.Contains(candidateParent.ID)
+			if !isSuperPeer && candidateParent.FSM.Is(resource.PeerStateSucceeded) &&
+				peer.Task.PromoteSuperPeer(candidateParent.ID, s.config.SuperNode.Limit) {
+				candidateParent.Log.Info("peer is promoted to super peer")
+				isSuperPeer = true
+			}
+
+			if !isSuperPeer && candidateParent.Depth() >= s.config.SuperNode.MaxDepth {
+				peer.Log.Debugf("candidate parent %s is not selected because its depth exceeds %d",
+					candidateParent.ID, s.config.SuperNode.MaxDepth)
+				continue
+			}
+		}
+
+		if isCrossDomain {
+			crossDomainParents++
+		}
+
+		if isFull {
+			// Replace the last parent in the same failure domain.
+			for i := len(candidateParents) - 1; i >= 0; i-- {
+				if evaluator.IsSameFailureDomain(candidateParents[i].Host, peer.Host) {
+					peer.Log.Debugf("candidate parent %s is replaced by %s in another failure domain",
+						candidateParents[i].ID, candidateParent.ID)
+					candidateParents[i] = candidateParent
+					candidateParentIDs[i] = candidateParent.ID
+					break
+				}
+			}
+
+			continue
+		}
+
 		candidateParents = append(candidateParents, candidateParent)
 		candidateParentIDs = append(candidateParentIDs, candidateParent.ID)
 	}
@@ -316,6 +442,50 @@ func (s *scheduler) filterCandidateParents(peer *resource.Peer, blocklist set.Sa
 	return candidateParents
 }
 
+// sortCandidateParents sorts candidate parents by evaluation score,
+// super peers are preferred for large fan-out tasks.
+func (s *scheduler) sortCandidateParents(peer *resource.Peer, candidateParents []*resource.Peer) {
+	// Evaluate candidate parents once before sorting, evaluation walks
+	// the finished pieces of candidate parent to find the rarest piece.
+	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
+	scores := make(map[string]float64, len(candidateParents))
+	for i, score := range evaluator.EvaluateParents(s.evaluator, candidateParents, peer, taskTotalPieceCount) {
+		scores[candidateParents[i].ID] = score
+	}
+
+	sort.Slice(
+		candidateParents,
+		func(i, j int) bool {
+			return scores[candidateParents[i].ID] > scores[candidateParents[j].ID]
+		},
+	)
+
+	if s.isLargeFanOut(peer.Task) {
+		sort.SliceStable(
+			candidateParents,
+			func(i, j int) bool {
+				return peer.Task.SuperPeers.Contains(candidateParents[i].ID) && !peer.Task.SuperPeers.Contains(candidateParents[j].ID)
+			},
+		)
+	}
+
+	// Parents in another ip family are deprioritized,
+	// peer in IPv6 only or IPv4 only network can not reach them.
+	isIPv6 := ip.IsIPv6(peer.Host.IP)
+	sort.SliceStable(
+		candidateParents,
+		func(i, j int) bool {
+			return ip.IsIPv6(candidateParents[i].Host.IP) == isIPv6 && ip.IsIPv6(candidateParents[j].Host.IP) != isIPv6
+		},
+	)
+}
+
+// isLargeFanOut returns whether super node hinting is applied to the task.
+func (s *scheduler) isLargeFanOut(task *resource.Task) bool {
+	return s.config.SuperNode != nil && s.config.SuperNode.Enable &&
+		task.PeerCount() >= s.config.SuperNode.PeerCountThreshold
+}
+
 // Construct peer successful packet.
 func constructSuccessPeerPacket(dynconfig config.DynconfigInterface, peer *resource.Peer, parent *resource.Peer, candidateParents []*resource.Peer) *schedulerv1.PeerPacket {
 	parallelCount := config.DefaultClientParallelCount
@@ -171,6 +171,7 @@ func TestScheduler_ScheduleParent(t *testing.T) {
 				assert := assert.New(t)
 				assert.Equal(len(peer.Parents()), 0)
 				assert.True(peer.FSM.Is(resource.PeerStateRunning))
+				assert.False(peer.Task.BackToSourcePeers.Contains(peer.ID))
 			},
 		},
 		{
@@ -189,6 +190,51 @@ func TestScheduler_ScheduleParent(t *testing.T) {
 				assert.Equal(len(peer.Parents()), 0)
 				assert.True(peer.FSM.Is(resource.PeerStateBackToSource))
 				assert.True(peer.Task.FSM.Is(resource.TaskStatePending))
+				assert.True(peer.Task.BackToSourcePeers.Contains(peer.ID))
+			},
+		},
+		{
+			name: "peer needs back-to-source and peer fsm event failed after sending Code_SchedNeedBackSource code",
+			mock: func(cancel context.CancelFunc, peer *resource.Peer, seedPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, mr *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				task := peer.Task
+				task.StorePeer(peer)
+				peer.NeedBackToSource.Store(true)
+				peer.FSM.SetState(resource.PeerStatePending)
+				peer.StoreStream(stream)
+
+				mr.Send(gomock.Eq(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedNeedBackSource})).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.True(peer.FSM.Is(resource.PeerStatePending))
+				assert.False(peer.Task.BackToSourcePeers.Contains(peer.ID))
+			},
+		},
+		{
+			name: "promoted peer downloads back-to-source when back-to-source slot is released",
+			mock: func(cancel context.CancelFunc, peer *resource.Peer, seedPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, mr *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				task := peer.Task
+				task.StorePeer(peer)
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.BackToSourceLimit.Store(1)
+				peer.Task.BackToSourcePeers.Add(seedPeer.ID)
+				peer.Task.EnqueueBackToSource(peer.ID)
+				peer.StoreStream(stream)
+
+				peer.Task.ReleaseBackToSource(seedPeer.ID)
+				promoted, ok := peer.Task.DequeueBackToSource()
+				if !ok {
+					t.Fatal("queued peer not found")
+				}
+				promoted.NeedBackToSource.Store(true)
+
+				mr.Send(gomock.Eq(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedNeedBackSource})).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.True(peer.FSM.Is(resource.PeerStateBackToSource))
+				assert.True(peer.Task.BackToSourcePeers.Contains(peer.ID))
+				assert.Equal(peer.Task.BackToSourceQueueLen(), 0)
 			},
 		},
 		{
@@ -221,6 +267,29 @@ func TestScheduler_ScheduleParent(t *testing.T) {
 			expect: func(t *testing.T, peer *resource.Peer) {
 				assert := assert.New(t)
 				assert.True(peer.FSM.Is(resource.PeerStateRunning))
+				assert.Equal(peer.Task.BackToSourcePeers.Len(), uint(0))
+			},
+		},
+		{
+			name: "schedule exceeds RetryBackSourceLimit and back-to-source peers reach the limit",
+			mock: func(cancel context.CancelFunc, peer *resource.Peer, seedPeer *resource.Peer, blocklist set.SafeSet[string], stream schedulerv1.Scheduler_ReportPieceResultServer, mr *mocks.MockScheduler_ReportPieceResultServerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				task := peer.Task
+				task.StorePeer(peer)
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.BackToSourceLimit.Store(1)
+				peer.Task.BackToSourcePeers.Add(seedPeer.ID)
+				peer.StoreStream(stream)
+
+				gomock.InOrder(
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(2),
+					mr.Send(gomock.Eq(&schedulerv1.PeerPacket{Code: commonv1.Code_SchedTaskStatusError})).Return(nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.True(peer.FSM.Is(resource.PeerStateRunning))
+				assert.Equal(peer.Task.BackToSourceQueueLen(), 1)
+				assert.False(peer.Task.BackToSourcePeers.Contains(peer.ID))
 			},
 		},
 		{
@@ -663,6 +732,21 @@ func TestScheduler_FindParent(t *testing.T) {
 				assert.False(ok)
 			},
 		},
+		{
+			name: "peer is excluded by hints",
+			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeers[0])
+				peer.ExcludeParents([]string{mockPeers[0].ID}, time.Minute)
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
 		{
 			name: "peer is bad node",
 			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
@@ -696,6 +780,25 @@ func TestScheduler_FindParent(t *testing.T) {
 				assert.False(ok)
 			},
 		},
+		{
+			name: "parent is in another security domain",
+			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeers[0].FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeers[0])
+				peer.Task.BackToSourcePeers.Add(mockPeers[0].ID)
+				mockPeers[0].IsBackToSource.Store(true)
+				mockPeers[0].FinishedPieces.Set(0)
+				mockPeers[0].Host.SecurityDomain = "foo"
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+			},
+		},
 		{
 			name: "parent free upload load is zero",
 			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
@@ -738,6 +841,33 @@ func TestScheduler_FindParent(t *testing.T) {
 				assert.Equal(mockPeers[1].ID, parent.ID)
 			},
 		},
+		{
+			name: "find parent in the same ip family",
+			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeers[0].FSM.SetState(resource.PeerStateRunning)
+				mockPeers[1].FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeers[0])
+				peer.Task.StorePeer(mockPeers[1])
+				peer.Task.BackToSourcePeers.Add(mockPeers[0].ID)
+				peer.Task.BackToSourcePeers.Add(mockPeers[1].ID)
+				mockPeers[0].IsBackToSource.Store(true)
+				mockPeers[1].IsBackToSource.Store(true)
+				mockPeers[0].Host.IP = "fd00::1"
+				mockPeers[0].FinishedPieces.Set(0)
+				mockPeers[0].FinishedPieces.Set(1)
+				mockPeers[0].FinishedPieces.Set(2)
+				mockPeers[1].FinishedPieces.Set(0)
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(mockPeers[1].ID, parent.ID)
+			},
+		},
 		{
 			name: "find seed peer parent",
 			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, blocklist set.SafeSet[string], md *configmocks.MockDynconfigInterfaceMockRecorder) {
@@ -981,3 +1111,394 @@ func TestScheduler_constructSuccessPeerPacket(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduler_FindParentWithSuperNode(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(peer *resource.Peer, mockPeers []*resource.Peer, md *configmocks.MockDynconfigInterfaceMockRecorder)
+		expect func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool)
+	}{
+		{
+			name: "succeeded peer is promoted to super peer",
+			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeers[0].FSM.SetState(resource.PeerStateSucceeded)
+				mockPeers[1].FSM.SetState(resource.PeerStateSucceeded)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeers[0])
+				peer.Task.StorePeer(mockPeers[1])
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(peer.Task.SuperPeers.Len(), uint(1))
+				assert.True(peer.Task.SuperPeers.Contains(parent.ID))
+			},
+		},
+		{
+			name: "super peer is preferred",
+			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeers[0].FSM.SetState(resource.PeerStateSucceeded)
+				mockPeers[1].FSM.SetState(resource.PeerStateSucceeded)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeers[0])
+				peer.Task.StorePeer(mockPeers[1])
+				peer.Task.PromoteSuperPeer(mockPeers[1].ID, 1)
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(mockPeers[1].ID, parent.ID)
+			},
+		},
+		{
+			name: "peers exceeding max depth are not selected",
+			mock: func(peer *resource.Peer, mockPeers []*resource.Peer, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				mockPeers[0].FSM.SetState(resource.PeerStateRunning)
+				mockPeers[0].IsBackToSource.Store(true)
+				mockPeers[1].FSM.SetState(resource.PeerStateRunning)
+				mockPeers[2].FSM.SetState(resource.PeerStateRunning)
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(mockPeers[0])
+				peer.Task.StorePeer(mockPeers[1])
+				peer.Task.StorePeer(mockPeers[2])
+				if err := peer.Task.AddPeerEdge(mockPeers[0], mockPeers[1]); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := peer.Task.AddPeerEdge(mockPeers[1], mockPeers[2]); err != nil {
+					t.Fatal(err)
+				}
+
+				md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, mockPeers []*resource.Peer, parent *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(mockPeers[0].ID, parent.ID)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+
+			var mockPeers []*resource.Peer
+			for i := 0; i < 3; i++ {
+				mockHost := resource.NewHost(&schedulerv1.PeerHost{
+					Id:             idgen.HostID(uuid.New().String(), 8003),
+					Ip:             "127.0.0.1",
+					RpcPort:        8003,
+					DownPort:       8001,
+					HostName:       "hostname",
+					SecurityDomain: "security_domain",
+					Location:       "location",
+					Idc:            "idc",
+					NetTopology:    "net_topology",
+				})
+				peer := resource.NewPeer(idgen.PeerID(fmt.Sprintf("127.0.0.%d", i)), mockTask, mockHost)
+				mockPeers = append(mockPeers, peer)
+			}
+
+			cfg := *mockSchedulerConfig
+			cfg.SuperNode = &config.SuperNodeConfig{
+				Enable:             true,
+				PeerCountThreshold: 3,
+				Limit:              1,
+				MaxDepth:           2,
+			}
+
+			tc.mock(peer, mockPeers, dynconfig.EXPECT())
+			scheduler := New(&cfg, dynconfig, mockPluginDir)
+			parent, ok := scheduler.FindParent(context.Background(), peer, set.NewSafeSet[string]())
+			tc.expect(t, peer, mockPeers, parent, ok)
+		})
+	}
+}
+
+func TestScheduler_filterCandidateParentsWithAntiAffinity(t *testing.T) {
+	tests := []struct {
+		name         string
+		antiAffinity *config.AntiAffinityConfig
+		idcs         []string
+		expect       func(t *testing.T, mockPeers []*resource.Peer, candidateParents []*resource.Peer)
+	}{
+		{
+			name:         "parent in another failure domain replaces parent in the same failure domain",
+			antiAffinity: &config.AntiAffinityConfig{Enable: true, MinParents: 1},
+			idcs:         []string{"idc", "idc", "idc", "foo"},
+			expect: func(t *testing.T, mockPeers []*resource.Peer, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 2)
+				assert.Contains(candidateParents, mockPeers[3])
+			},
+		},
+		{
+			name:         "min parents is limited by filter parent limit",
+			antiAffinity: &config.AntiAffinityConfig{Enable: true, MinParents: 3},
+			idcs:         []string{"idc", "foo", "bar", "baz"},
+			expect: func(t *testing.T, mockPeers []*resource.Peer, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 2)
+				for _, candidateParent := range candidateParents {
+					assert.NotEqual(mockPeers[0], candidateParent)
+				}
+			},
+		},
+		{
+			name:         "parents in another failure domain are not available",
+			antiAffinity: &config.AntiAffinityConfig{Enable: true, MinParents: 1},
+			idcs:         []string{"idc", "idc", "idc", "idc"},
+			expect: func(t *testing.T, mockPeers []*resource.Peer, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 2)
+			},
+		},
+		{
+			name:         "anti-affinity is disabled",
+			antiAffinity: &config.AntiAffinityConfig{Enable: false, MinParents: 1},
+			idcs:         []string{"idc", "foo", "bar", "baz"},
+			expect: func(t *testing.T, mockPeers []*resource.Peer, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 2)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+			peer.Task.StorePeer(peer)
+
+			var mockPeers []*resource.Peer
+			for i, idc := range tc.idcs {
+				mockHost := resource.NewHost(&schedulerv1.PeerHost{
+					Id:             idgen.HostID(uuid.New().String(), 8003),
+					Ip:             "127.0.0.1",
+					RpcPort:        8003,
+					DownPort:       8001,
+					HostName:       "hostname",
+					SecurityDomain: "security_domain",
+					Location:       "location",
+					Idc:            idc,
+					NetTopology:    "net_topology",
+				})
+				mockPeer := resource.NewPeer(idgen.PeerID(fmt.Sprintf("127.0.0.%d", i)), mockTask, mockHost)
+				mockPeer.FSM.SetState(resource.PeerStateSucceeded)
+				peer.Task.StorePeer(mockPeer)
+				mockPeers = append(mockPeers, mockPeer)
+			}
+
+			dynconfig.EXPECT().GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+				FilterParentLimit: 2,
+			}, true).Times(1)
+
+			cfg := *mockSchedulerConfig
+			cfg.AntiAffinity = tc.antiAffinity
+			scheduler := New(&cfg, dynconfig, mockPluginDir).(*scheduler)
+			tc.expect(t, mockPeers, scheduler.filterCandidateParents(peer, set.NewSafeSet[string]()))
+		})
+	}
+}
+
+func TestScheduler_filterCandidateParentsWithTaskClass(t *testing.T) {
+	tests := []struct {
+		name        string
+		taskClasses []*config.TaskClassConfig
+		class       string
+		expect      func(t *testing.T, candidateParents []*resource.Peer)
+	}{
+		{
+			name: "filter parent limit of task class overrides limit of scheduler cluster",
+			taskClasses: []*config.TaskClassConfig{
+				{Name: "production", FilterParentLimit: 4},
+			},
+			class: "production",
+			expect: func(t *testing.T, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 4)
+			},
+		},
+		{
+			name: "task class without filter parent limit",
+			taskClasses: []*config.TaskClassConfig{
+				{Name: "production"},
+			},
+			class: "production",
+			expect: func(t *testing.T, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 2)
+			},
+		},
+		{
+			name: "task without class",
+			taskClasses: []*config.TaskClassConfig{
+				{Name: "production", FilterParentLimit: 4},
+			},
+			expect: func(t *testing.T, candidateParents []*resource.Peer) {
+				assert := assert.New(t)
+				assert.Len(candidateParents, 2)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta,
+				resource.WithBackToSourceLimit(mockTaskBackToSourceLimit), resource.WithClass(tc.class))
+			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+			peer.Task.StorePeer(peer)
+
+			for i := 0; i < 5; i++ {
+				mockHost := resource.NewHost(&schedulerv1.PeerHost{
+					Id:             idgen.HostID(uuid.New().String(), 8003),
+					Ip:             "127.0.0.1",
+					RpcPort:        8003,
+					DownPort:       8001,
+					HostName:       "hostname",
+					SecurityDomain: "security_domain",
+					Location:       "location",
+					Idc:            "idc",
+					NetTopology:    "net_topology",
+				})
+				mockPeer := resource.NewPeer(idgen.PeerID(fmt.Sprintf("127.0.0.%d", i)), mockTask, mockHost)
+				mockPeer.FSM.SetState(resource.PeerStateSucceeded)
+				peer.Task.StorePeer(mockPeer)
+			}
+
+			dynconfig.EXPECT().GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{
+				FilterParentLimit: 2,
+			}, true).Times(1)
+
+			cfg := *mockSchedulerConfig
+			cfg.TaskClasses = tc.taskClasses
+			scheduler := New(&cfg, dynconfig, mockPluginDir).(*scheduler)
+			tc.expect(t, scheduler.filterCandidateParents(peer, set.NewSafeSet[string]()))
+		})
+	}
+}
+
+func TestScheduler_NeedAdjustParent(t *testing.T) {
+	tests := []struct {
+		name             string
+		parentAdjustment *config.ParentAdjustmentConfig
+		mock             func(peer *resource.Peer)
+		expect           func(t *testing.T, peer *resource.Peer, ok bool)
+	}{
+		{
+			name: "parent adjustment is disabled",
+			mock: func(peer *resource.Peer) {
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(1000)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal(int32(0), peer.DegradedCount.Load())
+			},
+		},
+		{
+			name:             "downloading is not degraded",
+			parentAdjustment: &config.ParentAdjustmentConfig{Enable: true, DegradedWindows: 1},
+			mock: func(peer *resource.Peer) {
+				peer.DegradedCount.Store(2)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(10)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal(int32(0), peer.DegradedCount.Load())
+			},
+		},
+		{
+			name:             "degradation does not last enough windows",
+			parentAdjustment: &config.ParentAdjustmentConfig{Enable: true, DegradedWindows: 3},
+			mock: func(peer *resource.Peer) {
+				peer.DegradedCount.Store(1)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(1000)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal(int32(2), peer.DegradedCount.Load())
+			},
+		},
+		{
+			name:             "peer is in cooldown",
+			parentAdjustment: &config.ParentAdjustmentConfig{Enable: true, DegradedWindows: 3, Cooldown: time.Minute},
+			mock: func(peer *resource.Peer) {
+				peer.DegradedCount.Store(2)
+				peer.ParentSwitchAt.Store(time.Now())
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(1000)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.False(ok)
+				assert.Equal(int32(3), peer.DegradedCount.Load())
+			},
+		},
+		{
+			name:             "peer needs to adjust parent",
+			parentAdjustment: &config.ParentAdjustmentConfig{Enable: true, DegradedWindows: 3, Cooldown: time.Minute},
+			mock: func(peer *resource.Peer) {
+				peer.DegradedCount.Store(2)
+				peer.ParentSwitchAt.Store(time.Now().Add(-2 * time.Minute))
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(10)
+				peer.AppendPieceCost(1000)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal(int32(3), peer.DegradedCount.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+			peer.FSM.SetState(resource.PeerStateRunning)
+
+			cfg := *mockSchedulerConfig
+			cfg.ParentAdjustment = tc.parentAdjustment
+
+			tc.mock(peer)
+			scheduler := New(&cfg, dynconfig, mockPluginDir)
+			tc.expect(t, peer, scheduler.NeedAdjustParent(peer))
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+const (
+	// admissionIPKeyPrefix is the key prefix of buckets by source ip.
+	admissionIPKeyPrefix = "ip:"
+
+	// admissionApplicationKeyPrefix is the key prefix of buckets by application.
+	admissionApplicationKeyPrefix = "application:"
+
+	// admissionMaxDelay is the max delay returned to throttled registrations.
+	admissionMaxDelay = time.Minute
+)
+
+// admission throttles peer registrations with token buckets of source ip and application,
+// so that a registration storm from a few hosts or applications can not starve the others.
+type admission struct {
+	config *config.AdmissionConfig
+
+	// buckets is the token buckets by key.
+	buckets map[string]*admissionBucket
+
+	// sweepAt is the time when idle buckets are reclaimed last time.
+	sweepAt time.Time
+
+	mu sync.Mutex
+}
+
+// admissionBucket is the token bucket of a source ip or an application.
+type admissionBucket struct {
+	limiter    *rate.Limiter
+	accessedAt time.Time
+}
+
+// newAdmission returns admission with config, it is nil when admission is disabled.
+func newAdmission(cfg *config.AdmissionConfig) *admission {
+	if cfg == nil || !cfg.Enable {
+		return nil
+	}
+
+	return &admission{
+		config:  cfg,
+		buckets: map[string]*admissionBucket{},
+		sweepAt: time.Now(),
+	}
+}
+
+// admit takes tokens of source ip and application for a registration, if it is throttled,
+// no token is taken and the delay after which the registration can be retried is returned.
+func (a *admission) admit(ip, application string) (time.Duration, bool) {
+	if a == nil {
+		return 0, true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	a.sweep(now)
+
+	var reservations []*rate.Reservation
+	if a.config.IPRate > 0 && ip != "" {
+		reservations = append(reservations, a.reserve(admissionIPKeyPrefix+ip, a.config.IPRate, a.config.IPBurst, now))
+	}
+
+	if a.config.ApplicationRate > 0 && application != "" {
+		reservations = append(reservations, a.reserve(admissionApplicationKeyPrefix+application, a.config.ApplicationRate, a.config.ApplicationBurst, now))
+	}
+
+	var delay time.Duration
+	for _, reservation := range reservations {
+		// The bucket can never be filled when burst is zero.
+		if !reservation.OK() {
+			if delay < admissionMaxDelay {
+				delay = admissionMaxDelay
+			}
+			continue
+		}
+
+		if d := reservation.DelayFrom(now); d > delay {
+			delay = d
+		}
+	}
+
+	if delay == 0 {
+		return 0, true
+	}
+
+	if delay > admissionMaxDelay {
+		delay = admissionMaxDelay
+	}
+
+	// Return the tokens of all buckets, the registration is throttled.
+	for _, reservation := range reservations {
+		reservation.CancelAt(now)
+	}
+
+	return delay, false
+}
+
+// reserve reserves a token of the bucket by key.
+func (a *admission) reserve(key string, r float64, burst int, now time.Time) *rate.Reservation {
+	bucket, ok := a.buckets[key]
+	if !ok {
+		bucket = &admissionBucket{limiter: rate.NewLimiter(rate.Limit(r), burst)}
+		a.buckets[key] = bucket
+	}
+
+	bucket.accessedAt = now
+	return bucket.limiter.ReserveN(now, 1)
+}
+
+// sweep reclaims the buckets idle for longer than bucket ttl,
+// a reclaimed bucket is full when it is created again.
+func (a *admission) sweep(now time.Time) {
+	if now.Sub(a.sweepAt) < a.config.BucketTTL {
+		return
+	}
+
+	for key, bucket := range a.buckets {
+		if now.Sub(bucket.accessedAt) >= a.config.BucketTTL {
+			delete(a.buckets, key)
+		}
+	}
+
+	a.sweepAt = now
+}
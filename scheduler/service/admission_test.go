@@ -0,0 +1,145 @@
+/*
+ *     Copyright 2020 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func TestAdmission_Admit(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.AdmissionConfig
+		expect func(t *testing.T, a *admission)
+	}{
+		{
+			name:   "admission is disabled",
+			config: &config.AdmissionConfig{Enable: false},
+			expect: func(t *testing.T, a *admission) {
+				assert := assert.New(t)
+				assert.Nil(a)
+				for i := 0; i < 10; i++ {
+					_, ok := a.admit("127.0.0.1", "foo")
+					assert.True(ok)
+				}
+			},
+		},
+		{
+			name: "throttle by source ip",
+			config: &config.AdmissionConfig{
+				Enable:    true,
+				IPRate:    1,
+				IPBurst:   2,
+				BucketTTL: time.Minute,
+			},
+			expect: func(t *testing.T, a *admission) {
+				assert := assert.New(t)
+				for i := 0; i < 2; i++ {
+					_, ok := a.admit("127.0.0.1", "foo")
+					assert.True(ok)
+				}
+
+				delay, ok := a.admit("127.0.0.1", "foo")
+				assert.False(ok)
+				assert.True(delay > 0 && delay <= time.Second)
+
+				// Other source ips are not affected.
+				_, ok = a.admit("127.0.0.2", "foo")
+				assert.True(ok)
+			},
+		},
+		{
+			name: "throttle by application",
+			config: &config.AdmissionConfig{
+				Enable:           true,
+				IPRate:           100,
+				IPBurst:          100,
+				ApplicationRate:  1,
+				ApplicationBurst: 1,
+				BucketTTL:        time.Minute,
+			},
+			expect: func(t *testing.T, a *admission) {
+				assert := assert.New(t)
+				_, ok := a.admit("127.0.0.1", "foo")
+				assert.True(ok)
+
+				_, ok = a.admit("127.0.0.2", "foo")
+				assert.False(ok)
+
+				// Registrations without application are throttled by source ip only.
+				_, ok = a.admit("127.0.0.2", "")
+				assert.True(ok)
+				_, ok = a.admit("127.0.0.2", "bar")
+				assert.True(ok)
+			},
+		},
+		{
+			name: "throttled registration takes no token",
+			config: &config.AdmissionConfig{
+				Enable:           true,
+				IPRate:           0.1,
+				IPBurst:          1,
+				ApplicationRate:  0.1,
+				ApplicationBurst: 1,
+				BucketTTL:        time.Minute,
+			},
+			expect: func(t *testing.T, a *admission) {
+				assert := assert.New(t)
+				_, ok := a.admit("127.0.0.1", "foo")
+				assert.True(ok)
+
+				// The token of source ip is returned when the application is throttled.
+				_, ok = a.admit("127.0.0.2", "foo")
+				assert.False(ok)
+				_, ok = a.admit("127.0.0.2", "bar")
+				assert.True(ok)
+			},
+		},
+		{
+			name: "idle buckets are reclaimed",
+			config: &config.AdmissionConfig{
+				Enable:    true,
+				IPRate:    0.1,
+				IPBurst:   1,
+				BucketTTL: time.Minute,
+			},
+			expect: func(t *testing.T, a *admission) {
+				assert := assert.New(t)
+				_, ok := a.admit("127.0.0.1", "")
+				assert.True(ok)
+				assert.Len(a.buckets, 1)
+
+				a.buckets[admissionIPKeyPrefix+"127.0.0.1"].accessedAt = time.Now().Add(-time.Hour)
+				a.sweepAt = time.Now().Add(-time.Hour)
+				_, ok = a.admit("127.0.0.1", "")
+				assert.True(ok)
+				assert.Len(a.buckets, 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, newAdmission(tc.config))
+		})
+	}
+}
@@ -20,9 +20,13 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
+	"strconv"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
@@ -31,7 +35,9 @@ import (
 
 	"d7y.io/dragonfly/v2/internal/dferrors"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/container/set"
+	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	pkgtime "d7y.io/dragonfly/v2/pkg/time"
 	"d7y.io/dragonfly/v2/scheduler/config"
@@ -56,6 +62,18 @@ type Service struct {
 
 	// Storage interface.
 	storage storage.Storage
+
+	// seedPeers is the seed peers of dynconfig notified last time.
+	seedPeers []*config.SeedPeer
+
+	// draining rejects new peers when scheduler is going to stop.
+	draining *atomic.Bool
+
+	// streams is the number of active piece result streams.
+	streams *atomic.Int64
+
+	// admission throttles peer registrations, it is nil when admission is disabled.
+	admission *admission
 }
 
 // New service instance.
@@ -66,18 +84,70 @@ func New(
 	dynconfig config.DynconfigInterface,
 	storage storage.Storage,
 ) *Service {
-
-	return &Service{
+	s := &Service{
 		resource:  resource,
 		scheduler: scheduler,
 		config:    cfg,
 		dynconfig: dynconfig,
 		storage:   storage,
+		draining:  atomic.NewBool(false),
+		streams:   atomic.NewInt64(0),
+	}
+
+	if cfg.Scheduler != nil {
+		s.admission = newAdmission(cfg.Scheduler.Admission)
+	}
+
+	return s
+}
+
+// Drain makes the service reject new peers, and the existing peers are still served.
+func (s *Service) Drain() {
+	if s.draining.CAS(false, true) {
+		logger.Info("scheduler service is draining")
 	}
 }
 
+// IsDraining returns whether the service is draining.
+func (s *Service) IsDraining() bool {
+	return s.draining.Load()
+}
+
+// ActiveStreams returns the number of active piece result streams.
+func (s *Service) ActiveStreams() int64 {
+	return s.streams.Load()
+}
+
 // RegisterPeerTask registers peer and triggers seed peer download task.
 func (s *Service) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTaskRequest) (*schedulerv1.RegisterResult, error) {
+	// Reject new peers when scheduler is draining, the unavailable code
+	// makes the client register to another scheduler.
+	if s.IsDraining() {
+		msg := fmt.Sprintf("peer %s register is failed: scheduler is draining", req.PeerId)
+		logger.Warn(msg)
+		return nil, status.Error(codes.Unavailable, msg)
+	}
+
+	// Reject tasks blocked by the blocklist of scheduler cluster, the permission denied
+	// code makes the client fail without back-to-source.
+	if rule, ok := s.matchBlocklist(req.Url); ok {
+		msg := fmt.Sprintf("peer %s register is failed: url %s is blocked by %s pattern %s", req.PeerId, req.Url, rule.Type, rule.Pattern)
+		logger.Warn(msg)
+		return nil, status.Error(codes.PermissionDenied, msg)
+	}
+
+	// Throttle registrations by source ip and application, the resource exhausted code
+	// with retry delay makes the client retry later. Migrating peers are not throttled,
+	// because they have been downloading.
+	if !req.IsMigrating {
+		if delay, ok := s.admission.admit(req.PeerHost.GetIp(), req.UrlMeta.GetApplication()); !ok {
+			msg := fmt.Sprintf("peer %s register is failed: registration is throttled, retry after %s", req.PeerId, delay)
+			logger.Warn(msg)
+			metrics.RegisterPeerTaskThrottledCount.WithLabelValues(req.UrlMeta.GetTag(), req.UrlMeta.GetApplication()).Inc()
+			return nil, common.NewRetryAfterError(msg, delay)
+		}
+	}
+
 	// Register task and trigger seed peer download task.
 	task, needBackToSource, err := s.registerTask(ctx, req)
 	if err != nil {
@@ -88,6 +158,10 @@ func (s *Service) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTas
 	host := s.registerHost(ctx, req.PeerHost)
 	peer := s.registerPeer(ctx, req.PeerId, task, host, req.UrlMeta.Tag, req.UrlMeta.Application)
 	peer.Log.Infof("register peer task request: %#v %#v %#v", req, req.UrlMeta, req.HostLoad)
+	if req.IsMigrating {
+		peer.Log.Info("peer is migrating from another scheduler")
+	}
+	host.StoreLoad(req.HostLoad)
 
 	// When the peer registers for the first time and
 	// does not have a seed peer, it will back-to-source.
@@ -205,7 +279,7 @@ func (s *Service) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTas
 			peer.Log.Infof("schedule parent successful, replace parent to %s ", parent.ID)
 			singlePiece := &schedulerv1.SinglePiece{
 				DstPid:  parent.ID,
-				DstAddr: fmt.Sprintf("%s:%d", parent.Host.IP, parent.Host.DownloadPort),
+				DstAddr: net.JoinHostPort(parent.Host.IP, strconv.Itoa(int(parent.Host.DownloadPort))),
 				PieceInfo: &commonv1.PieceInfo{
 					PieceNum:    firstPiece.PieceNum,
 					RangeStart:  firstPiece.RangeStart,
@@ -258,6 +332,9 @@ func (s *Service) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTas
 
 // ReportPieceResult handles the piece information reported by dfdaemon.
 func (s *Service) ReportPieceResult(stream schedulerv1.Scheduler_ReportPieceResultServer) error {
+	s.streams.Inc()
+	defer s.streams.Dec()
+
 	ctx := stream.Context()
 	var (
 		peer        *resource.Peer
@@ -298,61 +375,90 @@ func (s *Service) ReportPieceResult(stream schedulerv1.Scheduler_ReportPieceResu
 			defer peer.DeleteStream()
 		}
 
-		if piece.PieceInfo != nil {
-			// Handle begin of piece.
-			if piece.PieceInfo.PieceNum == common.BeginOfPiece {
-				peer.Log.Infof("receive begin of piece: %#v %#v", piece, piece.PieceInfo)
-				s.handleBeginOfPiece(ctx, peer)
-				continue
-			}
+		// Store host load reported by peer.
+		peer.Host.StoreLoad(piece.HostLoad)
 
-			// Handle end of piece.
-			if piece.PieceInfo.PieceNum == common.EndOfPiece {
-				peer.Log.Infof("receive end of piece: %#v %#v", piece, piece.PieceInfo)
-				s.handleEndOfPiece(ctx, peer)
-				continue
-			}
+		// Exclude parents recently failed or slow for peer, reported by dfdaemon.
+		if excludePeers := common.ExtractExcludePeers(piece); len(excludePeers) > 0 {
+			peer.Log.Infof("exclude parents %v", excludePeers)
+			peer.ExcludeParents(excludePeers, config.DefaultSchedulerExcludedParentTTL)
 		}
 
-		// Handle piece download successfully.
-		if piece.Success {
-			peer.Log.Infof("receive piece: %#v %#v", piece, piece.PieceInfo)
-			s.handlePieceSuccess(ctx, peer, piece)
+		// Expand piece results reported in batch by dfdaemon.
+		pieces, err := common.UnpackPieceResults(piece)
+		if err != nil {
+			peer.Log.Errorf("unpack piece %#v error: %s", piece, err.Error())
+			continue
+		}
 
-			// Collect peer host traffic metrics.
-			if s.config.Metrics != nil && s.config.Metrics.EnablePeerHost {
-				metrics.PeerHostTraffic.WithLabelValues(peer.Tag, peer.Application, metrics.PeerHostTrafficDownloadType, peer.Host.ID, peer.Host.IP).Add(float64(piece.PieceInfo.RangeSize))
-				if parent, ok := s.resource.PeerManager().Load(piece.DstPid); ok {
-					metrics.PeerHostTraffic.WithLabelValues(peer.Tag, peer.Application, metrics.PeerHostTrafficUploadType, parent.Host.ID, parent.Host.IP).Add(float64(piece.PieceInfo.RangeSize))
-				} else {
-					peer.Log.Warnf("dst peer %s not found for piece %#v %#v", piece.DstPid, piece, piece.PieceInfo)
-				}
-			}
+		for _, piece := range pieces {
+			s.handlePieceResult(ctx, peer, piece)
+		}
+	}
+}
+
+// handlePieceResult handles a single piece result reported by dfdaemon.
+func (s *Service) handlePieceResult(ctx context.Context, peer *resource.Peer, piece *schedulerv1.PieceResult) {
+	if piece.PieceInfo != nil {
+		// Handle begin of piece.
+		if piece.PieceInfo.PieceNum == common.BeginOfPiece {
+			peer.Log.Infof("receive begin of piece: %#v %#v", piece, piece.PieceInfo)
+			s.handleBeginOfPiece(ctx, peer)
+			return
+		}
 
-			// Collect traffic metrics.
-			if piece.DstPid != "" {
-				metrics.Traffic.WithLabelValues(peer.Tag, peer.Application, metrics.TrafficP2PType).Add(float64(piece.PieceInfo.RangeSize))
+		// Handle end of piece.
+		if piece.PieceInfo.PieceNum == common.EndOfPiece {
+			peer.Log.Infof("receive end of piece: %#v %#v", piece, piece.PieceInfo)
+			s.handleEndOfPiece(ctx, peer)
+			return
+		}
+	}
+
+	// Handle piece download successfully.
+	if piece.Success {
+		peer.Log.Infof("receive piece: %#v %#v", piece, piece.PieceInfo)
+		s.handlePieceSuccess(ctx, peer, piece)
+
+		// Piece is downloaded before the peer migrated from another scheduler,
+		// the traffic has been collected by that scheduler.
+		if piece.DstPid == peer.ID {
+			return
+		}
+
+		// Collect peer host traffic metrics.
+		if s.config.Metrics != nil && s.config.Metrics.EnablePeerHost {
+			metrics.PeerHostTraffic.WithLabelValues(peer.Tag, peer.Application, metrics.PeerHostTrafficDownloadType, peer.Host.ID, peer.Host.IP).Add(float64(piece.PieceInfo.RangeSize))
+			if parent, ok := s.resource.PeerManager().Load(piece.DstPid); ok {
+				metrics.PeerHostTraffic.WithLabelValues(peer.Tag, peer.Application, metrics.PeerHostTrafficUploadType, parent.Host.ID, parent.Host.IP).Add(float64(piece.PieceInfo.RangeSize))
 			} else {
-				metrics.Traffic.WithLabelValues(peer.Tag, peer.Application, metrics.TrafficBackToSourceType).Add(float64(piece.PieceInfo.RangeSize))
+				peer.Log.Warnf("dst peer %s not found for piece %#v %#v", piece.DstPid, piece, piece.PieceInfo)
 			}
-			continue
 		}
 
-		// Handle piece download code.
-		if piece.Code != commonv1.Code_Success {
-			if piece.Code == commonv1.Code_ClientWaitPieceReady {
-				peer.Log.Debugf("receive piece code %d and wait for dfdaemon piece ready", piece.Code)
-				continue
-			}
+		// Collect traffic metrics.
+		if piece.DstPid != "" {
+			metrics.Traffic.WithLabelValues(peer.Tag, peer.Application, metrics.TrafficP2PType).Add(float64(piece.PieceInfo.RangeSize))
+		} else {
+			metrics.Traffic.WithLabelValues(peer.Tag, peer.Application, metrics.TrafficBackToSourceType).Add(float64(piece.PieceInfo.RangeSize))
+		}
+		return
+	}
 
-			// Handle piece download failed.
-			peer.Log.Errorf("receive failed piece: %#v", piece)
-			s.handlePieceFail(ctx, peer, piece)
-			continue
+	// Handle piece download code.
+	if piece.Code != commonv1.Code_Success {
+		if piece.Code == commonv1.Code_ClientWaitPieceReady {
+			peer.Log.Debugf("receive piece code %d and wait for dfdaemon piece ready", piece.Code)
+			return
 		}
 
-		peer.Log.Warnf("receive unknow piece: %#v %#v", piece, piece.PieceInfo)
+		// Handle piece download failed.
+		peer.Log.Errorf("receive failed piece: %#v", piece)
+		s.handlePieceFail(ctx, peer, piece)
+		return
 	}
+
+	peer.Log.Warnf("receive unknow piece: %#v %#v", piece, piece.PieceInfo)
 }
 
 // ReportPeerResult handles peer result reported by dfdaemon.
@@ -450,7 +556,7 @@ func (s *Service) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTas
 				PieceInfo:       pieceInfo,
 				ExtendAttribute: req.PiecePacket.ExtendAttribute,
 			})
-			peer.FinishedPieces.Set(uint(pieceInfo.PieceNum))
+			peer.FinishPiece(pieceInfo.PieceNum)
 			peer.AppendPieceCost(int64(pieceInfo.DownloadCost) * int64(time.Millisecond))
 			task.StorePiece(pieceInfo)
 		}
@@ -499,6 +605,7 @@ func (s *Service) LeaveTask(ctx context.Context, req *schedulerv1.PeerTarget) er
 	metrics.LeaveTaskCount.WithLabelValues(peer.Tag, peer.Application).Inc()
 
 	peer.Log.Infof("leave task: %#v", req)
+	isBackToSource := peer.FSM.Is(resource.PeerStateBackToSource)
 	if err := peer.FSM.Event(resource.PeerEventLeave); err != nil {
 		metrics.LeaveTaskFailureCount.WithLabelValues(peer.Tag, peer.Application).Inc()
 
@@ -507,19 +614,134 @@ func (s *Service) LeaveTask(ctx context.Context, req *schedulerv1.PeerTarget) er
 		return dferrors.New(commonv1.Code_SchedTaskStatusError, msg)
 	}
 
+	// Super peer is leaving, its children are rebalanced to other parents.
+	if peer.Task.DemoteSuperPeer(peer.ID) {
+		peer.Log.Info("super peer is demoted because of leaving")
+	}
+
 	// Reschedule a new parent to children of peer to exclude the current leave peer.
 	for _, child := range peer.Children() {
 		child.Log.Infof("schedule parent because of parent peer %s is leaving", peer.ID)
 		s.scheduler.ScheduleParent(ctx, child, child.BlockPeers)
 	}
 
+	// Back-to-source slot is released, promote a queued peer asynchronously,
+	// because scheduling of the promoted peer may be retried several times.
+	if isBackToSource {
+		go s.promoteBackToSourcePeer(context.Background(), peer.Task)
+	}
+
 	s.resource.PeerManager().Delete(peer.ID)
 	return nil
 }
 
+// WatchPeerETA sends the estimated remaining time of downloading to the peer periodically,
+// it returns when the peer is done or the context is done.
+func (s *Service) WatchPeerETA(ctx context.Context, req *schedulerv1.PeerTarget, send func(time.Duration) error) error {
+	peer, ok := s.resource.PeerManager().Load(req.PeerId)
+	if !ok {
+		msg := fmt.Sprintf("watch eta and peer %s is not exists", req.PeerId)
+		logger.Error(msg)
+		return dferrors.New(commonv1.Code_SchedPeerNotFound, msg)
+	}
+
+	ticker := time.NewTicker(s.config.Scheduler.ETAInterval)
+	defer ticker.Stop()
+	for {
+		if peer.FSM.Is(resource.PeerStateFailed) || peer.FSM.Is(resource.PeerStateLeave) {
+			return nil
+		}
+
+		if eta, ok := peer.ETA(); ok {
+			if err := send(eta); err != nil {
+				peer.Log.Debugf("send eta failed: %s", err.Error())
+				return err
+			}
+		}
+
+		if peer.FSM.Is(resource.PeerStateSucceeded) {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// OnNotify reschedules the children of seed peers which are removed or changed in dynconfig,
+// so that children do not keep fetching pieces from the unavailable seed peers.
+func (s *Service) OnNotify(data *config.DynconfigData) {
+	hostIDs := changedSeedPeerHostIDs(s.seedPeers, data.SeedPeers)
+	s.seedPeers = data.SeedPeers
+	if len(hostIDs) == 0 {
+		return
+	}
+
+	var seedPeers []*resource.Peer
+	s.resource.PeerManager().Range(func(_, value any) bool {
+		peer, ok := value.(*resource.Peer)
+		if !ok {
+			return true
+		}
+
+		if _, ok := hostIDs[peer.Host.ID]; ok {
+			seedPeers = append(seedPeers, peer)
+		}
+
+		return true
+	})
+
+	// Children are rescheduled asynchronously, because scheduling may be retried
+	// several times and blocks the notification of dynconfig.
+	for _, seedPeer := range seedPeers {
+		for _, child := range seedPeer.Children() {
+			if !child.FSM.Is(resource.PeerStateRunning) {
+				continue
+			}
+
+			child.Log.Infof("schedule parent because of seed peer %s is changed", seedPeer.ID)
+			child.BlockPeers.Add(seedPeer.ID)
+			go s.scheduler.ScheduleParent(context.Background(), child, child.BlockPeers)
+		}
+	}
+}
+
+// changedSeedPeerHostIDs returns host ids of seed peers which are removed or whose ip is changed.
+func changedSeedPeerHostIDs(sx []*config.SeedPeer, sy []*config.SeedPeer) map[string]struct{} {
+	ips := make(map[string]string, len(sy))
+	for _, y := range sy {
+		ips[idgen.HostID(y.Hostname, y.Port)] = y.IP
+	}
+
+	hostIDs := map[string]struct{}{}
+	for _, x := range sx {
+		id := idgen.HostID(x.Hostname, x.Port)
+		if ip, ok := ips[id]; ok && ip == x.IP {
+			continue
+		}
+
+		hostIDs[id] = struct{}{}
+	}
+
+	return hostIDs
+}
+
 // registerTask creates a new task or reuses a previous task.
 func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskRequest) (*resource.Task, bool, error) {
-	task := resource.NewTask(req.TaskId, req.Url, commonv1.TaskType_Normal, req.UrlMeta, resource.WithBackToSourceLimit(int32(s.config.Scheduler.BackSourceCount)))
+	backToSourceCount := s.config.Scheduler.BackSourceCount
+	options := []resource.Option{}
+	if class, ok := s.config.Scheduler.MatchTaskClass(req.UrlMeta.GetTag(), req.UrlMeta.GetApplication()); ok {
+		if class.BackSourceCount > 0 {
+			backToSourceCount = class.BackSourceCount
+		}
+		options = append(options, resource.WithClass(class.Name))
+	}
+
+	options = append(options, resource.WithBackToSourceLimit(int32(backToSourceCount)))
+	task := resource.NewTask(req.TaskId, req.Url, commonv1.TaskType_Normal, req.UrlMeta, options...)
 	task, loaded := s.resource.TaskManager().LoadOrStore(task)
 	if loaded && !task.FSM.Is(resource.TaskStateFailed) {
 		task.Log.Infof("task state is %s", task.FSM.Current())
@@ -538,7 +760,7 @@ func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskReq
 	}
 
 	// Start trigger seed peer task.
-	if s.config.SeedPeer.Enable {
+	if s.seedPeerEnabled() {
 		if task.IsSeedPeerFailed() {
 			return task, true, nil
 		}
@@ -551,6 +773,30 @@ func (s *Service) registerTask(ctx context.Context, req *schedulerv1.PeerTaskReq
 	return task, true, nil
 }
 
+// seedPeerEnabled returns whether to trigger seed peers, seed peers can be
+// disabled by the features of scheduler cluster when enabled in config.
+func (s *Service) seedPeerEnabled() bool {
+	if !s.config.SeedPeer.Enable {
+		return false
+	}
+
+	if features, ok := s.dynconfig.GetSchedulerClusterFeatures(); ok {
+		return features.Enabled(types.SchedulerFeatureSeedPeer, true)
+	}
+
+	return true
+}
+
+// matchBlocklist returns the rule of scheduler cluster blocklist matching the url.
+func (s *Service) matchBlocklist(url string) (types.BlocklistRule, bool) {
+	blocklist, ok := s.dynconfig.GetSchedulerClusterBlocklist()
+	if !ok {
+		return types.BlocklistRule{}, false
+	}
+
+	return blocklist.Match(url)
+}
+
 // registerHost creates a new host or reuses a previous host.
 func (s *Service) registerHost(ctx context.Context, rawHost *schedulerv1.PeerHost) *resource.Host {
 	host, ok := s.resource.HostManager().Load(rawHost.Id)
@@ -584,6 +830,7 @@ func (s *Service) registerPeer(ctx context.Context, peerID string, task *resourc
 	peer, loaded := s.resource.PeerManager().LoadOrStore(resource.NewPeer(peerID, task, host, options...))
 	if !loaded {
 		peer.Log.Info("create new peer")
+		task.Applications.Add(peer.Application)
 		return peer
 	}
 
@@ -651,14 +898,45 @@ func (s *Service) handleEndOfPiece(ctx context.Context, peer *resource.Peer) {}
 func (s *Service) handlePieceSuccess(ctx context.Context, peer *resource.Peer, piece *schedulerv1.PieceResult) {
 	// Update peer piece info.
 	peer.Pieces.Add(piece)
-	peer.FinishedPieces.Set(uint(piece.PieceInfo.PieceNum))
+	peer.FinishPiece(piece.PieceInfo.PieceNum)
 	peer.AppendPieceCost(pkgtime.SubNano(int64(piece.EndTime), int64(piece.BeginTime)).Milliseconds())
 
+	// Count the piece request when the piece is downloaded from parent.
+	if piece.DstPid != "" && piece.DstPid != peer.ID {
+		peer.Task.AddPieceRequest(piece.PieceInfo.PieceNum)
+	}
+
 	// When the peer downloads back-to-source,
 	// piece downloads successfully updates the task piece info.
 	if peer.FSM.Is(resource.PeerStateBackToSource) {
 		peer.Task.StorePiece(piece.PieceInfo)
 	}
+
+	// Switch parent when downloading from parent is degraded.
+	if piece.DstPid != "" && piece.DstPid != peer.ID && peer.FSM.Is(resource.PeerStateRunning) &&
+		s.scheduler.NeedAdjustParent(peer) {
+		s.adjustParent(ctx, peer, piece.DstPid)
+	}
+}
+
+// adjustParent switches the parent of peer to exclude the degraded parent,
+// peer keeps the current parent when no other parent can be found.
+func (s *Service) adjustParent(ctx context.Context, peer *resource.Peer, parentID string) {
+	blocklist := set.NewSafeSet[string]()
+	for _, id := range peer.BlockPeers.Values() {
+		blocklist.Add(id)
+	}
+	blocklist.Add(parentID)
+
+	if _, ok := s.scheduler.FindParent(ctx, peer, blocklist); !ok {
+		peer.Log.Infof("keep parent %s because of no other parent can be found", parentID)
+		return
+	}
+
+	peer.Log.Infof("schedule parent because of downloading from parent %s is degraded", parentID)
+	if _, ok := s.scheduler.NotifyAndFindParent(ctx, peer, blocklist); !ok {
+		peer.Log.Warnf("switch parent %s failed", parentID)
+	}
 }
 
 // handlePieceFail handles failed piece.
@@ -668,6 +946,11 @@ func (s *Service) handlePieceFail(ctx context.Context, peer *resource.Peer, piec
 		return
 	}
 
+	// Failed request of the piece is counted also.
+	if piece.PieceInfo != nil {
+		peer.Task.AddPieceRequest(piece.PieceInfo.PieceNum)
+	}
+
 	// If parent can not found, reschedule parent.
 	parent, ok := s.resource.PeerManager().Load(piece.DstPid)
 	if !ok {
@@ -698,7 +981,7 @@ func (s *Service) handlePieceFail(ctx context.Context, peer *resource.Peer, piec
 		s.handleLegacySeedPeer(ctx, parent)
 
 		// Start trigger seed peer task.
-		if s.config.SeedPeer.Enable {
+		if s.seedPeerEnabled() {
 			go s.triggerSeedPeerTask(ctx, parent.Task)
 		}
 	default:
@@ -749,16 +1032,54 @@ func (s *Service) handlePeerSuccess(ctx context.Context, peer *resource.Peer) {
 
 // handlePeerFail handles failed peer.
 func (s *Service) handlePeerFail(ctx context.Context, peer *resource.Peer) {
+	isBackToSource := peer.FSM.Is(resource.PeerStateBackToSource)
 	if err := peer.FSM.Event(resource.PeerEventDownloadFailed); err != nil {
 		peer.Log.Errorf("peer fsm event failed: %s", err.Error())
 		return
 	}
 
+	// Super peer is failed, its children are rebalanced to other parents.
+	if peer.Task.DemoteSuperPeer(peer.ID) {
+		peer.Log.Info("super peer is demoted because of download failed")
+	}
+
 	// Reschedule a new parent to children of peer to exclude the current failed peer.
 	for _, child := range peer.Children() {
 		child.Log.Infof("schedule parent because of parent peer %s is failed", peer.ID)
 		s.scheduler.ScheduleParent(ctx, child, child.BlockPeers)
 	}
+
+	// Back-to-source slot is released, promote a queued peer asynchronously,
+	// because scheduling of the promoted peer may be retried several times.
+	if isBackToSource {
+		go s.promoteBackToSourcePeer(context.Background(), peer.Task)
+	}
+}
+
+// promoteBackToSourcePeer promotes the first running peer queued for back-to-source,
+// and the peer is notified by the scheduling packet.
+func (s *Service) promoteBackToSourcePeer(ctx context.Context, task *resource.Task) {
+	for {
+		peer, ok := task.DequeueBackToSource()
+		if !ok {
+			return
+		}
+
+		if !peer.FSM.Is(resource.PeerStateRunning) {
+			peer.Log.Infof("peer state is %s, skip promoting back-to-source", peer.FSM.Current())
+			continue
+		}
+
+		if len(peer.Parents()) > 0 {
+			peer.Log.Info("peer is downloading from parents, skip promoting back-to-source")
+			continue
+		}
+
+		peer.Log.Info("promote queued peer to back-to-source")
+		peer.NeedBackToSource.Store(true)
+		s.scheduler.ScheduleParent(ctx, peer, peer.BlockPeers)
+		return
+	}
 }
 
 // handleLegacySeedPeer handles seed server's task has left,
@@ -870,6 +1191,7 @@ func (s *Service) createRecord(peer *resource.Peer, peerState int, req *schedule
 		IP:                   peer.Host.IP,
 		Hostname:             peer.Host.Hostname,
 		Tag:                  peer.Tag,
+		Application:          peer.Application,
 		Cost:                 req.Cost,
 		PieceCount:           int32(peer.FinishedPieces.Count()),
 		TotalPieceCount:      peer.Task.TotalPieceCount.Load(),
@@ -130,6 +130,163 @@ func TestService_New(t *testing.T) {
 	}
 }
 
+func TestService_Drain(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	scheduler := mocks.NewMockScheduler(ctl)
+	res := resource.NewMockResource(ctl)
+	dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+	storage := storagemocks.NewMockStorage(ctl)
+	svc := New(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduler, dynconfig, storage)
+
+	assert := assert.New(t)
+	assert.False(svc.IsDraining())
+
+	svc.Drain()
+	svc.Drain()
+	assert.True(svc.IsDraining())
+
+	// New peers are rejected without touching resource.
+	result, err := svc.RegisterPeerTask(context.Background(), &schedulerv1.PeerTaskRequest{
+		PeerId:  mockPeerID,
+		UrlMeta: &commonv1.UrlMeta{},
+	})
+	assert.Nil(result)
+	assert.Equal(codes.Unavailable, status.Code(err))
+
+	// Active streams are counted until they are done.
+	stream := schedulerv1mocks.NewMockScheduler_ReportPieceResultServer(ctl)
+	stream.EXPECT().Context().Return(context.Background()).Times(1)
+	stream.EXPECT().Recv().DoAndReturn(func() (*schedulerv1.PieceResult, error) {
+		assert.Equal(int64(1), svc.ActiveStreams())
+		return nil, io.EOF
+	}).Times(1)
+	assert.NoError(svc.ReportPieceResult(stream))
+	assert.Equal(int64(0), svc.ActiveStreams())
+}
+
+func TestService_RegisterPeerTask_Blocklist(t *testing.T) {
+	tests := []struct {
+		name      string
+		url       string
+		blocklist types.Blocklist
+		expect    func(t *testing.T, result *schedulerv1.RegisterResult, err error)
+	}{
+		{
+			name: "url matches glob pattern",
+			url:  "https://example.com/images/foo.iso",
+			blocklist: types.Blocklist{
+				{Type: types.BlocklistTypeGlob, Pattern: "https://example.com/*.iso"},
+			},
+			expect: func(t *testing.T, result *schedulerv1.RegisterResult, err error) {
+				assert := assert.New(t)
+				assert.Nil(result)
+				assert.Equal(codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "url matches regex pattern",
+			url:  "https://example.com/images/foo.iso",
+			blocklist: types.Blocklist{
+				{Type: types.BlocklistTypeRegex, Pattern: "foo"},
+			},
+			expect: func(t *testing.T, result *schedulerv1.RegisterResult, err error) {
+				assert := assert.New(t)
+				assert.Nil(result)
+				assert.Equal(codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "invalid pattern is skipped",
+			url:  "https://example.com/images/foo.iso",
+			blocklist: types.Blocklist{
+				{Type: types.BlocklistTypeRegex, Pattern: "(foo"},
+				{Type: types.BlocklistTypeGlob, Pattern: "*.iso"},
+			},
+			expect: func(t *testing.T, result *schedulerv1.RegisterResult, err error) {
+				assert := assert.New(t)
+				assert.Nil(result)
+				assert.Equal(codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			svc := New(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduler, dynconfig, storage)
+
+			// Blocked peers are rejected without touching resource.
+			dynconfig.EXPECT().GetSchedulerClusterBlocklist().Return(tc.blocklist, len(tc.blocklist) > 0).Times(1)
+			result, err := svc.RegisterPeerTask(context.Background(), &schedulerv1.PeerTaskRequest{
+				Url:     tc.url,
+				PeerId:  mockPeerID,
+				UrlMeta: &commonv1.UrlMeta{},
+			})
+			tc.expect(t, result, err)
+		})
+	}
+}
+
+func TestService_RegisterPeerTask_Admission(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    *schedulerv1.PeerTaskRequest
+		expect func(t *testing.T, result *schedulerv1.RegisterResult, err error)
+	}{
+		{
+			name: "registration is throttled",
+			req: &schedulerv1.PeerTaskRequest{
+				Url:      mockTaskURL,
+				PeerId:   mockPeerID,
+				UrlMeta:  &commonv1.UrlMeta{},
+				PeerHost: mockRawHost,
+			},
+			expect: func(t *testing.T, result *schedulerv1.RegisterResult, err error) {
+				assert := assert.New(t)
+				assert.Nil(result)
+				assert.Equal(codes.ResourceExhausted, status.Code(err))
+				delay, ok := common.RetryAfter(err)
+				assert.True(ok)
+				assert.True(delay > 0)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			svc := New(&config.Config{Scheduler: &config.SchedulerConfig{
+				Admission: &config.AdmissionConfig{
+					Enable:    true,
+					IPRate:    0.1,
+					IPBurst:   1,
+					BucketTTL: time.Minute,
+				},
+			}}, res, scheduler, dynconfig, storage)
+
+			// Take the only token of the source ip.
+			_, ok := svc.admission.admit(tc.req.PeerHost.Ip, "")
+			assert.True(t, ok)
+
+			// Throttled peers are rejected without touching resource.
+			dynconfig.EXPECT().GetSchedulerClusterBlocklist().Return(nil, false).Times(1)
+			result, err := svc.RegisterPeerTask(context.Background(), tc.req)
+			tc.expect(t, result, err)
+		})
+	}
+}
+
 func TestService_RegisterPeerTask(t *testing.T) {
 	tests := []struct {
 		name string
@@ -742,6 +899,7 @@ func TestService_RegisterPeerTask(t *testing.T) {
 			taskManager := resource.NewMockTaskManager(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
 			svc := New(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduler, dynconfig, storage)
+			dynconfig.EXPECT().GetSchedulerClusterBlocklist().Return(nil, false).AnyTimes()
 
 			mockHost := resource.NewHost(mockRawHost)
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
@@ -935,6 +1093,44 @@ func TestService_ReportPieceResult(t *testing.T) {
 				assert.False(ok)
 			},
 		},
+		{
+			name: "revice batched successful pieces",
+			mock: func(
+				mockPeer *resource.Peer,
+				res resource.Resource, peerManager resource.PeerManager,
+				mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder, ms *schedulerv1mocks.MockScheduler_ReportPieceResultServerMockRecorder,
+
+			) {
+				piece, err := common.PackPieceResults([]*schedulerv1.PieceResult{
+					{
+						SrcPid:    mockPeerID,
+						Success:   true,
+						PieceInfo: &commonv1.PieceInfo{PieceNum: 0},
+					},
+					{
+						SrcPid:    mockPeerID,
+						Success:   true,
+						PieceInfo: &commonv1.PieceInfo{PieceNum: 1},
+					},
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				gomock.InOrder(
+					ms.Context().Return(context.Background()).Times(1),
+					ms.Recv().Return(piece, nil).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Eq(mockPeerID)).Return(mockPeer, true).Times(1),
+					ms.Recv().Return(nil, io.EOF).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(uint(2), peer.FinishedPieces.Count())
+			},
+		},
 		{
 			name: "revice Code_ClientWaitPieceReady code",
 			mock: func(
@@ -1502,6 +1698,9 @@ func TestService_AnnounceTask(t *testing.T) {
 }
 
 func TestService_LeaveTask(t *testing.T) {
+	// Queued peer is promoted asynchronously.
+	var promoted sync.WaitGroup
+
 	tests := []struct {
 		name   string
 		mock   func(peer *resource.Peer, child *resource.Peer, peerManager resource.PeerManager, ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder)
@@ -1716,6 +1915,35 @@ func TestService_LeaveTask(t *testing.T) {
 				assert.True(peer.FSM.Is(resource.PeerStateLeave))
 			},
 		},
+		{
+			name: "peer state is PeerStateBackToSource and queued peer is promoted",
+			mock: func(peer *resource.Peer, child *resource.Peer, peerManager resource.PeerManager, ms *mocks.MockSchedulerMockRecorder, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder) {
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(child)
+				peer.FSM.SetState(resource.PeerStateBackToSource)
+				peer.Task.BackToSourcePeers.Add(peer.ID)
+				child.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.EnqueueBackToSource(child.ID)
+
+				promoted.Add(1)
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Any()).Return(peer, true).Times(1),
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Delete(gomock.Eq(peer.ID)).Return().Times(1),
+				)
+				ms.ScheduleParent(gomock.Any(), gomock.Eq(child), gomock.Eq(set.NewSafeSet[string]())).Do(
+					func(context.Context, *resource.Peer, set.SafeSet[string]) { promoted.Done() }).Return().Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				promoted.Wait()
+				assert.True(peer.FSM.Is(resource.PeerStateLeave))
+				assert.False(peer.Task.BackToSourcePeers.Contains(peer.ID))
+				assert.Equal(peer.Task.BackToSourceQueueLen(), 0)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -1739,12 +1967,117 @@ func TestService_LeaveTask(t *testing.T) {
 	}
 }
 
-func TestService_registerTask(t *testing.T) {
+func TestService_WatchPeerETA(t *testing.T) {
 	tests := []struct {
 		name   string
-		config *config.Config
-		req    *schedulerv1.PeerTaskRequest
-		run    func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder)
+		mock   func(peer *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder)
+		expect func(t *testing.T, etas []time.Duration, err error)
+	}{
+		{
+			name: "peer not found",
+			mock: func(peer *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder) {
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Any()).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, etas []time.Duration, err error) {
+				assert := assert.New(t)
+				dferr, ok := err.(*dferrors.DfError)
+				assert.True(ok)
+				assert.Equal(dferr.Code, commonv1.Code_SchedPeerNotFound)
+				assert.Empty(etas)
+			},
+		},
+		{
+			name: "peer state is PeerStateSucceeded",
+			mock: func(peer *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateSucceeded)
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Any()).Return(peer, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, etas []time.Duration, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]time.Duration{0}, etas)
+			},
+		},
+		{
+			name: "peer state is PeerStateLeave",
+			mock: func(peer *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateLeave)
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Any()).Return(peer, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, etas []time.Duration, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(etas)
+			},
+		},
+		{
+			name: "peer is running until succeeded",
+			mock: func(peer *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.TotalPieceCount.Store(2)
+				peer.FinishedPieces.Set(0)
+				peer.CreateAt.Store(time.Now().Add(-10 * time.Second))
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Load(gomock.Any()).Return(peer, true).Times(1),
+				)
+
+				go func() {
+					time.Sleep(30 * time.Millisecond)
+					peer.FSM.SetState(resource.PeerStateSucceeded)
+				}()
+			},
+			expect: func(t *testing.T, etas []time.Duration, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Greater(len(etas), 1)
+				assert.InDelta(10*time.Second, etas[0], float64(time.Second))
+				assert.Equal(time.Duration(0), etas[len(etas)-1])
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			peerManager := resource.NewMockPeerManager(ctl)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := resource.NewPeer(mockPeerID, mockTask, mockHost)
+			svc := New(&config.Config{Scheduler: &config.SchedulerConfig{ETAInterval: 10 * time.Millisecond}, Metrics: &config.MetricsConfig{EnablePeerHost: true}}, res, scheduler, dynconfig, storage)
+
+			tc.mock(peer, peerManager, res.EXPECT(), peerManager.EXPECT())
+			var etas []time.Duration
+			err := svc.WatchPeerETA(context.Background(), &schedulerv1.PeerTarget{PeerId: mockPeerID}, func(eta time.Duration) error {
+				etas = append(etas, eta)
+				return nil
+			})
+			tc.expect(t, etas, err)
+		})
+	}
+}
+
+func TestService_registerTask(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *config.Config
+		features types.SchedulerClusterFeatures
+		req      *schedulerv1.PeerTaskRequest
+		run      func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder)
 	}{
 		{
 			name: "task already exists and state is TaskStatePending",
@@ -2041,6 +2374,38 @@ func TestService_registerTask(t *testing.T) {
 				assert.EqualValues(mockTask, task)
 			},
 		},
+		{
+			name: "task state is TaskStatePending and seed peer is disabled by features",
+			config: &config.Config{
+				Scheduler: mockSchedulerConfig,
+				SeedPeer: &config.SeedPeerConfig{
+					Enable: true,
+				},
+			},
+			features: types.SchedulerClusterFeatures{types.SchedulerFeatureSeedPeer: false},
+			req: &schedulerv1.PeerTaskRequest{
+				Url:     mockTaskURL,
+				UrlMeta: mockTaskURLMeta,
+				PeerHost: &schedulerv1.PeerHost{
+					Id: mockRawSeedHost.Id,
+				},
+			},
+			run: func(t *testing.T, svc *Service, req *schedulerv1.PeerTaskRequest, mockTask *resource.Task, mockPeer *resource.Peer, taskManager resource.TaskManager, hostManager resource.HostManager, seedPeer resource.SeedPeer, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, mh *resource.MockHostManagerMockRecorder, mc *resource.MockSeedPeerMockRecorder) {
+				mockTask.FSM.SetState(resource.TaskStatePending)
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.LoadOrStore(gomock.Any()).Return(mockTask, false).Times(1),
+					mr.HostManager().Return(hostManager).Times(1),
+					mh.Load(gomock.Any()).Return(nil, false).Times(1),
+				)
+
+				task, needBackToSource, err := svc.registerTask(context.Background(), req)
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.True(needBackToSource)
+				assert.EqualValues(mockTask, task)
+			},
+		},
 		{
 			name: "task state is TaskStateFailed and disable seed peer",
 			config: &config.Config{
@@ -2081,6 +2446,7 @@ func TestService_registerTask(t *testing.T) {
 			scheduler := mocks.NewMockScheduler(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			dynconfig.EXPECT().GetSchedulerClusterFeatures().Return(tc.features, tc.features != nil).AnyTimes()
 			storage := storagemocks.NewMockStorage(ctl)
 			svc := New(tc.config, res, scheduler, dynconfig, storage)
 
@@ -2368,6 +2734,7 @@ func TestService_registerPeer(t *testing.T) {
 				assert := assert.New(t)
 				assert.Equal(peer.ID, mockPeerID)
 				assert.Equal(peer.Tag, resource.DefaultTag)
+				assert.True(peer.Task.Applications.Contains(resource.DefaultApplication))
 			},
 		},
 	}
@@ -2402,7 +2769,7 @@ func TestService_handlePieceSuccess(t *testing.T) {
 		name   string
 		piece  *schedulerv1.PieceResult
 		peer   *resource.Peer
-		mock   func(peer *resource.Peer)
+		mock   func(peer *resource.Peer, ms *mocks.MockSchedulerMockRecorder)
 		expect func(t *testing.T, peer *resource.Peer)
 	}{
 		{
@@ -2416,7 +2783,7 @@ func TestService_handlePieceSuccess(t *testing.T) {
 				EndTime:   uint64(now.Add(1 * time.Millisecond).UnixNano()),
 			},
 			peer: resource.NewPeer(mockPeerID, mockTask, mockHost),
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
 				peer.FSM.SetState(resource.PeerStateRunning)
 			},
 			expect: func(t *testing.T, peer *resource.Peer) {
@@ -2437,7 +2804,7 @@ func TestService_handlePieceSuccess(t *testing.T) {
 				EndTime:   uint64(now.Add(1 * time.Millisecond).UnixNano()),
 			},
 			peer: resource.NewPeer(mockPeerID, mockTask, mockHost),
-			mock: func(peer *resource.Peer) {
+			mock: func(peer *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
 				peer.FSM.SetState(resource.PeerStateBackToSource)
 			},
 			expect: func(t *testing.T, peer *resource.Peer) {
@@ -2453,6 +2820,80 @@ func TestService_handlePieceSuccess(t *testing.T) {
 				})
 			},
 		},
+		{
+			name: "downloading from parent is not degraded",
+			piece: &schedulerv1.PieceResult{
+				DstPid: mockSeedPeerID,
+				PieceInfo: &commonv1.PieceInfo{
+					PieceNum: 0,
+					PieceMd5: "ac32345ef819f03710e2105c81106fdd",
+				},
+				BeginTime: uint64(now.UnixNano()),
+				EndTime:   uint64(now.Add(1 * time.Millisecond).UnixNano()),
+			},
+			peer: resource.NewPeer(mockPeerID, mockTask, mockHost),
+			mock: func(peer *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				ms.NeedAdjustParent(gomock.Eq(peer)).Return(false).Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(peer.Pieces.Len(), uint(1))
+				assert.Equal(peer.PieceCosts(), []int64{1})
+			},
+		},
+		{
+			name: "downloading from parent is degraded and parent is switched",
+			piece: &schedulerv1.PieceResult{
+				DstPid: mockSeedPeerID,
+				PieceInfo: &commonv1.PieceInfo{
+					PieceNum: 0,
+					PieceMd5: "ac32345ef819f03710e2105c81106fdd",
+				},
+				BeginTime: uint64(now.UnixNano()),
+				EndTime:   uint64(now.Add(1 * time.Millisecond).UnixNano()),
+			},
+			peer: resource.NewPeer(mockPeerID, mockTask, mockHost),
+			mock: func(peer *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				blocklist := set.NewSafeSet[string]()
+				blocklist.Add(mockSeedPeerID)
+				gomock.InOrder(
+					ms.NeedAdjustParent(gomock.Eq(peer)).Return(true).Times(1),
+					ms.FindParent(gomock.Any(), gomock.Eq(peer), gomock.Eq(blocklist)).Return(peer, true).Times(1),
+					ms.NotifyAndFindParent(gomock.Any(), gomock.Eq(peer), gomock.Eq(blocklist)).Return([]*resource.Peer{peer}, true).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(peer.Pieces.Len(), uint(1))
+				assert.Equal(peer.BlockPeers.Len(), uint(0))
+			},
+		},
+		{
+			name: "downloading from parent is degraded and no other parent can be found",
+			piece: &schedulerv1.PieceResult{
+				DstPid: mockSeedPeerID,
+				PieceInfo: &commonv1.PieceInfo{
+					PieceNum: 0,
+					PieceMd5: "ac32345ef819f03710e2105c81106fdd",
+				},
+				BeginTime: uint64(now.UnixNano()),
+				EndTime:   uint64(now.Add(1 * time.Millisecond).UnixNano()),
+			},
+			peer: resource.NewPeer(mockPeerID, mockTask, mockHost),
+			mock: func(peer *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				peer.FSM.SetState(resource.PeerStateRunning)
+				gomock.InOrder(
+					ms.NeedAdjustParent(gomock.Eq(peer)).Return(true).Times(1),
+					ms.FindParent(gomock.Any(), gomock.Eq(peer), gomock.Any()).Return(nil, false).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer) {
+				assert := assert.New(t)
+				assert.Equal(peer.Pieces.Len(), uint(1))
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -2465,7 +2906,7 @@ func TestService_handlePieceSuccess(t *testing.T) {
 			storage := storagemocks.NewMockStorage(ctl)
 			svc := New(&config.Config{Scheduler: mockSchedulerConfig, Metrics: &config.MetricsConfig{EnablePeerHost: true}}, res, scheduler, dynconfig, storage)
 
-			tc.mock(tc.peer)
+			tc.mock(tc.peer, scheduler.EXPECT())
 			svc.handlePieceSuccess(context.Background(), tc.peer, tc.piece)
 			tc.expect(t, tc.peer)
 		})
@@ -2658,6 +3099,7 @@ func TestService_handlePieceFail(t *testing.T) {
 			scheduler := mocks.NewMockScheduler(ctl)
 			res := resource.NewMockResource(ctl)
 			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			dynconfig.EXPECT().GetSchedulerClusterFeatures().Return(nil, false).AnyTimes()
 			storage := storagemocks.NewMockStorage(ctl)
 			peerManager := resource.NewMockPeerManager(ctl)
 			seedPeer := resource.NewMockSeedPeer(ctl)
@@ -2787,6 +3229,8 @@ func TestService_handlePeerSuccess(t *testing.T) {
 }
 
 func TestService_handlePeerFail(t *testing.T) {
+	// Queued peer is promoted asynchronously.
+	var promoted sync.WaitGroup
 
 	tests := []struct {
 		name   string
@@ -2842,6 +3286,29 @@ func TestService_handlePeerFail(t *testing.T) {
 				assert.True(peer.FSM.Is(resource.PeerStateFailed))
 			},
 		},
+		{
+			name: "peer state is PeerStateBackToSource and queued peer is promoted",
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(child)
+				peer.FSM.SetState(resource.PeerStateBackToSource)
+				peer.Task.BackToSourcePeers.Add(peer.ID)
+				child.FSM.SetState(resource.PeerStateRunning)
+				peer.Task.EnqueueBackToSource(child.ID)
+
+				promoted.Add(1)
+				ms.ScheduleParent(gomock.Any(), gomock.Eq(child), gomock.Eq(set.NewSafeSet[string]())).Do(
+					func(context.Context, *resource.Peer, set.SafeSet[string]) { promoted.Done() }).Return().Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				promoted.Wait()
+				assert.True(peer.FSM.Is(resource.PeerStateFailed))
+				assert.False(peer.Task.BackToSourcePeers.Contains(peer.ID))
+				assert.True(child.NeedBackToSource.Load())
+				assert.Equal(peer.Task.BackToSourceQueueLen(), 0)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -2865,6 +3332,200 @@ func TestService_handlePeerFail(t *testing.T) {
 	}
 }
 
+func TestService_OnNotify(t *testing.T) {
+	// Children are rescheduled asynchronously.
+	var scheduled sync.WaitGroup
+
+	mockSeedPeer := &config.SeedPeer{
+		Hostname: "hostname_seed",
+		IP:       "127.0.0.1",
+		Port:     8003,
+	}
+
+	tests := []struct {
+		name      string
+		seedPeers []*config.SeedPeer
+		data      *config.DynconfigData
+		mock      func(peer *resource.Peer, child *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder, ms *mocks.MockSchedulerMockRecorder)
+		expect    func(t *testing.T, peer *resource.Peer, child *resource.Peer)
+	}{
+		{
+			name:      "seed peers are not changed",
+			seedPeers: []*config.SeedPeer{mockSeedPeer},
+			data:      &config.DynconfigData{SeedPeers: []*config.SeedPeer{mockSeedPeer}},
+			mock: func(peer *resource.Peer, child *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder, ms *mocks.MockSchedulerMockRecorder) {
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				assert.False(child.BlockPeers.Contains(peer.ID))
+			},
+		},
+		{
+			name:      "seed peer is removed and running child needs to be scheduled",
+			seedPeers: []*config.SeedPeer{mockSeedPeer},
+			data:      &config.DynconfigData{},
+			mock: func(peer *resource.Peer, child *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder, ms *mocks.MockSchedulerMockRecorder) {
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(child)
+				if err := peer.Task.AddPeerEdge(peer, child); err != nil {
+					t.Fatal(err)
+				}
+				child.FSM.SetState(resource.PeerStateRunning)
+
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Range(gomock.Any()).Do(func(f func(key, value any) bool) {
+						f(peer.ID, peer)
+						f(child.ID, child)
+					}).Times(1),
+					ms.ScheduleParent(gomock.Any(), gomock.Eq(child), gomock.Any()).Do(
+						func(context.Context, *resource.Peer, set.SafeSet[string]) { scheduled.Done() }).Return().Times(1),
+				)
+				scheduled.Add(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				scheduled.Wait()
+				assert.True(child.BlockPeers.Contains(peer.ID))
+			},
+		},
+		{
+			name:      "seed peer ip is changed and child is not running",
+			seedPeers: []*config.SeedPeer{mockSeedPeer},
+			data: &config.DynconfigData{SeedPeers: []*config.SeedPeer{{
+				Hostname: mockSeedPeer.Hostname,
+				IP:       "127.0.0.2",
+				Port:     mockSeedPeer.Port,
+			}}},
+			mock: func(peer *resource.Peer, child *resource.Peer, peerManager resource.PeerManager, mr *resource.MockResourceMockRecorder, mp *resource.MockPeerManagerMockRecorder, ms *mocks.MockSchedulerMockRecorder) {
+				peer.Task.StorePeer(peer)
+				peer.Task.StorePeer(child)
+				if err := peer.Task.AddPeerEdge(peer, child); err != nil {
+					t.Fatal(err)
+				}
+				child.FSM.SetState(resource.PeerStateSucceeded)
+
+				gomock.InOrder(
+					mr.PeerManager().Return(peerManager).Times(1),
+					mp.Range(gomock.Any()).Do(func(f func(key, value any) bool) {
+						f(peer.ID, peer)
+						f(child.ID, child)
+					}).Times(1),
+				)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				assert.False(child.BlockPeers.Contains(peer.ID))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			peerManager := resource.NewMockPeerManager(ctl)
+			svc := New(&config.Config{Scheduler: mockSchedulerConfig, Metrics: &config.MetricsConfig{EnablePeerHost: true}}, res, scheduler, dynconfig, storage)
+			svc.seedPeers = tc.seedPeers
+			mockHost := resource.NewHost(mockRawHost)
+			mockSeedHost := resource.NewHost(mockRawSeedHost, resource.WithHostType(resource.HostTypeSuperSeed))
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := resource.NewPeer(mockSeedPeerID, mockTask, mockSeedHost)
+			child := resource.NewPeer(mockPeerID, mockTask, mockHost)
+
+			tc.mock(peer, child, peerManager, res.EXPECT(), peerManager.EXPECT(), scheduler.EXPECT())
+			svc.OnNotify(tc.data)
+			assert.Equal(t, svc.seedPeers, tc.data.SeedPeers)
+			tc.expect(t, peer, child)
+		})
+	}
+}
+
+func TestService_promoteBackToSourcePeer(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulerMockRecorder)
+		expect func(t *testing.T, peer *resource.Peer, child *resource.Peer)
+	}{
+		{
+			name: "queue is empty",
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				assert.False(child.NeedBackToSource.Load())
+			},
+		},
+		{
+			name: "queued peer is not running",
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				child.FSM.SetState(resource.PeerStateSucceeded)
+				child.Task.EnqueueBackToSource(child.ID)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				assert.False(child.NeedBackToSource.Load())
+				assert.Equal(child.Task.BackToSourceQueueLen(), 0)
+			},
+		},
+		{
+			name: "queued peer is downloading from parents",
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				if err := peer.Task.AddPeerEdge(peer, child); err != nil {
+					t.Fatal(err)
+				}
+				child.FSM.SetState(resource.PeerStateRunning)
+				child.Task.EnqueueBackToSource(child.ID)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				assert.False(child.NeedBackToSource.Load())
+				assert.Equal(child.Task.BackToSourceQueueLen(), 0)
+			},
+		},
+		{
+			name: "queued peer is promoted",
+			mock: func(peer *resource.Peer, child *resource.Peer, ms *mocks.MockSchedulerMockRecorder) {
+				child.FSM.SetState(resource.PeerStateRunning)
+				child.Task.EnqueueBackToSource(child.ID)
+
+				ms.ScheduleParent(gomock.Any(), gomock.Eq(child), gomock.Eq(set.NewSafeSet[string]())).Return().Times(1)
+			},
+			expect: func(t *testing.T, peer *resource.Peer, child *resource.Peer) {
+				assert := assert.New(t)
+				assert.True(child.NeedBackToSource.Load())
+				assert.Equal(child.Task.BackToSourceQueueLen(), 0)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			scheduler := mocks.NewMockScheduler(ctl)
+			res := resource.NewMockResource(ctl)
+			dynconfig := configmocks.NewMockDynconfigInterface(ctl)
+			storage := storagemocks.NewMockStorage(ctl)
+			svc := New(&config.Config{Scheduler: mockSchedulerConfig, Metrics: &config.MetricsConfig{EnablePeerHost: true}}, res, scheduler, dynconfig, storage)
+			mockHost := resource.NewHost(mockRawHost)
+			mockTask := resource.NewTask(mockTaskID, mockTaskURL, commonv1.TaskType_Normal, mockTaskURLMeta, resource.WithBackToSourceLimit(mockTaskBackToSourceLimit))
+			peer := resource.NewPeer(mockSeedPeerID, mockTask, mockHost)
+			child := resource.NewPeer(mockPeerID, mockTask, mockHost)
+			mockTask.StorePeer(peer)
+			mockTask.StorePeer(child)
+
+			tc.mock(peer, child, scheduler.EXPECT())
+			svc.promoteBackToSourcePeer(context.Background(), mockTask)
+			tc.expect(t, peer, child)
+		})
+	}
+}
+
 func TestService_handleTaskSuccess(t *testing.T) {
 	tests := []struct {
 		name   string
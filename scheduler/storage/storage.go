@@ -92,6 +92,9 @@ type Record struct {
 	// Tag is peer tag.
 	Tag string `csv:"tag"`
 
+	// Application is peer application.
+	Application string `csv:"application"`
+
 	// Cost is the task download time(millisecond).
 	Cost uint32 `csv:"cost"`
 